@@ -62,9 +62,11 @@ import (
 	"time"
 	"unicode"
 
+	"github.com/inth3shadows/runecho/internal/config"
 	"github.com/inth3shadows/runecho/internal/gitutil"
 	"github.com/inth3shadows/runecho/internal/guard"
 	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/parser"
 	"github.com/inth3shadows/runecho/internal/snapshot"
 	"github.com/inth3shadows/runecho/internal/store"
 	"github.com/inth3shadows/runecho/internal/version"
@@ -394,9 +396,20 @@ func refreshIRForFile(filePath string) (outcome string) {
 			}
 		}
 	}
-	irPath := filepath.Join(srcRoot, ".ai", "ir.json")
-
-	gen := ir.NewGenerator(ir.GeneratorConfig{})
+	// The repo's parser plugins, so an edit to a plugin-claimed file refreshes it
+	// like any other, and its IR location. Fail-open like the rest of this hook:
+	// a broken config degrades to built-in parsers and .ai/ir.json rather than
+	// blocking the edit.
+	var plugins []parser.Parser
+	cfg := &config.Config{}
+	if loaded, cfgErr := config.Load(srcRoot); cfgErr == nil {
+		cfg = loaded
+		plugins, _ = cfg.PluginParsers(srcRoot, nil)
+	}
+	irPath := cfg.IRLocation(srcRoot)
+	gc := cfg.Generator(plugins)
+	gc.Objects = cfg.ObjectStore(srcRoot)
+	gen := ir.NewGenerator(gc)
 	// Serialize the whole load→update→save (and the store roll that mirrors it)
 	// under a cross-process advisory lock: concurrent PostToolUse hooks otherwise
 	// interleave load-modify-save on ir.json and the last writer silently drops
@@ -443,7 +456,7 @@ func refreshIRForFile(filePath string) (outcome string) {
 			return
 		}
 
-		if err := updated.Save(irPath); err != nil {
+		if err := cfg.SaveIR(updated, srcRoot); err != nil {
 			outcome = "save-fail"
 			return
 		}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/inth3shadows/runecho/internal/analyze"
+	"github.com/inth3shadows/runecho/internal/config"
+)
+
+// runAnalyze runs every registered analyzer (built-ins plus .runecho.json exec
+// plugins) over a fresh IR and prints the unified findings report. Exits
+// ExitNoData when any finding is a warning or an error, so CI can gate on it;
+// info findings alone exit ExitOK.
+func runAnalyze(args []string) int {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	only := fs.String("only", "", "comma-separated analyzer names to run (default: all not disabled in config)")
+	list := fs.Bool("list", false, "list registered analyzers and exit")
+	asJSON := fs.Bool("json", false, "machine-readable JSON")
+	if code, ok := parseSub(fs, args); !ok {
+		return code
+	}
+	if *list {
+		for _, name := range analyze.Registered() {
+			fmt.Println(name)
+		}
+		return ExitOK
+	}
+
+	root, code := resolveRoot(fs.Args())
+	if code != 0 {
+		return code
+	}
+	cfg, err := config.Load(root)
+	if err != nil {
+		return printErr(err)
+	}
+	plugins, err := cfg.AnalyzerPlugins(root, func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, format, args...)
+	})
+	if err != nil {
+		return printErr(err)
+	}
+	irData, _, irCode := buildIR(root, 0)
+	if irCode != 0 {
+		return irCode
+	}
+
+	var names []string
+	if *only != "" {
+		names = strings.Split(*only, ",")
+	}
+	rep, err := analyze.Run(irData, cfg.Analyzers.Options, names, plugins...)
+	if rep == nil {
+		return printErr(err)
+	}
+	if err != nil {
+		// A failing analyzer must not hide the others' findings, but it must not
+		// pass for a clean run either.
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+
+	if *asJSON {
+		out, jerr := json.MarshalIndent(rep, "", "  ")
+		if jerr != nil {
+			return printErr(jerr)
+		}
+		fmt.Println(string(out))
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range rep.Findings {
+			loc := f.Path
+			if f.Line > 0 {
+				loc += fmt.Sprintf(":%d", f.Line)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", loc, f.Severity, f.Analyzer, f.Message)
+		}
+		w.Flush()
+		fmt.Printf("%d finding(s) from %s\n", len(rep.Findings), strings.Join(rep.Analyzers, ", "))
+	}
+
+	if err != nil {
+		return ExitError
+	}
+	for _, f := range rep.Findings {
+		if f.Severity != analyze.SeverityInfo {
+			return ExitNoData
+		}
+	}
+	return ExitOK
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAnalyze_ExitCodes: a clean tree exits ExitOK; a naming rule from
+// .runecho.json that stub.go's Hello breaks yields a warning and ExitNoData.
+func TestAnalyze_ExitCodes(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	irGitInit(t, dir)
+
+	code, stdout, stderr := runWith(t, home, []string{"runecho-ir", "analyze", dir})
+	if code != ExitOK || !strings.Contains(stdout, "0 finding(s) from boundary, naming, unused-export") {
+		t.Fatalf("clean tree: %d %q (stderr %q)", code, stdout, stderr)
+	}
+
+	cfg := `{"analyzers":{"naming":[{"kind":"function","pattern":"^[a-z]"}]}}`
+	if err := os.WriteFile(filepath.Join(dir, ".runecho.json"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, _ = runWith(t, home, []string{"runecho-ir", "analyze", "--only=naming", dir})
+	if code != ExitNoData || !strings.Contains(stdout, "stub.go:3") || !strings.Contains(stdout, "function Hello does not match") {
+		t.Errorf("naming violation: %d %q", code, stdout)
+	}
+	if code, _, stderr := runWith(t, home, []string{"runecho-ir", "analyze", "--only=nope", dir}); code != ExitError || !strings.Contains(stderr, `unknown analyzer "nope"`) {
+		t.Errorf("unknown --only: %d %q", code, stderr)
+	}
+}
+
+func TestAnalyze_List(t *testing.T) {
+	code, stdout, _ := runWith(t, t.TempDir(), []string{"runecho-ir", "analyze", "--list"})
+	if code != ExitOK || stdout != "boundary\nnaming\nunused-export\n" {
+		t.Errorf("--list = %d %q", code, stdout)
+	}
+}
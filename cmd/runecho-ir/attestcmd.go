@@ -0,0 +1,225 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/inth3shadows/runecho/internal/config"
+	"github.com/inth3shadows/runecho/internal/gitutil"
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/irverify"
+	"github.com/inth3shadows/runecho/internal/parser"
+	"github.com/inth3shadows/runecho/internal/store"
+	"github.com/inth3shadows/runecho/internal/version"
+)
+
+// runAttest writes an attestation for root's saved IR (see
+// internal/irverify): an in-toto Statement binding the file to the runecho
+// version, config digest, and commit that produced it, signed with --key. Like
+// a signature it goes stale on the next save, so attest where the IR is
+// published. With --check it instead reproduces an attestation: it
+// regenerates the IR from the checkout and compares root hashes.
+func runAttest(args []string) int {
+	fs := flag.NewFlagSet("attest", flag.ContinueOnError)
+	keyPath := fs.String("key", "", "Ed25519 private key (PKCS #8 PEM) to sign the attestation with")
+	check := fs.String("check", "", "reproduce this attestation from the checkout instead of writing one")
+	if code, ok := parseSub(fs, args); !ok {
+		return code
+	}
+	root, code := resolveRoot(fs.Args())
+	if code != 0 {
+		return code
+	}
+	cfg, plugins, code := repoConfig(root)
+	if code != 0 {
+		return code
+	}
+	if *check != "" {
+		return checkAttestation(root, cfg, plugins, *check)
+	}
+
+	irPath := cfg.IRLocation(root)
+	if ir.IsStorageURI(irPath) {
+		return printErr(fmt.Errorf("cannot attest %s: only a local IR file can be attested", irPath))
+	}
+	var key ed25519.PrivateKey
+	if *keyPath != "" {
+		data, err := os.ReadFile(*keyPath)
+		if err == nil {
+			key, err = irverify.ParsePrivateKey(data)
+		}
+		if err != nil {
+			return printErr(err)
+		}
+	}
+	r, err := irverify.Verify(irPath, irverify.Options{})
+	if err != nil {
+		return printErr(err)
+	}
+	if len(r.Problems) > 0 {
+		return printErr(fmt.Errorf("refusing to attest %s: %s", irPath, r.Problems[0]))
+	}
+	saved, err := ir.Load(irPath)
+	if err != nil {
+		return printErr(err)
+	}
+	pred, err := attestedPredicate(root, saved)
+	if err != nil {
+		return printErr(fmt.Errorf("refusing to attest %s: %w", irPath, err))
+	}
+	if pred.Generator.Version == "dev" {
+		fmt.Fprintln(os.Stderr, "Warning: this runecho is an unstamped dev build; its version does not pin the parsers that ran")
+	}
+	data, err := os.ReadFile(irPath)
+	if err != nil {
+		return printErr(err)
+	}
+	name, err := filepath.Rel(root, irPath)
+	if err != nil {
+		return printErr(err)
+	}
+	out, err := irverify.MarshalAttestation(irverify.NewStatement(filepath.ToSlash(name), data, pred), key)
+	if err != nil {
+		return printErr(err)
+	}
+	attPath := irverify.AttestationFile(irPath)
+	if err := store.AtomicWriteFile(attPath, out); err != nil {
+		return printErr(err)
+	}
+	fmt.Printf("Attested %s at %s: %s\n", irPath, pred.Source.Revision, attPath)
+	return ExitOK
+}
+
+// attestedPredicate describes how result was produced from root, provided the
+// files it indexed are exactly those of the commit checked out.
+func attestedPredicate(root string, result *ir.IR) (irverify.Predicate, error) {
+	rev, err := gitutil.Head(root)
+	if err != nil {
+		return irverify.Predicate{}, fmt.Errorf("no commit to attest to: %w", err)
+	}
+	top, err := gitutil.TopLevel(root)
+	if err != nil {
+		return irverify.Predicate{}, err
+	}
+	if err := sourceMatchesHead(root, result); err != nil {
+		return irverify.Predicate{}, err
+	}
+	cfgName, err := config.Locate(root)
+	if err != nil {
+		return irverify.Predicate{}, err
+	}
+	if cfgName == "" {
+		cfgName = config.FileName // digested as empty, as a missing file always was
+	}
+	cfgData, err := os.ReadFile(filepath.Join(root, cfgName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return irverify.Predicate{}, err
+	}
+	return irverify.Predicate{
+		Generator: irverify.Generator{
+			Name:      "runecho",
+			Version:   version.Canonical(version.Version),
+			IRVersion: result.Version,
+		},
+		RootHash:    result.RootHash,
+		RootHashAlg: result.RootHashAlgorithm(),
+		Config:      irverify.Subject{Name: cfgName, Digest: map[string]string{"sha256": ir.HashBytes(cfgData)}},
+		Source:      irverify.Source{Revision: rev, Path: relToTop(top, root)},
+	}, nil
+}
+
+// sourceMatchesHead checks that what result indexed is what HEAD holds: every
+// indexed file tracked and unmodified, and no tracked file deleted. Changes
+// to files the IR does not cover are no concern.
+func sourceMatchesHead(root string, result *ir.IR) error {
+	changes, err := gitutil.Changes(root)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if _, indexed := result.Files[c.Path]; indexed || c.Code[0] == 'D' || c.Code[1] == 'D' {
+			return fmt.Errorf("%s differs from HEAD (%q); commit or stash it first", c.Path, c.Code)
+		}
+	}
+	tracked, err := gitutil.TrackedFiles(root)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(result.Files))
+	for key := range result.Files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !tracked[key] {
+			return fmt.Errorf("%s is indexed but not in HEAD; commit it or ignore it", key)
+		}
+	}
+	return nil
+}
+
+func relToTop(top, root string) string {
+	// TopLevel resolves symlinks, so root is compared resolved too.
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(top, root)
+	if err != nil {
+		return "."
+	}
+	return filepath.ToSlash(rel)
+}
+
+// checkAttestation regenerates root's IR from scratch, without the object
+// store or the saved IR to reuse, and compares it with the attestation at
+// path. Signatures are runecho-verify-ir's to check; this answers whether the
+// checkout and this runecho reproduce the attested root hash.
+func checkAttestation(root string, cfg *config.Config, plugins []parser.Parser, path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return printErr(err)
+	}
+	st, err := irverify.ReadAttestation(data, nil)
+	if err != nil {
+		return printErr(fmt.Errorf("%s: %w", path, err))
+	}
+	gc := cfg.Generator(plugins)
+	gc.GenerateTimeout = cliGenerateTimeout()
+	generator := ir.NewGenerator(gc)
+	result, _, err := generator.Generate(root)
+	if err != nil {
+		return printErr(fmt.Errorf("generate IR for %q: %w", root, err))
+	}
+	got, err := attestedPredicate(root, result)
+	if err != nil {
+		return printErr(fmt.Errorf("cannot reproduce from %s: %w", root, err))
+	}
+	want := st.Predicate
+	var diffs []string
+	for _, d := range []struct{ what, want, got string }{
+		{"runecho version", want.Generator.Version, got.Generator.Version},
+		{"IR version", fmt.Sprint(want.Generator.IRVersion), fmt.Sprint(got.Generator.IRVersion)},
+		{"config digest", want.Config.Digest["sha256"], got.Config.Digest["sha256"]},
+		{"revision", want.Source.Revision, got.Source.Revision},
+		{"path in the repository", want.Source.Path, got.Source.Path},
+		{"root hash", want.RootHash, got.RootHash},
+	} {
+		if d.want != d.got {
+			diffs = append(diffs, fmt.Sprintf("%s: attested %s, here %s", d.what, d.want, d.got))
+		}
+	}
+	if len(diffs) > 0 {
+		for _, d := range diffs {
+			fmt.Fprintln(os.Stderr, d)
+		}
+		fmt.Printf("NOT REPRODUCED %s: %d differences\n", path, len(diffs))
+		return ExitNoData
+	}
+	fmt.Printf("REPRODUCED %s: root_hash %s at %s\n", path, got.RootHash, got.Source.Revision)
+	return ExitOK
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/inth3shadows/runecho/internal/config"
 	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/parser"
 )
 
 // generateTimeoutEnv is the env var that overrides the IR-generation wall-clock
@@ -78,25 +82,27 @@ func runBackup(args []string) int {
 // that fallback isn't duplicated here. Shared by runIndex and buildIR so both
 // the legacy `runecho-ir [root]` command and the central-store `repo add` /
 // `repo reindex` path get the same incremental-reuse behavior (issue #92).
-func generateIR(generator *ir.Generator, absRoot string) (*ir.IR, ir.Stats, error) {
-	irPath := filepath.Join(absRoot, ".ai", "ir.json")
-	if _, err := os.Stat(irPath); err == nil {
-		existing, loadErr := ir.Load(irPath)
-		if loadErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to load existing IR, regenerating: %v\n", loadErr)
-			return generator.Generate(absRoot)
-		}
-		if existing.Version != ir.IRVersion {
-			// An old-format IR cannot be incrementally updated: Update reuses
-			// unchanged files verbatim, which would leave fields added by newer
-			// versions (e.g. v2 refs) empty forever. Update() would already fall
-			// back to Generate() here on its own, but the warning is worth
-			// keeping visible to the caller.
-			fmt.Fprintf(os.Stderr, "IR format v%d -> v%d: full regenerate\n", existing.Version, ir.IRVersion)
-		}
-		return generator.Update(existing, absRoot)
+//
+// irPath is where the prior IR lives (see config.Config.IRLocation) — a local
+// path or a storage-backend URI; ir.Load handles both.
+func generateIR(generator *ir.Generator, absRoot, irPath string) (*ir.IR, ir.Stats, error) {
+	existing, loadErr := ir.Load(irPath)
+	if errors.Is(loadErr, fs.ErrNotExist) {
+		return generator.Generate(absRoot)
+	}
+	if loadErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load existing IR, regenerating: %v\n", loadErr)
+		return generator.Generate(absRoot)
+	}
+	if existing.Version != ir.IRVersion {
+		// An old-format IR cannot be incrementally updated: Update reuses
+		// unchanged files verbatim, which would leave fields added by newer
+		// versions (e.g. v2 refs) empty forever. Update() would already fall
+		// back to Generate() here on its own, but the warning is worth
+		// keeping visible to the caller.
+		fmt.Fprintf(os.Stderr, "IR format v%d -> v%d: full regenerate\n", existing.Version, ir.IRVersion)
 	}
-	return generator.Generate(absRoot)
+	return generator.Update(existing, absRoot)
 }
 
 // buildIR builds root's IR, incrementally reusing the prior .ai/ir.json when
@@ -142,18 +148,40 @@ func buildIR(root string, fileCap int) (*ir.IR, ir.Stats, int) {
 	if code := requireExistingDir(abs, root); code != 0 {
 		return nil, ir.Stats{}, code
 	}
-	generator := ir.NewGenerator(ir.GeneratorConfig{
-		IgnoredPaths:    ir.DefaultIgnoredPaths,
-		FileCap:         fileCap,
-		GenerateTimeout: cliGenerateTimeout(),
-	})
-	result, stats, err := generateIR(generator, abs)
+	cfg, plugins, code := repoConfig(abs)
+	if code != 0 {
+		return nil, ir.Stats{}, code
+	}
+	gc := cfg.Generator(plugins)
+	gc.FileCap = fileCap
+	gc.GenerateTimeout = cliGenerateTimeout()
+	gc.Objects = cfg.ObjectStore(abs)
+	generator := ir.NewGenerator(gc)
+	result, stats, err := generateIR(generator, abs, cfg.IRLocation(abs))
 	if err != nil {
 		return nil, ir.Stats{}, printErr(fmt.Errorf("generate IR for %q: %w", abs, err))
 	}
 	return result, stats, 0
 }
 
+// repoConfig loads root's .runecho.json and returns it with the parser plugins
+// it declares (nil when there is no config, or the exec gate is closed). A
+// malformed config is a hard error: indexing without the parsers it asked for
+// would silently drop whole languages from the IR.
+func repoConfig(root string) (*config.Config, []parser.Parser, int) {
+	cfg, err := config.Load(root)
+	if err != nil {
+		return nil, nil, printErr(err)
+	}
+	plugins, err := cfg.PluginParsers(root, func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, format, args...)
+	})
+	if err != nil {
+		return nil, nil, printErr(err)
+	}
+	return cfg, plugins, 0
+}
+
 // coverageSuffix formats " coverage=N/M (P%)" from walk stats, or "" when the
 // walk saw no supported files (nothing meaningful to report).
 func coverageSuffix(stats ir.Stats) string {
@@ -163,8 +191,30 @@ func coverageSuffix(stats ir.Stats) string {
 	return fmt.Sprintf(" coverage=%d/%d (%.0f%%)", stats.Indexed, stats.SupportedSeen, stats.Coverage())
 }
 
-// runIndex is the original runecho-ir [root] behavior.
+// loadStatCache returns absRoot's stat cache (see ir.StatCache) and where it
+// is saved. A cache that cannot be located or read is warned about and
+// replaced by an empty one: it only saves work.
+func loadStatCache(absRoot string) (*ir.StatCache, string) {
+	path, err := ir.StatCachePath(absRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: no stat cache: %v\n", err)
+		return ir.NewStatCache(), ""
+	}
+	cache, err := ir.LoadStatCache(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: starting a new stat cache: %v\n", err)
+	}
+	return cache, path
+}
+
+// runIndex is the original runecho-ir [root] behavior. Unchanged files are
+// recognized by size and mtime through the stat cache; --paranoid hashes
+// every file instead, and leaves the cache as it was.
 func runIndex(args []string) int {
+	paranoid := len(args) > 1 && args[1] == "--paranoid"
+	if paranoid {
+		args = append(args[:1:1], args[2:]...)
+	}
 	rootPath := "."
 	if len(args) > 1 {
 		if strings.HasPrefix(args[1], "-") {
@@ -189,9 +239,23 @@ func runIndex(args []string) int {
 		return code
 	}
 
-	irPath := filepath.Join(absRoot, ".ai", "ir.json")
-
-	generator := ir.NewGenerator(ir.GeneratorConfig{IgnoredPaths: ir.DefaultIgnoredPaths, GenerateTimeout: cliGenerateTimeout()})
+	cfg, plugins, code := repoConfig(absRoot)
+	if code != 0 {
+		return code
+	}
+	irPath := cfg.IRLocation(absRoot)
+	var (
+		statCache     *ir.StatCache
+		statCachePath string
+	)
+	if !paranoid {
+		statCache, statCachePath = loadStatCache(absRoot)
+	}
+	gc := cfg.Generator(plugins)
+	gc.GenerateTimeout = cliGenerateTimeout()
+	gc.Objects = cfg.ObjectStore(absRoot)
+	gc.StatCache = statCache
+	generator := ir.NewGenerator(gc)
 
 	// generateIR reads the existing ir.json for incremental reuse, then Save
 	// overwrites it — a read-modify-write that must not interleave with a
@@ -203,15 +267,21 @@ func runIndex(args []string) int {
 	exitCode := 0
 	build := func() {
 		var err error
-		result, stats, err = generateIR(generator, absRoot)
+		result, stats, err = generateIR(generator, absRoot, irPath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			exitCode = ExitError
 			return
 		}
-		if err := result.Save(irPath); err != nil {
+		if err := cfg.SaveIR(result, absRoot); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: failed to save IR: %v\n", err)
 			exitCode = ExitError
+			return
+		}
+		if statCachePath != "" {
+			if err := statCache.Save(statCachePath); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save stat cache: %v\n", err)
+			}
 		}
 	}
 	if id := enrolledRepoID(absRoot); id >= 0 {
@@ -16,6 +16,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/inth3shadows/runecho/internal/ir"
 
@@ -182,6 +183,33 @@ func TestBareRootPath_RealDirIndexes(t *testing.T) {
 	}
 }
 
+// The bare index keeps a stat cache under RUNECHO_HOME; --paranoid indexes
+// without consulting it.
+func TestBareRootPath_StatCacheAndParanoid(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(a, []byte("package a\n\nfunc F() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(a, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, stderr := runWith(t, home, []string{"runecho-ir", dir}); code != 0 {
+		t.Fatalf("index: code %d, stderr %q", code, stderr)
+	}
+	var cachePath string
+	withHome(home, func() { cachePath, _ = ir.StatCachePath(dir) })
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("no stat cache after indexing: %v", err)
+	}
+	code, out, _ := runWith(t, home, []string{"runecho-ir", "--paranoid", dir})
+	if code != 0 || !strings.Contains(out, "Indexed 1 files") {
+		t.Errorf("--paranoid index: code %d, stdout %q", code, out)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // repo add
 // ---------------------------------------------------------------------------
@@ -476,6 +504,42 @@ func TestRepoReindex_Incremental(t *testing.T) {
 	}
 }
 
+// TestRepoReindex_ConfigIRLocation: .runecho.json's "ir" moves where reindex
+// saves the IR and where the incremental path reads it back from; a URI whose
+// scheme no backend handles fails the reindex instead of writing locally.
+func TestRepoReindex_ConfigIRLocation(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	irGitInit(t, dir)
+	if err := os.WriteFile(filepath.Join(dir, ".runecho.json"), []byte(`{"ir":"build/ir.json"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, out, _ := runWith(t, home, []string{"runecho-ir", "repo", "add", dir})
+	if code != 0 {
+		t.Fatalf("repo add: %d", code)
+	}
+	name := strings.Fields(out)[1]
+	if _, err := os.Stat(filepath.Join(dir, ".ai", "ir.json")); err == nil {
+		t.Error(".ai/ir.json written despite the configured location")
+	}
+	irPath := filepath.Join(dir, "build", "ir.json")
+	poisonSymbolName(t, irPath, "stub.go", "Hello", "HelloPoisoned")
+	if code, _, _ := runWith(t, home, []string{"runecho-ir", "repo", "reindex", name}); code != 0 {
+		t.Fatalf("repo reindex: %d", code)
+	}
+	if !hasSymbolName(t, irPath, "stub.go", "HelloPoisoned") {
+		t.Error("reindex did not reuse the IR at the configured location")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".runecho.json"), []byte(`{"ir":"nosuch://bucket/ir.json"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code, _, stderr := runWith(t, home, []string{"runecho-ir", "repo", "reindex", name})
+	if code != ExitError || !strings.Contains(stderr, `no storage backend registered for scheme "nosuch"`) {
+		t.Errorf("unregistered scheme: %d %q", code, stderr)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // snapshot
 // ---------------------------------------------------------------------------
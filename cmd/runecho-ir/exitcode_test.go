@@ -53,7 +53,7 @@ func TestMap_HappyPath_Exits0(t *testing.T) {
 // version could not print.
 func TestMapHeader_PartialCoverage_Discloses(t *testing.T) {
 	out, _ := captureOutput(func() {
-		emitMapHeader(&ir.IR{}, ir.Stats{SupportedSeen: 5, Indexed: 3})
+		emitMapHeader(os.Stdout, &ir.IR{}, ir.Stats{SupportedSeen: 5, Indexed: 3})
 	})
 	if !strings.Contains(out, "coverage=3/5") {
 		t.Errorf("header must show the coverage figure 3/5; got:\n%s", out)
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// runFiles lists, without reading any of them, the files a bare `runecho-ir
+// root` would index (see ir.Generator.DryRun): the first thing to check when
+// a file is unexpectedly in or missing from the IR. --all adds each path the
+// walk leaves out, tab-separated from the reason.
+func runFiles(args []string) int {
+	fs := flag.NewFlagSet("files", flag.ContinueOnError)
+	all := fs.Bool("all", false, "also list skipped paths, with the reason")
+	asJSON := fs.Bool("json", false, "print the entries as a JSON array")
+	if code, ok := parseSub(fs, args); !ok {
+		return code
+	}
+	root, code := resolveRoot(fs.Args())
+	if code != 0 {
+		return code
+	}
+	if code := requireExistingDir(root, root); code != 0 {
+		return code
+	}
+	gen, code := shardGenerator(root)
+	if code != 0 {
+		return code
+	}
+	entries, _, err := gen.DryRun(context.Background(), root)
+	if err != nil {
+		return printErr(err)
+	}
+	shown := entries[:0:0]
+	for _, e := range entries {
+		if *all || e.Skip == "" {
+			shown = append(shown, e)
+		}
+	}
+	if *asJSON {
+		if shown == nil {
+			shown = []ir.DryRunEntry{}
+		}
+		data, err := json.MarshalIndent(shown, "", "  ")
+		if err != nil {
+			return printErr(err)
+		}
+		fmt.Println(string(data))
+		return ExitOK
+	}
+	for _, e := range shown {
+		if e.Skip == "" {
+			fmt.Println(e.Path)
+		} else {
+			fmt.Printf("%s\t%s\n", e.Path, e.Skip)
+		}
+	}
+	return ExitOK
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.go": "package a\n", "notes.xyz": "n\n", "node_modules/m.js": "m\n"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	home := t.TempDir()
+	if code, stdout, stderr := runWith(t, home, []string{"runecho-ir", "files", dir}); code != ExitOK || stdout != "a.go\n" {
+		t.Errorf("files = %d %q (stderr %q)", code, stdout, stderr)
+	}
+	if code, stdout, _ := runWith(t, home, []string{"runecho-ir", "files", "--all", dir}); code != ExitOK || stdout != "a.go\nnode_modules/\tignored\nnotes.xyz\tunsupported\n" {
+		t.Errorf("files --all = %d %q", code, stdout)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".ai")); !os.IsNotExist(err) {
+		t.Errorf("files wrote an IR: %v", err)
+	}
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
+	"github.com/inth3shadows/runecho/internal/ctxcache"
 	"github.com/inth3shadows/runecho/internal/gitutil"
 	"github.com/inth3shadows/runecho/internal/snapshot"
 	"github.com/inth3shadows/runecho/internal/store"
@@ -138,3 +141,26 @@ func printErr(err error) int {
 	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 	return ExitError
 }
+
+// serveCached writes the context bundle cached under key to stdout, or, on a
+// miss, runs produce into a buffer, caches the result if produce succeeded, and
+// writes it. key == "" bypasses the cache (--no-cache, or an output that depends
+// on more than the tree — e.g. a --since baseline that a new snapshot can move).
+func serveCached(key string, produce func(w io.Writer) int) int {
+	if key != "" {
+		if data, ok := ctxcache.Get(key); ok {
+			os.Stdout.Write(data)
+			return ExitOK
+		}
+	}
+	var buf bytes.Buffer
+	if code := produce(&buf); code != ExitOK {
+		os.Stdout.Write(buf.Bytes())
+		return code
+	}
+	if key != "" {
+		ctxcache.Put(key, buf.Bytes())
+	}
+	os.Stdout.Write(buf.Bytes())
+	return ExitOK
+}
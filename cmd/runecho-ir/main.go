@@ -16,9 +16,10 @@ const (
 	ExitError  = 2 // hard error: bad args, I/O failure, database error
 )
 
-// Usage: runecho-ir [root-path]
+// Usage: runecho-ir [--paranoid] [root-path]
 // Generates .ai/ir.json for the project at root-path (default: current directory).
-// If .ai/ir.json already exists, performs incremental update (only re-parses changed files).
+// If .ai/ir.json already exists, performs incremental update (only re-parses changed files,
+// and, unless --paranoid, only re-hashes files whose size or mtime moved).
 //
 // Subcommands:
 //
@@ -32,6 +33,14 @@ const (
 //	runecho-ir truth-trail [--since=session-start] [--session=<id>] [--text=<file>] [root]
 //	runecho-ir validate-claims --text=<file> [--ir=<path>]
 //	runecho-ir contract list|show|activate|deactivate|check
+//	runecho-ir render --template=<file> [root]
+//	runecho-ir analyze [--only=a,b] [--list] [--json] [root]
+//	runecho-ir watch [--poll=1s] [--debounce=500ms] [--rescan=1h] [--events] [--socket=<path>|--no-socket] [--listen=<addr> --tokens=<file> [--tls-cert=<pem> --tls-key=<pem>]] [root...]
+//	runecho-ir query [--socket=<path> | --addr=<host:port> [--ca=<pem>]] [--root=<path>] root_hash|ir|neighborhood <path>|rescan|projects|add <root>|remove <root>
+//	runecho-ir shard plan|gen|merge
+//	runecho-ir files [--all] [--json] [root]
+//	runecho-ir sign --key=<pem> [root]
+//	runecho-ir attest [--key=<pem>] [--check=<attestation>] [root]
 func main() {
 	os.Exit(run())
 }
@@ -89,9 +98,27 @@ func run() int {
 			return runValidateClaims(os.Args[2:])
 		case "contract":
 			return runContract(os.Args[2:])
+		case "render":
+			return runRender(os.Args[2:])
+		case "analyze":
+			return runAnalyze(os.Args[2:])
+		case "watch":
+			return runWatch(os.Args[2:])
+		case "query":
+			return runQuery(os.Args[2:])
+		case "shard":
+			return runShard(os.Args[2:])
+		case "files":
+			return runFiles(os.Args[2:])
+		case "sign":
+			return runSign(os.Args[2:])
+		case "attest":
+			return runAttest(os.Args[2:])
 		case "--help", "-h", "help":
 			printUsage()
 			return 0
+		case "--paranoid":
+			return runIndex(os.Args)
 		case "--version", "-v":
 			fmt.Println("runecho-ir " + version.Version)
 			return 0
@@ -108,10 +135,10 @@ func run() int {
 }
 
 func printUsage() {
-	fmt.Fprintln(os.Stderr, "Usage: runecho-ir [root-path]")
+	fmt.Fprintln(os.Stderr, "Usage: runecho-ir [--paranoid] [root-path]")
 	fmt.Fprintln(os.Stderr, "       runecho-ir snapshot [--label=manual] [--session=<id>] [root]")
-	fmt.Fprintln(os.Stderr, "       runecho-ir diff [--since=<label>] [--compact] [--json] [root]")
-	fmt.Fprintln(os.Stderr, "       runecho-ir map [--by-file] [--kind=func|class|export|import] [--dir=<p>] [--since=<label>] [--compact] [--json] [root]")
+	fmt.Fprintln(os.Stderr, "       runecho-ir diff [--since=<label>] [--compact] [--format=<name>|--json] [root]")
+	fmt.Fprintln(os.Stderr, "       runecho-ir map [--by-file] [--kind=func|class|export|import] [--dir=<p>] [--since=<label>] [--compact] [--format=<name>|--json] [root]")
 	fmt.Fprintln(os.Stderr, "       runecho-ir log [--n=10] [root]")
 	fmt.Fprintln(os.Stderr, "       runecho-ir verify [--session=<id>] [root]")
 	fmt.Fprintln(os.Stderr, "       runecho-ir churn [--n=20] [--min-changes=2] [--compact] [--json] [root]")
@@ -126,4 +153,12 @@ func printUsage() {
 	fmt.Fprintln(os.Stderr, "       runecho-ir validate-claims --text=<file> [--ir=<path>]")
 	fmt.Fprintln(os.Stderr, "       runecho-ir contract list | show <name> | activate --session=<id> <name> | deactivate --session=<id>")
 	fmt.Fprintln(os.Stderr, "       runecho-ir contract check [--contract=<name>|--session=<id>] [--base=<ref>] [--dir=<p>]")
+	fmt.Fprintln(os.Stderr, "       runecho-ir render --template=<file> [root]")
+	fmt.Fprintln(os.Stderr, "       runecho-ir analyze [--only=a,b] [--list] [--json] [root]")
+	fmt.Fprintln(os.Stderr, "       runecho-ir watch [--poll=1s] [--debounce=500ms] [--rescan=1h] [--events] [--socket=<path>|--no-socket] [--listen=<addr> --tokens=<file> [--tls-cert=<pem> --tls-key=<pem>]] [root...]")
+	fmt.Fprintln(os.Stderr, "       runecho-ir query [--socket=<path> | --addr=<host:port> [--ca=<pem>]] [--root=<path>] root_hash | ir | neighborhood <path> | rescan | projects | add <root> | remove <root>")
+	fmt.Fprintln(os.Stderr, "       runecho-ir shard plan [--shards=N] [root] | gen --plan=<file> --index=<i> --out=<file> [root] | merge [--out=<path>] [--root=<path>] <shard>...")
+	fmt.Fprintln(os.Stderr, "       runecho-ir files [--all] [--json] [root]")
+	fmt.Fprintln(os.Stderr, "       runecho-ir sign --key=<pem> [root]")
+	fmt.Fprintln(os.Stderr, "       runecho-ir attest [--key=<pem>] [--check=<attestation>] [root]")
 }
@@ -1,36 +1,20 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
-	"text/tabwriter"
 
+	"github.com/inth3shadows/runecho/internal/ctxcache"
 	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/render"
 	"github.com/inth3shadows/runecho/internal/snapshot"
 )
 
-// mapSym is one symbol's location for the repo map: a deterministic projection
-// of FileIR (name, kind, file, 1-based line, short body hash). No prose, no
-// ranking — just where each indexed symbol lives.
-type mapSym struct {
-	Name string `json:"name"`
-	Kind string `json:"kind"` // function | class | export | import
-	File string `json:"file"`
-	Line int    `json:"line"` // 1-based; 0 = unknown (parser had no span / pre-v4 index)
-	Hash string `json:"hash,omitempty"`
-}
-
-var kindAbbrev = map[string]string{
-	"function": "fn",
-	"class":    "cls",
-	"export":   "exp",
-	"import":   "imp",
-}
-
 // normalizeKind maps user-facing --kind values to internal kind names.
 func normalizeKind(k string) (string, bool) {
 	switch k {
@@ -62,7 +46,9 @@ func runMap(args []string) int {
 	sessionID := fs.String("session", "", "filter --since by session ID")
 	compact := fs.Bool("compact", false, "terser output (omit the hash column)")
 	header := fs.Bool("header", false, "print a <200-token repo summary for session-start injection, not the full map")
-	asJSON := fs.Bool("json", false, "machine-readable JSON")
+	format := fs.String("format", "text", "output format: "+strings.Join(render.Names(), "|"))
+	asJSON := fs.Bool("json", false, "machine-readable JSON (same as --format=json)")
+	noCache := fs.Bool("no-cache", false, "always regenerate; skip the RootHash-keyed context cache")
 	// ContinueOnError + parseSub (not ExitOnError) so a bad flag returns through
 	// the testable run() seam instead of calling os.Exit — consistent with every
 	// other subcommand and a prerequisite for integration-testing `map`.
@@ -82,6 +68,19 @@ func runMap(args []string) int {
 		}
 	})
 
+	if *asJSON {
+		if *format != "text" && *format != "json" {
+			fmt.Fprintf(os.Stderr, "--json conflicts with --format=%s\n", *format)
+			return ExitError
+		}
+		*format = "json"
+	}
+	renderer, ok := render.Lookup(*format)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown --format %q (want %s)\n", *format, strings.Join(render.Names(), "|"))
+		return ExitError
+	}
+
 	kind, ok := normalizeKind(*kindFlag)
 	if !ok {
 		fmt.Fprintf(os.Stderr, "Invalid --kind %q (want func|class|export|import)\n", *kindFlag)
@@ -106,35 +105,48 @@ func runMap(args []string) int {
 		return irCode
 	}
 
-	// --header: a tiny deterministic summary for session-start injection. Bypasses
-	// the per-symbol rendering entirely — the point is to NOT spend context on the
-	// full map up front, just to tell the agent the map exists and how to query it.
-	if *header {
-		emitMapHeader(irData, stats)
-		return 0
+	// An unchanged tree (same RootHash) with the same flags renders the same map,
+	// so a repeat session is served the cached bytes. --since output also depends
+	// on which snapshot the label currently names, so it is never cached.
+	key := ""
+	if !*noCache && !sinceProvided {
+		key = ctxcache.Key(irData.RootHash, "map", root,
+			strconv.FormatBool(*header), strconv.FormatBool(*byFile), kind, *dirPrefix,
+			strconv.FormatBool(*compact), *format,
+			fmt.Sprintf("%d/%d", stats.Indexed, stats.SupportedSeen))
 	}
 
-	// --since: compute the changed-symbol set (added ∪ modified) from a diff.
-	var changed map[string]map[string]bool
-	if sinceProvided {
-		set, code := changedSymbols(db, root, *since, *sessionID, irData)
-		if code != 0 {
-			return code
+	return serveCached(key, func(out io.Writer) int {
+		// --header: a tiny deterministic summary for session-start injection. Bypasses
+		// the per-symbol rendering entirely — the point is to NOT spend context on the
+		// full map up front, just to tell the agent the map exists and how to query it.
+		if *header {
+			emitMapHeader(out, irData, stats)
+			return 0
 		}
-		changed = set
-	}
 
-	syms := collectMapSymbols(irData, kind, *dirPrefix, changed)
+		// --since: compute the changed-symbol set (added ∪ modified) from a diff.
+		var changed map[string]map[string]bool
+		if sinceProvided {
+			set, code := changedSymbols(db, root, *since, *sessionID, irData)
+			if code != 0 {
+				return code
+			}
+			changed = set
+		}
 
-	if *asJSON {
-		return emitMapJSON(root, syms, *byFile, sinceProvided)
-	}
-	if *byFile {
-		emitMapByFile(syms, *compact)
-	} else {
-		emitMapBySymbol(syms, *compact)
-	}
-	return 0
+		m := &render.Map{
+			Root:        root,
+			ChangedOnly: sinceProvided,
+			ByFile:      *byFile,
+			Compact:     *compact,
+			Symbols:     collectMapSymbols(irData, kind, *dirPrefix, changed),
+		}
+		if err := renderer.Render(out, m); err != nil {
+			return printErr(fmt.Errorf("render %s: %w", *format, err))
+		}
+		return 0
+	})
 }
 
 // changedSymbols resolves the --since baseline and returns file → "kind:name" →
@@ -181,11 +193,11 @@ func changedSymbols(db *snapshot.DB, root, since, sessionID string, irData *ir.I
 	return changed, 0
 }
 
-// collectMapSymbols projects the IR into a sorted []mapSym (via the shared
+// collectMapSymbols projects the IR into a sorted []render.Symbol (via the shared
 // ir.SymbolLocations), applying the kind, dir, and changed-set filters. Default
 // kinds are function+class (the navigable definitions); export/import are
 // included only when explicitly requested via --kind.
-func collectMapSymbols(irData *ir.IR, kind, dirPrefix string, changed map[string]map[string]bool) []mapSym {
+func collectMapSymbols(irData *ir.IR, kind, dirPrefix string, changed map[string]map[string]bool) []render.Symbol {
 	keep := func(k string) bool {
 		if kind != "" {
 			return k == kind
@@ -193,7 +205,7 @@ func collectMapSymbols(irData *ir.IR, kind, dirPrefix string, changed map[string
 		return k == "function" || k == "class"
 	}
 
-	var syms []mapSym
+	var syms []render.Symbol
 	for _, s := range irData.SymbolLocations() { // already deterministically sorted
 		if !keep(s.Kind) {
 			continue
@@ -204,7 +216,7 @@ func collectMapSymbols(irData *ir.IR, kind, dirPrefix string, changed map[string
 		if changed != nil && !changed[s.File][s.Kind+":"+s.Name] {
 			continue
 		}
-		syms = append(syms, mapSym{
+		syms = append(syms, render.Symbol{
 			Name: s.Name,
 			Kind: s.Kind,
 			File: s.File,
@@ -222,7 +234,7 @@ func collectMapSymbols(irData *ir.IR, kind, dirPrefix string, changed map[string
 // the walk's coverage so a PARTIAL index (parse errors or file-cap truncation) is
 // disclosed rather than presented as a complete map — otherwise an agent treating
 // runecho as code-truth reads a symbol's absence as definitive when it isn't.
-func emitMapHeader(irData *ir.IR, stats ir.Stats) {
+func emitMapHeader(out io.Writer, irData *ir.IR, stats ir.Stats) {
 	funcs, classes := 0, 0
 	dirFiles := make(map[string]int)
 	for path, f := range irData.Files {
@@ -261,18 +273,18 @@ func emitMapHeader(irData *ir.IR, stats ir.Stats) {
 		top = append(top, dirs[i].dir)
 	}
 
-	fmt.Printf("runecho map: %d files, %d functions, %d classes.%s\n", len(irData.Files), funcs, classes, coverageSuffix(stats))
+	fmt.Fprintf(out, "runecho map: %d files, %d functions, %d classes.%s\n", len(irData.Files), funcs, classes, coverageSuffix(stats))
 	if missed := stats.SupportedSeen - stats.Indexed; missed > 0 {
 		// Partial coverage: `missed` supported-language files failed to parse or
 		// were truncated by the file cap and are NOT in the map. Only printed on a
 		// shortfall, so the full-coverage common case stays terse and within budget.
-		fmt.Printf("Coverage is PARTIAL — %d supported file(s) not indexed; a symbol missing from the map may still exist (reindex or read the file).\n", missed)
+		fmt.Fprintf(out, "Coverage is PARTIAL — %d supported file(s) not indexed; a symbol missing from the map may still exist (reindex or read the file).\n", missed)
 	}
 	if len(top) > 0 {
-		fmt.Printf("Busiest: %s\n", strings.Join(top, " "))
+		fmt.Fprintf(out, "Busiest: %s\n", strings.Join(top, " "))
 	}
-	fmt.Println("To locate a definition, query the map instead of grepping: " +
-		"`runecho-ir map --kind=func --dir=<p>` or the runecho MCP `locate` tool " +
+	fmt.Fprintln(out, "To locate a definition, query the map instead of grepping: "+
+		"`runecho-ir map --kind=func --dir=<p>` or the runecho MCP `locate` tool "+
 		"(symbol → file:line, deterministic).")
 }
 
@@ -284,89 +296,3 @@ func shortSym(h string) string {
 	}
 	return h
 }
-
-func lineStr(line int) string {
-	if line <= 0 {
-		return "?"
-	}
-	return fmt.Sprintf("%d", line)
-}
-
-// emitMapBySymbol renders the flat symbol index: name, kind, file:line, hash.
-func emitMapBySymbol(syms []mapSym, compact bool) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	for _, s := range syms {
-		loc := s.File + ":" + lineStr(s.Line)
-		if compact {
-			fmt.Fprintf(w, "%s\t%s\n", s.Name, loc)
-			continue
-		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, kindAbbrev[s.Kind], loc, s.Hash)
-	}
-	w.Flush()
-}
-
-// emitMapByFile groups symbols under their file, sorted by line then name.
-func emitMapByFile(syms []mapSym, compact bool) {
-	byFile := make(map[string][]mapSym)
-	var files []string
-	for _, s := range syms {
-		if _, seen := byFile[s.File]; !seen {
-			files = append(files, s.File)
-		}
-		byFile[s.File] = append(byFile[s.File], s)
-	}
-	sort.Strings(files)
-
-	for _, file := range files {
-		group := byFile[file]
-		sort.Slice(group, func(i, j int) bool {
-			if group[i].Line != group[j].Line {
-				return group[i].Line < group[j].Line
-			}
-			return group[i].Name < group[j].Name
-		})
-		fmt.Println(file)
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		for _, s := range group {
-			if compact {
-				fmt.Fprintf(w, "  \t%s\t%s\n", s.Name, lineStr(s.Line))
-				continue
-			}
-			fmt.Fprintf(w, "  \t%s\t%s:%s\t%s\n", kindAbbrev[s.Kind], s.Name, lineStr(s.Line), s.Hash)
-		}
-		w.Flush()
-	}
-}
-
-// emitMapJSON marshals the canonical machine shape: mode tells the consumer
-// which key to read without introspecting (parity with diff --json).
-func emitMapJSON(root string, syms []mapSym, byFile, changedOnly bool) int {
-	payload := map[string]interface{}{
-		"root":         root,
-		"changed_only": changedOnly,
-		"count":        len(syms),
-	}
-	if byFile {
-		payload["mode"] = "files"
-		// files is a JSON object keyed by path (always {} when empty, never null).
-		files := make(map[string][]mapSym)
-		for _, s := range syms {
-			files[s.File] = append(files[s.File], s)
-		}
-		payload["files"] = files
-	} else {
-		payload["mode"] = "symbols"
-		if syms == nil {
-			syms = []mapSym{}
-		}
-		payload["symbols"] = syms
-	}
-	out, err := json.MarshalIndent(payload, "", "  ")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return ExitError
-	}
-	fmt.Println(string(out))
-	return 0
-}
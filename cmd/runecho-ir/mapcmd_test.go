@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/inth3shadows/runecho/internal/ir"
@@ -103,7 +104,7 @@ func TestCollectMapSymbols_ChangedFilter(t *testing.T) {
 	}
 }
 
-func TestShortSymAndLineStr(t *testing.T) {
+func TestShortSym(t *testing.T) {
 	if shortSym("abcdef") != "abcd" {
 		t.Error("shortSym should truncate to 4")
 	}
@@ -113,10 +114,28 @@ func TestShortSymAndLineStr(t *testing.T) {
 	if shortSym("") != "" {
 		t.Error("shortSym empty should stay empty")
 	}
-	if lineStr(0) != "?" || lineStr(-1) != "?" {
-		t.Error("lineStr(<=0) should be ?")
+}
+
+// TestMap_Format: --format picks a registered renderer (--json is an alias for
+// the json one), and an unknown name is a usage error that lists the formats.
+func TestMap_Format(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	irGitInit(t, dir)
+
+	_, viaFlag, _ := runWith(t, home, []string{"runecho-ir", "map", "--format=json", dir})
+	_, viaAlias, _ := runWith(t, home, []string{"runecho-ir", "map", "--json", dir})
+	if !strings.Contains(viaFlag, `"mode": "symbols"`) || viaFlag != viaAlias {
+		t.Errorf("--format=json = %q, --json = %q", viaFlag, viaAlias)
+	}
+	if code, out, _ := runWith(t, home, []string{"runecho-ir", "map", "--format=text", dir}); code != ExitOK || !strings.HasPrefix(out, "Hello") {
+		t.Errorf("--format=text = %d %q", code, out)
+	}
+	code, _, stderr := runWith(t, home, []string{"runecho-ir", "map", "--format=org", dir})
+	if code != ExitError || !strings.Contains(stderr, "json|text") {
+		t.Errorf("unknown format = %d %q", code, stderr)
 	}
-	if lineStr(42) != "42" {
-		t.Error("lineStr(42) should be 42")
+	if code, _, _ := runWith(t, home, []string{"runecho-ir", "map", "--json", "--format=text", dir}); code != ExitOK {
+		t.Errorf("--json with the default --format should be accepted, got %d", code)
 	}
 }
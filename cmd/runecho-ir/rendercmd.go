@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/inth3shadows/runecho/internal/ctxcache"
+	"github.com/inth3shadows/runecho/internal/prompt"
+)
+
+// runRender renders a user-defined text/template against a fresh IR of root and
+// writes the result to stdout. The template sees the IR only through the
+// internal/prompt helper funcs, so the output is a pure function of the code and
+// the template text — which is exactly what the context-cache key covers.
+func runRender(args []string) int {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	tmplPath := fs.String("template", "", "path to a text/template file (required)")
+	noCache := fs.Bool("no-cache", false, "always regenerate; skip the RootHash-keyed context cache")
+	if code, ok := parseSub(fs, args); !ok {
+		return code
+	}
+	if *tmplPath == "" {
+		fmt.Fprintln(os.Stderr, "runecho-ir render: --template is required")
+		return ExitError
+	}
+
+	root, code := resolveRoot(fs.Args())
+	if code != 0 {
+		return code
+	}
+	text, err := prompt.ReadTemplate(*tmplPath)
+	if err != nil {
+		return printErr(err)
+	}
+	irData, _, irCode := buildIR(root, 0)
+	if irCode != 0 {
+		return irCode
+	}
+
+	key := ""
+	if !*noCache {
+		key = ctxcache.Key(irData.RootHash, "render", text)
+	}
+	return serveCached(key, func(out io.Writer) int {
+		if err := prompt.Render(out, *tmplPath, text, irData); err != nil {
+			return printErr(err)
+		}
+		return ExitOK
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func ctxcacheEntries(t *testing.T, home string) int {
+	t.Helper()
+	ents, err := os.ReadDir(filepath.Join(home, "ctxcache"))
+	if err != nil {
+		return 0
+	}
+	return len(ents)
+}
+
+// TestRender_CachedByRootHash: a repeat render of an unchanged tree is served
+// from the context cache (no new entry, identical bytes); an edit changes the
+// RootHash and renders afresh; --no-cache neither reads nor writes.
+func TestRender_CachedByRootHash(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	irGitInit(t, dir)
+	tmpl := filepath.Join(t.TempDir(), "ctx.tmpl")
+	os.WriteFile(tmpl, []byte(`{{range files}}{{.}}: {{join "," (functionsOf .)}}{{"\n"}}{{end}}`), 0o644)
+
+	code, first, stderr := runWith(t, home, []string{"runecho-ir", "render", "--template=" + tmpl, dir})
+	if code != ExitOK || first != "stub.go: Hello\n" {
+		t.Fatalf("render = %d %q (stderr %q)", code, first, stderr)
+	}
+	if n := ctxcacheEntries(t, home); n != 1 {
+		t.Fatalf("cache entries after first render = %d, want 1", n)
+	}
+	if _, again, _ := runWith(t, home, []string{"runecho-ir", "render", "--template=" + tmpl, dir}); again != first {
+		t.Errorf("cached render = %q, want %q", again, first)
+	}
+	if n := ctxcacheEntries(t, home); n != 1 {
+		t.Errorf("cache entries after repeat = %d, want 1 (hit, not a rewrite)", n)
+	}
+
+	os.WriteFile(filepath.Join(dir, "stub.go"), []byte("package stub\n\nfunc Bye() {}\n"), 0o644)
+	if _, edited, _ := runWith(t, home, []string{"runecho-ir", "render", "--template=" + tmpl, dir}); edited != "stub.go: Bye\n" {
+		t.Errorf("render after edit = %q, want the new tree", edited)
+	}
+	runWith(t, home, []string{"runecho-ir", "render", "--no-cache", "--template=" + tmpl, dir})
+	if n := ctxcacheEntries(t, home); n != 2 {
+		t.Errorf("cache entries = %d, want 2 (edit adds one, --no-cache none)", n)
+	}
+}
+
+func TestRender_MissingTemplateFlag_Exits2(t *testing.T) {
+	code, _, stderr := runWith(t, t.TempDir(), []string{"runecho-ir", "render", t.TempDir()})
+	if code != ExitError || !strings.Contains(stderr, "--template is required") {
+		t.Errorf("got %d %q, want ExitError with a --template hint", code, stderr)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/inth3shadows/runecho/internal/config"
 	"github.com/inth3shadows/runecho/internal/gitutil"
 	"github.com/inth3shadows/runecho/internal/snapshot"
 )
@@ -277,7 +278,12 @@ func doReindex(db *snapshot.DB, repo *snapshot.Repo) int {
 			exitCode = code
 			return
 		}
-		if err := irData.Save(filepath.Join(srcRoot, ".ai", "ir.json")); err != nil {
+		cfg, err := config.Load(srcRoot)
+		if err != nil {
+			exitCode = printErr(err)
+			return
+		}
+		if err := cfg.SaveIR(irData, srcRoot); err != nil {
 			exitCode = printErr(fmt.Errorf("save ir.json: %w", err))
 			return
 		}
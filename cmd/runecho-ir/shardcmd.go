@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/store"
+)
+
+// runShard splits one Generate across machines (see ir.ShardPlan). The
+// coordinator runs `shard plan` and hands the plan to every worker; each
+// runs `shard gen` on its own checkout of the same revision; the coordinator
+// collects the shard files and runs `shard merge`. Moving the files between
+// machines is left to whatever already runs the workers (CI artifacts, a
+// shared volume).
+func runShard(args []string) int {
+	const usage = "Usage: runecho-ir shard plan [--shards=N] [root] | gen --plan=<file> --index=<i> --out=<file> [root] | merge [--out=<path>] [--root=<path>] <shard>..."
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return ExitError
+	}
+	switch args[0] {
+	case "plan":
+		return runShardPlan(args[1:])
+	case "gen":
+		return runShardGen(args[1:])
+	case "merge":
+		return runShardMerge(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "runecho-ir shard: unknown subcommand %q\n%s\n", args[0], usage)
+		return ExitError
+	}
+}
+
+// shardGenerator is the Generator a bare `runecho-ir root` would use, which
+// every worker and the planner must share for the merge to match it.
+func shardGenerator(root string) (*ir.Generator, int) {
+	cfg, plugins, code := repoConfig(root)
+	if code != 0 {
+		return nil, code
+	}
+	gc := cfg.Generator(plugins)
+	gc.GenerateTimeout = cliGenerateTimeout()
+	gc.Objects = cfg.ObjectStore(root)
+	return ir.NewGenerator(gc), 0
+}
+
+// runShardPlan prints a plan for --shards workers as JSON.
+func runShardPlan(args []string) int {
+	fs := flag.NewFlagSet("shard plan", flag.ContinueOnError)
+	n := fs.Int("shards", 2, "number of workers")
+	if code, ok := parseSub(fs, args); !ok {
+		return code
+	}
+	root, code := resolveRoot(fs.Args())
+	if code != 0 {
+		return code
+	}
+	if code := requireExistingDir(root, root); code != 0 {
+		return code
+	}
+	gen, code := shardGenerator(root)
+	if code != 0 {
+		return code
+	}
+	plan, err := gen.PlanShards(context.Background(), root, *n)
+	if err != nil {
+		return printErr(err)
+	}
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return printErr(err)
+	}
+	fmt.Println(string(data))
+	return ExitOK
+}
+
+// runShardGen builds worker --index's share of root under --plan and writes
+// it to --out.
+func runShardGen(args []string) int {
+	fs := flag.NewFlagSet("shard gen", flag.ContinueOnError)
+	planPath := fs.String("plan", "", "plan file from `runecho-ir shard plan`")
+	index := fs.Int("index", -1, "this worker's shard index")
+	out := fs.String("out", "", "file to write the shard to")
+	if code, ok := parseSub(fs, args); !ok {
+		return code
+	}
+	if *planPath == "" || *index < 0 || *out == "" {
+		fmt.Fprintln(os.Stderr, "Usage: runecho-ir shard gen --plan=<file> --index=<i> --out=<file> [root]")
+		return ExitError
+	}
+	root, code := resolveRoot(fs.Args())
+	if code != 0 {
+		return code
+	}
+	if code := requireExistingDir(root, root); code != 0 {
+		return code
+	}
+	var plan ir.ShardPlan
+	if err := readJSON(*planPath, &plan); err != nil {
+		return printErr(err)
+	}
+	gen, code := shardGenerator(root)
+	if code != 0 {
+		return code
+	}
+	sh, err := gen.GenerateShard(context.Background(), root, plan, *index)
+	if err != nil {
+		return printErr(err)
+	}
+	data, err := json.Marshal(sh)
+	if err != nil {
+		return printErr(err)
+	}
+	if err := store.AtomicWriteFile(*out, data); err != nil {
+		return printErr(err)
+	}
+	fmt.Printf("Shard %d of %d: indexed %d files%s\n", *index, len(plan.Shards), len(sh.IR.Files), coverageSuffix(sh.Stats))
+	return ExitOK
+}
+
+// runShardMerge joins the shard files into root's IR, or --out.
+func runShardMerge(args []string) int {
+	fs := flag.NewFlagSet("shard merge", flag.ContinueOnError)
+	out := fs.String("out", "", "where to save the IR (default: the IR location of --root)")
+	rootFlag := fs.String("root", ".", "repo whose IR location --out defaults to")
+	if code, ok := parseSub(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: runecho-ir shard merge [--out=<path>] [--root=<path>] <shard>...")
+		return ExitError
+	}
+	var shards []*ir.ShardIR
+	for _, path := range fs.Args() {
+		var sh ir.ShardIR
+		if err := readJSON(path, &sh); err != nil {
+			return printErr(err)
+		}
+		shards = append(shards, &sh)
+	}
+	merged, stats, err := ir.MergeShards(shards)
+	if err != nil {
+		return printErr(err)
+	}
+	save := func() error { return merged.Save(*out) }
+	if *out == "" {
+		root, code := resolveRoot([]string{*rootFlag})
+		if code != 0 {
+			return code
+		}
+		cfg, _, code := repoConfig(root)
+		if code != 0 {
+			return code
+		}
+		save = func() error { return cfg.SaveIR(merged, root) }
+	}
+	if err := save(); err != nil {
+		return printErr(fmt.Errorf("failed to save IR: %w", err))
+	}
+	shortHash := merged.RootHash
+	if len(shortHash) > 12 {
+		shortHash = shortHash[:12]
+	}
+	fmt.Printf("Merged %d shards: %d files — root_hash: %s...%s\n", len(shards), len(merged.Files), shortHash, coverageSuffix(stats))
+	return ExitOK
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
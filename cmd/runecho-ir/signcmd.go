@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/irverify"
+	"github.com/inth3shadows/runecho/internal/store"
+)
+
+// runSign writes a detached signature for root's saved IR, which
+// runecho-verify-ir --pubkey checks (see internal/irverify). Any later save of
+// the IR, by the hook or a reindex, leaves the signature stale, so sign where
+// the IR is published — CI, after the build — not in a working tree.
+func runSign(args []string) int {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	keyPath := fs.String("key", "", "Ed25519 private key (PKCS #8 PEM)")
+	if code, ok := parseSub(fs, args); !ok {
+		return code
+	}
+	if *keyPath == "" {
+		fmt.Fprintln(os.Stderr, "Usage: runecho-ir sign --key=<pem> [root]")
+		return ExitError
+	}
+	root, code := resolveRoot(fs.Args())
+	if code != 0 {
+		return code
+	}
+	cfg, _, code := repoConfig(root)
+	if code != 0 {
+		return code
+	}
+	irPath := cfg.IRLocation(root)
+	if ir.IsStorageURI(irPath) {
+		return printErr(fmt.Errorf("cannot sign %s: only a local IR file can be signed", irPath))
+	}
+	keyData, err := os.ReadFile(*keyPath)
+	if err != nil {
+		return printErr(err)
+	}
+	key, err := irverify.ParsePrivateKey(keyData)
+	if err != nil {
+		return printErr(err)
+	}
+	// Vouch only for an IR the verifier would accept.
+	r, err := irverify.Verify(irPath, irverify.Options{})
+	if err != nil {
+		return printErr(err)
+	}
+	if len(r.Problems) > 0 {
+		return printErr(fmt.Errorf("refusing to sign %s: %s", irPath, r.Problems[0]))
+	}
+	data, err := os.ReadFile(irPath)
+	if err != nil {
+		return printErr(err)
+	}
+	sigPath := irverify.SignatureFile(irPath)
+	if err := store.AtomicWriteFile(sigPath, irverify.Sign(data, key)); err != nil {
+		return printErr(err)
+	}
+	fmt.Printf("Signed %s: %s\n", irPath, sigPath)
+	return ExitOK
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/inth3shadows/runecho/internal/config"
+	"github.com/inth3shadows/runecho/internal/daemon"
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/watch"
+)
+
+// runWatch keeps each root's IR file current until interrupted: one Update
+// per burst of changes, saved only once the tree has gone quiet (see
+// internal/watch). The saved IR is always a whole snapshot, so a reader of the
+// file never sees a branch switch half applied. Every root is its own project
+// with its own config and IR; more can be added to the running daemon with
+// `runecho-ir query add`. Unless --no-socket is given, the snapshots are
+// served on the control socket (see internal/daemon) for `runecho-ir query`
+// and editor plugins.
+func runWatch(args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	poll := fs.Duration("poll", watch.DefaultPoll, "how often to check the tree for changes")
+	debounce := fs.Duration("debounce", watch.DefaultDebounce, "how long the tree must stay unchanged before an update")
+	rescan := fs.Duration("rescan", watch.DefaultRescan, "how often to rescan every file for changes the poll missed (0 to disable)")
+	events := fs.Bool("events", false, "also react to the OS's file-change events, without waiting for the next poll")
+	socket := fs.String("socket", "", "control socket path (default $RUNECHO_HOME/daemon.sock)")
+	noSocket := fs.Bool("no-socket", false, "do not serve the control socket")
+	listen := fs.String("listen", "", "also serve tenants over TCP at this address (needs --tokens)")
+	tokens := fs.String("tokens", "", "tenants file: bearer-token digests, project access, and limits")
+	tlsCert := fs.String("tls-cert", "", "TLS certificate (PEM) for --listen; required beyond loopback")
+	tlsKey := fs.String("tls-key", "", "TLS private key (PEM) for --listen")
+	if code, ok := parseSub(fs, args); !ok {
+		return code
+	}
+	tenantLn, tenants, code := listenTenants(*listen, *tokens, *tlsCert, *tlsKey)
+	if code != 0 {
+		return code
+	}
+	if tenantLn != nil {
+		defer tenantLn.Close()
+	}
+	rootArgs := fs.Args()
+	if len(rootArgs) == 0 {
+		rootArgs = []string{"."}
+	}
+	var roots []string
+	for _, arg := range rootArgs {
+		root, code := resolveRoot([]string{arg})
+		if code != 0 {
+			return code
+		}
+		if code := requireExistingDir(root, arg); code != 0 {
+			return code
+		}
+		roots = append(roots, root)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv := daemon.NewServer(ctx, func(ctx context.Context, root string) (*watch.Watcher, error) {
+		return openWatched(ctx, root, *poll, *debounce, *rescan, *events)
+	})
+	defer srv.Wait()
+	for _, root := range roots {
+		if _, err := srv.Add(root); err != nil {
+			stop()
+			return printErr(err)
+		}
+		fmt.Fprintf(os.Stderr, "Watching %s\n", root)
+	}
+	if !*noSocket {
+		path := *socket
+		if path == "" {
+			var err error
+			if path, err = daemon.DefaultSocketPath(); err != nil {
+				stop()
+				return printErr(err)
+			}
+		}
+		ln, err := daemon.Listen(path)
+		if err != nil {
+			stop()
+			return printErr(err)
+		}
+		defer os.Remove(path)
+		go srv.Serve(ctx, ln)
+		fmt.Fprintf(os.Stderr, "Serving %s\n", path)
+	}
+	if tenantLn != nil {
+		go srv.ServeTenants(ctx, tenantLn, tenants)
+		fmt.Fprintf(os.Stderr, "Serving tenants on %s\n", tenantLn.Addr())
+	}
+	fmt.Fprintln(os.Stderr, "Ctrl-C to stop")
+	<-ctx.Done()
+	// Restore default signal handling, so a second Ctrl-C kills a shutdown
+	// stuck behind a long Update instead of being swallowed.
+	stop()
+	fmt.Fprintln(os.Stderr, "Shutting down: finishing in-flight updates")
+	return ExitOK
+}
+
+// listenTenants opens watch's --listen listener, or nothing when addr is
+// empty. It refuses to listen without a tenants file, and beyond loopback
+// without TLS: the tokens would cross the network in the clear.
+func listenTenants(addr, tokensPath, certPath, keyPath string) (net.Listener, *daemon.Tenants, int) {
+	if addr == "" {
+		if tokensPath != "" || certPath != "" || keyPath != "" {
+			fmt.Fprintln(os.Stderr, "Error: --tokens, --tls-cert, and --tls-key need --listen")
+			return nil, nil, ExitError
+		}
+		return nil, nil, 0
+	}
+	if tokensPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: --listen needs --tokens: a TCP listener serves only token holders")
+		return nil, nil, ExitError
+	}
+	if (certPath == "") != (keyPath == "") {
+		fmt.Fprintln(os.Stderr, "Error: --tls-cert and --tls-key go together")
+		return nil, nil, ExitError
+	}
+	if certPath == "" && !isLoopback(addr) {
+		fmt.Fprintf(os.Stderr, "Error: --listen=%s reaches beyond this machine; serve it with --tls-cert and --tls-key\n", addr)
+		return nil, nil, ExitError
+	}
+	tenants, err := daemon.LoadTenants(tokensPath)
+	if err != nil {
+		return nil, nil, printErr(err)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, printErr(err)
+	}
+	if certPath != "" {
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			ln.Close()
+			return nil, nil, printErr(err)
+		}
+		ln = tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12})
+	}
+	return ln, tenants, 0
+}
+
+// isLoopback reports whether the listen address addr binds only loopback.
+// An empty host binds every interface.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// openWatched builds root's Watcher the way a bare `runecho-ir root` would
+// index it — its own .runecho.json, plugins, and IR location — seeded from
+// the saved IR when that is of the current format. Each publish is saved.
+func openWatched(ctx context.Context, root string, poll, debounce, rescan time.Duration, events bool) (*watch.Watcher, error) {
+	cfg, err := config.Load(root)
+	if err != nil {
+		return nil, err
+	}
+	warn := func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, format, args...)
+	}
+	plugins, err := cfg.PluginParsers(root, warn)
+	if err != nil {
+		return nil, err
+	}
+	irPath := cfg.IRLocation(root)
+	gc := cfg.Generator(plugins)
+	gc.GenerateTimeout = cliGenerateTimeout()
+	gc.Objects = cfg.ObjectStore(root)
+	generator := ir.NewGenerator(gc)
+
+	// A saved IR of the current format seeds the first build incrementally;
+	// anything else (missing, unreadable, old) just means a full Generate.
+	var seed *ir.IR
+	if existing, err := ir.Load(irPath); err == nil && existing.Version == ir.IRVersion {
+		seed = existing
+	}
+	wcfg := watch.Config{
+		Root:      root,
+		Generator: generator,
+		Seed:      seed,
+		Poll:      poll,
+		Debounce:  debounce,
+		Rescan:    rescan,
+		Events:    events,
+		Warn:      warn,
+	}
+	// A clean shutdown's marker lets the seed be used as is when the tree has
+	// not moved since. It is consumed either way: once this daemon runs, only
+	// its own clean exit may vouch for the file again.
+	markerPath, err := watch.MarkerPath(root)
+	if err != nil {
+		return nil, err
+	}
+	if m, err := watch.TakeMarker(markerPath); err != nil {
+		warn("Warning: ignoring shutdown marker for %s: %v\n", root, err)
+	} else if m.Trusts(root, seed) {
+		wcfg.SeedStamp, wcfg.SeedStats = m.Stamp, m.Stats
+	}
+	// Failing that, a crashed predecessor's journal names the files to
+	// reparse.
+	if wcfg.Journal, err = watch.JournalPath(root); err != nil {
+		return nil, err
+	}
+
+	repoID := enrolledRepoID(root)
+	var savedSeq uint64
+	wcfg.OnPublish = func(s *watch.Snapshot) {
+		if saveWatched(s, cfg, root, repoID) {
+			savedSeq = s.Seq
+		}
+	}
+	wcfg.OnStop = func(s *watch.Snapshot) {
+		if savedSeq != s.Seq {
+			return // the file on disk is not this snapshot; vouch for nothing
+		}
+		if err := watch.WriteMarker(markerPath, root, s); err != nil {
+			warn("Warning: failed to record clean shutdown for %s: %v\n", root, err)
+		}
+	}
+	w, err := watch.New(ctx, wcfg)
+	if err == nil && seed != nil && w.Snapshot().IR == seed {
+		warn("%s: unchanged since its clean shutdown; reusing the saved IR\n", root)
+	}
+	return w, err
+}
+
+// runQuery asks a running `watch` over the control socket: `root_hash`, `ir`,
+// or `neighborhood <path>` about one project, `rescan` to have it re-hashed, or `projects`, `add <root>`,
+// `remove <root>` to manage the set. It prints the result JSON, and exits
+// ExitNoData when no daemon is listening.
+func runQuery(args []string) int {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	socket := fs.String("socket", "", "control socket path (default $RUNECHO_HOME/daemon.sock)")
+	rootFlag := fs.String("root", "", "project to ask about: its root or any path inside it (default: the only watched project)")
+	addr := fs.String("addr", "", "ask a daemon's tenant listener at host:port instead, with the token in $"+daemonTokenEnv)
+	caPath := fs.String("ca", "", "CA certificate (PEM) to trust for --addr, in addition to the system's")
+	if code, ok := parseSub(fs, args); !ok {
+		return code
+	}
+	const usage = "Usage: runecho-ir query [--socket=<path> | --addr=<host:port> [--ca=<pem>]] [--root=<path>] root_hash | ir | neighborhood <path> | rescan | projects | add <root> | remove <root>"
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return ExitError
+	}
+	req := daemon.Request{Method: rest[0]}
+	wantArgs := 0
+	switch req.Method {
+	case "neighborhood", "add", "remove":
+		wantArgs = 1
+	}
+	if len(rest) != 1+wantArgs {
+		fmt.Fprintln(os.Stderr, usage)
+		return ExitError
+	}
+	root := *rootFlag
+	switch req.Method {
+	case "neighborhood":
+		req.Path = filepath.ToSlash(rest[1])
+	case "add", "remove":
+		root = rest[1]
+	}
+	switch {
+	case root == "":
+	case *addr != "":
+		req.Root = root // a path on the daemon's machine, not this one
+	default:
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return printErr(err)
+		}
+		req.Root = abs
+	}
+	c, err := dialDaemon(*socket, *addr, *caPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "runecho-ir: %v (is `runecho-ir watch` running?)\n", err)
+		return ExitNoData
+	}
+	defer c.Close()
+	resp, err := c.Call(req)
+	if err != nil {
+		return printErr(err)
+	}
+	if resp.Error != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", resp.Error)
+		return ExitError
+	}
+	fmt.Println(string(resp.Result))
+	return ExitOK
+}
+
+// daemonTokenEnv holds the bearer token `query --addr` sends. It is read from
+// the environment, not a flag, to keep it out of the process list.
+const daemonTokenEnv = "RUNECHO_DAEMON_TOKEN"
+
+// dialDaemon connects query to the control socket, or with addr to a tenant
+// listener — over TLS unless addr is loopback, as watch --listen requires.
+func dialDaemon(socket, addr, caPath string) (*daemon.Client, error) {
+	if addr == "" {
+		if socket == "" {
+			var err error
+			if socket, err = daemon.DefaultSocketPath(); err != nil {
+				return nil, err
+			}
+		}
+		return daemon.Dial(socket)
+	}
+	token := os.Getenv(daemonTokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("--addr needs a token in $%s", daemonTokenEnv)
+	}
+	var tlsConfig *tls.Config
+	if caPath != "" || !isLoopback(addr) {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if caPath != "" {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			pem, err := os.ReadFile(caPath)
+			if err != nil {
+				return nil, err
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("%s: no certificates", caPath)
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+	return daemon.DialTCP(addr, tlsConfig, token)
+}
+
+// saveWatched writes a published snapshot to root's IR location, under the
+// repo's refresh lock when it is enrolled — the same lock the PostToolUse hook
+// takes for its own read-modify-write of the file. It reports whether the save
+// succeeded.
+func saveWatched(s *watch.Snapshot, cfg *config.Config, root string, repoID int64) bool {
+	ok := false
+	save := func() {
+		if err := cfg.SaveIR(s.IR, root); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to save IR: %v\n", err)
+			return
+		}
+		ok = true
+		shortHash := s.IR.RootHash
+		if len(shortHash) > 12 {
+			shortHash = shortHash[:12]
+		}
+		fmt.Printf("%s: indexed %d files — root_hash: %s...%s (%d changes)\n", root, len(s.IR.Files), shortHash, coverageSuffix(s.Stats), s.Events)
+	}
+	if repoID >= 0 {
+		withRepoRefreshLock(repoID, save)
+	} else {
+		save()
+	}
+	return ok
+}
@@ -0,0 +1,118 @@
+// Command runecho-verify-ir checks a saved IR — canonical form, file and root
+// hashes, and optionally a signature and the checkout it describes — without
+// any parser in the binary. It links only internal/irverify and the standard
+// library, for consumers that must trust an IR produced elsewhere but must not
+// run parsing logic on their side.
+//
+// Usage: runecho-verify-ir [--pubkey=<pem>] [--attestation[=<path>]] [--source=<dir>] [ir-path]
+//
+// ir-path defaults to .ai/ir.json. --attestation checks the IR against its
+// attestation, <ir-path>.intoto.json unless a path is given; with --pubkey it
+// is the attestation's signature that must be valid. Exit status is 0 when the IR verifies, 1
+// when it does not, and 2 when the check could not run (bad arguments, an
+// unreadable file or key).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/inth3shadows/runecho/internal/irverify"
+)
+
+const (
+	exitOK       = 0
+	exitRejected = 1
+	exitError    = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	fs := flag.NewFlagSet("runecho-verify-ir", flag.ContinueOnError)
+	pubkey := fs.String("pubkey", "", "Ed25519 public key (PEM); requires a valid <ir-path>.sig")
+	source := fs.String("source", "", "checkout to re-hash every indexed file from")
+	var attestation attestationFlag
+	fs.Var(&attestation, "attestation", "attestation to check the IR against (default <ir-path>.intoto.json)")
+	switch err := fs.Parse(args); err {
+	case nil:
+	case flag.ErrHelp:
+		return exitOK
+	default:
+		return exitError
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: runecho-verify-ir [--pubkey=<pem>] [--attestation[=<path>]] [--source=<dir>] [ir-path]")
+		return exitError
+	}
+	irPath := ".ai/ir.json"
+	if fs.NArg() == 1 {
+		irPath = fs.Arg(0)
+	}
+	opts := irverify.Options{Source: *source}
+	if attestation.set {
+		opts.Attestation = attestation.path
+		if opts.Attestation == "" {
+			opts.Attestation = irverify.AttestationFile(irPath)
+		}
+	}
+	if *pubkey != "" {
+		data, err := os.ReadFile(*pubkey)
+		if err == nil {
+			opts.PublicKey, err = irverify.ParsePublicKey(data)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "runecho-verify-ir: %v\n", err)
+			return exitError
+		}
+	}
+	r, err := irverify.Verify(irPath, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "runecho-verify-ir: %v\n", err)
+		return exitError
+	}
+	if len(r.Problems) > 0 {
+		for _, p := range r.Problems {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", irPath, p)
+		}
+		fmt.Printf("REJECTED %s: %d problems\n", irPath, len(r.Problems))
+		return exitRejected
+	}
+	form := "IR"
+	if r.Thin {
+		form = "thin IR"
+	}
+	fmt.Printf("OK %s: %s v%d, %d files, root_hash %s", irPath, form, r.Version, r.Files, r.RootHash)
+	if r.Signed {
+		fmt.Print(", signature valid")
+	}
+	if a := r.Attestation; a != nil {
+		fmt.Printf(", attested: %s %s at %s", a.Generator.Name, a.Generator.Version, a.Source.Revision)
+	}
+	if *source != "" {
+		fmt.Printf(", %d files match %s", r.SourceChecked, *source)
+	}
+	fmt.Println()
+	return exitOK
+}
+
+// attestationFlag is --attestation, which may be given bare.
+type attestationFlag struct {
+	set  bool
+	path string
+}
+
+func (f *attestationFlag) String() string { return f.path }
+
+func (f *attestationFlag) Set(v string) error {
+	f.set = true
+	if v != "true" {
+		f.path = v
+	}
+	return nil
+}
+
+func (f *attestationFlag) IsBoolFlag() bool { return true }
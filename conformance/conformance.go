@@ -0,0 +1,191 @@
+// Package conformance is RunEcho's cross-implementation determinism suite. The
+// corpus under corpus/ is plain JSON, one array of cases per file: each case is
+// an input tree (repo-relative path → file contents) with the exact IR bytes
+// and root hash the reference generator produces for it. Another
+// implementation — a port, a rewrite, or this one after a refactor — is
+// byte-compatible when it reproduces every case.
+//
+// Non-Go implementations read the JSON directly. Go code can use Run, which
+// writes each tree to a temporary directory and hands it to an Implementation.
+package conformance
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// CorpusDir is the corpus's directory, relative to this package.
+const CorpusDir = "corpus"
+
+//go:embed corpus/*.json
+var corpus embed.FS
+
+// Case is one corpus entry.
+type Case struct {
+	Name string `json:"name"`
+	Desc string `json:"desc,omitempty"`
+	// Files is the input tree: slash-separated repo-relative path → contents.
+	// Paths are given as they are written to disk, which for the Unicode cases
+	// is deliberately not NFC.
+	Files map[string]string `json:"files"`
+	// RootHash is the expected IR root_hash.
+	RootHash string `json:"root_hash"`
+	// IR is the expected IR, byte for byte, as ir.Save writes .ai/ir.json.
+	IR string `json:"ir"`
+}
+
+// Implementation indexes the tree at root and returns its IR in stored form.
+type Implementation func(root string) ([]byte, error)
+
+// Result is the outcome of one case. Err is nil when the case passed.
+type Result struct {
+	Case string
+	Err  error
+}
+
+// Cases returns the embedded corpus, ordered by file then position in file.
+func Cases() ([]Case, error) {
+	return ReadCases(corpus, CorpusDir)
+}
+
+// ReadCases loads every *.json case file in dir of fsys, in file-name order.
+// Case names must be unique across files.
+func ReadCases(fsys fs.FS, dir string) ([]Case, error) {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	var out []Case
+	seen := make(map[string]string)
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		cases, err := parseCases(name, data)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cases {
+			if prev, dup := seen[c.Name]; dup {
+				return nil, fmt.Errorf("%s: case %q already defined in %s", name, c.Name, prev)
+			}
+			seen[c.Name] = name
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// parseCases strictly decodes one case file: an unknown field is a typo that
+// would otherwise drop an expectation silently.
+func parseCases(name string, data []byte) ([]Case, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cases []Case
+	if err := dec.Decode(&cases); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	for _, c := range cases {
+		if c.Name == "" {
+			return nil, fmt.Errorf("%s: case without a name", name)
+		}
+	}
+	return cases, nil
+}
+
+// Reference is the reference implementation: this module's generator with the
+// default configuration (no .runecho.json, no plugins). Path filters registered
+// in the calling program apply to it as to any generator, so a conformance run
+// belongs in a binary that registers none.
+func Reference(root string) ([]byte, error) {
+	irData, _, err := ir.NewGenerator(ir.GeneratorConfig{IgnoredPaths: ir.DefaultIgnoredPaths}).Generate(root)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(irData)
+}
+
+// Materialize writes c's tree under dir, creating parent directories.
+func (c Case) Materialize(dir string) error {
+	for name, content := range c.Files {
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return fmt.Errorf("case %q: %q is not a path inside the tree", c.Name, name)
+		}
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check compares an implementation's output with c. A root-hash mismatch is
+// reported first, since it says the two disagree on file contents or paths
+// rather than on encoding; otherwise the first differing byte is shown in
+// context.
+func (c Case) Check(got []byte) error {
+	var head struct {
+		RootHash string `json:"root_hash"`
+	}
+	if err := json.Unmarshal(got, &head); err != nil {
+		return fmt.Errorf("output is not an IR: %w", err)
+	}
+	if head.RootHash != c.RootHash {
+		return fmt.Errorf("root_hash %s, want %s", head.RootHash, c.RootHash)
+	}
+	if string(got) == c.IR {
+		return nil
+	}
+	i := 0
+	for i < len(got) && i < len(c.IR) && got[i] == c.IR[i] {
+		i++
+	}
+	return fmt.Errorf("IR bytes differ at offset %d:\n  got:  %q\n  want: %q", i, excerpt(string(got), i), excerpt(c.IR, i))
+}
+
+// excerpt returns up to 40 bytes of s on either side of offset i.
+func excerpt(s string, i int) string {
+	lo, hi := max(i-40, 0), min(i+40, len(s))
+	return s[lo:hi]
+}
+
+// Run materializes each case in its own temporary directory, runs impl on it,
+// and checks the output. The error is for setup failures only; a case that
+// fails is reported in its Result.
+func Run(cases []Case, impl Implementation) ([]Result, error) {
+	out := make([]Result, 0, len(cases))
+	for _, c := range cases {
+		dir, err := os.MkdirTemp("", "runecho-conformance-")
+		if err != nil {
+			return out, err
+		}
+		res := Result{Case: c.Name}
+		if err := c.Materialize(dir); err != nil {
+			os.RemoveAll(dir)
+			return out, err
+		}
+		got, err := impl(dir)
+		if err != nil {
+			res.Err = fmt.Errorf("implementation failed: %w", err)
+		} else {
+			res.Err = c.Check(got)
+		}
+		os.RemoveAll(dir)
+		out = append(out, res)
+	}
+	return out, nil
+}
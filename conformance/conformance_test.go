@@ -0,0 +1,131 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/inth3shadows/runecho/runechotest"
+)
+
+// TestReference is the corpus's own regression gate: the reference generator
+// must reproduce every case. Run with RUNECHO_UPDATE_GOLDEN=1 to rewrite the
+// expected IRs and root hashes after a deliberate format change — and bump
+// ir.IRVersion, since every other implementation is now out of date.
+func TestReference(t *testing.T) {
+	if os.Getenv(runechotest.UpdateEnv) == "1" {
+		updateCorpus(t)
+	}
+	cases, err := Cases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("empty corpus")
+	}
+	results, err := Run(cases, Reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.Case, r.Err)
+		}
+	}
+}
+
+// updateCorpus regenerates the expected outputs in the on-disk corpus files.
+func updateCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(CorpusDir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases, err := parseCases(f, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range cases {
+			dir := t.TempDir()
+			if err := cases[i].Materialize(dir); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Reference(dir)
+			if err != nil {
+				t.Fatalf("%s: %v", cases[i].Name, err)
+			}
+			var head struct {
+				RootHash string `json:"root_hash"`
+			}
+			if err := json.Unmarshal(got, &head); err != nil {
+				t.Fatal(err)
+			}
+			cases[i].IR, cases[i].RootHash = string(got), head.RootHash
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(cases); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRun_ReportsMismatch(t *testing.T) {
+	cases, err := Cases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cases[0]
+	results, err := Run([]Case{c}, func(root string) ([]byte, error) {
+		got, err := Reference(root)
+		return bytes.Replace(got, []byte(`"version":`), []byte(`"version":-`), 1), err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "offset 11") {
+		t.Errorf("layout drift not reported at its line: %v", results[0].Err)
+	}
+
+	c.RootHash = strings.Repeat("0", 64)
+	results, _ = Run([]Case{c}, Reference)
+	if results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "root_hash") {
+		t.Errorf("root hash mismatch not reported: %v", results[0].Err)
+	}
+}
+
+func TestReadCases_Rejects(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"duplicate name": {
+			"c/a.json": {Data: []byte(`[{"name":"x","files":{}}]`)},
+			"c/b.json": {Data: []byte(`[{"name":"x","files":{}}]`)},
+		},
+		"unknown field": {"c/a.json": {Data: []byte(`[{"name":"x","file":{}}]`)}},
+		"no name":       {"c/a.json": {Data: []byte(`[{"files":{}}]`)}},
+	}
+	for name, fsys := range tests {
+		if _, err := ReadCases(fsys, "c"); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestMaterialize_RejectsEscape(t *testing.T) {
+	c := Case{Name: "escape", Files: map[string]string{"../out.go": "package out\n"}}
+	if err := c.Materialize(t.TempDir()); err == nil {
+		t.Error("Materialize wrote outside the tree")
+	}
+}
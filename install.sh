@@ -1,13 +1,14 @@
 #!/usr/bin/env bash
-# RunEcho installer — builds the three truth-oracle binaries.
+# RunEcho installer — builds the truth-oracle binaries.
 #
 #   runecho-ir     low-level CLI: index, snapshot, diff, log, churn, verify,
 #                  repo add|list|rm|reindex, backup
 #   runecho-mcp    stdio MCP oracle server (structure/diff/hash/status/health)
 #   runecho-guard  git pre-commit hook — blocks commits with unresolved symbols
+#   runecho-verify-ir  parser-free checker for a saved (optionally signed) IR
 #
 # Usage:
-#   bash install.sh            # build all three binaries to $BIN_DIR
+#   bash install.sh            # build all four binaries to $BIN_DIR
 #   bash install.sh --hook     # also install the GIT pre-commit hook in the cwd repo
 #   bash install.sh --hook --force      # overwrite an existing pre-commit hook
 #   bash install.sh --print-hook-config # print the Claude Code PreToolUse snippet
@@ -130,7 +131,7 @@ command -v go >/dev/null 2>&1 || { echo "install.sh: ERROR: Go toolchain not fou
 # to regex (names only, no per-symbol spans). runecho-guard does not import the
 # parser, so the tags are a harmless no-op there. Build stays CGO-free; do not
 # set CGO_ENABLED=1.
-GRAMMAR_TAGS="grammar_subset grammar_subset_python grammar_subset_javascript grammar_subset_typescript grammar_subset_tsx grammar_subset_rust grammar_subset_ruby"
+GRAMMAR_TAGS="grammar_subset grammar_subset_python grammar_subset_javascript grammar_subset_typescript grammar_subset_tsx grammar_subset_rust grammar_subset_ruby grammar_subset_java grammar_subset_c_sharp grammar_subset_php grammar_subset_kotlin grammar_subset_swift grammar_subset_scala grammar_subset_dart grammar_subset_elixir grammar_subset_lua grammar_subset_proto grammar_subset_solidity grammar_subset_yaml grammar_subset_json"
 
 # Stamp the version both binaries report (internal/version.Version) from the
 # latest git tag. Outside a git checkout (tarball install, no tags) git describe
@@ -139,7 +140,7 @@ GRAMMAR_TAGS="grammar_subset grammar_subset_python grammar_subset_javascript gra
 RUNECHO_VERSION="$(git describe --tags --always --dirty 2>/dev/null || echo dev)"
 VERSION_LDFLAGS="-X github.com/inth3shadows/runecho/internal/version.Version=$RUNECHO_VERSION"
 
-for cmd in runecho-ir runecho-mcp runecho-guard; do
+for cmd in runecho-ir runecho-mcp runecho-guard runecho-verify-ir; do
   echo "Building $cmd..."
   go build -tags "$GRAMMAR_TAGS" -ldflags "$VERSION_LDFLAGS" -o "$BIN_DIR/$cmd$EXE" "./cmd/$cmd"
   echo "  Built: $BIN_DIR/$cmd$EXE"
@@ -0,0 +1,205 @@
+// Package analyze runs analyzers over an IR and merges what they find into one
+// report. An analyzer is anything implementing Analyzer: the built-ins
+// (unused-export, boundary, naming) register themselves at init exactly as a
+// third-party analyzer registers through the public runecho package, and an
+// exec analyzer declared in .runecho.json joins the same run. None of them can
+// tell which kind they are, so the report has one shape no matter who produced a
+// finding.
+package analyze
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// Severity levels a Finding may carry.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Finding is one result from an analyzer. Analyzer is filled in by Run, so an
+// analyzer cannot impersonate another.
+type Finding struct {
+	Analyzer string `json:"analyzer"`
+	Rule     string `json:"rule,omitempty"`
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"` // 1-based; 0 = unknown or whole-file
+	Symbol   string `json:"symbol,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Context is what an analyzer sees: the IR, its import graph (computed once
+// per run and shared), and the repo's analyzer options.
+type Context struct {
+	IR      *ir.IR
+	Graph   *Graph
+	Options Options
+}
+
+// Analyzer inspects an IR and reports findings. Analyze must be a pure function
+// of its Context — same IR, same findings — because the report is part of
+// RunEcho's same-input-same-output contract. Run sorts the merged findings, so
+// an analyzer need not.
+type Analyzer interface {
+	Name() string
+	Analyze(ctx *Context) ([]Finding, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Analyzer{}
+)
+
+// Register makes an analyzer available to every Run. Like database/sql.Register
+// it panics on a nil analyzer or a duplicate name: both are programming errors
+// that would otherwise surface as a silently missing (or shadowed) analyzer.
+func Register(a Analyzer) {
+	if a == nil {
+		panic("analyze: Register analyzer is nil")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	name := a.Name()
+	if name == "" {
+		panic("analyze: Register analyzer has an empty name")
+	}
+	if _, dup := registry[name]; dup {
+		panic("analyze: Register called twice for analyzer " + name)
+	}
+	registry[name] = a
+}
+
+// Registered returns the names of all registered analyzers, sorted.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Report is the merged result of a Run.
+type Report struct {
+	Analyzers []string  `json:"analyzers"` // names that ran, sorted
+	Findings  []Finding `json:"findings"`
+}
+
+// Run executes every registered analyzer not disabled by opts, plus extra (exec
+// analyzers from config), and returns the merged, sorted report. only, when
+// non-empty, restricts the run to those names; an unknown name there is an
+// error rather than an empty report that reads as "clean".
+func Run(irData *ir.IR, opts Options, only []string, extra ...Analyzer) (*Report, error) {
+	registryMu.RLock()
+	all := make(map[string]Analyzer, len(registry)+len(extra))
+	for name, a := range registry {
+		all[name] = a
+	}
+	registryMu.RUnlock()
+	for _, a := range extra {
+		if _, dup := all[a.Name()]; dup {
+			return nil, fmt.Errorf("analyzer %q is already registered", a.Name())
+		}
+		all[a.Name()] = a
+	}
+
+	selected := make(map[string]bool)
+	if len(only) > 0 {
+		for _, name := range only {
+			if _, ok := all[name]; !ok {
+				return nil, fmt.Errorf("unknown analyzer %q", name)
+			}
+			selected[name] = true
+		}
+	} else {
+		for name := range all {
+			selected[name] = true
+		}
+		for _, name := range opts.Disable {
+			delete(selected, name)
+		}
+	}
+
+	names := make([]string, 0, len(selected))
+	for name := range selected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx := &Context{IR: irData, Graph: BuildGraph(irData), Options: opts}
+	rep := &Report{Analyzers: names, Findings: []Finding{}}
+	var errs []error
+	for _, name := range names {
+		found, err := all[name].Analyze(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("analyzer %q: %w", name, err))
+			continue
+		}
+		for _, f := range found {
+			f.Analyzer = name
+			if f.Severity == "" {
+				f.Severity = SeverityWarning
+			}
+			rep.Findings = append(rep.Findings, f)
+		}
+	}
+	SortFindings(rep.Findings)
+	return rep, errors.Join(errs...)
+}
+
+// SortFindings orders findings by path, line, analyzer, rule, symbol, message —
+// every field, so the order is total and the report byte-stable.
+func SortFindings(fs []Finding) {
+	sort.Slice(fs, func(i, j int) bool {
+		a, b := fs[i], fs[j]
+		switch {
+		case a.Path != b.Path:
+			return a.Path < b.Path
+		case a.Line != b.Line:
+			return a.Line < b.Line
+		case a.Analyzer != b.Analyzer:
+			return a.Analyzer < b.Analyzer
+		case a.Rule != b.Rule:
+			return a.Rule < b.Rule
+		case a.Symbol != b.Symbol:
+			return a.Symbol < b.Symbol
+		case a.Severity != b.Severity:
+			return a.Severity < b.Severity
+		}
+		return a.Message < b.Message
+	})
+}
+
+// Graph is the IR's in-repo import graph (see ir.ResolveImport), in both
+// directions. Every adjacency list is sorted.
+type Graph struct {
+	Deps       map[string][]string // file → files it imports
+	Dependents map[string][]string // file → files that import it
+}
+
+// BuildGraph resolves every file's imports once.
+func BuildGraph(irData *ir.IR) *Graph {
+	g := &Graph{Deps: make(map[string][]string), Dependents: make(map[string][]string)}
+	for path := range irData.Files {
+		deps := irData.Dependencies(path)
+		if len(deps) > 0 {
+			g.Deps[path] = deps
+		}
+		for _, d := range deps {
+			g.Dependents[d] = append(g.Dependents[d], path)
+		}
+	}
+	for _, list := range g.Dependents {
+		sort.Strings(list)
+	}
+	return g
+}
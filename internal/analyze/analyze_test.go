@@ -0,0 +1,201 @@
+package analyze
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// fixtureIR is a small JS repo: ui/app.ts imports db/store.ts (a boundary
+// violation under the test rules) and uses one of its two exports.
+func fixtureIR() *ir.IR {
+	return &ir.IR{
+		Version: 2,
+		Files: map[string]ir.FileIR{
+			"ui/app.ts": {Symbols: []ir.Symbol{
+				{Name: "../db/store", Kind: "import"},
+				{Name: "load", Kind: "import_name"},
+				{Name: "render_app", Kind: "function", Line: 3},
+			}},
+			"db/store.ts": {Symbols: []ir.Symbol{
+				{Name: "load", Kind: "export", Line: 1},
+				{Name: "save", Kind: "export", Line: 5},
+				{Name: "default", Kind: "export", Line: 9},
+			}},
+			"main.ts": {Symbols: []ir.Symbol{
+				{Name: "unusedEntry", Kind: "export", Line: 1},
+			}},
+		},
+	}
+}
+
+func fixtureOptions() Options {
+	return Options{
+		Boundaries: []BoundaryRule{{From: "ui/", Deny: []string{"db/"}}},
+		Naming:     []NamingRule{{Kind: "function", Pattern: "^[a-z][a-zA-Z0-9]*$"}},
+	}
+}
+
+// TestRun_BuiltinsUnifiedReport: all three built-ins run by default and their
+// findings come back as one sorted report, each stamped with its analyzer.
+func TestRun_BuiltinsUnifiedReport(t *testing.T) {
+	rep, err := Run(fixtureIR(), fixtureOptions(), nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if want := []string{"boundary", "naming", "unused-export"}; !reflect.DeepEqual(rep.Analyzers, want) {
+		t.Errorf("Analyzers = %v, want %v", rep.Analyzers, want)
+	}
+	var got []string
+	for _, f := range rep.Findings {
+		got = append(got, f.Analyzer+" "+f.Severity+" "+f.Path+" "+f.Symbol)
+	}
+	want := []string{
+		"unused-export info db/store.ts save", // load is imported; default is skipped
+		"boundary error ui/app.ts db/store.ts",
+		"naming warning ui/app.ts render_app",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestRun_OnlyAndDisable(t *testing.T) {
+	opts := fixtureOptions()
+	opts.Disable = []string{"naming", "unused-export"}
+	rep, err := Run(fixtureIR(), opts, nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !reflect.DeepEqual(rep.Analyzers, []string{"boundary"}) {
+		t.Errorf("Disable: ran %v", rep.Analyzers)
+	}
+
+	// only overrides Disable: naming was asked for by name.
+	rep, err = Run(fixtureIR(), opts, []string{"naming"})
+	if err != nil || !reflect.DeepEqual(rep.Analyzers, []string{"naming"}) {
+		t.Errorf("only: ran %v, err %v", rep, err)
+	}
+
+	if _, err := Run(fixtureIR(), opts, []string{"nmaing"}); err == nil {
+		t.Error("unknown name in only should be an error, not an empty report")
+	}
+}
+
+type stubAnalyzer struct {
+	name  string
+	found []Finding
+	err   error
+}
+
+func (s stubAnalyzer) Name() string                        { return s.name }
+func (s stubAnalyzer) Analyze(*Context) ([]Finding, error) { return s.found, s.err }
+
+// TestRun_ExtraAnalyzers: an extra analyzer joins the report on equal terms;
+// its Analyzer field is overwritten and a missing severity defaults to
+// warning. A failing one still lets the others report.
+func TestRun_ExtraAnalyzers(t *testing.T) {
+	custom := stubAnalyzer{name: "custom", found: []Finding{{Analyzer: "boundary", Path: "main.ts", Message: "m"}}}
+	broken := stubAnalyzer{name: "broken", err: errors.New("boom")}
+	rep, err := Run(fixtureIR(), Options{}, []string{"custom", "broken"}, custom, broken)
+	if err == nil || !strings.Contains(err.Error(), `"broken"`) {
+		t.Errorf("err = %v, want the broken analyzer's error", err)
+	}
+	if rep == nil || len(rep.Findings) != 1 {
+		t.Fatalf("report = %+v", rep)
+	}
+	if f := rep.Findings[0]; f.Analyzer != "custom" || f.Severity != SeverityWarning {
+		t.Errorf("finding = %+v", f)
+	}
+
+	if _, err := Run(fixtureIR(), Options{}, nil, stubAnalyzer{name: "naming"}); err == nil {
+		t.Error("an extra analyzer shadowing a registered name should be refused")
+	}
+}
+
+func TestRegister_Panics(t *testing.T) {
+	for name, a := range map[string]Analyzer{
+		"nil":       nil,
+		"empty":     stubAnalyzer{},
+		"duplicate": stubAnalyzer{name: "naming"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: Register did not panic", name)
+				}
+			}()
+			Register(a)
+		}()
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	if err := fixtureOptions().Validate(); err != nil {
+		t.Errorf("valid options: %v", err)
+	}
+	bad := Options{Naming: []NamingRule{{Kind: "function", Pattern: "("}}}
+	if err := bad.Validate(); err == nil {
+		t.Error("bad pattern accepted")
+	}
+}
+
+// execAnalyzer writes a /bin/sh analyzer script and returns it as "lint".
+func execAnalyzer(t *testing.T, script string) *ExecAnalyzer {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lint.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewExecAnalyzer("lint", []string{"sh", path}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestExecAnalyzer(t *testing.T) {
+	// The script proves it saw the graph by echoing a dependent it can only
+	// know from the input.
+	a := execAnalyzer(t, `grep -q '"db/store.ts":\["ui/app.ts"\]' || exit 3
+printf '[{"rule":"r","severity":"error","path":"main.ts","line":2,"message":"nope"}]'
+`)
+	rep, err := Run(fixtureIR(), Options{}, []string{"lint"}, a)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := []Finding{{Analyzer: "lint", Rule: "r", Severity: SeverityError, Path: "main.ts", Line: 2, Message: "nope"}}
+	if !reflect.DeepEqual(rep.Findings, want) {
+		t.Errorf("findings = %+v, want %+v", rep.Findings, want)
+	}
+}
+
+func TestExecAnalyzer_RejectsMalformedOutput(t *testing.T) {
+	cases := map[string]string{
+		"path not in IR": `[{"path":"nope.ts","message":"m"}]`,
+		"empty message":  `[{"path":"main.ts"}]`,
+		"bad severity":   `[{"path":"main.ts","message":"m","severity":"fatal"}]`,
+		"unknown field":  `[{"path":"main.ts","message":"m","sev":"info"}]`,
+		"trailing data":  `[] []`,
+	}
+	for name, out := range cases {
+		a := execAnalyzer(t, "cat >/dev/null; printf '%s' '"+out+"'\n")
+		if _, err := a.Analyze(&Context{IR: fixtureIR(), Graph: &Graph{}}); err == nil {
+			t.Errorf("%s: %s accepted", name, out)
+		}
+	}
+	a := execAnalyzer(t, "cat >/dev/null; echo bad >&2; exit 1\n")
+	if _, err := a.Analyze(&Context{IR: fixtureIR(), Graph: &Graph{}}); err == nil || !strings.Contains(err.Error(), "bad") {
+		t.Errorf("non-zero exit: err = %v, want stderr quoted", err)
+	}
+}
@@ -0,0 +1,220 @@
+package analyze
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// Options configures the built-in analyzers. It is the "analyzers" object of
+// .runecho.json minus the plugin declarations, and is handed unchanged to every
+// analyzer, so a third-party analyzer can read it too.
+type Options struct {
+	// Disable names analyzers to skip on a default run.
+	Disable []string `json:"disable,omitempty"`
+	// Boundaries are import rules checked by the "boundary" analyzer.
+	Boundaries []BoundaryRule `json:"boundaries,omitempty"`
+	// Naming are symbol-name rules checked by the "naming" analyzer.
+	Naming []NamingRule `json:"naming,omitempty"`
+}
+
+// BoundaryRule forbids files under From from importing files under any Deny
+// prefix. Prefixes are plain path prefixes ("internal/ui/"), matched against
+// IR paths.
+type BoundaryRule struct {
+	From string   `json:"from"`
+	Deny []string `json:"deny"`
+}
+
+// NamingRule requires every symbol of Kind (function, class, or export) in
+// files under Under ("" = everywhere) to match Pattern. For a qualified name
+// (Widget.render) only the last segment is checked, so a method rule does not
+// also constrain its class's name.
+type NamingRule struct {
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+	Under   string `json:"under,omitempty"`
+}
+
+// Validate reports the first malformed rule. Called by the config loader, so a
+// bad rule fails the load instead of every analysis run.
+func (o Options) Validate() error {
+	for i, r := range o.Boundaries {
+		if r.From == "" || len(r.Deny) == 0 {
+			return fmt.Errorf("boundaries[%d]: needs from and at least one deny prefix", i)
+		}
+	}
+	for i, r := range o.Naming {
+		switch r.Kind {
+		case "function", "class", "export":
+		default:
+			return fmt.Errorf("naming[%d]: kind %q (want function, class, or export)", i, r.Kind)
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("naming[%d]: pattern: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	Register(unusedExport{})
+	Register(boundary{})
+	Register(naming{})
+}
+
+// unusedExport flags JS/TS exports that no other file binds by name (an
+// import_name) or calls (a ref). It only looks at files with at least one
+// in-repo importer: a file nothing imports is an entry point or a package's
+// public surface, whose exports exist for callers outside the repo.
+//
+// Limited to JS/TS on purpose. Python and Go code routinely reaches an export
+// through its module (`mod.fn()`, `pkg.Fn()`), which binds no name and records
+// no bare ref, so those languages would drown the report in false positives. A
+// JS namespace import (`import * as ns`) has the same blind spot; the finding's
+// message says "by name" for that reason.
+type unusedExport struct{}
+
+func (unusedExport) Name() string { return "unused-export" }
+
+func (unusedExport) Analyze(ctx *Context) ([]Finding, error) {
+	used := make(map[string]map[string]bool) // name → files that mention it
+	mark := func(name, file string) {
+		if used[name] == nil {
+			used[name] = make(map[string]bool)
+		}
+		used[name][file] = true
+	}
+	for p, f := range ctx.IR.Files {
+		for _, s := range f.Symbols {
+			if s.Kind == "import_name" {
+				mark(s.Name, p)
+			}
+		}
+		for _, r := range f.Refs {
+			mark(r, p)
+		}
+	}
+
+	var out []Finding
+	for p, f := range ctx.IR.Files {
+		if !isJSPath(p) || len(ctx.Graph.Dependents[p]) == 0 {
+			continue
+		}
+		for _, s := range f.Symbols {
+			if s.Kind != "export" || s.Name == "default" {
+				continue
+			}
+			elsewhere := false
+			for file := range used[s.Name] {
+				if file != p {
+					elsewhere = true
+					break
+				}
+			}
+			if !elsewhere {
+				out = append(out, Finding{
+					Rule:     "unused-export",
+					Severity: SeverityInfo,
+					Path:     p,
+					Line:     s.Line,
+					Symbol:   s.Name,
+					Message:  fmt.Sprintf("export %s is not imported or called by name anywhere else in the repo", s.Name),
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+func isJSPath(p string) bool {
+	switch path.Ext(p) {
+	case ".js", ".mjs", ".cjs", ".ts", ".tsx", ".jsx", ".mts", ".cts", ".gs":
+		return true
+	}
+	return false
+}
+
+// boundary enforces Options.Boundaries over the import graph.
+type boundary struct{}
+
+func (boundary) Name() string { return "boundary" }
+
+func (boundary) Analyze(ctx *Context) ([]Finding, error) {
+	var out []Finding
+	for _, rule := range ctx.Options.Boundaries {
+		for from, deps := range ctx.Graph.Deps {
+			if !strings.HasPrefix(from, rule.From) {
+				continue
+			}
+			for _, dep := range deps {
+				for _, deny := range rule.Deny {
+					if strings.HasPrefix(dep, deny) {
+						out = append(out, Finding{
+							Rule:     "boundary",
+							Severity: SeverityError,
+							Path:     from,
+							Symbol:   dep,
+							Message:  fmt.Sprintf("%s imports %s, but %s* may not import %s*", from, dep, rule.From, deny),
+						})
+					}
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// naming enforces Options.Naming over declared symbols.
+type naming struct{}
+
+func (naming) Name() string { return "naming" }
+
+func (naming) Analyze(ctx *Context) ([]Finding, error) {
+	var out []Finding
+	paths := sortedPaths(ctx.IR)
+	for _, rule := range ctx.Options.Naming {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("naming pattern %q: %w", rule.Pattern, err)
+		}
+		for _, p := range paths {
+			if !strings.HasPrefix(p, rule.Under) {
+				continue
+			}
+			for _, s := range ctx.IR.Files[p].Symbols {
+				if s.Kind != rule.Kind {
+					continue
+				}
+				leaf := s.Name
+				if i := strings.LastIndexByte(leaf, '.'); i >= 0 {
+					leaf = leaf[i+1:]
+				}
+				if !re.MatchString(leaf) {
+					out = append(out, Finding{
+						Rule:     "naming",
+						Severity: SeverityWarning,
+						Path:     p,
+						Line:     s.Line,
+						Symbol:   s.Name,
+						Message:  fmt.Sprintf("%s %s does not match %s", s.Kind, s.Name, rule.Pattern),
+					})
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+func sortedPaths(irData *ir.IR) []string {
+	out := make([]string, 0, len(irData.Files))
+	for p := range irData.Files {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,140 @@
+package analyze
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ExecAnalyzer runs an external executable as an analyzer, for teams that
+// would rather write a rule in any language than in Go. Protocol:
+//
+//   - stdin: one JSON object {"ir": <the .ai/ir.json shape>, "graph":
+//     {"deps": {...}, "dependents": {...}}, "options": <Options>}.
+//   - stdout: a JSON array of findings, each {"rule", "severity", "path",
+//     "line", "symbol", "message"}; "analyzer" is ignored and set by Run.
+//   - exit status: non-zero fails this analyzer (stderr is quoted).
+//
+// Every finding must name a file in the IR and carry a message, so a plugin
+// cannot point the report at paths that do not exist.
+type ExecAnalyzer struct {
+	name    string
+	command []string
+	dir     string
+	timeout time.Duration
+}
+
+// DefaultExecTimeout bounds one exec analyzer run. An analyzer sees the whole
+// IR at once, so it gets longer than a per-file parser plugin.
+const DefaultExecTimeout = 2 * time.Minute
+
+// maxExecOutputBytes caps an exec analyzer's stdout.
+const maxExecOutputBytes = 16 << 20
+
+// NewExecAnalyzer returns an analyzer that runs command (argv) in dir.
+func NewExecAnalyzer(name string, command []string, dir string) (*ExecAnalyzer, error) {
+	if name == "" {
+		return nil, errors.New("exec analyzer: empty name")
+	}
+	if len(command) == 0 || command[0] == "" {
+		return nil, fmt.Errorf("exec analyzer %q: empty command", name)
+	}
+	return &ExecAnalyzer{name: name, command: append([]string(nil), command...), dir: dir, timeout: DefaultExecTimeout}, nil
+}
+
+// Name returns the analyzer's configured name.
+func (a *ExecAnalyzer) Name() string { return a.name }
+
+// execInput is the stdin wire shape.
+type execInput struct {
+	IR    any `json:"ir"`
+	Graph struct {
+		Deps       map[string][]string `json:"deps"`
+		Dependents map[string][]string `json:"dependents"`
+	} `json:"graph"`
+	Options Options `json:"options"`
+}
+
+// Analyze runs the executable once over the whole IR.
+func (a *ExecAnalyzer) Analyze(ctx *Context) ([]Finding, error) {
+	var in execInput
+	in.IR = ctx.IR
+	in.Graph.Deps = ctx.Graph.Deps
+	in.Graph.Dependents = ctx.Graph.Dependents
+	in.Options = ctx.Options
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("encode input: %w", err)
+	}
+
+	runCtx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, a.command[0], a.command[1:]...)
+	cmd.Dir = a.dir
+	cmd.Stdin = bytes.NewReader(payload)
+	stdout := &cappedBuffer{max: maxExecOutputBytes}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if runCtx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out after %s", a.timeout)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 200 {
+			msg = msg[:200] + "…"
+		}
+		return nil, fmt.Errorf("%w (stderr: %q)", err, msg)
+	}
+	if stdout.overflow {
+		return nil, fmt.Errorf("output exceeds %d bytes", maxExecOutputBytes)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(stdout.Bytes()))
+	dec.DisallowUnknownFields()
+	var found []Finding
+	if err := dec.Decode(&found); err != nil {
+		return nil, fmt.Errorf("decode output: %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("decode output: trailing data after the JSON array")
+	}
+	for i, f := range found {
+		if _, ok := ctx.IR.Files[f.Path]; !ok {
+			return nil, fmt.Errorf("finding %d: path %q is not in the IR", i, f.Path)
+		}
+		if f.Message == "" {
+			return nil, fmt.Errorf("finding %d: empty message", i)
+		}
+		switch f.Severity {
+		case "", SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			return nil, fmt.Errorf("finding %d: severity %q (want error, warning, or info)", i, f.Severity)
+		}
+		if f.Line < 0 {
+			return nil, fmt.Errorf("finding %d: negative line", i)
+		}
+	}
+	return found, nil
+}
+
+// cappedBuffer accepts writes up to max bytes and records an overflow instead
+// of failing the write, so the child is not killed by EPIPE mid-output.
+type cappedBuffer struct {
+	bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.overflow || b.Len()+len(p) > b.max {
+		b.overflow = true
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,467 @@
+// Package config loads the optional per-repo configuration file, .runecho.json
+// or .runecho.yml at the repo root. It is versioned with the code it
+// describes, so every clone indexes the same way. A repo without the file gets
+// the zero Config, which reproduces the built-in behaviour exactly.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/inth3shadows/runecho/internal/analyze"
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/parser"
+)
+
+// FileName is the config file's name, looked up at the repo root only.
+const FileName = ".runecho.json"
+
+// YAMLFileNames are the names the same config goes by in YAML. A repo has
+// one config file: two at its root is an error, not a merge.
+var YAMLFileNames = []string{".runecho.yml", ".runecho.yaml"}
+
+// maxConfigBytes caps the config read. The file is a handful of declarations.
+const maxConfigBytes = 1 << 20
+
+// ExecPluginsEnv gates exec parser plugins. A repo's config is as untrusted as
+// the repo: without this gate, indexing a freshly cloned repo — which the
+// PostToolUse hook and the MCP server do unprompted — would run whatever
+// command its .runecho.json names. Plugins declared while the gate is closed
+// are skipped with a warning, never run. WASM plugins are sandboxed (see
+// parser.WasmParser) and need no gate.
+const ExecPluginsEnv = "RUNECHO_EXEC_PLUGINS"
+
+// Config is the decoded .runecho.json.
+type Config struct {
+	// IR is where the CLI and the guard hook keep the repo's IR: a path
+	// relative to the repo root, or a "scheme://…" URI served by a storage
+	// backend registered with ir.RegisterStorage. Empty means .ai/ir.json.
+	IR string `json:"ir,omitempty"`
+	// Objects saves the IR thin, its per-file entries kept as content-addressed
+	// objects next to it (see ir.ObjectStore), and has indexing reuse the
+	// parses stored there. It needs a local IR location.
+	Objects bool `json:"objects,omitempty"`
+	// Parsers declares parser plugins (see parser.ExecParser, parser.WasmParser).
+	Parsers []ParserPlugin `json:"parsers,omitempty"`
+	// Extensions maps a file extension to the parser that reads it: a built-in
+	// language name (parser.BuiltinNames) or its alias (see parser.Builtin),
+	// or a declared plugin's name.
+	Extensions map[string]string `json:"extensions,omitempty"`
+	// Analyzers configures `runecho-ir analyze`.
+	Analyzers Analyzers `json:"analyzers"`
+
+	// Ignore lists paths to leave out of the IR, in .gitignore syntax and
+	// relative to the repo root: a root .runechoignore kept in the config.
+	Ignore []string `json:"ignore,omitempty"`
+	// Include lists paths, in the same syntax, to index although the
+	// built-in ignored directories (ir.DefaultIgnoredPaths) or a .gitignore
+	// would skip them: "vendor/", a checked-in "gen/**". Ignore wins over it.
+	Include []string `json:"include,omitempty"`
+	// MaxFileSize, MaxDepth, ParserBackend, and NormalizedHash are the
+	// ir.GeneratorConfig settings of those names (NormalizedHash is
+	// NormalizedRootHash).
+	MaxFileSize    int64  `json:"max_file_size,omitempty"`
+	MaxDepth       int    `json:"max_depth,omitempty"`
+	ParserBackend  string `json:"parser_backend,omitempty"`
+	NormalizedHash bool   `json:"normalized_hash,omitempty"`
+
+	// name is the file the config was read from; "" is FileName.
+	name string
+}
+
+// Analyzers is the "analyzers" object: the built-in analyzers' options, inline,
+// plus exec analyzer plugins.
+type Analyzers struct {
+	analyze.Options
+	Plugins []AnalyzerPlugin `json:"plugins,omitempty"`
+}
+
+// AnalyzerPlugin declares one exec analyzer (see analyze.ExecAnalyzer). Like an
+// exec parser plugin it runs only behind ExecPluginsEnv.
+type AnalyzerPlugin struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+}
+
+// ParserPlugin declares one parser plugin: an executable (Command) or a
+// WebAssembly module (Wasm), exactly one of the two.
+type ParserPlugin struct {
+	Name string `json:"name"`
+	// Command is argv, not a shell string. A relative command[0] containing a
+	// path separator ("./tools/parse-ex") resolves against the repo root.
+	Command []string `json:"command,omitempty"`
+	// Wasm is the path of a .wasm module, relative to the repo root.
+	Wasm       string   `json:"wasm,omitempty"`
+	Extensions []string `json:"extensions"`
+}
+
+// Locate returns the name of root's config file, FileName or one of
+// YAMLFileNames, or "" when it has none. More than one is an error.
+func Locate(root string) (string, error) {
+	var found []string
+	for _, name := range append([]string{FileName}, YAMLFileNames...) {
+		_, err := os.Stat(filepath.Join(root, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", name, err)
+		}
+		found = append(found, name)
+	}
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return found[0], nil
+	}
+	return "", fmt.Errorf("%s: one config file per repo, not %d", strings.Join(found, " and "), len(found))
+}
+
+// Load reads root's config file (see Locate). A missing file is not an error
+// and yields the zero Config. Unknown fields are an error: a typo'd key would
+// otherwise be silently ignored and the repo indexed without the setting it
+// asked for.
+func Load(root string) (*Config, error) {
+	name, err := Locate(root)
+	if err != nil || name == "" {
+		return &Config{}, err
+	}
+	f, err := os.Open(filepath.Join(root, name))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxConfigBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	if len(data) > maxConfigBytes {
+		return nil, fmt.Errorf("%s exceeds %d bytes", name, maxConfigBytes)
+	}
+	if name != FileName {
+		return parseYAML(name, data)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates config bytes.
+func Parse(data []byte) (*Config, error) {
+	return parse(FileName, data)
+}
+
+// ParseYAML is Parse for a config written in YAML.
+func ParseYAML(data []byte) (*Config, error) {
+	return parseYAML(YAMLFileNames[0], data)
+}
+
+// parseYAML reads the YAML config name as the JSON it corresponds to, so both
+// forms have one schema, the JSON one, and are validated alike.
+func parseYAML(name string, data []byte) (*Config, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	if doc == nil {
+		doc = map[string]any{} // an empty file, or comments alone
+	}
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	return parse(name, js)
+}
+
+// parse is Parse for the file name.
+func parse(name string, data []byte) (*Config, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	c := Config{name: name}
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("parse %s: trailing data after the JSON object", name)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &c, nil
+}
+
+// Name returns the name of the file c was read from: FileName for the zero
+// Config.
+func (c *Config) Name() string {
+	if c.name == "" {
+		return FileName
+	}
+	return c.name
+}
+
+func (c *Config) validate() error {
+	if err := validateIRLocation(c.IR); err != nil {
+		return err
+	}
+	if c.Objects && ir.IsStorageURI(c.IR) {
+		return fmt.Errorf("objects: needs a local ir location, not %q", c.IR)
+	}
+	names := make(map[string]bool)
+	owner := make(map[string]string)
+	for i, p := range c.Parsers {
+		if p.Name == "" {
+			return fmt.Errorf("parsers[%d]: missing name", i)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("parsers[%d]: duplicate name %q", i, p.Name)
+		}
+		names[p.Name] = true
+		hasCmd := len(p.Command) > 0 && p.Command[0] != ""
+		switch {
+		case hasCmd && p.Wasm != "":
+			return fmt.Errorf("parser %q: set command or wasm, not both", p.Name)
+		case !hasCmd && p.Wasm == "":
+			return fmt.Errorf("parser %q: missing command or wasm", p.Name)
+		}
+		if len(p.Extensions) == 0 {
+			return fmt.Errorf("parser %q: no extensions", p.Name)
+		}
+		for _, ext := range p.Extensions {
+			if prev, dup := owner[ext]; dup {
+				// First-match dispatch would make the later declaration dead code;
+				// refusing is clearer than an order-dependent winner.
+				return fmt.Errorf("extension %q claimed by both %q and %q", ext, prev, p.Name)
+			}
+			owner[ext] = p.Name
+		}
+	}
+	for ext, name := range c.Extensions {
+		if !strings.HasPrefix(ext, ".") || len(ext) < 2 {
+			return fmt.Errorf("extensions: %q must look like \".ext\"", ext)
+		}
+		if _, _, builtin := parser.Builtin(name); !names[name] && !builtin {
+			return fmt.Errorf("extensions: %s maps to %q, which is neither a declared parser nor one of %v", ext, name, parser.BuiltinNames())
+		}
+		if prev, claimed := owner[ext]; claimed && prev != name {
+			return fmt.Errorf("extensions: %s maps to %q but parser %q claims it", ext, name, prev)
+		}
+	}
+	for _, list := range []struct {
+		key      string
+		patterns []string
+	}{{"ignore", c.Ignore}, {"include", c.Include}} {
+		for i, pat := range list.patterns {
+			if strings.TrimSpace(pat) == "" || strings.ContainsAny(pat, "\r\n") {
+				return fmt.Errorf("%s[%d]: %q is not a pattern", list.key, i, pat)
+			}
+		}
+	}
+	if c.MaxFileSize < 0 {
+		return fmt.Errorf("max_file_size: %d is negative", c.MaxFileSize)
+	}
+	if c.MaxDepth < 0 {
+		return fmt.Errorf("max_depth: %d is negative", c.MaxDepth)
+	}
+	if c.ParserBackend != ir.ParserBackendDefault && c.ParserBackend != ir.ParserBackendTreeSitter {
+		return fmt.Errorf("parser_backend: %q must be %q or %q", c.ParserBackend, ir.ParserBackendDefault, ir.ParserBackendTreeSitter)
+	}
+	if err := c.Analyzers.Options.Validate(); err != nil {
+		return fmt.Errorf("analyzers: %w", err)
+	}
+	seen := make(map[string]bool)
+	for i, p := range c.Analyzers.Plugins {
+		if p.Name == "" {
+			return fmt.Errorf("analyzers.plugins[%d]: missing name", i)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("analyzers.plugins[%d]: duplicate name %q", i, p.Name)
+		}
+		seen[p.Name] = true
+		if len(p.Command) == 0 || p.Command[0] == "" {
+			return fmt.Errorf("analyzer plugin %q: missing command", p.Name)
+		}
+	}
+	return nil
+}
+
+// IRLocation returns where root's IR lives, ready for ir.Save and ir.Load.
+func (c *Config) IRLocation(root string) string {
+	switch {
+	case c.IR == "":
+		return filepath.Join(root, ".ai", "ir.json")
+	case ir.IsStorageURI(c.IR):
+		return c.IR
+	}
+	return filepath.Join(root, filepath.FromSlash(c.IR))
+}
+
+// ObjectStore returns the object store root's IR shares its parses through,
+// for ir.GeneratorConfig.Objects: nil unless Objects is set.
+func (c *Config) ObjectStore(root string) *ir.ObjectStore {
+	if !c.Objects {
+		return nil
+	}
+	return ir.NewObjectStore(ir.ObjectsDir(c.IRLocation(root)))
+}
+
+// SaveIR saves irData at root's IR location, thin when Objects is set.
+func (c *Config) SaveIR(irData *ir.IR, root string) error {
+	if c.Objects {
+		return irData.SaveThin(c.IRLocation(root))
+	}
+	return irData.Save(c.IRLocation(root))
+}
+
+// validateIRLocation keeps a local "ir" inside the repo. The hook saves the IR
+// on every edit, so a cloned repo's config naming ../../.bashrc (or a file://
+// URI) would otherwise have it overwrite an arbitrary file of the user's.
+func validateIRLocation(loc string) error {
+	if loc == "" {
+		return nil
+	}
+	if ir.IsStorageURI(loc) {
+		if scheme, _, _ := strings.Cut(loc, "://"); strings.EqualFold(scheme, "file") {
+			return fmt.Errorf("ir: %q: use a repo-relative path, not a file URI", loc)
+		}
+		return nil
+	}
+	if !filepath.IsLocal(filepath.FromSlash(loc)) {
+		return fmt.Errorf("ir: %q must be a path inside the repo", loc)
+	}
+	return nil
+}
+
+// Generator returns the ir.GeneratorConfig every entry point indexes root
+// with, given the plugins PluginParsers built: the built-in ignored
+// directories and c's settings. The caller adds what is its own choice — a
+// timeout, a FileCap, the object store, a StatCache.
+func (c *Config) Generator(plugins []parser.Parser) ir.GeneratorConfig {
+	gc := ir.GeneratorConfig{
+		IgnoredPaths:       ir.DefaultIgnoredPaths,
+		Parsers:            plugins,
+		Extensions:         c.ExtensionsFor(plugins),
+		MaxFileSize:        c.MaxFileSize,
+		MaxDepth:           c.MaxDepth,
+		ParserBackend:      c.ParserBackend,
+		NormalizedRootHash: c.NormalizedHash,
+	}
+	if len(c.Ignore) > 0 || len(c.Include) > 0 {
+		gc.PathFilters = []ir.PathFilter{ir.PatternFilter(c.Ignore, c.Include)}
+	}
+	return gc
+}
+
+// ExtensionsFor returns Extensions minus the entries naming a declared plugin
+// absent from loaded (the PluginParsers result): an exec plugin skipped by the
+// gate has been warned about once already, and its files stay unindexed either
+// way.
+func (c *Config) ExtensionsFor(loaded []parser.Parser) map[string]string {
+	if len(c.Extensions) == 0 {
+		return nil
+	}
+	have := make(map[string]bool, len(loaded))
+	for _, p := range loaded {
+		if n, ok := p.(interface{ Name() string }); ok {
+			have[n.Name()] = true
+		}
+	}
+	declared := make(map[string]bool, len(c.Parsers))
+	for _, p := range c.Parsers {
+		declared[p.Name] = true
+	}
+	out := make(map[string]string, len(c.Extensions))
+	for ext, name := range c.Extensions {
+		if declared[name] && !have[name] {
+			continue
+		}
+		out[ext] = name
+	}
+	return out
+}
+
+// PluginParsers builds the declared plugins as parsers rooted at root, in
+// declaration order. With the ExecPluginsEnv gate closed, exec plugins are left
+// out and one warning names them; WASM plugins load either way.
+func (c *Config) PluginParsers(root string, warn func(format string, args ...any)) ([]parser.Parser, error) {
+	if len(c.Parsers) == 0 {
+		return nil, nil
+	}
+	allowExec := os.Getenv(ExecPluginsEnv) == "1"
+	var out []parser.Parser
+	var skipped []string
+	for _, p := range c.Parsers {
+		if p.Wasm != "" {
+			path := p.Wasm
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(root, path)
+			}
+			wp, err := parser.NewWasmParser(p.Name, path, p.Extensions)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, wp)
+			continue
+		}
+		if !allowExec {
+			skipped = append(skipped, p.Name)
+			continue
+		}
+		ep, err := parser.NewExecParser(p.Name, resolveCommand(root, p.Command), p.Extensions, root)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ep)
+	}
+	if len(skipped) > 0 && warn != nil {
+		sort.Strings(skipped)
+		warn("Warning: %s declares exec parser plugins %v; not running them (set %s=1 to allow)\n", c.Name(), skipped, ExecPluginsEnv)
+	}
+	return out, nil
+}
+
+// AnalyzerPlugins builds the declared exec analyzers rooted at root. With the
+// ExecPluginsEnv gate closed it returns nil and one warning naming them.
+func (c *Config) AnalyzerPlugins(root string, warn func(format string, args ...any)) ([]analyze.Analyzer, error) {
+	plugins := c.Analyzers.Plugins
+	if len(plugins) == 0 {
+		return nil, nil
+	}
+	if os.Getenv(ExecPluginsEnv) != "1" {
+		if warn != nil {
+			names := make([]string, 0, len(plugins))
+			for _, p := range plugins {
+				names = append(names, p.Name)
+			}
+			sort.Strings(names)
+			warn("Warning: %s declares exec analyzer plugins %v; not running them (set %s=1 to allow)\n", c.Name(), names, ExecPluginsEnv)
+		}
+		return nil, nil
+	}
+	out := make([]analyze.Analyzer, 0, len(plugins))
+	for _, p := range plugins {
+		ea, err := analyze.NewExecAnalyzer(p.Name, resolveCommand(root, p.Command), root)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ea)
+	}
+	return out, nil
+}
+
+// resolveCommand copies argv, resolving a relative command[0] that contains a
+// path separator ("./tools/x") against root. A bare name stays a PATH lookup.
+func resolveCommand(root string, command []string) []string {
+	cmd := append([]string(nil), command...)
+	if !filepath.IsAbs(cmd[0]) && filepath.Base(cmd[0]) != cmd[0] {
+		cmd[0] = filepath.Join(root, cmd[0])
+	}
+	return cmd
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+func TestLoad_MissingFileIsZeroConfig(t *testing.T) {
+	c, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(c.Parsers) != 0 {
+		t.Errorf("zero config has parsers: %+v", c.Parsers)
+	}
+	ps, err := c.PluginParsers("/r", nil)
+	if err != nil || ps != nil {
+		t.Errorf("ExecParsers on zero config = %v, %v", ps, err)
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":     `{"parser":[]}`,
+		"missing name":      `{"parsers":[{"command":["x"],"extensions":[".ex"]}]}`,
+		"missing command":   `{"parsers":[{"name":"a","extensions":[".ex"]}]}`,
+		"command and wasm":  `{"parsers":[{"name":"a","command":["x"],"wasm":"a.wasm","extensions":[".ex"]}]}`,
+		"no extensions":     `{"parsers":[{"name":"a","command":["x"]}]}`,
+		"duplicate name":    `{"parsers":[{"name":"a","command":["x"],"extensions":[".a"]},{"name":"a","command":["y"],"extensions":[".b"]}]}`,
+		"contested ext":     `{"parsers":[{"name":"a","command":["x"],"extensions":[".ex"]},{"name":"b","command":["y"],"extensions":[".ex"]}]}`,
+		"trailing data":     `{} {}`,
+		"bad naming kind":   `{"analyzers":{"naming":[{"kind":"method","pattern":"x"}]}}`,
+		"bad naming regexp": `{"analyzers":{"naming":[{"kind":"function","pattern":"("}]}}`,
+		"empty boundary":    `{"analyzers":{"boundaries":[{"from":"ui/"}]}}`,
+		"analyzer no cmd":   `{"analyzers":{"plugins":[{"name":"a"}]}}`,
+		"ir outside repo":   `{"ir":"../elsewhere/ir.json"}`,
+		"ir absolute":       `{"ir":"/tmp/ir.json"}`,
+		"ir file URI":       `{"ir":"file:///tmp/ir.json"}`,
+		"ext without dot":   `{"extensions":{"mts":"typescript"}}`,
+		"ext unknown name":  `{"extensions":{".mts":"typescrpt"}}`,
+		"ext contested":     `{"parsers":[{"name":"a","command":["x"],"extensions":[".ex"]}],"extensions":{".ex":"python"}}`,
+		"objects remote":    `{"ir":"s3://bucket/ir.json","objects":true}`,
+		"not a JSON object": `parsers: []`,
+		"empty ignore":      `{"ignore":[""]}`,
+		"multiline ignore":  `{"include":["a\nb"]}`,
+		"negative size":     `{"max_file_size":-1}`,
+		"negative depth":    `{"max_depth":-1}`,
+		"unknown backend":   `{"parser_backend":"treesitter"}`,
+	}
+	for name, data := range cases {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: %s parsed without error", name, data)
+		}
+	}
+}
+
+// TestExecParsers_Gated: a repo's config alone never runs a command — the user
+// must opt in through the environment — and a skipped plugin is announced.
+func TestExecParsers_Gated(t *testing.T) {
+	root := t.TempDir()
+	data := `{"parsers":[{"name":"elixir","command":["./tools/parse-ex","--json"],"extensions":[".ex",".exs"]}]}`
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	t.Setenv(ExecPluginsEnv, "")
+	var warned string
+	ps, err := c.PluginParsers(root, func(format string, args ...any) { warned = fmt.Sprintf(format, args...) })
+	if err != nil || ps != nil {
+		t.Fatalf("gate closed: ExecParsers = %v, %v; want nil, nil", ps, err)
+	}
+	if !strings.Contains(warned, ExecPluginsEnv) {
+		t.Errorf("gate closed: warning %q should name %s", warned, ExecPluginsEnv)
+	}
+
+	t.Setenv(ExecPluginsEnv, "1")
+	ps, err = c.PluginParsers(root, nil)
+	if err != nil || len(ps) != 1 {
+		t.Fatalf("gate open: ExecParsers = %v, %v; want one parser", ps, err)
+	}
+	if !ps[0].SupportsExtension(".exs") || ps[0].SupportsExtension(".go") {
+		t.Error("plugin extensions not wired through")
+	}
+}
+
+// TestAnalyzers_InlineOptions: the built-in options sit directly in the
+// "analyzers" object, beside the plugin list, and exec analyzers share the
+// parser plugins' gate.
+func TestAnalyzers_InlineOptions(t *testing.T) {
+	c, err := Parse([]byte(`{"analyzers":{"disable":["naming"],"boundaries":[{"from":"ui/","deny":["db/"]}],"plugins":[{"name":"lint","command":["./lint"]}]}}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(c.Analyzers.Disable) != 1 || len(c.Analyzers.Boundaries) != 1 || len(c.Analyzers.Plugins) != 1 {
+		t.Fatalf("analyzers not decoded: %+v", c.Analyzers)
+	}
+
+	t.Setenv(ExecPluginsEnv, "")
+	var warned string
+	as, err := c.AnalyzerPlugins("/r", func(format string, args ...any) { warned = fmt.Sprintf(format, args...) })
+	if err != nil || as != nil || !strings.Contains(warned, "lint") {
+		t.Errorf("gate closed: AnalyzerPlugins = %v, %v, warning %q", as, err, warned)
+	}
+	t.Setenv(ExecPluginsEnv, "1")
+	as, err = c.AnalyzerPlugins("/r", nil)
+	if err != nil || len(as) != 1 || as[0].Name() != "lint" {
+		t.Errorf("gate open: AnalyzerPlugins = %v, %v", as, err)
+	}
+}
+
+func TestIRLocation(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+	for in, want := range map[string]string{
+		"":                    filepath.Join(root, ".ai", "ir.json"),
+		"build/ir.json":       filepath.Join(root, "build", "ir.json"),
+		"artifacts://team/ir": "artifacts://team/ir",
+	} {
+		c, err := Parse([]byte(fmt.Sprintf(`{"ir":%q}`, in)))
+		if err != nil {
+			t.Fatalf("%q: %v", in, err)
+		}
+		if got := c.IRLocation(root); got != want {
+			t.Errorf("IRLocation(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestExtensionsFor: a mapping to an exec plugin the gate skipped is dropped
+// (its warning was PluginParsers'), while built-in mappings, by name or alias,
+// always pass.
+func TestExtensionsFor(t *testing.T) {
+	c, err := Parse([]byte(`{"parsers":[{"name":"elixir","command":["./x"],"extensions":[".ex"]}],"extensions":{".ex":"elixir",".exs":"elixir",".mts":"typescript",".cjsx":"js"}}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	t.Setenv(ExecPluginsEnv, "")
+	loaded, _ := c.PluginParsers("/r", nil)
+	if got := c.ExtensionsFor(loaded); len(got) != 2 || got[".mts"] != "typescript" || got[".cjsx"] != "js" {
+		t.Errorf("gate closed: ExtensionsFor = %v", got)
+	}
+	t.Setenv(ExecPluginsEnv, "1")
+	loaded, _ = c.PluginParsers("/r", nil)
+	if got := c.ExtensionsFor(loaded); len(got) != 4 {
+		t.Errorf("gate open: ExtensionsFor = %v", got)
+	}
+}
+
+// TestLoad_YAML: .runecho.yml has the JSON schema, strictness included, and
+// a repo may have only one config file.
+func TestLoad_YAML(t *testing.T) {
+	root := t.TempDir()
+	data := `# house settings
+ir: build/ir.json
+ignore:
+  - "*.pb.go"
+  - /testdata/
+include: [vendor/]
+extensions:
+  .pyi: python
+max_file_size: 65536
+max_depth: 8
+normalized_hash: true
+`
+	if err := os.WriteFile(filepath.Join(root, ".runecho.yml"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if c.Name() != ".runecho.yml" || c.IR != "build/ir.json" || c.MaxFileSize != 65536 || c.MaxDepth != 8 || !c.NormalizedHash {
+		t.Errorf("Load = %+v", c)
+	}
+	gc := c.Generator(nil)
+	if len(gc.PathFilters) != 1 || gc.Extensions[".pyi"] != "python" || gc.MaxFileSize != 65536 || gc.MaxDepth != 8 || !gc.NormalizedRootHash {
+		t.Errorf("Generator = %+v", gc)
+	}
+
+	if _, err := ParseYAML([]byte("ignor: [x]\n")); err == nil {
+		t.Error("ParseYAML accepted an unknown key")
+	}
+	if c, err := ParseYAML([]byte("# nothing yet\n")); err != nil || c.Name() != ".runecho.yml" {
+		t.Errorf("ParseYAML(comment only) = %+v, %v", c, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(root); err == nil || !strings.Contains(err.Error(), "one config file") {
+		t.Errorf("Load with two config files = %v, want an error", err)
+	}
+}
+
+// TestGenerator_Include: an include re-opens a directory a .gitignore or the
+// built-in list prunes, whether it names the directory ("vendor/") or what is
+// inside it ("gen/**").
+func TestGenerator_Include(t *testing.T) {
+	for _, include := range []string{`["gen/","vendor/"]`, `["gen/**","vendor/**"]`} {
+		root := t.TempDir()
+		for name, src := range map[string]string{
+			".gitignore":    "gen/\n",
+			"m.go":          "package m\n",
+			"gen/a.go":      "package gen\n",
+			"vendor/x/b.go": "package x\n",
+		} {
+			p := filepath.Join(root, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(p, []byte(src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		c, err := Parse([]byte(`{"include":` + include + `}`))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		res, _, err := ir.NewGenerator(c.Generator(nil)).Generate(root)
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		var got []string
+		for p := range res.Files {
+			got = append(got, p)
+		}
+		sort.Strings(got)
+		if want := []string{"gen/a.go", "m.go", "vendor/x/b.go"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("include %s indexed %v, want %v", include, got, want)
+		}
+	}
+}
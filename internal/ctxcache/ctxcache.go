@@ -0,0 +1,132 @@
+// Package ctxcache memoizes generated context bundles — repo maps, rendered
+// prompt templates — keyed by the IR's RootHash plus the parameters that shaped
+// them, so repeated agent sessions on an unchanged repo are served the bytes the
+// first session produced instead of regenerating them.
+//
+// The RootHash is a content hash of every indexed file, so an entry can only be
+// served for the exact tree it was rendered from: there is nothing to invalidate,
+// and a stale key is simply never asked for again. What the key must ALSO cover
+// is everything else the output depends on — the flags, the template bytes, the
+// walk's coverage stats, and the binary version (an upgrade that changes a
+// renderer must not keep serving the old rendering). Callers pass those as
+// params; Key only guarantees that distinct inputs never collide.
+//
+// Like depindex's export memo, every failure is a miss and every write is
+// best-effort: a missing cache costs latency, never correctness.
+package ctxcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/inth3shadows/runecho/internal/store"
+	"github.com/inth3shadows/runecho/internal/version"
+)
+
+// maxEntries bounds the cache directory. Unlike depindex (one entry per
+// dependency version, a small fixed set), every edit to a repo mints a new
+// RootHash, so without a bound the directory grows by one bundle per session
+// forever. Oldest-first pruning on write keeps the recent working set.
+const maxEntries = 256
+
+// Dir returns the cache directory ($RUNECHO_HOME/ctxcache).
+func Dir() (string, error) {
+	base, err := store.RunechoDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "ctxcache"), nil
+}
+
+// Key hashes rootHash, the bundle kind (e.g. "map", "render"), the binary
+// version, and params into a cache key. Each part is length-prefixed, so
+// ("a", "bc") and ("ab", "c") never share a key.
+func Key(rootHash, kind string, params ...string) string {
+	h := sha256.New()
+	write := func(s string) {
+		h.Write([]byte(strconv.Itoa(len(s))))
+		h.Write([]byte{0})
+		h.Write([]byte(s))
+	}
+	write(version.Version)
+	write(kind)
+	write(rootHash)
+	for _, p := range params {
+		write(p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the bundle stored under key, or ok=false on a miss or any error.
+// A hit refreshes the entry's mtime, so pruning evicts least-recently-used.
+func Get(key string) ([]byte, bool) {
+	dir, err := Dir()
+	if err != nil || key == "" {
+		return nil, false
+	}
+	p := filepath.Join(dir, key)
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+	now := time.Now()
+	os.Chtimes(p, now, now) // a hit is recent use: keep it out of prune's way
+	return data, true
+}
+
+// Put stores data under key and prunes the directory to maxEntries. Best-effort:
+// errors are dropped, since the caller already has the bytes it rendered.
+func Put(key string, data []byte) {
+	dir, err := Dir()
+	if err != nil || key == "" {
+		return
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return
+	}
+	if err := store.AtomicWriteFile(filepath.Join(dir, key), data); err != nil {
+		return
+	}
+	prune(dir, maxEntries)
+}
+
+// prune removes the oldest entries (by mtime, then name for determinism) until
+// at most keep remain. In-flight temp files are left to their writers.
+func prune(dir string, keep int) {
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	type entry struct {
+		name  string
+		mtime int64
+	}
+	var files []entry
+	for _, e := range ents {
+		if !e.Type().IsRegular() || filepath.Ext(e.Name()) != "" {
+			continue // entries are bare hex keys; anything with a suffix is a temp
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, entry{e.Name(), info.ModTime().UnixNano()})
+	}
+	if len(files) <= keep {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].mtime != files[j].mtime {
+			return files[i].mtime < files[j].mtime
+		}
+		return files[i].name < files[j].name
+	})
+	for _, f := range files[:len(files)-keep] {
+		os.Remove(filepath.Join(dir, f.name))
+	}
+}
@@ -0,0 +1,72 @@
+package ctxcache
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestKey_DistinctInputsNeverCollide(t *testing.T) {
+	seen := map[string]string{}
+	for name, k := range map[string]string{
+		"base":      Key("r1", "map", "a"),
+		"root":      Key("r2", "map", "a"),
+		"kind":      Key("r1", "render", "a"),
+		"param":     Key("r1", "map", "b"),
+		"split":     Key("r1", "map", "a", ""),
+		"boundary1": Key("r1", "map", "ab", "c"),
+		"boundary2": Key("r1", "map", "a", "bc"),
+	} {
+		if prev, dup := seen[k]; dup {
+			t.Errorf("%s and %s share key %s", name, prev, k)
+		}
+		seen[k] = name
+	}
+	if Key("r1", "map", "a") != Key("r1", "map", "a") {
+		t.Error("Key is not deterministic")
+	}
+}
+
+func TestGetPut_RoundTrip(t *testing.T) {
+	t.Setenv("RUNECHO_HOME", t.TempDir())
+	k := Key("root", "map")
+	if _, ok := Get(k); ok {
+		t.Fatal("hit on an empty cache")
+	}
+	Put(k, []byte("bundle"))
+	got, ok := Get(k)
+	if !ok || string(got) != "bundle" {
+		t.Fatalf("Get = %q, %v; want bundle, true", got, ok)
+	}
+	if _, ok := Get(""); ok {
+		t.Error("empty key must always miss")
+	}
+}
+
+// TestPrune_KeepsMostRecentlyUsed: pruning evicts by mtime, and a Get refreshes
+// it, so an entry read since it was written outlives newer unread ones.
+func TestPrune_KeepsMostRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		p := filepath.Join(dir, "k"+strconv.Itoa(i))
+		if err := os.WriteFile(p, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		ts := base.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(p, ts, ts)
+	}
+	os.WriteFile(filepath.Join(dir, "k9.tmp-123"), []byte("x"), 0o600) // in-flight temp
+	now := time.Now()
+	os.Chtimes(filepath.Join(dir, "k0"), now, now) // k0 was just read
+
+	prune(dir, 2)
+	for name, want := range map[string]bool{"k0": true, "k4": true, "k1": false, "k2": false, "k3": false, "k9.tmp-123": true} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if got := err == nil; got != want {
+			t.Errorf("%s present = %v, want %v", name, got, want)
+		}
+	}
+}
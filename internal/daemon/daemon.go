@@ -0,0 +1,544 @@
+// Package daemon serves watched projects' IRs to other local processes over a
+// unix-domain socket, so an editor plugin can ask the running watcher instead
+// of paying for a cold Generate on every question. One daemon can watch any
+// number of project roots, each with its own Watcher and so its own IR,
+// snapshots, and update schedule; a change in one never republishes another.
+//
+// The protocol is newline-delimited JSON, one request per line and one
+// response per line, any number of them per connection:
+//
+//	→ {"method": "root_hash", "root": "/src/app"}
+//	← {"root": "/src/app", "seq": 4, "root_hash": "…", "result": "…"}
+//
+// Methods that read a project take "root": an absolute path to the project or
+// to anything inside it, resolved to the innermost watched root containing
+// it. It may be omitted while the daemon watches exactly one project.
+//
+//   - "root_hash": the current root hash.
+//   - "ir": the whole IR, in the .ai/ir.json shape.
+//   - "neighborhood" {"path": "src/a.ts"}: the file's entry, the in-repo files
+//     it imports and the ones that import it (see ir.Dependencies), and the
+//     entries of all of those.
+//   - "rescan": a full rescan as soon as the tree is quiet (see
+//     watch.Watcher.Rescan); answers at once with when it may start.
+//
+// Methods that manage the set:
+//
+//   - "projects": every watched root with its current seq and root hash.
+//   - "add" {"root": …}: start watching a project; answers once its initial
+//     IR is built.
+//   - "remove" {"root": …}: stop watching one (an exact root, not a path
+//     inside it).
+//
+// Every answer about a project comes from a single watch.Snapshot, and the
+// response carries that snapshot's seq and root hash, so a client can key a
+// cache on them and never mix data from two states of the tree. A failed
+// request gets {"error": "…"} and the connection stays open.
+//
+// The socket is the owner's alone and asks for no credentials. To serve
+// other people, the daemon also listens on TCP (ServeTenants): there every
+// request carries a tenant's bearer token in "token", is rate limited, and
+// may only read that tenant's projects (see Tenants).
+//
+// Windows has AF_UNIX sockets too (Windows 10 1803 and later), and Go's net
+// package speaks them there, so the daemon listens on a socket file on every
+// platform rather than a named pipe.
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/store"
+	"github.com/inth3shadows/runecho/internal/watch"
+)
+
+// SocketName is the control socket's file name in the RunEcho store directory.
+const SocketName = "daemon.sock"
+
+// maxRequestBytes caps one request line. Requests are a method and a path.
+const maxRequestBytes = 64 << 10
+
+// DefaultSocketPath returns $RUNECHO_HOME/daemon.sock (see store.RunechoDir).
+func DefaultSocketPath() (string, error) {
+	dir, err := store.RunechoDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SocketName), nil
+}
+
+// Request is one line a client sends.
+type Request struct {
+	Method string `json:"method"`
+	// Root selects the project (see the package comment), or names the one
+	// to add or remove.
+	Root string `json:"root,omitempty"`
+	// Path is the file a "neighborhood" request centres on, as an IR key.
+	Path string `json:"path,omitempty"`
+	// Token is the tenant's bearer token on a ServeTenants listener; the
+	// control socket ignores it.
+	Token string `json:"token,omitempty"`
+}
+
+// Response is one line the daemon sends back. Root, Seq, and RootHash
+// identify the snapshot a project answer came from.
+type Response struct {
+	Root     string          `json:"root,omitempty"`
+	Seq      uint64          `json:"seq,omitempty"`
+	RootHash string          `json:"root_hash,omitempty"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// Neighborhood is the "neighborhood" result.
+type Neighborhood struct {
+	Path         string               `json:"path"`
+	Dependencies []string             `json:"dependencies"`
+	Dependents   []string             `json:"dependents"`
+	Files        map[string]ir.FileIR `json:"files"` // Path and every neighbour
+}
+
+// Rescan is the "rescan" result. A rescan asked for within the rate limit of
+// the last one is put off until NotBefore, not refused.
+type Rescan struct {
+	NotBefore time.Time `json:"not_before"`
+}
+
+// Project is one entry of the "projects" result, and the "add" result.
+type Project struct {
+	Root     string `json:"root"`
+	Seq      uint64 `json:"seq"`
+	RootHash string `json:"root_hash"`
+	Files    int    `json:"files"`
+}
+
+// OpenFunc builds the Watcher for a project root — absolute and cleaned —
+// with that project's own configuration. The Server runs it.
+type OpenFunc func(ctx context.Context, root string) (*watch.Watcher, error)
+
+// Server watches a set of projects and answers control requests about them.
+type Server struct {
+	ctx  context.Context
+	open OpenFunc
+	wg   sync.WaitGroup
+
+	mu       sync.Mutex
+	projects map[string]*project
+}
+
+type project struct {
+	w      *watch.Watcher
+	cancel context.CancelFunc
+}
+
+// NewServer returns a Server with no projects. ctx bounds every project it
+// will watch: when ctx is done, all of them stop (see Wait).
+func NewServer(ctx context.Context, open OpenFunc) *Server {
+	return &Server{ctx: ctx, open: open, projects: make(map[string]*project)}
+}
+
+// Add starts watching root. It returns once the project's initial IR is
+// built, or with an error if root is not a directory, is already watched, or
+// fails to open.
+func (s *Server) Add(root string) (*watch.Watcher, error) {
+	if !filepath.IsAbs(root) {
+		return nil, fmt.Errorf("root %q is not an absolute path", root)
+	}
+	root = filepath.Clean(root)
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("root %q is not a directory", root)
+	}
+	if s.lookup(root) != nil {
+		return nil, fmt.Errorf("%s is already watched", root)
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	w, err := s.open(ctx, root)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s.mu.Lock()
+	if _, dup := s.projects[root]; dup {
+		// Two adds of one root raced through the initial build.
+		s.mu.Unlock()
+		cancel()
+		return nil, fmt.Errorf("%s is already watched", root)
+	}
+	s.projects[root] = &project{w: w, cancel: cancel}
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		w.Run(ctx)
+	}()
+	return w, nil
+}
+
+// Remove stops watching root, which must be a watched root exactly.
+func (s *Server) Remove(root string) error {
+	root = filepath.Clean(root)
+	s.mu.Lock()
+	p, ok := s.projects[root]
+	delete(s.projects, root)
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%s is not watched", root)
+	}
+	p.cancel()
+	return nil
+}
+
+// Projects returns the watched roots, sorted.
+func (s *Server) Projects() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	roots := make([]string, 0, len(s.projects))
+	for root := range s.projects {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// Wait blocks until every project's watch loop has returned, which happens
+// once the Server's ctx is done.
+func (s *Server) Wait() { s.wg.Wait() }
+
+func (s *Server) lookup(root string) *watch.Watcher {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.projects[root]; ok {
+		return p.w
+	}
+	return nil
+}
+
+// resolve picks the project a request reads: the innermost watched root
+// containing path, or the only project when path is empty.
+func (s *Server) resolve(path string) (string, *watch.Watcher, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if path == "" {
+		if len(s.projects) == 1 {
+			for root, p := range s.projects {
+				return root, p.w, nil
+			}
+		}
+		return "", nil, fmt.Errorf("root required: the daemon watches %d projects", len(s.projects))
+	}
+	if !filepath.IsAbs(path) {
+		return "", nil, fmt.Errorf("root %q is not an absolute path", path)
+	}
+	path = filepath.Clean(path)
+	best := ""
+	for root := range s.projects {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || !filepath.IsLocal(rel) {
+			continue
+		}
+		if len(root) > len(best) {
+			best = root
+		}
+	}
+	if best == "" {
+		return "", nil, fmt.Errorf("%s is not inside a watched project", path)
+	}
+	return best, s.projects[best].w, nil
+}
+
+// Listen opens the control socket at path, readable and writable by its owner
+// only: it hands out the project's whole symbol table. A socket file left
+// behind by a daemon that died is replaced; one with a live daemon behind it
+// is an error, not a takeover.
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("create socket dir: %w", err)
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("remove stale socket: %w", err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("restrict socket: %w", err)
+	}
+	return ln, nil
+}
+
+// Serve accepts connections on ln until ctx is done, then closes ln and every
+// open connection, waits for their handlers to return, and returns nil. Every
+// connection is trusted: ln must be the owner-only control socket.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	return s.serve(ctx, ln, nil)
+}
+
+// ServeTenants is Serve for a listener others can reach: each request must
+// carry the token of one of ts, and is answered within that tenant's access
+// and limits. Connections past ts.MaxConns are turned away.
+func (s *Server) ServeTenants(ctx context.Context, ln net.Listener, ts *Tenants) error {
+	return s.serve(ctx, ln, ts)
+}
+
+func (s *Server) serve(ctx context.Context, ln net.Listener, ts *Tenants) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	conns := make(map[net.Conn]bool)
+	stop := context.AfterFunc(ctx, func() {
+		ln.Close()
+		mu.Lock()
+		for c := range conns {
+			c.Close()
+		}
+		mu.Unlock()
+	})
+	defer stop()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			wg.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		full := ts != nil && len(conns) >= ts.MaxConns
+		if !full {
+			conns[conn] = true
+		}
+		mu.Unlock()
+		if full {
+			conn.SetWriteDeadline(time.Now().Add(time.Second))
+			json.NewEncoder(conn).Encode(Response{Error: fmt.Sprintf("too many connections (limit %d)", ts.MaxConns)})
+			conn.Close()
+			continue
+		}
+		if ctx.Err() != nil {
+			conn.Close() // accepted as the shutdown closed the others
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(conn, ts)
+			mu.Lock()
+			delete(conns, conn)
+			mu.Unlock()
+			conn.Close()
+		}()
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn, ts *Tenants) {
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 0, 4096), maxRequestBytes)
+	enc := json.NewEncoder(conn)
+	for {
+		if ts != nil {
+			conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		}
+		if !sc.Scan() {
+			break
+		}
+		var req Request
+		resp := Response{}
+		switch err := json.Unmarshal(sc.Bytes(), &req); {
+		case err != nil:
+			resp.Error = fmt.Sprintf("bad request: %v", err)
+		case ts != nil:
+			resp = s.handleTenant(ts, req)
+		default:
+			resp = s.Handle(req)
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+	if errors.Is(sc.Err(), bufio.ErrTooLong) {
+		enc.Encode(Response{Error: fmt.Sprintf("request exceeds %d bytes", maxRequestBytes)})
+	}
+}
+
+// Handle answers one request.
+func (s *Server) Handle(req Request) Response {
+	switch req.Method {
+	case "projects":
+		var out []Project
+		for _, root := range s.Projects() {
+			if w := s.lookup(root); w != nil {
+				out = append(out, projectInfo(root, w.Snapshot()))
+			}
+		}
+		return result(Response{}, emptyIfNil(out))
+	case "add":
+		w, err := s.Add(req.Root)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		return result(Response{}, projectInfo(filepath.Clean(req.Root), w.Snapshot()))
+	case "remove":
+		if err := s.Remove(req.Root); err != nil {
+			return Response{Error: err.Error()}
+		}
+		return result(Response{}, filepath.Clean(req.Root))
+	}
+
+	root, w, err := s.resolve(req.Root)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	snap := w.Snapshot()
+	resp := Response{Root: root, Seq: snap.Seq, RootHash: snap.IR.RootHash}
+	switch req.Method {
+	case "root_hash":
+		return result(resp, snap.IR.RootHash)
+	case "ir":
+		return result(resp, snap.IR)
+	case "neighborhood":
+		n, err := neighborhood(snap.IR, req.Path)
+		if err != nil {
+			resp.Error = err.Error()
+			return resp
+		}
+		return result(resp, n)
+	case "rescan":
+		return result(resp, Rescan{NotBefore: w.Rescan()})
+	}
+	resp.Error = fmt.Sprintf("unknown method %q", req.Method)
+	return resp
+}
+
+// result fills resp.Result with v's JSON.
+func result(resp Response, v any) Response {
+	data, err := json.Marshal(v)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+	resp.Result = data
+	return resp
+}
+
+func projectInfo(root string, snap *watch.Snapshot) Project {
+	return Project{Root: root, Seq: snap.Seq, RootHash: snap.IR.RootHash, Files: len(snap.IR.Files)}
+}
+
+func neighborhood(irData *ir.IR, path string) (*Neighborhood, error) {
+	if path == "" {
+		return nil, errors.New("neighborhood: missing path")
+	}
+	self, ok := irData.Files[path]
+	if !ok {
+		return nil, fmt.Errorf("neighborhood: %q is not in the IR", path)
+	}
+	n := &Neighborhood{
+		Path:         path,
+		Dependencies: emptyIfNil(irData.Dependencies(path)),
+		Dependents:   emptyIfNil(irData.Dependents(path)),
+		Files:        map[string]ir.FileIR{path: self},
+	}
+	for _, list := range [][]string{n.Dependencies, n.Dependents} {
+		for _, p := range list {
+			n.Files[p] = irData.Files[p]
+		}
+	}
+	return n, nil
+}
+
+func emptyIfNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// Client is a connection to a running daemon. It is not safe for concurrent
+// use; open one per goroutine.
+type Client struct {
+	conn net.Conn
+	sc   *bufio.Scanner
+	// Token is sent with every request that carries none of its own.
+	Token string
+}
+
+// Dial connects to the daemon's control socket.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("no daemon on %s: %w", path, err)
+	}
+	return newClient(conn), nil
+}
+
+// DialTCP connects to a daemon's tenant listener at addr, over TLS unless
+// tlsConfig is nil, and sends token with every request.
+func DialTCP(addr string, tlsConfig *tls.Config, token string) (*Client, error) {
+	var (
+		conn net.Conn
+		err  error
+	)
+	if tlsConfig != nil {
+		conn, err = tls.Dial("tcp", addr, tlsConfig)
+	} else {
+		conn, err = net.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no daemon on %s: %w", addr, err)
+	}
+	c := newClient(conn)
+	c.Token = token
+	return c, nil
+}
+
+func newClient(conn net.Conn) *Client {
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<30)
+	return &Client{conn: conn, sc: sc}
+}
+
+// Close closes the connection.
+func (c *Client) Close() error { return c.conn.Close() }
+
+// Call sends req and returns the daemon's response. A response carrying an
+// error is returned as-is; err is reserved for transport failures.
+func (c *Client) Call(req Request) (*Response, error) {
+	if req.Token == "" {
+		req.Token = c.Token
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.conn.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+	if !c.sc.Scan() {
+		if err := c.sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("daemon closed the connection")
+	}
+	var resp Response
+	if err := json.Unmarshal(c.sc.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,247 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/watch"
+)
+
+// writeProject writes a small JS project and returns its root.
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+var jsProject = map[string]string{
+	"src/a.js": "import { b } from './b.js'\nexport function a() { return b() }\n",
+	"src/b.js": "export function b() { return 1 }\n",
+	"src/c.js": "import { a } from './a.js'\na()\n",
+}
+
+func openWatcher(ctx context.Context, root string) (*watch.Watcher, error) {
+	return watch.New(ctx, watch.Config{Root: root, Generator: ir.NewGenerator(ir.GeneratorConfig{})})
+}
+
+// serve starts a Server watching roots and returns it with its socket path.
+func serve(t *testing.T, roots ...string) (*Server, string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := NewServer(ctx, openWatcher)
+	for _, root := range roots {
+		if _, err := srv.Add(root); err != nil {
+			cancel()
+			t.Fatal(err)
+		}
+	}
+	sock := filepath.Join(t.TempDir(), SocketName)
+	ln, err := Listen(sock)
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+		srv.Wait()
+	})
+	return srv, sock
+}
+
+func dial(t *testing.T, sock string) *Client {
+	t.Helper()
+	c, err := Dial(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func call(t *testing.T, c *Client, req Request) *Response {
+	t.Helper()
+	resp, err := c.Call(req)
+	if err != nil {
+		t.Fatalf("Call(%+v): %v", req, err)
+	}
+	return resp
+}
+
+func TestServer_Methods(t *testing.T) {
+	root := writeProject(t, jsProject)
+	srv, sock := serve(t, root)
+	c := dial(t, sock)
+	snap := srv.lookup(root).Snapshot()
+
+	resp := call(t, c, Request{Method: "root_hash"})
+	var hash string
+	if err := json.Unmarshal(resp.Result, &hash); err != nil || hash != snap.IR.RootHash {
+		t.Errorf("root_hash = %s (%v), want %q", resp.Result, err, snap.IR.RootHash)
+	}
+	if resp.Root != root || resp.Seq != snap.Seq || resp.RootHash != snap.IR.RootHash {
+		t.Errorf("envelope = root %q seq %d hash %q, want %q %d %q", resp.Root, resp.Seq, resp.RootHash, root, snap.Seq, snap.IR.RootHash)
+	}
+
+	resp = call(t, c, Request{Method: "ir"})
+	var got ir.IR
+	if err := json.Unmarshal(resp.Result, &got); err != nil {
+		t.Fatalf("ir result: %v", err)
+	}
+	if err := got.VerifyRootHash(); err != nil || len(got.Files) != 3 {
+		t.Errorf("ir result: %d files, verify %v", len(got.Files), err)
+	}
+
+	// The same connection keeps answering.
+	resp = call(t, c, Request{Method: "neighborhood", Path: "src/a.js"})
+	var n Neighborhood
+	if err := json.Unmarshal(resp.Result, &n); err != nil {
+		t.Fatalf("neighborhood result: %v (%s)", err, resp.Error)
+	}
+	if !slices.Equal(n.Dependencies, []string{"src/b.js"}) || !slices.Equal(n.Dependents, []string{"src/c.js"}) {
+		t.Errorf("neighborhood = deps %v dependents %v", n.Dependencies, n.Dependents)
+	}
+	if len(n.Files) != 3 || n.Files["src/b.js"].Hash != snap.IR.Files["src/b.js"].Hash {
+		t.Errorf("neighborhood files = %v", n.Files)
+	}
+
+	resp = call(t, c, Request{Method: "rescan"})
+	var r Rescan
+	if err := json.Unmarshal(resp.Result, &r); err != nil || r.NotBefore.IsZero() {
+		t.Errorf("rescan result = %s (%v)", resp.Result, err)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	_, sock := serve(t, writeProject(t, jsProject))
+	c := dial(t, sock)
+	for _, tc := range []struct {
+		req  Request
+		want string
+	}{
+		{Request{Method: "reindex"}, "unknown method"},
+		{Request{Method: "neighborhood"}, "missing path"},
+		{Request{Method: "neighborhood", Path: "nope.js"}, "not in the IR"},
+	} {
+		resp := call(t, c, tc.req)
+		if !strings.Contains(resp.Error, tc.want) || resp.Result != nil {
+			t.Errorf("%+v: error %q, result %s; want error containing %q", tc.req, resp.Error, resp.Result, tc.want)
+		}
+	}
+	if _, err := c.conn.Write([]byte("{not json\n")); err != nil {
+		t.Fatal(err)
+	}
+	if !c.sc.Scan() || !strings.Contains(c.sc.Text(), "bad request") {
+		t.Errorf("malformed line answered with %q", c.sc.Text())
+	}
+}
+
+// TestServer_MultiProject watches two projects from one daemon, adds and
+// removes one at runtime, and checks that each answers from its own IR.
+func TestServer_MultiProject(t *testing.T) {
+	first := writeProject(t, jsProject)
+	second := writeProject(t, map[string]string{"main.go": "package main\n\nfunc main() {}\n"})
+	_, sock := serve(t, first)
+	c := dial(t, sock)
+
+	resp := call(t, c, Request{Method: "add", Root: second})
+	var added Project
+	if err := json.Unmarshal(resp.Result, &added); err != nil || added.Root != second || added.Files != 1 {
+		t.Fatalf("add = %+v (%s %v)", added, resp.Error, err)
+	}
+	if resp := call(t, c, Request{Method: "add", Root: second}); !strings.Contains(resp.Error, "already watched") {
+		t.Errorf("second add: error %q", resp.Error)
+	}
+
+	resp = call(t, c, Request{Method: "projects"})
+	var projects []Project
+	if err := json.Unmarshal(resp.Result, &projects); err != nil || len(projects) != 2 {
+		t.Fatalf("projects = %s (%v)", resp.Result, err)
+	}
+
+	// With two projects a root is required; a path inside one selects it.
+	if resp := call(t, c, Request{Method: "root_hash"}); !strings.Contains(resp.Error, "root required") {
+		t.Errorf("rootless query: error %q", resp.Error)
+	}
+	resp = call(t, c, Request{Method: "ir", Root: filepath.Join(first, "src")})
+	var got ir.IR
+	if err := json.Unmarshal(resp.Result, &got); err != nil || resp.Root != first || len(got.Files) != 3 {
+		t.Errorf("ir of first via subdir: root %q, %d files (%v)", resp.Root, len(got.Files), err)
+	}
+	resp = call(t, c, Request{Method: "ir", Root: second})
+	if err := json.Unmarshal(resp.Result, &got); err != nil || resp.Root != second || len(got.Files) != 1 {
+		t.Errorf("ir of second: root %q, %d files (%v)", resp.Root, len(got.Files), err)
+	}
+	if resp := call(t, c, Request{Method: "ir", Root: t.TempDir()}); !strings.Contains(resp.Error, "not inside a watched project") {
+		t.Errorf("outside query: error %q", resp.Error)
+	}
+
+	if resp := call(t, c, Request{Method: "remove", Root: first}); resp.Error != "" {
+		t.Fatalf("remove: %s", resp.Error)
+	}
+	if resp := call(t, c, Request{Method: "remove", Root: first}); !strings.Contains(resp.Error, "not watched") {
+		t.Errorf("second remove: error %q", resp.Error)
+	}
+	// One project left: the root may be omitted again.
+	if resp := call(t, c, Request{Method: "root_hash"}); resp.Error != "" || resp.Root != second {
+		t.Errorf("after remove: root %q error %q", resp.Root, resp.Error)
+	}
+}
+
+func TestServer_AddRejects(t *testing.T) {
+	srv, _ := serve(t)
+	file := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, root := range []string{"relative/dir", file, filepath.Join(t.TempDir(), "missing")} {
+		if _, err := srv.Add(root); err == nil {
+			t.Errorf("Add(%q) succeeded", root)
+		}
+	}
+}
+
+func TestListen_RefusesLiveDaemon(t *testing.T) {
+	_, sock := serve(t)
+	if _, err := Listen(sock); err == nil || !strings.Contains(err.Error(), "already listening") {
+		t.Fatalf("second Listen = %v, want already-listening error", err)
+	}
+}
+
+func TestListen_ReplacesStaleSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), SocketName)
+	if err := os.WriteFile(sock, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := Listen(sock)
+	if err != nil {
+		t.Fatalf("Listen over a stale file: %v", err)
+	}
+	defer ln.Close()
+	info, err := os.Stat(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket mode = %v, want 0600", perm)
+	}
+}
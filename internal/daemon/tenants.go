@@ -0,0 +1,248 @@
+package daemon
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// A daemon serving more than its owner — one runecho for several teams, over
+// TCP — answers only requests that carry a tenant's bearer token, and only
+// about the projects that tenant may read. The tenants file declares them:
+//
+//	{"rate": 20, "burst": 40, "max_conns": 64,
+//	 "tenants": [
+//	   {"name": "web", "token_sha256": "…", "projects": ["/srv/src/web"]},
+//	   {"name": "ops", "token_sha256": "…", "projects": ["*"], "admin": true}]}
+//
+// The file holds SHA-256 digests of the tokens, never the tokens, so reading
+// it does not let anyone in. A project entry grants every watched root at or
+// under it; "*" grants all. Only an admin tenant may "add" or "remove".
+//
+// Limits apply per listener: max_conns connections at once, and per tenant a
+// token bucket of burst requests refilled at rate per second. A request over
+// the rate is answered with an error, not queued.
+
+// DefaultRate, DefaultBurst, and DefaultMaxConns are the limits a tenants
+// file that sets none gets.
+const (
+	DefaultRate     = 20
+	DefaultBurst    = 40
+	DefaultMaxConns = 64
+)
+
+// idleTimeout closes a tenant connection that sends nothing for this long,
+// so idle or stalled clients cannot hold the connection slots.
+const idleTimeout = 2 * time.Minute
+
+// maxTenantsBytes caps the tenants file read.
+const maxTenantsBytes = 1 << 20
+
+// Tenants is a parsed tenants file (see ServeTenants).
+type Tenants struct {
+	// MaxConns caps the connections a tenant listener holds open at once.
+	MaxConns int
+	tenants  []*tenant
+}
+
+type tenant struct {
+	name     string
+	sum      [sha256.Size]byte
+	projects []string // cleaned absolute roots
+	all      bool
+	admin    bool
+	limit    *bucket
+}
+
+type tenantsFile struct {
+	Rate     float64 `json:"rate"`
+	Burst    int     `json:"burst"`
+	MaxConns int     `json:"max_conns"`
+	Tenants  []struct {
+		Name        string   `json:"name"`
+		TokenSHA256 string   `json:"token_sha256"`
+		Projects    []string `json:"projects"`
+		Admin       bool     `json:"admin"`
+	} `json:"tenants"`
+}
+
+// LoadTenants reads and parses the tenants file at path.
+func LoadTenants(path string) (*Tenants, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxTenantsBytes {
+		return nil, fmt.Errorf("tenants file %s exceeds %d bytes", path, maxTenantsBytes)
+	}
+	t, err := ParseTenants(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// ParseTenants parses a tenants file's content.
+func ParseTenants(data []byte) (*Tenants, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var f tenantsFile
+	if err := dec.Decode(&f); err != nil {
+		return nil, err
+	}
+	switch {
+	case f.Rate < 0 || f.Burst < 0 || f.MaxConns < 0:
+		return nil, errors.New("rate, burst, and max_conns cannot be negative")
+	case len(f.Tenants) == 0:
+		return nil, errors.New("no tenants")
+	}
+	if f.Rate == 0 {
+		f.Rate = DefaultRate
+	}
+	if f.Burst == 0 {
+		f.Burst = DefaultBurst
+	}
+	if f.MaxConns == 0 {
+		f.MaxConns = DefaultMaxConns
+	}
+	out := &Tenants{MaxConns: f.MaxConns}
+	names := make(map[string]bool)
+	sums := make(map[[sha256.Size]byte]bool)
+	for i, d := range f.Tenants {
+		if d.Name == "" {
+			return nil, fmt.Errorf("tenants[%d]: missing name", i)
+		}
+		if names[d.Name] {
+			return nil, fmt.Errorf("tenants[%d]: duplicate name %q", i, d.Name)
+		}
+		names[d.Name] = true
+		t := &tenant{name: d.Name, admin: d.Admin, limit: newBucket(f.Rate, f.Burst)}
+		sum, err := hex.DecodeString(d.TokenSHA256)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("tenant %q: token_sha256 must be a hex SHA-256", d.Name)
+		}
+		copy(t.sum[:], sum)
+		if sums[t.sum] {
+			return nil, fmt.Errorf("tenant %q: token shared with another tenant", d.Name)
+		}
+		sums[t.sum] = true
+		for _, p := range d.Projects {
+			switch {
+			case p == "*":
+				t.all = true
+			case filepath.IsAbs(p):
+				t.projects = append(t.projects, filepath.Clean(p))
+			default:
+				return nil, fmt.Errorf("tenant %q: project %q is not an absolute path", d.Name, p)
+			}
+		}
+		out.tenants = append(out.tenants, t)
+	}
+	return out, nil
+}
+
+// authenticate returns the tenant token belongs to. Every tenant's digest is
+// compared in constant time, so the time taken says nothing about which
+// digests are close to the token's.
+func (ts *Tenants) authenticate(token string) *tenant {
+	if token == "" {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(token))
+	var found *tenant
+	for _, t := range ts.tenants {
+		if subtle.ConstantTimeCompare(sum[:], t.sum[:]) == 1 {
+			found = t
+		}
+	}
+	return found
+}
+
+// reads reports whether t may read the project at root.
+func (t *tenant) reads(root string) bool {
+	if t.all {
+		return true
+	}
+	for _, p := range t.projects {
+		if p == root {
+			return true
+		}
+		if rel, err := filepath.Rel(p, root); err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleTenant answers req for the tenant whose token it carries.
+func (s *Server) handleTenant(ts *Tenants, req Request) Response {
+	t := ts.authenticate(req.Token)
+	if t == nil {
+		return Response{Error: "unauthorized"}
+	}
+	if wait := t.limit.take(time.Now()); wait > 0 {
+		return Response{Error: fmt.Sprintf("rate limit exceeded; retry in %s", wait.Round(time.Millisecond))}
+	}
+	switch req.Method {
+	case "add", "remove":
+		if !t.admin {
+			return Response{Error: fmt.Sprintf("forbidden: tenant %q may not %s projects", t.name, req.Method)}
+		}
+		return s.Handle(req)
+	case "projects":
+		var out []Project
+		for _, root := range s.Projects() {
+			if w := s.lookup(root); w != nil && t.reads(root) {
+				out = append(out, projectInfo(root, w.Snapshot()))
+			}
+		}
+		return result(Response{}, emptyIfNil(out))
+	}
+	if req.Root == "" {
+		return Response{Error: "root required"}
+	}
+	root, _, err := s.resolve(req.Root)
+	if err != nil || !t.reads(root) {
+		// The same answer for a project that is not watched and one that is
+		// not the tenant's, so a tenant cannot probe for other teams' roots.
+		return Response{Error: "forbidden: not a project this token may read"}
+	}
+	req.Root = root
+	return s.Handle(req)
+}
+
+// bucket is a token-bucket rate limiter.
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate float64, burst int) *bucket {
+	return &bucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// take spends one token at now, returning 0, or how long until one is due.
+func (b *bucket) take(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
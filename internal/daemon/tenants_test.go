@@ -0,0 +1,142 @@
+package daemon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+// serveTenants starts a Server watching roots behind a TCP tenant listener
+// configured by tenantsJSON, and returns the listener's address.
+func serveTenants(t *testing.T, tenantsJSON string, roots ...string) string {
+	t.Helper()
+	ts, err := ParseTenants([]byte(tenantsJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := NewServer(ctx, openWatcher)
+	for _, root := range roots {
+		if _, err := srv.Add(root); err != nil {
+			cancel()
+			t.Fatal(err)
+		}
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.ServeTenants(ctx, ln, ts) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("ServeTenants: %v", err)
+		}
+		srv.Wait()
+	})
+	return ln.Addr().String()
+}
+
+func dialTenant(t *testing.T, addr, token string) *Client {
+	t.Helper()
+	c, err := DialTCP(addr, nil, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func tokenSum(token string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(token)))
+}
+
+func TestServeTenants_Access(t *testing.T) {
+	web := writeProject(t, jsProject)
+	ops := writeProject(t, jsProject)
+	addr := serveTenants(t, fmt.Sprintf(`{"tenants": [
+		{"name": "web", "token_sha256": %q, "projects": [%q]},
+		{"name": "ops", "token_sha256": %q, "projects": ["*"], "admin": true}]}`,
+		tokenSum("web-token"), web, tokenSum("ops-token")), web, ops)
+
+	for _, token := range []string{"", "wrong"} {
+		if resp := call(t, dialTenant(t, addr, token), Request{Method: "projects"}); resp.Error != "unauthorized" {
+			t.Errorf("token %q: error %q, want unauthorized", token, resp.Error)
+		}
+	}
+
+	c := dialTenant(t, addr, "web-token")
+	var projects []Project
+	resp := call(t, c, Request{Method: "projects"})
+	if err := json.Unmarshal(resp.Result, &projects); err != nil || len(projects) != 1 || projects[0].Root != web {
+		t.Errorf("web projects = %s (%v), want only %s", resp.Result, err, web)
+	}
+	if resp := call(t, c, Request{Method: "root_hash", Root: web}); resp.Error != "" || resp.Root != web {
+		t.Errorf("web root_hash of its project: %+v", resp)
+	}
+	for _, req := range []Request{
+		{Method: "root_hash", Root: ops},
+		{Method: "root_hash", Root: "/not/watched"},
+	} {
+		if resp := call(t, c, req); !strings.HasPrefix(resp.Error, "forbidden") || resp.Result != nil {
+			t.Errorf("web %+v: error %q, result %s; want forbidden", req, resp.Error, resp.Result)
+		}
+	}
+	if resp := call(t, c, Request{Method: "root_hash"}); resp.Error != "root required" {
+		t.Errorf("web root_hash without a root: error %q", resp.Error)
+	}
+	if resp := call(t, c, Request{Method: "remove", Root: web}); !strings.HasPrefix(resp.Error, "forbidden") {
+		t.Errorf("web remove: error %q, want forbidden", resp.Error)
+	}
+
+	admin := dialTenant(t, addr, "ops-token")
+	if resp := call(t, admin, Request{Method: "remove", Root: web}); resp.Error != "" {
+		t.Errorf("ops remove: %s", resp.Error)
+	}
+	if resp := call(t, admin, Request{Method: "root_hash", Root: ops}); resp.Error != "" {
+		t.Errorf("ops root_hash: %s", resp.Error)
+	}
+}
+
+func TestServeTenants_Limits(t *testing.T) {
+	root := writeProject(t, jsProject)
+	addr := serveTenants(t, fmt.Sprintf(`{"rate": 0.001, "burst": 1, "max_conns": 1,
+		"tenants": [{"name": "web", "token_sha256": %q, "projects": ["*"]}]}`, tokenSum("t")), root)
+
+	c := dialTenant(t, addr, "t")
+	if resp := call(t, c, Request{Method: "root_hash", Root: root}); resp.Error != "" {
+		t.Fatalf("first request: %s", resp.Error)
+	}
+	if resp := call(t, c, Request{Method: "root_hash", Root: root}); !strings.Contains(resp.Error, "rate limit") {
+		t.Errorf("second request: error %q, want a rate limit", resp.Error)
+	}
+
+	// c still holds the only connection slot.
+	if resp := call(t, dialTenant(t, addr, "t"), Request{Method: "projects"}); !strings.Contains(resp.Error, "too many connections") {
+		t.Errorf("second connection: error %q, want too many connections", resp.Error)
+	}
+}
+
+func TestParseTenants_Rejects(t *testing.T) {
+	sum := tokenSum("t")
+	for _, tc := range []struct{ json, want string }{
+		{`{"tenants": []}`, "no tenants"},
+		{`{"tenants": [{"token_sha256": "` + sum + `"}]}`, "missing name"},
+		{`{"tenants": [{"name": "a", "token_sha256": "abc"}]}`, "hex SHA-256"},
+		{`{"tenants": [{"name": "a", "token_sha256": "` + sum + `", "projects": ["src"]}]}`, "not an absolute path"},
+		{`{"tenants": [{"name": "a", "token_sha256": "` + sum + `"}, {"name": "a", "token_sha256": "` + tokenSum("u") + `"}]}`, "duplicate name"},
+		{`{"tenants": [{"name": "a", "token_sha256": "` + sum + `"}, {"name": "b", "token_sha256": "` + sum + `"}]}`, "shared"},
+		{`{"rate": -1, "tenants": [{"name": "a", "token_sha256": "` + sum + `"}]}`, "negative"},
+		{`{"tenants": [{"name": "a", "token": "t"}]}`, "unknown field"},
+	} {
+		if _, err := ParseTenants([]byte(tc.json)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error %v, want one containing %q", tc.json, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+// Package gitsource reads a commit's tree straight from a repository's object
+// store, as an fs.FS, so the IR of any ref can be generated without checking
+// it out: a historical commit, or two branches compared on a server that has
+// only a bare clone. Nothing is written to disk, and a blob is read only when
+// the walk asks for it.
+package gitsource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inth3shadows/runecho/internal/gitutil"
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// Generate builds, with gen, the IR of ref's tree in the repository at dir
+// (a working tree or a bare repository). ctx bounds the whole run, git
+// included.
+func Generate(ctx context.Context, gen *ir.Generator, dir, ref string) (*ir.IR, ir.Stats, error) {
+	t, err := Open(ctx, dir, ref)
+	if err != nil {
+		return nil, ir.Stats{}, err
+	}
+	defer t.Close()
+	return gen.GenerateFSCtx(ctx, t)
+}
+
+// A Tree is the tree of one commit. It implements fs.FS, fs.ReadDirFS,
+// fs.ReadFileFS, and fs.StatFS, and is safe for concurrent use.
+//
+// Regular files and symlinks are its files; a symlink reads as its target
+// path, and is never resolved, so Stat reports it as the link. A submodule,
+// whose commit lives in another repository, is left out, as is any entry
+// whose path fs.ValidPath rejects (a ".." a hostile tree may carry).
+type Tree struct {
+	// Commit is the full hash of the commit the ref named.
+	Commit string
+
+	entries map[string]*entry // name → entry, "." the root
+	cat     *catFile
+}
+
+// Open resolves ref in the repository at dir and lists its commit's tree.
+// The Tree holds a git process open for reading blobs until Close; ctx bounds
+// that process too.
+func Open(ctx context.Context, dir, ref string) (*Tree, error) {
+	out, err := run(ctx, dir, "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("gitsource: resolve %s: %w", ref, err)
+	}
+	t := &Tree{
+		Commit:  strings.TrimSpace(string(out)),
+		entries: map[string]*entry{".": {name: ".", mode: fs.ModeDir | 0o555}},
+	}
+	out, err = run(ctx, dir, "ls-tree", "-r", "-z", "-l", "--full-tree", t.Commit)
+	if err != nil {
+		return nil, fmt.Errorf("gitsource: list %s: %w", t.Commit, err)
+	}
+	for _, rec := range bytes.Split(out, []byte{0}) {
+		if len(rec) == 0 {
+			continue
+		}
+		if err := t.add(string(rec)); err != nil {
+			return nil, fmt.Errorf("gitsource: list %s: %w", t.Commit, err)
+		}
+	}
+	for _, e := range t.entries {
+		sort.Slice(e.children, func(i, j int) bool { return e.children[i].name < e.children[j].name })
+	}
+	if t.cat, err = startCatFile(ctx, dir); err != nil {
+		return nil, fmt.Errorf("gitsource: %w", err)
+	}
+	return t, nil
+}
+
+// add records one `ls-tree -l` line: "<mode> <type> <object> <size>\t<path>".
+func (t *Tree) add(rec string) error {
+	meta, name, ok := strings.Cut(rec, "\t")
+	fields := strings.Fields(meta)
+	if !ok || len(fields) != 4 {
+		return fmt.Errorf("unexpected ls-tree line %q", rec)
+	}
+	var mode fs.FileMode
+	switch fields[0] {
+	case "100644":
+		mode = 0o444
+	case "100755":
+		mode = 0o555
+	case "120000":
+		mode = fs.ModeSymlink | 0o777
+	default:
+		return nil // a submodule
+	}
+	if !fs.ValidPath(name) || name == "." {
+		return nil
+	}
+	size, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return fmt.Errorf("unexpected ls-tree line %q", rec)
+	}
+	e := &entry{name: path.Base(name), mode: mode, size: size, oid: fields[2]}
+	if _, dup := t.entries[name]; dup {
+		return nil
+	}
+	t.entries[name] = e
+	// Create the directories above the file, stopping at the first that
+	// already exists.
+	for dir := path.Dir(name); ; dir = path.Dir(dir) {
+		parent, ok := t.entries[dir]
+		if !ok {
+			parent = &entry{name: path.Base(dir), mode: fs.ModeDir | 0o555}
+			t.entries[dir] = parent
+		}
+		if !parent.IsDir() {
+			return fmt.Errorf("%s is both a file and a directory", dir)
+		}
+		parent.children = append(parent.children, e)
+		if ok {
+			return nil
+		}
+		e = parent
+	}
+}
+
+// Close stops the Tree's git process. The Tree is unusable afterwards.
+func (t *Tree) Close() error {
+	return t.cat.close()
+}
+
+// lookup returns the entry named name, or an *fs.PathError for op.
+func (t *Tree) lookup(op, name string) (*entry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	e, ok := t.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return e, nil
+}
+
+// Open opens the file or directory name.
+func (t *Tree) Open(name string) (fs.File, error) {
+	e, err := t.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if e.IsDir() {
+		return &dirFile{e: e}, nil
+	}
+	data, err := t.cat.blob(e.oid)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &blobFile{e: e, Reader: bytes.NewReader(data)}, nil
+}
+
+// ReadFile returns the contents of the file name.
+func (t *Tree) ReadFile(name string) ([]byte, error) {
+	e, err := t.lookup("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if e.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	data, err := t.cat.blob(e.oid)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// Stat describes the file or directory name without reading it.
+func (t *Tree) Stat(name string) (fs.FileInfo, error) {
+	e, err := t.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ReadDir lists the directory name, sorted by name.
+func (t *Tree) ReadDir(name string) ([]fs.DirEntry, error) {
+	e, err := t.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return e.list(), nil
+}
+
+// entry is one file or directory of a Tree; it is its own fs.FileInfo and
+// fs.DirEntry. A tree has no modification times: ModTime is the zero time.
+type entry struct {
+	name     string
+	mode     fs.FileMode
+	size     int64
+	oid      string   // the blob, for a file
+	children []*entry // for a directory, sorted by name
+}
+
+func (e *entry) Name() string               { return e.name }
+func (e *entry) Size() int64                { return e.size }
+func (e *entry) Mode() fs.FileMode          { return e.mode }
+func (e *entry) ModTime() time.Time         { return time.Time{} }
+func (e *entry) IsDir() bool                { return e.mode.IsDir() }
+func (e *entry) Sys() any                   { return nil }
+func (e *entry) Type() fs.FileMode          { return e.mode.Type() }
+func (e *entry) Info() (fs.FileInfo, error) { return e, nil }
+
+func (e *entry) list() []fs.DirEntry {
+	list := make([]fs.DirEntry, len(e.children))
+	for i, c := range e.children {
+		list[i] = c
+	}
+	return list
+}
+
+// blobFile is an open file: its blob, read in full.
+type blobFile struct {
+	e *entry
+	*bytes.Reader
+}
+
+func (f *blobFile) Stat() (fs.FileInfo, error) { return f.e, nil }
+func (f *blobFile) Close() error               { return nil }
+
+// dirFile is an open directory.
+type dirFile struct {
+	e   *entry
+	off int // children already returned by ReadDir
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) { return d.e, nil }
+func (d *dirFile) Close() error               { return nil }
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.e.name, Err: errors.New("is a directory")}
+}
+
+// ReadDir follows the fs.ReadDirFile contract.
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.e.list()[d.off:]
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && n < len(rest) {
+		rest = rest[:n]
+	}
+	d.off += len(rest)
+	return rest, nil
+}
+
+// catFile is a running `git cat-file --batch`, serving one blob at a time.
+type catFile struct {
+	mu  sync.Mutex
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Reader
+}
+
+func startCatFile(ctx context.Context, dir string) (*catFile, error) {
+	cmd := gitutil.Command(ctx, dir, "cat-file", "--batch")
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start git cat-file: %w", err)
+	}
+	return &catFile{cmd: cmd, in: in, out: bufio.NewReader(out)}, nil
+}
+
+// blob returns the contents of the blob oid.
+func (c *catFile) blob(oid string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := io.WriteString(c.in, oid+"\n"); err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	// The header is "<oid> <type> <size>", or "<oid> missing".
+	header, err := c.out.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 || fields[1] != "blob" {
+		return nil, fmt.Errorf("git cat-file: %s is not a blob: %s", oid, strings.TrimSpace(header))
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: bad header %q", header)
+	}
+	data := make([]byte, size+1) // the blob and its trailing newline
+	if _, err := io.ReadFull(c.out, data); err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	return data[:size], nil
+}
+
+func (c *catFile) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.in.Close()
+	return c.cmd.Wait()
+}
+
+// run runs git in dir and returns its stdout, with git's diagnostic on
+// failure.
+func run(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	var stderr strings.Builder
+	cmd := gitutil.Command(ctx, dir, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,109 @@
+package gitsource
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// repo is a fresh repository in a temp dir with files committed.
+func repo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	commit(t, dir, files)
+	return dir
+}
+
+// commit writes files into dir, removing those whose content is "", and
+// commits everything.
+func commit(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if content == "" {
+			if err := os.Remove(p); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(t, dir, "add", "-A")
+	git(t, dir, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "c")
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+// TestGenerate: the IR of an earlier commit is the IR its working tree had,
+// whatever has been committed and checked out since.
+func TestGenerate(t *testing.T) {
+	dir := repo(t, map[string]string{
+		"main.go":    "package main\n\nfunc main() {}\n",
+		"web/app.js": "export function app() {}\n",
+		"web/old.py": "def old():\n    pass\n",
+		"README.md":  "# r\n",
+	})
+	if err := os.Symlink("main.go", filepath.Join(dir, "link.go")); err != nil {
+		t.Skip("symlinks unavailable:", err)
+	}
+	git(t, dir, "add", "link.go")
+	git(t, dir, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "link")
+	gen := ir.NewGenerator(ir.GeneratorConfig{IgnoredPaths: ir.DefaultIgnoredPaths})
+	want, wantStats, err := gen.Generate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit(t, dir, map[string]string{"main.go": "package main\n", "web/old.py": "", "web/new.ts": "export const n = 1\n"})
+	got, gotStats, err := Generate(context.Background(), gen, dir, "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotStats.Time, wantStats.Time = ir.PhaseTimes{}, ir.PhaseTimes{}
+	if !reflect.DeepEqual(got, want) || gotStats != wantStats {
+		t.Errorf("IR of HEAD~1 = %+v, %+v\nwant %+v, %+v", got, gotStats, want, wantStats)
+	}
+	head, _, err := Generate(context.Background(), gen, dir, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := ir.DiffFiles(got, head); !reflect.DeepEqual(c, ir.ChangeSet{Added: []string{"web/new.ts"}, Modified: []string{"main.go"}, Removed: []string{"web/old.py"}}) {
+		t.Errorf("HEAD~1 → HEAD = %+v", c)
+	}
+
+	if _, _, err := Generate(context.Background(), gen, dir, "no-such-branch"); err == nil {
+		t.Error("an unknown ref generated an IR")
+	}
+}
+
+func TestTree_FS(t *testing.T) {
+	dir := repo(t, map[string]string{"a.go": "package a\n", "b/c/d.txt": "d\n", "b/e.md": "e\n"})
+	tree, err := Open(context.Background(), dir, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+	if err := fstest.TestFS(tree, "a.go", "b/c/d.txt", "b/e.md"); err != nil {
+		t.Error(err)
+	}
+}
@@ -146,3 +146,67 @@ func WorktreePaths(dir string) []string {
 	}
 	return paths
 }
+
+// Head returns the commit HEAD names in the repo containing dir. It errors in
+// a repository with no commits yet.
+func Head(dir string) (string, error) {
+	out, err := runGit(dir, "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Change is one path `git status` reports under a directory: Code is the
+// two-letter porcelain status ("??" for untracked, " D" for deleted in the
+// working tree), Path is slash-separated and relative to that directory.
+type Change struct {
+	Code string
+	Path string
+}
+
+// Changes returns what differs from HEAD under dir: staged and unstaged
+// changes and untracked files, every untracked file listed on its own.
+// Ignored files are not reported.
+func Changes(dir string) ([]Change, error) {
+	prefix, err := runGit(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGit(dir, "status", "--porcelain", "-z", "--untracked-files=all", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	// Porcelain paths are relative to the top level whatever the directory.
+	pre := strings.TrimSpace(string(prefix))
+	var changes []Change
+	fields := strings.Split(string(out), "\x00")
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if len(f) < 4 {
+			continue
+		}
+		c := Change{Code: f[:2], Path: strings.TrimPrefix(f[3:], pre)}
+		if c.Code[0] == 'R' || c.Code[0] == 'C' {
+			i++ // a rename or copy is followed by its source path
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// TrackedFiles returns the files git tracks under dir, slash-separated and
+// relative to dir.
+func TrackedFiles(dir string) (map[string]bool, error) {
+	out, err := runGit(dir, "ls-files", "-z")
+	if err != nil {
+		return nil, err
+	}
+	tracked := make(map[string]bool)
+	for _, p := range strings.Split(string(out), "\x00") {
+		if p != "" {
+			tracked[p] = true
+		}
+	}
+	return tracked, nil
+}
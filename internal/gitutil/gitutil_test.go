@@ -187,3 +187,57 @@ func TestWorktreePaths_NonRepo(t *testing.T) {
 		t.Errorf("WorktreePaths on a non-git dir should be nil, got %v", paths)
 	}
 }
+
+// TestChanges runs from a subdirectory: paths come back relative to it, and
+// changes outside it are left out.
+func TestChanges(t *testing.T) {
+	dir := t.TempDir()
+	gitInit(t, dir)
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	for _, p := range []string{"top.go", "sub/a.go", "sub/b.go"} {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(p)), 0755)
+		os.WriteFile(filepath.Join(dir, p), []byte("package x\n"), 0644)
+	}
+	git("add", ".")
+	git("commit", "-qm", "init")
+	sub := filepath.Join(dir, "sub")
+
+	if _, err := Head(sub); err != nil {
+		t.Fatalf("Head: %v", err)
+	}
+	if changes, err := Changes(sub); err != nil || len(changes) != 0 {
+		t.Fatalf("clean tree: %v, %v", changes, err)
+	}
+	tracked, err := TrackedFiles(sub)
+	if err != nil || len(tracked) != 2 || !tracked["a.go"] {
+		t.Errorf("TrackedFiles = %v, %v", tracked, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "top.go"), []byte("package y\n"), 0644)
+	os.Remove(filepath.Join(sub, "a.go"))
+	os.WriteFile(filepath.Join(sub, "new.go"), []byte("package x\n"), 0644)
+	git("mv", "sub/b.go", "sub/c.go")
+	changes, err := Changes(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, c := range changes {
+		got[c.Path] = c.Code
+	}
+	want := map[string]string{"a.go": " D", "new.go": "??", "c.go": "R "}
+	if len(got) != len(want) {
+		t.Errorf("Changes = %v, want %v", got, want)
+	}
+	for p, code := range want {
+		if got[p] != code {
+			t.Errorf("Changes[%s] = %q, want %q (all: %v)", p, got[p], code, got)
+		}
+	}
+}
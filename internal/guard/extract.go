@@ -10,7 +10,7 @@ type Lang string
 
 const (
 	LangGo      Lang = "go"
-	LangJS      Lang = "js" // covers .js, .mjs, .cjs, .ts, .jsx, .tsx, .gs (GAS)
+	LangJS      Lang = "js" // covers .js, .mjs, .cjs, .ts, .mts, .cts, .jsx, .tsx, .gs (GAS)
 	LangPython  Lang = "py"
 	LangUnknown Lang = ""
 )
@@ -22,6 +22,7 @@ func LangFor(path string) Lang {
 		return LangGo
 	case strings.HasSuffix(path, ".js"), strings.HasSuffix(path, ".mjs"),
 		strings.HasSuffix(path, ".cjs"), strings.HasSuffix(path, ".ts"),
+		strings.HasSuffix(path, ".mts"), strings.HasSuffix(path, ".cts"),
 		strings.HasSuffix(path, ".jsx"), strings.HasSuffix(path, ".tsx"),
 		strings.HasSuffix(path, ".gs"):
 		return LangJS
@@ -938,6 +938,8 @@ func TestLangFor(t *testing.T) {
 		{"qux.gs", LangJS},
 		{"esm.mjs", LangJS},
 		{"commonjs.cjs", LangJS},
+		{"esm.mts", LangJS},
+		{"commonjs.cts", LangJS},
 		{"script.py", LangPython},
 		{"data.json", LangUnknown},
 		// Shell is intentionally parser-only: the ShellParser indexes .sh/.bash
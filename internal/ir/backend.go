@@ -0,0 +1,112 @@
+package ir
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Storage is an IR storage backend: somewhere other than the local filesystem
+// that an IR document can live, such as a company artifact store. A backend
+// is registered under a URI scheme, and Save and Load route any location of the
+// form "scheme://…" to it, so callers handle a remote IR exactly like
+// .ai/ir.json.
+//
+// A backend moves bytes only. Encoding, the deterministic JSON layout, and
+// Load's size cap stay in this package, so every backend stores the same
+// document.
+type Storage interface {
+	// Read opens the IR stored at uri. A missing document must yield an error
+	// wrapping fs.ErrNotExist, which callers treat as "no prior IR".
+	Read(uri string) (io.ReadCloser, error)
+	// Write replaces the IR stored at uri with data. It should be atomic: a
+	// reader must see the old document or the new one, never a mix.
+	Write(uri string, data []byte) error
+}
+
+var (
+	storageMu sync.RWMutex
+	storages  = map[string]Storage{}
+)
+
+// schemeRe is RFC 3986's scheme grammar, lowercase only (schemes are
+// case-insensitive; registration normalizes).
+var schemeRe = regexp.MustCompile(`^[a-z][a-z0-9+.-]*$`)
+
+// RegisterStorage makes s the backend for scheme. Like analyze.Register, it
+// panics on a nil backend, a malformed or duplicate scheme, or "file", which
+// is built in and always means the local filesystem.
+func RegisterStorage(scheme string, s Storage) {
+	scheme = strings.ToLower(scheme)
+	if s == nil {
+		panic("ir: RegisterStorage backend is nil")
+	}
+	if !schemeRe.MatchString(scheme) {
+		panic("ir: RegisterStorage invalid scheme " + scheme)
+	}
+	if scheme == "file" {
+		panic("ir: RegisterStorage cannot replace the built-in file scheme")
+	}
+	storageMu.Lock()
+	defer storageMu.Unlock()
+	if _, dup := storages[scheme]; dup {
+		panic("ir: RegisterStorage called twice for scheme " + scheme)
+	}
+	storages[scheme] = s
+}
+
+// StorageSchemes returns the registered schemes, sorted. "file" is built in and
+// not listed.
+func StorageSchemes() []string {
+	storageMu.RLock()
+	defer storageMu.RUnlock()
+	out := make([]string, 0, len(storages))
+	for scheme := range storages {
+		out = append(out, scheme)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// IsStorageURI reports whether loc names a backend ("scheme://…") rather than
+// a local path.
+func IsStorageURI(loc string) bool {
+	scheme, _, ok := strings.Cut(loc, "://")
+	return ok && schemeRe.MatchString(strings.ToLower(scheme))
+}
+
+// resolveStorage maps an IR location to its backend. A plain path, or a
+// file:// URI, is local: it returns a nil Storage and the filesystem path. A
+// URI with an unregistered scheme is an error, never a local path: writing to
+// a directory literally named "s3:" would look like success.
+func resolveStorage(loc string) (Storage, string, error) {
+	if !IsStorageURI(loc) {
+		return nil, loc, nil
+	}
+	scheme, _, _ := strings.Cut(loc, "://")
+	scheme = strings.ToLower(scheme)
+	if scheme == "file" {
+		u, err := url.Parse(loc)
+		if err != nil {
+			return nil, "", fmt.Errorf("IR location %q: %w", loc, err)
+		}
+		if u.Host != "" && u.Host != "localhost" {
+			return nil, "", fmt.Errorf("IR location %q: file URI with a remote host", loc)
+		}
+		if u.Path == "" {
+			return nil, "", fmt.Errorf("IR location %q: empty path", loc)
+		}
+		return nil, u.Path, nil
+	}
+	storageMu.RLock()
+	s := storages[scheme]
+	storageMu.RUnlock()
+	if s == nil {
+		return nil, "", fmt.Errorf("IR location %q: no storage backend registered for scheme %q", loc, scheme)
+	}
+	return s, loc, nil
+}
@@ -0,0 +1,113 @@
+package ir
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memStorage is an in-memory backend, the shape a third-party one takes.
+type memStorage struct {
+	mu   sync.Mutex
+	docs map[string][]byte
+}
+
+func (m *memStorage) Read(uri string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.docs[uri]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memStorage) Write(uri string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.docs[uri] = append([]byte(nil), data...)
+	return nil
+}
+
+var testMem = &memStorage{docs: map[string][]byte{}}
+
+func init() { RegisterStorage("MemTest", testMem) }
+
+// TestStorage_RoutesByScheme: Save and Load send a registered scheme's URIs to
+// its backend (registered as "MemTest", normalized to lowercase), with the same
+// bytes a local Save writes.
+func TestStorage_RoutesByScheme(t *testing.T) {
+	irData := &IR{Version: IRVersion, RootHash: "abc", Files: map[string]FileIR{
+		"a.go": {Hash: "h", Symbols: []Symbol{{Name: "A", Kind: "function", Line: 1}}},
+	}}
+	const uri = "memtest://bucket/repo/ir.json"
+	if err := irData.Save(uri); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	local := filepath.Join(t.TempDir(), "ir.json")
+	if err := irData.Save(local); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := os.ReadFile(local)
+	if !bytes.Equal(testMem.docs[uri], want) {
+		t.Error("backend received different bytes than a local Save writes")
+	}
+
+	got, err := Load(uri)
+	if err != nil || got.RootHash != "abc" || len(got.Files["a.go"].Symbols) != 1 {
+		t.Fatalf("Load = %+v, %v", got, err)
+	}
+	if _, err := Load("memtest://bucket/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing document: err = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestStorage_UnregisteredSchemeIsAnError(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	err := (&IR{Version: IRVersion}).Save("nosuch://x/ir.json")
+	if err == nil || !strings.Contains(err.Error(), `"nosuch"`) {
+		t.Fatalf("Save = %v, want an unregistered-scheme error", err)
+	}
+	if ents, _ := os.ReadDir(dir); len(ents) != 0 {
+		t.Errorf("an unregistered URI was written locally: %v", ents)
+	}
+}
+
+func TestStorage_FileURIIsLocal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ir.json")
+	if err := (&IR{Version: IRVersion, RootHash: "r"}).Save("file://" + filepath.ToSlash(path)); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil || got.RootHash != "r" {
+		t.Errorf("Load = %+v, %v", got, err)
+	}
+}
+
+func TestRegisterStorage_Panics(t *testing.T) {
+	for name, reg := range map[string]func(){
+		"nil":       func() { RegisterStorage("x", nil) },
+		"bad":       func() { RegisterStorage("1x", testMem) },
+		"file":      func() { RegisterStorage("file", testMem) },
+		"duplicate": func() { RegisterStorage("memtest", testMem) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: RegisterStorage did not panic", name)
+				}
+			}()
+			reg()
+		}()
+	}
+	if got := StorageSchemes(); len(got) != 1 || got[0] != "memtest" {
+		t.Errorf("StorageSchemes = %v", got)
+	}
+}
@@ -0,0 +1,176 @@
+package ir
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// jsResolveExts is the probe order for an extensionless JS/TS relative import
+// (`./utils` → utils.ts, utils.tsx, …, then utils/index.*). Fixed, so two
+// candidates that both exist always resolve the same way.
+var jsResolveExts = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".mts", ".cts", ".gs"}
+
+// ResolveImport maps an import specifier recorded in fromPath to the in-repo
+// files it names, sorted. It is a deliberately shallow, syntax-only resolver —
+// no tsconfig paths, no PYTHONPATH, no go.mod — so it can only ever find a
+// subset of the real edges, never invent one:
+//
+//   - JS/TS: relative specifiers (`./x`, `../x`) probed as-is, with each of
+//     jsResolveExts, then as a directory index.
+//   - Python: dotted modules (`pkg.mod` → pkg/mod.py or pkg/mod/__init__.py),
+//     with leading dots resolved relative to fromPath's package.
+//   - Go: an import path names a package, i.e. every .go file of the in-repo
+//     directory whose path is a suffix of the import path.
+//   - Anything else: a relative specifier probed as-is and with fromPath's own
+//     extension.
+//
+// Returns nil when the import is external or unresolvable.
+func (ir *IR) ResolveImport(fromPath, spec string) []string {
+	if spec == "" {
+		return nil
+	}
+	ext := path.Ext(fromPath)
+	dir := path.Dir(fromPath)
+	switch {
+	case isJSExt(ext):
+		if !isRelativeSpec(spec) {
+			return nil
+		}
+		base := path.Join(dir, spec)
+		cands := []string{base}
+		for _, e := range jsResolveExts {
+			cands = append(cands, base+e)
+		}
+		for _, e := range jsResolveExts {
+			cands = append(cands, base+"/index"+e)
+		}
+		return ir.firstPresent(cands)
+	case ext == ".py":
+		return ir.firstPresent(pyModuleCandidates(dir, spec))
+	case ext == ".go":
+		return ir.goPackageFiles(spec)
+	default:
+		if !isRelativeSpec(spec) {
+			return nil
+		}
+		base := path.Join(dir, spec)
+		return ir.firstPresent([]string{base, base + ext})
+	}
+}
+
+// Dependencies returns the sorted, deduplicated in-repo files that filePath
+// imports or re-exports from (see ResolveImport). A file never depends on
+// itself.
+func (ir *IR) Dependencies(filePath string) []string {
+	f, ok := ir.Files[filePath]
+	if !ok {
+		return nil
+	}
+	set := make(map[string]bool)
+	for _, spec := range append(f.namesOf("import"), f.ReExports...) {
+		for _, target := range ir.ResolveImport(filePath, spec) {
+			if target != filePath {
+				set[target] = true
+			}
+		}
+	}
+	return sortedKeys(set)
+}
+
+// Dependents returns the sorted in-repo files that import or re-export from
+// filePath — the reverse of Dependencies.
+func (ir *IR) Dependents(filePath string) []string {
+	set := make(map[string]bool)
+	for from := range ir.Files {
+		if from == filePath {
+			continue
+		}
+		for _, dep := range ir.Dependencies(from) {
+			if dep == filePath {
+				set[from] = true
+				break
+			}
+		}
+	}
+	return sortedKeys(set)
+}
+
+func isJSExt(ext string) bool {
+	for _, e := range jsResolveExts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+func isRelativeSpec(spec string) bool {
+	return spec == "." || spec == ".." || strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../")
+}
+
+// pyModuleCandidates returns the file candidates for a Python module spec
+// imported from a file in dir. N leading dots climb N-1 packages from dir; no
+// leading dot resolves from the repo root.
+func pyModuleCandidates(dir, spec string) []string {
+	base := ""
+	rest := spec
+	if strings.HasPrefix(spec, ".") {
+		dots := len(spec) - len(strings.TrimLeft(spec, "."))
+		rest = spec[dots:]
+		base = dir
+		for i := 1; i < dots; i++ {
+			base = path.Dir(base)
+		}
+	}
+	mod := strings.ReplaceAll(rest, ".", "/")
+	p := path.Join(base, mod)
+	if mod == "" {
+		return []string{path.Join(p, "__init__.py")}
+	}
+	return []string{p + ".py", path.Join(p, "__init__.py")}
+}
+
+// goPackageFiles returns every .go file whose directory is the in-repo tail of
+// importPath (e.g. internal/ir for github.com/x/y/internal/ir).
+func (ir *IR) goPackageFiles(importPath string) []string {
+	var out []string
+	for p := range ir.Files {
+		if path.Ext(p) != ".go" {
+			continue
+		}
+		d := path.Dir(p)
+		if d == "." {
+			continue
+		}
+		if importPath == d || strings.HasSuffix(importPath, "/"+d) {
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// firstPresent returns the first candidate (cleaned) that is a file in the IR,
+// as a one-element slice, or nil.
+func (ir *IR) firstPresent(cands []string) []string {
+	for _, c := range cands {
+		c = path.Clean(c)
+		if strings.HasPrefix(c, "../") || c == ".." {
+			continue // climbed out of the repo
+		}
+		if _, ok := ir.Files[c]; ok {
+			return []string{c}
+		}
+	}
+	return nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
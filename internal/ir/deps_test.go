@@ -0,0 +1,98 @@
+package ir
+
+import (
+	"reflect"
+	"testing"
+)
+
+func importsIR(files map[string][]string) *IR {
+	out := &IR{Version: IRVersion, Files: map[string]FileIR{}}
+	for p, imps := range files {
+		var syms []Symbol
+		for _, i := range imps {
+			syms = append(syms, Symbol{Name: i, Kind: "import"})
+		}
+		out.Files[p] = FileIR{Hash: p, Symbols: syms}
+	}
+	return out
+}
+
+func TestResolveImport_PerLanguage(t *testing.T) {
+	subject := importsIR(map[string][]string{
+		"web/app.ts":              nil,
+		"web/utils.ts":            nil,
+		"web/lib/index.js":        nil,
+		"pkg/__init__.py":         nil,
+		"pkg/mod.py":              nil,
+		"pkg/sub/leaf.py":         nil,
+		"internal/ir/ir.go":       nil,
+		"internal/ir/storage.go":  nil,
+		"internal/irx/other.go":   nil,
+		"cmd/tool/main.go":        nil,
+		"scripts/run.sh":          nil,
+		"scripts/lib/common.sh":   nil,
+		"web/nested/deep/page.ts": nil,
+	})
+	cases := []struct {
+		from, spec string
+		want       []string
+	}{
+		{"web/app.ts", "./utils", []string{"web/utils.ts"}},
+		{"web/app.ts", "./lib", []string{"web/lib/index.js"}},
+		{"web/nested/deep/page.ts", "../../utils", []string{"web/utils.ts"}},
+		{"web/app.ts", "react", nil},
+		{"web/app.ts", "../../../outside", nil},
+		{"pkg/sub/leaf.py", "pkg.mod", []string{"pkg/mod.py"}},
+		{"pkg/sub/leaf.py", "..mod", []string{"pkg/mod.py"}},
+		{"pkg/mod.py", ".", []string{"pkg/__init__.py"}},
+		{"pkg/mod.py", "pkg", []string{"pkg/__init__.py"}},
+		{"pkg/mod.py", "os", nil},
+		{"cmd/tool/main.go", "github.com/x/y/internal/ir", []string{"internal/ir/ir.go", "internal/ir/storage.go"}},
+		{"cmd/tool/main.go", "fmt", nil},
+		{"scripts/run.sh", "./lib/common", []string{"scripts/lib/common.sh"}},
+	}
+	for _, c := range cases {
+		if got := subject.ResolveImport(c.from, c.spec); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ResolveImport(%q, %q) = %v, want %v", c.from, c.spec, got, c.want)
+		}
+	}
+}
+
+func TestDependenciesAndDependents(t *testing.T) {
+	subject := importsIR(map[string][]string{
+		"a.ts": {"./b", "./c", "lodash"},
+		"b.ts": {"./c"},
+		"c.ts": {"./c"}, // self-import never counts
+		"d.ts": nil,
+	})
+	if got, want := subject.Dependencies("a.ts"), []string{"b.ts", "c.ts"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependencies(a.ts) = %v, want %v", got, want)
+	}
+	if got, want := subject.Dependents("c.ts"), []string{"a.ts", "b.ts"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependents(c.ts) = %v, want %v", got, want)
+	}
+	if got := subject.Dependents("d.ts"); len(got) != 0 {
+		t.Errorf("Dependents(d.ts) = %v, want none", got)
+	}
+	if got := subject.Dependencies("missing.ts"); got != nil {
+		t.Errorf("Dependencies(missing) = %v, want nil", got)
+	}
+}
+
+// A barrel file imports nothing; its re-exports are its edges.
+func TestDependencies_FollowsReExports(t *testing.T) {
+	subject := importsIR(map[string][]string{
+		"index.ts": nil,
+		"a.ts":     nil,
+		"b.ts":     nil,
+	})
+	barrel := subject.Files["index.ts"]
+	barrel.ReExports = []string{"./a", "./b", "react"}
+	subject.Files["index.ts"] = barrel
+	if got, want := subject.Dependencies("index.ts"), []string{"a.ts", "b.ts"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependencies(index.ts) = %v, want %v", got, want)
+	}
+	if got, want := subject.Dependents("a.ts"), []string{"index.ts"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependents(a.ts) = %v, want %v", got, want)
+	}
+}
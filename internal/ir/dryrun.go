@@ -0,0 +1,81 @@
+package ir
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Reasons a DryRunEntry gives for a path the walk leaves out.
+const (
+	SkipUnsupported = "unsupported"      // no parser claims the file's extension
+	SkipIgnored     = "ignored"          // IgnoredPaths, an ignore file, or a PathFilter
+	SkipSymlink     = "symlink"          // a link, without FollowSymlinks
+	SkipCollision   = "collision"        // another spelling has the file's IR key
+	SkipTooDeep     = OmissionTooDeep    // a directory past MaxDepth
+	SkipUnreadable  = OmissionUnreadable // a path the walk could not stat or list
+)
+
+// DryRunEntry is one path a dry run reached.
+type DryRunEntry struct {
+	// Path is the IR key; a directory's ends in "/", and a collision's is
+	// its spelling on disk, since its key is the file Generate keeps.
+	Path string `json:"path"`
+	// Skip is why Generate would leave the path out, one of the Skip
+	// reasons, or "" for a file it would read.
+	Skip string `json:"skip,omitempty"`
+}
+
+// DryRun walks rootPath exactly as Generate would — IgnoredPaths, ignore
+// files, PathFilters, extensions, MaxDepth, collisions — without reading,
+// hashing, or parsing a file. It returns, sorted by Path, every file Generate
+// would read and every path it would leave out with the reason, so a caller
+// can see why a file is or is not in the IR. The entries under a directory
+// that is skipped are not listed: the walk never saw them.
+//
+// FileCap is not applied: which files it keeps depends on which fail to
+// parse. The Stats have the walk's counts and Time.Walk; nothing is Indexed.
+func (g *Generator) DryRun(ctx context.Context, rootPath string) ([]DryRunEntry, Stats, error) {
+	start := time.Now()
+	ctx, cancel := g.withDeadline(ctx)
+	defer cancel()
+
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, Stats{}, fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	var entries []DryRunEntry
+	dry := *g
+	dry.explain = func(path, skip string) {
+		entries = append(entries, DryRunEntry{Path: path, Skip: skip})
+	}
+	files, sum, err := dry.findSourceFiles(ctx, filepath.Clean(absRoot))
+	if err != nil {
+		return nil, Stats{}, fmt.Errorf("failed to walk directory: %w", err)
+	}
+	for _, e := range files {
+		entries = append(entries, DryRunEntry{Path: e.key})
+	}
+	for _, o := range sum.omissions {
+		if o.Reason == OmissionUnreadable {
+			entries = append(entries, DryRunEntry{Path: o.Path, Skip: SkipUnreadable})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	var stats Stats
+	sum.count(&stats)
+	stats.SupportedSeen = len(files)
+	stats.Time.Walk = time.Since(start)
+	stats.Time.Total = stats.Time.Walk
+	return entries, stats, nil
+}
+
+// skipped reports the path the walk leaves out, and why, to explain.
+func (g *Generator) skipped(path, skip string) {
+	if g.explain != nil {
+		g.explain(path, skip)
+	}
+}
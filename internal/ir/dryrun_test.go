@@ -0,0 +1,57 @@
+package ir
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDryRun: a dry run lists the files Generate indexes and every path it
+// leaves out with the reason, and reads none of them.
+func TestDryRun(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":          "gen/\n",
+		"a.go":                "package a\n",
+		"d/e/c.go":            "package e\n",
+		"d/e/f/deep.go":       "package f\n",
+		"gen/out.go":          "package gen\n",
+		"node_modules/m/m.js": "export const m = 1\n",
+		"notes.xyz":           "notes\n",
+	})
+	if err := os.Symlink("a.go", filepath.Join(root, "link.go")); err != nil {
+		t.Skip("symlinks unavailable:", err)
+	}
+	gen := NewGenerator(GeneratorConfig{MaxDepth: 2})
+	entries, stats, err := gen.DryRun(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DryRunEntry{
+		{Path: ".gitignore", Skip: SkipUnsupported},
+		{Path: "a.go"},
+		{Path: "d/e/c.go"},
+		{Path: "d/e/f/", Skip: SkipTooDeep},
+		{Path: "gen/", Skip: SkipIgnored},
+		{Path: "link.go", Skip: SkipSymlink},
+		{Path: "node_modules/", Skip: SkipIgnored},
+		{Path: "notes.xyz", Skip: SkipUnsupported},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("DryRun = %+v\nwant %+v", entries, want)
+	}
+	if untimed(stats) != (Stats{SupportedSeen: 2, Walked: 4, Unsupported: 2}) || stats.Time.Walk <= 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// Unreadable, a file still lists: nothing is opened.
+	if err := os.Chmod(filepath.Join(root, "a.go"), 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(filepath.Join(root, "a.go"), 0o644)
+	if again, _, err := gen.DryRun(context.Background(), root); err != nil || !reflect.DeepEqual(again, entries) {
+		t.Errorf("DryRun of an unreadable file = %+v, %v", again, err)
+	}
+}
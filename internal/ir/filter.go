@@ -0,0 +1,163 @@
+package ir
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Decision is a PathFilter's verdict on one walk entry.
+type Decision int
+
+const (
+	// PathDefault leaves the entry to the next filter and, failing any verdict,
+	// to the built-in rules (ignored directory names, supported extensions).
+	PathDefault Decision = iota
+	// PathSkip drops a file from the IR, or a directory with its whole subtree.
+	PathSkip
+	// PathInclude descends into a directory the ignored-name list would skip
+	// ("vendor", say). For a file it only ends the filter chain: a file still
+	// needs a parser for its extension, and symlinks are followed only under
+	// GeneratorConfig.FollowSymlinks.
+	PathInclude
+)
+
+// PathFilter is an embedder's hook into the walk: organization-specific rules
+// (skip generated code, skip paths an internal registry marks private) without
+// forking the walker. path is the entry's IR key — relative to the walk root,
+// slash-separated, NFC — so a rule written once holds on every OS; info is the
+// entry itself. Filters run in order and the first verdict other than
+// PathDefault wins.
+//
+// A filter must be a pure function of its arguments. The walk and the per-edit
+// UpdateFile refresh both consult it, and a filter that answered them
+// differently would make the IR depend on which one ran last.
+type PathFilter func(path string, info fs.DirEntry) Decision
+
+// PatternFilter returns a PathFilter for two lists of .gitignore patterns,
+// read as a root .gitignore would be: PathSkip for a path ignore matches,
+// else PathInclude for one include matches, else PathDefault. It is how a
+// repo config names, in a familiar syntax, what to leave out and which
+// ignored directories ("vendor/") to index after all. An include ending in
+// "/**" also includes the directory itself, without which the walk would
+// prune it before reaching what the pattern names.
+func PatternFilter(ignore, include []string) PathFilter {
+	rules := func(patterns []string) *gitignore {
+		return &gitignore{rules: map[string][]ignoreRule{".": parseGitignore([]byte(strings.Join(patterns, "\n")))}}
+	}
+	var withDirs []string
+	for _, pat := range include {
+		if dir, ok := strings.CutSuffix(pat, "/**"); ok && strings.Trim(dir, "/!") != "" {
+			withDirs = append(withDirs, dir+"/")
+		}
+		withDirs = append(withDirs, pat)
+	}
+	skip, keep := rules(ignore), rules(withDirs)
+	return func(path string, info fs.DirEntry) Decision {
+		switch {
+		case skip.ignored(path, info.IsDir()):
+			return PathSkip
+		case keep.ignored(path, info.IsDir()):
+			return PathInclude
+		}
+		return PathDefault
+	}
+}
+
+var (
+	pathFilterMu sync.RWMutex
+	pathFilters  []PathFilter
+)
+
+// RegisterPathFilter adds f to every Generator created afterwards, after any
+// GeneratorConfig.PathFilters. Call it from an init func, so every entry point
+// (CLI, MCP server, guard hook) of a binary that links it indexes the same set
+// of files.
+func RegisterPathFilter(f PathFilter) {
+	if f == nil {
+		panic("ir: RegisterPathFilter filter is nil")
+	}
+	pathFilterMu.Lock()
+	defer pathFilterMu.Unlock()
+	pathFilters = append(pathFilters, f)
+}
+
+// registeredPathFilters returns a snapshot of the registry.
+func registeredPathFilters() []PathFilter {
+	pathFilterMu.RLock()
+	defer pathFilterMu.RUnlock()
+	return append([]PathFilter(nil), pathFilters...)
+}
+
+// decide runs the filter chain on one entry.
+func (g *Generator) decide(normalizedPath string, info fs.DirEntry) Decision {
+	for _, f := range g.filters {
+		if d := f(normalizedPath, info); d != PathDefault {
+			return d
+		}
+	}
+	return PathDefault
+}
+
+// skipDir reports whether the walk prunes the directory at normalizedPath.
+func (g *Generator) skipDir(gi *gitignore, normalizedPath string, info fs.DirEntry) bool {
+	switch g.decide(normalizedPath, info) {
+	case PathSkip:
+		return true
+	case PathInclude:
+		return false
+	}
+	return g.ignoredPaths[info.Name()] || gi.ignored(normalizedPath, true)
+}
+
+// tooDeep reports whether the walk stops at the directory key, more than
+// GeneratorConfig.MaxDepth directories below the root.
+func (g *Generator) tooDeep(key string) bool {
+	return g.maxDepth > 0 && strings.Count(key, "/") >= g.maxDepth
+}
+
+// skipFile reports whether the walk leaves out the file at normalizedPath: a
+// filter skips it, or, barring a PathInclude, an ignore file does.
+func (g *Generator) skipFile(gi *gitignore, normalizedPath string, info fs.DirEntry) bool {
+	switch g.decide(normalizedPath, info) {
+	case PathSkip:
+		return true
+	case PathInclude:
+		return false
+	}
+	return gi.ignored(normalizedPath, false)
+}
+
+// pathFilteredOut reports whether the walk would never reach absFile under
+// absRoot: some directory between them is pruned, or the filters or a
+// .gitignore skip the file itself, or it lies past MaxDepth. It lets
+// UpdateFile refuse exactly what walkSourceFiles refuses. A component that
+// cannot be stat'ed defers to the caller (false), like pathCrossesSymlink.
+func (g *Generator) pathFilteredOut(absRoot, absFile string) bool {
+	rel, err := filepath.Rel(absRoot, absFile)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	gi := g.gitignoreFor()
+	gi.read(".", absRoot)
+	for i := range parts {
+		p := filepath.Join(absRoot, filepath.Join(parts[:i+1]...))
+		li, err := g.statLink(p)
+		if err != nil {
+			return false
+		}
+		entry := fs.FileInfoToDirEntry(li)
+		norm := normalizePath(filepath.Join(parts[:i+1]...))
+		if i < len(parts)-1 {
+			if g.skipDir(gi, norm, entry) || g.tooDeep(norm) {
+				return true
+			}
+			gi.read(norm, p)
+			continue
+		}
+		return g.skipFile(gi, norm, entry)
+	}
+	return false
+}
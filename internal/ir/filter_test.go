@@ -0,0 +1,155 @@
+package ir
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func init() {
+	// A registered filter reaches every Generator; it keys on a name no other
+	// test uses, so it cannot disturb them.
+	RegisterPathFilter(func(path string, _ fs.DirEntry) Decision {
+		if filepath.Base(path) == "zz_registered_skip.go" {
+			return PathSkip
+		}
+		return PathDefault
+	})
+}
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func indexedPaths(irData *IR) []string {
+	var out []string
+	for p := range irData.Files {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// TestPathFilter_Walk: filters see slash-separated IR keys and DirEntries;
+// PathSkip prunes a directory or drops a file, PathInclude re-opens an ignored
+// directory, and the first non-default verdict wins.
+func TestPathFilter_Walk(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"main.go":                "package m\n\nfunc Main() {}\n",
+		"gen/api.go":             "package gen\n\nfunc Gen() {}\n",
+		"pkg/model_generated.go": "package pkg\n\nfunc Gen() {}\n",
+		"pkg/model.go":           "package pkg\n\nfunc Model() {}\n",
+		"vendor/dep/dep.go":      "package dep\n\nfunc Dep() {}\n",
+		"zz_registered_skip.go":  "package m\n\nfunc Z() {}\n",
+	})
+	var sawDir bool
+	gen := NewGenerator(GeneratorConfig{PathFilters: []PathFilter{
+		func(path string, info fs.DirEntry) Decision {
+			switch {
+			case path == "gen" && info.IsDir():
+				sawDir = true
+				return PathSkip
+			case strings.HasSuffix(path, "_generated.go"):
+				return PathSkip
+			case path == "vendor":
+				return PathInclude
+			}
+			return PathDefault
+		},
+		func(path string, _ fs.DirEntry) Decision {
+			if path == "vendor" {
+				return PathSkip // never reached: the first filter already decided
+			}
+			return PathDefault
+		},
+	}})
+	irData, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	want := []string{"main.go", "pkg/model.go", "vendor/dep/dep.go"}
+	if got := indexedPaths(irData); !reflect.DeepEqual(got, want) {
+		t.Errorf("indexed %v, want %v", got, want)
+	}
+	if !sawDir {
+		t.Error("filter never saw the gen directory as a directory")
+	}
+}
+
+// TestPathFilter_UpdateFileMatchesWalk: the per-edit refresh refuses exactly
+// what the walk refuses — a filtered file, a file under a filtered directory,
+// and a file under a built-in ignored directory — so UpdateFile can never
+// index what Generate would not.
+func TestPathFilter_UpdateFileMatchesWalk(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"main.go": "package m\n\nfunc Main() {}\n"})
+	gen := NewGenerator(GeneratorConfig{PathFilters: []PathFilter{
+		func(path string, _ fs.DirEntry) Decision {
+			if path == "gen" || path == "skip.go" {
+				return PathSkip
+			}
+			return PathDefault
+		},
+	}})
+	base, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"skip.go", "gen/api.go", "node_modules/x/x.go", "zz_registered_skip.go"} {
+		writeTree(t, root, map[string]string{name: "package x\n\nfunc X() {}\n"})
+		got, changed, err := gen.UpdateFile(base, root, filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil || changed {
+			t.Errorf("%s: UpdateFile changed=%v err=%v, want a no-op", name, changed, err)
+		}
+		if _, ok := got.Files[name]; ok {
+			t.Errorf("%s: indexed by UpdateFile", name)
+		}
+	}
+	full, _, _ := gen.Generate(root)
+	if full.RootHash != base.RootHash {
+		t.Errorf("full walk indexed %v; filters disagree with UpdateFile", indexedPaths(full))
+	}
+}
+
+// TestPatternFilter: ignore patterns skip in .gitignore syntax, include
+// patterns re-open a built-in ignored directory, and ignore wins over include.
+func TestPatternFilter(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"main.go":               "package m\n\nfunc Main() {}\n",
+		"gen/api.go":            "package gen\n\nfunc Gen() {}\n",
+		"pkg/fixture_test.go":   "package pkg\n\nfunc F() {}\n",
+		"pkg/keep.go":           "package pkg\n\nfunc Keep() {}\n",
+		"vendor/dep/dep.go":     "package dep\n\nfunc Dep() {}\n",
+		"vendor/dep/skipped.go": "package dep\n\nfunc Skip() {}\n",
+	})
+	gen := NewGenerator(GeneratorConfig{
+		IgnoredPaths: DefaultIgnoredPaths,
+		PathFilters: []PathFilter{PatternFilter(
+			[]string{"# generated", "/gen/", "*_test.go", "skipped.go"},
+			[]string{"vendor/", "skipped.go"},
+		)},
+	})
+	irData, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	want := []string{"main.go", "pkg/keep.go", "vendor/dep/dep.go"}
+	if got := indexedPaths(irData); !reflect.DeepEqual(got, want) {
+		t.Errorf("indexed %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+package ir
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"time"
+)
+
+// GenerateFS is Generate for the tree fsys holds rather than a directory on
+// disk: an embed.FS, an fstest.MapFS fixture, a zip.Reader, an os.DirFS. Its
+// keys are fsys's names, which are already slash-separated and relative, and
+// the warnings it raises name files the same way.
+//
+// The walk is Generate's — filters, .gitignore files, FileCap, MaxFileSize,
+// collisions — less what only a real directory has: a symlink is never
+// followed (fs.WalkDir reports it as an entry, whatever FollowSymlinks says),
+// and the StatCache is neither read nor written. With Workers above 1, fsys
+// is read from that many goroutines at once, or IOConcurrency if fewer.
+func (g *Generator) GenerateFS(fsys fs.FS) (*IR, Stats, error) {
+	return g.GenerateFSCtx(context.Background(), fsys)
+}
+
+// GenerateFSCtx is GenerateFS with an explicit context, bounded the way
+// GenerateCtx is.
+func (g *Generator) GenerateFSCtx(ctx context.Context, fsys fs.FS) (*IR, Stats, error) {
+	start := time.Now()
+	ctx, cancel := g.withDeadline(ctx)
+	defer cancel()
+
+	result := &IR{Version: IRVersion, RootHashAlg: g.rootHashAlg, Files: make(map[string]FileIR)}
+	var stats Stats
+
+	files, sum, err := g.findFSFiles(ctx, fsys)
+	stats.Time.Walk = time.Since(start)
+	if err == nil {
+		err = inOrder(ctx, g.workers, files, func(e walkEntry) fileResult {
+			var r fileResult
+			r.f, r.err = g.deferWarnings(&r).parseFSFile(fsys, e.abs, e.key)
+			return r
+		}, func(e walkEntry, get func() fileResult) {
+			stats.SupportedSeen++
+			defer g.progress(stats.SupportedSeen, len(files), e.key)
+			if g.capReached(len(result.Files)) {
+				stats.OverCap++
+				return
+			}
+			if r := get(); g.record(e, r, &stats) {
+				result.Files[e.key] = r.f
+			}
+		})
+		stats.Time.Files = time.Since(start) - stats.Time.Walk
+	}
+	if err != nil {
+		return nil, Stats{}, fmt.Errorf("failed to walk file system: %w", err)
+	}
+	sum.count(&stats)
+	result.Omissions = sum.omissions
+
+	result.RootHash = g.rootHash(result.Files)
+	stats.Indexed = len(result.Files)
+	stats.ParseSkipped = countParseSkipped(result.Files)
+	stats.Time.Total = time.Since(start)
+	return result, stats, nil
+}
+
+// findFSFiles is findSourceFiles for fsys. A walkEntry's abs is the file's
+// name in fsys.
+func (g *Generator) findFSFiles(ctx context.Context, fsys fs.FS) ([]walkEntry, walkSummary, error) {
+	var found []walkEntry
+	gi := g.gitignoreFor()
+	var sum walkSummary
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if cerr := ctx.Err(); cerr != nil {
+			return cerr
+		}
+		if err != nil {
+			g.warnf(WarnAccess, name, "failed to access %s: %v", name, err)
+			sum.omissions = append(sum.omissions, Omission{Path: normalizePath(name), Reason: OmissionUnreadable})
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		if d.IsDir() {
+			// The root is not an entry of the tree: filters never see it.
+			if name != "." && g.skipDir(gi, normalizePath(name), d) {
+				return fs.SkipDir
+			}
+			if name != "." && g.tooDeep(normalizePath(name)) {
+				sum.omissions = append(sum.omissions, Omission{Path: normalizePath(name), Reason: OmissionTooDeep})
+				return fs.SkipDir
+			}
+			gi.readFS(fsys, name)
+			return nil
+		}
+		sum.walked++
+		if !g.supportsFile(name) {
+			sum.unsupported++
+			return nil
+		}
+		key := normalizePath(name)
+		if g.skipFile(gi, key, d) {
+			sum.ignored++
+			return nil
+		}
+		found = append(found, walkEntry{abs: name, raw: name, key: key})
+		return nil
+	})
+	if err != nil {
+		return nil, walkSummary{}, err
+	}
+	sort.Slice(sum.omissions, func(i, j int) bool { return sum.omissions[i].Path < sum.omissions[j].Path })
+	var winners []walkEntry
+	winners, sum.collisions = g.resolveCollisions(found)
+	if sum.caseCollisions, err = g.caseCollisions("", winners); err != nil {
+		return nil, walkSummary{}, err
+	}
+	return winners, sum, nil
+}
+
+// parseFSFile is parseFile for the file name in fsys.
+func (g *Generator) parseFSFile(fsys fs.FS, name, key string) (FileIR, error) {
+	size, content, hash, err := g.readFSFile(fsys, name)
+	if err != nil {
+		return FileIR{}, err
+	}
+	if g.tooLarge(size) {
+		return g.hashOnly(name, hash, size, func() (io.ReadCloser, error) { return fsys.Open(name) })
+	}
+	return g.parseContent(name, key, content, hash)
+}
+
+// readFSFile is readFile for the file name in fsys.
+func (g *Generator) readFSFile(fsys fs.FS, name string) (size int64, content []byte, hash string, err error) {
+	defer acquire(g.ioSlots)()
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return 0, nil, "", fmt.Errorf("failed to stat file: %w", err)
+	}
+	if g.tooLarge(info.Size()) {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return 0, nil, "", fmt.Errorf("failed to open file: %w", err)
+		}
+		hash, err := hashStream(f)
+		f.Close()
+		if err != nil {
+			return 0, nil, "", err
+		}
+		g.hashed(info.Size())
+		return info.Size(), nil, hash, nil
+	}
+	if info.Size() > g.maxParseBytes {
+		return 0, nil, "", fmt.Errorf("skipping oversized file (%d bytes)", info.Size())
+	}
+	content, err = fs.ReadFile(fsys, name)
+	if err != nil {
+		return 0, nil, "", fmt.Errorf("failed to read file: %w", err)
+	}
+	g.hashed(int64(len(content)))
+	return int64(len(content)), content, HashBytes(content), nil
+}
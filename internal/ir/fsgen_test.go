@@ -0,0 +1,65 @@
+package ir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// TestGenerateFS: an in-memory tree is filtered, hashed, and parsed with no
+// disk behind it — .gitignore files, default skips, hash-only files and all —
+// with warnings naming files by their fs.FS names.
+func TestGenerateFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		".gitignore":          {Data: []byte("gen/\n*.min.js\n")},
+		"main.go":             {Data: []byte("package main\n\nfunc main() {}\n")},
+		"app/app.min.js":      {Data: []byte("function minified() {}\n")},
+		"app/util.js":         {Data: []byte("export function util() { return 1 }\n")},
+		"app/big.py":          {Data: []byte("def big():\n    return '0123456789012345678901234567890123456789'\n")},
+		"gen/out.go":          {Data: []byte("package gen\n")},
+		"node_modules/x/i.js": {Data: []byte("module.exports = 1\n")},
+		"lib/bin.go":          {Data: []byte("package lib\x00\n")},
+		"lib/.gitignore":      {Data: []byte("skip.go\n")},
+		"lib/skip.go":         {Data: []byte("package lib\n")},
+		"lib/keep.go":         {Data: []byte("package lib\n\nfunc Keep() {}\n")},
+	}
+	gen := NewGenerator(GeneratorConfig{MaxFileSize: 60})
+	warned := captureWarnings(gen)
+	got, stats, err := gen.GenerateFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPaths := []string{"app/big.py", "app/util.js", "lib/bin.go", "lib/keep.go", "main.go"}
+	if paths := indexedPaths(got); !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("indexed %v, want %v", paths, wantPaths)
+	}
+	if stats.ParseSkipped != 2 {
+		t.Errorf("stats %+v, want 2 parse-skipped files", stats)
+	}
+	sum := sha256.Sum256(fsys["main.go"].Data)
+	if main := got.Files["main.go"]; main.Hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("main.go hash %s, want the SHA-256 of its bytes", main.Hash)
+	}
+	for path, want := range map[string][]string{"lib/keep.go": {"Keep"}, "app/util.js": {"util"}} {
+		if names := got.Files[path].namesOf("function"); !reflect.DeepEqual(names, want) {
+			t.Errorf("%s functions = %v, want %v", path, names, want)
+		}
+	}
+	for path, want := range map[string]string{"app/big.py": ParseSkippedTooLarge, "lib/bin.go": ParseSkippedBinary} {
+		if f := got.Files[path]; f.ParseSkipped != want || f.Hash == "" || len(f.Symbols) != 0 {
+			t.Errorf("%s = %+v, want hash only (%s)", path, f, want)
+		}
+	}
+	if got.RootHash == "" {
+		t.Error("no root hash")
+	}
+	wantWarned := []string{
+		"Note: app/big.py is 65 bytes, over the 60-byte limit; indexing its hash only\n",
+		"Note: lib/bin.go is not text; indexing its hash only\n",
+	}
+	if !reflect.DeepEqual(*warned, wantWarned) {
+		t.Errorf("warned %q, want %q", *warned, wantWarned)
+	}
+}
@@ -1,16 +1,28 @@
 package ir
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/inth3shadows/runecho/internal/guard"
 	"github.com/inth3shadows/runecho/internal/parser"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/unicode/norm"
 )
 
@@ -46,21 +58,70 @@ func (g *Generator) withDeadline(ctx context.Context) (context.Context, context.
 
 // Generator creates and updates IR from source files.
 type Generator struct {
-	parsers      []parser.Parser
+	// parsers dispatches each file: GeneratorConfig.Parsers, then the
+	// built-ins.
+	parsers      *parser.Registry
 	ignoredPaths map[string]bool
+	filters      []PathFilter // GeneratorConfig.PathFilters, then registered ones
+	extMap       map[string]extMapping
 	fileCap      int // 0 = unlimited; walk stops after this many files
 	// maxParseBytes is the per-file parse size limit (see defaultMaxParseBytes).
 	// A per-Generator field, not a package global, so a test that lowers it can
 	// never race a parallel test.
 	maxParseBytes int64
+	// maxLineBytes is the longest line a file may have and still be parsed
+	// (see defaultMaxLineBytes); per-Generator for the same reason.
+	maxLineBytes int
+	// maxFileSize is GeneratorConfig.MaxFileSize; 0 = no hash-only cut.
+	maxFileSize int64
 	// warn routes non-fatal walk/parse diagnostics. Defaults to stderr (set by
 	// NewGenerator) so existing callers are unchanged; tests inject a sink to
 	// assert the otherwise-silent skip branches actually fire.
-	warn func(format string, args ...any)
+	warn func(Warning)
+	// tally, on a copy made by deferWarnings, is the result the copy counts
+	// its parses and hashed bytes in; nil counts nothing.
+	tally *fileResult
 	// genTimeout is the default wall-clock bound on a Generate/Update walk when the
 	// caller passes no ctx deadline. NewGenerator resolves it: 0 → DefaultGenerateTimeout,
 	// <0 → unbounded (the walk gets no default deadline). See withDeadline.
 	genTimeout time.Duration
+	// rootHashes lets UpdateSingleFile re-hash without re-sorting; a pointer
+	// so copies of the Generator share it.
+	rootHashes *rootHashCache
+	// scope, when non-nil, narrows the walk to one worker's share of a
+	// sharded Generate (see GenerateShard). Nil everywhere else.
+	scope *shardScope
+	// explain, when set, hears each path a walk leaves out and why (see
+	// DryRun). Nil everywhere else.
+	explain func(path, skip string)
+	// objects caches built-in parses by content (see GeneratorConfig.Objects).
+	objects *ObjectStore
+	// plugins is how many of parsers, at the front, came from
+	// GeneratorConfig.Parsers.
+	plugins int
+	// gitignore has walks skip what the tree's .gitignore files exclude (see
+	// GeneratorConfig.NoGitignore).
+	gitignore bool
+	// workers bounds the concurrent hashing and parsing of a walk (see
+	// GeneratorConfig.Workers).
+	workers int
+	// ioSlots and parseSlots hold a token while a worker reads or hashes a
+	// file, and while it parses one (GeneratorConfig.IOConcurrency and
+	// ParseConcurrency); nil bounds nothing beyond workers.
+	ioSlots, parseSlots chan struct{}
+	// onProgress is GeneratorConfig.OnProgress; nil reports nothing.
+	onProgress func(done, total int, path string)
+	// statCache is GeneratorConfig.StatCache; nil hashes every file.
+	statCache *StatCache
+	// rootHashAlg is the root hash algorithm written: RootHashV1LF under
+	// GeneratorConfig.NormalizedRootHash, else CurrentRootHashAlg.
+	rootHashAlg string
+	// followSymlinks is GeneratorConfig.FollowSymlinks.
+	followSymlinks bool
+	// strictCase is GeneratorConfig.StrictCase.
+	strictCase bool
+	// maxDepth is GeneratorConfig.MaxDepth; 0 = no limit.
+	maxDepth int
 }
 
 // GeneratorConfig configures IR generation behavior.
@@ -73,6 +134,120 @@ type GeneratorConfig struct {
 	// the RUNECHO_GENERATE_TIMEOUT env var onto this so a huge/slow-FS repo can
 	// raise or disable the ceiling without a code change.
 	GenerateTimeout time.Duration
+	// Parsers are consulted before the built-in parsers, in order, so a plugin
+	// (e.g. a parser.ExecParser declared in .runecho.json) can claim a new
+	// extension or take over a built-in one. Nil means built-ins only.
+	Parsers []parser.Parser
+	// PathFilters are consulted for every walked file and directory, before
+	// those added with RegisterPathFilter (see PathFilter).
+	PathFilters []PathFilter
+	// Extensions maps a file extension to a parser by name, ahead of every
+	// parser's own SupportsExtension: a built-in language (see parser.Builtin —
+	// ".mts": "typescript") or the Name of one of Parsers. A name that resolves
+	// to neither is dropped with a warning.
+	Extensions map[string]string
+	// Objects, when set, caches parse results by file content (see
+	// ObjectStore): a file whose content the store has seen before is not
+	// parsed again. Only built-in parsers' results are cached; a plugin's
+	// output can change without the file changing. Nil parses every file.
+	Objects *ObjectStore
+	// ParserBackend selects the JS/TS parser: ParserBackendDefault (the AST
+	// with a regex fallback where the grammar gives up) or
+	// ParserBackendTreeSitter (the AST alone; see parser.TreeSitterParser). An
+	// unknown value falls back to the default with a warning.
+	ParserBackend string
+	// MaxFileSize, when positive, is the largest file in bytes the parsers
+	// are handed. A larger one — a bundled or minified script, a generated
+	// blob — is indexed by its hash alone and flagged (see
+	// ParseSkippedTooLarge), however large, so it still counts toward the
+	// root hash. 0 leaves only the built-in 10 MiB limit, past which a file
+	// is left out of the IR as a parse error.
+	MaxFileSize int64
+	// NoGitignore walks into paths the tree's .gitignore files exclude. By
+	// default they are skipped like IgnoredPaths, unless a PathFilter
+	// returns PathInclude for them (see gitignore for what is read). The
+	// .runechoignore files (IgnoreFileName) apply either way.
+	NoGitignore bool
+	// Workers is how many files Generate and Update hash and parse at once.
+	// 0 or 1 works through them one at a time. Either way each result is
+	// recorded in walk order, so the IR, Stats, and warnings do not depend on
+	// it. Above 1, every Parsers entry must be safe for concurrent use; the
+	// built-in parsers are. 0 with IOConcurrency or ParseConcurrency set is
+	// their sum, so reading and parsing can both run at their limits.
+	Workers int
+	// IOConcurrency, when positive, is how many of the Workers may read or
+	// hash a file at once, so a tree on a network file system (NFS, a cloud
+	// workspace) is not hit with a request per core. ParseConcurrency is
+	// the same bound on parsing, the CPU-bound half. A worker holds one or
+	// the other, never both: one waiting to read does not keep another
+	// from parsing what it has read. 0 leaves the phase bounded by Workers
+	// alone.
+	IOConcurrency    int
+	ParseConcurrency int
+	// OnProgress, when set, is called once per supported file as Generate
+	// or Update finishes with it, in walk order: done of total files so far
+	// and the file's IR key. total is fixed once the walk has listed the
+	// tree, before the first file is read, and counts files past FileCap
+	// too. It runs on the caller's goroutine, between files, so a slow
+	// callback slows the walk.
+	OnProgress func(done, total int, path string)
+	// StatCache, when set, lets Update skip hashing a file whose size and
+	// mtime match what the cache saw with the hash its IR entry still has
+	// (see StatCache). Generate and Update fill it as they hash. Nil hashes
+	// every file on every Update, which catches an edit that restored both.
+	StatCache *StatCache
+	// NormalizedRootHash records each file's FileIR.NormalizedHash and
+	// computes the root hash over those (RootHashV1LF), so the same tree
+	// checked out with CRLF and with LF line endings has one root hash. File
+	// hashes stay byte-level either way. An IR written with the other
+	// algorithm is regenerated by Update, not updated.
+	NormalizedRootHash bool
+	// FollowSymlinks walks into symlinked directories and indexes symlinked
+	// files, each under the path of the link, not of its target, so the IR
+	// does not depend on where a shared package happens to live. A link to
+	// a directory the walk is already inside — the same device and inode as
+	// one of its ancestors, however reached — is a cycle and is not
+	// followed, with a warning; a broken link is an Omission. A link may
+	// lead outside the root, so set this only for a tree you trust. By
+	// default every symlink is skipped.
+	FollowSymlinks bool
+	// Logger, when set, receives the Generator's warnings in place of
+	// stderr: each Warning is a record whose message is Warning.Message,
+	// with "kind" and, when there is one, "path" attributes, at LevelInfo
+	// for a WarnHashOnly note and LevelWarn otherwise. A Logger over
+	// slog.DiscardHandler silences them: the parsers write nothing of their
+	// own, but return their diagnostics (parser.FileStructure.Diagnostics)
+	// for the Generator to warn about. GenerateResult and UpdateResult
+	// collect a walk's warnings instead of logging them either way.
+	Logger *slog.Logger
+	// StrictCase fails a walk that finds two paths differing only in case
+	// (ErrCaseCollision) rather than warning about them. For a tree whose IR
+	// must be the same when it is checked out on macOS or Windows.
+	StrictCase bool
+	// MaxDepth, when positive, is how many directories deep the walk goes
+	// below the root: with 2, "a/b/c.go" is indexed and the directory
+	// "a/b/c" is not entered. Each directory cut off is an Omission
+	// (OmissionTooDeep) for its whole subtree, so an IR bounded this way says
+	// where it stops, and the cutoff depends on keys alone, not on walk
+	// order. For GenerateRoots it counts from each root. 0 walks any depth.
+	MaxDepth int
+}
+
+// GeneratorConfig.ParserBackend values.
+const (
+	ParserBackendDefault    = ""
+	ParserBackendTreeSitter = "tree-sitter"
+)
+
+// extMapping is one resolved GeneratorConfig.Extensions entry.
+type extMapping struct {
+	p parser.Parser
+	// as is the extension the file is parsed as: the built-in language's own
+	// (".ts" for a mapped .mts, so the TS grammar is used) or, for a plugin,
+	// the file's real one.
+	as string
+	// plugin marks p as one of GeneratorConfig.Parsers.
+	plugin bool
 }
 
 // Stats reports honest-coverage counters from a Generate/Update walk.
@@ -80,6 +255,69 @@ type Stats struct {
 	ParseErrors   int // supported files that failed to parse (not in the IR)
 	SupportedSeen int // supported-extension files encountered, including beyond the cap
 	Indexed       int // files in the IR (== len(IR.Files))
+	// ParseSkipped counts indexed files whose parse was skipped (see
+	// FileIR.ParseSkipped); they are in Indexed.
+	ParseSkipped int
+	// PathCollisions counts files left out because another file's path
+	// normalizes to the same IR key (see resolveCollisions). They are not in
+	// SupportedSeen: the key they share is indexed.
+	PathCollisions int
+	// CaseCollisions counts indexed paths, files or directories, that differ
+	// only in case from one indexed before them (see caseCollisions). They
+	// are indexed; a case-insensitive checkout could not hold them.
+	CaseCollisions int
+
+	// Walked counts the files the walk reached, whatever their extension;
+	// a file in a directory it pruned is not reached. Each is in exactly one
+	// of Unsupported, Ignored, PathCollisions, and SupportedSeen.
+	Walked int
+	// Unsupported counts walked files no parser claims.
+	Unsupported int
+	// Ignored counts supported files an ignore file or a PathFilter left out.
+	Ignored int
+	// OverCap counts supported files past FileCap, seen but not read.
+	OverCap int
+	// Parsed counts the files a parser ran on and succeeded. A file Update
+	// reuses, one the ObjectStore already held, and one indexed by hash alone
+	// were not parsed.
+	Parsed int
+	// BytesHashed is how many bytes of file content were read through the
+	// content hash. A file whose hash the StatCache vouched for adds
+	// nothing; one Update reparses is hashed twice, once to see that it
+	// changed and once as it is parsed.
+	BytesHashed int64
+	// Time is the wall time the walk spent in each phase. Unlike the counts,
+	// it differs from run to run.
+	Time PhaseTimes
+}
+
+// PhaseTimes splits a Generate or Update by wall time.
+type PhaseTimes struct {
+	// Walk is listing the tree: reading directories, applying ignore rules
+	// and filters, and resolving collisions.
+	Walk time.Duration
+	// Files is reading, hashing, and parsing the files listed; with Workers
+	// above 1, the wall time over all of them at once.
+	Files time.Duration
+	// Total is the whole call, from start to the root hash.
+	Total time.Duration
+}
+
+// add adds s's counts and times to t.
+func (t *Stats) add(s Stats) {
+	t.ParseErrors += s.ParseErrors
+	t.SupportedSeen += s.SupportedSeen
+	t.PathCollisions += s.PathCollisions
+	t.CaseCollisions += s.CaseCollisions
+	t.Walked += s.Walked
+	t.Unsupported += s.Unsupported
+	t.Ignored += s.Ignored
+	t.OverCap += s.OverCap
+	t.Parsed += s.Parsed
+	t.BytesHashed += s.BytesHashed
+	t.Time.Walk += s.Time.Walk
+	t.Time.Files += s.Time.Files
+	t.Time.Total += s.Time.Total
 }
 
 // Coverage returns Indexed as a percentage of SupportedSeen.
@@ -124,16 +362,111 @@ func NewGenerator(config GeneratorConfig) *Generator {
 	if genTimeout == 0 {
 		genTimeout = DefaultGenerateTimeout
 	}
-	return &Generator{
-		parsers:       []parser.Parser{parser.NewJSParser(), parser.NewGoParser(), parser.NewPythonParser(), parser.NewShellParser(), parser.NewRustParser(), parser.NewRubyParser()},
-		ignoredPaths:  ignored,
-		fileCap:       config.FileCap,
-		maxParseBytes: defaultMaxParseBytes,
-		genTimeout:    genTimeout,
-		warn: func(format string, args ...any) {
-			fmt.Fprintf(os.Stderr, format, args...)
-		},
+	filters := append([]PathFilter(nil), config.PathFilters...)
+	filters = append(filters, registeredPathFilters()...)
+	warn := func(w Warning) {
+		fmt.Fprintln(os.Stderr, w)
+	}
+	if config.Logger != nil {
+		warn = logWarnings(config.Logger)
+	}
+	js := jsBackend(config.ParserBackend, warn)
+	parsers := append([]parser.Parser(nil), config.Parsers...)
+	parsers = append(parsers, js, parser.NewGoParser(), parser.NewPythonParser(), parser.NewShellParser(), parser.NewRustParser(), parser.NewRubyParser(), parser.NewJavaParser(), parser.NewCSharpParser(), parser.NewPHPParser(), parser.NewKotlinParser(), parser.NewSwiftParser(), parser.NewScalaParser(), parser.NewDartParser(), parser.NewElixirParser(), parser.NewLuaParser(), parser.NewSQLParser(), parser.NewProtoParser(), parser.NewDockerfileParser(), parser.NewVueParser(), parser.NewSvelteParser(), parser.NewHTMLParser(), parser.NewCSSParser(), parser.NewMarkdownParser(), parser.NewNotebookParser(), parser.NewSolidityParser(), parser.NewOpenAPIParser(), parser.NewManifestParser())
+	g := &Generator{
+		parsers:        parser.NewRegistry(parsers...),
+		ignoredPaths:   ignored,
+		filters:        filters,
+		fileCap:        config.FileCap,
+		maxParseBytes:  defaultMaxParseBytes,
+		maxLineBytes:   defaultMaxLineBytes,
+		maxFileSize:    config.MaxFileSize,
+		genTimeout:     genTimeout,
+		rootHashes:     new(rootHashCache),
+		objects:        config.Objects,
+		plugins:        len(config.Parsers),
+		workers:        config.Workers,
+		ioSlots:        slots(config.IOConcurrency),
+		parseSlots:     slots(config.ParseConcurrency),
+		onProgress:     config.OnProgress,
+		statCache:      config.StatCache,
+		gitignore:      !config.NoGitignore,
+		rootHashAlg:    CurrentRootHashAlg,
+		followSymlinks: config.FollowSymlinks,
+		strictCase:     config.StrictCase,
+		maxDepth:       config.MaxDepth,
+		warn:           warn,
+	}
+	if config.NormalizedRootHash {
+		g.rootHashAlg = RootHashV1LF
+	}
+	if g.workers == 0 {
+		g.workers = max(config.IOConcurrency, 0) + max(config.ParseConcurrency, 0)
+	}
+	g.extMap = resolveExtensions(config.Extensions, config.Parsers, g.warn)
+	for ext, p := range parser.Registered() {
+		if _, mapped := g.extMap[ext]; mapped {
+			continue // an explicit mapping wins
+		}
+		if g.extMap == nil {
+			g.extMap = make(map[string]extMapping)
+		}
+		g.extMap[ext] = extMapping{p: p, as: ext, plugin: true}
+	}
+	// A .mts → "typescript" mapping parses with the selected backend too.
+	for ext, m := range g.extMap {
+		if _, ok := m.p.(*parser.JSParser); ok && !m.plugin {
+			m.p = js
+			g.extMap[ext] = m
+		}
+	}
+	return g
+}
+
+// jsBackend returns the JS/TS parser a GeneratorConfig.ParserBackend names.
+func jsBackend(name string, warn func(Warning)) parser.Parser {
+	switch name {
+	case ParserBackendDefault:
+	case ParserBackendTreeSitter:
+		return parser.NewTreeSitterParser()
+	default:
+		warn(Warning{Kind: WarnConfig, Message: fmt.Sprintf("unknown parser backend %q; using the default", name)})
+	}
+	return parser.NewJSParser()
+}
+
+// resolveExtensions turns an extension → parser-name map into parsers. A
+// plugin name wins over a built-in language of the same name, since naming a
+// plugin after a language is how one replaces it.
+func resolveExtensions(exts map[string]string, plugins []parser.Parser, warn func(Warning)) map[string]extMapping {
+	if len(exts) == 0 {
+		return nil
+	}
+	named := make(map[string]parser.Parser, len(plugins))
+	for _, p := range plugins {
+		if n, ok := p.(interface{ Name() string }); ok {
+			named[n.Name()] = p
+		}
 	}
+	keys := make([]string, 0, len(exts))
+	for ext := range exts {
+		keys = append(keys, ext)
+	}
+	sort.Strings(keys)
+	out := make(map[string]extMapping, len(exts))
+	for _, ext := range keys {
+		name := exts[ext]
+		if p, ok := named[name]; ok {
+			out[ext] = extMapping{p: p, as: ext, plugin: true}
+			continue
+		}
+		if p, as, ok := parser.Builtin(name); ok {
+			out[ext] = extMapping{p: p, as: as}
+			continue
+		}
+		warn(Warning{Kind: WarnConfig, Message: fmt.Sprintf("extension %s mapped to unknown parser %q; ignoring", ext, name)})
+	}
+	return out
 }
 
 // walkerFunc is called for each supported source file found during a walk.
@@ -141,43 +474,454 @@ func NewGenerator(config GeneratorConfig) *Generator {
 // Returning an error from walkerFunc is propagated and stops the walk.
 type walkerFunc func(absPath, normalizedPath string) error
 
+// walkEntry is one supported file found by walkSourceFiles: its absolute path,
+// its relative path as spelled on disk (slash-separated), and its IR key.
+type walkEntry struct {
+	abs, raw, key string
+}
+
 // walkSourceFiles walks absRoot, calling fn for each supported source file.
-// It skips ignored directories, symlinked directories, and unsupported extensions.
+// It skips ignored directories, symlinks (unless FollowSymlinks), and
+// unsupported extensions.
 // The walk is checked for cancellation before each entry, so a done ctx
 // (deadline or explicit cancel) aborts it between files and propagates ctx.Err()
 // to the caller. Per-file granularity is sufficient: a single oversized file is
 // already bounded by maxParseBytes.
-func (g *Generator) walkSourceFiles(ctx context.Context, absRoot string, fn walkerFunc) error {
-	return filepath.Walk(absRoot, func(path string, info os.FileInfo, err error) error {
+//
+// Files whose paths normalize to the same key (see resolveCollisions) are
+// resolved before fn sees any of them. An entry the walk cannot read is warned
+// about and recorded as an Omission.
+func (g *Generator) walkSourceFiles(ctx context.Context, absRoot string, fn walkerFunc) (walkSummary, error) {
+	files, sum, err := g.findSourceFiles(ctx, absRoot)
+	if err != nil {
+		return walkSummary{}, err
+	}
+	for _, e := range files {
+		if cerr := ctx.Err(); cerr != nil {
+			return walkSummary{}, cerr
+		}
+		if err := fn(e.abs, e.key); err != nil {
+			return walkSummary{}, err
+		}
+	}
+	return sum, nil
+}
+
+// findSourceFiles is the walk behind walkSourceFiles: the supported files
+// under absRoot, collisions resolved, in walk order.
+func (g *Generator) findSourceFiles(ctx context.Context, absRoot string) ([]walkEntry, walkSummary, error) {
+	var found []walkEntry
+	gi := g.gitignoreFor()
+	var sum walkSummary
+	// dirs is the chain of directories from the root down to the entry being
+	// visited, a followed link's target standing for the link; FollowSymlinks
+	// checks a link against it for cycles.
+	type openDir struct {
+		path string
+		info os.FileInfo
+	}
+	var dirs []openDir
+	var visit filepath.WalkFunc
+	visit = func(path string, info os.FileInfo, err error) error {
 		if cerr := ctx.Err(); cerr != nil {
 			return cerr
 		}
 		if err != nil {
-			g.warn("Warning: failed to access %s: %v\n", path, err)
+			rel, rerr := filepath.Rel(absRoot, path)
+			if g.scope != nil && (rerr != nil || !g.scope.keeps(normalizePath(rel))) {
+				return nil // another worker's to report
+			}
+			g.warnf(WarnAccess, path, "failed to access %s: %v", path, err)
+			if rerr == nil {
+				sum.omissions = append(sum.omissions, Omission{Path: normalizePath(rel), Reason: OmissionUnreadable})
+			}
 			return nil
 		}
+		if g.followSymlinks {
+			for len(dirs) > 0 && !inDir(path, dirs[len(dirs)-1].path) {
+				dirs = dirs[:len(dirs)-1]
+			}
+		}
 		if info.Mode()&os.ModeSymlink != 0 {
-			if info.IsDir() {
-				return filepath.SkipDir
+			if !g.followSymlinks {
+				if rel, err := filepath.Rel(absRoot, path); err == nil {
+					g.skipped(normalizePath(rel), SkipSymlink)
+				}
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			target, err := os.Stat(path)
+			if err != nil {
+				return visit(path, nil, err)
+			}
+			switch {
+			case target.Mode().IsRegular():
+				info = target // indexed as the file it points to
+			case target.IsDir():
+				for _, d := range dirs {
+					if os.SameFile(d.info, target) {
+						g.warnf(WarnSymlinkCycle, path, "not following %s: it leads back to %s", path, d.path)
+						return nil
+					}
+				}
+				real, err := filepath.EvalSymlinks(path)
+				if err != nil {
+					return visit(path, nil, err)
+				}
+				// Walk the target, naming each entry by its path through the
+				// link; the target itself is entered as the link.
+				return filepath.Walk(real, func(p string, info os.FileInfo, err error) error {
+					if p == real {
+						return visit(path, target, err)
+					}
+					rel, rerr := filepath.Rel(real, p)
+					if rerr != nil {
+						return rerr
+					}
+					return visit(filepath.Join(path, rel), info, err)
+				})
+			default:
+				return nil
 			}
-			return nil
 		}
 		if info.IsDir() {
-			if g.ignoredPaths[filepath.Base(path)] {
+			if path == absRoot {
+				// The root is not an entry of the tree: filters never see it.
+				if g.ignoredPaths[filepath.Base(path)] {
+					return filepath.SkipDir
+				}
+				gi.read(".", path)
+				if g.followSymlinks {
+					dirs = append(dirs, openDir{path, info})
+				}
+				return nil
+			}
+			relPath, err := filepath.Rel(absRoot, path)
+			if err != nil {
+				g.warnf(WarnAccess, path, "failed to compute relative path for %s: %v", path, err)
+				return nil
+			}
+			if g.scope != nil && !g.scope.descend(normalizePath(relPath)) {
 				return filepath.SkipDir
 			}
-			return nil
-		}
-		if !g.supportsExtension(filepath.Ext(path)) {
+			if g.skipDir(gi, normalizePath(relPath), fs.FileInfoToDirEntry(info)) {
+				g.skipped(normalizePath(relPath)+"/", SkipIgnored)
+				return filepath.SkipDir
+			}
+			if g.tooDeep(normalizePath(relPath)) {
+				g.skipped(normalizePath(relPath)+"/", SkipTooDeep)
+				if g.scope == nil || g.scope.keeps(normalizePath(relPath)) {
+					sum.omissions = append(sum.omissions, Omission{Path: normalizePath(relPath), Reason: OmissionTooDeep})
+				}
+				return filepath.SkipDir
+			}
+			gi.read(normalizePath(relPath), path)
+			if g.followSymlinks {
+				dirs = append(dirs, openDir{path, info})
+			}
 			return nil
 		}
 		relPath, err := filepath.Rel(absRoot, path)
 		if err != nil {
-			g.warn("Warning: failed to compute relative path for %s: %v\n", path, err)
+			g.warnf(WarnAccess, path, "failed to compute relative path for %s: %v", path, err)
 			return nil
 		}
-		return fn(path, normalizePath(relPath))
-	})
+		normalized := normalizePath(relPath)
+		if g.scope != nil && !g.scope.keeps(normalized) {
+			return nil
+		}
+		sum.walked++
+		if !g.supportsFile(path) {
+			sum.unsupported++
+			g.skipped(normalized, SkipUnsupported)
+			return nil
+		}
+		if g.skipFile(gi, normalized, fs.FileInfoToDirEntry(info)) {
+			sum.ignored++
+			g.skipped(normalized, SkipIgnored)
+			return nil
+		}
+		found = append(found, walkEntry{abs: path, raw: filepath.ToSlash(relPath), key: normalized})
+		return nil
+	}
+	err := filepath.Walk(absRoot, visit)
+	if err != nil {
+		return nil, walkSummary{}, err
+	}
+	sort.Slice(sum.omissions, func(i, j int) bool { return sum.omissions[i].Path < sum.omissions[j].Path })
+	var winners []walkEntry
+	winners, sum.collisions = g.resolveCollisions(found)
+	if g.explain != nil && sum.collisions > 0 {
+		kept := make(map[string]bool, len(winners))
+		for _, e := range winners {
+			kept[e.raw] = true
+		}
+		for _, e := range found {
+			if !kept[e.raw] {
+				g.skipped(e.raw, SkipCollision)
+			}
+		}
+	}
+	if sum.caseCollisions, err = g.caseCollisions(absRoot, winners); err != nil {
+		return nil, walkSummary{}, err
+	}
+	return winners, sum, nil
+}
+
+// fileResult is what a walk's work on one file produced, held until the file's
+// turn comes to be recorded (see inOrder).
+type fileResult struct {
+	f   FileIR
+	err error // the file failed to parse; a Stats.ParseErrors
+	// skip leaves the file out without counting it as a parse error.
+	skip bool
+	// warnings were raised while f was produced, and are replayed when it
+	// is recorded.
+	warnings []Warning
+	// parsed and hashed are f's share of Stats.Parsed and BytesHashed.
+	parsed bool
+	hashed int64
+}
+
+// deferWarnings returns a copy of g whose warnings are held in r rather than
+// written, so the work on a file can run off the walk's goroutine and its
+// warnings still come out in walk order.
+func (g *Generator) deferWarnings(r *fileResult) *Generator {
+	w := *g
+	w.warn = func(x Warning) { r.warnings = append(r.warnings, x) }
+	w.tally = r
+	return &w
+}
+
+// hashed counts n bytes of file content hashed.
+func (g *Generator) hashed(n int64) {
+	if g.tally != nil {
+		g.tally.hashed += n
+	}
+}
+
+// record replays r's warnings and counts its failure, and reports whether
+// e's FileIR belongs in the IR.
+func (g *Generator) record(e walkEntry, r fileResult, stats *Stats) bool {
+	for _, w := range r.warnings {
+		g.warn(w)
+	}
+	stats.BytesHashed += r.hashed
+	if r.parsed {
+		stats.Parsed++
+	}
+	if r.err != nil {
+		g.warnf(WarnParse, e.abs, "failed to parse %s: %v", e.abs, r.err)
+		stats.ParseErrors++
+		return false
+	}
+	return !r.skip
+}
+
+// progress reports a recorded file to GeneratorConfig.OnProgress.
+func (g *Generator) progress(done, total int, key string) {
+	if g.onProgress != nil {
+		g.onProgress(done, total, key)
+	}
+}
+
+// inOrder calls record for each of files, in order, with a get that returns
+// work's result for the file. With workers above 1, work runs on that many
+// goroutines, at most 2*workers files ahead of record; otherwise get runs it,
+// so a record that never calls get saves the work. record always runs on the
+// caller's goroutine. A done ctx stops the loop between files with ctx.Err().
+func inOrder[T any](ctx context.Context, workers int, files []walkEntry, work func(walkEntry) T, record func(e walkEntry, get func() T)) error {
+	if workers <= 1 {
+		for _, e := range files {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			record(e, func() T { return work(e) })
+		}
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	results := make([]chan T, len(files))
+	for i := range results {
+		results[i] = make(chan T, 1)
+	}
+	// ahead holds a token for each file handed out but not yet recorded.
+	ahead := make(chan struct{}, 2*workers)
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range files {
+			select {
+			case ahead <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] <- work(files[i])
+			}
+		}()
+	}
+	for i, e := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var (
+			got  T
+			done bool
+		)
+		record(e, func() T {
+			if !done {
+				select {
+				case got = <-results[i]:
+				case <-ctx.Done():
+				}
+				done = true
+			}
+			return got
+		})
+		<-ahead
+	}
+	// A file record asked for after ctx was done got a zero result.
+	return ctx.Err()
+}
+
+// slots returns a semaphore of n tokens, or nil for n <= 0.
+func slots(n int) chan struct{} {
+	if n <= 0 {
+		return nil
+	}
+	return make(chan struct{}, n)
+}
+
+// acquire takes one of sem's tokens, waiting for it, and returns the func
+// that puts it back. A nil sem is unbounded.
+func acquire(sem chan struct{}) (release func()) {
+	if sem == nil {
+		return func() {}
+	}
+	sem <- struct{}{}
+	return func() { <-sem }
+}
+
+// walkSummary is what walkSourceFiles reports besides the files themselves.
+type walkSummary struct {
+	collisions     int
+	caseCollisions int
+	omissions      []Omission
+	// walked, unsupported, and ignored are Stats.Walked, Unsupported, and
+	// Ignored.
+	walked, unsupported, ignored int
+}
+
+// count copies sum's counters into stats.
+func (sum walkSummary) count(stats *Stats) {
+	stats.PathCollisions = sum.collisions
+	stats.CaseCollisions = sum.caseCollisions
+	stats.Walked = sum.walked
+	stats.Unsupported = sum.unsupported
+	stats.Ignored = sum.ignored
+}
+
+// resolveCollisions keeps one file per IR key. Two files collide when their
+// paths differ on disk but normalize to the same key — a precomposed and a
+// decomposed "café.go" side by side, which some filesystems allow. Letting the
+// later one overwrite the earlier would make the IR depend on walk order, so
+// the winner is chosen from the spellings alone: the one already in normal
+// form, else the bytewise-smallest. Each loser is named in a warning. Order of
+// the survivors is preserved.
+func (g *Generator) resolveCollisions(found []walkEntry) ([]walkEntry, int) {
+	best := make(map[string]int, len(found))
+	collided := false
+	for i, e := range found {
+		j, seen := best[e.key]
+		if !seen {
+			best[e.key] = i
+			continue
+		}
+		collided = true
+		if preferSpelling(e, found[j]) {
+			best[e.key] = i
+		}
+	}
+	if !collided {
+		return found, 0
+	}
+	winners := make([]walkEntry, 0, len(best))
+	dropped := 0
+	for i, e := range found {
+		if w := found[best[e.key]]; best[e.key] != i {
+			g.warnf(WarnPathCollision, e.abs, "skipping %+q: its path normalizes to %+q, which %+q already claims", e.raw, e.key, w.raw)
+			dropped++
+			continue
+		}
+		winners = append(winners, e)
+	}
+	return winners, dropped
+}
+
+// preferSpelling reports whether a should index instead of b (same key).
+func preferSpelling(a, b walkEntry) bool {
+	if (a.raw == a.key) != (b.raw == b.key) {
+		return a.raw == a.key
+	}
+	return a.raw < b.raw
+}
+
+// ErrCaseCollision is what a GeneratorConfig.StrictCase walk fails with,
+// wrapped, on two paths that differ only in case.
+var ErrCaseCollision = errors.New("paths differ only in case")
+
+// caseCollisions finds the paths among files, under root — their keys and
+// the directories above them — that differ only in case from one before
+// them: "Utils.ts" beside "utils.ts", or "Src/a.ts" beside "src/b.ts". A
+// case-insensitive filesystem, the default on macOS and Windows, holds one
+// of each such pair, so a checkout there would index other files, or the
+// same files under other keys. Each is named in a WarnCaseCollision, unless
+// g.strictCase makes the first an error. It returns how many there were.
+func (g *Generator) caseCollisions(root string, files []walkEntry) (int, error) {
+	fold := cases.Fold()
+	seen := make(map[string]string) // folded path → the first spelling
+	n := 0
+	for _, e := range files {
+		for p := e.key; p != "."; p = path.Dir(p) {
+			folded := fold.String(p)
+			first, ok := seen[folded]
+			if !ok {
+				seen[folded] = p
+				continue
+			}
+			if first == p {
+				break // a directory already seen, and so are those above it
+			}
+			n++
+			if g.strictCase {
+				return n, fmt.Errorf("%w: %q and %q", ErrCaseCollision, first, p)
+			}
+			g.warnf(WarnCaseCollision, filepath.Join(root, filepath.FromSlash(p)), "%q and %q differ only in case; a case-insensitive checkout cannot hold both", first, p)
+			// The walk lists a directory's files together, so p's next files
+			// find it as the spelling seen and are not reported again.
+			seen[folded] = p
+		}
+	}
+	return n, nil
 }
 
 // Generate creates IR for all supported files in the given root directory.
@@ -195,6 +939,7 @@ func (g *Generator) Generate(rootPath string) (*IR, Stats, error) {
 // context is cancelled or its deadline passes, the walk stops between files, the
 // partial result is discarded, and the (wrapped) ctx error is returned.
 func (g *Generator) GenerateCtx(ctx context.Context, rootPath string) (*IR, Stats, error) {
+	start := time.Now()
 	ctx, cancel := g.withDeadline(ctx)
 	defer cancel()
 
@@ -204,28 +949,48 @@ func (g *Generator) GenerateCtx(ctx context.Context, rootPath string) (*IR, Stat
 	}
 	absRoot = filepath.Clean(absRoot)
 
-	result := &IR{Version: IRVersion, Files: make(map[string]FileIR)}
+	result := &IR{Version: IRVersion, RootHashAlg: g.rootHashAlg, Files: make(map[string]FileIR)}
 	var stats Stats
 
-	if err := g.walkSourceFiles(ctx, absRoot, func(absPath, normPath string) error {
-		stats.SupportedSeen++
-		if g.capReached(len(result.Files)) {
-			return nil // count only; cap bounds parse work, not the denominator
-		}
-		fileIR, err := g.parseFile(absPath)
-		if err != nil {
-			g.warn("Warning: failed to parse %s: %v\n", absPath, err)
-			stats.ParseErrors++
-			return nil
-		}
-		result.Files[normPath] = fileIR
-		return nil
-	}); err != nil {
+	files, sum, err := g.findSourceFiles(ctx, absRoot)
+	stats.Time.Walk = time.Since(start)
+	if err == nil {
+		err = inOrder(ctx, g.workers, files, func(e walkEntry) fileResult {
+			var r fileResult
+			var info fs.FileInfo
+			if g.statCache != nil {
+				info, _ = os.Stat(e.abs) // before the read, so a later edit moves it
+			}
+			r.f, r.err = g.deferWarnings(&r).parseFile(e.abs, e.key)
+			if info != nil && r.err == nil {
+				g.statCache.record(e.abs, info, r.f.Hash)
+			}
+			return r
+		}, func(e walkEntry, get func() fileResult) {
+			stats.SupportedSeen++
+			defer g.progress(stats.SupportedSeen, len(files), e.key)
+			if g.capReached(len(result.Files)) {
+				stats.OverCap++
+				return // count only; cap bounds parse work, not the denominator
+			}
+			if r := get(); g.record(e, r, &stats) {
+				result.Files[e.key] = r.f
+			}
+		})
+		stats.Time.Files = time.Since(start) - stats.Time.Walk
+	}
+	if err != nil {
+		g.statCache.discard()
 		return nil, Stats{}, fmt.Errorf("failed to walk directory: %w", err)
 	}
+	g.statCache.commit()
+	sum.count(&stats)
+	result.Omissions = sum.omissions
 
-	result.RootHash = ComputeRootHash(result.Files)
+	result.RootHash = g.rootHash(result.Files)
 	stats.Indexed = len(result.Files)
+	stats.ParseSkipped = countParseSkipped(result.Files)
+	stats.Time.Total = time.Since(start)
 	return result, stats, nil
 }
 
@@ -237,7 +1002,9 @@ func (g *Generator) GenerateCtx(ctx context.Context, rootPath string) (*IR, Stat
 // A version-mismatched IR falls back to a full Generate: Update reuses entries
 // for unchanged files verbatim, which would leave fields added by newer format
 // versions (e.g. v2 refs) empty forever. Guarding here — not just at call
-// sites — means no caller can perpetuate a stale format by mistake.
+// sites — means no caller can perpetuate a stale format by mistake. An IR
+// hashed with another root hash algorithm than g writes (see
+// GeneratorConfig.NormalizedRootHash) is regenerated the same way.
 func (g *Generator) Update(existingIR *IR, rootPath string) (*IR, Stats, error) {
 	return g.UpdateCtx(context.Background(), existingIR, rootPath)
 }
@@ -247,9 +1014,10 @@ func (g *Generator) Update(existingIR *IR, rootPath string) (*IR, Stats, error)
 // version-mismatch fallback forwards ctx to GenerateCtx so the bound holds on
 // either path.
 func (g *Generator) UpdateCtx(ctx context.Context, existingIR *IR, rootPath string) (*IR, Stats, error) {
-	if existingIR == nil || existingIR.Version != IRVersion {
+	if existingIR == nil || existingIR.Version != IRVersion || existingIR.RootHashAlgorithm() != g.rootHashAlg {
 		return g.GenerateCtx(ctx, rootPath)
 	}
+	start := time.Now()
 	ctx, cancel := g.withDeadline(ctx)
 	defer cancel()
 
@@ -259,48 +1027,202 @@ func (g *Generator) UpdateCtx(ctx context.Context, existingIR *IR, rootPath stri
 	}
 	absRoot = filepath.Clean(absRoot)
 
-	updated := &IR{Version: IRVersion, Files: make(map[string]FileIR)}
+	updated := &IR{Version: IRVersion, RootHashAlg: g.rootHashAlg, Files: make(map[string]FileIR)}
 	var stats Stats
 
-	if err := g.walkSourceFiles(ctx, absRoot, func(absPath, normPath string) error {
-		stats.SupportedSeen++
-		if g.capReached(len(updated.Files)) {
-			return nil // count only; cap bounds parse work, not the denominator
-		}
-		// Guard size before hashing: HashFile streams the whole file through
-		// SHA-256, and parseFile rejects anything over maxParseBytes anyway, so
-		// without this an oversized file is fully read on every Update only to be
-		// rejected at parse. Generate guards inside parseFile; mirror it here.
-		// A stat error falls through to HashFile, which surfaces it as before.
-		if info, serr := os.Stat(absPath); serr == nil && info.Size() > g.maxParseBytes {
-			g.warn("Warning: failed to parse %s: skipping oversized file (%d bytes)\n", absPath, info.Size())
-			stats.ParseErrors++
-			return nil
-		}
-		currentHash, err := HashFile(absPath)
-		if err != nil {
-			g.warn("Warning: failed to hash %s: %v\n", absPath, err)
-			return nil
+	files, sum, err := g.findSourceFiles(ctx, absRoot)
+	stats.Time.Walk = time.Since(start)
+	if err == nil {
+		err = inOrder(ctx, g.workers, files, func(e walkEntry) fileResult {
+			var r fileResult
+			w := g.deferWarnings(&r)
+			// Guard size before hashing: HashFile streams the whole file through
+			// SHA-256, and parseFile rejects anything over maxParseBytes anyway, so
+			// without this an oversized file is fully read on every Update only to be
+			// rejected at parse. Generate guards inside parseFile; mirror it here.
+			// A stat error falls through to HashFile, which surfaces it as before.
+			info, serr := os.Stat(e.abs)
+			if serr == nil && info.Size() > g.maxParseBytes && !g.tooLarge(info.Size()) {
+				r.err = fmt.Errorf("skipping oversized file (%d bytes)", info.Size())
+				return r
+			}
+			existing, known := existingIR.Files[e.key]
+			if serr == nil && known {
+				if h, ok := g.statCache.hash(e.abs, info); ok && h == existing.Hash {
+					g.statCache.record(e.abs, info, h)
+					r.f = existing
+					return r
+				}
+			}
+			release := acquire(g.ioSlots)
+			currentHash, err := HashFile(e.abs)
+			release()
+			if err != nil {
+				w.warnf(WarnAccess, e.abs, "failed to hash %s: %v", e.abs, err)
+				r.skip = true
+				return r
+			}
+			if serr == nil {
+				w.hashed(info.Size())
+				g.statCache.record(e.abs, info, currentHash)
+			}
+			if known && existing.Hash == currentHash {
+				r.f = existing
+				return r
+			}
+			if serr == nil && g.tooLarge(info.Size()) {
+				r.f, r.err = w.hashOnly(e.abs, currentHash, info.Size(), func() (io.ReadCloser, error) { return os.Open(e.abs) })
+				return r
+			}
+			r.f, r.err = w.parseFile(e.abs, e.key)
+			return r
+		}, func(e walkEntry, get func() fileResult) {
+			stats.SupportedSeen++
+			defer g.progress(stats.SupportedSeen, len(files), e.key)
+			if g.capReached(len(updated.Files)) {
+				stats.OverCap++
+				return // count only; cap bounds parse work, not the denominator
+			}
+			if r := get(); g.record(e, r, &stats) {
+				updated.Files[e.key] = r.f
+			}
+		})
+		stats.Time.Files = time.Since(start) - stats.Time.Walk
+	}
+	if err != nil {
+		g.statCache.discard()
+		return nil, Stats{}, fmt.Errorf("failed to walk directory: %w", err)
+	}
+	g.statCache.commit()
+	sum.count(&stats)
+	updated.Omissions = sum.omissions
+
+	updated.RootHash = g.rootHash(updated.Files)
+	stats.Indexed = len(updated.Files)
+	stats.ParseSkipped = countParseSkipped(updated.Files)
+	stats.Time.Total = time.Since(start)
+	return updated, stats, nil
+}
+
+// ChangeSet names the files one IR adds, changes, and drops relative to
+// another, each list sorted by IR key. A renamed file is one removal and one
+// addition.
+type ChangeSet struct {
+	Added    []string
+	Modified []string // the same key with a different content hash
+	Removed  []string
+}
+
+// Empty reports whether c names no file.
+func (c ChangeSet) Empty() bool {
+	return len(c.Added) == 0 && len(c.Modified) == 0 && len(c.Removed) == 0
+}
+
+// DiffFiles returns the ChangeSet that takes from's files to to's. It
+// compares keys and hashes only, so a file reparsed to different symbols
+// under an unchanged hash (a format upgrade) is not Modified. A nil IR has no
+// files.
+func DiffFiles(from, to *IR) ChangeSet {
+	var a, b map[string]FileIR
+	if from != nil {
+		a = from.Files
+	}
+	if to != nil {
+		b = to.Files
+	}
+	var c ChangeSet
+	for key, f := range b {
+		if old, ok := a[key]; !ok {
+			c.Added = append(c.Added, key)
+		} else if old.Hash != f.Hash {
+			c.Modified = append(c.Modified, key)
 		}
-		if existing, ok := existingIR.Files[normPath]; ok && existing.Hash == currentHash {
-			updated.Files[normPath] = existing
-			return nil
+	}
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			c.Removed = append(c.Removed, key)
 		}
-		fileIR, err := g.parseFile(absPath)
-		if err != nil {
-			g.warn("Warning: failed to parse %s: %v\n", absPath, err)
-			stats.ParseErrors++
-			return nil
+	}
+	slices.Sort(c.Added)
+	slices.Sort(c.Modified)
+	slices.Sort(c.Removed)
+	return c
+}
+
+// UpdateChanges is UpdateCtx that also reports what the update changed, so a
+// caller can log or react to deletions and renames without keeping the old
+// IR around to diff. A version-mismatched existingIR is still diffed by key
+// and hash against the regenerated one.
+func (g *Generator) UpdateChanges(ctx context.Context, existingIR *IR, rootPath string) (*IR, Stats, ChangeSet, error) {
+	updated, stats, err := g.UpdateCtx(ctx, existingIR, rootPath)
+	if err != nil {
+		return nil, stats, ChangeSet{}, err
+	}
+	return updated, stats, DiffFiles(existingIR, updated), nil
+}
+
+// FileStat is what a stat walk knows of one file: where it is on disk, its
+// size, and its modification time. Nothing is read.
+type FileStat struct {
+	Abs     string
+	Size    int64
+	ModTime time.Time
+}
+
+// StatTree stats, without reading, every file a Generate of rootPath would
+// see, keyed by IR key. It is the walk behind TreeStamp, for a caller that
+// needs to know which files moved, not only that some did.
+//
+// The walk is silent: an unreadable entry already warns on every Generate,
+// and a poller would repeat that warning on every tick. A file that vanishes
+// between the walk and its stat is left out.
+func (g *Generator) StatTree(ctx context.Context, rootPath string) (map[string]FileStat, error) {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	quiet := *g
+	quiet.warn = func(Warning) {}
+	out := make(map[string]FileStat)
+	_, err = quiet.walkSourceFiles(ctx, filepath.Clean(absRoot), func(absPath, normPath string) error {
+		if info, err := quiet.statLink(absPath); err == nil {
+			out[normPath] = FileStat{Abs: absPath, Size: info.Size(), ModTime: info.ModTime()}
 		}
-		updated.Files[normPath] = fileIR
 		return nil
-	}); err != nil {
-		return nil, Stats{}, fmt.Errorf("failed to walk directory: %w", err)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return out, nil
+}
 
-	updated.RootHash = ComputeRootHash(updated.Files)
-	stats.Indexed = len(updated.Files)
-	return updated, stats, nil
+// StampOf digests a StatTree result into one string: equal stamps, equal
+// trees, as far as sizes and mtimes can tell.
+func StampOf(stats map[string]FileStat) string {
+	keys := make([]string, 0, len(stats))
+	for k := range stats {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", k, stats[k].Size, stats[k].ModTime.UnixNano())
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// TreeStamp summarizes, without reading any file, the state of every file a
+// Generate of rootPath would see: its IR key, size, and modification time. Two
+// equal stamps mean nothing a walk would notice has changed since — short of
+// an edit that keeps a file's size within one mtime tick, which a caller that
+// must catch it has to learn about some other way. It is what a watcher polls
+// to tell a busy tree from a quiescent one at a fraction of an Update's cost.
+func (g *Generator) TreeStamp(ctx context.Context, rootPath string) (string, error) {
+	stats, err := g.StatTree(ctx, rootPath)
+	if err != nil {
+		return "", err
+	}
+	return StampOf(stats), nil
 }
 
 // UpdateFile refreshes a single file's entry in an existing IR and returns the
@@ -310,11 +1232,21 @@ func (g *Generator) UpdateCtx(ctx context.Context, existingIR *IR, rootPath stri
 // hook where walking the whole tree on every keystroke would be wasteful.
 //
 // It is conservative: any condition it can't handle cleanly (file outside the
-// repo, stat/parse error, IR version mismatch) returns the IR unchanged with
+// repo, stat/parse error, IR version mismatch, a multi-root IR) returns the IR unchanged with
 // changed=false, so the caller simply skips the refresh rather than corrupting
 // state. RootHash is recomputed; changed is RootHash != existing.RootHash.
 func (g *Generator) UpdateFile(existing *IR, rootPath, filePath string) (*IR, bool, error) {
-	if existing == nil || existing.Version != IRVersion {
+	return g.UpdatePaths(existing, rootPath, []string{filePath})
+}
+
+// UpdatePaths is UpdateFile for a batch: each of filePaths is refreshed the
+// way UpdateFile would, but the IR is copied and its root hash computed once,
+// not once per file. A watcher replaying the files a crash left unindexed
+// uses it instead of an Update, which would re-hash every file in the repo.
+func (g *Generator) UpdatePaths(existing *IR, rootPath string, filePaths []string) (*IR, bool, error) {
+	// A multi-root IR's roots are walked apart, each under its own
+	// .gitignore files, which a refresh from rootPath would not see.
+	if existing == nil || existing.Version != IRVersion || existing.RootHashAlgorithm() != g.rootHashAlg || existing.Roots != nil {
 		return existing, false, nil
 	}
 	absRoot, err := filepath.Abs(rootPath)
@@ -322,56 +1254,167 @@ func (g *Generator) UpdateFile(existing *IR, rootPath, filePath string) (*IR, bo
 		return existing, false, nil
 	}
 	absRoot = filepath.Clean(absRoot)
+
+	// Copy the map so the returned IR is independent of existing (callers may
+	// keep using existing if changed=false).
+	files := make(map[string]FileIR, len(existing.Files))
+	for k, v := range existing.Files {
+		files[k] = v
+	}
+	touched := false
+	for _, filePath := range filePaths {
+		if g.refreshPath(files, absRoot, filePath) {
+			touched = true
+		}
+	}
+	if !touched {
+		return existing, false, nil
+	}
+
+	updated := &IR{Version: IRVersion, RootHashAlg: g.rootHashAlg, Files: files, Omissions: existing.Omissions}
+	updated.RootHash = g.rootHash(files)
+	return updated, updated.RootHash != existing.RootHash, nil
+}
+
+// refreshPath applies one UpdateFile step to files and reports whether it
+// changed the map.
+func (g *Generator) refreshPath(files map[string]FileIR, absRoot, filePath string) bool {
 	absFile, err := filepath.Abs(filePath)
 	if err != nil {
-		return existing, false, nil
+		return false
 	}
 	rel, err := filepath.Rel(absRoot, absFile)
 	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
-		return existing, false, nil // edited file is outside this repo
+		return false // edited file is outside this repo
 	}
 	norm := normalizePath(rel)
-
-	// Copy the map so the returned IR is independent of existing (callers may
-	// keep using existing if changed=false).
-	files := make(map[string]FileIR, len(existing.Files))
-	for k, v := range existing.Files {
-		files[k] = v
+	if shadowedSpelling(absRoot, absFile, rel, norm) {
+		return false // the key belongs to another spelling of this path
 	}
 
 	info, statErr := os.Stat(absFile)
 	switch {
 	case statErr != nil:
 		if !os.IsNotExist(statErr) {
-			return existing, false, nil // transient stat error — leave IR alone
+			return false // transient stat error — leave IR alone
 		}
 		if _, ok := files[norm]; !ok {
-			return existing, false, nil // already absent
+			return false // already absent
 		}
 		delete(files, norm) // file was deleted
-	case info.IsDir() || !g.supportsExtension(filepath.Ext(absFile)) || pathCrossesSymlink(absRoot, absFile):
-		// Not an indexed source file. A symlink — the edited target itself or any
+	case info.IsDir() || !g.supportsFile(absFile) || g.linkRefused(absRoot, absFile) || g.pathFilteredOut(absRoot, absFile):
+		// Not an indexed source file. A symlink (unless FollowSymlinks) — the edited target itself or any
 		// directory component within the repo — mirrors walkSourceFiles, which skips
 		// symlinked files and dirs: without this the per-edit refresh would os.Stat
 		// through the link and pull an out-of-repo target's content into the IR under
-		// an in-repo key, while a full walk skipped it (#143). If a real file at this
-		// key used to be indexed (extension changed, or a file replaced by a symlink),
+		// an in-repo key, while a full walk skipped it (#143). The same goes for a
+		// file under an ignored directory or refused by a PathFilter. If a real
+		// file at this key used to be indexed (extension changed, or a file replaced by a symlink),
 		// drop the stale entry; otherwise no-op.
 		if _, ok := files[norm]; !ok {
-			return existing, false, nil
+			return false
 		}
 		delete(files, norm)
 	default:
-		fileIR, perr := g.parseFile(absFile)
+		fileIR, perr := g.parseFile(absFile, norm)
 		if perr != nil {
-			return existing, false, nil // parse failed — keep the prior entry
+			return false // parse failed — keep the prior entry
 		}
 		files[norm] = fileIR
 	}
+	return true
+}
 
-	updated := &IR{Version: IRVersion, Files: files}
-	updated.RootHash = ComputeRootHash(files)
-	return updated, updated.RootHash != existing.RootHash, nil
+// UpdateSingleFile refreshes relPath's entry in irIn, in place, for an
+// editor's on-save hook, and reports whether the root hash changed. relPath
+// is relative to root, in either slash or OS form.
+//
+// The case it is built for — an indexed file saved with new content — costs
+// one Lstat and one read of the file (its parent directories are Lstat-ed
+// too, for the symlink rule, but those are in the kernel's cache), a parse,
+// and a root hash that reuses the sorted preimage of the previous call's
+// (see rootHashCache) instead of re-sorting every key: a few milliseconds on
+// a 20,000-file repo, where UpdateFile spends most of its time copying the
+// file map and re-sorting it.
+// A save that leaves the bytes as they were is not even parsed. Anything
+// else — a new, deleted, or renamed file, a symlink, an oversized file —
+// takes UpdateFile's path, applied in place.
+//
+// irIn is modified, not copied: pass an IR nobody else is reading (not a
+// watch.Snapshot's). Like UpdateFile it is conservative, and a file it
+// cannot read or parse keeps its prior entry; but an IR of another version
+// or root hash algorithm, or a relPath outside root, is an error rather than a silent no-op, so a
+// plugin knows to fall back to a full Update.
+func (g *Generator) UpdateSingleFile(irIn *IR, root, relPath string) (bool, error) {
+	if irIn == nil || irIn.Version != IRVersion {
+		return false, fmt.Errorf("UpdateSingleFile: IR is not version %d; run a full Update", IRVersion)
+	}
+	if alg := irIn.RootHashAlgorithm(); alg != g.rootHashAlg {
+		return false, fmt.Errorf("UpdateSingleFile: IR root hash is %s, not %s; run a full Update", alg, g.rootHashAlg)
+	}
+	if irIn.Roots != nil {
+		return false, errors.New("UpdateSingleFile: IR has several roots; run UpdateRoots")
+	}
+	rel := filepath.Clean(filepath.FromSlash(relPath))
+	if !filepath.IsLocal(rel) {
+		return false, fmt.Errorf("UpdateSingleFile: %q is not inside the root", relPath)
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	absRoot = filepath.Clean(absRoot)
+	absFile := filepath.Join(absRoot, rel)
+	key := normalizePath(rel)
+
+	// The fast path needs an entry the walk already vetted under this exact
+	// spelling, still a regular file reached through no symlink.
+	prev, indexed := irIn.Files[key]
+	info, statErr := os.Lstat(absFile)
+	if !indexed || filepath.ToSlash(rel) != key || statErr != nil || !info.Mode().IsRegular() ||
+		info.Size() > g.maxParseBytes || g.tooLarge(info.Size()) || pathCrossesSymlink(absRoot, filepath.Dir(absFile)) {
+		if !g.refreshPath(irIn.Files, absRoot, absFile) {
+			return false, nil
+		}
+		old := irIn.RootHash
+		irIn.RootHashAlg = g.rootHashAlg
+		irIn.RootHash = g.rootHash(irIn.Files)
+		return irIn.RootHash != old, nil
+	}
+	content, err := os.ReadFile(absFile)
+	if err != nil {
+		return false, nil
+	}
+	hash := HashBytes(content)
+	if hash == prev.Hash {
+		return false, nil
+	}
+	fileIR, err := g.parseContent(absFile, key, content, hash)
+	if err != nil {
+		return false, nil // keep the prior entry, as UpdateFile does
+	}
+	irIn.Files[key] = fileIR
+	irIn.RootHashAlg = g.rootHashAlg
+	irIn.RootHash = g.rootHashes.rehash(irIn, g.rootHashAlg, key, fileHash(g.rootHashAlg, prev))
+	return true, nil
+}
+
+// shadowedSpelling reports whether rel is not spelled in normal form while a
+// different file spelled exactly as key exists. resolveCollisions gives the key
+// to that file, so refreshing (or deleting) this one would overwrite it. On a
+// filesystem that ignores normalization both spellings name one file, which
+// SameFile catches. Two non-normal spellings of one key are not detected here;
+// the next full walk settles them.
+func shadowedSpelling(absRoot, absFile, rel, key string) bool {
+	if filepath.ToSlash(rel) == key {
+		return false
+	}
+	canon, err := os.Lstat(filepath.Join(absRoot, filepath.FromSlash(key)))
+	if err != nil {
+		return false
+	}
+	self, err := os.Lstat(absFile)
+	return err != nil || !os.SameFile(canon, self)
 }
 
 // pathCrossesSymlink reports whether absFile, or any directory component strictly
@@ -405,6 +1448,61 @@ func pathCrossesSymlink(absRoot, absFile string) bool {
 	return false
 }
 
+// inDir reports whether path is dir or lies under it.
+func inDir(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// linkRefused reports whether the symlinks between absRoot and absFile keep
+// the walk from absFile: any symlink at all (pathCrossesSymlink), or under
+// FollowSymlinks, one that closes a cycle (pathLoops).
+func (g *Generator) linkRefused(absRoot, absFile string) bool {
+	if !g.followSymlinks {
+		return pathCrossesSymlink(absRoot, absFile)
+	}
+	return pathLoops(absRoot, absFile)
+}
+
+// pathLoops reports whether a directory between absRoot and absFile, links
+// followed, is the same directory as one above it: the link that leads back
+// is one the FollowSymlinks walk does not follow. A component that cannot be
+// stat'ed defers to the caller (false), like pathCrossesSymlink.
+func pathLoops(absRoot, absFile string) bool {
+	rel, err := filepath.Rel(absRoot, filepath.Dir(absFile))
+	if err != nil || rel == "." {
+		return false
+	}
+	info, err := os.Stat(absRoot)
+	if err != nil {
+		return false
+	}
+	above := []os.FileInfo{info}
+	p := absRoot
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		p = filepath.Join(p, part)
+		info, err := os.Stat(p)
+		if err != nil {
+			return false
+		}
+		for _, a := range above {
+			if os.SameFile(a, info) {
+				return true
+			}
+		}
+		above = append(above, info)
+	}
+	return false
+}
+
+// statLink stats name as the walk sees it: the symlink itself, or under
+// FollowSymlinks, what it points to.
+func (g *Generator) statLink(name string) (os.FileInfo, error) {
+	if g.followSymlinks {
+		return os.Stat(name)
+	}
+	return os.Lstat(name)
+}
+
 // normalizePath applies all path normalization rules:
 // 1. Convert to forward slashes (filepath.ToSlash)
 // 2. Strip repeated leading "./" segments if present
@@ -432,24 +1530,25 @@ func normalizePath(relPath string) string {
 	return normalized
 }
 
-// supportsExtension returns true if any registered parser handles this extension.
-func (g *Generator) supportsExtension(ext string) bool {
-	for _, p := range g.parsers {
-		if p.SupportsExtension(ext) {
-			return true
-		}
-	}
-	return false
+// supportsFile returns true if an extension mapping or any registered parser
+// handles the file at path.
+func (g *Generator) supportsFile(path string) bool {
+	p, _, _ := g.parserFor(path)
+	return p != nil
 }
 
-// parserFor returns the first parser that supports the given extension, or nil.
-func (g *Generator) parserFor(ext string) parser.Parser {
-	for _, p := range g.parsers {
-		if p.SupportsExtension(ext) {
-			return p
-		}
+// parserFor returns the parser for the file at path, or nil, and the
+// extension to parse the file as: an Extensions mapping first, then the first
+// parser that supports the file's extension or, for a parser.FilenameParser,
+// its base name (parsed as its own extension either way). builtin reports
+// that the parser is not a plugin.
+func (g *Generator) parserFor(path string) (p parser.Parser, as string, builtin bool) {
+	ext := filepath.Ext(path)
+	if m, ok := g.extMap[ext]; ok {
+		return m.p, m.as, !m.plugin
 	}
-	return nil
+	p, i := g.parsers.Lookup(path)
+	return p, ext, p != nil && i >= g.plugins
 }
 
 // defaultMaxParseBytes is the per-file size limit for source parsing. Files
@@ -458,53 +1557,264 @@ func (g *Generator) parserFor(ext string) parser.Parser {
 // in NewGenerator; tests lower the per-Generator field, never a shared global.
 const defaultMaxParseBytes int64 = 10 * 1024 * 1024
 
-// parseFile parses a single file and returns its IR.
-func (g *Generator) parseFile(path string) (FileIR, error) {
+// defaultMaxLineBytes caps the longest line of a file the parsers see. A line
+// past it is minified or generated — a bundle, a source map, inlined data —
+// and is where the line-oriented extractors spend their time: each regex is
+// linear, but a dozen passes over a multi-megabyte line add up on every edit.
+// Such a file is indexed by hash alone and flagged (see FileIR.ParseSkipped).
+// The cut is by length rather than by a parse timeout so the outcome depends
+// on the file alone, never on how loaded the machine was.
+const defaultMaxLineBytes = 64 * 1024
+
+// parseFile parses a single file, whose IR key is key, and returns its IR.
+func (g *Generator) parseFile(path, key string) (FileIR, error) {
+	size, content, hash, err := g.readFile(path)
+	if err != nil {
+		return FileIR{}, err
+	}
+	if g.tooLarge(size) {
+		return g.hashOnly(path, hash, size, func() (io.ReadCloser, error) { return os.Open(path) })
+	}
+	return g.parseContent(path, key, content, hash)
+}
+
+// readFile is parseFile's reading, under IOConcurrency: the file's size, and
+// its content and hash, or for a file tooLarge keeps from the parsers its
+// hash alone.
+func (g *Generator) readFile(path string) (size int64, content []byte, hash string, err error) {
+	defer acquire(g.ioSlots)()
 	info, err := os.Stat(path)
 	if err != nil {
-		return FileIR{}, fmt.Errorf("failed to stat file: %w", err)
+		return 0, nil, "", fmt.Errorf("failed to stat file: %w", err)
+	}
+	if g.tooLarge(info.Size()) {
+		// Stream the hash: the file may be past what parsing would read.
+		hash, err := HashFile(path)
+		if err != nil {
+			return 0, nil, "", fmt.Errorf("failed to hash file: %w", err)
+		}
+		g.hashed(info.Size())
+		return info.Size(), nil, hash, nil
 	}
 	if info.Size() > g.maxParseBytes {
-		return FileIR{}, fmt.Errorf("skipping oversized file (%d bytes)", info.Size())
+		return 0, nil, "", fmt.Errorf("skipping oversized file (%d bytes)", info.Size())
 	}
 
 	// Read file
-	content, err := os.ReadFile(path)
+	content, err = os.ReadFile(path)
 	if err != nil {
-		return FileIR{}, fmt.Errorf("failed to read file: %w", err)
+		return 0, nil, "", fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Hash the bytes already in memory — re-reading via HashFile would both
 	// waste a syscall and race file modification between read and hash.
-	hash := HashBytes(content)
+	g.hashed(int64(len(content)))
+	return int64(len(content)), content, HashBytes(content), nil
+}
+
+// tooLarge reports whether a file of size bytes is over
+// GeneratorConfig.MaxFileSize.
+func (g *Generator) tooLarge(size int64) bool {
+	return g.maxFileSize > 0 && size > g.maxFileSize
+}
+
+// hashOnly is the entry of the file at path, hashed to hash, that tooLarge
+// keeps from the parsers. Its NormalizedHash, when wanted, is streamed from
+// what open returns.
+func (g *Generator) hashOnly(path, hash string, size int64, open func() (io.ReadCloser, error)) (FileIR, error) {
+	g.warnf(WarnHashOnly, path, "%s is %d bytes, over the %d-byte limit; indexing its hash only", path, size, g.maxFileSize)
+	f := FileIR{Hash: hash, ParseSkipped: ParseSkippedTooLarge}
+	if g.rootHashAlg == RootHashV1LF {
+		release := acquire(g.ioSlots)
+		h, err := hashFileNormalized(open)
+		release()
+		if err != nil {
+			return FileIR{}, err
+		}
+		f.NormalizedHash = h
+	}
+	return f, nil
+}
+
+// rootHash is files' root hash under g's algorithm. Every entry g writes has
+// the hash it needs.
+func (g *Generator) rootHash(files map[string]FileIR) string {
+	h, _ := ComputeRootHashAlg(g.rootHashAlg, files)
+	return h
+}
 
-	// Dispatch to the right parser by extension
+// parseContent is parseFile once path's content is in memory and hashed.
+func (g *Generator) parseContent(path, key string, content []byte, hash string) (FileIR, error) {
+	defer acquire(g.parseSlots)()
+	// Dispatch to the right parser by extension or file name
 	ext := filepath.Ext(path)
-	p := g.parserFor(ext)
+	p, as, builtin := g.parserFor(path)
 	if p == nil {
-		return FileIR{}, fmt.Errorf("no parser for extension %s", ext)
+		return FileIR{}, fmt.Errorf("no parser for %s", filepath.Base(path))
+	}
+	// Parsers read UTF-8; a BOM says the file is that after the mark, or
+	// UTF-16.
+	base := FileIR{Hash: hash}
+	if text, enc := decodeText(content); enc != "" {
+		content = text
+		base.Encoding, base.TextHash = enc, HashBytes(text)
+	}
+	if g.rootHashAlg == RootHashV1LF {
+		base.NormalizedHash = normalizedHash(content)
+	}
+	if looksBinary(content) {
+		g.warnf(WarnHashOnly, path, "%s is not text; indexing its hash only", path)
+		base.ParseSkipped = ParseSkippedBinary
+		return base, nil
+	}
+	if n := longestLine(content); n > g.maxLineBytes {
+		g.warnf(WarnHashOnly, path, "%s has a %d-byte line; indexing its hash only", path, n)
+		base.ParseSkipped = ParseSkippedLongLine
+		return base, nil
+	}
+	// A name-aware parser's output depends on the file's name too.
+	var name string
+	if _, ok := p.(parser.NameAwareParser); ok {
+		name = filepath.Base(path)
+	}
+	var objKey string
+	if g.objects != nil && builtin {
+		objKey = g.parseKey(p, as, name, hash)
+		if f, ok := g.objects.lookup(objKey, hash); ok {
+			f.NormalizedHash = base.NormalizedHash
+			if isJSExt(as) {
+				f.Symbols = namedDefaults(f.Symbols, key)
+			}
+			return f, nil
+		}
 	}
+	// A file mapped to a built-in language is read as that language throughout,
+	// including the guard's extractors, which pick a language from the path.
+	langPath := strings.TrimSuffix(path, ext) + as
 
 	// Parse structure. Convert to string once and share with extractRefs below —
 	// a 10 MiB file would otherwise hold three live copies of the source.
 	src := string(content)
-	// Pass the extension to parsers that need it to pick a grammar (JS/TS);
-	// others use the plain Parse method.
-	var structure parser.FileStructure
-	if ep, ok := p.(parser.ExtAwareParser); ok {
-		structure, err = ep.ParseExt(src, ext)
+	// Pass the file name to parsers whose output depends on it (Vue, Svelte) and the
+	// extension to parsers that need it to pick a grammar (JS/TS); others use
+	// the plain Parse method.
+	var (
+		structure parser.FileStructure
+		err       error
+	)
+	if np, ok := p.(parser.NameAwareParser); ok {
+		structure, err = np.ParseNamed(src, name)
+	} else if ep, ok := p.(parser.ExtAwareParser); ok {
+		structure, err = ep.ParseExt(src, as)
 	} else {
 		structure, err = p.Parse(src)
 	}
 	if err != nil {
 		return FileIR{}, fmt.Errorf("failed to parse file: %w", err)
 	}
+	if g.tally != nil {
+		g.tally.parsed = true
+	}
+	// The file is indexed, but with the symbols the parser gave up on missing.
+	for _, d := range structure.Diagnostics {
+		g.warnf(WarnParse, path, "%s: %s", path, d)
+	}
+	normalizeDetails(&structure)
+
+	f := FileIR{
+		Hash:     hash,
+		Encoding: base.Encoding,
+		TextHash: base.TextHash,
+		Symbols:  symbolsFromStructure(structure, langPath, src),
+		Refs:     extractRefs(langPath, src),
+
+		Enums:           structure.Enums,
+		Namespaces:      structure.Namespaces,
+		Variables:       structure.Variables,
+		ImportDetails:   structure.ImportDetails,
+		TypeImports:     structure.TypeImports,
+		ClassDetails:    structure.ClassDetails,
+		FunctionDetails: structure.FunctionDetails,
+		Decorators:      structure.Decorators,
+		ReExports:       structure.ReExports,
+		Docs:            structure.Docs,
+		Manifest:        structure.Manifest,
+	}
+	// A parse with diagnostics is not stored: a lookup would index the file
+	// again without its warnings.
+	if objKey != "" && len(structure.Diagnostics) == 0 {
+		g.objects.remember(objKey, f, g.warn)
+	}
+	// Set after the store has its copy, which is shared with generators
+	// that do not record it, and is the same wherever the file lives.
+	f.NormalizedHash = base.NormalizedHash
+	if isJSExt(as) {
+		f.Symbols = namedDefaults(f.Symbols, key)
+	}
+	return f, nil
+}
+
+// countParseSkipped counts the entries of files indexed by hash only.
+func countParseSkipped(files map[string]FileIR) int {
+	n := 0
+	for _, f := range files {
+		if f.ParseSkipped != "" {
+			n++
+		}
+	}
+	return n
+}
 
-	return FileIR{
-		Hash:    hash,
-		Symbols: symbolsFromStructure(structure, path, src),
-		Refs:    extractRefs(path, src),
-	}, nil
+// decodeText returns content as the UTF-8 text a byte-order mark says it
+// holds, and the FileIR.Encoding that names the decoding, or content itself
+// and "" when it has no mark. Unmarked UTF-16 is left alone: its NUL bytes
+// make it binary (see looksBinary), which is the better guess without one.
+func decodeText(content []byte) ([]byte, string) {
+	var (
+		order unicode.Endianness
+		enc   string
+	)
+	switch {
+	case bytes.HasPrefix(content, []byte{0xEF, 0xBB, 0xBF}):
+		return content[3:], EncodingUTF8BOM
+	case bytes.HasPrefix(content, []byte{0xFF, 0xFE}):
+		order, enc = unicode.LittleEndian, EncodingUTF16LE
+	case bytes.HasPrefix(content, []byte{0xFE, 0xFF}):
+		order, enc = unicode.BigEndian, EncodingUTF16BE
+	default:
+		return content, ""
+	}
+	// Unpaired surrogates and an odd last byte decode to U+FFFD, never an
+	// error.
+	text, err := unicode.UTF16(order, unicode.IgnoreBOM).NewDecoder().Bytes(content[2:])
+	if err != nil {
+		return content, ""
+	}
+	return text, enc
+}
+
+// binarySniffBytes is how much of a file looksBinary reads: git's figure.
+const binarySniffBytes = 8000
+
+// looksBinary reports whether content is binary rather than text, by git's
+// test: a NUL byte in its first 8000 bytes. Source text never has one; a
+// concatenated blob, an image under a source name, or UTF-16 text does.
+func looksBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binarySniffBytes)], 0) >= 0
+}
+
+// longestLine returns the length in bytes of data's longest line.
+func longestLine(data []byte) int {
+	longest := 0
+	for len(data) > 0 {
+		n := bytes.IndexByte(data, '\n')
+		if n < 0 {
+			n = len(data)
+		}
+		longest = max(longest, n)
+		data = data[min(n+1, len(data)):]
+	}
+	return longest
 }
 
 // symbolsFromStructure folds the parser's parallel arrays and "kind:name"-keyed
@@ -522,7 +1832,10 @@ func symbolsFromStructure(s parser.FileStructure, path, src string) []Symbol {
 	add := func(names []string, kind string) {
 		for _, n := range names {
 			key := kind + ":" + n
-			syms = append(syms, Symbol{Name: n, Kind: kind, Line: s.SymbolLines[key], Hash: s.SymbolHashes[key]})
+			// Names are NFC, like paths: the same identifier typed on macOS and on
+			// Linux can arrive precomposed or decomposed, and would otherwise
+			// sort, hash, and resolve as two different symbols.
+			syms = append(syms, Symbol{Name: norm.NFC.String(n), Kind: kind, Line: s.SymbolLines[key], Col: s.SymbolColumns[key], Hash: s.SymbolHashes[key]})
 		}
 	}
 	add(s.Functions, "function")
@@ -531,14 +1844,84 @@ func symbolsFromStructure(s parser.FileStructure, path, src string) []Symbol {
 	add(s.Imports, "import")
 	add(importedNames(path, src), "import_name")
 	// Module specifiers behind a bare `export * from './mod'` re-export
-	// (JS/TS). The names that re-export actually binds aren't enumerable from
+	// (JS/TS; Dart's `export 'uri';`; Protobuf's `import public`). The names that re-export actually binds aren't enumerable from
 	// this file alone (see FileStructure.WildcardReexports) — recording the
 	// specifier under its own kind keeps the fact visible (`runecho-ir map`/
 	// `locate`) instead of the prior silent drop, without fabricating export
 	// names this file doesn't itself define.
 	add(s.WildcardReexports, "export_wildcard")
 	sortSymbols(syms)
-	return syms
+	return dedupeSymbols(syms)
+}
+
+// normalizeDetails NFC-normalizes the symbol names in s's per-symbol details
+// — enums, class and function details, decorators, and docs — as
+// symbolsFromStructure does the symbols', so each detail still names a
+// symbol. Where two spellings normalize to one, the first detail in the
+// parser's order survives, as when the parser itself sees a name twice.
+func normalizeDetails(s *parser.FileStructure) {
+	s.Enums = nfcDetails(s.Enums, func(e *parser.Enum) *string { return &e.Name })
+	s.Namespaces = nfcDetails(s.Namespaces, func(n *parser.Namespace) *string { return &n.Name })
+	s.Variables = nfcDetails(s.Variables, func(v *parser.Variable) *string { return &v.Name })
+	s.ImportDetails = nfcDetails(s.ImportDetails, func(d *parser.ImportDetail) *string { return &d.Module })
+	s.TypeImports = nfcDetails(s.TypeImports, func(m *string) *string { return m })
+	s.ClassDetails = nfcDetails(s.ClassDetails, func(d *parser.ClassDetail) *string { return &d.Name })
+	s.FunctionDetails = nfcDetails(s.FunctionDetails, func(d *parser.FunctionDetail) *string { return &d.Name })
+	for i := range s.Decorators {
+		s.Decorators[i].Name = norm.NFC.String(s.Decorators[i].Name)
+	}
+	sort.SliceStable(s.Decorators, func(i, j int) bool {
+		a, b := s.Decorators[i], s.Decorators[j]
+		return a.Kind < b.Kind || a.Kind == b.Kind && a.Name < b.Name
+	})
+	s.Decorators = slices.CompactFunc(s.Decorators, func(a, b parser.Decorated) bool { return a.Kind == b.Kind && a.Name == b.Name })
+	if s.Docs != nil {
+		docs := make(map[string]string, len(s.Docs))
+		for _, k := range slices.Sorted(maps.Keys(s.Docs)) {
+			if nk := norm.NFC.String(k); docs[nk] == "" {
+				docs[nk] = s.Docs[k]
+			}
+		}
+		s.Docs = docs
+	}
+}
+
+// nfcDetails NFC-normalizes the name of each of items, which are sorted by
+// name, then restores that order and keeps the first of each name.
+func nfcDetails[T any](items []T, name func(*T) *string) []T {
+	for i := range items {
+		*name(&items[i]) = norm.NFC.String(*name(&items[i]))
+	}
+	sort.SliceStable(items, func(i, j int) bool { return *name(&items[i]) < *name(&items[j]) })
+	return slices.CompactFunc(items, func(a, b T) bool { return *name(&a) == *name(&b) })
+}
+
+// dedupeSymbols collapses sorted symbols that share a kind and name — two
+// spellings that normalize to one, or a name imported twice. The survivor is
+// the one with the earliest known line, then the smaller hash, so it does not
+// depend on the order the parser listed them in.
+func dedupeSymbols(syms []Symbol) []Symbol {
+	out := syms[:0]
+	for _, s := range syms {
+		if n := len(out); n > 0 && out[n-1].Kind == s.Kind && out[n-1].Name == s.Name {
+			if betterSymbol(s, out[n-1]) {
+				out[n-1] = s
+			}
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func betterSymbol(a, b Symbol) bool {
+	switch {
+	case (a.Line == 0) != (b.Line == 0):
+		return b.Line == 0
+	case a.Line != b.Line:
+		return a.Line < b.Line
+	}
+	return a.Hash < b.Hash
 }
 
 // importedNames returns the locally-bound names this file's import statements
@@ -550,14 +1933,15 @@ func importedNames(path, src string) []string {
 	return guard.ExtractImports(lang, guard.TextToAddedLines(src))
 }
 
-// extractRefs returns the sorted, deduplicated bare call targets in content,
-// using the guard's extractor as the single source of truth (see FileIR.Refs).
-// Always non-nil so the JSON form is a stable [] rather than null.
+// extractRefs returns the sorted, deduplicated, NFC-normalized bare call
+// targets in content, using the guard's extractor as the single source of
+// truth (see FileIR.Refs). Always non-nil so the JSON form is a stable []
+// rather than null.
 func extractRefs(path, content string) []string {
 	lang := guard.LangFor(path)
 	set := make(map[string]struct{})
 	for _, ref := range guard.ExtractRefs(lang, guard.TextToAddedLines(content)) {
-		set[ref.Name] = struct{}{}
+		set[norm.NFC.String(ref.Name)] = struct{}{}
 	}
 	refs := make([]string, 0, len(set))
 	for name := range set {
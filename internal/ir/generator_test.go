@@ -2,15 +2,24 @@ package ir
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
+
+	"github.com/inth3shadows/runecho/internal/parser"
 )
 
 // A cancelled context aborts the walk cleanly: GenerateCtx returns an error that
@@ -80,12 +89,58 @@ func TestGenerator_ConfigTimeout(t *testing.T) {
 // skip branches (walk-access error, rel-path failure, parse/hash failures).
 func captureWarnings(g *Generator) *[]string {
 	var lines []string
-	g.warn = func(format string, args ...any) {
-		lines = append(lines, fmt.Sprintf(format, args...))
+	g.warn = func(w Warning) {
+		lines = append(lines, w.String()+"\n")
 	}
 	return &lines
 }
 
+// untimed is s without its wall times, which no two runs share.
+func untimed(s Stats) Stats {
+	s.Time = PhaseTimes{}
+	return s
+}
+
+// TestGenerate_Stats: each walked file is counted once under the reason it
+// was or was not indexed, and only the files read this run count as hashed
+// or parsed.
+func TestGenerate_Stats(t *testing.T) {
+	root := t.TempDir()
+	big := strings.Repeat("x = 1\n", 20)
+	writeTree(t, root, map[string]string{
+		".gitignore": "gen.go\n",
+		"a.go":       "package a\n",
+		"b/b.go":     "package b\n",
+		"gen.go":     "package gen\n",
+		"notes.xyz":  "notes\n",
+		"big.py":     big,
+	})
+	gen := NewGenerator(GeneratorConfig{MaxFileSize: 50})
+	captureWarnings(gen)
+	result, stats, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Stats{SupportedSeen: 3, Indexed: 3, ParseSkipped: 1, Walked: 6, Unsupported: 2, Ignored: 1, Parsed: 2, BytesHashed: 20 + int64(len(big))}
+	if untimed(stats) != want {
+		t.Errorf("Generate stats = %+v, want %+v", stats, want)
+	}
+	if stats.Time.Files <= 0 || stats.Time.Total < stats.Time.Walk+stats.Time.Files {
+		t.Errorf("Generate times = %+v", stats.Time)
+	}
+
+	writeTree(t, root, map[string]string{"a.go": "package a2\n"})
+	_, stats, err = gen.Update(result, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a.go is hashed to see it changed, then again as it is parsed.
+	want.Parsed, want.BytesHashed = 1, 11+10+int64(len(big))+11
+	if untimed(stats) != want {
+		t.Errorf("Update stats = %+v, want %+v", stats, want)
+	}
+}
+
 // The walk-error callback (a directory that errors during Walk) must route a
 // warning through the injected sink and let the walk continue, not abort. On
 // Linux an unreadable dir (chmod 000) makes filepath.Walk hand the callback a
@@ -134,6 +189,14 @@ func TestGenerate_WalkErrorWarns(t *testing.T) {
 	}) {
 		t.Errorf("expected a 'failed to access' warning, got %v", *warnings)
 	}
+	// The unreadable subtree is on record, and does not move the root hash.
+	want := []Omission{{Path: "denied", Reason: OmissionUnreadable}}
+	if !slices.Equal(result.Omissions, want) {
+		t.Errorf("Omissions = %v, want %v", result.Omissions, want)
+	}
+	if result.RootHash != ComputeRootHash(result.Files) {
+		t.Error("omissions leaked into the root hash")
+	}
 }
 
 // The parse-failure branch must route its warning through the injected sink.
@@ -308,7 +371,7 @@ func TestGenerator_Generate_OnlySupportedExtensions(t *testing.T) {
 		"test.gs":  "function gs() {}",
 		"test.py":  "def python(): pass",
 		"test.txt": "not code",
-		"test.md":  "# Markdown",
+		"test.csv": "a,b",
 	}
 
 	for name, content := range testFiles {
@@ -326,7 +389,7 @@ func TestGenerator_Generate_OnlySupportedExtensions(t *testing.T) {
 		t.Fatalf("Generate failed: %v", err)
 	}
 
-	// Should only have 4 files (.js, .ts, .gs, .py) — not .txt or .md
+	// Should only have 4 files (.js, .ts, .gs, .py) — not .txt or .csv
 	if len(ir.Files) != 4 {
 		t.Errorf("Expected 4 files, got %d", len(ir.Files))
 		for path := range ir.Files {
@@ -582,6 +645,573 @@ func TestGenerate_WildcardReexportSymbol(t *testing.T) {
 	}
 }
 
+// stubParser is a GeneratorConfig.Parsers plugin for tests: it claims exts and
+// reports every source as a single function named fn.
+type stubParser struct {
+	exts []string
+	fn   string
+}
+
+func (p stubParser) Parse(string) (parser.FileStructure, error) {
+	return parser.FileStructure{Functions: []string{p.fn}}, nil
+}
+
+func (p stubParser) SupportsExtension(ext string) bool { return slices.Contains(p.exts, ext) }
+
+// TestGenerate_ConfigParsersTakePrecedence: a configured parser both claims an
+// extension no built-in handles and overrides a built-in one, while files it
+// does not claim keep their built-in parser.
+func TestGenerate_ConfigParsersTakePrecedence(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, src := range map[string]string{
+		"a.ex":    "defmodule A do end\n",
+		"b.sh":    "real() { :; }\n",
+		"c.py":    "def kept():\n    pass\n",
+		"skip.zz": "ignored\n",
+	} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gen := NewGenerator(GeneratorConfig{Parsers: []parser.Parser{stubParser{exts: []string{".ex", ".sh"}, fn: "fromPlugin"}}})
+	result, _, err := gen.Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	for path, want := range map[string]string{"a.ex": "fromPlugin", "b.sh": "fromPlugin", "c.py": "kept"} {
+		if got := result.Files[path].namesOf("function"); !slices.Equal(got, []string{want}) {
+			t.Errorf("%s functions = %v, want [%s]", path, got, want)
+		}
+	}
+	if _, ok := result.Files["skip.zz"]; ok {
+		t.Error("unclaimed extension was indexed")
+	}
+}
+
+// namedStub is a stubParser with a name, so Extensions can map to it.
+type namedStub struct {
+	stubParser
+	name string
+}
+
+func (p namedStub) Name() string { return p.name }
+
+// TestGenerate_ExtensionMapping: a mapped extension is parsed by the named
+// built-in language, with that language's grammar (TS-only syntax in .mts), or
+// by a named plugin; an unknown name is dropped with a warning.
+func TestGenerate_ExtensionMapping(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, src := range map[string]string{
+		"a.mts":    "export function typed(x: number): string { return helper(x) }\ninterface Shape { w: number }\n",
+		"b.gsx":    "function appsScript() {}\n",
+		"c.pyi":    "def stub(): ...\n",
+		"d.nope":   "ignored\n",
+		"plain.js": "function plain() {}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gen := NewGenerator(GeneratorConfig{
+		Parsers: []parser.Parser{namedStub{stubParser{exts: []string{".ex"}, fn: "fromPlugin"}, "stubby"}},
+		Extensions: map[string]string{
+			".mts":  "typescript",
+			".gsx":  "javascript",
+			".pyi":  "stubby",
+			".nope": "cobol",
+		},
+	})
+	result, _, err := gen.Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	for path, want := range map[string]string{"a.mts": "typed", "b.gsx": "appsScript", "c.pyi": "fromPlugin", "plain.js": "plain"} {
+		if got := result.Files[path].namesOf("function"); !slices.Equal(got, []string{want}) {
+			t.Errorf("%s functions = %v, want [%s]", path, got, want)
+		}
+	}
+	if got := result.Files["a.mts"].Refs; !slices.Contains(got, "helper") {
+		t.Errorf("a.mts refs = %v: mapped files should get JS ref extraction", got)
+	}
+	if _, ok := result.Files["d.nope"]; ok {
+		t.Error("extension mapped to an unknown parser was indexed")
+	}
+	var warned string
+	resolveExtensions(map[string]string{".nope": "cobol"}, nil, func(w Warning) { warned = w.String() })
+	if !strings.Contains(warned, `"cobol"`) {
+		t.Errorf("unknown parser name: warning %q should name it", warned)
+	}
+}
+
+// TestGenerate_ExtensionAliases: a mapping may name a built-in language by
+// its short alias, which reads the file as that language, grammar included;
+// a name that is neither a language nor an alias is a WarnConfig.
+func TestGenerate_ExtensionAliases(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		"a.gs":  "function appsScript() {}\n",
+		"b.mtx": "export function typed(x: number): string { return String(x) }\n",
+		"c.pyi": "def stub(): ...\n",
+		"d.es":  "function rejected() {}\n",
+	})
+	gen := NewGenerator(GeneratorConfig{Extensions: map[string]string{
+		".gs":  "js",
+		".mtx": "ts",
+		".pyi": "py",
+		".es":  "ecmascript",
+	}})
+	result, _, err := gen.Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	for path, want := range map[string]string{"a.gs": "appsScript", "b.mtx": "typed", "c.pyi": "stub"} {
+		if got := result.Files[path].namesOf("function"); !slices.Equal(got, []string{want}) {
+			t.Errorf("%s functions = %v, want [%s]", path, got, want)
+		}
+	}
+	if _, ok := result.Files["d.es"]; ok {
+		t.Error("extension mapped to an unknown name was indexed")
+	}
+	var warned []Warning
+	resolveExtensions(map[string]string{".es": "ecmascript"}, nil, func(w Warning) { warned = append(warned, w) })
+	if len(warned) != 1 || warned[0].Kind != WarnConfig || !strings.Contains(warned[0].Message, `"ecmascript"`) {
+		t.Errorf("unknown name: warnings %+v, want one WarnConfig naming it", warned)
+	}
+}
+
+// TestGenerate_ParserBackend: the tree-sitter backend indexes JS/TS, mapped
+// extensions included, from the AST alone, so a require spelled inside a
+// template literal is not an import; an unknown backend warns and falls back.
+func TestGenerate_ParserBackend(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		"app.js":  "const fs = require('fs');\nconst doc = `require('fake')`;\nfunction main() {}\n",
+		"lib.mts": "export function typed(x: number): string { return String(x) }\n",
+	})
+	imports := func(config GeneratorConfig) []string {
+		result, _, err := NewGenerator(config).Generate(tmpDir)
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		if got := result.Files["lib.mts"].namesOf("function"); !slices.Equal(got, []string{"typed"}) {
+			t.Errorf("lib.mts functions = %v, want [typed]", got)
+		}
+		return result.Files["app.js"].namesOf("import")
+	}
+	exts := map[string]string{".mts": "typescript"}
+	if got := imports(GeneratorConfig{Extensions: exts}); !slices.Equal(got, []string{"fake", "fs"}) {
+		t.Errorf("default backend imports = %v, want [fake fs]", got)
+	}
+	if got := imports(GeneratorConfig{Extensions: exts, ParserBackend: ParserBackendTreeSitter}); !slices.Equal(got, []string{"fs"}) {
+		t.Errorf("tree-sitter backend imports = %v, want [fs]", got)
+	}
+
+	var warned string
+	if _, ok := jsBackend("acorn", func(w Warning) { warned = w.String() }).(*parser.JSParser); !ok {
+		t.Error("an unknown backend should fall back to the default parser")
+	}
+	if !strings.Contains(warned, `"acorn"`) {
+		t.Errorf("unknown backend: warning %q should name it", warned)
+	}
+}
+
+// TestGenerate_FilenameMatch: a parser.FilenameParser claims files by name, so
+// an extensionless Dockerfile is indexed on a full walk and a per-file update
+// alike, while other extensionless files still are not.
+func TestGenerate_FilenameMatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		"Dockerfile":            "FROM golang:1.25 AS build\nRUN go build\n",
+		"deploy/Dockerfile.dev": "FROM build AS dev\n",
+		"LICENSE":               "MIT\n",
+	})
+	gen := NewGenerator(GeneratorConfig{})
+	result, _, err := gen.Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if got := result.Files["Dockerfile"].namesOf("export"); !slices.Equal(got, []string{"build"}) {
+		t.Errorf("Dockerfile exports = %v, want [build]", got)
+	}
+	if got := result.Files["deploy/Dockerfile.dev"].namesOf("export"); !slices.Equal(got, []string{"dev"}) {
+		t.Errorf("Dockerfile.dev exports = %v, want [dev]", got)
+	}
+	if _, ok := result.Files["LICENSE"]; ok {
+		t.Error("an extensionless file no parser claims was indexed")
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "Dockerfile"), []byte("FROM golang:1.25 AS compile\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := gen.UpdateSingleFile(result, tmpDir, "Dockerfile"); err != nil || !changed {
+		t.Fatalf("UpdateSingleFile = %v, %v; want the Dockerfile re-parsed", changed, err)
+	}
+	if got := result.Files["Dockerfile"].namesOf("export"); !slices.Equal(got, []string{"compile"}) {
+		t.Errorf("updated Dockerfile exports = %v, want [compile]", got)
+	}
+}
+
+// TestGenerate_Manifests: package manifests are claimed by name, carry their
+// Manifest through a save and load, and a version bump re-hashes only the
+// bumped dependency's import.
+func TestGenerate_Manifests(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		"go.mod":               "module example.com/app\n\ngo 1.25.0\n\nrequire golang.org/x/text v0.21.0\n",
+		"web/package.json":     `{"name": "web", "dependencies": {"react": "^18.2.0", "zod": "^3.22.0"}}`,
+		"api/requirements.txt": "flask==3.0.0\n",
+		"main.go":              "package main\n\nfunc main() {}\n",
+		"web/tsconfig.json":    "{}\n",
+	})
+	gen := NewGenerator(GeneratorConfig{})
+	result, _, err := gen.Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	manifests := result.Manifests()
+	if len(manifests) != 3 {
+		t.Fatalf("Manifests() = %+v, want go.mod, web/package.json, api/requirements.txt", manifests)
+	}
+	if m := manifests["web/package.json"]; m.Ecosystem != "npm" || m.Name != "web" || len(m.Dependencies) != 2 {
+		t.Errorf("package.json manifest = %+v", m)
+	}
+	if _, ok := result.Files["web/tsconfig.json"]; ok {
+		t.Error("a .json file that is not a manifest was indexed")
+	}
+
+	irPath := filepath.Join(t.TempDir(), "ir.json")
+	if err := result.Save(irPath); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(irPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Manifests(), manifests) {
+		t.Errorf("Manifests after a save and load:\n got %+v\nwant %+v", loaded.Manifests(), manifests)
+	}
+
+	before := result.Files["web/package.json"].Symbols
+	if err := os.WriteFile(filepath.Join(tmpDir, "web/package.json"), []byte(`{"name": "web", "dependencies": {"react": "^19.0.0", "zod": "^3.22.0"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := gen.UpdateSingleFile(result, tmpDir, "web/package.json"); err != nil || !changed {
+		t.Fatalf("UpdateSingleFile = %v, %v; want the manifest re-parsed", changed, err)
+	}
+	after := result.Files["web/package.json"].Symbols
+	for i := range before {
+		if bumped := before[i].Hash != after[i].Hash; bumped != (before[i].Name == "react") {
+			t.Errorf("%s:%s hash changed = %v after bumping react", before[i].Kind, before[i].Name, bumped)
+		}
+	}
+	if got := result.Manifests()["web/package.json"].Dependencies[0].Version; got != "^19.0.0" {
+		t.Errorf("react version = %q after the bump, want ^19.0.0", got)
+	}
+}
+
+// TestGenerate_EnumsClassesDecorators: a TS file's enums, namespaces,
+// variables, import details, type imports, class details, and decorators reach its FileIR and survive a save and load; other files carry
+// none.
+func TestGenerate_EnumsClassesDecorators(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		"mode.ts": "import { Base } from './base';\nimport type { Options } from './options';\nexport const enum Mode { Fast, Safe }\nexport enum Level { Low }\n@Injectable()\nexport class Switch extends Base { static of() {} flip() {} }\ndeclare module \"vendor\" {}\nexport const LIMIT = 10;\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	})
+	result, _, err := NewGenerator(GeneratorConfig{}).Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	want := []parser.Enum{{Name: "Level"}, {Name: "Mode", Const: true}}
+	if got := result.Files["mode.ts"].Enums; !reflect.DeepEqual(got, want) {
+		t.Errorf("mode.ts enums = %+v, want %+v", got, want)
+	}
+	if got := result.Files["main.go"].Enums; got != nil {
+		t.Errorf("main.go enums = %+v, want none", got)
+	}
+	irPath := filepath.Join(t.TempDir(), "ir.json")
+	if err := result.Save(irPath); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(irPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Files["mode.ts"].Enums; !reflect.DeepEqual(got, want) {
+		t.Errorf("enums after a save and load = %+v, want %+v", got, want)
+	}
+	namespaces := []parser.Namespace{{Name: "vendor", Ambient: true}}
+	if got := loaded.Files["mode.ts"].Namespaces; !reflect.DeepEqual(got, namespaces) {
+		t.Errorf("namespaces after a save and load = %+v, want %+v", got, namespaces)
+	}
+	variables := []parser.Variable{{Name: "LIMIT", Const: true}}
+	if got := loaded.Files["mode.ts"].Variables; !reflect.DeepEqual(got, variables) {
+		t.Errorf("variables after a save and load = %+v, want %+v", got, variables)
+	}
+	imported := []parser.ImportDetail{{Module: "./base", Named: []string{"Base"}}, {Module: "./options", Named: []string{"Options"}}}
+	if got := loaded.Files["mode.ts"].ImportDetails; !reflect.DeepEqual(got, imported) {
+		t.Errorf("import details after a save and load = %+v, want %+v", got, imported)
+	}
+	if got := loaded.Files["mode.ts"].TypeImports; !reflect.DeepEqual(got, []string{"./options"}) {
+		t.Errorf("type imports after a save and load = %q, want [./options]", got)
+	}
+	details := []parser.ClassDetail{{Name: "Switch", Extends: "Base", Methods: []string{"flip"}, Static: []string{"of"}}}
+	if got := loaded.Files["mode.ts"].ClassDetails; !reflect.DeepEqual(got, details) {
+		t.Errorf("class details after a save and load = %+v, want %+v", got, details)
+	}
+	decorated := []parser.Decorated{{Kind: "class", Name: "Switch", Decorators: []string{"Injectable"}}}
+	if got := loaded.Files["mode.ts"].Decorators; !reflect.DeepEqual(got, decorated) {
+		t.Errorf("decorators after a save and load = %+v, want %+v", got, decorated)
+	}
+}
+
+// TestGenerate_NameAwareParser: a Vue component is named after its file, so
+// two components with the same content must not share a cached parse.
+func TestGenerate_NameAwareParser(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := "<template><p/></template>\n<script setup>\nfunction go() {}\n</script>\n"
+	writeTree(t, tmpDir, map[string]string{"UserCard.vue": src, "TeamCard.vue": src})
+	gen := NewGenerator(GeneratorConfig{Objects: NewObjectStore(t.TempDir())})
+	result, _, err := gen.Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	for path, want := range map[string]string{"UserCard.vue": "UserCard", "TeamCard.vue": "TeamCard"} {
+		if got := result.Files[path].namesOf("class"); !slices.Equal(got, []string{want}) {
+			t.Errorf("%s classes = %v, want [%s]", path, got, want)
+		}
+	}
+}
+
+// TestGenerate_WorkersMatchSerial: parsing on a worker pool changes nothing
+// but speed — the IR, the Stats, and the warnings, in order, are those of a
+// serial walk, for Generate, Update, and a capped Generate alike.
+func TestGenerate_WorkersMatchSerial(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"big.go":      "package main\n\n// " + strings.Repeat("x", 400) + "\n",
+		"minified.js": "var a=" + strings.Repeat("1+", 40) + "1;\n",
+	}
+	for i := range 15 {
+		files[fmt.Sprintf("pkg%d/file%d.go", i%5, i)] = fmt.Sprintf("package pkg\n\nfunc F%d() {}\n", i)
+		files[fmt.Sprintf("web/mod%d.js", i)] = fmt.Sprintf("import { F } from './mod%d';\nexport class C%d {}\n", i+1, i)
+		files[fmt.Sprintf("py/m%d.py", i)] = fmt.Sprintf("def f%d():\n    pass\n", i)
+	}
+	writeTree(t, tmpDir, files)
+	run := func(workers, fileCap int, update bool) (*IR, Stats, []string) {
+		t.Helper()
+		gen := NewGenerator(GeneratorConfig{Workers: workers, FileCap: fileCap})
+		gen.maxParseBytes = 256
+		gen.maxLineBytes = 64
+		warnings := captureWarnings(gen)
+		result, stats, err := gen.Generate(tmpDir)
+		if err == nil && update {
+			stale := *result
+			stale.Files = maps.Clone(result.Files)
+			delete(stale.Files, "pkg0/file0.go")
+			f := stale.Files["web/mod1.js"]
+			f.Hash = "stale"
+			stale.Files["web/mod1.js"] = f
+			*warnings = nil
+			result, stats, err = gen.Update(&stale, tmpDir)
+		}
+		if err != nil {
+			t.Fatalf("workers=%d cap=%d update=%v: %v", workers, fileCap, update, err)
+		}
+		return result, stats, *warnings
+	}
+	for _, tc := range []struct {
+		fileCap int
+		update  bool
+	}{{0, false}, {0, true}, {20, false}} {
+		wantIR, wantStats, wantWarnings := run(1, tc.fileCap, tc.update)
+		if len(wantWarnings) == 0 {
+			t.Fatalf("cap=%d update=%v: a serial walk raised no warning", tc.fileCap, tc.update)
+		}
+		for _, workers := range []int{2, 8} {
+			got, stats, warnings := run(workers, tc.fileCap, tc.update)
+			if !reflect.DeepEqual(got, wantIR) {
+				t.Errorf("workers=%d cap=%d update=%v: IR differs from a serial walk's", workers, tc.fileCap, tc.update)
+			}
+			if untimed(stats) != untimed(wantStats) {
+				t.Errorf("workers=%d cap=%d update=%v: stats = %+v, want %+v", workers, tc.fileCap, tc.update, stats, wantStats)
+			}
+			if !slices.Equal(warnings, wantWarnings) {
+				t.Errorf("workers=%d cap=%d update=%v: warnings = %q, want %q", workers, tc.fileCap, tc.update, warnings, wantWarnings)
+			}
+		}
+	}
+}
+
+// slowFS is an fs.FS whose files take a moment to open, and which records
+// the most it ever had open at once.
+type slowFS struct {
+	fs.FS
+	mu         sync.Mutex
+	open, most int
+}
+
+func (s *slowFS) Open(name string) (fs.File, error) {
+	f, err := s.FS.Open(name)
+	if err != nil || name == "." || !strings.Contains(path.Base(name), ".") {
+		return f, err // a directory
+	}
+	s.mu.Lock()
+	s.open++
+	s.most = max(s.most, s.open)
+	s.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	return &slowFile{File: f, fs: s}, nil
+}
+
+type slowFile struct {
+	fs.File
+	fs *slowFS
+}
+
+func (f *slowFile) Close() error {
+	f.fs.mu.Lock()
+	f.fs.open--
+	f.fs.mu.Unlock()
+	return f.File.Close()
+}
+
+// TestGenerate_IOConcurrency: however many workers there are, no more than
+// IOConcurrency of them read at once, and the IR is a serial walk's.
+func TestGenerate_IOConcurrency(t *testing.T) {
+	tree := fstest.MapFS{}
+	for i := range 40 {
+		tree[fmt.Sprintf("pkg%d/f%d.go", i%4, i)] = &fstest.MapFile{Data: []byte(fmt.Sprintf("package p\n\nfunc F%d() {}\n", i))}
+	}
+	want, _, err := NewGenerator(GeneratorConfig{}).GenerateFS(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow := &slowFS{FS: tree}
+	gen := NewGenerator(GeneratorConfig{IOConcurrency: 2, ParseConcurrency: 6})
+	if gen.workers != 8 {
+		t.Errorf("workers = %d, want IOConcurrency+ParseConcurrency", gen.workers)
+	}
+	got, _, err := gen.GenerateFS(slow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("IR differs from a serial walk's")
+	}
+	if slow.most > 2 {
+		t.Errorf("%d files open at once, want at most 2", slow.most)
+	}
+}
+
+// TestGenerate_OnProgress: the hook sees every supported file once, in walk
+// order, counting up to a total fixed up front — files past FileCap included,
+// and the same with a worker pool as without.
+func TestGenerate_OnProgress(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		"a.go":        "package a\n",
+		"b/c.py":      "def c():\n    pass\n",
+		"b/d.js":      "export const d = 1;\n",
+		"e.go":        "package a\n",
+		"notes.txt":   "not source\n",
+		"vendor/v.go": "package v\n",
+	})
+	want := []string{"1/4 a.go", "2/4 b/c.py", "3/4 b/d.js", "4/4 e.go"}
+	for _, cfg := range []GeneratorConfig{{}, {Workers: 3}, {FileCap: 2}} {
+		var got []string
+		cfg.OnProgress = func(done, total int, path string) {
+			got = append(got, fmt.Sprintf("%d/%d %s", done, total, path))
+		}
+		gen := NewGenerator(cfg)
+		base, _, err := gen.Generate(tmpDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("workers=%d cap=%d: Generate progress = %q, want %q", cfg.Workers, cfg.FileCap, got, want)
+		}
+		got = nil
+		if _, _, err := gen.Update(base, tmpDir); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("workers=%d cap=%d: Update progress = %q, want %q", cfg.Workers, cfg.FileCap, got, want)
+		}
+	}
+}
+
+// TestUpdateChanges: an Update that adds, edits, deletes, and renames files
+// reports each, sorted, and the rename as a removal plus an addition; a
+// no-op Update reports nothing.
+func TestUpdateChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		"a.go":       "package a\n",
+		"b.go":       "package a\n\nfunc B() {}\n",
+		"gone.py":    "x = 1\n",
+		"old/mv.js":  "export const m = 1;\n",
+		"same/s.go":  "package same\n",
+		"notes.txt":  "not source\n",
+		"web/app.ts": "export const a = 1;\n",
+	})
+	gen := NewGenerator(GeneratorConfig{})
+	base, _, err := gen.Generate(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, c, err := gen.UpdateChanges(context.Background(), base, tmpDir); err != nil || !c.Empty() {
+		t.Fatalf("no-op UpdateChanges = %+v, %v; want no changes", c, err)
+	}
+	writeTree(t, tmpDir, map[string]string{
+		"b.go":       "package a\n\nfunc B2() {}\n",
+		"new/n.go":   "package n\n",
+		"new/mv.js":  "export const m = 1;\n",
+		"web/app.ts": "export const a = 2;\n",
+		"notes.txt":  "still not source\n",
+	})
+	for _, name := range []string{"gone.py", "old/mv.js"} {
+		if err := os.Remove(filepath.Join(tmpDir, filepath.FromSlash(name))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updated, _, c, err := gen.UpdateChanges(context.Background(), base, tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ChangeSet{
+		Added:    []string{"new/mv.js", "new/n.go"},
+		Modified: []string{"b.go", "web/app.ts"},
+		Removed:  []string{"gone.py", "old/mv.js"},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("changes = %+v,\nwant %+v", c, want)
+	}
+	if !reflect.DeepEqual(DiffFiles(base, updated), c) {
+		t.Error("DiffFiles disagrees with UpdateChanges")
+	}
+	if got := DiffFiles(nil, base); len(got.Added) != len(base.Files) || got.Modified != nil || got.Removed != nil {
+		t.Errorf("DiffFiles(nil, base) = %+v, want every file added", got)
+	}
+}
+
+// A cancelled walk on a worker pool returns the ctx error and no IR, and
+// leaves no worker behind.
+func TestGenerate_WorkersCancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := make(map[string]string)
+	for i := range 20 {
+		files[fmt.Sprintf("f%d.go", i)] = "package p\n"
+	}
+	writeTree(t, tmpDir, files)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, _, err := NewGenerator(GeneratorConfig{Workers: 4}).GenerateCtx(ctx, tmpDir)
+	if !errors.Is(err, context.Canceled) || result != nil {
+		t.Fatalf("GenerateCtx on a cancelled ctx = %v, %v; want nil, context.Canceled", result, err)
+	}
+}
+
 // TestUpdate_VersionMismatchRegenerates: Update must fall back to a full
 // Generate for an old-format IR — reusing v1 entries verbatim would leave
 // their Refs empty forever.
@@ -734,6 +1364,156 @@ func TestGenerator_Generate_UnicodeNormalization(t *testing.T) {
 	}
 }
 
+// TestGenerate_SymbolNameNormalization: identifiers are NFC like paths, so a
+// decomposed spelling indexes as the precomposed one, and a file that uses both
+// spellings declares the symbol once.
+func TestGenerate_SymbolNameNormalization(t *testing.T) {
+	tmpDir := t.TempDir()
+	nfd, nfc := "cafe\u0301", "caf\u00e9"
+	src := "export function " + nfd + "() {}\nexport function " + nfc + "() {}\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "menu.js"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	irData, _, err := NewGenerator(GeneratorConfig{}).Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	f := irData.Files["menu.js"]
+	if got := f.namesOf("function"); !slices.Equal(got, []string{nfc}) {
+		t.Errorf("functions = %q, want [%q]", got, nfc)
+	}
+	for _, s := range f.Symbols {
+		if s.Name == nfd {
+			t.Errorf("symbol %s kept its decomposed spelling", s.Kind)
+		}
+		if s.Kind == "function" && s.Line != 1 {
+			t.Errorf("merged function line = %d, want the earliest (1)", s.Line)
+		}
+	}
+}
+
+// TestGenerate_PathCollision: two files whose names normalize to one key must
+// not race for it. The NFC spelling wins whatever the walk order, the other is
+// named in a warning and counted, and the per-edit refresh of the loser leaves
+// the winner's entry alone.
+func TestGenerate_PathCollision(t *testing.T) {
+	tmpDir := t.TempDir()
+	nfc, nfd := "caf\u00e9.ts", "cafe\u0301.ts"
+	if err := os.WriteFile(filepath.Join(tmpDir, nfc), []byte("export function composed() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, nfd), []byte("export function decomposed() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 2 {
+		t.Skip("filesystem folds NFC and NFD names into one file")
+	}
+
+	gen := NewGenerator(GeneratorConfig{})
+	warnings := captureWarnings(gen)
+	result, stats, err := gen.Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(result.Files) != 1 {
+		t.Fatalf("Files = %d, want 1", len(result.Files))
+	}
+	if got := result.Files[nfc].namesOf("export"); !slices.Equal(got, []string{"composed"}) {
+		t.Errorf("exports = %v, want the NFC-named file's [composed]", got)
+	}
+	if stats.PathCollisions != 1 || stats.SupportedSeen != 1 {
+		t.Errorf("stats = %+v, want 1 collision and 1 supported file", stats)
+	}
+	if !slices.ContainsFunc(*warnings, func(s string) bool { return strings.Contains(s, `\u0301`) }) {
+		t.Errorf("expected a warning naming the skipped spelling, got %v", *warnings)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, nfd), []byte("export function edited() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, changed, _ := gen.UpdateFile(result, tmpDir, filepath.Join(tmpDir, nfd)); changed {
+		t.Error("UpdateFile on the losing spelling overwrote the winner's entry")
+	}
+	if err := os.Remove(filepath.Join(tmpDir, nfd)); err != nil {
+		t.Fatal(err)
+	}
+	if _, changed, _ := gen.UpdateFile(result, tmpDir, filepath.Join(tmpDir, nfd)); changed {
+		t.Error("UpdateFile on the deleted losing spelling dropped the winner's entry")
+	}
+}
+
+// TestGenerate_CaseCollision: paths that differ only in case, as files or as
+// directories, are all indexed, each spelling after the first named once in
+// a warning; StrictCase makes the first an error. The tree is an fs.FS, so
+// the test runs the same on a case-insensitive disk.
+func TestGenerate_CaseCollision(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for _, name := range []string{"Lib/a.go", "lib/b.go", "lib/c.go", "other.go", "src/Utils.ts", "src/utils.ts", "src/Été.ts", "src/éTÉ.ts"} {
+		fsys[name] = &fstest.MapFile{Data: []byte("package p\n")}
+	}
+	gen := NewGenerator(GeneratorConfig{})
+	warnings := captureWarnings(gen)
+	result, stats, err := gen.GenerateFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`Warning: "Lib" and "lib" differ only in case; a case-insensitive checkout cannot hold both` + "\n",
+		`Warning: "src/Utils.ts" and "src/utils.ts" differ only in case; a case-insensitive checkout cannot hold both` + "\n",
+		`Warning: "src/Été.ts" and "src/éTÉ.ts" differ only in case; a case-insensitive checkout cannot hold both` + "\n",
+	}
+	if !reflect.DeepEqual(*warnings, want) {
+		t.Errorf("warnings %q,\nwant %q", *warnings, want)
+	}
+	if len(result.Files) != len(fsys) || stats.CaseCollisions != 3 {
+		t.Errorf("indexed %d of %d, stats %+v", len(result.Files), len(fsys), stats)
+	}
+
+	_, _, err = NewGenerator(GeneratorConfig{StrictCase: true}).GenerateFS(fsys)
+	if !errors.Is(err, ErrCaseCollision) || !strings.Contains(err.Error(), `"Lib" and "lib"`) {
+		t.Errorf("StrictCase: err = %v", err)
+	}
+}
+
+// TestGenerate_MaxDepth: the walk enters no directory more than MaxDepth
+// below the root, lists each it cuts off as an omission, and UpdateFile
+// refuses what lies past it; GenerateFS stops at the same place.
+func TestGenerate_MaxDepth(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.go":         "package a\n",
+		"x/b.go":       "package x\n",
+		"x/y/c.go":     "package y\n",
+		"x/y/z/d.go":   "package z\n",
+		"x/y/z/q/e.go": "package q\n",
+		"x/w/f.go":     "package w\n",
+		"x/y/v/g.go":   "package v\n",
+	})
+	gen := NewGenerator(GeneratorConfig{MaxDepth: 2})
+	result, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.go", "x/b.go", "x/w/f.go", "x/y/c.go"}; !reflect.DeepEqual(indexedPaths(result), want) {
+		t.Errorf("indexed %v, want %v", indexedPaths(result), want)
+	}
+	want := []Omission{{Path: "x/y/v", Reason: OmissionTooDeep}, {Path: "x/y/z", Reason: OmissionTooDeep}}
+	if !slices.Equal(result.Omissions, want) {
+		t.Errorf("Omissions = %v, want %v", result.Omissions, want)
+	}
+	if _, changed, _ := gen.UpdateFile(result, root, filepath.Join(root, "x/y/z/d.go")); changed {
+		t.Error("UpdateFile indexed a file past MaxDepth")
+	}
+
+	fromFS, _, err := gen.GenerateFS(os.DirFS(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromFS, result) {
+		t.Errorf("GenerateFS = %+v, want %+v", fromFS, result)
+	}
+}
+
 // TestGenerate_OversizedFileSkipped verifies that a file exceeding maxParseBytes
 // is silently skipped rather than causing Generate to fail.
 func TestGenerate_OversizedFileSkipped(t *testing.T) {
@@ -761,6 +1541,406 @@ func TestGenerate_OversizedFileSkipped(t *testing.T) {
 	}
 }
 
+// TestGenerate_LongLineParseSkipped verifies that a file with a line past
+// maxLineBytes is indexed by hash alone, flagged, and counted — and that a
+// short-lined file beside it is parsed as usual.
+func TestGenerate_LongLineParseSkipped(t *testing.T) {
+	tmpDir := t.TempDir()
+	minified := "export function a(){return b()}" + strings.Repeat(";", 64) + "\nfunction c() {}\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "bundle.js"), []byte(minified), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.js"), []byte("function main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := NewGenerator(GeneratorConfig{})
+	gen.maxLineBytes = 32
+	warnings := captureWarnings(gen)
+	result, stats, err := gen.Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	got := result.Files["bundle.js"]
+	want := FileIR{Hash: HashBytes([]byte(minified)), ParseSkipped: ParseSkippedLongLine}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bundle.js = %+v, want %+v", got, want)
+	}
+	if len(result.Files["main.js"].Symbols) == 0 {
+		t.Error("main.js should still be parsed")
+	}
+	if stats.ParseSkipped != 1 || stats.Indexed != 2 || stats.ParseErrors != 0 {
+		t.Errorf("stats = %+v, want ParseSkipped=1 Indexed=2 ParseErrors=0", stats)
+	}
+	if !slices.ContainsFunc(*warnings, func(s string) bool { return strings.Contains(s, "hash only") }) {
+		t.Errorf("expected a hash-only note, got %v", *warnings)
+	}
+	if err := result.VerifyRootHash(); err != nil {
+		t.Errorf("root hash: %v", err)
+	}
+
+	// The flag survives the round trip and an Update reusing the entry.
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded IR
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Files["bundle.js"].ParseSkipped != ParseSkippedLongLine {
+		t.Errorf("parse_skipped lost in JSON round trip: %s", data)
+	}
+	updated, stats, err := gen.Update(&loaded, tmpDir)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.RootHash != result.RootHash || stats.ParseSkipped != 1 {
+		t.Errorf("Update: root hash %s (want %s), ParseSkipped=%d", updated.RootHash, result.RootHash, stats.ParseSkipped)
+	}
+}
+
+// TestGenerate_BinaryFiles: a file with a NUL byte early on is indexed by
+// hash only and flagged — a blob under a .js name, UTF-16 text — while a NUL
+// past the sniffed prefix leaves the file to the parsers.
+func TestGenerate_BinaryFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	blob := "function a() {}\n\x00\x01\x02\xff"
+	utf16 := "d\x00e\x00f\x00 \x00f\x00(\x00)\x00:\x00\n\x00"
+	late := "function late() {}\n" + strings.Repeat("// pad\n", binarySniffBytes/7+1) + "\x00"
+	writeTree(t, tmpDir, map[string]string{
+		"blob.js":  blob,
+		"wide.py":  utf16,
+		"late.js":  late,
+		"plain.go": "package p\n\nfunc P() {}\n",
+	})
+	gen := NewGenerator(GeneratorConfig{})
+	warnings := captureWarnings(gen)
+	result, stats, err := gen.Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for name, content := range map[string]string{"blob.js": blob, "wide.py": utf16} {
+		want := FileIR{Hash: HashBytes([]byte(content)), ParseSkipped: ParseSkippedBinary}
+		if got := result.Files[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %+v, want %+v", name, got, want)
+		}
+	}
+	for _, name := range []string{"late.js", "plain.go"} {
+		if f := result.Files[name]; f.ParseSkipped != "" || len(f.Symbols) == 0 {
+			t.Errorf("%s = %+v, want it parsed", name, f)
+		}
+	}
+	if stats.ParseSkipped != 2 || stats.Indexed != 4 {
+		t.Errorf("stats = %+v, want ParseSkipped=2 Indexed=4", stats)
+	}
+	if !slices.ContainsFunc(*warnings, func(s string) bool { return strings.Contains(s, "blob.js is not text") }) {
+		t.Errorf("expected a hash-only note, got %v", *warnings)
+	}
+}
+
+// TestGenerate_EncodedFiles: a file saved with a UTF-8 BOM, or as UTF-16
+// either way round, parses to what its plain UTF-8 twin does. Its Hash is
+// still over its bytes; its TextHash is the twin's Hash.
+func TestGenerate_EncodedFiles(t *testing.T) {
+	const text = "def greet(name):\n    return 'héllo ' + name\n"
+	utf16 := func(bigEndian bool) string {
+		out := []byte{0xFF, 0xFE}
+		if bigEndian {
+			out = []byte{0xFE, 0xFF}
+		}
+		for _, r := range text {
+			hi, lo := byte(r>>8), byte(r)
+			if bigEndian {
+				out = append(out, hi, lo)
+			} else {
+				out = append(out, lo, hi)
+			}
+		}
+		return string(out)
+	}
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{
+		"plain.py": text,
+		"bom.py":   "\xEF\xBB\xBF" + text,
+		"le.py":    utf16(false),
+		"be.py":    utf16(true),
+	})
+	result, stats, err := NewGenerator(GeneratorConfig{}).Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	plain := result.Files["plain.py"]
+	if plain.Encoding != "" || plain.TextHash != "" || len(plain.Symbols) == 0 {
+		t.Fatalf("plain.py = %+v", plain)
+	}
+	for name, enc := range map[string]string{"bom.py": EncodingUTF8BOM, "le.py": EncodingUTF16LE, "be.py": EncodingUTF16BE} {
+		f := result.Files[name]
+		if f.Encoding != enc || f.TextHash != plain.Hash || f.Hash == plain.Hash {
+			t.Errorf("%s: encoding %q text_hash %.8s hash %.8s; want %q, %.8s, and its own hash", name, f.Encoding, f.TextHash, f.Hash, enc, plain.Hash)
+		}
+		if !reflect.DeepEqual(f.Symbols, plain.Symbols) || !reflect.DeepEqual(f.Refs, plain.Refs) {
+			t.Errorf("%s symbols = %+v, want plain.py's %+v", name, f.Symbols, plain.Symbols)
+		}
+	}
+	if stats.ParseSkipped != 0 {
+		t.Errorf("stats = %+v, want nothing skipped", stats)
+	}
+}
+
+// TestGenerate_NormalizedRootHash: with NormalizedRootHash a tree checked out
+// with CRLF line endings has the root hash of its LF twin, a hash-only file's
+// included, while file hashes stay byte-level; without it the root hashes
+// differ. Update regenerates an IR hashed the other way, and UpdateSingleFile
+// keeps the root hash in step.
+func TestGenerate_NormalizedRootHash(t *testing.T) {
+	lf := map[string]string{
+		"main.go":   "package main\n\nfunc main() {}\n",
+		"bundle.js": "function a() {}\n" + strings.Repeat("var x = 1;\n", 20),
+	}
+	crlf := make(map[string]string)
+	for name, body := range lf {
+		crlf[name] = strings.ReplaceAll(body, "\n", "\r\n")
+	}
+	lfDir, crlfDir := t.TempDir(), t.TempDir()
+	writeTree(t, lfDir, lf)
+	writeTree(t, crlfDir, crlf)
+
+	gen := NewGenerator(GeneratorConfig{NormalizedRootHash: true, MaxFileSize: 128})
+	captureWarnings(gen)
+	a, _, err := gen.Generate(lfDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _, err := gen.Generate(crlfDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.RootHashAlg != RootHashV1LF || a.RootHash != b.RootHash {
+		t.Errorf("root hashes %s (%s) and %s, want one %s hash", a.RootHash, a.RootHashAlg, b.RootHash, RootHashV1LF)
+	}
+	if b.Files["bundle.js"].ParseSkipped != ParseSkippedTooLarge {
+		t.Errorf("bundle.js = %+v, want it hash-only", b.Files["bundle.js"])
+	}
+	for name := range lf {
+		if a.Files[name].Hash == b.Files[name].Hash || a.Files[name].NormalizedHash != a.Files[name].Hash {
+			t.Errorf("%s: hashes %.8s/%.8s normalized %.8s/%.8s", name, a.Files[name].Hash, b.Files[name].Hash, a.Files[name].NormalizedHash, b.Files[name].NormalizedHash)
+		}
+	}
+	if err := b.VerifyRootHash(); err != nil {
+		t.Error(err)
+	}
+
+	plain := NewGenerator(GeneratorConfig{MaxFileSize: 128})
+	captureWarnings(plain)
+	c, _, _ := plain.Generate(lfDir)
+	d, _, _ := plain.Generate(crlfDir)
+	if c.RootHash == d.RootHash || c.RootHashAlg != RootHashV1 || c.Files["main.go"].NormalizedHash != "" {
+		t.Errorf("without NormalizedRootHash: %s and %s (%s)", c.RootHash, d.RootHash, c.RootHashAlg)
+	}
+	up, _, err := gen.Update(d, crlfDir)
+	if err != nil || up.RootHashAlg != RootHashV1LF || up.RootHash != b.RootHash {
+		t.Errorf("Update of a v1 IR: %v, %+v", err, up)
+	}
+	if _, err := plain.UpdateSingleFile(b, crlfDir, "main.go"); err == nil {
+		t.Error("UpdateSingleFile accepted an IR hashed with another algorithm")
+	}
+
+	writeTree(t, crlfDir, map[string]string{"main.go": "package main\r\n\r\nfunc main() { main() }\r\n"})
+	if changed, err := gen.UpdateSingleFile(b, crlfDir, "main.go"); err != nil || !changed {
+		t.Fatalf("UpdateSingleFile: changed=%v err=%v", changed, err)
+	}
+	if err := b.VerifyRootHash(); err != nil {
+		t.Error(err)
+	}
+	writeTree(t, lfDir, map[string]string{"main.go": "package main\n\nfunc main() { main() }\n"})
+	if a, _, _ = gen.Generate(lfDir); a.RootHash != b.RootHash {
+		t.Errorf("after the same edit: %s and %s", a.RootHash, b.RootHash)
+	}
+}
+
+// TestGenerate_FollowSymlinks: with FollowSymlinks a symlinked directory
+// outside the root and a symlinked file are indexed under the links' paths,
+// links back into the directories above them are cycles, and a dangling link
+// is an omission; UpdateFile and StatTree agree with the walk. Without it
+// every link is skipped.
+func TestGenerate_FollowSymlinks(t *testing.T) {
+	tmp := t.TempDir()
+	root, shared := filepath.Join(tmp, "repo"), filepath.Join(tmp, "shared")
+	writeTree(t, root, map[string]string{"apps/web/main.go": "package main\n\nfunc main() {}\n"})
+	writeTree(t, shared, map[string]string{"lib.go": "package shared\n\nfunc Lib() {}\n"})
+	links := map[string]string{
+		filepath.Join(root, "apps", "web", "shared"): shared,
+		filepath.Join(root, "link.go"):               filepath.Join(root, "apps", "web", "main.go"),
+		filepath.Join(root, "dangling.go"):           filepath.Join(root, "missing.go"),
+		filepath.Join(shared, "loop"):                shared,
+		filepath.Join(shared, "apps"):                filepath.Join(root, "apps"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks unavailable on this platform: %v", err)
+		}
+	}
+
+	gen := NewGenerator(GeneratorConfig{FollowSymlinks: true})
+	warnings := captureWarnings(gen)
+	base, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"apps/web/main.go", "apps/web/shared/lib.go", "link.go"}
+	if got := indexedPaths(base); !reflect.DeepEqual(got, want) {
+		t.Errorf("indexed %v, want %v", got, want)
+	}
+	if base.Files["link.go"].Hash != base.Files["apps/web/main.go"].Hash {
+		t.Error("link.go was not indexed as the file it points to")
+	}
+	if len(base.Omissions) != 1 || base.Omissions[0].Path != "dangling.go" {
+		t.Errorf("omissions = %+v, want dangling.go", base.Omissions)
+	}
+	if n := strings.Count(strings.Join(*warnings, ""), "leads back"); n != 2 {
+		t.Errorf("warned of %d cycles, want 2: %q", n, *warnings)
+	}
+
+	writeTree(t, shared, map[string]string{"lib.go": "package shared\n\nfunc Lib2() {}\n"})
+	updated, changed, err := gen.UpdateFile(base, root, filepath.Join(root, "apps", "web", "shared", "lib.go"))
+	if err != nil || !changed {
+		t.Fatalf("UpdateFile through the link: changed=%v err=%v", changed, err)
+	}
+	if full, _, _ := gen.Generate(root); updated.RootHash != full.RootHash {
+		t.Errorf("UpdateFile root hash %s, a full walk %s", updated.RootHash, full.RootHash)
+	}
+	if _, changed, _ := gen.UpdateFile(updated, root, filepath.Join(root, "apps", "web", "shared", "loop", "lib.go")); changed {
+		t.Error("UpdateFile indexed a path through a cycle")
+	}
+	tree, err := gen.StatTree(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(filepath.Join(shared, "lib.go")); tree["apps/web/shared/lib.go"].Size != info.Size() {
+		t.Errorf("StatTree = %+v, want the linked file's own size", tree)
+	}
+
+	plain, _, err := NewGenerator(GeneratorConfig{}).Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := indexedPaths(plain); !reflect.DeepEqual(got, []string{"apps/web/main.go"}) {
+		t.Errorf("without FollowSymlinks indexed %v", got)
+	}
+}
+
+// TestGenerate_MaxFileSize: a file over MaxFileSize is indexed by hash alone
+// and flagged, even past the parse limit, by Generate, Update, UpdateFile, and
+// UpdateSingleFile alike; a file within it is parsed.
+func TestGenerate_MaxFileSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	bundle := "function a() {}\n" + strings.Repeat("var x = 1;\n", 20)
+	writeTree(t, tmpDir, map[string]string{"bundle.js": bundle, "main.js": "function main() {}\n"})
+
+	gen := NewGenerator(GeneratorConfig{MaxFileSize: 64})
+	gen.maxParseBytes = 128 // bundle.js is past this too: MaxFileSize still indexes it
+	warnings := captureWarnings(gen)
+	result, stats, err := gen.Generate(tmpDir)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	want := FileIR{Hash: HashBytes([]byte(bundle)), ParseSkipped: ParseSkippedTooLarge}
+	if got := result.Files["bundle.js"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("bundle.js = %+v, want %+v", got, want)
+	}
+	if len(result.Files["main.js"].Symbols) == 0 {
+		t.Error("main.js should still be parsed")
+	}
+	if stats.ParseSkipped != 1 || stats.Indexed != 2 || stats.ParseErrors != 0 {
+		t.Errorf("stats = %+v, want ParseSkipped=1 Indexed=2 ParseErrors=0", stats)
+	}
+	if !slices.ContainsFunc(*warnings, func(s string) bool { return strings.Contains(s, "bundle.js is 236 bytes") }) {
+		t.Errorf("expected a hash-only note, got %v", *warnings)
+	}
+
+	bundle += "var y = 2;\n"
+	writeTree(t, tmpDir, map[string]string{"bundle.js": bundle})
+	want.Hash = HashBytes([]byte(bundle))
+	updated, stats, err := gen.Update(result, tmpDir)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if got := updated.Files["bundle.js"]; !reflect.DeepEqual(got, want) || stats.ParseErrors != 0 {
+		t.Errorf("Update: bundle.js = %+v, ParseErrors = %d; want %+v, 0", got, stats.ParseErrors, want)
+	}
+	bundle += "var z = 3;\n"
+	writeTree(t, tmpDir, map[string]string{"bundle.js": bundle})
+	want.Hash = HashBytes([]byte(bundle))
+	refreshed, changed, _ := gen.UpdateFile(updated, tmpDir, filepath.Join(tmpDir, "bundle.js"))
+	if got := refreshed.Files["bundle.js"]; !changed || !reflect.DeepEqual(got, want) {
+		t.Errorf("UpdateFile: bundle.js = %+v, changed = %v; want %+v", got, changed, want)
+	}
+	bundle += "var w = 4;\n"
+	writeTree(t, tmpDir, map[string]string{"bundle.js": bundle})
+	want.Hash = HashBytes([]byte(bundle))
+	if changed, err := gen.UpdateSingleFile(refreshed, tmpDir, "bundle.js"); err != nil || !changed || !reflect.DeepEqual(refreshed.Files["bundle.js"], want) {
+		t.Errorf("UpdateSingleFile: bundle.js = %+v, changed = %v, err = %v; want %+v", refreshed.Files["bundle.js"], changed, err, want)
+	}
+}
+
+func TestLongestLine(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"\n\n", 0},
+		{"abc", 3},
+		{"ab\nabcd\n", 4},
+		{"abcd\r\nab", 5},
+	} {
+		if got := longestLine([]byte(tc.in)); got != tc.want {
+			t.Errorf("longestLine(%q) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
+
+// TestTreeStamp verifies the stamp moves with a walked file and holds still
+// for files a walk would never see.
+func TestTreeStamp(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.go", "package a\n")
+	write("node_modules/x.js", "x()\n")
+	gen := NewGenerator(GeneratorConfig{IgnoredPaths: DefaultIgnoredPaths})
+	stamp := func() string {
+		t.Helper()
+		s, err := gen.TreeStamp(context.Background(), root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	first := stamp()
+	if stamp() != first {
+		t.Fatal("TreeStamp is not stable on an idle tree")
+	}
+	write("node_modules/y.js", "y()\n")
+	write("notes.txt", "unsupported\n")
+	if stamp() != first {
+		t.Error("ignored and unsupported files moved the stamp")
+	}
+	write("b.go", "package a\n")
+	if stamp() == first {
+		t.Error("a new source file did not move the stamp")
+	}
+}
+
 // Helper functions
 
 func equalIR(a, b *IR) bool {
@@ -900,3 +2080,58 @@ func TestGenerate_ParseErrorCount(t *testing.T) {
 		t.Error("big.go should be excluded (parse error)")
 	}
 }
+
+// TestGenerate_DefaultExportName: an anonymous JS/TS default function or
+// class is also recorded under the name its file suggests — the file's name,
+// or an index file's directory's — with the same line and hash, unless that
+// is no identifier or the file already declares the name.
+func TestGenerate_DefaultExportName(t *testing.T) {
+	anonFunc := "export default function () { return 1 }\n"
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"src/UserCard.tsx":      anonFunc,
+		"src/Button/index.js":   "export default class { click() {} }\n",
+		"src/user-card.js":      anonFunc,
+		"src/Taken.js":          anonFunc + "function Taken() {}\n",
+		"src/Named.js":          "export default function Other() {}\n",
+		"index.js":              anonFunc,
+		"scripts/helper.py":     "def default():\n    pass\n",
+		"src/lib/Formatter.mjs": "export default () => 1\n",
+	})
+	result, _, err := NewGenerator(GeneratorConfig{}).Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string][]string{
+		"src/UserCard.tsx":      {"UserCard", "default"},
+		"src/user-card.js":      {"default"},
+		"src/Taken.js":          {"Taken", "default"},
+		"src/Named.js":          {"Other"},
+		"index.js":              {"default"},
+		"scripts/helper.py":     {"default"},
+		"src/lib/Formatter.mjs": {"Formatter", "default"},
+	} {
+		if got := result.Files[path].namesOf("function"); !slices.Equal(got, want) {
+			t.Errorf("%s functions = %v, want %v", path, got, want)
+		}
+	}
+	if got := result.Files["src/Button/index.js"].namesOf("class"); !slices.Equal(got, []string{"Button", "default"}) {
+		t.Errorf("index.js classes = %v, want [Button default]", got)
+	}
+	syms := result.Files["src/UserCard.tsx"].Symbols
+	var named, anon Symbol
+	for _, s := range syms {
+		switch {
+		case s.Kind == "function" && s.Name == "UserCard":
+			named = s
+		case s.Kind == "function" && s.Name == "default":
+			anon = s
+		}
+	}
+	if named.Line != anon.Line || named.Hash != anon.Hash || anon.Hash == "" {
+		t.Errorf("UserCard = %+v, want default's line and hash (%+v)", named, anon)
+	}
+	if exports := result.Files["src/UserCard.tsx"].namesOf("export"); !slices.Equal(exports, []string{"default"}) {
+		t.Errorf("exports = %v: the module still exports only default", exports)
+	}
+}
@@ -0,0 +1,194 @@
+package ir
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxGitignoreBytes caps the read of one .gitignore. A real one is a few
+// hundred lines; past this the file is read no further.
+const maxGitignoreBytes = 1 << 20
+
+// IgnoreFileName is the file, in .gitignore syntax, that excludes paths from
+// the IR alone: generated code checked in on purpose, fixtures, vendored
+// trees. It may sit in any directory, as a .gitignore may, and is read
+// whatever GeneratorConfig.NoGitignore says.
+const IgnoreFileName = ".runechoignore"
+
+// gitignore is one walk's view of the ignore files under its root — the
+// .gitignore files, unless GeneratorConfig.NoGitignore, and the
+// .runechoignore files — each read as the walk enters its directory. A nil
+// *gitignore ignores nothing. Only files inside the root count: not one above
+// it, .git/info/exclude, or the user's core.excludesFile, none of which
+// travel with a clone.
+type gitignore struct {
+	// names are the ignore files read in each directory. A directory's rules
+	// are theirs in this order, so a later file's pattern overrides an
+	// earlier file's as a later line overrides an earlier one.
+	names []string
+	rules map[string][]ignoreRule // directory key ("." for the root) → its rules
+}
+
+// ignoreRule is one .gitignore pattern.
+type ignoreRule struct {
+	// segs is the pattern split on "/", relative to the .gitignore's
+	// directory; a pattern without an inner slash gets a leading "**", since
+	// it matches at any depth.
+	segs    []string
+	negate  bool // "!pattern" re-includes
+	dirOnly bool // "pattern/" matches only directories
+}
+
+// gitignoreFor returns the gitignore for a new walk.
+func (g *Generator) gitignoreFor() *gitignore {
+	gi := &gitignore{rules: make(map[string][]ignoreRule)}
+	if g.gitignore {
+		gi.names = append(gi.names, ".gitignore")
+	}
+	gi.names = append(gi.names, IgnoreFileName)
+	return gi
+}
+
+// read loads the ignore files of the directory at absDir, whose key is dir.
+// The walk calls it before it looks at anything in the directory. A missing
+// or unreadable file, or one that is not a regular file, has no rules.
+func (gi *gitignore) read(dir, absDir string) {
+	if gi == nil {
+		return
+	}
+	for _, n := range gi.names {
+		name := filepath.Join(absDir, n)
+		if info, err := os.Lstat(name); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		gi.load(dir, data)
+	}
+}
+
+// readFS is read for the directory named dir in fsys, whose key is also dir.
+func (gi *gitignore) readFS(fsys fs.FS, dir string) {
+	if gi == nil {
+		return
+	}
+	for _, n := range gi.names {
+		name := path.Join(dir, n)
+		if info, err := fs.Lstat(fsys, name); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			continue
+		}
+		gi.load(normalizePath(dir), data)
+	}
+}
+
+// load adds the rules of one of the ignore files of the directory at key dir.
+func (gi *gitignore) load(dir string, data []byte) {
+	if len(data) > maxGitignoreBytes {
+		data = data[:maxGitignoreBytes]
+	}
+	gi.rules[dir] = append(gi.rules[dir], parseGitignore(data)...)
+}
+
+// ignored reports whether the file or directory at key is excluded. The
+// deepest directory with a matching pattern decides, and within it the last
+// match, as in git. The caller prunes excluded directories, so a path is never
+// asked about once its parent is out — which is also why, as in git, a file
+// cannot be re-included from inside an excluded directory.
+func (gi *gitignore) ignored(key string, dir bool) bool {
+	if gi == nil || key == "." {
+		return false
+	}
+	for d := path.Dir(key); ; d = path.Dir(d) {
+		rel := key
+		if d != "." {
+			rel = key[len(d)+1:]
+		}
+		rules := gi.rules[d]
+		for i := len(rules) - 1; i >= 0; i-- {
+			if r := rules[i]; (dir || !r.dirOnly) && matchSegs(r.segs, strings.Split(rel, "/")) {
+				return !r.negate
+			}
+		}
+		if d == "." {
+			return false
+		}
+	}
+}
+
+// parseGitignore reads the patterns of a .gitignore file: one per line, blank
+// lines and "#" comments skipped, trailing spaces dropped unless escaped with
+// a backslash, and "\#" or "\!" for a pattern that starts with the character.
+func parseGitignore(data []byte) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		p := strings.TrimSuffix(string(line), "\r")
+		for strings.HasSuffix(p, " ") && !strings.HasSuffix(p, `\ `) {
+			p = p[:len(p)-1]
+		}
+		if p == "" || p[0] == '#' {
+			continue
+		}
+		var r ignoreRule
+		if p[0] == '!' {
+			r.negate = true
+			p = p[1:]
+		} else if strings.HasPrefix(p, `\#`) || strings.HasPrefix(p, `\!`) {
+			p = p[1:]
+		}
+		if strings.HasSuffix(p, "/") {
+			r.dirOnly = true
+			p = strings.TrimRight(p, "/")
+		}
+		if p == "" {
+			continue
+		}
+		// A slash anywhere but the end anchors the pattern to the
+		// .gitignore's directory; otherwise it matches a name at any depth.
+		if strings.Contains(p, "/") {
+			p = strings.TrimPrefix(p, "/")
+		} else {
+			p = "**/" + p
+		}
+		for _, seg := range strings.Split(p, "/") {
+			// git writes a negated class [!a]; path.Match wants [^a].
+			r.segs = append(r.segs, strings.ReplaceAll(seg, "[!", "[^"))
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// matchSegs reports whether the pattern segments pat match the path segments
+// name in full. "**" matches any number of segments, except that a trailing
+// one needs at least one: "build/**" is what is inside build, not build.
+func matchSegs(pat, name []string) bool {
+	if len(pat) == 0 {
+		return len(name) == 0
+	}
+	if pat[0] == "**" {
+		if len(pat) == 1 {
+			return len(name) > 0
+		}
+		for i := range len(name) + 1 {
+			if matchSegs(pat[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, err := path.Match(pat[0], name[0])
+	return err == nil && ok && matchSegs(pat[1:], name[1:])
+}
@@ -0,0 +1,197 @@
+package ir
+
+import (
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestGitignore_Patterns: the pattern forms git documents — anchored and
+// floating names, directory-only patterns, "**", negation, classes, escapes —
+// each against a path it must and one it must not match.
+func TestGitignore_Patterns(t *testing.T) {
+	cases := []struct {
+		pattern string
+		key     string
+		dir     bool
+		want    bool
+	}{
+		{"*.log", "debug.log", false, true},
+		{"*.log", "a/b/debug.log", false, true},
+		{"*.log", "debug.log.go", false, false},
+		{"build/", "build", true, true},
+		{"build/", "src/build", true, true},
+		{"build/", "build", false, false}, // a file named build
+		{"/out", "out", true, true},
+		{"/out", "src/out", true, false},
+		{"docs/gen", "docs/gen", true, true},
+		{"docs/gen", "src/docs/gen", true, false},
+		{"**/fixtures", "a/b/fixtures", true, true},
+		{"lib/**", "lib/x/y.go", false, true},
+		{"lib/**", "lib", true, false},
+		{"a/**/z.go", "a/z.go", false, true},
+		{"a/**/z.go", "a/b/c/z.go", false, true},
+		{"a/**/z.go", "b/a/z.go", false, false},
+		{"file?.go", "file1.go", false, true},
+		{"file?.go", "file10.go", false, false},
+		{"[!a]x.go", "bx.go", false, true},
+		{"[!a]x.go", "ax.go", false, false},
+		{`\#note.go`, "#note.go", false, true},
+		{`\!bang.go`, "!bang.go", false, true},
+		{"trailing.go   ", "trailing.go", false, true},
+		{"# a comment", "# a comment", false, false},
+	}
+	for _, tc := range cases {
+		gi := &gitignore{rules: map[string][]ignoreRule{".": parseGitignore([]byte(tc.pattern))}}
+		if got := gi.ignored(tc.key, tc.dir); got != tc.want {
+			t.Errorf("%q ignores %q (dir=%v) = %v, want %v", tc.pattern, tc.key, tc.dir, got, tc.want)
+		}
+	}
+}
+
+// TestGitignore_Walk: the walk skips what the root and nested .gitignore
+// files exclude, a deeper file and a later line overriding earlier ones, and
+// NoGitignore or a PathInclude filter brings the paths back.
+func TestGitignore_Walk(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":                "# build output\nbuild/\ncoverage/\n*.gen.go\n!keep.gen.go\n/out.go\n",
+		"main.go":                   "package m\n",
+		"build/app.go":              "package build\n",
+		"coverage/report.js":        "var r = 1;\n",
+		"api.gen.go":                "package m\n",
+		"keep.gen.go":               "package m\n",
+		"out.go":                    "package m\n",
+		"web/.gitignore":            ".next/\n!*.gen.go\nlocal/*.ts\n",
+		"web/.next/chunk.js":        "var c = 1;\n",
+		"web/page.gen.go":           "package web\n",
+		"web/out.go":                "package web\n",
+		"web/local/dev.ts":          "export const dev = 1;\n",
+		"web/local/nested/deep.ts":  "export const deep = 1;\n",
+		"web/pkg/.gitignore":        "*.ts\n",
+		"web/pkg/index.ts":          "export const i = 1;\n",
+		"web/pkg/index.go":          "package pkg\n",
+		"sibling/page.gen.go":       "package sibling\n",
+		"sibling/local/kept.ts":     "export const s = 1;\n",
+		"sibling/pkg/not_caught.ts": "export const n = 1;\n",
+	})
+	irData, _, err := NewGenerator(GeneratorConfig{}).Generate(root)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	want := []string{
+		"keep.gen.go", "main.go",
+		"sibling/local/kept.ts", "sibling/pkg/not_caught.ts",
+		"web/local/nested/deep.ts", "web/out.go", "web/page.gen.go", "web/pkg/index.go",
+	}
+	if got := indexedPaths(irData); !reflect.DeepEqual(got, want) {
+		t.Errorf("indexed %v,\nwant %v", got, want)
+	}
+
+	all, _, err := NewGenerator(GeneratorConfig{NoGitignore: true}).Generate(root)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if got := len(all.Files); got != 16 {
+		t.Errorf("NoGitignore indexed %v, want all 16 source files", indexedPaths(all))
+	}
+
+	include, _, err := NewGenerator(GeneratorConfig{PathFilters: []PathFilter{
+		func(path string, _ fs.DirEntry) Decision {
+			if path == "build" {
+				return PathInclude
+			}
+			return PathDefault
+		},
+	}}).Generate(root)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if _, ok := include.Files["build/app.go"]; !ok {
+		t.Errorf("PathInclude on build did not override .gitignore: indexed %v", indexedPaths(include))
+	}
+}
+
+// TestGitignore_UpdateFileMatchesWalk: the per-edit refresh refuses a file a
+// .gitignore excludes, directly or through its directory, as the walk does.
+func TestGitignore_UpdateFileMatchesWalk(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":     "dist-types/\n*.gen.go\n",
+		"main.go":        "package m\n",
+		"web/.gitignore": "/local.ts\n",
+	})
+	gen := NewGenerator(GeneratorConfig{})
+	base, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"api.gen.go", "dist-types/a.ts", "web/local.ts"} {
+		writeTree(t, root, map[string]string{name: "export const x = 1;\n"})
+		got, changed, err := gen.UpdateFile(base, root, filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil || changed {
+			t.Errorf("%s: UpdateFile changed=%v err=%v, want a no-op", name, changed, err)
+		}
+		if _, ok := got.Files[name]; ok {
+			t.Errorf("%s: indexed by UpdateFile", name)
+		}
+	}
+	writeTree(t, root, map[string]string{"web/app.ts": "export const a = 1;\n"})
+	if got, changed, _ := gen.UpdateFile(base, root, filepath.Join(root, "web", "app.ts")); !changed || got.Files["web/app.ts"].Hash == "" {
+		t.Errorf("UpdateFile did not index web/app.ts, which no .gitignore excludes")
+	}
+	full, _, _ := gen.Generate(root)
+	if want := []string{"main.go", "web/app.ts"}; !reflect.DeepEqual(indexedPaths(full), want) {
+		t.Errorf("full walk indexed %v, want %v", indexedPaths(full), want)
+	}
+}
+
+// TestRunechoIgnore: .runechoignore files nest as .gitignore files do, apply
+// without the .gitignore files, and come after them in their directory, so
+// one can re-include what git ignores.
+func TestRunechoIgnore(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":                    "*.gen.go\n",
+		".runechoignore":                "fixtures/\n!api.gen.go\n",
+		"main.go":                       "package m\n",
+		"api.gen.go":                    "package m\n",
+		"db.gen.go":                     "package m\n",
+		"fixtures/case.go":              "package fixtures\n",
+		"web/.runechoignore":            "/lib/\n*.stories.ts\n",
+		"web/app.ts":                    "export const a = 1;\n",
+		"web/button.stories.ts":         "export const b = 1;\n",
+		"web/lib/lib.js":                "var l = 1;\n",
+		"web/pkg/lib/kept.js":           "var k = 1;\n",
+		"other/button.stories.ts":       "export const o = 1;\n",
+		"web/pkg/.runechoignore":        "!button.stories.ts\n",
+		"web/pkg/button.stories.ts":     "export const p = 1;\n",
+		"web/pkg/sub/button.stories.ts": "export const s = 1;\n",
+	})
+	gen := NewGenerator(GeneratorConfig{})
+	irData, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"api.gen.go", "main.go", "other/button.stories.ts",
+		"web/app.ts", "web/pkg/button.stories.ts", "web/pkg/lib/kept.js", "web/pkg/sub/button.stories.ts",
+	}
+	if got := indexedPaths(irData); !reflect.DeepEqual(got, want) {
+		t.Errorf("indexed %v,\nwant %v", got, want)
+	}
+
+	noGit, _, err := NewGenerator(GeneratorConfig{NoGitignore: true}).Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := noGit.Files["fixtures/case.go"]; ok || noGit.Files["db.gen.go"].Hash == "" {
+		t.Errorf("NoGitignore indexed %v, want .runechoignore alone applied", indexedPaths(noGit))
+	}
+
+	writeTree(t, root, map[string]string{"fixtures/new.go": "package fixtures\n"})
+	if _, changed, _ := gen.UpdateFile(irData, root, filepath.Join(root, "fixtures", "new.go")); changed {
+		t.Error("UpdateFile indexed a file a .runechoignore excludes")
+	}
+}
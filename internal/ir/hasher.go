@@ -1,12 +1,16 @@
 package ir
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 )
 
 // HashFile computes SHA256 hash of a file and returns it as lowercase hex string.
@@ -14,16 +18,21 @@ import (
 // Content is hashed verbatim, including line endings: the same source checked
 // out with CRLF hashes differently than with LF. Path keys are OS-normalized
 // (see normalizePath) but byte content is not, so cross-OS golden-hash fixtures
-// must pin a single line-ending convention (e.g. .gitattributes `-text`).
+// must pin a single line-ending convention (e.g. .gitattributes `-text`), or
+// compare RootHashV1LF hashes (GeneratorConfig.NormalizedRootHash).
 func HashFile(path string) (string, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to open file: %w", err)
 	}
 	defer f.Close()
+	return hashStream(f)
+}
 
+// hashStream is HashFile for the bytes r yields.
+func hashStream(r io.Reader) (string, error) {
 	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
+	if _, err := io.Copy(h, r); err != nil {
 		return "", fmt.Errorf("failed to hash file: %w", err)
 	}
 
@@ -37,13 +46,112 @@ func HashBytes(data []byte) string {
 	return fmt.Sprintf("%x", h[:])
 }
 
-// ComputeRootHash computes deterministic root hash from IR files.
-// For each file (sorted by normalized path):
-//
-//	normalized_path + ":" + file_hash
-//
-// Join with newlines, SHA256 hash, return lowercase hex.
+// Root-hash algorithms. The identifier is stored in the IR next to the hash,
+// so a hash is never compared with one computed a different way, and a tool
+// that changes the algorithm can still compute the old one while data
+// migrates.
+const (
+	// RootHashV1 is SHA-256, lowercase hex, over the IR's files sorted
+	// byte-wise by key, each as "key:filehash", joined by "\n" with no trailing
+	// newline. An empty IR hashes the empty string.
+	RootHashV1 = "v1"
+
+	// RootHashV1LF is RootHashV1 over each file's NormalizedHash instead of
+	// its Hash, so line endings do not count. Every file must have one.
+	RootHashV1LF = "v1-lf"
+
+	// CurrentRootHashAlg is what the generator writes, unless configured
+	// with NormalizedRootHash.
+	CurrentRootHashAlg = RootHashV1
+)
+
+// ComputeRootHash computes the root hash of files with CurrentRootHashAlg.
 func ComputeRootHash(files map[string]FileIR) string {
+	h, _ := ComputeRootHashAlg(CurrentRootHashAlg, files)
+	return h
+}
+
+// ComputeRootHashAlg computes the root hash of files with the named algorithm.
+// An unknown name is an error: guessing would produce a hash that compares
+// unequal for the wrong reason.
+func ComputeRootHashAlg(alg string, files map[string]FileIR) (string, error) {
+	switch alg {
+	case RootHashV1:
+		return rootHashV1(alg, files), nil
+	case RootHashV1LF:
+		for path, f := range files {
+			if f.NormalizedHash == "" {
+				return "", fmt.Errorf("%s has no normalized hash for %s", path, alg)
+			}
+		}
+		return rootHashV1(alg, files), nil
+	}
+	return "", fmt.Errorf("unknown root hash algorithm %q (known: %s, %s)", alg, RootHashV1, RootHashV1LF)
+}
+
+// fileHash is the hash of f that alg's root hash is over.
+func fileHash(alg string, f FileIR) string {
+	if alg == RootHashV1LF {
+		return f.NormalizedHash
+	}
+	return f.Hash
+}
+
+// normalizedHash is FileIR.NormalizedHash for text.
+func normalizedHash(text []byte) string {
+	return HashBytes(bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n")))
+}
+
+// hashFileNormalized is normalizedHash for the file open opens, streamed.
+func hashFileNormalized(open func() (io.ReadCloser, error)) (string, error) {
+	f, err := open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	w := &crlfWriter{w: h}
+	if _, err := io.Copy(w, f); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+	w.flush()
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// crlfWriter passes on what it is written with each CRLF turned into LF. A
+// CR that ends one write is held until the next shows what follows it.
+type crlfWriter struct {
+	w  io.Writer
+	cr bool
+}
+
+func (c *crlfWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p)+1)
+	for _, b := range p {
+		if c.cr && b != '\n' {
+			out = append(out, '\r')
+		}
+		c.cr = b == '\r'
+		if !c.cr {
+			out = append(out, b)
+		}
+	}
+	if _, err := c.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// flush writes a CR still held at the end of the input.
+func (c *crlfWriter) flush() {
+	if c.cr {
+		c.w.Write([]byte{'\r'})
+		c.cr = false
+	}
+}
+
+// rootHashV1 is RootHashV1, or RootHashV1LF, over files.
+func rootHashV1(alg string, files map[string]FileIR) string {
 	if len(files) == 0 {
 		return HashBytes([]byte{})
 	}
@@ -63,8 +171,105 @@ func ComputeRootHash(files map[string]FileIR) string {
 		}
 		builder.WriteString(path)
 		builder.WriteByte(':')
-		builder.WriteString(files[path].Hash)
+		builder.WriteString(fileHash(alg, files[path]))
 	}
 
 	return HashBytes([]byte(builder.String()))
 }
+
+// VerifyRootHash recomputes ir's root hash with the algorithm it names and
+// reports a mismatch or an unknown algorithm. The hashes of a multi-root IR's
+// Roots are checked the same way.
+func (ir *IR) VerifyRootHash() error {
+	alg := ir.RootHashAlgorithm()
+	got, err := ComputeRootHashAlg(alg, ir.Files)
+	if err != nil {
+		return err
+	}
+	if got != ir.RootHash {
+		return fmt.Errorf("root hash %s does not match its files (%s computes %s)", ir.RootHash, alg, got)
+	}
+	if ir.Roots == nil {
+		return nil
+	}
+	roots := slices.Sorted(maps.Keys(ir.Roots))
+	if err := checkRoots(roots); err != nil {
+		return err
+	}
+	for key := range ir.Files {
+		if rootOf(ir.Roots, key) == "" {
+			return fmt.Errorf("%s is under none of the IR's roots", key)
+		}
+	}
+	for _, root := range roots {
+		got, _ := ComputeRootHashAlg(alg, underRoot(ir.Files, root))
+		if got != ir.Roots[root] {
+			return fmt.Errorf("root %s hash %s does not match its files (%s computes %s)", root, ir.Roots[root], alg, got)
+		}
+	}
+	return nil
+}
+
+// RootHashAlgorithm returns the algorithm ir.RootHash was computed with. An IR
+// written before the field existed names none, and was computed with
+// RootHashV1.
+func (ir *IR) RootHashAlgorithm() string {
+	if ir.RootHashAlg == "" {
+		return RootHashV1
+	}
+	return ir.RootHashAlg
+}
+
+// rootHashCache keeps the RootHashV1 (or RootHashV1LF) preimage of the last
+// IR UpdateSingleFile touched, with the offset of each file's hash in it. An edit that changes
+// one file's hash and no key overwrites those bytes in place and re-hashes
+// the buffer: no sort, no string building, which are most of the cost of
+// ComputeRootHash on a large repo.
+type rootHashCache struct {
+	mu sync.Mutex
+	// ir is the IR the rest describes, when its RootHash was rootHash; any
+	// other IR, or this one changed behind the cache's back, rebuilds it.
+	ir       *IR
+	rootHash string
+	alg      string
+	keys     []string // sorted
+	offsets  []int    // offsets[i]: where keys[i]'s file hash starts in buf
+	buf      []byte
+}
+
+// rehash returns irData's root hash under alg after the hash of key, already
+// in the IR, changed from old. irData.RootHash must still be the one from
+// before.
+func (c *rootHashCache) rehash(irData *IR, alg, key, old string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h := fileHash(alg, irData.Files[key])
+	if c.ir != irData || c.alg != alg || c.rootHash != irData.RootHash || len(c.keys) != len(irData.Files) {
+		c.build(irData, alg)
+	} else if i := sort.SearchStrings(c.keys, key); i < len(c.keys) && c.keys[i] == key && len(h) == len(old) {
+		copy(c.buf[c.offsets[i]:], h)
+	} else {
+		c.build(irData, alg)
+	}
+	c.rootHash = HashBytes(c.buf)
+	return c.rootHash
+}
+
+func (c *rootHashCache) build(irData *IR, alg string) {
+	c.ir, c.alg = irData, alg
+	c.keys = c.keys[:0]
+	for k := range irData.Files {
+		c.keys = append(c.keys, k)
+	}
+	sort.Strings(c.keys)
+	c.offsets = c.offsets[:0]
+	c.buf = c.buf[:0]
+	for i, k := range c.keys {
+		if i > 0 {
+			c.buf = append(c.buf, '\n')
+		}
+		c.buf = append(append(c.buf, k...), ':')
+		c.offsets = append(c.offsets, len(c.buf))
+		c.buf = append(c.buf, fileHash(alg, irData.Files[k])...)
+	}
+}
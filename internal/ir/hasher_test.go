@@ -3,6 +3,7 @@ package ir
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -161,3 +162,77 @@ func TestHashBytes_Format(t *testing.T) {
 		}
 	}
 }
+
+// TestComputeRootHashAlg_V1Format pins RootHashV1's input format, so a change
+// to it cannot pass as a refactor: it must be a new algorithm.
+func TestComputeRootHashAlg_V1Format(t *testing.T) {
+	files := map[string]FileIR{"b.go": {Hash: "h2"}, "a.go": {Hash: "h1"}}
+	got, err := ComputeRootHashAlg(RootHashV1, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := HashBytes([]byte("a.go:h1\nb.go:h2")); got != want {
+		t.Errorf("v1 root hash = %s, want %s", got, want)
+	}
+	if _, err := ComputeRootHashAlg("v0", files); err == nil {
+		t.Error("unknown algorithm accepted")
+	}
+}
+
+// TestComputeRootHashAlg_V1LF: RootHashV1LF is v1's format over the
+// normalized hashes, and refuses a file without one.
+func TestComputeRootHashAlg_V1LF(t *testing.T) {
+	files := map[string]FileIR{"b.go": {Hash: "h2", NormalizedHash: "n2"}, "a.go": {Hash: "h1", NormalizedHash: "n1"}}
+	got, err := ComputeRootHashAlg(RootHashV1LF, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := HashBytes([]byte("a.go:n1\nb.go:n2")); got != want {
+		t.Errorf("v1-lf root hash = %s, want %s", got, want)
+	}
+	files["c.go"] = FileIR{Hash: "h3"}
+	if _, err := ComputeRootHashAlg(RootHashV1LF, files); err == nil {
+		t.Error("file without a normalized hash accepted")
+	}
+}
+
+// TestCRLFWriter: the streamed normalization matches the in-memory one, a
+// CRLF split across writes included, and a lone CR is kept.
+func TestCRLFWriter(t *testing.T) {
+	for _, chunks := range [][]string{
+		{"a\r\nb\r\n"},
+		{"a\r", "\nb\r", "\n"},
+		{"a\rb\r", "\r\n", "\r"},
+		{"", "\r", "", "\n"},
+	} {
+		var out strings.Builder
+		w := &crlfWriter{w: &out}
+		for _, c := range chunks {
+			w.Write([]byte(c))
+		}
+		w.flush()
+		in := strings.Join(chunks, "")
+		if want := strings.ReplaceAll(in, "\r\n", "\n"); out.String() != want {
+			t.Errorf("%q: wrote %q, want %q", chunks, out.String(), want)
+		}
+		if got, want := HashBytes([]byte(out.String())), normalizedHash([]byte(in)); got != want {
+			t.Errorf("%q: streamed hash %s, in-memory %s", chunks, got, want)
+		}
+	}
+}
+
+func TestVerifyRootHash(t *testing.T) {
+	files := map[string]FileIR{"a.go": {Hash: "h1"}}
+	irData := &IR{Version: IRVersion, RootHash: ComputeRootHash(files), Files: files}
+	if err := irData.VerifyRootHash(); err != nil {
+		t.Errorf("IR without an algorithm (pre-field) should verify as v1: %v", err)
+	}
+	irData.Files = map[string]FileIR{"a.go": {Hash: "h2"}}
+	if err := irData.VerifyRootHash(); err == nil {
+		t.Error("tampered file hash verified")
+	}
+	irData.RootHashAlg = "v99"
+	if err := irData.VerifyRootHash(); err == nil {
+		t.Error("unknown algorithm verified")
+	}
+}
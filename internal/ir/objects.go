@@ -0,0 +1,235 @@
+package ir
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/inth3shadows/runecho/internal/parser"
+	"github.com/inth3shadows/runecho/internal/store"
+	"github.com/inth3shadows/runecho/internal/version"
+)
+
+// An ObjectStore keeps FileIRs as content-addressed blobs, git-style: each is
+// a file named by the SHA-256 of its JSON, under a two-character fan-out
+// directory. It serves two ends. A thin IR (see SaveThin) names each file's
+// object instead of inlining it, so a committed IR is a map of paths to ids
+// and each revision adds objects only for the files it changed. And a
+// Generator given a store (GeneratorConfig.Objects) looks a file's content
+// up in it before parsing, so switching branches, or indexing another
+// revision, reparses only content the store has never seen.
+//
+// The parse lookups go through index entries, one file per parse key (see
+// Generator.parseKey), under index/ in the same tree. Objects and index
+// entries are written once and never removed: nothing collects the objects
+// no IR names any longer.
+type ObjectStore struct {
+	dir string
+	// warnOnce keeps a store that cannot be written (read-only checkout, full
+	// disk) to one warning per Generator run rather than one per file.
+	warnOnce sync.Once
+}
+
+// ObjectsDirName is the object directory's name, next to the IR file it
+// serves: .ai/objects for the default .ai/ir.json.
+const ObjectsDirName = "objects"
+
+// NewObjectStore returns the store rooted at dir. Nothing is created until the
+// first write.
+func NewObjectStore(dir string) *ObjectStore { return &ObjectStore{dir: dir} }
+
+// ObjectsDir returns the object directory that belongs with the local IR file
+// at irPath.
+func ObjectsDir(irPath string) string {
+	return filepath.Join(filepath.Dir(irPath), ObjectsDirName)
+}
+
+// Dir returns the directory the store is rooted at.
+func (s *ObjectStore) Dir() string { return s.dir }
+
+func (s *ObjectStore) path(id string) string {
+	return filepath.Join(s.dir, id[:2], id[2:])
+}
+
+func (s *ObjectStore) indexPath(key string) string {
+	return filepath.Join(s.dir, "index", key[:2], key[2:])
+}
+
+// validID reports whether id is a lowercase hex SHA-256, so a crafted thin IR
+// cannot name a path outside the store.
+func validID(id string) bool {
+	if len(id) != 2*sha256.Size {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if c := id[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Put stores f and returns its id. Storing an object already present is a
+// stat, not a write.
+func (s *ObjectStore) Put(f FileIR) (string, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	id := HashBytes(data)
+	if err := writeOnce(s.path(id), data); err != nil {
+		return "", fmt.Errorf("store object %s: %w", id, err)
+	}
+	return id, nil
+}
+
+// Get reads object id, checking that its content still hashes to id.
+func (s *ObjectStore) Get(id string) (FileIR, error) {
+	if !validID(id) {
+		return FileIR{}, fmt.Errorf("bad object id %q", id)
+	}
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		return FileIR{}, fmt.Errorf("read object %s: %w", id, err)
+	}
+	if HashBytes(data) != id {
+		return FileIR{}, fmt.Errorf("object %s is corrupt: its content hashes differently", id)
+	}
+	var f FileIR
+	if err := json.Unmarshal(data, &f); err != nil {
+		return FileIR{}, fmt.Errorf("object %s: %w", id, err)
+	}
+	return f, nil
+}
+
+// lookup returns the FileIR recorded under parse key, if there is one and
+// its object is intact.
+func (s *ObjectStore) lookup(key, hash string) (FileIR, bool) {
+	id, err := os.ReadFile(s.indexPath(key))
+	if err != nil {
+		return FileIR{}, false
+	}
+	f, err := s.Get(string(id))
+	if err != nil || f.Hash != hash {
+		return FileIR{}, false
+	}
+	return f, true
+}
+
+// remember stores f and records it under parse key. A failure is warned
+// about once and otherwise ignored: the store only saves work.
+func (s *ObjectStore) remember(key string, f FileIR, warn func(Warning)) {
+	id, err := s.Put(f)
+	if err == nil {
+		err = writeOnce(s.indexPath(key), []byte(id))
+	}
+	if err != nil {
+		s.warnOnce.Do(func() {
+			warn(Warning{Kind: WarnObjectStore, Path: s.dir, Message: fmt.Sprintf("object store %s: not caching parse results: %v", s.dir, err)})
+		})
+	}
+}
+
+// writeOnce writes data to path unless a file is already there. Content
+// addressing makes an existing file's content the same as data, so two
+// writers racing to create it agree.
+func writeOnce(path string, data []byte) error {
+	if _, err := os.Lstat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return store.AtomicWriteFile(path, data)
+}
+
+// parseKey names what a parse of content hash produces with parser p, read as
+// extension as from a file named name (empty unless p is a
+// parser.NameAwareParser): everything a built-in parse depends on besides the
+// bytes. The
+// runecho version is in it because a parser fix does not always bump
+// IRVersion; a "dev" build cannot tell its own revisions apart, so after
+// changing a parser in a working tree, remove the objects directory.
+func (g *Generator) parseKey(p parser.Parser, as, name, hash string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "runecho-parse\x00%d\x00%s\x00%T\x00%s\x00%s\x00%d\x00%s", IRVersion, version.Version, p, as, name, g.maxLineBytes, hash)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// thinIR is the on-disk shape of a thin IR: an IR whose files name objects.
+type thinIR struct {
+	Version     int               `json:"version"`
+	RootHash    string            `json:"root_hash"`
+	RootHashAlg string            `json:"root_hash_alg"`
+	Roots       map[string]string `json:"roots,omitempty"`
+	// Objects is the object directory, relative to the IR file. It is what
+	// marks the IR as thin.
+	Objects   string            `json:"objects"`
+	Files     map[string]string `json:"files"`
+	Omissions []Omission        `json:"omissions,omitempty"`
+}
+
+// SaveThin writes the IR to the local file path as a thin IR, its FileIRs
+// stored as objects in ObjectsDir(path). Load reads it back whole. Readers
+// of the file itself must resolve the objects: "files" maps each path to an
+// object id rather than to its symbols. A storage-backend path is refused,
+// since the objects would have nowhere to live.
+func (ir *IR) SaveThin(path string) error {
+	if path == "" {
+		path = DefaultIRPath
+	}
+	if IsStorageURI(path) {
+		return fmt.Errorf("cannot save a thin IR to %s: a thin IR needs a local objects directory", path)
+	}
+	objects := NewObjectStore(ObjectsDir(path))
+	out := thinIR{
+		Version:     ir.Version,
+		RootHash:    ir.RootHash,
+		RootHashAlg: ir.RootHashAlgorithm(),
+		Roots:       ir.Roots,
+		Objects:     ObjectsDirName,
+		Files:       make(map[string]string, len(ir.Files)),
+		Omissions:   ir.Omissions,
+	}
+	for key, f := range ir.Files {
+		id, err := objects.Put(f)
+		if err != nil {
+			return fmt.Errorf("failed to save IR: %w", err)
+		}
+		out.Files[key] = id
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("failed to marshal IR: %w", err)
+	}
+	return writeIRFile(path, data)
+}
+
+// resolveThin fills in a thin IR loaded from the local file at path from its
+// objects, and checks the result against its root hash.
+func (ir *IR) resolveThin(path string) error {
+	if !filepath.IsLocal(filepath.FromSlash(ir.thin.objects)) {
+		return fmt.Errorf("thin IR %q: objects directory %q is outside its directory", path, ir.thin.objects)
+	}
+	objects := NewObjectStore(filepath.Join(filepath.Dir(path), filepath.FromSlash(ir.thin.objects)))
+	files := make(map[string]FileIR, len(ir.thin.files))
+	for key, id := range ir.thin.files {
+		f, err := objects.Get(id)
+		if err != nil {
+			return fmt.Errorf("thin IR %q: %s: %w", path, key, err)
+		}
+		files[key] = f
+	}
+	ir.Files, ir.thin = files, nil
+	if err := ir.VerifyRootHash(); err != nil {
+		return fmt.Errorf("thin IR %q: %w", path, err)
+	}
+	return nil
+}
+
+// errThinBackend is Load's answer for a thin IR behind a storage backend.
+var errThinBackend = errors.New("a thin IR can only be loaded from a local file")
@@ -0,0 +1,132 @@
+package ir
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSaveThin_RoundTrip: a thin IR loads back to the IR that was saved, and
+// a second revision of the tree stores objects only for what it changed.
+func TestSaveThin_RoundTrip(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.go":     "package a\n\nfunc A() {}\n",
+		"b.py":     "def b():\n    pass\n",
+		"web/c.ts": "export function c() {}\n",
+	})
+	gen := NewGenerator(GeneratorConfig{})
+	first, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	irPath := filepath.Join(t.TempDir(), "ir.json")
+	if err := first.SaveThin(irPath); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(irPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(first)
+	got, _ := json.Marshal(loaded)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("thin round trip differs:\n got %s\nwant %s", got, want)
+	}
+	raw, _ := os.ReadFile(irPath)
+	if bytes.Contains(raw, []byte(`"symbols"`)) {
+		t.Errorf("thin IR inlines its files:\n%s", raw)
+	}
+
+	writeTree(t, root, map[string]string{"a.go": "package a\n\nfunc A2() {}\n"})
+	second, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := second.SaveThin(irPath); err != nil {
+		t.Fatal(err)
+	}
+	if n := countObjects(t, ObjectsDir(irPath)); n != 4 {
+		t.Errorf("%d objects after two revisions, want 4 (3 + the changed a.go)", n)
+	}
+
+	var thin thinIR
+	if err := json.Unmarshal(raw, &thin); err != nil {
+		t.Fatal(err)
+	}
+	objects := NewObjectStore(ObjectsDir(irPath))
+	os.WriteFile(objects.path(thin.Files["b.py"]), []byte(`{"hash":"x"}`), 0600)
+	if _, err := Load(irPath); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Errorf("corrupt object: err = %v", err)
+	}
+}
+
+// TestObjectStore_ParseCache: a Generator with a store takes a file's entry
+// from it by content, so a parse is not repeated — here proven by planting a
+// different entry under the key and seeing it come back.
+func TestObjectStore_ParseCache(t *testing.T) {
+	root := t.TempDir()
+	src := "package a\n\nfunc A() {}\n"
+	writeTree(t, root, map[string]string{"a.go": src})
+	objects := NewObjectStore(t.TempDir())
+	gen := NewGenerator(GeneratorConfig{Objects: objects})
+	first, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, as, _ := gen.parserFor("a.go")
+	key := gen.parseKey(p, as, "", HashBytes([]byte(src)))
+	if _, ok := objects.lookup(key, HashBytes([]byte(src))); !ok {
+		t.Fatal("the parse was not recorded")
+	}
+	planted := first.Files["a.go"]
+	planted.Symbols = append([]Symbol{{Name: "Planted", Kind: "function"}}, planted.Symbols...)
+	sortSymbols(planted.Symbols)
+	id, err := objects.Put(planted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(objects.indexPath(key), []byte(id), 0600); err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := NewGenerator(GeneratorConfig{Objects: objects}).Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasSymbol(again.Files["a.go"], "Planted") {
+		t.Errorf("the file was parsed again: %+v", again.Files["a.go"].Symbols)
+	}
+	if fresh, _, _ := NewGenerator(GeneratorConfig{}).Generate(root); hasSymbol(fresh.Files["a.go"], "Planted") {
+		t.Error("a Generator without a store read it")
+	}
+}
+
+func countObjects(t *testing.T, dir string) int {
+	t.Helper()
+	n := 0
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == "index" {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			n++
+		}
+		return nil
+	})
+	return n
+}
+
+func hasSymbol(f FileIR, name string) bool {
+	for _, s := range f.Symbols {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package ir
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/inth3shadows/runecho/internal/parser"
+)
+
+// adversarialRunes mixes scripts and encodings where a locale-aware, case-
+// folding, or UTF-16 comparison would order differently from bytes: RTL
+// letters and marks, astral-plane emoji and math letters (surrogate pairs in
+// UTF-16, so they sort before U+E000–U+FFFF there but after it in UTF-8),
+// a halfwidth form just below them, ligatures, and letters whose case mapping
+// changes length.
+var adversarialRunes = []rune("aZz_-09éßİﬁΩאع\u200f中｡😀👍🏽𝒳")
+
+func adversarialName(rng *rand.Rand) string {
+	var b strings.Builder
+	for n := 1 + rng.Intn(5); n > 0; n-- {
+		b.WriteRune(adversarialRunes[rng.Intn(len(adversarialRunes))])
+	}
+	return b.String()
+}
+
+// shufflingParser declares every line of a file as a function, class, and
+// import, in a different random order on every call: a parser that leaks map
+// iteration order, as far as the generator can tell.
+type shufflingParser struct{ rng *rand.Rand }
+
+func (p shufflingParser) SupportsExtension(ext string) bool { return ext == ".adv" }
+
+func (p shufflingParser) Parse(src string) (parser.FileStructure, error) {
+	names := strings.Fields(src)
+	shuffled := func() []string {
+		out := append([]string(nil), names...)
+		p.rng.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+		return out
+	}
+	return parser.FileStructure{Functions: shuffled(), Classes: shuffled(), Imports: shuffled()}, nil
+}
+
+// objectKeys returns the keys of the top-level object member field of data,
+// in encoded order.
+func objectKeys(t *testing.T, data []byte, field string) []string {
+	t.Helper()
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(top[field]))
+	if _, err := dec.Token(); err != nil { // {
+		t.Fatal(err)
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, tok.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return keys
+}
+
+func isByteSorted(s []string) bool {
+	for i := 1; i < len(s); i++ {
+		if s[i-1] > s[i] { // Go compares strings byte-wise
+			return false
+		}
+	}
+	return true
+}
+
+// TestOrdering_ByteWiseProperty generates trees of adversarially named files
+// and symbols and checks the ordering contract: files, symbols, and imports
+// are in byte-wise UTF-8 order, and the IR is byte-identical however the
+// parser happened to order its output.
+func TestOrdering_ByteWiseProperty(t *testing.T) {
+	rng := rand.New(rand.NewSource(4213))
+	for iter := 0; iter < 25; iter++ {
+		root := t.TempDir()
+		for n := 2 + rng.Intn(6); n > 0; n-- {
+			rel := adversarialName(rng) + ".adv"
+			if rng.Intn(2) == 0 {
+				rel = filepath.Join(adversarialName(rng), rel)
+			}
+			var src []string
+			for k := 1 + rng.Intn(6); k > 0; k-- {
+				src = append(src, adversarialName(rng))
+			}
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(root, rel)), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(root, rel), []byte(strings.Join(src, "\n")), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var outputs [][]byte
+		for run := 0; run < 2; run++ {
+			gen := NewGenerator(GeneratorConfig{Parsers: []parser.Parser{shufflingParser{rand.New(rand.NewSource(int64(run)))}}})
+			captureWarnings(gen)
+			irData, _, err := gen.Generate(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal(irData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			outputs = append(outputs, data)
+
+			if keys := objectKeys(t, data, "files"); !isByteSorted(keys) {
+				t.Fatalf("iteration %d: files not in byte order: %q", iter, keys)
+			}
+			for path, f := range irData.Files {
+				for i := 1; i < len(f.Symbols); i++ {
+					a, b := f.Symbols[i-1], f.Symbols[i]
+					if a.Kind > b.Kind || (a.Kind == b.Kind && a.Name > b.Name) {
+						t.Fatalf("iteration %d: %s: symbols not in byte order: %q then %q", iter, path, a.Name, b.Name)
+					}
+				}
+				if imports := f.namesOf("import"); !isByteSorted(imports) {
+					t.Fatalf("iteration %d: %s: imports not in byte order: %q", iter, path, imports)
+				}
+			}
+		}
+		if !bytes.Equal(outputs[0], outputs[1]) {
+			t.Fatalf("iteration %d: IR depends on the parser's output order", iter)
+		}
+	}
+}
+
+// TestOrdering_PinnedCases pins the orders where the obvious alternatives
+// disagree with bytes: case-insensitive collation would put "a" before "Z", a
+// UTF-16 comparison would put U+1F600 (a surrogate pair) before U+FF61, and
+// locale collation would interleave "é" with "e".
+func TestOrdering_PinnedCases(t *testing.T) {
+	root := t.TempDir()
+	want := []string{"Z.go", "a.go", "e.go", "z.go", "é.go", "｡.go", "\U0001F600.go"}
+	for _, name := range want {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("package p\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	irData, _, err := NewGenerator(GeneratorConfig{}).Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(irData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := objectKeys(t, data, "files")
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("file order = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,142 @@
+package ir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GenerateRoots builds one IR of several directories under base: the apps
+// and packages of a monorepo whose root is too broad to walk whole. Each of
+// roots is a directory key relative to base ("apps/web"), none inside
+// another. Each is walked as Generate walks a tree of its own — the
+// .gitignore files that count are the ones inside it — in key order, and its
+// files and omissions are keyed with the root as prefix
+// ("apps/web/src/main.ts"). IR.Roots holds each root's own hash beside the
+// RootHash of the whole.
+//
+// A root that is not a directory is an error rather than an empty share, so
+// a mistyped root fails instead of quietly indexing nothing. A Generator with
+// a FileCap cannot generate roots, for the reason it cannot shard.
+func (g *Generator) GenerateRoots(ctx context.Context, base string, roots []string) (*IR, Stats, error) {
+	return g.buildRoots(ctx, base, roots, func(ctx context.Context, _, dir string) (*IR, Stats, error) {
+		return g.GenerateCtx(ctx, dir)
+	})
+}
+
+// UpdateRoots is GenerateRoots updating existingIR the way UpdateCtx does:
+// each root's files in existingIR, with the prefix taken off, are the
+// entries its Update may reuse. existingIR need not have been built with the
+// same roots, or with roots at all.
+func (g *Generator) UpdateRoots(ctx context.Context, existingIR *IR, base string, roots []string) (*IR, Stats, error) {
+	return g.buildRoots(ctx, base, roots, func(ctx context.Context, root, dir string) (*IR, Stats, error) {
+		var sub *IR
+		if existingIR != nil {
+			sub = &IR{Version: existingIR.Version, RootHashAlg: existingIR.RootHashAlg, Files: underRoot(existingIR.Files, root)}
+		}
+		return g.UpdateCtx(ctx, sub, dir)
+	})
+}
+
+// buildRoots is GenerateRoots with build making each root's IR.
+func (g *Generator) buildRoots(ctx context.Context, base string, roots []string, build func(ctx context.Context, root, dir string) (*IR, Stats, error)) (*IR, Stats, error) {
+	if g.fileCap > 0 {
+		return nil, Stats{}, errors.New("a capped generator cannot generate roots: the cap spans the whole tree")
+	}
+	roots = slices.Sorted(slices.Values(roots))
+	if err := checkRoots(roots); err != nil {
+		return nil, Stats{}, err
+	}
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		return nil, Stats{}, fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	for _, root := range roots {
+		if info, err := os.Stat(filepath.Join(absBase, filepath.FromSlash(root))); err != nil || !info.IsDir() {
+			return nil, Stats{}, fmt.Errorf("root %s is not a directory under %s", root, absBase)
+		}
+	}
+	start := time.Now()
+	// One deadline for the whole workspace, not one per root.
+	ctx, cancel := g.withDeadline(ctx)
+	defer cancel()
+
+	result := &IR{Version: IRVersion, RootHashAlg: g.rootHashAlg, Roots: make(map[string]string), Files: make(map[string]FileIR)}
+	var stats Stats
+	for _, root := range roots {
+		sub, s, err := build(ctx, root, filepath.Join(absBase, filepath.FromSlash(root)))
+		if err != nil {
+			return nil, Stats{}, fmt.Errorf("root %s: %w", root, err)
+		}
+		for key, f := range sub.Files {
+			result.Files[root+"/"+key] = f
+		}
+		for _, o := range sub.Omissions {
+			o.Path = path.Join(root, o.Path)
+			result.Omissions = append(result.Omissions, o)
+		}
+		result.Roots[root] = sub.RootHash
+		stats.add(s)
+	}
+	sort.Slice(result.Omissions, func(i, j int) bool { return result.Omissions[i].Path < result.Omissions[j].Path })
+	result.RootHash = g.rootHash(result.Files)
+	stats.Indexed = len(result.Files)
+	stats.ParseSkipped = countParseSkipped(result.Files)
+	stats.Time.Total = time.Since(start)
+	return result, stats, nil
+}
+
+// checkRoots reports the first of roots, sorted, that is not a directory key
+// or that repeats or nests in another.
+func checkRoots(roots []string) error {
+	if len(roots) == 0 {
+		return errors.New("no roots")
+	}
+	for i, root := range roots {
+		if root == "" || root == "." || path.Clean(root) != root || !isLocalKey(root) || normalizePath(root) != root {
+			return fmt.Errorf("root %q is not a directory key", root)
+		}
+		// Sorted, the roots starting with root come right after it, and any
+		// inside it is among them.
+		for _, next := range roots[i+1:] {
+			if next == root {
+				return fmt.Errorf("root %q given twice", root)
+			}
+			if strings.HasPrefix(next, root+"/") {
+				return fmt.Errorf("root %q is inside %q", next, root)
+			}
+			if !strings.HasPrefix(next, root) {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// rootOf returns the root among roots that key is under, or "".
+func rootOf(roots map[string]string, key string) string {
+	for d := path.Dir(key); d != "."; d = path.Dir(d) {
+		if _, ok := roots[d]; ok {
+			return d
+		}
+	}
+	return ""
+}
+
+// underRoot returns the files under root, keyed relative to it.
+func underRoot(files map[string]FileIR, root string) map[string]FileIR {
+	sub := make(map[string]FileIR)
+	for key, f := range files {
+		if rel, ok := strings.CutPrefix(key, root+"/"); ok {
+			sub[rel] = f
+		}
+	}
+	return sub
+}
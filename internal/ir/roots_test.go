@@ -0,0 +1,77 @@
+package ir
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestGenerateRoots: each root is walked as a tree of its own, under its key
+// as prefix, and its hash in Roots is the RootHash a Generate of it gets.
+func TestGenerateRoots(t *testing.T) {
+	base := t.TempDir()
+	writeTree(t, base, map[string]string{
+		".gitignore":                 "*.js\n", // above the roots: not theirs
+		"main.go":                    "package main\n",
+		"apps/web/app.js":            "export function app() {}\n",
+		"apps/web/.gitignore":        "gen/\n",
+		"apps/web/gen/out.js":        "export const out = 1\n",
+		"apps/api/server.go":         "package api\n",
+		"packages/shared/util.ts":    "export function util() {}\n",
+		"packages/shared/lib/fmt.ts": "export const fmt = 1\n",
+	})
+	gen := NewGenerator(GeneratorConfig{})
+	ctx := context.Background()
+	got, stats, err := gen.GenerateRoots(ctx, base, []string{"packages/shared", "apps/web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"apps/web/app.js", "packages/shared/lib/fmt.ts", "packages/shared/util.ts"}; !reflect.DeepEqual(indexedPaths(got), want) {
+		t.Errorf("indexed %v, want %v", indexedPaths(got), want)
+	}
+	if stats.Indexed != 3 || stats.SupportedSeen != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+	for root := range got.Roots {
+		alone, _, err := gen.Generate(filepath.Join(base, filepath.FromSlash(root)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Roots[root] != alone.RootHash {
+			t.Errorf("Roots[%s] = %s, a Generate of it gets %s", root, got.Roots[root], alone.RootHash)
+		}
+	}
+	if len(got.Roots) != 2 || got.VerifyRootHash() != nil {
+		t.Errorf("roots %v: %v", got.Roots, got.VerifyRootHash())
+	}
+
+	writeTree(t, base, map[string]string{"apps/web/app.js": "export function app2() {}\n"})
+	updated, _, err := gen.UpdateRoots(ctx, got, base, []string{"apps/web", "packages/shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	regenerated, _, err := gen.GenerateRoots(ctx, base, []string{"apps/web", "packages/shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(updated, regenerated) || updated.Roots["packages/shared"] != got.Roots["packages/shared"] {
+		t.Errorf("UpdateRoots = %+v, GenerateRoots = %+v", updated, regenerated)
+	}
+	if _, changed, _ := gen.UpdateFile(updated, base, filepath.Join(base, "apps/web/app.js")); changed {
+		t.Error("UpdateFile refreshed a multi-root IR")
+	}
+
+	tampered := *updated
+	tampered.Roots = map[string]string{"apps/web": got.Roots["apps/web"], "packages/shared": got.Roots["packages/shared"]}
+	if err := tampered.VerifyRootHash(); err == nil || !strings.Contains(err.Error(), "root apps/web") {
+		t.Errorf("a stale root hash verified: %v", err)
+	}
+
+	for _, roots := range [][]string{nil, {"apps", "apps/web"}, {"apps/web", "apps/web"}, {"../x"}, {"."}, {"apps/missing"}, {"main.go"}} {
+		if _, _, err := gen.GenerateRoots(ctx, base, roots); err == nil {
+			t.Errorf("GenerateRoots(%q) succeeded", roots)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package ir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A distributed Generate splits one tree among workers by directory. A
+// ShardPlan gives each worker a set of directories, and worker 0 the rest of
+// the tree besides. Every worker walks the whole root under the same rules as
+// Generate — the same IR keys, ignored paths, and path filters — and keeps
+// only the files in its share, so MergeShards can put the shards back
+// together into exactly the IR one Generate of the tree would have built,
+// byte for byte. The workers must run the same version with the same
+// configuration; the merge checks what it can (format, root hashes, that no
+// shard strays outside its share) but cannot see a parser that differs.
+
+// ShardPlan assigns a tree's directories to workers.
+type ShardPlan struct {
+	// Shards[i] lists worker i's directories, as IR keys. No directory is
+	// inside another, in one shard or across two. Files under none of them
+	// belong to worker 0.
+	Shards [][]string `json:"shards"`
+}
+
+// ShardIR is one worker's result: the IR of its share of the tree.
+type ShardIR struct {
+	Plan  ShardPlan `json:"plan"`
+	Index int       `json:"index"`
+	IR    *IR       `json:"ir"`
+	Stats Stats     `json:"stats"`
+}
+
+// shardScope is the walk's view of one worker's share (see Generator.scope).
+type shardScope struct {
+	index int
+	owner map[string]int // plan directory → its worker
+	// ancestors holds the proper ancestors of this worker's directories: a
+	// worker other than 0 walks through them without keeping their files.
+	ancestors map[string]bool
+}
+
+func (p ShardPlan) scope(index int) (*shardScope, error) {
+	if len(p.Shards) == 0 {
+		return nil, errors.New("shard plan has no shards")
+	}
+	if index < 0 || index >= len(p.Shards) {
+		return nil, fmt.Errorf("shard index %d out of range [0, %d)", index, len(p.Shards))
+	}
+	s := &shardScope{index: index, owner: make(map[string]int), ancestors: make(map[string]bool)}
+	for i, dirs := range p.Shards {
+		for _, dir := range dirs {
+			if dir == "" || dir == "." || path.Clean(dir) != dir || !isLocalKey(dir) || normalizePath(dir) != dir {
+				return nil, fmt.Errorf("shard plan: %q is not a directory key", dir)
+			}
+			if prev, dup := s.owner[dir]; dup {
+				return nil, fmt.Errorf("shard plan: %q is in shards %d and %d", dir, prev, i)
+			}
+			s.owner[dir] = i
+		}
+	}
+	for dir := range s.owner {
+		for parent := path.Dir(dir); parent != "."; parent = path.Dir(parent) {
+			if _, nested := s.owner[parent]; nested {
+				return nil, fmt.Errorf("shard plan: %q is inside %q", dir, parent)
+			}
+			if s.owner[dir] == index {
+				s.ancestors[parent] = true
+			}
+		}
+	}
+	return s, nil
+}
+
+// isLocalKey reports whether key is a relative, slash-separated path that
+// stays inside the root.
+func isLocalKey(key string) bool {
+	return !path.IsAbs(key) && key != ".." && !strings.HasPrefix(key, "../") && !strings.Contains(key, "\\")
+}
+
+// ownerOf returns the worker whose share holds key, a file or directory.
+func (s *shardScope) ownerOf(key string) int {
+	for k := key; k != "."; k = path.Dir(k) {
+		if i, ok := s.owner[k]; ok {
+			return i
+		}
+	}
+	return 0
+}
+
+// keeps reports whether key is in this worker's share.
+func (s *shardScope) keeps(key string) bool { return s.ownerOf(key) == s.index }
+
+// descend reports whether the walk must enter directory key: it is in this
+// worker's share, or leads to a part that is.
+func (s *shardScope) descend(key string) bool { return s.keeps(key) || s.ancestors[key] }
+
+// GenerateShard builds worker index's share of rootPath under plan. A
+// Generator with a FileCap cannot shard: the cap picks files in walk order
+// across the whole tree, which no worker sees.
+func (g *Generator) GenerateShard(ctx context.Context, rootPath string, plan ShardPlan, index int) (*ShardIR, error) {
+	if g.fileCap > 0 {
+		return nil, errors.New("a capped generator cannot shard: the cap spans the whole tree")
+	}
+	scope, err := plan.scope(index)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *g
+	scoped.scope = scope
+	irData, stats, err := scoped.GenerateCtx(ctx, rootPath)
+	if err != nil {
+		return nil, err
+	}
+	return &ShardIR{Plan: plan, Index: index, IR: irData, Stats: stats}, nil
+}
+
+// MergeShards joins every shard of one plan into the IR of the whole tree.
+// It fails unless it has each shard exactly once, all built under the same
+// plan in the current format and with one root hash algorithm, each intact and holding only its own share.
+// Its Stats are the shards' added up; Time too, so it is the time the workers
+// spent between them, not how long the run took.
+func MergeShards(shards []*ShardIR) (*IR, Stats, error) {
+	if len(shards) == 0 {
+		return nil, Stats{}, errors.New("no shards to merge")
+	}
+	plan := shards[0].Plan
+	if len(shards) != len(plan.Shards) {
+		return nil, Stats{}, fmt.Errorf("have %d shards, the plan has %d", len(shards), len(plan.Shards))
+	}
+	seen := make([]bool, len(plan.Shards))
+	files := make(map[string]FileIR)
+	var (
+		omissions []Omission
+		stats     Stats
+		alg       string // the shards' root hash algorithm
+	)
+	for _, sh := range shards {
+		if !reflect.DeepEqual(sh.Plan, plan) {
+			return nil, Stats{}, fmt.Errorf("shard %d was built under another plan", sh.Index)
+		}
+		scope, err := plan.scope(sh.Index)
+		if err != nil {
+			return nil, Stats{}, err
+		}
+		if seen[sh.Index] {
+			return nil, Stats{}, fmt.Errorf("shard %d given twice", sh.Index)
+		}
+		seen[sh.Index] = true
+		if sh.IR == nil || sh.IR.Version != IRVersion {
+			return nil, Stats{}, fmt.Errorf("shard %d is not an IR of version %d", sh.Index, IRVersion)
+		}
+		if err := sh.IR.VerifyRootHash(); err != nil {
+			return nil, Stats{}, fmt.Errorf("shard %d: %w", sh.Index, err)
+		}
+		if a := sh.IR.RootHashAlgorithm(); alg == "" {
+			alg = a
+		} else if a != alg {
+			return nil, Stats{}, fmt.Errorf("shard %d is hashed with %s, not %s", sh.Index, a, alg)
+		}
+		for key, f := range sh.IR.Files {
+			if !scope.keeps(key) {
+				return nil, Stats{}, fmt.Errorf("shard %d holds %q, outside its share", sh.Index, key)
+			}
+			files[key] = f
+		}
+		for _, o := range sh.IR.Omissions {
+			if !scope.keeps(o.Path) {
+				return nil, Stats{}, fmt.Errorf("shard %d omits %q, outside its share", sh.Index, o.Path)
+			}
+			omissions = append(omissions, o)
+		}
+		stats.add(sh.Stats)
+	}
+	sort.Slice(omissions, func(i, j int) bool { return omissions[i].Path < omissions[j].Path })
+	merged := &IR{Version: IRVersion, RootHashAlg: alg, Files: files, Omissions: omissions}
+	merged.RootHash, _ = ComputeRootHashAlg(alg, files)
+	stats.Indexed = len(files)
+	stats.ParseSkipped = countParseSkipped(files)
+	return merged, stats, nil
+}
+
+// PlanShards splits rootPath into a plan for n workers, balancing the number
+// of source files each gets. It starts from the top-level directories and
+// splits any holding more than a worker's fair share into its
+// subdirectories, then deals the directories out largest first to the
+// least-loaded worker. The plan depends only on the tree, so every run over
+// the same tree plans alike. It needs a stat walk of the whole tree, but no
+// file is read.
+func (g *Generator) PlanShards(ctx context.Context, rootPath string, n int) (ShardPlan, error) {
+	if n < 1 {
+		return ShardPlan{}, fmt.Errorf("cannot plan %d shards", n)
+	}
+	tree, err := g.StatTree(ctx, rootPath)
+	if err != nil {
+		return ShardPlan{}, err
+	}
+	count := make(map[string]int)                // directory → files under it
+	children := make(map[string]map[string]bool) // directory → its subdirectories
+	top := make(map[string]bool)
+	for key := range tree {
+		for d := path.Dir(key); d != "."; d = path.Dir(d) {
+			count[d]++
+			if parent := path.Dir(d); parent == "." {
+				top[d] = true
+			} else {
+				if children[parent] == nil {
+					children[parent] = make(map[string]bool)
+				}
+				children[parent][d] = true
+			}
+		}
+	}
+	fair := (len(tree) + n - 1) / n
+	var dirs []string
+	queue := sortedKeys(top)
+	for len(queue) > 0 {
+		d := queue[0]
+		queue = queue[1:]
+		if count[d] > fair && len(children[d]) > 0 {
+			queue = append(queue, sortedKeys(children[d])...)
+			continue
+		}
+		dirs = append(dirs, d)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if count[dirs[i]] != count[dirs[j]] {
+			return count[dirs[i]] > count[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+	plan := ShardPlan{Shards: make([][]string, n)}
+	load := make([]int, n)
+	planned := 0
+	for _, d := range dirs {
+		planned += count[d]
+	}
+	load[0] = len(tree) - planned // the rest of the tree
+	for _, d := range dirs {
+		least := 0
+		for i := range load {
+			if load[i] < load[least] {
+				least = i
+			}
+		}
+		plan.Shards[least] = append(plan.Shards[least], d)
+		load[least] += count[d]
+	}
+	for i := range plan.Shards {
+		if plan.Shards[i] == nil {
+			plan.Shards[i] = []string{}
+		}
+		sort.Strings(plan.Shards[i])
+	}
+	return plan, nil
+}
@@ -0,0 +1,151 @@
+package ir
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// shardTree is a tree with files at the root, nested and ignored
+// directories, and one directory big enough that planning has to split it.
+var shardTree = map[string]string{
+	"main.go":                     "package main\n\nfunc main() {}\n",
+	"web/app.ts":                  "export function app() {}\n",
+	"web/util/fmt.ts":             "export const fmt = 1\n",
+	"node_modules/dep/x.js":       "export function x() {}\n",
+	"svc/api/handler.go":          "package api\n\nfunc Handle() {}\n",
+	"svc/api/routes.go":           "package api\n\nfunc Routes() {}\n",
+	"svc/db/conn.go":              "package db\n\nfunc Open() {}\n",
+	"svc/db/migrate/up.go":        "package migrate\n\nfunc Up() {}\n",
+	"svc/readme_helper.py":        "def helper():\n    pass\n",
+	"tools/gen.py":                "def gen():\n    pass\n",
+	"tools/scripts/build.sh":      "build() { :; }\n",
+	"tools/zz_registered_skip.go": "package tools\n",
+}
+
+// TestShards_MergeMatchesGenerate plans, generates, and merges the tree at
+// several worker counts; the merged IR must be a full Generate's, byte for
+// byte, whichever way the tree was cut.
+func TestShards_MergeMatchesGenerate(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, shardTree)
+	gen := NewGenerator(GeneratorConfig{IgnoredPaths: DefaultIgnoredPaths})
+	ctx := context.Background()
+	full, fullStats, err := gen.GenerateCtx(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := json.Marshal(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{1, 2, 3, 5} {
+		plan, err := gen.PlanShards(ctx, root, n)
+		if err != nil {
+			t.Fatalf("n=%d: %v", n, err)
+		}
+		var shards []*ShardIR
+		for i := n - 1; i >= 0; i-- { // order must not matter
+			sh, err := gen.GenerateShard(ctx, root, plan, i)
+			if err != nil {
+				t.Fatalf("n=%d shard %d: %v", n, i, err)
+			}
+			shards = append(shards, sh)
+		}
+		merged, stats, err := MergeShards(shards)
+		if err != nil {
+			t.Fatalf("n=%d merge: %v", n, err)
+		}
+		got, err := json.Marshal(merged)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("n=%d (plan %v): merged IR differs from a full Generate", n, plan.Shards)
+		}
+		if untimed(stats) != untimed(fullStats) {
+			t.Errorf("n=%d: merged stats %+v, want %+v", n, stats, fullStats)
+		}
+	}
+}
+
+func TestPlanShards_SplitsLargeDirectories(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, shardTree)
+	gen := NewGenerator(GeneratorConfig{IgnoredPaths: DefaultIgnoredPaths})
+	plan, err := gen.PlanShards(context.Background(), root, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprint(plan.Shards)
+	// svc holds 5 of the 10 files, more than a third: it is split.
+	if strings.Contains(got, "svc ") || strings.Contains(got, "svc]") || !strings.Contains(got, "svc/api") {
+		t.Errorf("plan %s did not split svc", got)
+	}
+	again, _ := gen.PlanShards(context.Background(), root, 3)
+	if fmt.Sprint(again.Shards) != got {
+		t.Errorf("planning is not deterministic: %v then %v", got, again.Shards)
+	}
+	if _, err := gen.PlanShards(context.Background(), root, 0); err == nil {
+		t.Error("a plan for 0 workers was made")
+	}
+}
+
+func TestMergeShards_Rejects(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, shardTree)
+	gen := NewGenerator(GeneratorConfig{IgnoredPaths: DefaultIgnoredPaths})
+	ctx := context.Background()
+	plan := ShardPlan{Shards: [][]string{{"web"}, {"svc", "tools"}}}
+	shard := func(i int) *ShardIR {
+		t.Helper()
+		sh, err := gen.GenerateShard(ctx, root, plan, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sh
+	}
+	s0, s1 := shard(0), shard(1)
+
+	stray := *s1
+	stray.IR = &IR{Version: IRVersion, RootHashAlg: CurrentRootHashAlg, Files: map[string]FileIR{"main.go": s0.IR.Files["main.go"]}}
+	stray.IR.RootHash = ComputeRootHash(stray.IR.Files)
+	otherPlan := *s1
+	otherPlan.Plan = ShardPlan{Shards: [][]string{{"web"}, {"svc"}}}
+	tampered := *s1
+	tampered.IR = &IR{Version: IRVersion, RootHashAlg: CurrentRootHashAlg, Files: s1.IR.Files, RootHash: "0"}
+
+	for _, tc := range []struct {
+		name   string
+		shards []*ShardIR
+		want   string
+	}{
+		{"missing", []*ShardIR{s0}, "have 1 shards"},
+		{"twice", []*ShardIR{s0, s0}, "given twice"},
+		{"stray file", []*ShardIR{s0, &stray}, "outside its share"},
+		{"other plan", []*ShardIR{s0, &otherPlan}, "another plan"},
+		{"tampered", []*ShardIR{s0, &tampered}, "root hash"},
+	} {
+		if _, _, err := MergeShards(tc.shards); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+
+	for _, bad := range []ShardPlan{
+		{},
+		{Shards: [][]string{{"svc"}, {"svc/api"}}},
+		{Shards: [][]string{{"../up"}}},
+		{Shards: [][]string{{"svc"}, {"svc"}}},
+	} {
+		if _, err := gen.GenerateShard(ctx, root, bad, 0); err == nil {
+			t.Errorf("plan %v accepted", bad.Shards)
+		}
+	}
+	capped := NewGenerator(GeneratorConfig{FileCap: 5})
+	if _, err := capped.GenerateShard(ctx, root, plan, 0); err == nil {
+		t.Error("a capped generator sharded")
+	}
+}
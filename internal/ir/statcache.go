@@ -0,0 +1,163 @@
+package ir
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/inth3shadows/runecho/internal/store"
+)
+
+// statCacheVersion is the on-disk StatCache format; a file of another
+// version loads empty.
+const statCacheVersion = 1
+
+// statCacheRacy is how recently a file may have been modified and still be
+// cached. An edit landing in the same mtime tick as the read that hashed it
+// leaves size and mtime as they were, so a file that young is hashed again
+// next time instead. Two seconds covers the coarsest common clock (FAT).
+const statCacheRacy = 2 * time.Second
+
+// A StatCache remembers each file's size and modification time alongside
+// the hash they were seen with. Given one (GeneratorConfig.StatCache), Update
+// takes a file whose size and mtime still match, and whose IR entry still has
+// that hash, as unchanged without reading it. That is what git's index does,
+// and it fails the way git's does: a tool that rewrites a file but restores
+// both its size and its mtime hides the edit. Leave the cache out for a
+// walk that must re-hash everything.
+//
+// The cache is machine-local — paths are absolute, mtimes are this
+// filesystem's — so it lives under $RUNECHO_HOME (StatCachePath), never next
+// to an IR that may be committed. It is safe for concurrent use, and its
+// unexported methods are no-ops on a nil *StatCache.
+type StatCache struct {
+	mu sync.Mutex
+	// seen is what the last completed walk recorded; next collects the
+	// current walk's entries, and replaces seen when the walk completes, so
+	// files that are gone drop out.
+	seen map[string]statEntry
+	next map[string]statEntry
+}
+
+// statEntry is one file's cached stat, keyed by its absolute path.
+type statEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime_ns"`
+	Hash    string `json:"hash"`
+}
+
+// statCacheFile is a StatCache on disk.
+type statCacheFile struct {
+	Version int                  `json:"version"`
+	Files   map[string]statEntry `json:"files"`
+}
+
+// NewStatCache returns an empty cache.
+func NewStatCache() *StatCache {
+	return &StatCache{seen: make(map[string]statEntry)}
+}
+
+// StatCachePath returns root's cache location under $RUNECHO_HOME/statcache.
+func StatCachePath(root string) (string, error) {
+	dir, err := store.RunechoDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(root)))
+	return filepath.Join(dir, "statcache", fmt.Sprintf("%x.json", sum[:8])), nil
+}
+
+// LoadStatCache reads the cache saved at path. A missing file, or one of
+// another format version, is an empty cache; an unreadable or corrupt one is
+// an empty cache and an error, which a caller can warn about and go on.
+func LoadStatCache(path string) (*StatCache, error) {
+	c := NewStatCache()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	var f statCacheFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return c, fmt.Errorf("stat cache %s: %w", path, err)
+	}
+	if f.Version == statCacheVersion && f.Files != nil {
+		c.seen = f.Files
+	}
+	return c, nil
+}
+
+// Save writes the cache to path, atomically.
+func (c *StatCache) Save(path string) error {
+	c.mu.Lock()
+	data, err := json.Marshal(statCacheFile{Version: statCacheVersion, Files: c.seen})
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create stat cache dir: %w", err)
+	}
+	return store.AtomicWriteFile(path, data)
+}
+
+// hash returns the hash cached for the file at abs if its size and mtime are
+// still those of info.
+func (c *StatCache) hash(abs string, info fs.FileInfo) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.seen[abs]
+	if !ok || e.Size != info.Size() || e.ModTime != info.ModTime().UnixNano() {
+		return "", false
+	}
+	return e.Hash, true
+}
+
+// record notes that the file at abs, stat'd as info before it was read,
+// hashed to hash — unless it was modified too recently to trust (see
+// statCacheRacy).
+func (c *StatCache) record(abs string, info fs.FileInfo, hash string) {
+	if c == nil || hash == "" || time.Since(info.ModTime()) < statCacheRacy {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.next == nil {
+		c.next = make(map[string]statEntry)
+	}
+	c.next[abs] = statEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), Hash: hash}
+}
+
+// commit ends a completed walk: its entries replace the previous walk's.
+func (c *StatCache) commit() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen, c.next = c.next, nil
+	if c.seen == nil {
+		c.seen = make(map[string]statEntry)
+	}
+}
+
+// discard ends a failed or cancelled walk, keeping the previous entries.
+func (c *StatCache) discard() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.next = nil
+}
@@ -0,0 +1,75 @@
+package ir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestStatCache_SkipsUnchangedFiles: with a cache, Update trusts a file whose
+// size and mtime are as cached — even an edit that restored both, which only
+// a cacheless (paranoid) Update sees — and hashes one whose stat moved. A
+// file modified too recently is never cached, and the cache survives a
+// Save/Load round trip.
+func TestStatCache_SkipsUnchangedFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.go":     "package a\n\nfunc A() {}\n",
+		"b.go":     "package a\n\nfunc B() {}\n",
+		"fresh.go": "package a\n",
+	})
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.Chtimes(filepath.Join(root, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache := NewStatCache()
+	gen := NewGenerator(GeneratorConfig{StatCache: cache})
+	base, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.seen[filepath.Join(root, "fresh.go")]; ok || len(cache.seen) != 2 {
+		t.Fatalf("cached %v, want a.go and b.go only", cache.seen)
+	}
+
+	// Same size, mtime restored: invisible to the cache.
+	writeTree(t, root, map[string]string{"a.go": "package a\n\nfunc Z() {}\n"})
+	if err := os.Chtimes(filepath.Join(root, "a.go"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	// A new size: hashed and reparsed.
+	writeTree(t, root, map[string]string{"b.go": "package a\n\nfunc B2() {}\n"})
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	if err := cache.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadStatCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached, _, err := NewGenerator(GeneratorConfig{StatCache: loaded}).Update(base, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cached.Files["a.go"].Symbols[0].Name; got != "A" {
+		t.Errorf("cached Update reparsed a.go (%s); its size and mtime did not move", got)
+	}
+	if got := cached.Files["b.go"].Symbols[0].Name; got != "B2" {
+		t.Errorf("cached Update kept b.go's %s, want B2", got)
+	}
+	paranoid, _, err := NewGenerator(GeneratorConfig{}).Update(base, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := paranoid.Files["a.go"].Symbols[0].Name; got != "Z" {
+		t.Errorf("cacheless Update kept a.go's %s, want Z", got)
+	}
+
+	if c, err := LoadStatCache(filepath.Join(t.TempDir(), "missing.json")); err != nil || len(c.seen) != 0 {
+		t.Errorf("LoadStatCache(missing) = %v, %v; want an empty cache", c.seen, err)
+	}
+}
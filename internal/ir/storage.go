@@ -1,3 +1,12 @@
+// Package ir builds, stores, and compares the intermediate representation: a
+// repo's files, their content hashes, and the symbols declared in them.
+//
+// Every ordering in the IR — file keys, symbols, imports, refs, omissions, and
+// the root-hash input — is byte-wise comparison of UTF-8 strings, Go's native
+// string <. Not locale collation, not case folding, not UTF-16 code units
+// (which put astral-plane characters such as emoji before U+E000–U+FFFF). That
+// is what lets an implementation on another platform or in another language
+// reproduce the IR byte for byte; see the conformance package.
 package ir
 
 import (
@@ -9,6 +18,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/inth3shadows/runecho/internal/parser"
 	"github.com/inth3shadows/runecho/internal/store"
 )
 
@@ -24,25 +34,84 @@ const DefaultIRPath = ".ai/ir.json"
 // for class/struct symbols (previously located but never hashed). A loaded IR
 // with an older version must be fully regenerated, not incrementally updated —
 // Update reuses unchanged-file entries verbatim, which would leave new fields
-// (or, as of v6, newly-populated existing fields) empty/stale forever.
-const IRVersion = 6
+// (or, as of v6, newly-populated existing fields) empty/stale forever. v7
+// NFC-normalizes symbol names and refs, so entries carried over from a v6 IR
+// could keep a decomposed spelling. v8 indexes files with an overlong line by
+// hash only (FileIR.ParseSkipped); a v7 entry for such a file still carries
+// whatever the regexes extracted from it. v9 records TypeScript enums
+// (FileIR.Enums), which a v8 entry for a .ts file lacks. v10 adds
+// FileIR.ClassDetails for JS/TS classes, v11 FileIR.Decorators, v12
+// FileIR.ReExports, which Dependencies follows, v13 FileIR.Docs, v14
+// FileIR.FunctionDetails, v15 Symbol.Col, v16 the async, generator, and
+// arrow flags on FunctionDetails, v17 FileIR.Namespaces, v18
+// FileIR.Variables, v19 FileIR.ImportDetails, and v20 FileIR.TypeImports.
+// v21 indexes binary files by hash only, as v8 did minified ones. v22 parses
+// BOM-marked files decoded (FileIR.Encoding, FileIR.TextHash), so a v21
+// entry for one has symbols read from the raw bytes. v23 adds
+// FileIR.NormalizedHash and the RootHashV1LF algorithm built on it. v24
+// adds IR.Roots, the per-root hashes of a multi-root IR (GenerateRoots).
+const IRVersion = 24
 
 // IR represents the complete intermediate representation of a codebase.
 type IR struct {
-	Version  int               `json:"version"`
-	RootHash string            `json:"root_hash"`
-	Files    map[string]FileIR `json:"-"` // Excluded from direct marshalling
+	Version  int    `json:"version"`
+	RootHash string `json:"root_hash"`
+	// RootHashAlg names the algorithm RootHash was computed with (see
+	// RootHashV1); empty means RootHashV1.
+	RootHashAlg string `json:"root_hash_alg"`
+	// Roots, set on the IR of a multi-root workspace (see GenerateRoots), maps
+	// each root's key to the root hash of its files alone, keyed relative to
+	// it: what a Generate of that directory would have as its RootHash. Every
+	// file is under one of them. Nil for the IR of a single tree.
+	Roots map[string]string `json:"-"`
+	Files map[string]FileIR `json:"-"` // Excluded from direct marshalling
+	// Omissions lists what the walk could not read, sorted by path. A non-empty
+	// list means Files is partial. It is left out of RootHash on purpose: the
+	// hash identifies the content indexed, and a privileged and an unprivileged
+	// run that read the same files should agree on it.
+	Omissions []Omission `json:"-"`
+
+	// thin holds a thin IR's object ids between UnmarshalJSON and Load's
+	// resolving them (see SaveThin); nil once Files is filled in.
+	thin *thinRefs
+}
+
+// thinRefs is what a thin IR file says in place of its FileIRs.
+type thinRefs struct {
+	objects string
+	files   map[string]string
+}
+
+// OmissionUnreadable is the Omission reason for an entry the walk could not
+// stat or list.
+const OmissionUnreadable = "unreadable"
+
+// OmissionTooDeep is the Omission reason for a directory past
+// GeneratorConfig.MaxDepth, which the walk did not enter.
+const OmissionTooDeep = "too_deep"
+
+// Omission is one path the walk skipped for a reason other than an ignore rule
+// (ignored directories and path filters are not omissions). Path is an IR-style
+// key; for a directory the whole subtree is missing. Reason is a fixed word,
+// never an OS error string, so the IR stays byte-stable across platforms.
+type Omission struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
 }
 
 // Symbol is one declared symbol. Kind is function | class | export | import |
-// import_name | export_wildcard (a JS/TS bare `export * from './mod'`
-// specifier — see FileStructure.WildcardReexports). Line is the 1-based start
-// line (0 = unknown). Hash is the symbol's body hash, empty unless the parser
-// isolated a body (AST functions/methods carry it).
+// import_name | export_wildcard (a JS/TS bare `export * from './mod'`, Dart
+// `export 'uri';`, or Protobuf `import public` specifier — see FileStructure.WildcardReexports). Line is
+// the 1-based start line (0 = unknown), and Col the 1-based byte column on it
+// (0 = unknown; only the AST parsers know it). Neither is in any hash: moving
+// a symbol is not editing it, and RootHash covers file content alone. Hash is
+// the symbol's body hash, empty unless the parser isolated a body (AST
+// functions/methods carry it).
 type Symbol struct {
 	Name string `json:"name"`
 	Kind string `json:"kind"`
 	Line int    `json:"line,omitempty"`
+	Col  int    `json:"col,omitempty"`
 	Hash string `json:"hash,omitempty"`
 }
 
@@ -59,6 +128,99 @@ type FileIR struct {
 	// (guard.ExtractRefs) so edit-time validation and index-time facts can
 	// never disagree.
 	Refs []string
+	// ParseSkipped, when set, says why the file was indexed by hash alone
+	// (IR v8): its Symbols and Refs are empty because the parsers never ran,
+	// not because the file declares nothing: ParseSkippedLongLine,
+	// ParseSkippedTooLarge, or ParseSkippedBinary.
+	ParseSkipped string
+	// Encoding, when set, is how the file's bytes were decoded to the UTF-8
+	// text its parsers saw (IR v22): EncodingUTF8BOM, EncodingUTF16LE, or
+	// EncodingUTF16BE. Empty for plain UTF-8, which is read as is.
+	Encoding string
+	// TextHash is the SHA-256 of that decoded text, set with Encoding. Hash
+	// stays over the bytes on disk, as the root hash needs; TextHash is the
+	// Hash the file would have saved as plain UTF-8, so a file some editor
+	// wrote with a BOM or as UTF-16 can still be matched to its twin.
+	TextHash string
+	// NormalizedHash is the SHA-256 of the file's text — the bytes TextHash
+	// covers when Encoding is set, else the bytes on disk — with every CRLF
+	// line ending replaced by LF (IR v23). It is set only by a generator with
+	// GeneratorConfig.NormalizedRootHash, whose root hash (RootHashV1LF) is
+	// over these instead of Hash, so a checkout with CRLF line endings hashes
+	// the same as one with LF.
+	NormalizedHash string
+	// Enums are the TypeScript enums the file declares, sorted by name (IR
+	// v9). Each is also a class symbol; this says which classes are enums and
+	// which of those are `const enum`s.
+	Enums []parser.Enum
+	// Namespaces are the TypeScript namespaces and ambient module
+	// declarations the file declares, sorted by name (IR v17). Each is a
+	// class symbol.
+	Namespaces []parser.Namespace
+	// Variables are the file's top-level JS/TS bindings that are not
+	// functions or classes, sorted by name (IR v18).
+	Variables []parser.Variable
+	// ImportDetails give the bindings the file's JS/TS imports take from
+	// each module, sorted by module (IR v19).
+	ImportDetails []parser.ImportDetail
+	// TypeImports are the modules the file's TS imports take types from and
+	// nothing else, sorted (IR v20). Each is also an import symbol.
+	TypeImports []string
+	// ClassDetails describe the file's JS/TS classes — superclass, methods,
+	// and static members — sorted by name (IR v10). Each is a class symbol.
+	ClassDetails []parser.ClassDetail
+	// FunctionDetails give the parameters and declared return type of the
+	// file's JS/TS functions and methods, sorted by name (IR v14). Each is a
+	// function symbol.
+	FunctionDetails []parser.FunctionDetail
+	// Decorators are the file's decorated JS/TS classes and methods, each with
+	// its decorators in source order, sorted by kind, then name (IR v11).
+	Decorators []parser.Decorated
+	// ReExports are the module specifiers of the file's JS/TS re-exports —
+	// wildcard, namespace, and named — sorted (IR v12). A barrel file imports
+	// nothing, so these are its dependency edges (see Dependencies).
+	ReExports []string
+	// Docs maps "kind:name" to the first sentence of the symbol's JSDoc
+	// comment or Python docstring (IR v13). Only documented symbols are in it;
+	// nil when none is.
+	Docs map[string]string
+	// Manifest is the file's declared dependencies and scripts, for a package
+	// manifest (package.json, go.mod, requirements.txt); nil for every other
+	// file. See IR.Manifests.
+	Manifest *parser.Manifest
+}
+
+// ParseSkippedLongLine marks a file with a line longer than the generator's
+// cap — minified or generated code (see defaultMaxLineBytes).
+const ParseSkippedLongLine = "line_too_long"
+
+// ParseSkippedTooLarge marks a file over GeneratorConfig.MaxFileSize.
+const ParseSkippedTooLarge = "too_large"
+
+// FileIR.Encoding values.
+const (
+	EncodingUTF8BOM = "utf-8-bom" // UTF-8 after a byte-order mark, which is dropped
+	EncodingUTF16LE = "utf-16le"  // UTF-16, little-endian, after its byte-order mark
+	EncodingUTF16BE = "utf-16be"  // UTF-16, big-endian, after its byte-order mark
+)
+
+// ParseSkippedBinary marks a file whose content is not text though its name
+// says source: a NUL byte early on (see looksBinary).
+const ParseSkippedBinary = "binary"
+
+// Manifests returns the IR's dependency manifests keyed by file path: the
+// declared dependencies and scripts of every indexed package.json, go.mod, and
+// requirements file. Each also lists its dependencies as imports hashed over
+// their versions, so a snapshot diff reports a version bump as a modified
+// import; the manifests are the full record behind that.
+func (ir *IR) Manifests() map[string]parser.Manifest {
+	out := make(map[string]parser.Manifest)
+	for path, f := range ir.Files {
+		if f.Manifest != nil {
+			out[path] = *f.Manifest
+		}
+	}
+	return out
 }
 
 // namesOf returns the names of all symbols of the given kind. Symbols is kept
@@ -73,7 +235,7 @@ func (f FileIR) namesOf(kind string) []string {
 	return out
 }
 
-// sortSymbols orders symbols deterministically by kind, then name.
+// sortSymbols orders symbols deterministically by kind, then name, byte-wise.
 func sortSymbols(syms []Symbol) {
 	sort.Slice(syms, func(i, j int) bool {
 		if syms[i].Kind != syms[j].Kind {
@@ -86,15 +248,30 @@ func sortSymbols(syms []Symbol) {
 // fileIRJSON is the on-disk shape of a FileIR: the canonical `symbols` array
 // PLUS the legacy fields, kept so existing .ai/ir.json consumers do not break.
 type fileIRJSON struct {
-	Hash         string            `json:"hash"`
-	Imports      []string          `json:"imports"`
-	Functions    []string          `json:"functions"`
-	Classes      []string          `json:"classes"`
-	Exports      []string          `json:"exports"`
-	Refs         []string          `json:"refs"`
-	SymbolHashes map[string]string `json:"symbol_hashes,omitempty"`
-	SymbolLines  map[string]int    `json:"symbol_lines,omitempty"`
-	Symbols      []Symbol          `json:"symbols"`
+	Hash            string                  `json:"hash"`
+	Encoding        string                  `json:"encoding,omitempty"`
+	TextHash        string                  `json:"text_hash,omitempty"`
+	NormalizedHash  string                  `json:"normalized_hash,omitempty"`
+	Imports         []string                `json:"imports"`
+	Functions       []string                `json:"functions"`
+	Classes         []string                `json:"classes"`
+	Exports         []string                `json:"exports"`
+	Refs            []string                `json:"refs"`
+	SymbolHashes    map[string]string       `json:"symbol_hashes,omitempty"`
+	SymbolLines     map[string]int          `json:"symbol_lines,omitempty"`
+	Symbols         []Symbol                `json:"symbols"`
+	ParseSkipped    string                  `json:"parse_skipped,omitempty"`
+	Enums           []parser.Enum           `json:"enums,omitempty"`
+	Namespaces      []parser.Namespace      `json:"namespaces,omitempty"`
+	Variables       []parser.Variable       `json:"variables,omitempty"`
+	ImportDetails   []parser.ImportDetail   `json:"import_details,omitempty"`
+	TypeImports     []string                `json:"type_imports,omitempty"`
+	ClassDetails    []parser.ClassDetail    `json:"class_details,omitempty"`
+	FunctionDetails []parser.FunctionDetail `json:"function_details,omitempty"`
+	Decorators      []parser.Decorated      `json:"decorators,omitempty"`
+	ReExports       []string                `json:"reexports,omitempty"`
+	Docs            map[string]string       `json:"docs,omitempty"`
+	Manifest        *parser.Manifest        `json:"manifest,omitempty"`
 }
 
 func emptySliceIfNil[T any](s []T) []T {
@@ -120,13 +297,29 @@ func (f FileIR) MarshalJSON() ([]byte, error) {
 		}
 	}
 	out := fileIRJSON{
-		Hash:      f.Hash,
-		Imports:   emptySliceIfNil(f.namesOf("import")),
-		Functions: emptySliceIfNil(f.namesOf("function")),
-		Classes:   emptySliceIfNil(f.namesOf("class")),
-		Exports:   emptySliceIfNil(f.namesOf("export")),
-		Refs:      emptySliceIfNil(f.Refs),
-		Symbols:   emptySliceIfNil(f.Symbols),
+		Hash:           f.Hash,
+		Encoding:       f.Encoding,
+		TextHash:       f.TextHash,
+		NormalizedHash: f.NormalizedHash,
+		Imports:        emptySliceIfNil(f.namesOf("import")),
+		Functions:      emptySliceIfNil(f.namesOf("function")),
+		Classes:        emptySliceIfNil(f.namesOf("class")),
+		Exports:        emptySliceIfNil(f.namesOf("export")),
+		Refs:           emptySliceIfNil(f.Refs),
+		Symbols:        emptySliceIfNil(f.Symbols),
+
+		ParseSkipped:    f.ParseSkipped,
+		Enums:           f.Enums,
+		Namespaces:      f.Namespaces,
+		Variables:       f.Variables,
+		ImportDetails:   f.ImportDetails,
+		TypeImports:     f.TypeImports,
+		ClassDetails:    f.ClassDetails,
+		FunctionDetails: f.FunctionDetails,
+		Decorators:      f.Decorators,
+		ReExports:       f.ReExports,
+		Docs:            f.Docs,
+		Manifest:        f.Manifest,
 	}
 	if len(hashes) > 0 {
 		out.SymbolHashes = hashes
@@ -146,7 +339,22 @@ func (f *FileIR) UnmarshalJSON(data []byte) error {
 		return err
 	}
 	f.Hash = in.Hash
+	f.Encoding = in.Encoding
+	f.TextHash = in.TextHash
+	f.NormalizedHash = in.NormalizedHash
 	f.Refs = in.Refs
+	f.ParseSkipped = in.ParseSkipped
+	f.Enums = in.Enums
+	f.Namespaces = in.Namespaces
+	f.Variables = in.Variables
+	f.ImportDetails = in.ImportDetails
+	f.TypeImports = in.TypeImports
+	f.ClassDetails = in.ClassDetails
+	f.FunctionDetails = in.FunctionDetails
+	f.Decorators = in.Decorators
+	f.ReExports = in.ReExports
+	f.Docs = in.Docs
+	f.Manifest = in.Manifest
 	if len(in.Symbols) > 0 {
 		f.Symbols = in.Symbols
 	} else {
@@ -180,22 +388,34 @@ func (ir *IR) MarshalJSON() ([]byte, error) {
 	// ordering in the output is already deterministic — no need to pre-sort into
 	// a second map; marshal ir.Files directly.
 	return json.MarshalIndent(&struct {
-		Version  int               `json:"version"`
-		RootHash string            `json:"root_hash"`
-		Files    map[string]FileIR `json:"files"`
+		Version     int               `json:"version"`
+		RootHash    string            `json:"root_hash"`
+		RootHashAlg string            `json:"root_hash_alg"`
+		Roots       map[string]string `json:"roots,omitempty"`
+		Files       map[string]FileIR `json:"files"`
+		Omissions   []Omission        `json:"omissions,omitempty"`
 	}{
-		Version:  ir.Version,
-		RootHash: ir.RootHash,
-		Files:    ir.Files,
+		Version:     ir.Version,
+		RootHash:    ir.RootHash,
+		RootHashAlg: ir.RootHashAlgorithm(),
+		Roots:       ir.Roots,
+		Files:       ir.Files,
+		Omissions:   ir.Omissions,
 	}, "", "  ")
 }
 
-// UnmarshalJSON implements JSON unmarshalling for IR.
+// UnmarshalJSON implements JSON unmarshalling for IR. A thin IR (see
+// SaveThin) decodes with no Files: only Load, which knows where the file
+// came from, can resolve its objects.
 func (ir *IR) UnmarshalJSON(data []byte) error {
 	aux := &struct {
-		Version  int               `json:"version"`
-		RootHash string            `json:"root_hash"`
-		Files    map[string]FileIR `json:"files"`
+		Version     int               `json:"version"`
+		RootHash    string            `json:"root_hash"`
+		RootHashAlg string            `json:"root_hash_alg"`
+		Roots       map[string]string `json:"roots"`
+		Objects     string            `json:"objects"`
+		Files       json.RawMessage   `json:"files"`
+		Omissions   []Omission        `json:"omissions"`
 	}{}
 
 	if err := json.Unmarshal(data, aux); err != nil {
@@ -204,18 +424,51 @@ func (ir *IR) UnmarshalJSON(data []byte) error {
 
 	ir.Version = aux.Version
 	ir.RootHash = aux.RootHash
-	ir.Files = aux.Files
-
-	return nil
+	ir.RootHashAlg = aux.RootHashAlg
+	ir.Roots = aux.Roots
+	ir.Omissions = aux.Omissions
+	ir.Files, ir.thin = nil, nil
+	if len(aux.Files) == 0 {
+		return nil
+	}
+	if aux.Objects != "" {
+		ir.thin = &thinRefs{objects: aux.Objects}
+		return json.Unmarshal(aux.Files, &ir.thin.files)
+	}
+	return json.Unmarshal(aux.Files, &ir.Files)
 }
 
 // Save writes IR to a file with deterministic formatting.
-// If path is empty string, uses DefaultIRPath.
+// If path is empty string, uses DefaultIRPath. A "scheme://…" path is written
+// through the storage backend registered for that scheme (see RegisterStorage).
 func (ir *IR) Save(path string) error {
 	if path == "" {
 		path = DefaultIRPath
 	}
+	backend, path, err := resolveStorage(path)
+	if err != nil {
+		return err
+	}
+	if backend != nil {
+		data, err := json.Marshal(ir)
+		if err != nil {
+			return fmt.Errorf("failed to marshal IR: %w", err)
+		}
+		if err := backend.Write(path, data); err != nil {
+			return fmt.Errorf("failed to save IR to %s: %w", path, err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(ir)
+	if err != nil {
+		return fmt.Errorf("failed to marshal IR: %w", err)
+	}
+	return writeIRFile(path, data)
+}
 
+// writeIRFile is the local half of Save: it writes an IR's bytes to path.
+func writeIRFile(path string, data []byte) error {
 	// Ensure the parent dir exists — the DefaultIRPath default (.ai/ir.json)
 	// must work standalone, not only when the caller pre-created .ai/.
 	if dir := filepath.Dir(path); dir != "." {
@@ -227,11 +480,6 @@ func (ir *IR) Save(path string) error {
 		}
 	}
 
-	data, err := json.Marshal(ir)
-	if err != nil {
-		return fmt.Errorf("failed to marshal IR: %w", err)
-	}
-
 	// Reap temp files orphaned by a prior crash/kill between CreateTemp and Rename
 	// in store.AtomicWriteFile below. Unique temp names never self-overwrite, so
 	// these only accumulate on abnormal exit; age-gated so a live concurrent Save's
@@ -264,14 +512,25 @@ func (ir *IR) Save(path string) error {
 // Load errors, so it self-heals rather than trusting the giant file.
 const maxIRBytes = 100 << 20 // 100 MiB
 
-// Load reads IR from a file.
+// Load reads IR from a file, or from the storage backend registered for a
+// "scheme://…" path. A missing IR yields an error wrapping fs.ErrNotExist. A
+// thin IR is returned whole, its FileIRs read from its objects directory.
 func Load(path string) (*IR, error) { return loadCapped(path, maxIRBytes) }
 
 // loadCapped is Load with an explicit size limit (seam for tests). It reads at
 // most max+1 bytes so a giant file never fully buffers, then rejects if the file
 // exceeds max.
 func loadCapped(path string, max int64) (*IR, error) {
-	f, err := os.Open(path)
+	backend, path, err := resolveStorage(path)
+	if err != nil {
+		return nil, err
+	}
+	var f io.ReadCloser
+	if backend != nil {
+		f, err = backend.Read(path)
+	} else {
+		f, err = os.Open(path)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read IR file: %w", err)
 	}
@@ -289,6 +548,14 @@ func loadCapped(path string, max int64) (*IR, error) {
 	if err := json.Unmarshal(data, &ir); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal IR: %w", err)
 	}
+	if ir.thin != nil {
+		if backend != nil {
+			return nil, fmt.Errorf("IR %q: %w", path, errThinBackend)
+		}
+		if err := ir.resolveThin(path); err != nil {
+			return nil, err
+		}
+	}
 
 	return &ir, nil
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -213,6 +214,31 @@ func TestIR_SaveAndLoad_RoundTrip(t *testing.T) {
 	}
 }
 
+// TestIR_Omissions_JSON: omissions round-trip, and an IR without any encodes
+// exactly as before the field existed.
+func TestIR_Omissions_JSON(t *testing.T) {
+	full := &IR{Version: IRVersion, Files: map[string]FileIR{}, Omissions: []Omission{{Path: "secret", Reason: OmissionUnreadable}}}
+	data, err := json.Marshal(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back IR
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if len(back.Omissions) != 1 || back.Omissions[0] != full.Omissions[0] {
+		t.Errorf("Omissions after round trip = %v", back.Omissions)
+	}
+
+	bare, err := json.Marshal(&IR{Version: IRVersion, Files: map[string]FileIR{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(bare), "omissions") {
+		t.Errorf("IR without omissions encodes the key: %s", bare)
+	}
+}
+
 func TestIR_Save_Determinism(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -1,6 +1,13 @@
 package ir
 
-import "sort"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
 
 // SymbolLoc is one symbol's location — a deterministic projection of FileIR.
 // Both `runecho-ir map` (CLI) and the MCP `locate` tool render this same shape
@@ -46,3 +53,80 @@ func (ir *IR) SymbolLocations() []SymbolLoc {
 	})
 	return out
 }
+
+// SymbolID returns the derived stable identifier for a symbol: a short SHA-256
+// over path, kind, and name (NUL-separated, so "a:b" + "c" can never collide
+// with "a" + "b:c"). It is a pure function of the key, so two runs — or two
+// machines — agree on a symbol's ID without shared state. It is the ID a symbol
+// gets the first time it is seen; the snapshot store carries an earlier ID
+// forward across a rename or move (see snapshot.StableIDs), so consumers keying
+// annotations or embeddings to a symbol should read the persisted ID, not
+// recompute this one.
+func SymbolID(path, kind, name string) string {
+	h := sha256.Sum256([]byte(path + "\x00" + kind + "\x00" + name))
+	return hex.EncodeToString(h[:8])
+}
+
+// namedDefaults returns syms plus, for an anonymous JS/TS default export —
+// the function or class symbol "default" — the same symbol under the name
+// the file at key suggests (see defaultNameFor), as importers tend to bind
+// it. syms is not modified.
+func namedDefaults(syms []Symbol, key string) []Symbol {
+	name := defaultNameFor(key)
+	if name == "" {
+		return syms
+	}
+	var named []Symbol
+	for _, s := range syms {
+		if s.Name != "default" || (s.Kind != "function" && s.Kind != "class") {
+			continue
+		}
+		if containsSymbol(syms, s.Kind, name) {
+			continue
+		}
+		s.Name = name
+		named = append(named, s)
+	}
+	if named == nil {
+		return syms
+	}
+	out := append(append(make([]Symbol, 0, len(syms)+len(named)), syms...), named...)
+	sortSymbols(out)
+	return out
+}
+
+// defaultNameFor returns the name an anonymous default export of the file at
+// key is known by: the file's name without its extension, or for an index
+// file its directory's name (src/Button/index.tsx is Button). It is "" when
+// that is not a JS identifier, or for an index file at the root, whose
+// directory's name depends on where the tree is checked out.
+func defaultNameFor(key string) string {
+	base := path.Base(key)
+	name := strings.TrimSuffix(base, path.Ext(base))
+	if name == "index" {
+		dir := path.Dir(key)
+		if dir == "." {
+			return ""
+		}
+		name = path.Base(dir)
+	}
+	if name == "default" {
+		return ""
+	}
+	for i, r := range name {
+		if !(r == '_' || r == '$' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return ""
+		}
+	}
+	return name
+}
+
+// containsSymbol reports whether syms has the symbol kind:name.
+func containsSymbol(syms []Symbol, kind, name string) bool {
+	for _, s := range syms {
+		if s.Kind == kind && s.Name == name {
+			return true
+		}
+	}
+	return false
+}
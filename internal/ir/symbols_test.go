@@ -52,3 +52,26 @@ func TestSymbolLocations_KindTiebreaker(t *testing.T) {
 		}
 	}
 }
+
+// TestSymbolID_DeterministicAndKeyed pins that the derived ID is a pure function
+// of (path, kind, name): equal keys agree, and changing any one component — or
+// shifting a separator between components — yields a different ID.
+func TestSymbolID_DeterministicAndKeyed(t *testing.T) {
+	base := SymbolID("src/a.ts", "function", "foo")
+	if base != SymbolID("src/a.ts", "function", "foo") {
+		t.Fatal("SymbolID not deterministic for an identical key")
+	}
+	if len(base) != 16 {
+		t.Errorf("SymbolID length = %d, want 16 hex chars", len(base))
+	}
+	for _, other := range []string{
+		SymbolID("src/b.ts", "function", "foo"),
+		SymbolID("src/a.ts", "class", "foo"),
+		SymbolID("src/a.ts", "function", "bar"),
+		SymbolID("src/a.ts\x00function", "", "foo"),
+	} {
+		if other == base {
+			t.Errorf("distinct key produced the same ID %s", base)
+		}
+	}
+}
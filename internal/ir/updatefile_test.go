@@ -1,6 +1,7 @@
 package ir
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -128,3 +129,109 @@ func TestUpdateFile_SkipsSymlink(t *testing.T) {
 		t.Errorf("file under a symlinked dir must be skipped: changed=%v err=%v", changed, err)
 	}
 }
+
+// TestUpdatePaths refreshes an add, a modify, a delete, and an unchanged file
+// in one batch; the result must match a full Generate.
+func TestUpdatePaths(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("a.go", "package x\n\nfunc Alpha() {}\n")
+	write("b.go", "package x\n\nfunc Beta() {}\n")
+	write("c.go", "package x\n\nfunc Gamma() {}\n")
+	gen := NewGenerator(GeneratorConfig{})
+	base, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	write("a.go", "package x\n\nfunc Alpha2() {}\n")
+	write("d.go", "package x\n\nfunc Delta() {}\n")
+	if err := os.Remove(filepath.Join(root, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, name := range []string{"a.go", "b.go", "c.go", "d.go"} {
+		paths = append(paths, filepath.Join(root, name))
+	}
+	updated, changed, err := gen.UpdatePaths(base, root, paths)
+	if err != nil || !changed {
+		t.Fatalf("UpdatePaths: changed=%v err=%v", changed, err)
+	}
+	if full, _, _ := gen.Generate(root); updated.RootHash != full.RootHash {
+		t.Errorf("RootHash %s != full generate %s", updated.RootHash, full.RootHash)
+	}
+	if len(base.Files) != 3 || base.Files["a.go"].Hash == updated.Files["a.go"].Hash {
+		t.Error("UpdatePaths modified its input IR")
+	}
+	if _, changed, _ := gen.UpdatePaths(updated, root, paths); changed {
+		t.Error("a batch with nothing new reported a change")
+	}
+}
+
+// TestUpdateSingleFile drives the on-save fast path through a run of edits,
+// checking after each that the IR, updated in place, matches a full Generate —
+// the cached root-hash preimage included — and that the cases it hands to
+// UpdateFile's path (new and deleted files) land the same way.
+func TestUpdateSingleFile(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("a.go", "package x\n\nfunc Alpha() {}\n")
+	write("pkg/b.go", "package pkg\n\nfunc Beta() {}\n")
+	gen := NewGenerator(GeneratorConfig{})
+	irData, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	check := func(step string, wantChanged, changed bool, err error) {
+		t.Helper()
+		if err != nil || changed != wantChanged {
+			t.Fatalf("%s: changed=%v err=%v, want changed=%v", step, changed, err, wantChanged)
+		}
+		full, _, _ := gen.Generate(root)
+		if !equalIR(irData, full) {
+			t.Errorf("%s: IR differs from a full generate (root hash %s, want %s)", step, irData.RootHash, full.RootHash)
+		}
+	}
+
+	for i, body := range []string{"func Beta2() {}", "func Beta3() {}", "func B() {}"} {
+		write("pkg/b.go", "package pkg\n\n"+body+"\n")
+		changed, err := gen.UpdateSingleFile(irData, root, "pkg/b.go")
+		check(fmt.Sprintf("edit %d", i), true, changed, err)
+	}
+	changed, err := gen.UpdateSingleFile(irData, root, "pkg/b.go")
+	check("unchanged save", false, changed, err)
+	write("a.go", "package x\n\nfunc Alpha2() {}\n")
+	changed, err = gen.UpdateSingleFile(irData, root, filepath.Join("pkg", "..", "a.go"))
+	check("OS-form path", true, changed, err)
+
+	write("pkg/c.go", "package pkg\n\nfunc Gamma() {}\n")
+	changed, err = gen.UpdateSingleFile(irData, root, "pkg/c.go")
+	check("new file", true, changed, err)
+	if err := os.Remove(filepath.Join(root, "a.go")); err != nil {
+		t.Fatal(err)
+	}
+	changed, err = gen.UpdateSingleFile(irData, root, "a.go")
+	check("deleted file", true, changed, err)
+	write("pkg/c.go", "package pkg\n\nfunc Gamma2() {}\n")
+	changed, err = gen.UpdateSingleFile(irData, root, "pkg/c.go")
+	check("edit after the key set changed", true, changed, err)
+
+	if _, err := gen.UpdateSingleFile(irData, root, "../elsewhere.go"); err == nil {
+		t.Error("a path outside the root was accepted")
+	}
+	old := &IR{Version: IRVersion - 1, Files: map[string]FileIR{}}
+	if _, err := gen.UpdateSingleFile(old, root, "pkg/c.go"); err == nil {
+		t.Error("an IR of another version was accepted")
+	}
+}
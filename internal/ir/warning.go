@@ -0,0 +1,120 @@
+package ir
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// WarningKind is the category of a Warning.
+type WarningKind string
+
+// Warning kinds.
+const (
+	// WarnConfig: a GeneratorConfig value was ignored (an unknown parser
+	// backend, an extension mapped to no parser).
+	WarnConfig WarningKind = "config"
+	// WarnAccess: the walk could not read a file or directory; a directory
+	// or file it could not list or stat is also an Omission.
+	WarnAccess WarningKind = "access"
+	// WarnParse: a supported file failed to parse and is not in the IR
+	// (Stats.ParseErrors), or parsed only in part and is in it with symbols
+	// missing (parser.FileStructure.Diagnostics).
+	WarnParse WarningKind = "parse"
+	// WarnHashOnly: a file is indexed by hash alone (FileIR.ParseSkipped).
+	WarnHashOnly WarningKind = "hash_only"
+	// WarnPathCollision: a file is left out because another file's path
+	// normalizes to the same key (Stats.PathCollisions).
+	WarnPathCollision WarningKind = "path_collision"
+	// WarnCaseCollision: an indexed path differs only in case from another
+	// (Stats.CaseCollisions, GeneratorConfig.StrictCase).
+	WarnCaseCollision WarningKind = "case_collision"
+	// WarnSymlinkCycle: a symlink leading back into the directories above it
+	// was not followed (GeneratorConfig.FollowSymlinks).
+	WarnSymlinkCycle WarningKind = "symlink_cycle"
+	// WarnObjectStore: GeneratorConfig.Objects failed and parse results are
+	// not being cached.
+	WarnObjectStore WarningKind = "object_store"
+)
+
+// A Warning is one non-fatal diagnostic from building an IR.
+type Warning struct {
+	Kind WarningKind
+	// Path is the file or directory concerned, absolute (for GenerateFS, its
+	// name in the fs.FS), or "" when the warning is about no single path.
+	Path    string
+	Message string // one line, no trailing newline
+}
+
+// String is the warning as the Generator writes it to stderr: a WarnHashOnly
+// is a note, the rest warnings.
+func (w Warning) String() string {
+	if w.Kind == WarnHashOnly {
+		return "Note: " + w.Message
+	}
+	return "Warning: " + w.Message
+}
+
+// logWarnings returns a warning sink that logs to l (see
+// GeneratorConfig.Logger).
+func logWarnings(l *slog.Logger) func(Warning) {
+	return func(w Warning) {
+		level := slog.LevelWarn
+		if w.Kind == WarnHashOnly {
+			level = slog.LevelInfo
+		}
+		attrs := []slog.Attr{slog.String("kind", string(w.Kind))}
+		if w.Path != "" {
+			attrs = append(attrs, slog.String("path", w.Path))
+		}
+		l.LogAttrs(context.Background(), level, w.Message, attrs...)
+	}
+}
+
+// Result is a walk's IR together with what it reported along the way.
+type Result struct {
+	IR    *IR
+	Stats Stats
+	// Warnings are the walk's, in the order they arose — the order
+	// Generate reports them in — and are not reported anywhere else.
+	Warnings []Warning
+}
+
+// warnf raises a Warning of kind about path.
+func (g *Generator) warnf(kind WarningKind, path, format string, args ...any) {
+	g.warn(Warning{Kind: kind, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// collecting returns a copy of g whose warnings are appended to *ws instead
+// of written.
+func (g *Generator) collecting(ws *[]Warning) *Generator {
+	c := *g
+	c.warn = func(w Warning) { *ws = append(*ws, w) }
+	return &c
+}
+
+// GenerateResult is GenerateCtx, returning the walk's warnings in the Result
+// rather than writing them to stderr or GeneratorConfig.Logger: for an embedder that shows, filters, or
+// counts them itself. Warnings about the GeneratorConfig are raised once, by
+// NewGenerator, and are not repeated here.
+func (g *Generator) GenerateResult(ctx context.Context, rootPath string) (*Result, error) {
+	res := new(Result)
+	irData, stats, err := g.collecting(&res.Warnings).GenerateCtx(ctx, rootPath)
+	if err != nil {
+		return nil, err
+	}
+	res.IR, res.Stats = irData, stats
+	return res, nil
+}
+
+// UpdateResult is UpdateCtx with its warnings collected the way
+// GenerateResult collects them.
+func (g *Generator) UpdateResult(ctx context.Context, existingIR *IR, rootPath string) (*Result, error) {
+	res := new(Result)
+	irData, stats, err := g.collecting(&res.Warnings).UpdateCtx(ctx, existingIR, rootPath)
+	if err != nil {
+		return nil, err
+	}
+	res.IR, res.Stats = irData, stats
+	return res, nil
+}
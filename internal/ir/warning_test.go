@@ -0,0 +1,128 @@
+package ir
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestGenerateResult: the walk's warnings come back typed, in walk order,
+// and never reach the Generator's own sink; UpdateResult collects the same
+// way, for the files it reads.
+func TestGenerateResult(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.go":      "package a\x00\n",
+		"b/big.js":  "function big() {}\n" + "var x = 1;\n",
+		"main.go":   "package main\n",
+		"z/zero.py": "x = 1\x00\n",
+	})
+	gen := NewGenerator(GeneratorConfig{MaxFileSize: 20})
+	written := captureWarnings(gen)
+	res, err := gen.GenerateResult(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Warning{
+		{Kind: WarnHashOnly, Path: filepath.Join(root, "a.go")},
+		{Kind: WarnHashOnly, Path: filepath.Join(root, "b", "big.js")},
+		{Kind: WarnHashOnly, Path: filepath.Join(root, "z", "zero.py")},
+	}
+	var got []Warning
+	for _, w := range res.Warnings {
+		if w.Message == "" {
+			t.Errorf("%+v has no message", w)
+		}
+		got = append(got, Warning{Kind: w.Kind, Path: w.Path})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings = %+v, want %+v", got, want)
+	}
+	if res.IR == nil || res.Stats.Indexed != 4 || res.Stats.ParseSkipped != 3 {
+		t.Errorf("result = %+v", res)
+	}
+	if len(*written) != 0 {
+		t.Errorf("GenerateResult wrote %q", *written)
+	}
+
+	writeTree(t, root, map[string]string{"a.go": "package a\n", "c.go": "package c\x00\n"})
+	up, err := gen.UpdateResult(context.Background(), res.IR, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(up.Warnings) != 1 || up.Warnings[0].Path != filepath.Join(root, "c.go") || up.Stats.ParseSkipped != 3 || len(*written) != 0 {
+		t.Errorf("UpdateResult warnings %+v, stats %+v, wrote %q", up.Warnings, up.Stats, *written)
+	}
+}
+
+func TestWarning_String(t *testing.T) {
+	if got := (Warning{Kind: WarnHashOnly, Message: "m"}).String(); got != "Note: m" {
+		t.Errorf("hash-only warning = %q", got)
+	}
+	if got := (Warning{Kind: WarnParse, Message: "m"}).String(); got != "Warning: m" {
+		t.Errorf("parse warning = %q", got)
+	}
+}
+
+// TestGeneratorConfig_Logger: with a Logger, warnings — the config's included
+// — are records with their kind and path, a note at info level.
+func TestGeneratorConfig_Logger(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a.go": "package a\x00\n", "b.go": "package b\n"})
+	var buf bytes.Buffer
+	gen := NewGenerator(GeneratorConfig{
+		ParserBackend: "acorn",
+		Logger:        slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: dropTime})),
+	})
+	if _, _, err := gen.Generate(root); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		`level=WARN msg="unknown parser backend \"acorn\"; using the default" kind=config`,
+		`level=INFO msg="` + filepath.Join(root, "a.go") + ` is not text; indexing its hash only" kind=hash_only path=` + filepath.Join(root, "a.go"),
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("logged\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func dropTime(_ []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
+
+// TestGenerateResult_ParseDiagnostics: a file a parser gave up on part of is
+// indexed, and its parser's diagnostic comes back as a WarnParse for the file
+// instead of reaching stderr, so a caller can tell missing symbols from
+// absent ones.
+func TestGenerateResult_ParseDiagnostics(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"Bad.java":  "class Bad { int x = " + strings.Repeat("(", 1001) + "; }\n",
+		"Good.java": "class Good {}\n",
+	})
+	gen := NewGenerator(GeneratorConfig{})
+	written := captureWarnings(gen)
+	res, err := gen.GenerateResult(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := filepath.Join(root, "Bad.java")
+	if len(res.Warnings) != 1 || res.Warnings[0].Kind != WarnParse || res.Warnings[0].Path != bad ||
+		!strings.Contains(res.Warnings[0].Message, bad) || !strings.Contains(res.Warnings[0].Message, "Java") {
+		t.Errorf("warnings = %+v, want one WarnParse for %s", res.Warnings, bad)
+	}
+	if _, ok := res.IR.Files["Bad.java"]; !ok || res.Stats.Indexed != 2 || res.Stats.ParseErrors != 0 {
+		t.Errorf("files %v, stats %+v: a partial parse is still indexed", indexedPaths(res.IR), res.Stats)
+	}
+	if len(*written) != 0 {
+		t.Errorf("GenerateResult wrote %q", *written)
+	}
+}
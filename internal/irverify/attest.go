@@ -0,0 +1,213 @@
+package irverify
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// An attestation is an in-toto Statement about an IR file. Its subject is the
+// file, by SHA-256, and its predicate says how the file was made: the runecho
+// version and IR format, the root hash, the digest of the repo config, and the
+// commit the tree was at. Anyone can then check out that commit, run that
+// runecho with that config, and expect the same root hash. Unsigned, the file
+// holds the Statement itself; signed, a DSSE envelope around it, with an
+// Ed25519 signature over DSSE's pre-authentication encoding of the payload.
+
+// The in-toto and DSSE type strings an attestation uses.
+const (
+	StatementType = "https://in-toto.io/Statement/v1"
+	PredicateType = "https://github.com/inth3shadows/runecho/attestation/ir/v1"
+	PayloadType   = "application/vnd.in-toto+json"
+)
+
+// AttestationFile returns where the attestation of the IR at irPath lives.
+func AttestationFile(irPath string) string { return irPath + ".intoto.json" }
+
+// Statement is an in-toto v1 Statement with a runecho IR predicate.
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject names an attested file by digest.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate records how an IR was produced.
+type Predicate struct {
+	Generator   Generator `json:"generator"`
+	RootHash    string    `json:"root_hash"`
+	RootHashAlg string    `json:"root_hash_alg"`
+	// Config is the SHA-256 of the repo's config file (.runecho.json or
+	// .runecho.yml) as it was read; a repo without one has the digest of
+	// empty content under the name .runecho.json.
+	Config Subject `json:"config"`
+	Source Source  `json:"source"`
+}
+
+// Generator identifies the runecho that wrote the IR.
+type Generator struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	IRVersion int    `json:"ir_version"`
+}
+
+// Source is the commit the indexed tree matched.
+type Source struct {
+	Revision string `json:"revision"`
+	// Path is the indexed root within the repository, "." for its top.
+	Path string `json:"path"`
+}
+
+// NewStatement returns the Statement for the IR file content data, saved at
+// name, with predicate p.
+func NewStatement(name string, data []byte, p Predicate) Statement {
+	return Statement{
+		Type:          StatementType,
+		Subject:       []Subject{{Name: name, Digest: map[string]string{"sha256": sha256Hex(data)}}},
+		PredicateType: PredicateType,
+		Predicate:     p,
+	}
+}
+
+// envelope is a DSSE envelope.
+type envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []signature `json:"signatures"`
+}
+
+type signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// MarshalAttestation returns the attestation file content for st: the
+// Statement, or with a key, a DSSE envelope signed by it.
+func MarshalAttestation(st Statement, key ed25519.PrivateKey) ([]byte, error) {
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+	var out any = st
+	if key != nil {
+		out = envelope{
+			PayloadType: PayloadType,
+			Payload:     base64.StdEncoding.EncodeToString(payload),
+			Signatures: []signature{{
+				KeyID: keyID(key.Public().(ed25519.PublicKey)),
+				Sig:   base64.StdEncoding.EncodeToString(ed25519.Sign(key, pae(PayloadType, payload))),
+			}},
+		}
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// ReadAttestation decodes attestation file content. With a key, only a DSSE
+// envelope carrying that key's signature is accepted; without one, any
+// signatures are not checked.
+func ReadAttestation(data []byte, key ed25519.PublicKey) (*Statement, error) {
+	var probe struct {
+		PayloadType *string `json:"payloadType"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	payload := data
+	if probe.PayloadType != nil {
+		var env envelope
+		if err := strictDecode(data, &env); err != nil {
+			return nil, fmt.Errorf("DSSE envelope: %w", err)
+		}
+		if env.PayloadType != PayloadType {
+			return nil, fmt.Errorf("DSSE payload type %q, want %q", env.PayloadType, PayloadType)
+		}
+		var err error
+		if payload, err = base64.StdEncoding.DecodeString(env.Payload); err != nil {
+			return nil, fmt.Errorf("DSSE payload: %w", err)
+		}
+		if key != nil && !signedBy(env, payload, key) {
+			return nil, errors.New("no signature by the given key")
+		}
+	} else if key != nil {
+		return nil, errors.New("attestation is not signed")
+	}
+	var st Statement
+	if err := strictDecode(payload, &st); err != nil {
+		return nil, fmt.Errorf("statement: %w", err)
+	}
+	if st.Type != StatementType || st.PredicateType != PredicateType {
+		return nil, fmt.Errorf("not a runecho IR attestation (%s, %s)", st.Type, st.PredicateType)
+	}
+	if len(st.Subject) != 1 {
+		return nil, fmt.Errorf("%d subjects, want 1", len(st.Subject))
+	}
+	return &st, nil
+}
+
+func signedBy(env envelope, payload []byte, key ed25519.PublicKey) bool {
+	msg := pae(env.PayloadType, payload)
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err == nil && len(sig) == ed25519.SignatureSize && ed25519.Verify(key, msg, sig) {
+			return true
+		}
+	}
+	return false
+}
+
+// pae is DSSE's pre-authentication encoding of a payload.
+func pae(payloadType string, payload []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("DSSEv1 " + strconv.Itoa(len(payloadType)) + " " + payloadType + " " + strconv.Itoa(len(payload)) + " ")
+	b.Write(payload)
+	return b.Bytes()
+}
+
+// keyID names a public key by the SHA-256 of its bytes.
+func keyID(key ed25519.PublicKey) string { return sha256Hex(key) }
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkAttestation checks that the attestation at path is about the IR file
+// content data, whose top level is doc.
+func checkAttestation(r *Report, path string, data []byte, doc document, key ed25519.PublicKey) {
+	raw, err := readCapped(path)
+	if err != nil {
+		r.problem("attestation: %v", err)
+		return
+	}
+	st, err := ReadAttestation(raw, key)
+	if err != nil {
+		r.problem("attestation %s: %v", path, err)
+		return
+	}
+	p := st.Predicate
+	switch {
+	case st.Subject[0].Digest["sha256"] != sha256Hex(data):
+		r.problem("attestation: its subject is not this IR file")
+	case p.RootHash != doc.RootHash || p.RootHashAlg != doc.RootHashAlg || p.Generator.IRVersion != doc.Version:
+		r.problem("attestation: predicate says %s/%s v%d, the IR has %s/%s v%d", p.RootHash, p.RootHashAlg, p.Generator.IRVersion, doc.RootHash, doc.RootHashAlg, doc.Version)
+	default:
+		r.Attestation = &p
+		r.Signed = r.Signed || key != nil
+	}
+}
@@ -0,0 +1,81 @@
+package irverify
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// An IR is signed with a detached Ed25519 signature over the file's exact
+// bytes, kept next to it in SignatureFile(irPath) as one line of standard
+// base64. A thin IR's signature covers its objects too: the file names each
+// by the hash of its content. Keys are PEM files, PKCS #8 for the private key
+// and PKIX for the public one — what `openssl genpkey -algorithm ed25519`
+// and `openssl pkey -pubout` write.
+
+// SignatureFile returns where the signature of the IR at irPath lives.
+func SignatureFile(irPath string) string { return irPath + ".sig" }
+
+// Sign returns the signature file content for data.
+func Sign(data []byte, key ed25519.PrivateKey) []byte {
+	sig := ed25519.Sign(key, data)
+	return []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+// checkSignature verifies data against the signature file for irPath.
+func checkSignature(r *Report, irPath string, data []byte, key ed25519.PublicKey) bool {
+	raw, err := os.ReadFile(SignatureFile(irPath))
+	if err != nil {
+		r.problem("signature: %v", err)
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(raw)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		r.problem("signature: %s is not a base64 Ed25519 signature", SignatureFile(irPath))
+		return false
+	}
+	if !ed25519.Verify(key, data, sig) {
+		r.problem("signature: does not match the IR and key")
+		return false
+	}
+	return true
+}
+
+// ParsePublicKey reads an Ed25519 public key from PEM.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("public key: no PEM block")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("public key: %w", err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key: %T is not Ed25519", key)
+	}
+	return pub, nil
+}
+
+// ParsePrivateKey reads an Ed25519 private key from PEM.
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key: no PEM block")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("private key: %w", err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key: %T is not Ed25519", key)
+	}
+	return priv, nil
+}
@@ -0,0 +1,741 @@
+// Package irverify checks a saved IR without generating one: that the file is
+// in the exact canonical form the generator writes, that its hashes agree, and
+// that it carries a valid signature. It is what runecho-verify-ir runs, for
+// consumers that must trust an IR but must not execute parsing logic, so it
+// imports only the standard library — never internal/ir, which pulls in every
+// parser. The on-disk format is restated here; a test in this package checks
+// it against internal/ir's own output, so the two cannot drift silently.
+package irverify
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// IRVersion is the IR format version this package verifies.
+const IRVersion = 24
+
+// The root-hash algorithms this package knows (see ir.RootHashV1 and
+// ir.RootHashV1LF).
+const (
+	RootHashV1   = "v1"
+	RootHashV1LF = "v1-lf"
+)
+
+// maxIRBytes matches ir.Load's read cap.
+const maxIRBytes = 100 << 20
+
+// Options selects the optional checks.
+type Options struct {
+	// PublicKey, when set, requires a valid signature (see SignatureFile) —
+	// or, with Attestation, a valid signature on the attestation, whose
+	// subject digest covers the IR.
+	PublicKey ed25519.PublicKey
+	// Attestation, when set, is the path of an attestation (see
+	// AttestationFile) that must be about this IR file.
+	Attestation string
+	// Source, when set, is a checkout the IR should describe: every indexed
+	// file is re-hashed from it. Nothing is parsed, and files the IR does not
+	// list are not looked for.
+	Source string
+}
+
+// Report is the outcome of Verify. The IR is good when Problems is empty.
+type Report struct {
+	Path     string
+	Version  int
+	RootHash string
+	Files    int
+	// Thin is set for an IR whose entries are objects (see ir.SaveThin).
+	Thin bool
+	// Signed is set once the signature has been checked and found valid.
+	Signed bool
+	// Attestation is the predicate of an attestation found to be about the IR.
+	Attestation *Predicate
+	// SourceChecked counts the files re-hashed from Options.Source.
+	SourceChecked int
+	Problems      []string
+}
+
+func (r *Report) problem(format string, args ...any) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// document is a saved IR's top level, fat or thin.
+type document struct {
+	Version     int               `json:"version"`
+	RootHash    string            `json:"root_hash"`
+	RootHashAlg string            `json:"root_hash_alg"`
+	Roots       map[string]string `json:"roots"`
+	Objects     string            `json:"objects"`
+	Files       json.RawMessage   `json:"files"`
+	Omissions   []omission        `json:"omissions"`
+}
+
+type omission struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type symbol struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Line int    `json:"line,omitempty"`
+	Col  int    `json:"col,omitempty"`
+	Hash string `json:"hash,omitempty"`
+}
+
+// fileEntry is one file's entry, field for field as ir.FileIR marshals it.
+type fileEntry struct {
+	Hash            string            `json:"hash"`
+	Encoding        string            `json:"encoding,omitempty"`
+	TextHash        string            `json:"text_hash,omitempty"`
+	NormalizedHash  string            `json:"normalized_hash,omitempty"`
+	Imports         []string          `json:"imports"`
+	Functions       []string          `json:"functions"`
+	Classes         []string          `json:"classes"`
+	Exports         []string          `json:"exports"`
+	Refs            []string          `json:"refs"`
+	SymbolHashes    map[string]string `json:"symbol_hashes,omitempty"`
+	SymbolLines     map[string]int    `json:"symbol_lines,omitempty"`
+	Symbols         []symbol          `json:"symbols"`
+	ParseSkipped    string            `json:"parse_skipped,omitempty"`
+	Enums           []enum            `json:"enums,omitempty"`
+	Namespaces      []namespace       `json:"namespaces,omitempty"`
+	Variables       []variable        `json:"variables,omitempty"`
+	ImportDetails   []importDetail    `json:"import_details,omitempty"`
+	TypeImports     []string          `json:"type_imports,omitempty"`
+	ClassDetails    []classDetail     `json:"class_details,omitempty"`
+	FunctionDetails []functionDetail  `json:"function_details,omitempty"`
+	Decorators      []decorated       `json:"decorators,omitempty"`
+	ReExports       []string          `json:"reexports,omitempty"`
+	Docs            map[string]string `json:"docs,omitempty"`
+	Manifest        *manifest         `json:"manifest,omitempty"`
+}
+
+// classDetail is a JS/TS class's entry, as parser.ClassDetail marshals it.
+type classDetail struct {
+	Name    string   `json:"name"`
+	Extends string   `json:"extends,omitempty"`
+	Methods []string `json:"methods,omitempty"`
+	Static  []string `json:"static,omitempty"`
+}
+
+// functionDetail is a JS/TS function's entry, as parser.FunctionDetail
+// marshals it.
+type functionDetail struct {
+	Name      string   `json:"name"`
+	Params    []string `json:"params,omitempty"`
+	Returns   string   `json:"returns,omitempty"`
+	Async     bool     `json:"async,omitempty"`
+	Generator bool     `json:"generator,omitempty"`
+	Arrow     bool     `json:"arrow,omitempty"`
+}
+
+// decorated is a decorated JS/TS class or method's entry, as parser.Decorated
+// marshals it.
+type decorated struct {
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Decorators []string `json:"decorators"`
+}
+
+// enum is a TypeScript enum's entry, as parser.Enum marshals it.
+type enum struct {
+	Name  string `json:"name"`
+	Const bool   `json:"const,omitempty"`
+}
+
+// namespace is a TypeScript namespace's entry, as parser.Namespace marshals
+// it.
+type namespace struct {
+	Name    string `json:"name"`
+	Ambient bool   `json:"ambient,omitempty"`
+}
+
+// variable is a top-level binding's entry, as parser.Variable marshals it.
+type variable struct {
+	Name  string `json:"name"`
+	Const bool   `json:"const,omitempty"`
+}
+
+// importDetail is an imported module's entry, as parser.ImportDetail
+// marshals it.
+type importDetail struct {
+	Module    string   `json:"module"`
+	Default   string   `json:"default,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
+	Named     []string `json:"named,omitempty"`
+}
+
+// manifest is a package manifest's entry, as parser.Manifest marshals it.
+type manifest struct {
+	Ecosystem    string `json:"ecosystem"`
+	Name         string `json:"name,omitempty"`
+	Dependencies []struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+		Scope   string `json:"scope"`
+	} `json:"dependencies"`
+	Scripts []struct {
+		Name    string `json:"name"`
+		Command string `json:"command"`
+	} `json:"scripts,omitempty"`
+}
+
+// Verify checks the IR file at irPath and returns what it found. The error is
+// for a file that could not be read at all; everything wrong with its content
+// is a Problem.
+func Verify(irPath string, opts Options) (*Report, error) {
+	data, err := readCapped(irPath)
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{Path: irPath}
+	if opts.PublicKey != nil && opts.Attestation == "" {
+		r.Signed = checkSignature(r, irPath, data, opts.PublicKey)
+	}
+
+	var doc document
+	if err := strictDecode(data, &doc); err != nil {
+		r.problem("not an IR: %v", err)
+		return r, nil
+	}
+	r.Version, r.RootHash, r.Thin = doc.Version, doc.RootHash, doc.Objects != ""
+	if doc.Version != IRVersion {
+		r.problem("IR version %d, this verifier checks version %d", doc.Version, IRVersion)
+		return r, nil
+	}
+	if opts.Attestation != "" {
+		checkAttestation(r, opts.Attestation, data, doc, opts.PublicKey)
+	}
+
+	hashes := make(map[string]string)     // file key → content hash
+	normalized := make(map[string]string) // file key → normalized hash, where set
+	if r.Thin {
+		var ids map[string]string
+		if err := strictDecode(doc.Files, &ids); err != nil {
+			r.problem("files: %v", err)
+			return r, nil
+		}
+		objDir := filepath.Join(filepath.Dir(irPath), filepath.FromSlash(doc.Objects))
+		if !filepath.IsLocal(filepath.FromSlash(doc.Objects)) {
+			r.problem("objects directory %q is outside the IR's directory", doc.Objects)
+			return r, nil
+		}
+		for key, id := range ids {
+			if f, ok := readObject(r, objDir, key, id); ok {
+				hashes[key] = f.Hash
+				if f.NormalizedHash != "" {
+					normalized[key] = f.NormalizedHash
+				}
+			}
+		}
+		checkCanonical(r, data, thinForm(doc, ids))
+	} else {
+		var files map[string]fileEntry
+		if err := strictDecode(doc.Files, &files); err != nil {
+			r.problem("files: %v", err)
+			return r, nil
+		}
+		canon := make(map[string]fileEntry, len(files))
+		for key, f := range files {
+			canon[key] = checkEntry(r, key, f)
+			hashes[key] = f.Hash
+			if f.NormalizedHash != "" {
+				normalized[key] = f.NormalizedHash
+			}
+		}
+		checkCanonical(r, data, fatForm(doc, canon))
+	}
+	r.Files = len(hashes)
+
+	for key := range hashes {
+		if !validKey(key) {
+			r.problem("file key %q is not a relative path inside the root", key)
+		}
+	}
+	if !sort.SliceIsSorted(doc.Omissions, func(i, j int) bool { return doc.Omissions[i].Path < doc.Omissions[j].Path }) {
+		r.problem("omissions are not sorted by path")
+	}
+	for _, o := range doc.Omissions {
+		if !validKey(o.Path) || o.Reason == "" {
+			r.problem("omission %+v is malformed", o)
+		}
+	}
+	var over map[string]string // the hashes root_hash is over, when they are all there
+	switch alg := doc.RootHashAlg; alg {
+	case RootHashV1:
+		over = hashes
+	case RootHashV1LF:
+		for _, key := range slices.Sorted(maps.Keys(hashes)) {
+			if _, ok := normalized[key]; !ok {
+				r.problem("%s: no normalized_hash, which root_hash_alg %s is over", key, alg)
+			}
+		}
+		if len(normalized) == len(hashes) {
+			over = normalized
+		}
+	default:
+		r.problem("unknown root_hash_alg %q", alg)
+	}
+	if over != nil {
+		if got := rootHashV1(over); got != doc.RootHash {
+			r.problem("root_hash %s does not match its files (computes %s)", doc.RootHash, got)
+		}
+		checkRoots(r, doc.Roots, over)
+	}
+	if opts.Source != "" {
+		checkSource(r, opts.Source, hashes)
+	}
+	return r, nil
+}
+
+func readCapped(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxIRBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxIRBytes {
+		return nil, fmt.Errorf("%s exceeds the %d-byte IR size cap", name, maxIRBytes)
+	}
+	return data, nil
+}
+
+// strictDecode decodes one JSON value, refusing unknown fields and trailing
+// data: the canonical form has neither.
+func strictDecode(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("trailing data")
+	}
+	return nil
+}
+
+// checkEntry checks one file entry's invariants and returns its canonical
+// form: the legacy arrays and maps rebuilt from Symbols, as ir.FileIR's
+// MarshalJSON derives them.
+func checkEntry(r *Report, key string, f fileEntry) fileEntry {
+	if !validHash(f.Hash) {
+		r.problem("%s: hash %q is not a lowercase SHA-256", key, f.Hash)
+	}
+	switch f.Encoding {
+	case "":
+		if f.TextHash != "" {
+			r.problem("%s: text_hash without an encoding", key)
+		}
+	case "utf-8-bom", "utf-16le", "utf-16be":
+		if !validHash(f.TextHash) {
+			r.problem("%s: text_hash %q is not a lowercase SHA-256", key, f.TextHash)
+		}
+	default:
+		r.problem("%s: unknown encoding %q", key, f.Encoding)
+	}
+	if f.NormalizedHash != "" && !validHash(f.NormalizedHash) {
+		r.problem("%s: normalized_hash %q is not a lowercase SHA-256", key, f.NormalizedHash)
+	}
+	if !sort.SliceIsSorted(f.Symbols, func(i, j int) bool { return symbolLess(f.Symbols[i], f.Symbols[j]) }) {
+		r.problem("%s: symbols are not sorted by kind, then name", key)
+	}
+	for i := 1; i < len(f.Symbols); i++ {
+		if f.Symbols[i].Kind == f.Symbols[i-1].Kind && f.Symbols[i].Name == f.Symbols[i-1].Name {
+			r.problem("%s: symbol %s:%s is listed twice", key, f.Symbols[i].Kind, f.Symbols[i].Name)
+		}
+	}
+	if !sort.StringsAreSorted(f.Refs) {
+		r.problem("%s: refs are not sorted", key)
+	}
+	for i := 1; i < len(f.Refs); i++ {
+		if f.Refs[i] == f.Refs[i-1] {
+			r.problem("%s: ref %s is listed twice", key, f.Refs[i])
+		}
+	}
+	if f.ParseSkipped != "" && len(f.Symbols)+len(f.Refs) > 0 {
+		r.problem("%s: parse was skipped (%s) yet it has symbols or refs", key, f.ParseSkipped)
+	}
+	isSymbol := func(kind, name string) bool {
+		return slices.ContainsFunc(f.Symbols, func(s symbol) bool { return s.Kind == kind && s.Name == name })
+	}
+	isClass := func(name string) bool { return isSymbol("class", name) }
+	if !sort.SliceIsSorted(f.Enums, func(i, j int) bool { return f.Enums[i].Name < f.Enums[j].Name }) {
+		r.problem("%s: enums are not sorted", key)
+	}
+	for i, e := range f.Enums {
+		if i > 0 && e.Name == f.Enums[i-1].Name {
+			r.problem("%s: enum %s is listed twice", key, e.Name)
+		}
+		if !isClass(e.Name) {
+			r.problem("%s: enum %s is not a class symbol", key, e.Name)
+		}
+	}
+	for i, n := range f.Namespaces {
+		if i > 0 && n.Name <= f.Namespaces[i-1].Name {
+			r.problem("%s: namespaces are not sorted and unique", key)
+		}
+		if !isClass(n.Name) {
+			r.problem("%s: namespace %s is not a class symbol", key, n.Name)
+		}
+	}
+	for i, v := range f.Variables {
+		if i > 0 && v.Name <= f.Variables[i-1].Name {
+			r.problem("%s: variables are not sorted and unique", key)
+		}
+	}
+	for i, d := range f.ImportDetails {
+		if i > 0 && d.Module <= f.ImportDetails[i-1].Module {
+			r.problem("%s: import details are not sorted and unique", key)
+		}
+		if !sortedUnique(d.Named) {
+			r.problem("%s: names imported from %s are not sorted and unique", key, d.Module)
+		}
+	}
+	if !sortedUnique(f.TypeImports) {
+		r.problem("%s: type imports are not sorted and unique", key)
+	}
+	for _, m := range f.TypeImports {
+		if !isSymbol("import", m) {
+			r.problem("%s: type import %s is not an import symbol", key, m)
+		}
+	}
+	if !sort.SliceIsSorted(f.ClassDetails, func(i, j int) bool { return f.ClassDetails[i].Name < f.ClassDetails[j].Name }) {
+		r.problem("%s: class details are not sorted", key)
+	}
+	for i, d := range f.ClassDetails {
+		if i > 0 && d.Name == f.ClassDetails[i-1].Name {
+			r.problem("%s: class %s is detailed twice", key, d.Name)
+		}
+		if !isClass(d.Name) {
+			r.problem("%s: class detail %s is not a class symbol", key, d.Name)
+		}
+		if !sortedUnique(d.Methods) || !sortedUnique(d.Static) {
+			r.problem("%s: class %s members are not sorted and unique", key, d.Name)
+		}
+	}
+	for i, d := range f.FunctionDetails {
+		if i > 0 && d.Name <= f.FunctionDetails[i-1].Name {
+			r.problem("%s: function details are not sorted and unique", key)
+		}
+		if !isSymbol("function", d.Name) {
+			r.problem("%s: function detail %s is not a function symbol", key, d.Name)
+		}
+	}
+	if !sort.SliceIsSorted(f.Decorators, func(i, j int) bool {
+		return symbolLess(symbol{Kind: f.Decorators[i].Kind, Name: f.Decorators[i].Name}, symbol{Kind: f.Decorators[j].Kind, Name: f.Decorators[j].Name})
+	}) {
+		r.problem("%s: decorators are not sorted by kind, then name", key)
+	}
+	for i, d := range f.Decorators {
+		if i > 0 && d.Kind == f.Decorators[i-1].Kind && d.Name == f.Decorators[i-1].Name {
+			r.problem("%s: %s:%s is decorated twice", key, d.Kind, d.Name)
+		}
+		if !isSymbol(d.Kind, d.Name) || len(d.Decorators) == 0 {
+			r.problem("%s: decorated %s:%s is not a symbol with decorators", key, d.Kind, d.Name)
+		}
+	}
+	if !sortedUnique(f.ReExports) {
+		r.problem("%s: re-exports are not sorted and unique", key)
+	}
+	for _, k := range slices.Sorted(maps.Keys(f.Docs)) {
+		kind, name, _ := strings.Cut(k, ":")
+		if !isSymbol(kind, name) || f.Docs[k] == "" {
+			r.problem("%s: doc %s is not a symbol's documentation", key, k)
+		}
+	}
+	if m := f.Manifest; m != nil {
+		deps := m.Dependencies
+		if !sort.SliceIsSorted(deps, func(i, j int) bool {
+			if deps[i].Name != deps[j].Name {
+				return deps[i].Name < deps[j].Name
+			}
+			return deps[i].Scope < deps[j].Scope
+		}) {
+			r.problem("%s: manifest dependencies are not sorted by name, then scope", key)
+		}
+		if !sort.SliceIsSorted(m.Scripts, func(i, j int) bool { return m.Scripts[i].Name < m.Scripts[j].Name }) {
+			r.problem("%s: manifest scripts are not sorted", key)
+		}
+	}
+
+	names := func(kind string) []string {
+		out := []string{}
+		for _, s := range f.Symbols {
+			if s.Kind == kind {
+				out = append(out, s.Name)
+			}
+		}
+		return out
+	}
+	c := fileEntry{
+		Hash:            f.Hash,
+		Encoding:        f.Encoding,
+		TextHash:        f.TextHash,
+		NormalizedHash:  f.NormalizedHash,
+		Imports:         names("import"),
+		Functions:       names("function"),
+		Classes:         names("class"),
+		Exports:         names("export"),
+		Refs:            f.Refs,
+		Symbols:         f.Symbols,
+		ParseSkipped:    f.ParseSkipped,
+		Enums:           f.Enums,
+		Namespaces:      f.Namespaces,
+		Variables:       f.Variables,
+		ImportDetails:   f.ImportDetails,
+		TypeImports:     f.TypeImports,
+		ClassDetails:    f.ClassDetails,
+		FunctionDetails: f.FunctionDetails,
+		Decorators:      f.Decorators,
+		ReExports:       f.ReExports,
+		Docs:            f.Docs,
+		Manifest:        f.Manifest,
+	}
+	if c.Refs == nil {
+		c.Refs = []string{}
+	}
+	if c.Symbols == nil {
+		c.Symbols = []symbol{}
+	}
+	for _, s := range f.Symbols {
+		k := s.Kind + ":" + s.Name
+		if s.Hash != "" {
+			if c.SymbolHashes == nil {
+				c.SymbolHashes = make(map[string]string)
+			}
+			c.SymbolHashes[k] = s.Hash
+		}
+		if s.Line != 0 {
+			if c.SymbolLines == nil {
+				c.SymbolLines = make(map[string]int)
+			}
+			c.SymbolLines[k] = s.Line
+		}
+	}
+	return c
+}
+
+func symbolLess(a, b symbol) bool {
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	return a.Name < b.Name
+}
+
+// fatForm is the canonical form of an IR with inline entries.
+func fatForm(doc document, files map[string]fileEntry) any {
+	return &struct {
+		Version     int                  `json:"version"`
+		RootHash    string               `json:"root_hash"`
+		RootHashAlg string               `json:"root_hash_alg"`
+		Roots       map[string]string    `json:"roots,omitempty"`
+		Files       map[string]fileEntry `json:"files"`
+		Omissions   []omission           `json:"omissions,omitempty"`
+	}{doc.Version, doc.RootHash, doc.RootHashAlg, doc.Roots, files, doc.Omissions}
+}
+
+// thinForm is the canonical form of a thin IR.
+func thinForm(doc document, ids map[string]string) any {
+	if ids == nil {
+		ids = map[string]string{}
+	}
+	return &struct {
+		Version     int               `json:"version"`
+		RootHash    string            `json:"root_hash"`
+		RootHashAlg string            `json:"root_hash_alg"`
+		Roots       map[string]string `json:"roots,omitempty"`
+		Objects     string            `json:"objects"`
+		Files       map[string]string `json:"files"`
+		Omissions   []omission        `json:"omissions,omitempty"`
+	}{doc.Version, doc.RootHash, doc.RootHashAlg, doc.Roots, doc.Objects, ids, doc.Omissions}
+}
+
+// checkCanonical compares data with canon marshalled as the generator
+// writes an IR: compact, keys in struct order, map keys sorted.
+func checkCanonical(r *Report, data []byte, canon any) {
+	want, err := json.Marshal(canon)
+	if err != nil {
+		r.problem("cannot re-encode: %v", err)
+		return
+	}
+	if !bytes.Equal(data, want) {
+		r.problem("not in canonical form: %s", firstDifference(data, want))
+	}
+}
+
+// firstDifference locates where got first departs from want.
+func firstDifference(got, want []byte) string {
+	i := 0
+	for i < len(got) && i < len(want) && got[i] == want[i] {
+		i++
+	}
+	return fmt.Sprintf("differs from the canonical encoding at byte %d", i)
+}
+
+// readObject reads and checks the object a thin IR names for key.
+func readObject(r *Report, dir, key, id string) (fileEntry, bool) {
+	if !validHash(id) {
+		r.problem("%s: object id %q is not a lowercase SHA-256", key, id)
+		return fileEntry{}, false
+	}
+	data, err := os.ReadFile(filepath.Join(dir, id[:2], id[2:]))
+	if err != nil {
+		r.problem("%s: %v", key, err)
+		return fileEntry{}, false
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != id {
+		r.problem("%s: object %s is corrupt: its content hashes differently", key, id)
+		return fileEntry{}, false
+	}
+	var f fileEntry
+	if err := strictDecode(data, &f); err != nil {
+		r.problem("%s: object %s: %v", key, id, err)
+		return fileEntry{}, false
+	}
+	canon := checkEntry(r, key, f)
+	if want, err := json.Marshal(canon); err != nil || !bytes.Equal(data, want) {
+		r.problem("%s: object %s is not in canonical form", key, id)
+	}
+	return f, true
+}
+
+// rootHashV1 is ir.RootHashV1: SHA-256 over "key:hash" lines sorted by key.
+func rootHashV1(hashes map[string]string) string {
+	keys := make([]string, 0, len(hashes))
+	for k := range hashes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(k + ":" + hashes[k])
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkSource re-hashes every listed file from dir. The files are opened
+// through an os.Root, so a key or a symlink in the checkout cannot lead the
+// check outside it.
+func checkSource(r *Report, dir string, hashes map[string]string) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		r.problem("source: %v", err)
+		return
+	}
+	defer root.Close()
+	keys := make([]string, 0, len(hashes))
+	for k := range hashes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !validKey(key) {
+			continue
+		}
+		f, err := root.Open(filepath.FromSlash(key))
+		if err != nil {
+			r.problem("source: %s: %v", key, err)
+			continue
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			r.problem("source: %s: %v", key, err)
+			continue
+		}
+		r.SourceChecked++
+		if got := hex.EncodeToString(h.Sum(nil)); got != hashes[key] {
+			r.problem("source: %s has hash %s, the IR says %s", key, got, hashes[key])
+		}
+	}
+}
+
+// checkRoots checks a multi-root IR's roots against the hashes its root hash
+// is over: each root is a directory key inside no other, every file is under
+// one, and each root's hash is over its files keyed relative to it.
+func checkRoots(r *Report, roots, hashes map[string]string) {
+	if roots == nil {
+		return
+	}
+	sub := make(map[string]map[string]string, len(roots)) // root → its files' hashes
+	for _, root := range slices.Sorted(maps.Keys(roots)) {
+		if !validKey(root) {
+			r.problem("root %q is not a directory key", root)
+		}
+		for d := path.Dir(root); d != "."; d = path.Dir(d) {
+			if _, ok := roots[d]; ok {
+				r.problem("root %q is inside root %q", root, d)
+			}
+		}
+		sub[root] = make(map[string]string)
+	}
+	for _, key := range slices.Sorted(maps.Keys(hashes)) {
+		d := path.Dir(key)
+		for d != "." && sub[d] == nil {
+			d = path.Dir(d)
+		}
+		if d == "." {
+			r.problem("file %s is under none of the roots", key)
+			continue
+		}
+		sub[d][key[len(d)+1:]] = hashes[key]
+	}
+	for _, root := range slices.Sorted(maps.Keys(roots)) {
+		if got := rootHashV1(sub[root]); got != roots[root] {
+			r.problem("root %s hash %s does not match its files (computes %s)", root, roots[root], got)
+		}
+	}
+}
+
+// validKey reports whether key is a clean relative slash path inside the root.
+func validKey(key string) bool {
+	return key != "" && key != "." && path.Clean(key) == key && !path.IsAbs(key) &&
+		key != ".." && !strings.HasPrefix(key, "../") && !strings.Contains(key, "\\")
+}
+
+func validHash(s string) bool {
+	if len(s) != 2*sha256.Size {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedUnique reports whether names is in strictly increasing order.
+func sortedUnique(names []string) bool {
+	for i := 1; i < len(names); i++ {
+		if names[i] <= names[i-1] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,290 @@
+package irverify_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/inth3shadows/runecho/conformance"
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/irverify"
+)
+
+var tree = map[string]string{
+	"main.go":    "package main\n\ntype Server struct{}\n\nfunc (s *Server) Start() error { return nil }\n\nfunc main() {\n\tnew(Server).Start()\n}\n",
+	"web/app.ts": "import { fmt } from './fmt'\n/** Renders the app. */\nexport function app(n: number): string { return fmt(n) }\nexport const enum Mode { Fast }\n@sealed\nexport class Shell extends Base { static open() {} close() {} }\nexport { fmt as format } from './fmt'\n",
+	"tools/x.py": "import os\n\ndef gen():\n    os.getcwd()\n",
+	// A manifest, so its entry's extra section is held to the format too.
+	"web/package.json": "{\"name\": \"web\", \"dependencies\": {\"react\": \"^18.2.0\"}, \"scripts\": {\"build\": \"tsc\"}}\n",
+}
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, body := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// saved generates tree and saves it to a fresh directory, thin or not.
+func saved(t *testing.T, thin bool) (root, irPath string) {
+	return savedWith(t, thin, ir.GeneratorConfig{})
+}
+
+// savedWith is saved with the generator configured by config.
+func savedWith(t *testing.T, thin bool, config ir.GeneratorConfig) (root, irPath string) {
+	t.Helper()
+	root = t.TempDir()
+	writeTree(t, root, tree)
+	irData, _, err := ir.NewGenerator(config).Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root, save(t, irData, thin)
+}
+
+// savedRoots is saved for the IR of tree's web and tools directories as a
+// multi-root workspace.
+func savedRoots(t *testing.T, thin bool) (root, irPath string) {
+	t.Helper()
+	root = t.TempDir()
+	writeTree(t, root, tree)
+	irData, _, err := ir.NewGenerator(ir.GeneratorConfig{}).GenerateRoots(context.Background(), root, []string{"web", "tools"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root, save(t, irData, thin)
+}
+
+func save(t *testing.T, irData *ir.IR, thin bool) (irPath string) {
+	t.Helper()
+	irPath = filepath.Join(t.TempDir(), "ir.json")
+	var err error
+	if thin {
+		err = irData.SaveThin(irPath)
+	} else {
+		err = irData.Save(irPath)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return irPath
+}
+
+func verify(t *testing.T, irPath string, opts irverify.Options) *irverify.Report {
+	t.Helper()
+	r, err := irverify.Verify(irPath, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// TestVerify_AcceptsWhatTheGeneratorWrites holds the restated format to
+// internal/ir's: both IR shapes, and every conformance case.
+func TestVerify_AcceptsWhatTheGeneratorWrites(t *testing.T) {
+	if irverify.IRVersion != ir.IRVersion || irverify.RootHashV1 != ir.CurrentRootHashAlg || irverify.RootHashV1LF != ir.RootHashV1LF {
+		t.Fatalf("verifier checks v%d/%s, the generator writes v%d/%s", irverify.IRVersion, irverify.RootHashV1, ir.IRVersion, ir.CurrentRootHashAlg)
+	}
+	for _, thin := range []bool{false, true} {
+		root, irPath := saved(t, thin)
+		r := verify(t, irPath, irverify.Options{Source: root})
+		if len(r.Problems) > 0 || r.Thin != thin || r.Files != len(tree) || r.SourceChecked != len(tree) {
+			t.Errorf("thin=%v: %+v", thin, r)
+		}
+		_, irPath = savedWith(t, thin, ir.GeneratorConfig{NormalizedRootHash: true})
+		if r := verify(t, irPath, irverify.Options{}); len(r.Problems) > 0 {
+			t.Errorf("thin=%v, normalized root hash: %v", thin, r.Problems)
+		}
+		root, irPath = savedRoots(t, thin)
+		if r := verify(t, irPath, irverify.Options{Source: root}); len(r.Problems) > 0 {
+			t.Errorf("thin=%v, roots: %v", thin, r.Problems)
+		}
+	}
+
+	cases, err := conformance.Cases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, c := range cases {
+		irPath := filepath.Join(dir, c.Name+".json")
+		if err := os.WriteFile(irPath, []byte(c.IR), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if r := verify(t, irPath, irverify.Options{}); len(r.Problems) > 0 {
+			t.Errorf("conformance case %s: %v", c.Name, r.Problems)
+		}
+	}
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	for _, tc := range []struct {
+		name, old, new, want string
+	}{
+		{"reformatted", `,"root_hash"`, `, "root_hash"`, "canonical form"},
+		{"root hash", `"root_hash":"`, `"root_hash":"0`, "root_hash"},
+		{"legacy field", `"functions":["gen"]`, `"functions":["other"]`, "canonical form"},
+		{"version", `"version":24`, `"version":23`, "version 23"},
+		{"enum", `"enums":[{"name":"Mode"`, `"enums":[{"name":"Other"`, "enum Other is not a class symbol"},
+		{"decorator", `"decorators":[{"kind":"class","name":"Shell"`, `"decorators":[{"kind":"function","name":"Shell"`, "decorated function:Shell is not a symbol"},
+		{"class members", `"methods":["close"],"static":["open"]`, `"methods":["close","close"],"static":["open"]`, "members are not sorted and unique"},
+		{"text hash", `,"imports":`, `,"text_hash":"00","imports":`, "text_hash without an encoding"},
+		{"normalized root hash", `"root_hash_alg":"v1"`, `"root_hash_alg":"v1-lf"`, "no normalized_hash"},
+		{"unknown field", `"root_hash_alg"`, `"extra":1,"root_hash_alg"`, "unknown field"},
+	} {
+		_, irPath := saved(t, false)
+		data, _ := os.ReadFile(irPath)
+		if !bytes.Contains(data, []byte(tc.old)) {
+			t.Fatalf("%s: %q not in the IR", tc.name, tc.old)
+		}
+		os.WriteFile(irPath, bytes.Replace(data, []byte(tc.old), []byte(tc.new), 1), 0600)
+		r := verify(t, irPath, irverify.Options{})
+		if !strings.Contains(strings.Join(r.Problems, "\n"), tc.want) {
+			t.Errorf("%s: problems %v, want one mentioning %q", tc.name, r.Problems, tc.want)
+		}
+	}
+
+	for _, tc := range []struct{ old, new, want string }{
+		{`"roots":{"tools":"`, `"roots":{"tools":"0`, "root tools hash"},
+		{`"roots":{"tools":`, `"roots":{"tool":`, "tools/x.py is under none of the roots"},
+	} {
+		_, irPath := savedRoots(t, false)
+		data, _ := os.ReadFile(irPath)
+		if !bytes.Contains(data, []byte(tc.old)) {
+			t.Fatalf("%q not in the IR", tc.old)
+		}
+		os.WriteFile(irPath, bytes.Replace(data, []byte(tc.old), []byte(tc.new), 1), 0600)
+		if r := verify(t, irPath, irverify.Options{}); !strings.Contains(strings.Join(r.Problems, "\n"), tc.want) {
+			t.Errorf("problems %v, want one mentioning %q", r.Problems, tc.want)
+		}
+	}
+
+	root, irPath := saved(t, false)
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+	if r := verify(t, irPath, irverify.Options{Source: root}); len(r.Problems) != 1 || !strings.Contains(r.Problems[0], "main.go") {
+		t.Errorf("edited source: %v", r.Problems)
+	}
+
+	_, irPath = saved(t, true)
+	objects := ir.ObjectsDir(irPath)
+	filepath.WalkDir(objects, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			os.WriteFile(p, []byte("{}"), 0600)
+		}
+		return err
+	})
+	if r := verify(t, irPath, irverify.Options{}); !strings.Contains(strings.Join(r.Problems, "\n"), "corrupt") {
+		t.Errorf("corrupt objects: %v", r.Problems)
+	}
+}
+
+func TestVerify_Signature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, irPath := saved(t, false)
+	if r := verify(t, irPath, irverify.Options{PublicKey: pub}); r.Signed || len(r.Problems) != 1 {
+		t.Errorf("unsigned IR: %+v", r)
+	}
+	data, _ := os.ReadFile(irPath)
+	os.WriteFile(irverify.SignatureFile(irPath), irverify.Sign(data, priv), 0600)
+	if r := verify(t, irPath, irverify.Options{PublicKey: pub}); !r.Signed || len(r.Problems) > 0 {
+		t.Errorf("signed IR: %+v", r)
+	}
+	other, _, _ := ed25519.GenerateKey(nil)
+	if r := verify(t, irPath, irverify.Options{PublicKey: other}); r.Signed {
+		t.Error("another key's signature was accepted")
+	}
+}
+
+// TestStdlibOnly: the verifier and its binary must not link the parsers, or
+// anything else of this module that might.
+func TestStdlibOnly(t *testing.T) {
+	for _, dir := range []string{".", "../../cmd/runecho-verify-ir"} {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, imp := range f.Imports {
+				path := strings.Trim(imp.Path.Value, `"`)
+				first, _, _ := strings.Cut(path, "/")
+				if strings.Contains(first, ".") && path != "github.com/inth3shadows/runecho/internal/irverify" {
+					t.Errorf("%s imports %s", file, path)
+				}
+			}
+		}
+	}
+}
+
+func TestVerify_Attestation(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, irPath := saved(t, false)
+	data, _ := os.ReadFile(irPath)
+	loaded, err := ir.Load(irPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pred := irverify.Predicate{
+		Generator:   irverify.Generator{Name: "runecho", Version: "v1.0.0", IRVersion: loaded.Version},
+		RootHash:    loaded.RootHash,
+		RootHashAlg: loaded.RootHashAlg,
+		Source:      irverify.Source{Revision: "abc123", Path: "."},
+	}
+	attPath := irverify.AttestationFile(irPath)
+	write := func(st irverify.Statement, key ed25519.PrivateKey) {
+		t.Helper()
+		out, err := irverify.MarshalAttestation(st, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.WriteFile(attPath, out, 0600)
+	}
+
+	write(irverify.NewStatement("ir.json", data, pred), nil)
+	if r := verify(t, irPath, irverify.Options{Attestation: attPath}); len(r.Problems) > 0 || r.Attestation == nil || r.Attestation.Source.Revision != "abc123" {
+		t.Errorf("unsigned attestation: %+v", r)
+	}
+	if r := verify(t, irPath, irverify.Options{Attestation: attPath, PublicKey: pub}); r.Signed || r.Attestation != nil {
+		t.Errorf("unsigned attestation passed a key check: %+v", r)
+	}
+
+	write(irverify.NewStatement("ir.json", data, pred), priv)
+	if r := verify(t, irPath, irverify.Options{Attestation: attPath, PublicKey: pub}); len(r.Problems) > 0 || !r.Signed {
+		t.Errorf("signed attestation: %+v", r)
+	}
+	other, _, _ := ed25519.GenerateKey(nil)
+	if r := verify(t, irPath, irverify.Options{Attestation: attPath, PublicKey: other}); r.Signed || len(r.Problems) == 0 {
+		t.Errorf("another key's attestation was accepted: %+v", r)
+	}
+
+	write(irverify.NewStatement("ir.json", append(data, ' '), pred), nil)
+	if r := verify(t, irPath, irverify.Options{Attestation: attPath}); !strings.Contains(strings.Join(r.Problems, "\n"), "subject") {
+		t.Errorf("attestation of other content: %v", r.Problems)
+	}
+	pred.RootHash = strings.Repeat("0", 64)
+	write(irverify.NewStatement("ir.json", data, pred), nil)
+	if r := verify(t, irPath, irverify.Options{Attestation: attPath}); !strings.Contains(strings.Join(r.Problems, "\n"), "predicate") {
+		t.Errorf("attestation of another root hash: %v", r.Problems)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/inth3shadows/runecho/internal/config"
 	"github.com/inth3shadows/runecho/internal/ir"
 	"github.com/inth3shadows/runecho/internal/snapshot"
 )
@@ -228,7 +229,21 @@ func (o *Oracle) resolveRepo(name string) (*snapshot.Repo, error) {
 // so the live IR is generated under the same cap as the repo's stored snapshots.
 // A mismatch here would make every diff/hash report phantom drift for capped repos.
 func liveIR(path string, fileCap int) (*ir.IR, error) {
-	gen := ir.NewGenerator(ir.GeneratorConfig{IgnoredPaths: ir.DefaultIgnoredPaths, FileCap: fileCap})
+	// Same .runecho.json parser plugins as the CLI, or every file a plugin
+	// claims would read as deleted drift against the stored snapshots. No warn
+	// sink: a skipped-plugin notice on every request is noise, and the CLI
+	// already reports it where a user will see it.
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	plugins, err := cfg.PluginParsers(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	gc := cfg.Generator(plugins)
+	gc.FileCap = fileCap
+	gen := ir.NewGenerator(gc)
 	// A fresh IR is built on every MCP call, so an unbounded walk (huge repo,
 	// stalled FS) would hang the agent's request with no recourse. Set the
 	// per-request deadline explicitly here — rather than leaning on the package
@@ -0,0 +1,87 @@
+package parser
+
+import "sort"
+
+// builtinLanguages names the built-in parsers for extension mapping (see
+// Builtin). Each name fixes the parser and the extension a mapped file is
+// parsed as; for the JS/TS parser that extension picks the grammar, which is
+// why javascript, typescript, and tsx are separate names.
+var builtinLanguages = map[string]struct {
+	parse func() Parser
+	ext   string
+}{
+	"javascript": {func() Parser { return NewJSParser() }, ".js"},
+	"jsx":        {func() Parser { return NewJSParser() }, ".jsx"},
+	"typescript": {func() Parser { return NewJSParser() }, ".ts"},
+	"tsx":        {func() Parser { return NewJSParser() }, ".tsx"},
+	"go":         {func() Parser { return NewGoParser() }, ".go"},
+	"python":     {func() Parser { return NewPythonParser() }, ".py"},
+	"shell":      {func() Parser { return NewShellParser() }, ".sh"},
+	"rust":       {func() Parser { return NewRustParser() }, ".rs"},
+	"ruby":       {func() Parser { return NewRubyParser() }, ".rb"},
+	"java":       {func() Parser { return NewJavaParser() }, ".java"},
+	"csharp":     {func() Parser { return NewCSharpParser() }, ".cs"},
+	"php":        {func() Parser { return NewPHPParser() }, ".php"},
+	"kotlin":     {func() Parser { return NewKotlinParser() }, ".kt"},
+	"swift":      {func() Parser { return NewSwiftParser() }, ".swift"},
+	"scala":      {func() Parser { return NewScalaParser() }, ".scala"},
+	"dart":       {func() Parser { return NewDartParser() }, ".dart"},
+	"elixir":     {func() Parser { return NewElixirParser() }, ".ex"},
+	"lua":        {func() Parser { return NewLuaParser() }, ".lua"},
+	"sql":        {func() Parser { return NewSQLParser() }, ".sql"},
+	"proto":      {func() Parser { return NewProtoParser() }, ".proto"},
+	"dockerfile": {func() Parser { return NewDockerfileParser() }, ".dockerfile"},
+	"vue":        {func() Parser { return NewVueParser() }, ".vue"},
+	"svelte":     {func() Parser { return NewSvelteParser() }, ".svelte"},
+	"html":       {func() Parser { return NewHTMLParser() }, ".html"},
+	"css":        {func() Parser { return NewCSSParser() }, ".css"},
+	"scss":       {func() Parser { return NewCSSParser() }, ".scss"},
+	"markdown":   {func() Parser { return NewMarkdownParser() }, ".md"},
+	"notebook":   {func() Parser { return NewNotebookParser() }, ".ipynb"},
+	"solidity":   {func() Parser { return NewSolidityParser() }, ".sol"},
+	"openapi":    {func() Parser { return NewOpenAPIParser() }, ".yaml"},
+	"manifest":   {func() Parser { return NewManifestParser() }, ".json"},
+}
+
+// builtinAliases are the short names a mapping may use for a built-in
+// language, the ones its files are commonly known by: ".gs": "js".
+var builtinAliases = map[string]string{
+	"js":     "javascript",
+	"ts":     "typescript",
+	"py":     "python",
+	"sh":     "shell",
+	"bash":   "shell",
+	"rb":     "ruby",
+	"rs":     "rust",
+	"cs":     "csharp",
+	"kt":     "kotlin",
+	"ex":     "elixir",
+	"md":     "markdown",
+	"golang": "go",
+}
+
+// Builtin returns a new built-in parser by language name or alias, with the
+// native extension a file mapped to it should be parsed as:
+// Builtin("typescript") yields the JS/TS parser and ".ts". ok is false for an
+// unknown name.
+func Builtin(name string) (p Parser, ext string, ok bool) {
+	if full, alias := builtinAliases[name]; alias {
+		name = full
+	}
+	b, ok := builtinLanguages[name]
+	if !ok {
+		return nil, "", false
+	}
+	return b.parse(), b.ext, true
+}
+
+// BuiltinNames returns the language names Builtin accepts, sorted, without
+// their aliases.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtinLanguages))
+	for name := range builtinLanguages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,323 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// CSharpParser implements structural parsing for .cs files using the vendored
+// pure-Go tree-sitter C# grammar, for the reasons the Java parser gives:
+// generics, attributes, verbatim and raw string literals (`@"…"`, `"""…"""`),
+// and interpolation holes (`$"{x}"`) all hide braces a masking scan would
+// count.
+//
+// Symbol routing:
+//   - `class` / `struct` / `interface` / `record` / `enum` / `delegate` →
+//     Classes, nested-qualified ("Outer.Inner"). A delegate declares a type,
+//     so it lands with the types, not with the methods.
+//   - Methods and constructors → Functions, qualified by their type
+//     ("Reader.Fetch"); a constructor is named after its type
+//     ("Reader.Reader"). Overloads share one name and their hashes combine, as
+//     in the Java parser. Finalizers, operators, and indexers have no name a
+//     caller writes and are skipped.
+//   - Fields, constants, properties, events, and enum members → Exports when
+//     visible (see below); located, not hashed.
+//   - `using` directives → Imports, as written minus `global`, `using`, and the
+//     semicolon: "System.Linq", "static System.Math",
+//     "Json = Newtonsoft.Json".
+//   - Namespaces → Exports, block-scoped and file-scoped alike. A namespace is
+//     what a `using` names, so listing it is what lets an import be matched
+//     to the files that provide it. Types are NOT qualified by namespace:
+//     after a `using`, a caller writes "Reader", not "Acme.Web.Reader".
+//
+// Visibility follows the Java parser's rule: every type and method is listed,
+// and Exports additionally lists those marked `public`, plus interface members
+// not marked otherwise (public by default since C# 8). `internal` is not
+// exported: it stops at the assembly, which is usually smaller than the repo.
+// Enum members are as visible as their enum.
+//
+// Method bodies are not descended into: local functions and lambdas have no
+// name a caller elsewhere could write.
+type CSharpParser struct{}
+
+// NewCSharpParser creates a new C# parser.
+func NewCSharpParser() *CSharpParser { return &CSharpParser{} }
+
+// SupportsExtension returns true for .cs files.
+func (p *CSharpParser) SupportsExtension(ext string) bool {
+	return ext == ".cs"
+}
+
+var (
+	csharpLangOnce sync.Once
+	csharpLang     *ts.Language
+)
+
+func csharpLanguage() *ts.Language {
+	csharpLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("C#", r)
+			}
+		}()
+		csharpLang = grammars.CSharpLanguage()
+	})
+	return csharpLang
+}
+
+// Parse extracts structure from C# source via tree-sitter. Best-effort on
+// parse errors: tree-sitter recovers to a partial tree for most mid-edit
+// buffers, and we walk whatever it produced.
+func (p *CSharpParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := csharpSymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}, nil
+}
+
+// csharpTypeDecls are the declarations that define a named type with a body,
+// and whether their members are public unless marked otherwise.
+var csharpTypeDecls = map[string]struct{ implicitlyPublic bool }{
+	"class_declaration":     {false},
+	"struct_declaration":    {false},
+	"record_declaration":    {false},
+	"enum_declaration":      {false},
+	"interface_declaration": {true},
+}
+
+func csharpSymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("C# parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			hashes, lines = nil, nil
+		}
+	}()
+
+	lang := csharpLanguage()
+	if lang == nil {
+		diag.noGrammar("C#")
+		return imports, functions, classes, exports, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("C# source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil
+	}
+	// As for Ruby: an ERROR root means nothing below it is a declaration, so
+	// say the symbols are missing rather than let them read as absent.
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("C# file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+
+	// recordHash combines on collision: overloads, and partial classes split
+	// across one file.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+	recordLine := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+	addExport := func(full string, line int) {
+		exports = append(exports, full)
+		recordLine("export:"+full, line)
+	}
+	addType := func(full string, span []byte, line int, visible bool) {
+		classes = append(classes, full)
+		recordHash("class:"+full, span)
+		recordLine("class:"+full, line)
+		if visible {
+			exports = append(exports, full)
+		}
+	}
+
+	// walk visits the declarations in n. prefix is the enclosing type's
+	// qualified name; implicit says its members are public by default;
+	// enumPublic is the visibility enum members inherit.
+	var walk func(n *ts.Node, prefix string, implicit, enumPublic bool, depth int)
+	walk = func(n *ts.Node, prefix string, implicit, enumPublic bool, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			c := n.NamedChild(i)
+			kind := c.Type(lang)
+			span := src[c.StartByte():c.EndByte()]
+			line := int(c.StartPoint().Row) + 1
+			visible := csharpVisible(c, lang, src, implicit)
+
+			if decl, ok := csharpTypeDecls[kind]; ok {
+				name := rustFieldText(c, "name", lang, src)
+				if name == "" {
+					continue
+				}
+				full := qualify(prefix, name)
+				addType(full, span, line, visible)
+				if body := c.ChildByFieldName("body", lang); body != nil {
+					walk(body, full, decl.implicitlyPublic, visible, depth+1)
+				}
+				continue
+			}
+
+			switch kind {
+			case "using_directive":
+				if path := csharpUsingPath(c, src); path != "" {
+					imports = append(imports, path)
+				}
+
+			case "namespace_declaration", "file_scoped_namespace_declaration":
+				if name := rustFieldText(c, "name", lang, src); name != "" {
+					addExport(strings.Join(strings.Fields(name), ""), line)
+				}
+				if body := c.ChildByFieldName("body", lang); body != nil {
+					walk(body, prefix, false, false, depth+1)
+				}
+
+			case "delegate_declaration":
+				if name := rustFieldText(c, "name", lang, src); name != "" {
+					addType(qualify(prefix, name), span, line, visible)
+				}
+
+			case "method_declaration", "constructor_declaration":
+				name := rustFieldText(c, "name", lang, src)
+				if name == "" || prefix == "" {
+					continue
+				}
+				full := qualify(prefix, name)
+				functions = append(functions, full)
+				recordHash("function:"+full, span)
+				recordLine("function:"+full, line)
+				if visible {
+					exports = append(exports, full)
+				}
+
+			case "property_declaration":
+				if name := rustFieldText(c, "name", lang, src); name != "" && visible && prefix != "" {
+					addExport(qualify(prefix, name), line)
+				}
+
+			case "field_declaration", "event_field_declaration":
+				if !visible || prefix == "" {
+					continue
+				}
+				for j := 0; j < c.NamedChildCount(); j++ {
+					v := c.NamedChild(j)
+					if v.Type(lang) != "variable_declaration" {
+						continue
+					}
+					for k := 0; k < v.NamedChildCount(); k++ {
+						if d := v.NamedChild(k); d.Type(lang) == "variable_declarator" {
+							if name := csharpDeclaratorName(d, lang, src); name != "" {
+								addExport(qualify(prefix, name), line)
+							}
+						}
+					}
+				}
+
+			case "enum_member_declaration":
+				if name := rustFieldText(c, "name", lang, src); name != "" && enumPublic {
+					addExport(qualify(prefix, name), line)
+				}
+
+			case "declaration_list", "ERROR":
+				// A namespace body, or a mid-edit buffer's ERROR node, can still
+				// hold whole declarations.
+				walk(c, prefix, implicit, enumPublic, depth+1)
+			}
+		}
+	}
+	walk(tree.RootNode(), "", false, false, 0)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, hashes, lines
+}
+
+// csharpVisible reports whether a declaration is visible outside its
+// assembly: marked `public`, or unmarked in a type whose members default to
+// public.
+func csharpVisible(n *ts.Node, lang *ts.Language, src []byte, implicit bool) bool {
+	marked := false
+	for i := 0; i < n.NamedChildCount(); i++ {
+		m := n.NamedChild(i)
+		if m.Type(lang) != "modifier" {
+			continue
+		}
+		switch m.Text(src) {
+		case "public":
+			return true
+		case "private", "protected", "internal":
+			marked = true
+		}
+	}
+	return implicit && !marked
+}
+
+// csharpDeclaratorName returns a variable declarator's name: its first
+// identifier, before any initializer.
+func csharpDeclaratorName(d *ts.Node, lang *ts.Language, src []byte) string {
+	if name := rustFieldText(d, "name", lang, src); name != "" {
+		return name
+	}
+	for i := 0; i < d.NamedChildCount(); i++ {
+		if c := d.NamedChild(i); c.Type(lang) == "identifier" {
+			return c.Text(src)
+		}
+	}
+	return ""
+}
+
+// csharpUsingPath returns a using directive as written, minus `global`,
+// `using`, and the semicolon, with whitespace collapsed so formatting cannot
+// change it.
+func csharpUsingPath(n *ts.Node, src []byte) string {
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(n.Text(src)), ";"))
+	for len(fields) > 0 && (fields[0] == "global" || fields[0] == "using") {
+		fields = fields[1:]
+	}
+	return strings.Join(fields, " ")
+}
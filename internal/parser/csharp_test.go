@@ -0,0 +1,184 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const csharpSample = `using System;
+using System.Collections.Generic;
+using static System.Math;
+using Json = Newtonsoft.Json;
+global using System.Linq;
+
+namespace Acme.Web
+{
+    [Serializable]
+    public partial class Reader<T> : Base, IRunnable where T : class
+    {
+        private int x = 1;
+        public const string Name = "r";
+        public static readonly int A = 1, B = 2;
+        public string Title { get; set; }
+        public event EventHandler Changed;
+        public Reader() {}
+        ~Reader() {}
+        public List<T> Fetch(int n) => null;
+        public List<T> Fetch() { return Fetch(0); }
+        internal void Helper() { void Local() {} Action a = () => {}; }
+        public static Reader<T> operator +(Reader<T> a, Reader<T> b) => a;
+        public T this[int i] => default;
+        public class Inner { public void Go() {} }
+        public delegate void Callback(int x);
+    }
+
+    public interface IApi { string Get(); private void Hidden() {} }
+
+    public record Point(int X, int Y) { public int Sum() => X + Y; }
+
+    record struct Pair(int A);
+
+    struct Size { public int Area() => 1; }
+
+    public enum Color { Red, Green }
+}
+`
+
+func TestCSharpParser_Extension(t *testing.T) {
+	p := NewCSharpParser()
+	if !p.SupportsExtension(".cs") {
+		t.Error("want .cs supported")
+	}
+	if p.SupportsExtension(".csx") || p.SupportsExtension(".java") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestCSharpParser_Symbols(t *testing.T) {
+	got, err := NewCSharpParser().Parse(csharpSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	wantImports := []string{"Json = Newtonsoft.Json", "System", "System.Collections.Generic", "System.Linq", "static System.Math"}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports:\n got %q\nwant %q", got.Imports, wantImports)
+	}
+	// Types are not qualified by namespace; a delegate is a type.
+	wantClasses := []string{"Color", "IApi", "Pair", "Point", "Reader", "Reader.Callback", "Reader.Inner", "Size"}
+	if !reflect.DeepEqual(got.Classes, wantClasses) {
+		t.Errorf("Classes:\n got %q\nwant %q", got.Classes, wantClasses)
+	}
+	// Overloads share a name; the finalizer, operator, indexer, local
+	// function, and lambda are not listed.
+	wantFunctions := []string{"IApi.Get", "IApi.Hidden", "Point.Sum", "Reader.Fetch", "Reader.Helper", "Reader.Inner.Go", "Reader.Reader", "Size.Area"}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+}
+
+// Exports are the public surface plus the namespaces a using can name.
+// internal, private, and unmarked class members stay out.
+func TestCSharpParser_Visibility(t *testing.T) {
+	got, _ := NewCSharpParser().Parse(csharpSample)
+	wantExports := []string{
+		"Acme.Web", "Color", "Color.Green", "Color.Red", "IApi", "IApi.Get",
+		"Point", "Point.Sum", "Reader", "Reader.A", "Reader.B", "Reader.Callback",
+		"Reader.Changed", "Reader.Fetch", "Reader.Inner", "Reader.Inner.Go",
+		"Reader.Name", "Reader.Reader", "Reader.Title", "Size.Area",
+	}
+	if !reflect.DeepEqual(got.Exports, wantExports) {
+		t.Errorf("Exports:\n got %q\nwant %q", got.Exports, wantExports)
+	}
+
+	fileScoped, _ := NewCSharpParser().Parse("namespace Acme.Core;\n\npublic class Svc { }\n")
+	if !reflect.DeepEqual(fileScoped.Exports, []string{"Acme.Core", "Svc"}) || fileScoped.SymbolLines["class:Svc"] != 3 {
+		t.Errorf("file-scoped namespace: exports %q, lines %v", fileScoped.Exports, fileScoped.SymbolLines)
+	}
+}
+
+func TestCSharpParser_HashesAndLines(t *testing.T) {
+	got, _ := NewCSharpParser().Parse(csharpSample)
+	for key, want := range map[string]int{
+		"function:Reader.Fetch": 19, // the first overload anchors the line
+		"class:Reader":          9,  // attributes are part of the declaration
+		"export:Reader.B":       14,
+		"export:Acme.Web":       7,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(csharpSample, "return Fetch(0);", "return Fetch(1);", 1)
+	after, _ := NewCSharpParser().Parse(edited)
+	if after.SymbolHashes["function:Reader.Fetch"] == got.SymbolHashes["function:Reader.Fetch"] {
+		t.Error("editing the second overload did not change the symbol hash")
+	}
+	if after.SymbolHashes["function:Point.Sum"] != got.SymbolHashes["function:Point.Sum"] {
+		t.Error("unrelated symbol's hash changed")
+	}
+}
+
+func TestCSharpParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "class A {\n" +
+		"  // public void Commented() {}\n" +
+		"  /* class Fake { } */\n" +
+		"  string s = @\"public void Verbatim() { \"\" }\";\n" +
+		"  string t = $\"{x} class Interp {{ }}\";\n" +
+		"  string u = \"\"\"\n    class Raw { }\n    \"\"\";\n" +
+		"  void Real() {}\n}\n"
+	got, _ := NewCSharpParser().Parse(src)
+	if !reflect.DeepEqual(got.Functions, []string{"A.Real"}) || !reflect.DeepEqual(got.Classes, []string{"A"}) {
+		t.Errorf("Functions %q, Classes %q; want only A and A.Real", got.Functions, got.Classes)
+	}
+}
+
+func TestCSharpParser_CRLFParity(t *testing.T) {
+	lf, _ := NewCSharpParser().Parse(csharpSample)
+	crlf, _ := NewCSharpParser().Parse(strings.ReplaceAll(csharpSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestCSharpParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "class Broken {", "public \x00\xff", "}}}}"} {
+		got, err := NewCSharpParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzCSharpParser asserts the C# parser never panics on arbitrary input and
+// keeps the invariants the other tree-sitter parsers keep.
+// Run: go test -run=x -fuzz=FuzzCSharpParser ./internal/parser
+func FuzzCSharpParser(f *testing.F) {
+	seeds := []string{
+		csharpSample,
+		"class A { void B() {} }",
+		"namespace N; public enum E { X, Y }",
+		"interface I { void D() {} }",
+		"public record R(int A) { }",
+		"global using static A.B;",
+		"class G<T> where T : new() { public U M<U>() => default; }",
+		"", "class", "}}}", "public public", "class A { void F(\x00) {",
+		"string s = \"\"\"\nunterminated", "var s = $\"{",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewCSharpParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -0,0 +1,373 @@
+package parser
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CSSParser implements structural parsing for stylesheets (.css, .scss): what
+// a design-system package imports and what it offers its users.
+//
+//   - `@import`, `@use`, and `@forward` paths go to Imports as written
+//     ("sass:math", "./tokens", "theme.css"), `url(…)` or quoted.
+//   - `@mixin` and `@function` names go to Functions, each hashed over its
+//     whole block so an edited body shows as modified.
+//   - Top-level custom properties — those declared in a `:root`, `html`, or
+//     `:host` rule, bare or inside an `@media`, `@supports`, `@layer`, or
+//     `@container` block — and top-level SCSS `$variables` go to Exports,
+//     each hashed over its declaration: they are a design system's tokens.
+//
+// Public mixins and functions are Exports too; as Sass's module system has
+// it, a name starting with `-` or `_` is private to its file. A stylesheet
+// has no classes in this sense (a selector is not a declaration), so Classes
+// is always empty.
+//
+// The engine is a statement scan, like the SQL parser's: comments are
+// blanked, strings, `url(…)` arguments, and `#{…}` interpolations are skipped
+// whole, and the rest splits at `;`, `{`, and `}`, so a brace or `@mixin`
+// inside a comment or string never opens a block or declares a name. One scan
+// reads both syntaxes: a `//` line comment is Sass-only but cannot occur in
+// valid CSS outside a string or `url(…)`.
+//
+// Known limitations: the indented Sass syntax (.sass) and Less are not read;
+// custom properties declared under any other selector (`[data-theme=dark]`)
+// are overrides, not tokens, and are skipped; `@include`d mixins are uses,
+// not imports.
+type CSSParser struct{}
+
+// NewCSSParser creates a new CSS/SCSS parser.
+func NewCSSParser() *CSSParser { return &CSSParser{} }
+
+// SupportsExtension returns true for .css and .scss files.
+func (p *CSSParser) SupportsExtension(ext string) bool {
+	return ext == ".css" || ext == ".scss"
+}
+
+var (
+	reCSSImport   = regexp.MustCompile(`(?i)^@(import|use|forward)\b`)
+	reCSSCallable = regexp.MustCompile(`^@(?:mixin|function)\s+([A-Za-z_-][\w-]*)`)
+	reCSSVariable = regexp.MustCompile(`^(\$[A-Za-z_-][\w-]*)\s*:`)
+	reCSSCustom   = regexp.MustCompile(`^(--[\w-]+)\s*:`)
+	// reCSSImportPath matches the path at the head of one import item: a
+	// quoted string or a url() argument.
+	reCSSImportPath = regexp.MustCompile(`(?i)^(?:"([^"]*)"|'([^']*)'|url\(\s*(?:"([^"]*)"|'([^']*)'|([^)\s]*))\s*\))`)
+	// reCSSGroupRule matches the at-rules a token declaration may sit inside.
+	reCSSGroupRule = regexp.MustCompile(`(?i)^@(?:media|supports|layer|container)\b`)
+)
+
+// cssStatement is one statement of a stylesheet: a declaration or at-rule
+// ended by `;`, or the prelude of a block. start and end delimit its text,
+// trimmed; a block's end is just past its closing `}` (or the source's end
+// if it never closes) and prelude is where its `{` is. parent is the index
+// of the enclosing block's statement, or -1 at the top level.
+type cssStatement struct {
+	start, prelude, end int
+	block               bool
+	parent              int
+}
+
+// Parse extracts a stylesheet's imports, mixins, functions, and tokens.
+// Best-effort and never errors: an unclosed block runs to the end of the
+// file.
+func (p *CSSParser) Parse(source string) (FileStructure, error) {
+	// Normalize CRLF→LF so hashes and line numbers are line-ending-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	src := []byte(source)
+	masked, stmts := splitCSS(src)
+	starts := lineStartsOf(src)
+
+	imports, functions, exports := []string{}, []string{}, []string{}
+	hashes := make(map[string]string)
+	lines := make(map[string]int)
+
+	record := func(key string, st cssStatement) {
+		h := hashBytesHex(src[st.start:st.end])
+		// Combine on collision: a token redeclared under `@media`.
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+		if _, ok := lines[key]; !ok {
+			lines[key] = lineForOffset(starts, st.start)
+		}
+	}
+
+	// tokenScope memoizes isCSSTokenScope per block.
+	tokenScope := map[int]bool{}
+	inTokenScope := func(block int) bool {
+		in, ok := tokenScope[block]
+		if !ok {
+			in = isCSSTokenScope(masked, stmts, block)
+			tokenScope[block] = in
+		}
+		return in
+	}
+
+	for _, st := range stmts {
+		head := masked[st.start:st.prelude]
+		switch {
+		case st.parent < 0 && !st.block && reCSSImport.Match(head):
+			m := reCSSImport.FindSubmatchIndex(head)
+			items := [][]byte{head[m[1]:]}
+			if strings.EqualFold(string(head[m[2]:m[3]]), "import") {
+				items = splitCSSTopLevel(head[m[1]:]) // @import "a", "b";
+			}
+			for _, item := range items {
+				if path := cssImportPath(bytes.TrimSpace(item)); path != "" {
+					imports = append(imports, path)
+				}
+			}
+		case st.parent < 0 && st.block && reCSSCallable.Match(head):
+			name := string(reCSSCallable.FindSubmatch(head)[1])
+			functions = append(functions, name)
+			if !strings.HasPrefix(name, "-") && !strings.HasPrefix(name, "_") {
+				exports = append(exports, name)
+			}
+			record("function:"+name, st)
+		case st.parent < 0 && !st.block && reCSSVariable.Match(head):
+			name := string(reCSSVariable.FindSubmatch(head)[1])
+			if !strings.HasPrefix(name, "$-") && !strings.HasPrefix(name, "$_") {
+				exports = append(exports, name)
+				record("export:"+name, st)
+			}
+		case st.parent >= 0 && !st.block && reCSSCustom.Match(head) && inTokenScope(st.parent):
+			name := string(reCSSCustom.FindSubmatch(head)[1])
+			exports = append(exports, name)
+			record("export:"+name, st)
+		}
+	}
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(exports)
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      []string{},
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+	}, nil
+}
+
+// isCSSTokenScope reports whether the block at index i is a `:root`, `html`,
+// or `:host` rule whose enclosing blocks are all grouping at-rules.
+func isCSSTokenScope(masked []byte, stmts []cssStatement, i int) bool {
+	for _, sel := range splitCSSTopLevel(masked[stmts[i].start:stmts[i].prelude]) {
+		switch strings.ToLower(string(bytes.TrimSpace(sel))) {
+		case ":root", "html", ":host":
+		default:
+			return false
+		}
+	}
+	for i = stmts[i].parent; i >= 0; i = stmts[i].parent {
+		if !reCSSGroupRule.Match(masked[stmts[i].start:stmts[i].prelude]) {
+			return false
+		}
+	}
+	return true
+}
+
+// cssImportPath returns the path at the head of one import item, or "".
+func cssImportPath(item []byte) string {
+	m := reCSSImportPath.FindSubmatch(item)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(string(m[1]) + string(m[2]) + string(m[3]) + string(m[4]) + string(m[5]))
+}
+
+// splitCSSTopLevel splits text at the commas outside parentheses and strings.
+func splitCSSTopLevel(text []byte) [][]byte {
+	var out [][]byte
+	depth, from := 0, 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '"', '\'':
+			i = skipCSSString(text, i) - 1
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				out = append(out, text[from:i])
+				from = i + 1
+			}
+		}
+	}
+	return append(out, text[from:])
+}
+
+// splitCSS returns a masked copy of src — comments blanked to spaces,
+// newlines kept — and its statements in source order. Offsets index src and
+// the mask alike.
+func splitCSS(src []byte) ([]byte, []cssStatement) {
+	masked := append([]byte(nil), src...)
+	blank := func(from, to int) {
+		for ; from < to; from++ {
+			if masked[from] != '\n' {
+				masked[from] = ' '
+			}
+		}
+	}
+
+	var stmts []cssStatement
+	var open []int // indices of the blocks enclosing the scan
+	parent := func() int {
+		if len(open) == 0 {
+			return -1
+		}
+		return open[len(open)-1]
+	}
+	from, parens := 0, 0
+	// emit records the statement in [from, to) unless it is blank.
+	emit := func(to int, block bool) {
+		s, e := from, to
+		for s < e && isCSSSpace(masked[s]) {
+			s++
+		}
+		for e > s && isCSSSpace(masked[e-1]) {
+			e--
+		}
+		if s < e || block {
+			stmts = append(stmts, cssStatement{start: s, prelude: e, end: e, block: block, parent: parent()})
+		}
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(src)
+			} else {
+				end += i + 4
+			}
+			blank(i, end)
+			i = end
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			end := bytes.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src)
+			} else {
+				end += i
+			}
+			blank(i, end)
+			i = end
+		case c == '"' || c == '\'':
+			i = skipCSSString(src, i)
+		case c == '#' && i+1 < len(src) && src[i+1] == '{':
+			i = skipCSSInterpolation(src, i+1)
+		case (c == 'u' || c == 'U') && hasCSSURLAt(src, i):
+			i = skipCSSURL(src, i+4)
+		case c == '(':
+			parens++
+			i++
+		case c == ')':
+			if parens > 0 {
+				parens--
+			}
+			i++
+		case c == ';' && parens == 0:
+			emit(i, false)
+			from, i = i+1, i+1
+		case c == '{':
+			emit(i, true)
+			open = append(open, len(stmts)-1)
+			from, parens, i = i+1, 0, i+1
+		case c == '}':
+			emit(i, false)
+			if len(open) > 0 {
+				stmts[open[len(open)-1]].end = i + 1
+				open = open[:len(open)-1]
+			}
+			from, parens, i = i+1, 0, i+1
+		default:
+			i++
+		}
+	}
+	emit(len(src), false)
+	for _, b := range open {
+		stmts[b].end = len(src) // never closed
+	}
+	return masked, stmts
+}
+
+// skipCSSString returns the offset just past the '…' or "…" string at i, or
+// the end of its line for one left open.
+func skipCSSString(src []byte, i int) int {
+	q := src[i]
+	for i++; i < len(src) && src[i] != '\n'; i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case q:
+			return i + 1
+		}
+	}
+	return i
+}
+
+// skipCSSInterpolation returns the offset just past the `{…}` of a `#{…}`
+// interpolation whose `{` is at i, or the end of src.
+func skipCSSInterpolation(src []byte, i int) int {
+	depth := 0
+	for ; i < len(src); i++ {
+		switch src[i] {
+		case '"', '\'':
+			i = skipCSSString(src, i) - 1
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(src)
+}
+
+// hasCSSURLAt reports whether an unquoted `url(` starts at i: its argument
+// may hold `//`, `;`, and quotes that are none of a comment, a statement
+// end, or a string.
+func hasCSSURLAt(src []byte, i int) bool {
+	if i > 0 && isCSSWordByte(src[i-1]) || i+4 > len(src) || !strings.EqualFold(string(src[i:i+4]), "url(") {
+		return false
+	}
+	j := i + 4
+	for j < len(src) && isCSSSpace(src[j]) {
+		j++
+	}
+	return j < len(src) && src[j] != '"' && src[j] != '\''
+}
+
+// skipCSSURL returns the offset just past the `)` closing an unquoted url()
+// argument that starts at i, or the end of its line if it never closes.
+func skipCSSURL(src []byte, i int) int {
+	for ; i < len(src) && src[i] != '\n'; i++ {
+		if src[i] == ')' {
+			return i + 1
+		}
+	}
+	return i
+}
+
+func isCSSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isCSSWordByte(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const scssSample = `@use "sass:math";
+@use './tokens' as t;
+@forward "src/list" hide list-reset;
+@import "reset", 'grid';
+@import url("https://fonts.example.com/inter.css") screen;
+
+// @mixin commented-out { }
+/* @import "not-imported"; */
+$brand-primary: #0055ff !default;
+$_internal-scale: 1.25;
+$breakpoints: (small: 576px, large: 992px);
+
+:root {
+  --space-1: 4px;
+  --font-body: "Inter; sans-serif", system-ui;
+  --logo: url(//cdn.example.com/logo.svg);
+}
+
+@media (prefers-color-scheme: dark) {
+  :root { --surface: #111; }
+}
+
+[data-theme="dark"] { --surface: #000; }
+
+.button {
+  --local: 1px;
+  padding: math.div($brand-primary, 2);
+}
+
+@mixin focus-ring($color: $brand-primary) {
+  outline: 2px solid $color;
+  &:hover { outline-width: 3px; }
+}
+
+@function rem($px) {
+  @return math.div($px, 16px) * 1rem;
+}
+
+@mixin _private-helper { content: "{"; }
+`
+
+func TestCSSParser_Extension(t *testing.T) {
+	p := NewCSSParser()
+	for _, ext := range []string{".css", ".scss"} {
+		if !p.SupportsExtension(ext) {
+			t.Errorf("want %s supported", ext)
+		}
+	}
+	if p.SupportsExtension(".sass") || p.SupportsExtension(".less") {
+		t.Error("must not claim other stylesheet syntaxes")
+	}
+}
+
+func TestCSSParser_Symbols(t *testing.T) {
+	got, err := NewCSSParser().Parse(scssSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"./tokens", "grid", "https://fonts.example.com/inter.css", "reset", "sass:math", "src/list"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if want := []string{"_private-helper", "focus-ring", "rem"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+	if len(got.Classes) != 0 {
+		t.Errorf("Classes = %q, want none", got.Classes)
+	}
+	want := []string{"$brand-primary", "$breakpoints", "--font-body", "--logo", "--space-1", "--surface", "focus-ring", "rem"}
+	if !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+}
+
+// Plain CSS has no Sass constructs but the same tokens and imports.
+func TestCSSParser_PlainCSS(t *testing.T) {
+	src := "@charset \"utf-8\";\n@import url(theme.css);\n@layer tokens {\n  html, :root { --radius: 4px }\n}\na { color: red }\n"
+	got, _ := NewCSSParser().Parse(src)
+	if want := []string{"theme.css"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if want := []string{"--radius"}; !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+	if got.SymbolLines["export:--radius"] != 4 {
+		t.Errorf("--radius line = %d, want 4", got.SymbolLines["export:--radius"])
+	}
+}
+
+func TestCSSParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "/* @mixin a {} */\n// @mixin b {}\n.x::before { content: \"@mixin c { }\"; }\n.y { background: url(data:image/png;base64,AAAA//8=); }\n@mixin real { }\n"
+	got, _ := NewCSSParser().Parse(src)
+	if want := []string{"real"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+}
+
+func TestCSSParser_HashesAndLines(t *testing.T) {
+	got, _ := NewCSSParser().Parse(scssSample)
+	for key, want := range map[string]int{
+		"function:focus-ring":   30,
+		"function:rem":          35,
+		"export:$brand-primary": 9,
+		"export:--space-1":      14,
+		"export:--surface":      20,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(scssSample, "outline-width: 3px", "outline-width: 4px", 1)
+	after, _ := NewCSSParser().Parse(edited)
+	if after.SymbolHashes["function:focus-ring"] == got.SymbolHashes["function:focus-ring"] {
+		t.Error("editing a nested rule did not change the mixin's hash")
+	}
+	if after.SymbolHashes["function:rem"] != got.SymbolHashes["function:rem"] {
+		t.Error("editing focus-ring changed rem's hash")
+	}
+	if after.SymbolHashes["export:--space-1"] != got.SymbolHashes["export:--space-1"] {
+		t.Error("editing focus-ring changed a token's hash")
+	}
+}
+
+func TestCSSParser_CRLFParity(t *testing.T) {
+	lf, _ := NewCSSParser().Parse(scssSample)
+	crlf, _ := NewCSSParser().Parse(strings.ReplaceAll(scssSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestCSSParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "{", "}", ":root { --a: 1", "@mixin", "@import", "/* open", "a { b: url(", "#{", "'open\n@mixin m {}"} {
+		got, err := NewCSSParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		assertParserInvariants(t, got)
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzCSSParser asserts the CSS/SCSS parser never panics on arbitrary input
+// and keeps the shared invariants.
+// Run: go test -run=x -fuzz=FuzzCSSParser ./internal/parser
+func FuzzCSSParser(f *testing.F) {
+	seeds := []string{
+		scssSample,
+		":root { --a: 1; }",
+		"@media screen { :root { --b: 2 } }",
+		"@mixin m($x) { .#{$x} { a: b } }",
+		"@use 'a' with ($b: 'c');",
+		"", "{", "}", "/*", "//", "url(", "#{", "\"",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewCSSParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -0,0 +1,427 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// DartParser implements structural parsing for .dart files using the vendored
+// pure-Go tree-sitter Dart grammar. Flutter code is mostly nested constructor
+// calls whose closures and string interpolations (`'${a.map((x) { … })}'`)
+// bury braces a masking scan would count.
+//
+// Symbol routing:
+//   - `class` / `mixin` / `enum` / named `extension` / `typedef` → Classes.
+//     Dart has no nested types, so names are bare ("MyApp").
+//   - Functions and methods → Functions, methods qualified by their type
+//     ("MyApp.build"). Constructors are named as a caller writes them:
+//     "MyApp.named", "MyApp.fromJson", and "MyApp.MyApp" for the unnamed one.
+//     An extension's methods are qualified by the type it is `on`
+//     ("String.ext"), as the Kotlin parser qualifies extension functions. Operators have no name a caller writes and are skipped.
+//   - Variables, fields, getters, and setters → Exports when public; located,
+//     not hashed. A getter reads like a field at the call site, so it is
+//     listed with the fields rather than the functions.
+//   - `import` and `part` → Imports, as the URI: "package:flutter/material.dart",
+//     "dart:async", "src/app.g.dart".
+//   - `export 'uri' show A, B;` → Exports A and B. A bare or `hide` export
+//     re-exports names this file cannot enumerate, so its URI goes to
+//     WildcardReexports, as for a JS/TS `export * from`.
+//   - A named `library` directive → Exports ("acme.web"): it is what a
+//     `part of acme.web;` names, and nothing ties it to the file's path.
+//
+// Visibility is Dart's: a name starting with `_` is private to its library,
+// and so are the members of a private type or of a private or unnamed
+// extension; every other name is exported. As in the Java parser, every type
+// and function is still listed whatever its visibility.
+//
+// Function bodies are not descended into: local functions and closures have
+// no name a caller elsewhere could write.
+type DartParser struct{}
+
+// NewDartParser creates a new Dart parser.
+func NewDartParser() *DartParser { return &DartParser{} }
+
+// SupportsExtension returns true for .dart files.
+func (p *DartParser) SupportsExtension(ext string) bool {
+	return ext == ".dart"
+}
+
+var (
+	dartLangOnce sync.Once
+	dartLang     *ts.Language
+)
+
+func dartLanguage() *ts.Language {
+	dartLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("Dart", r)
+			}
+		}()
+		dartLang = grammars.DartLanguage()
+	})
+	return dartLang
+}
+
+// Parse extracts structure from Dart source via tree-sitter. Best-effort on
+// parse errors: tree-sitter recovers to a partial tree for most mid-edit
+// buffers, and we walk whatever it produced.
+func (p *DartParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, reexports, hashes, lines := dartSymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	fs := FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}
+	if len(reexports) > 0 {
+		sort.Strings(reexports)
+		fs.WildcardReexports = deduplicate(reexports)
+	}
+	return fs, nil
+}
+
+// dartTypeDecls are the declarations that define a named type.
+var dartTypeDecls = map[string]bool{
+	"class_definition":      true,
+	"mixin_declaration":     true,
+	"enum_declaration":      true,
+	"extension_declaration": true,
+	"type_alias":            true,
+}
+
+func dartSymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports, reexports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("Dart parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			reexports, hashes, lines = nil, nil, nil
+		}
+	}()
+
+	lang := dartLanguage()
+	if lang == nil {
+		diag.noGrammar("Dart")
+		return imports, functions, classes, exports, nil, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("Dart source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil, nil
+	}
+	// As for Ruby: an ERROR root means nothing below it is a declaration, so
+	// say the symbols are missing rather than let them read as absent.
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("Dart file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+
+	// recordHash combines on collision: a getter and a same-named setter's
+	// owner, or a buffer declaring one name twice mid-edit.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+	recordLine := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+	addExport := func(full, name string, line int, visible bool) {
+		if visible && dartPublic(name) {
+			exports = append(exports, full)
+			recordLine("export:"+full, line)
+		}
+	}
+
+	// member handles one signature or variable list. prefix is the enclosing
+	// type ("" at top level), owner the class constructors belong to, and
+	// visible whether the enclosing declaration is reachable from other
+	// libraries. end is the node whose end closes the symbol's span: the
+	// function body that follows a signature as its sibling, or the signature
+	// itself.
+	var member func(c, end *ts.Node, prefix, owner string, visible bool)
+	member = func(c, end *ts.Node, prefix, owner string, visible bool) {
+		line := int(c.StartPoint().Row) + 1
+		switch c.Type(lang) {
+		case "method_signature", "declaration":
+			for j := 0; j < c.NamedChildCount(); j++ {
+				member(c.NamedChild(j), end, prefix, owner, visible)
+			}
+
+		case "function_signature":
+			name := rustFieldText(c, "name", lang, src)
+			if name == "" {
+				return
+			}
+			full := qualify(prefix, name)
+			functions = append(functions, full)
+			recordHash("function:"+full, src[c.StartByte():end.EndByte()])
+			recordLine("function:"+full, line)
+			if visible && dartPublic(name) {
+				exports = append(exports, full)
+			}
+
+		case "constructor_signature", "factory_constructor_signature", "redirecting_factory_constructor_signature", "constant_constructor_signature":
+			if owner == "" {
+				return
+			}
+			name := dartConstructorName(c, lang, src, owner)
+			full := qualify(owner, name)
+			functions = append(functions, full)
+			recordHash("function:"+full, src[c.StartByte():end.EndByte()])
+			recordLine("function:"+full, line)
+			if visible && dartPublic(name) {
+				exports = append(exports, full)
+			}
+
+		case "getter_signature", "setter_signature":
+			if name := rustFieldText(c, "name", lang, src); name != "" {
+				addExport(qualify(prefix, name), name, line, visible)
+			}
+
+		case "static_final_declaration_list", "initialized_identifier_list":
+			for j := 0; j < c.NamedChildCount(); j++ {
+				d := c.NamedChild(j)
+				if name := dartChildText(d, "identifier", lang, src); name != "" {
+					addExport(qualify(prefix, name), name, int(d.StartPoint().Row)+1, visible)
+				}
+			}
+
+		case "enum_constant":
+			if name := rustFieldText(c, "name", lang, src); name != "" {
+				addExport(qualify(prefix, name), name, line, visible)
+			}
+		}
+	}
+
+	// body visits a class, mixin, enum, or extension body, pairing each
+	// signature with the function body that follows it.
+	body := func(n *ts.Node, prefix, owner string, visible bool) {
+		for i := 0; i < n.NamedChildCount(); i++ {
+			c := n.NamedChild(i)
+			end := c
+			if next := n.NamedChild(i + 1); next != nil && next.Type(lang) == "function_body" {
+				end = next
+			}
+			member(c, end, prefix, owner, visible)
+		}
+	}
+
+	var walk func(n *ts.Node, depth int)
+	walk = func(n *ts.Node, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			c := n.NamedChild(i)
+			kind := c.Type(lang)
+			span := src[c.StartByte():c.EndByte()]
+			line := int(c.StartPoint().Row) + 1
+
+			if dartTypeDecls[kind] {
+				// A mixin's and a typedef's names are unfielded children.
+				name := rustFieldText(c, "name", lang, src)
+				switch kind {
+				case "mixin_declaration":
+					name = dartChildText(c, "identifier", lang, src)
+				case "type_alias":
+					name = dartChildText(c, "type_identifier", lang, src)
+				}
+				// An unnamed extension adds no type, only members.
+				if name != "" {
+					classes = append(classes, name)
+					recordHash("class:"+name, span)
+					recordLine("class:"+name, line)
+					if dartPublic(name) {
+						exports = append(exports, name)
+					}
+				}
+				// Members of a private type, or of a private or unnamed
+				// extension, cannot be reached from another library.
+				prefix, owner := name, name
+				if kind == "extension_declaration" {
+					owner = ""
+					if on := c.ChildByFieldName("class", lang); on != nil {
+						prefix = dartTypeName(on.Text(src))
+					}
+				}
+				for j := 0; j < c.NamedChildCount(); j++ {
+					switch b := c.NamedChild(j); b.Type(lang) {
+					case "class_body", "enum_body", "extension_body":
+						if prefix != "" {
+							body(b, prefix, owner, dartPublic(name))
+						}
+					}
+				}
+				continue
+			}
+
+			switch kind {
+			case "import_or_export":
+				for j := 0; j < c.NamedChildCount(); j++ {
+					d := c.NamedChild(j)
+					switch d.Type(lang) {
+					case "library_import":
+						if uri := dartURI(d, lang, src); uri != "" {
+							imports = append(imports, uri)
+						}
+					case "library_export":
+						shown := dartShown(d, lang, src)
+						if len(shown) > 0 {
+							for _, name := range shown {
+								addExport(name, name, int(d.StartPoint().Row)+1, true)
+							}
+						} else if uri := dartURI(d, lang, src); uri != "" {
+							reexports = append(reexports, uri)
+						}
+					}
+				}
+
+			case "part_directive":
+				if uri := dartURI(c, lang, src); uri != "" {
+					imports = append(imports, uri)
+				}
+
+			case "library_name":
+				// `library;` names nothing.
+				if name := strings.Join(strings.Fields(dartChildText(c, "dotted_identifier_list", lang, src)), ""); name != "" {
+					addExport(name, name, line, true)
+				}
+
+			case "function_signature", "getter_signature", "setter_signature",
+				"static_final_declaration_list", "initialized_identifier_list":
+				end := c
+				if next := n.NamedChild(i + 1); next != nil && next.Type(lang) == "function_body" {
+					end = next
+				}
+				member(c, end, "", "", true)
+
+			case "ERROR":
+				// A mid-edit buffer's ERROR node can still hold whole
+				// declarations.
+				walk(c, depth+1)
+			}
+		}
+	}
+	walk(tree.RootNode(), 0)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, reexports, hashes, lines
+}
+
+// dartPublic reports whether a Dart name is visible outside its library.
+func dartPublic(name string) bool {
+	return name != "" && !strings.HasPrefix(name, "_")
+}
+
+// dartChildText returns the text of n's first named child of the given kind.
+func dartChildText(n *ts.Node, kind string, lang *ts.Language, src []byte) string {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Type(lang) == kind {
+			return c.Text(src)
+		}
+	}
+	return ""
+}
+
+// dartConstructorName returns a constructor's name as a caller writes it
+// after the class: "named" for `MyApp.named(…)`, and the class name itself
+// for the unnamed constructor.
+func dartConstructorName(n *ts.Node, lang *ts.Language, src []byte, owner string) string {
+	var ids []string
+	for i := 0; i < n.ChildCount(); i++ {
+		c := n.Child(i)
+		if c.Type(lang) == "formal_parameter_list" {
+			break
+		}
+		if c.Type(lang) == "identifier" {
+			ids = append(ids, c.Text(src))
+		}
+	}
+	if len(ids) < 2 {
+		return owner
+	}
+	return ids[len(ids)-1]
+}
+
+// dartURI returns the URI of an import, export, or part directive, without
+// its quotes.
+func dartURI(n *ts.Node, lang *ts.Language, src []byte) string {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		c := n.NamedChild(i)
+		switch c.Type(lang) {
+		case "uri":
+			return strings.Trim(strings.TrimSpace(c.Text(src)), `'"`)
+		case "import_specification", "configurable_uri":
+			if uri := dartURI(c, lang, src); uri != "" {
+				return uri
+			}
+		}
+	}
+	return ""
+}
+
+// dartShown returns the names an export's `show` combinators list.
+func dartShown(n *ts.Node, lang *ts.Language, src []byte) []string {
+	var names []string
+	for i := 0; i < n.NamedChildCount(); i++ {
+		c := n.NamedChild(i)
+		if c.Type(lang) != "combinator" || c.ChildCount() == 0 || c.Child(0).Type(lang) != "show" {
+			continue
+		}
+		for j := 0; j < c.NamedChildCount(); j++ {
+			if id := c.NamedChild(j); id.Type(lang) == "identifier" {
+				names = append(names, id.Text(src))
+			}
+		}
+	}
+	return names
+}
+
+// dartTypeName returns a type's name without type arguments, nullability, or
+// whitespace.
+func dartTypeName(text string) string {
+	if i := strings.IndexAny(text, "<?"); i >= 0 {
+		text = text[:i]
+	}
+	return strings.Join(strings.Fields(text), "")
+}
@@ -0,0 +1,206 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const dartSample = `library acme.app;
+
+import 'package:flutter/material.dart';
+import 'dart:async' as async show Future;
+import '../util.dart' deferred as util;
+export 'src/widgets.dart' hide Secret;
+export 'src/models.dart' show User, Role;
+part 'app.g.dart';
+
+const top = 1;
+final _hidden = 2;
+var a = 1, b = 2;
+late String name;
+
+void main() { void local() {} runApp(MyApp()); }
+int _priv() => 1;
+Future<void> load() async {}
+String get version => '1';
+
+@immutable
+class MyApp extends StatelessWidget with Mixin implements Api {
+  static const k = 1;
+  final int id;
+  int _count = 0;
+  MyApp({super.key, required this.id});
+  MyApp.named() : id = 0;
+  factory MyApp.fromJson(Map j) => MyApp(id: 1);
+  @override
+  Widget build(BuildContext context) { return Text('class Fake {}'); }
+  void _helper() {}
+  int get count => _count;
+  set count(int v) => _count = v;
+  static MyApp of(context) => throw '';
+  bool operator ==(Object o) => true;
+}
+
+abstract class Api { void fetch(); }
+mixin Mixin on Object { void mix() {} }
+enum Color { red, green; int get rgb => 0; }
+extension StringX on String { int ext() => length; }
+extension on int { int twice() => this * 2; }
+typedef Cb = void Function(int);
+class _Private { void pub() {} int field = 0; }
+`
+
+func TestDartParser_Extension(t *testing.T) {
+	p := NewDartParser()
+	if !p.SupportsExtension(".dart") {
+		t.Error("want .dart supported")
+	}
+	if p.SupportsExtension(".js") || p.SupportsExtension(".java") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestDartParser_Symbols(t *testing.T) {
+	got, err := NewDartParser().Parse(dartSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// Imports and parts are listed; exports are not.
+	wantImports := []string{"../util.dart", "app.g.dart", "dart:async", "package:flutter/material.dart"}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports:\n got %q\nwant %q", got.Imports, wantImports)
+	}
+	// The unnamed extension adds no type.
+	wantClasses := []string{"Api", "Cb", "Color", "Mixin", "MyApp", "StringX", "_Private"}
+	if !reflect.DeepEqual(got.Classes, wantClasses) {
+		t.Errorf("Classes:\n got %q\nwant %q", got.Classes, wantClasses)
+	}
+	// Constructors are named as called, extension methods by the extended
+	// type; the local function and the operator are not listed.
+	wantFunctions := []string{
+		"Api.fetch", "Mixin.mix", "MyApp.MyApp", "MyApp._helper", "MyApp.build", "MyApp.fromJson",
+		"MyApp.named", "MyApp.of", "String.ext", "_Private.pub", "_priv", "int.twice", "load", "main",
+	}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+	// A `hide` export re-exports what this file cannot enumerate.
+	if want := []string{"src/widgets.dart"}; !reflect.DeepEqual(got.WildcardReexports, want) {
+		t.Errorf("WildcardReexports = %q, want %q", got.WildcardReexports, want)
+	}
+	// The library's name, which a `part of` names, is listed with the
+	// exports; an unnamed `library;` adds nothing.
+	if line := got.SymbolLines["export:acme.app"]; line != 1 {
+		t.Errorf("export:acme.app line = %d, want 1", line)
+	}
+	if unnamed, _ := NewDartParser().Parse("library;\n\nvoid main() {}\n"); !reflect.DeepEqual(unnamed.Exports, []string{"main"}) {
+		t.Errorf("unnamed library Exports = %q, want [main]", unnamed.Exports)
+	}
+}
+
+// Exports leave out underscore names and the members of private types and
+// of the unnamed extension; a `show` export's names are listed.
+func TestDartParser_Visibility(t *testing.T) {
+	got, _ := NewDartParser().Parse(dartSample)
+	wantExports := []string{
+		"Api", "Api.fetch", "Cb", "Color", "Color.green", "Color.red", "Color.rgb", "Mixin",
+		"Mixin.mix", "MyApp", "MyApp.MyApp", "MyApp.build", "MyApp.count", "MyApp.fromJson",
+		"MyApp.id", "MyApp.k", "MyApp.named", "MyApp.of", "Role", "String.ext", "StringX",
+		"User", "a", "acme.app", "b", "load", "main", "name", "top", "version",
+	}
+	if !reflect.DeepEqual(got.Exports, wantExports) {
+		t.Errorf("Exports:\n got %q\nwant %q", got.Exports, wantExports)
+	}
+}
+
+func TestDartParser_HashesAndLines(t *testing.T) {
+	got, _ := NewDartParser().Parse(dartSample)
+	for key, want := range map[string]int{
+		"function:main":        15,
+		"class:MyApp":          20, // annotations included
+		"function:MyApp.named": 26,
+		"function:MyApp.build": 29,
+		"export:MyApp.id":      23,
+		"export:b":             12,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	// A signature and its body are siblings in the tree; the hash covers both.
+	edited := strings.Replace(dartSample, "Text('class Fake {}')", "Text('changed')", 1)
+	after, _ := NewDartParser().Parse(edited)
+	if after.SymbolHashes["function:MyApp.build"] == got.SymbolHashes["function:MyApp.build"] {
+		t.Error("editing the method body did not change the symbol hash")
+	}
+	if after.SymbolHashes["function:MyApp.of"] != got.SymbolHashes["function:MyApp.of"] {
+		t.Error("unrelated symbol's hash changed")
+	}
+	arrow := strings.Replace(dartSample, "int _priv() => 1;", "int _priv() => 2;", 1)
+	if a, _ := NewDartParser().Parse(arrow); a.SymbolHashes["function:_priv"] == got.SymbolHashes["function:_priv"] {
+		t.Error("editing an arrow body did not change the symbol hash")
+	}
+}
+
+func TestDartParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "class A {\n" +
+		"  // void commented() {}\n" +
+		"  /* class Fake { /* nested */ } */\n" +
+		"  final s = '${xs.map((x) { return \"void interp() {}\"; })}';\n" +
+		"  final r = '''\n    class Raw { }\n  ''';\n" +
+		"  void real() {}\n}\n"
+	got, _ := NewDartParser().Parse(src)
+	if !reflect.DeepEqual(got.Functions, []string{"A.real"}) || !reflect.DeepEqual(got.Classes, []string{"A"}) {
+		t.Errorf("Functions %q, Classes %q; want only A and A.real", got.Functions, got.Classes)
+	}
+}
+
+func TestDartParser_CRLFParity(t *testing.T) {
+	lf, _ := NewDartParser().Parse(dartSample)
+	crlf, _ := NewDartParser().Parse(strings.ReplaceAll(dartSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestDartParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "class Broken {", "void \x00\xff", "}}}}"} {
+		got, err := NewDartParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzDartParser asserts the Dart parser never panics on arbitrary input and
+// keeps the invariants the other tree-sitter parsers keep.
+// Run: go test -run=x -fuzz=FuzzDartParser ./internal/parser
+func FuzzDartParser(f *testing.F) {
+	seeds := []string{
+		dartSample,
+		"void f() {}",
+		"class A { A.b(); void c() => 1; }",
+		"extension on int { int get d => 1; }",
+		"enum E { x, y }",
+		"export 'a.dart' show B hide C;",
+		"final s = '''\nunterminated",
+		"", "class", "}}}", "static static", "final s = '${",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewDartParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -0,0 +1,217 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DockerfileParser implements structural parsing for Dockerfiles: the images
+// a build starts from and the stages it names. Each `FROM` image reference
+// goes to Imports as written, tag or digest included ("golang:1.25-alpine"),
+// so bumping a base image shows as an import change; `FROM scratch` and a
+// `FROM` of an earlier stage are not images. Each `AS` stage name goes to
+// Exports — it is what `COPY --from=` and `docker build --target` refer to —
+// hashed over the stage's instructions so an edited `RUN` shows as modified.
+// A Dockerfile has no functions or types, so Functions and Classes are always
+// empty.
+//
+// Dockerfiles are usually named rather than suffixed, so besides the
+// `.dockerfile` extension the parser claims `Dockerfile`, `Containerfile`,
+// and their `Dockerfile.dev`-style variants by name (see FilenameParser).
+//
+// The engine is a line scan, like the shell and SQL parsers, rather than the
+// tree-sitter Dockerfile grammar, which reads a `RUN <<EOF` heredoc's body as
+// instructions. The scan joins continuation lines, drops comment lines,
+// honours an `# escape=` directive, and skips heredoc bodies, so a `FROM`
+// inside a heredoc or a quoted `RUN` argument is never an instruction.
+//
+// Known limitations: build args are not expanded, so `FROM golang:${GO}` is
+// recorded with the `${GO}` in it; images pulled only by `COPY --from=` or
+// `RUN --mount=from=` are not imports.
+type DockerfileParser struct{}
+
+// NewDockerfileParser creates a new Dockerfile parser.
+func NewDockerfileParser() *DockerfileParser { return &DockerfileParser{} }
+
+// SupportsExtension returns true for .dockerfile files.
+func (p *DockerfileParser) SupportsExtension(ext string) bool {
+	return ext == ".dockerfile"
+}
+
+// SupportsFilename returns true for Dockerfile and Containerfile, in any
+// case, with or without a variant suffix (Dockerfile.dev), and for names
+// ending in .Dockerfile (api.Dockerfile).
+func (p *DockerfileParser) SupportsFilename(name string) bool {
+	lower := strings.ToLower(name)
+	for _, base := range []string{"dockerfile", "containerfile"} {
+		if lower == base || strings.HasPrefix(lower, base+".") {
+			return true
+		}
+	}
+	return strings.HasSuffix(lower, ".dockerfile")
+}
+
+// reDockerEscape matches the `# escape=` parser directive.
+var reDockerEscape = regexp.MustCompile(`^#\s*escape\s*=\s*(\S)\s*$`)
+
+// reDockerDirective matches any parser directive (`# syntax=…`, `# check=…`).
+var reDockerDirective = regexp.MustCompile(`^#\s*[A-Za-z]+\s*=`)
+
+// reDockerHeredoc matches a heredoc opener in a RUN, COPY, or ADD
+// instruction; group 1 is the `-` that strips leading tabs, group 3 the word.
+var reDockerHeredoc = regexp.MustCompile(`(?:^|\s)<<(-?)(["']?)([A-Za-z_][A-Za-z0-9_]*)(["']?)`)
+
+// dockerInstruction is one logical instruction: its text with continuations
+// joined, and the 0-based lines it spans, heredoc bodies included.
+type dockerInstruction struct {
+	text        string
+	first, last int
+}
+
+// Parse extracts the base images and stage names of a Dockerfile.
+// Best-effort and never errors: a malformed line is skipped.
+func (p *DockerfileParser) Parse(source string) (FileStructure, error) {
+	// Normalize CRLF→LF so hashes and line numbers are line-ending-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	lines := strings.Split(source, "\n")
+
+	imports, exports := []string{}, []string{}
+	hashes := make(map[string]string)
+	starts := make(map[string]int)
+	stages := map[string]bool{}
+
+	// A named stage runs from its FROM through the last instruction before
+	// the next FROM.
+	stage, stageFirst, stageLast := "", 0, 0
+	closeStage := func() {
+		if stage == "" {
+			return
+		}
+		key := "export:" + stage
+		h := hashBytesHex([]byte(strings.Join(lines[stageFirst:stageLast+1], "\n")))
+		// Combine on collision: two stages given one name.
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+		if _, ok := starts[key]; !ok {
+			starts[key] = stageFirst + 1
+		}
+		stage = ""
+	}
+
+	for _, in := range dockerInstructions(lines) {
+		fields := strings.Fields(in.text)
+		if !strings.EqualFold(fields[0], "FROM") {
+			stageLast = in.last
+			continue
+		}
+		closeStage()
+		args := fields[1:]
+		for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+		image := args[0]
+		if !strings.EqualFold(image, "scratch") && !stages[strings.ToLower(image)] {
+			imports = append(imports, image)
+		}
+		if len(args) >= 3 && strings.EqualFold(args[1], "AS") {
+			stage, stageFirst, stageLast = args[2], in.first, in.last
+			stages[strings.ToLower(stage)] = true
+			exports = append(exports, stage)
+		}
+	}
+	closeStage()
+
+	sort.Strings(imports)
+	sort.Strings(exports)
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(starts) == 0 {
+		starts = nil
+	}
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    []string{},
+		Classes:      []string{},
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  starts,
+	}, nil
+}
+
+// dockerInstructions splits a Dockerfile's lines into logical instructions.
+// Blank and comment lines are skipped, inside a continuation too, as the
+// Docker builder does; a line ending in the escape character continues onto
+// the next; a RUN, COPY, or ADD with heredocs also spans their bodies.
+func dockerInstructions(lines []string) []dockerInstruction {
+	escape := "\\"
+	i := 0
+	// Parser directives come first, before any comment or instruction.
+	for ; i < len(lines); i++ {
+		l := strings.TrimSpace(lines[i])
+		if m := reDockerEscape.FindStringSubmatch(l); m != nil && (m[1] == "\\" || m[1] == "`") {
+			escape = m[1]
+		} else if !reDockerDirective.MatchString(l) {
+			break
+		}
+	}
+
+	var out []dockerInstruction
+	for i < len(lines) {
+		l := strings.TrimSpace(lines[i])
+		if l == "" || strings.HasPrefix(l, "#") {
+			i++
+			continue
+		}
+		in := dockerInstruction{first: i}
+		var text strings.Builder
+		for ; i < len(lines); i++ {
+			l := strings.TrimSpace(lines[i])
+			if i > in.first && (l == "" || strings.HasPrefix(l, "#")) {
+				continue
+			}
+			in.last = i
+			if body, ok := strings.CutSuffix(l, escape); ok {
+				text.WriteString(body)
+				text.WriteByte(' ')
+				continue
+			}
+			text.WriteString(l)
+			i++
+			break
+		}
+		in.text = text.String()
+		if strings.TrimSpace(in.text) == "" {
+			continue
+		}
+
+		switch strings.ToUpper(strings.Fields(in.text)[0]) {
+		case "RUN", "COPY", "ADD":
+			for _, m := range reDockerHeredoc.FindAllStringSubmatch(in.text, -1) {
+				if m[2] != m[4] {
+					continue // mismatched quotes: not a heredoc
+				}
+				for ; i < len(lines); i++ {
+					body := lines[i]
+					if m[1] == "-" {
+						body = strings.TrimLeft(body, "\t")
+					}
+					if body == m[3] {
+						in.last = i
+						i++
+						break
+					}
+					in.last = i
+				}
+			}
+		}
+		out = append(out, in)
+	}
+	return out
+}
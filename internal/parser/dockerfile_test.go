@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const dockerfileSample = `# syntax=docker/dockerfile:1
+ARG GO_VERSION=1.25
+FROM --platform=$BUILDPLATFORM golang:${GO_VERSION}-alpine AS build
+WORKDIR /src
+RUN echo "FROM fake AS nope" \
+    && go build ./...
+COPY --from=build /src/app /app
+FROM build as test
+RUN go test ./...
+FROM gcr.io/distroless/static@sha256:abc123
+COPY --from=build /app /app
+RUN <<EOT
+FROM heredoc AS fake
+EOT
+ENTRYPOINT ["/app"]
+`
+
+func TestDockerfileParser_Extension(t *testing.T) {
+	p := NewDockerfileParser()
+	if !p.SupportsExtension(".dockerfile") {
+		t.Error("want .dockerfile supported")
+	}
+	if p.SupportsExtension("") || p.SupportsExtension(".dev") {
+		t.Error("must not claim other extensions")
+	}
+	for _, name := range []string{"Dockerfile", "dockerfile", "Containerfile", "Dockerfile.dev", "api.Dockerfile"} {
+		if !p.SupportsFilename(name) {
+			t.Errorf("want %s supported", name)
+		}
+	}
+	for _, name := range []string{"Makefile", "Dockerfiles", "dockerfile_test.go", ".dockerignore"} {
+		if p.SupportsFilename(name) {
+			t.Errorf("must not claim %s", name)
+		}
+	}
+}
+
+func TestDockerfileParser_Symbols(t *testing.T) {
+	got, err := NewDockerfileParser().Parse(dockerfileSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// Images as written; FROM of an earlier stage is not an image, and the
+	// quoted and heredoc FROMs are not instructions.
+	wantImports := []string{"gcr.io/distroless/static@sha256:abc123", "golang:${GO_VERSION}-alpine"}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports:\n got %q\nwant %q", got.Imports, wantImports)
+	}
+	if want := []string{"build", "test"}; !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+	if len(got.Functions) != 0 || len(got.Classes) != 0 {
+		t.Errorf("Functions %q, Classes %q; want none", got.Functions, got.Classes)
+	}
+}
+
+func TestDockerfileParser_HashesAndLines(t *testing.T) {
+	got, _ := NewDockerfileParser().Parse(dockerfileSample)
+	for key, want := range map[string]int{"export:build": 3, "export:test": 8} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(dockerfileSample, "go build ./...", "go build -trimpath ./...", 1)
+	after, _ := NewDockerfileParser().Parse(edited)
+	if after.SymbolHashes["export:build"] == got.SymbolHashes["export:build"] {
+		t.Error("editing the stage's RUN did not change its hash")
+	}
+	if after.SymbolHashes["export:test"] != got.SymbolHashes["export:test"] {
+		t.Error("unrelated stage's hash changed")
+	}
+}
+
+// A continuation may carry comment lines, and an escape directive swaps the
+// continuation character; a `FROM` in a comment or a <<- heredoc is not one.
+func TestDockerfileParser_ContinuationsAndDirectives(t *testing.T) {
+	src := "# escape=`\n" +
+		"# FROM commented AS c\n" +
+		"FROM `\n" +
+		"  # a comment inside the continuation\n" +
+		"  --platform=linux/amd64 `\n" +
+		"  alpine:3.20 `\n" +
+		"  AS base\n" +
+		"RUN <<-'SH'\n" +
+		"\tFROM nested AS inner\n" +
+		"\tSH\n" +
+		"FROM scratch AS final\n"
+	got, _ := NewDockerfileParser().Parse(src)
+	if want := []string{"alpine:3.20"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if want := []string{"base", "final"}; !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+	if got.SymbolLines["export:final"] != 11 {
+		t.Errorf("export:final start line = %d, want 11", got.SymbolLines["export:final"])
+	}
+}
+
+func TestDockerfileParser_CRLFParity(t *testing.T) {
+	lf, _ := NewDockerfileParser().Parse(dockerfileSample)
+	crlf, _ := NewDockerfileParser().Parse(strings.ReplaceAll(dockerfileSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestDockerfileParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "FROM", "FROM --platform=x", "FROM a AS", "RUN <<EOF\nFROM x", "\\", "FROM \x00\xff"} {
+		got, err := NewDockerfileParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzDockerfileParser pins the instruction splitter's invariants:
+// instructions are ordered, in bounds, and disjoint, and Parse never panics.
+// Run: go test -run=x -fuzz=FuzzDockerfileParser ./internal/parser
+func FuzzDockerfileParser(f *testing.F) {
+	seeds := []string{
+		dockerfileSample,
+		"FROM a AS b\nFROM b",
+		"# escape=`\nFROM a `\n AS b",
+		"RUN <<A <<B\na\nA\nb\nB\nFROM c",
+		"COPY <<\"EOF\" /x\nEOF",
+		"", "FROM", "\\", "<<", "# escape=", "RUN <<-X\n\tX",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewDockerfileParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		lines := strings.Split(src, "\n")
+		next := 0
+		for _, in := range dockerInstructions(lines) {
+			if in.first < next || in.last < in.first || in.last >= len(lines) {
+				t.Fatalf("instruction %d-%d out of order or bounds (next %d, %d lines)", in.first, in.last, next, len(lines))
+			}
+			next = in.last + 1
+		}
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
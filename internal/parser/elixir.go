@@ -0,0 +1,370 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// ElixirParser implements structural parsing for .ex and .exs files using the
+// vendored pure-Go tree-sitter Elixir grammar. Every Elixir construct is a
+// macro call — `def`, `defmodule`, and `alias` parse exactly like `IO.puts` —
+// and `do`/`end` blocks, sigils with user-chosen delimiters (`~r{…}`), and
+// `#{…}` interpolation leave no keyword or bracket a line scan could trust.
+//
+// Symbol routing:
+//   - `defmodule` / `defprotocol` → Classes, nested-qualified the way Elixir
+//     names them ("Acme.Reader.Inner"). `defimpl P, for: T` defines the module
+//     "P.T", as Elixir names it — one per type for `for: [T, U]` — and without
+//     `for:` it implements P for the enclosing module.
+//   - `def` / `defp` / `defmacro` / `defmacrop` / `defguard` / `defguardp` /
+//     `defdelegate` → Functions, qualified by module ("Acme.Reader.fetch").
+//     Clauses and arities share one name and their hashes combine, as
+//     overloads do in the Java parser. Operator definitions and names built
+//     with `unquote` have no name a caller writes and are skipped.
+//   - `alias` / `import` / `use` / `require` → Imports, as the module written:
+//     "Acme.Cache", "Ecto.Query", ":lists". A multi-alias (`alias Acme.{Repo,
+//     User}`) is expanded to one import per module; options such as `as:` and
+//     `only:` are dropped.
+//
+// Visibility is Elixir's: `defp`, `defmacrop`, and `defguardp` are private to
+// their module and every other definition is exported, modules included.
+// As in the Java parser, every module and function is still listed whatever
+// its visibility.
+//
+// Function bodies are not descended into: anonymous functions have no name,
+// and an `alias` inside a function is local to it. A script's top-level
+// statements outside any module declare nothing and are skipped.
+type ElixirParser struct{}
+
+// NewElixirParser creates a new Elixir parser.
+func NewElixirParser() *ElixirParser { return &ElixirParser{} }
+
+// SupportsExtension returns true for .ex and .exs files.
+func (p *ElixirParser) SupportsExtension(ext string) bool {
+	return ext == ".ex" || ext == ".exs"
+}
+
+var (
+	elixirLangOnce sync.Once
+	elixirLang     *ts.Language
+)
+
+func elixirLanguage() *ts.Language {
+	elixirLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("Elixir", r)
+			}
+		}()
+		elixirLang = grammars.ElixirLanguage()
+	})
+	return elixirLang
+}
+
+// Parse extracts structure from Elixir source via tree-sitter. Best-effort on
+// parse errors: tree-sitter recovers to a partial tree for most mid-edit
+// buffers, and we walk whatever it produced.
+func (p *ElixirParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := elixirSymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}, nil
+}
+
+// elixirDefs maps each function-defining macro to whether what it defines is
+// public.
+var elixirDefs = map[string]bool{
+	"def":         true,
+	"defp":        false,
+	"defmacro":    true,
+	"defmacrop":   false,
+	"defguard":    true,
+	"defguardp":   false,
+	"defdelegate": true,
+}
+
+func elixirSymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("Elixir parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			hashes, lines = nil, nil
+		}
+	}()
+
+	lang := elixirLanguage()
+	if lang == nil {
+		diag.noGrammar("Elixir")
+		return imports, functions, classes, exports, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("Elixir source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil
+	}
+	// As for Ruby: an ERROR root means nothing below it is a declaration, so
+	// say the symbols are missing rather than let them read as absent.
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("Elixir file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+
+	// recordHash combines on collision: a function's clauses and arities, and
+	// a module reopened in one file.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+	recordLine := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+
+	// walk visits the calls in n. prefix is the enclosing module's full name,
+	// "" at the top of the file.
+	var walk func(n *ts.Node, prefix string, depth int)
+	walk = func(n *ts.Node, prefix string, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			c := n.NamedChild(i)
+			if c.Type(lang) == "ERROR" {
+				// A mid-edit buffer's ERROR node can still hold whole
+				// definitions.
+				walk(c, prefix, depth+1)
+				continue
+			}
+			if c.Type(lang) != "call" {
+				continue
+			}
+			target := c.ChildByFieldName("target", lang)
+			if target == nil || target.Type(lang) != "identifier" {
+				continue
+			}
+			args := elixirArguments(c, lang)
+			if args == nil {
+				continue
+			}
+			span := src[c.StartByte():c.EndByte()]
+			line := int(c.StartPoint().Row) + 1
+
+			switch macro := target.Text(src); macro {
+			case "defmodule", "defprotocol", "defimpl":
+				first := args.NamedChild(0)
+				if first == nil || first.Type(lang) != "alias" {
+					continue
+				}
+				names := []string{qualify(prefix, elixirModuleName(first.Text(src)))}
+				if macro == "defimpl" {
+					names = elixirImplNames(elixirModuleName(first.Text(src)), prefix, args, lang, src)
+				}
+				for _, full := range names {
+					classes = append(classes, full)
+					exports = append(exports, full)
+					recordHash("class:"+full, span)
+					recordLine("class:"+full, line)
+					if body := elixirBody(c, args, lang, src); body != nil {
+						walk(body, full, depth+1)
+					}
+				}
+
+			case "alias", "import", "use", "require":
+				imports = append(imports, elixirImports(args.NamedChild(0), lang, src)...)
+
+			default:
+				public, ok := elixirDefs[macro]
+				if !ok || prefix == "" {
+					continue
+				}
+				name := elixirDefName(args.NamedChild(0), lang, src)
+				if name == "" {
+					continue
+				}
+				full := qualify(prefix, name)
+				functions = append(functions, full)
+				recordHash("function:"+full, span)
+				recordLine("function:"+full, line)
+				if public {
+					exports = append(exports, full)
+				}
+			}
+		}
+	}
+	walk(tree.RootNode(), "", 0)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, hashes, lines
+}
+
+// elixirArguments returns a call's arguments node, or nil for a bare call.
+func elixirArguments(call *ts.Node, lang *ts.Language) *ts.Node {
+	for i := 0; i < call.NamedChildCount(); i++ {
+		if c := call.NamedChild(i); c.Type(lang) == "arguments" {
+			return c
+		}
+	}
+	return nil
+}
+
+// elixirBody returns the node holding a module's definitions: its `do` block,
+// or the arguments whose `do:` keyword holds a one-line body.
+func elixirBody(call, args *ts.Node, lang *ts.Language, src []byte) *ts.Node {
+	for i := 0; i < call.NamedChildCount(); i++ {
+		if c := call.NamedChild(i); c.Type(lang) == "do_block" {
+			return c
+		}
+	}
+	// The pair, not its value, so walk sees the expression as a child.
+	return elixirKeyword(args, "do", lang, src)
+}
+
+// elixirKeyword returns the pair holding a call's `key:` keyword argument.
+func elixirKeyword(args *ts.Node, key string, lang *ts.Language, src []byte) *ts.Node {
+	for i := 0; i < args.NamedChildCount(); i++ {
+		kw := args.NamedChild(i)
+		if kw.Type(lang) != "keywords" {
+			continue
+		}
+		for j := 0; j < kw.NamedChildCount(); j++ {
+			pair := kw.NamedChild(j)
+			k := pair.ChildByFieldName("key", lang)
+			if k == nil {
+				continue
+			}
+			if strings.TrimSuffix(strings.TrimSpace(k.Text(src)), ":") == key {
+				return pair
+			}
+		}
+	}
+	return nil
+}
+
+// elixirImplNames returns the modules `defimpl proto` defines: "P.T" for
+// `for: T`, one per type for `for: [T, U]`, and P for the enclosing module
+// when `for:` is omitted.
+func elixirImplNames(proto, prefix string, args *ts.Node, lang *ts.Language, src []byte) []string {
+	var forType *ts.Node
+	if pair := elixirKeyword(args, "for", lang, src); pair != nil {
+		forType = pair.ChildByFieldName("value", lang)
+	}
+	if forType == nil {
+		if prefix == "" {
+			return []string{proto}
+		}
+		return []string{qualify(proto, prefix)}
+	}
+	if forType.Type(lang) == "alias" {
+		return []string{qualify(proto, elixirModuleName(forType.Text(src)))}
+	}
+	var names []string
+	if forType.Type(lang) == "list" {
+		for i := 0; i < forType.NamedChildCount(); i++ {
+			if t := forType.NamedChild(i); t.Type(lang) == "alias" {
+				names = append(names, qualify(proto, elixirModuleName(t.Text(src))))
+			}
+		}
+	}
+	return names
+}
+
+// elixirDefName returns the name a `def`-family head defines: "fetch" for
+// `fetch(id)`, `fetch(id) when …`, and the zero-arity `fetch`. Operators and
+// `unquote` names return "".
+func elixirDefName(head *ts.Node, lang *ts.Language, src []byte) string {
+	for head != nil {
+		switch head.Type(lang) {
+		case "identifier":
+			return head.Text(src)
+		case "call":
+			target := head.ChildByFieldName("target", lang)
+			if target == nil || target.Type(lang) != "identifier" {
+				return ""
+			}
+			return target.Text(src)
+		case "binary_operator":
+			op := head.ChildByFieldName("operator", lang)
+			if op == nil || op.Type(lang) != "when" {
+				return ""
+			}
+			head = head.ChildByFieldName("left", lang)
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// elixirImports returns the modules a directive names: one for `Acme.Cache`
+// or `:lists`, one per member of a multi-alias `Acme.{Repo, User}`.
+func elixirImports(n *ts.Node, lang *ts.Language, src []byte) []string {
+	if n == nil {
+		return nil
+	}
+	switch n.Type(lang) {
+	case "alias", "atom":
+		return []string{elixirModuleName(n.Text(src))}
+	case "dot":
+		left, right := n.ChildByFieldName("left", lang), n.ChildByFieldName("right", lang)
+		if left == nil || right == nil || right.Type(lang) != "tuple" {
+			return []string{elixirModuleName(n.Text(src))}
+		}
+		base := elixirModuleName(left.Text(src))
+		var mods []string
+		for i := 0; i < right.NamedChildCount(); i++ {
+			if m := right.NamedChild(i); m.Type(lang) == "alias" {
+				mods = append(mods, qualify(base, elixirModuleName(m.Text(src))))
+			}
+		}
+		return mods
+	}
+	return nil
+}
+
+// elixirModuleName returns a module name with whitespace removed, so
+// `Acme . Reader` and `Acme.Reader` name one module.
+func elixirModuleName(text string) string {
+	return strings.Join(strings.Fields(text), "")
+}
@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const elixirSample = `defmodule Acme.Reader do
+  @moduledoc "defmodule Fake do end"
+  alias Acme.{Repo, User}
+  alias Acme.Cache, as: C
+  import Ecto.Query, only: [from: 2]
+  import :lists
+  use GenServer
+  require Logger
+
+  @type t :: %__MODULE__{}
+  defstruct [:id, :name]
+
+  def fetch(id), do: Repo.get(User, id)
+  def fetch(id, opts) when is_list(opts) do
+    x = fn y -> y end
+    x.(id)
+  end
+  defp helper(a \\ 1), do: a
+  defmacro my_macro(x), do: x
+  defmacrop priv_macro(x), do: x
+  defguard is_ok(x) when x == :ok
+  def unquote(name)(), do: 1
+  def a + b, do: a
+  defdelegate size(x), to: Enum
+
+  defmodule Inner do
+    def go, do: :ok
+  end
+
+  defprotocol Proto do
+    def area(shape)
+  end
+  defimpl Proto, for: Map do
+    def area(_), do: 0
+  end
+  defexception message: "boom"
+end
+
+defmodule Other, do: def(one(), do: 1)
+
+IO.puts("def script_only, do: 1")
+
+`
+
+func TestElixirParser_Extension(t *testing.T) {
+	p := NewElixirParser()
+	for _, ext := range []string{".ex", ".exs"} {
+		if !p.SupportsExtension(ext) {
+			t.Errorf("want %s supported", ext)
+		}
+	}
+	if p.SupportsExtension(".erl") || p.SupportsExtension(".rb") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestElixirParser_Symbols(t *testing.T) {
+	got, err := NewElixirParser().Parse(elixirSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// The multi-alias is expanded; `as:` and `only:` are dropped.
+	wantImports := []string{":lists", "Acme.Cache", "Acme.Repo", "Acme.User", "Ecto.Query", "GenServer", "Logger"}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports:\n got %q\nwant %q", got.Imports, wantImports)
+	}
+	// defimpl names the module Elixir defines, not a nested one.
+	wantClasses := []string{"Acme.Reader", "Acme.Reader.Inner", "Acme.Reader.Proto", "Other", "Proto.Map"}
+	if !reflect.DeepEqual(got.Classes, wantClasses) {
+		t.Errorf("Classes:\n got %q\nwant %q", got.Classes, wantClasses)
+	}
+	// Both fetch clauses share a name; the unquote and operator definitions
+	// and the script-level call are not listed.
+	wantFunctions := []string{
+		"Acme.Reader.Inner.go", "Acme.Reader.Proto.area", "Acme.Reader.fetch", "Acme.Reader.helper",
+		"Acme.Reader.is_ok", "Acme.Reader.my_macro", "Acme.Reader.priv_macro", "Acme.Reader.size",
+		"Other.one", "Proto.Map.area",
+	}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+}
+
+// defimpl defines one module per type it is for, and implements the
+// enclosing module when it names none.
+func TestElixirParser_ImplModules(t *testing.T) {
+	src := "defimpl P, for: [A, B.C] do\n  def f(_), do: 1\nend\n" +
+		"defmodule M do\n  defimpl String.Chars do\n    def to_string(_), do: \"m\"\n  end\nend\n"
+	got, _ := NewElixirParser().Parse(src)
+	wantClasses := []string{"M", "P.A", "P.B.C", "String.Chars.M"}
+	if !reflect.DeepEqual(got.Classes, wantClasses) {
+		t.Errorf("Classes:\n got %q\nwant %q", got.Classes, wantClasses)
+	}
+	wantFunctions := []string{"P.A.f", "P.B.C.f", "String.Chars.M.to_string"}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+}
+
+// Exports leave out defp, defmacrop, and defguardp definitions.
+func TestElixirParser_Visibility(t *testing.T) {
+	got, _ := NewElixirParser().Parse(elixirSample)
+	wantExports := []string{
+		"Acme.Reader", "Acme.Reader.Inner", "Acme.Reader.Inner.go", "Acme.Reader.Proto",
+		"Acme.Reader.Proto.area", "Acme.Reader.fetch", "Acme.Reader.is_ok", "Acme.Reader.my_macro",
+		"Acme.Reader.size", "Other", "Other.one", "Proto.Map", "Proto.Map.area",
+	}
+	if !reflect.DeepEqual(got.Exports, wantExports) {
+		t.Errorf("Exports:\n got %q\nwant %q", got.Exports, wantExports)
+	}
+}
+
+func TestElixirParser_HashesAndLines(t *testing.T) {
+	got, _ := NewElixirParser().Parse(elixirSample)
+	for key, want := range map[string]int{
+		"function:Acme.Reader.fetch": 13, // the first clause anchors the line
+		"class:Acme.Reader.Inner":    26,
+		"function:Proto.Map.area":    34,
+		"function:Other.one":         39,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(elixirSample, "x.(id)", "x.(id + 1)", 1)
+	after, _ := NewElixirParser().Parse(edited)
+	if after.SymbolHashes["function:Acme.Reader.fetch"] == got.SymbolHashes["function:Acme.Reader.fetch"] {
+		t.Error("editing the second clause did not change the symbol hash")
+	}
+	if after.SymbolHashes["function:Acme.Reader.helper"] != got.SymbolHashes["function:Acme.Reader.helper"] {
+		t.Error("unrelated symbol's hash changed")
+	}
+}
+
+func TestElixirParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "defmodule A do\n" +
+		"  # def commented, do: 1\n" +
+		"  @doc \"\"\"\n  defmodule Fake do end\n  \"\"\"\n" +
+		"  @re ~r{def sigil}\n" +
+		"  @s \"#{Enum.map(xs, fn _ -> \"def interp, do: 1\" end)}\"\n" +
+		"  def real, do: 1\nend\n"
+	got, _ := NewElixirParser().Parse(src)
+	if !reflect.DeepEqual(got.Functions, []string{"A.real"}) || !reflect.DeepEqual(got.Classes, []string{"A"}) {
+		t.Errorf("Functions %q, Classes %q; want only A and A.real", got.Functions, got.Classes)
+	}
+}
+
+func TestElixirParser_CRLFParity(t *testing.T) {
+	lf, _ := NewElixirParser().Parse(elixirSample)
+	crlf, _ := NewElixirParser().Parse(strings.ReplaceAll(elixirSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestElixirParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "defmodule Broken do", "def \x00\xff", "end end end"} {
+		got, err := NewElixirParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzElixirParser asserts the Elixir parser never panics on arbitrary input
+// and keeps the invariants the other tree-sitter parsers keep.
+// Run: go test -run=x -fuzz=FuzzElixirParser ./internal/parser
+func FuzzElixirParser(f *testing.F) {
+	seeds := []string{
+		elixirSample,
+		"defmodule A do def b, do: 1 end",
+		"defmodule A, do: defp(c(x), do: x)",
+		"alias A.{B, C.D}",
+		"defimpl P, for: [A, B] do end",
+		"def f(x) when x > 0 and x < 9, do: x",
+		"@doc \"\"\"\nunterminated",
+		"", "defmodule", "end", "def def", "\"#{",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewElixirParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
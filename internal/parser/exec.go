@@ -0,0 +1,250 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ExecParser delegates parsing to an external executable, so a team can add a
+// language (or front a real compiler) without recompiling runecho. The protocol
+// is one process per file:
+//
+//   - stdin: the file's source bytes, verbatim.
+//   - env: RUNECHO_EXT carries the file extension (".ex"), for a plugin that
+//     serves several.
+//   - stdout: one JSON object — {"imports":[], "functions":[], "classes":[],
+//     "exports":[], "wildcard_reexports":[], "symbol_hashes":{},
+//     "symbol_lines":{}} — every field optional.
+//   - exit status: non-zero is a parse failure (stderr is quoted in the error).
+//
+// The IR's same-input-same-output guarantee cannot be delegated to code runecho
+// did not write, so ingest enforces what it can (see normalizeExecOutput): lists
+// are sorted and deduplicated, and output that could not have come from a
+// deterministic, well-formed plugin is rejected rather than indexed.
+type ExecParser struct {
+	name       string
+	command    []string
+	extensions map[string]bool
+	dir        string
+	timeout    time.Duration
+}
+
+// DefaultExecTimeout bounds one plugin invocation. A hung plugin otherwise
+// stalls the whole walk until the generator's own deadline.
+const DefaultExecTimeout = 30 * time.Second
+
+// maxExecOutputBytes caps a plugin's stdout. The JSON for a real file is a few
+// KB; this is a guard against a runaway plugin, not a tuning knob.
+const maxExecOutputBytes = 16 << 20
+
+// NewExecParser returns a parser that runs command (argv, not a shell string)
+// in dir for every file whose extension is in exts.
+func NewExecParser(name string, command []string, exts []string, dir string) (*ExecParser, error) {
+	if len(command) == 0 || command[0] == "" {
+		return nil, fmt.Errorf("parser plugin %q: empty command", name)
+	}
+	set, err := extensionSet(name, exts)
+	if err != nil {
+		return nil, err
+	}
+	return &ExecParser{
+		name:       name,
+		command:    append([]string(nil), command...),
+		extensions: set,
+		dir:        dir,
+		timeout:    DefaultExecTimeout,
+	}, nil
+}
+
+// extensionSet validates a plugin's extension list into a lookup set.
+func extensionSet(name string, exts []string) (map[string]bool, error) {
+	if len(exts) == 0 {
+		return nil, fmt.Errorf("parser plugin %q: no extensions", name)
+	}
+	set := make(map[string]bool, len(exts))
+	for _, e := range exts {
+		if !strings.HasPrefix(e, ".") || len(e) < 2 {
+			return nil, fmt.Errorf("parser plugin %q: extension %q must look like \".ext\"", name, e)
+		}
+		set[e] = true
+	}
+	return set, nil
+}
+
+// Name returns the plugin's configured name.
+func (p *ExecParser) Name() string { return p.name }
+
+// SupportsExtension reports whether ext is one of the plugin's extensions.
+func (p *ExecParser) SupportsExtension(ext string) bool { return p.extensions[ext] }
+
+// Parse runs the plugin with no extension hint.
+func (p *ExecParser) Parse(source string) (FileStructure, error) {
+	return p.ParseExt(source, "")
+}
+
+// ParseExt runs the plugin on source (see ExtAwareParser).
+func (p *ExecParser) ParseExt(source, ext string) (FileStructure, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.command[0], p.command[1:]...)
+	cmd.Dir = p.dir
+	cmd.Env = append(os.Environ(), "RUNECHO_EXT="+ext)
+	cmd.Stdin = strings.NewReader(source)
+	var stdout limitedBuffer
+	stdout.max = maxExecOutputBytes
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return FileStructure{}, fmt.Errorf("parser plugin %q: timed out after %s", p.name, p.timeout)
+		}
+		if stdout.overflow {
+			return FileStructure{}, fmt.Errorf("parser plugin %q: output exceeds %d bytes", p.name, maxExecOutputBytes)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 200 {
+			msg = msg[:200] + "…"
+		}
+		return FileStructure{}, fmt.Errorf("parser plugin %q: %w (stderr: %q)", p.name, err, msg)
+	}
+	if stdout.overflow {
+		return FileStructure{}, fmt.Errorf("parser plugin %q: output exceeds %d bytes", p.name, maxExecOutputBytes)
+	}
+	fs, err := decodeExecOutput(stdout.Bytes())
+	if err != nil {
+		return FileStructure{}, fmt.Errorf("parser plugin %q: %w", p.name, err)
+	}
+	return fs, nil
+}
+
+// execOutput is the plugin's stdout wire shape.
+type execOutput struct {
+	Imports           []string          `json:"imports"`
+	Functions         []string          `json:"functions"`
+	Classes           []string          `json:"classes"`
+	Exports           []string          `json:"exports"`
+	WildcardReexports []string          `json:"wildcard_reexports"`
+	SymbolHashes      map[string]string `json:"symbol_hashes"`
+	SymbolLines       map[string]int    `json:"symbol_lines"`
+}
+
+// decodeExecOutput strictly decodes one JSON object (unknown fields and
+// trailing data are errors — a typo'd field would otherwise silently drop a
+// whole symbol kind) and normalizes it.
+func decodeExecOutput(data []byte) (FileStructure, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var out execOutput
+	if err := dec.Decode(&out); err != nil {
+		return FileStructure{}, fmt.Errorf("decode output: %w", err)
+	}
+	if dec.More() {
+		return FileStructure{}, errors.New("decode output: trailing data after the JSON object")
+	}
+	return normalizeExecOutput(out)
+}
+
+// normalizeExecOutput is the determinism gate on ingest. Lists are sorted and
+// deduplicated, so a plugin that emits symbols in hash-map order still yields a
+// byte-stable IR. What sorting cannot repair is rejected: names that are empty,
+// not UTF-8, or carry a newline (they would corrupt the line-oriented
+// formatters), and hash/line entries keyed to a symbol the plugin never
+// declared (a "kind:name" typo that would otherwise vanish silently).
+func normalizeExecOutput(o execOutput) (FileStructure, error) {
+	fs := FileStructure{}
+	declared := make(map[string]bool)
+	lists := []struct {
+		kind string
+		in   []string
+		dst  *[]string
+	}{
+		{"import", o.Imports, &fs.Imports},
+		{"function", o.Functions, &fs.Functions},
+		{"class", o.Classes, &fs.Classes},
+		{"export", o.Exports, &fs.Exports},
+		{"export_wildcard", o.WildcardReexports, &fs.WildcardReexports},
+	}
+	for _, l := range lists {
+		seen := make(map[string]bool, len(l.in))
+		for _, name := range l.in {
+			if err := validExecName(name); err != nil {
+				return FileStructure{}, fmt.Errorf("%s %q: %w", l.kind, name, err)
+			}
+			if !seen[name] {
+				seen[name] = true
+				*l.dst = append(*l.dst, name)
+			}
+			declared[l.kind+":"+name] = true
+		}
+		sort.Strings(*l.dst)
+	}
+	for key, h := range o.SymbolHashes {
+		if !declared[key] {
+			return FileStructure{}, fmt.Errorf("symbol_hashes key %q names no declared symbol", key)
+		}
+		if h == "" {
+			continue
+		}
+		if fs.SymbolHashes == nil {
+			fs.SymbolHashes = make(map[string]string)
+		}
+		fs.SymbolHashes[key] = h
+	}
+	for key, line := range o.SymbolLines {
+		if !declared[key] {
+			return FileStructure{}, fmt.Errorf("symbol_lines key %q names no declared symbol", key)
+		}
+		if line < 1 {
+			return FileStructure{}, fmt.Errorf("symbol_lines %q: line %d is not 1-based", key, line)
+		}
+		if fs.SymbolLines == nil {
+			fs.SymbolLines = make(map[string]int)
+		}
+		fs.SymbolLines[key] = line
+	}
+	return fs, nil
+}
+
+func validExecName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("empty name")
+	case !utf8.ValidString(name):
+		return errors.New("not valid UTF-8")
+	case strings.ContainsAny(name, "\n\r\x00"):
+		return errors.New("contains a newline or NUL")
+	}
+	return nil
+}
+
+// limitedBuffer is a bytes.Buffer that stops accepting writes past max and
+// records that it overflowed. It reports full writes to the child's pipe copier
+// so the plugin is not killed by EPIPE mid-output; the overflow flag decides.
+type limitedBuffer struct {
+	bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.overflow {
+		return len(p), nil
+	}
+	if b.Len()+len(p) > b.max {
+		b.overflow = true
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// execPlugin writes a /bin/sh plugin script and returns an ExecParser for .ex.
+func execPlugin(t *testing.T, script string) *ExecParser {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugin.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewExecParser("test", []string{"sh", path}, []string{".ex"}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// TestExecParser_NormalizesOnIngest: unsorted, duplicated plugin output becomes
+// the sorted, deduplicated structure a built-in parser would return, and the
+// plugin sees the source on stdin and the extension in RUNECHO_EXT.
+func TestExecParser_NormalizesOnIngest(t *testing.T) {
+	p := execPlugin(t, `src=$(cat)
+printf '{"functions":["zeta","alpha","zeta"],"classes":["%s"],"imports":["%s"],"symbol_lines":{"function:alpha":3}}' "$src" "$RUNECHO_EXT"
+`)
+	if !p.SupportsExtension(".ex") || p.SupportsExtension(".exs") {
+		t.Error("SupportsExtension must match exactly the configured extensions")
+	}
+	got, err := p.ParseExt("Mod", ".ex")
+	if err != nil {
+		t.Fatalf("ParseExt: %v", err)
+	}
+	want := FileStructure{
+		Functions:   []string{"alpha", "zeta"},
+		Classes:     []string{"Mod"},
+		Imports:     []string{".ex"},
+		SymbolLines: map[string]int{"function:alpha": 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestExecParser_RejectsMalformedOutput(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":    `{"funtions":["a"]}`,
+		"trailing data":    `{"functions":["a"]} {}`,
+		"empty name":       `{"functions":[""]}`,
+		"newline in name":  `{"functions":["a\nb"]}`,
+		"undeclared hash":  `{"functions":["a"],"symbol_hashes":{"function:b":"h"}}`,
+		"zero-based line":  `{"functions":["a"],"symbol_lines":{"function:a":0}}`,
+		"not json":         `functions: a`,
+		"kind-mismatched":  `{"classes":["a"],"symbol_lines":{"function:a":1}}`,
+		"wrong field type": `{"functions":"a"}`,
+	}
+	for name, out := range cases {
+		if _, err := decodeExecOutput([]byte(out)); err == nil {
+			t.Errorf("%s: %s decoded without error", name, out)
+		}
+	}
+}
+
+func TestExecParser_FailuresAreErrors(t *testing.T) {
+	if _, err := execPlugin(t, "echo boom >&2\nexit 3\n").Parse("x"); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("non-zero exit: err = %v, want one quoting stderr", err)
+	}
+	slow := execPlugin(t, "exec sleep 5\n")
+	slow.timeout = 50 * time.Millisecond
+	if _, err := slow.Parse("x"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("hung plugin: err = %v, want timeout", err)
+	}
+}
+
+func TestNewExecParser_Validates(t *testing.T) {
+	if _, err := NewExecParser("p", nil, []string{".ex"}, ""); err == nil {
+		t.Error("empty command accepted")
+	}
+	if _, err := NewExecParser("p", []string{"x"}, nil, ""); err == nil {
+		t.Error("no extensions accepted")
+	}
+	if _, err := NewExecParser("p", []string{"x"}, []string{"ex"}, ""); err == nil {
+		t.Error("extension without a leading dot accepted")
+	}
+}
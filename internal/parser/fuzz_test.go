@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"slices"
 	"sort"
 	"testing"
 )
@@ -93,7 +94,29 @@ func FuzzPythonParser(f *testing.F) {
 // the structural contract the IR generator and snapshot symbolizer depend on.
 func assertParserInvariants(t *testing.T, fs FileStructure) {
 	t.Helper()
-	for _, list := range [][]string{fs.Imports, fs.Functions, fs.Classes, fs.Exports} {
+	lists := [][]string{fs.Imports, fs.TypeImports, fs.Functions, fs.Classes, fs.Exports}
+	// Enums, namespaces, and class details are sorted by name, and each names
+	// a class.
+	var enums, namespaces, detailed, variables, modules []string
+	for _, e := range fs.Enums {
+		enums = append(enums, e.Name)
+	}
+	for _, v := range fs.Variables {
+		variables = append(variables, v.Name)
+	}
+	for _, d := range fs.ImportDetails {
+		modules = append(modules, d.Module)
+		lists = append(lists, d.Named)
+	}
+	for _, n := range fs.Namespaces {
+		namespaces = append(namespaces, n.Name)
+	}
+	for _, d := range fs.ClassDetails {
+		detailed = append(detailed, d.Name)
+		lists = append(lists, d.Methods, d.Static)
+	}
+	lists = append(lists, enums, namespaces, detailed, variables, modules)
+	for _, list := range lists {
 		if !sort.StringsAreSorted(list) {
 			t.Fatalf("parser returned unsorted list: %v", list)
 		}
@@ -103,4 +126,32 @@ func assertParserInvariants(t *testing.T, fs FileStructure) {
 			}
 		}
 	}
+	for _, name := range slices.Concat(enums, namespaces, detailed) {
+		if !slices.Contains(fs.Classes, name) {
+			t.Fatalf("enum, namespace, or class detail %q is not in Classes %v", name, fs.Classes)
+		}
+	}
+	for _, m := range fs.TypeImports {
+		if !slices.Contains(fs.Imports, m) {
+			t.Fatalf("type import %q is not in Imports %v", m, fs.Imports)
+		}
+	}
+	// Function details are sorted by name, and each names a function.
+	for i, d := range fs.FunctionDetails {
+		if i > 0 && d.Name <= fs.FunctionDetails[i-1].Name {
+			t.Fatalf("function details unsorted or duplicated: %+v", fs.FunctionDetails)
+		}
+		if !slices.Contains(fs.Functions, d.Name) {
+			t.Fatalf("function detail %q is not in Functions %v", d.Name, fs.Functions)
+		}
+	}
+	// Decorated symbols are sorted by kind, then name, and each is a symbol.
+	for i, d := range fs.Decorators {
+		if i > 0 && (d.Kind < fs.Decorators[i-1].Kind || d.Kind == fs.Decorators[i-1].Kind && d.Name <= fs.Decorators[i-1].Name) {
+			t.Fatalf("decorated symbols unsorted or duplicated: %+v", fs.Decorators)
+		}
+		if _, ok := fs.SymbolLines[d.Kind+":"+d.Name]; !ok || len(d.Decorators) == 0 {
+			t.Fatalf("decorated %s:%s is not a symbol with decorators", d.Kind, d.Name)
+		}
+	}
 }
@@ -62,11 +62,13 @@ func (p *GoParser) Parse(source string) (FileStructure, error) {
 	exports := []string{}
 	hashes := make(map[string]string)
 	lines := make(map[string]int)
+	cols := make(map[string]int)
 
 	// recordLine anchors a symbol at its FIRST definition (parity with Python).
-	recordLine := func(key string, line int) {
+	recordLine := func(key string, pos token.Position) {
 		if _, ok := lines[key]; !ok {
-			lines[key] = line
+			lines[key] = pos.Line
+			cols[key] = pos.Column
 		}
 	}
 	// recordHash combines on collision so a change in ANY variant of a collapsed
@@ -105,7 +107,7 @@ func (p *GoParser) Parse(source string) (FileStructure, error) {
 				functions = append(functions, full)
 				key := "function:" + full
 				recordHash(key, nodeSpan(fset, src, d))
-				recordLine(key, fset.Position(d.Pos()).Line)
+				recordLine(key, fset.Position(d.Pos()))
 			case *ast.GenDecl:
 				collectGenDecl(d, fset, src, &imports, &functions, &classes, &exports, recordLine, recordHash)
 			}
@@ -123,16 +125,17 @@ func (p *GoParser) Parse(source string) (FileStructure, error) {
 		hashes = nil
 	}
 	if len(lines) == 0 {
-		lines = nil
+		lines, cols = nil, nil
 	}
 
 	return FileStructure{
-		Imports:      deduplicate(imports),
-		Functions:    deduplicate(functions),
-		Classes:      deduplicate(classes),
-		Exports:      deduplicate(exports),
-		SymbolHashes: hashes,
-		SymbolLines:  lines,
+		Imports:       deduplicate(imports),
+		Functions:     deduplicate(functions),
+		Classes:       deduplicate(classes),
+		Exports:       deduplicate(exports),
+		SymbolHashes:  hashes,
+		SymbolLines:   lines,
+		SymbolColumns: cols,
 	}, nil
 }
 
@@ -141,7 +144,7 @@ func (p *GoParser) Parse(source string) (FileStructure, error) {
 // two regex-era bugs for free: `var X, Y = 1, 2` yields both names (a ValueSpec
 // carries all of them), and a `var (...)` / `import (...)` block's boundaries are
 // owned by the AST, so a nested `)` no longer closes the block early.
-func collectGenDecl(d *ast.GenDecl, fset *token.FileSet, src []byte, imports, functions, classes, exports *[]string, recordLine func(string, int), recordHash func(string, []byte)) {
+func collectGenDecl(d *ast.GenDecl, fset *token.FileSet, src []byte, imports, functions, classes, exports *[]string, recordLine func(string, token.Position), recordHash func(string, []byte)) {
 	switch d.Tok {
 	case token.IMPORT:
 		for _, spec := range d.Specs {
@@ -164,7 +167,7 @@ func collectGenDecl(d *ast.GenDecl, fset *token.FileSet, src []byte, imports, fu
 			*classes = append(*classes, s.Name.Name)
 			key := "class:" + s.Name.Name
 			recordHash(key, nodeSpan(fset, src, s))
-			recordLine(key, fset.Position(s.Pos()).Line)
+			recordLine(key, fset.Position(s.Pos()))
 			// Descend into an interface body so its method signatures become
 			// referenceable as Type.Method (parity with JS/TS interface methods
 			// and Python class methods). Only methods carry Names + a FuncType;
@@ -186,7 +189,7 @@ func collectGenDecl(d *ast.GenDecl, fset *token.FileSet, src []byte, imports, fu
 					*functions = append(*functions, full)
 					key := "function:" + full
 					recordHash(key, nodeSpan(fset, src, m))
-					recordLine(key, fset.Position(m.Pos()).Line)
+					recordLine(key, fset.Position(m.Pos()))
 				}
 			}
 		}
@@ -201,7 +204,7 @@ func collectGenDecl(d *ast.GenDecl, fset *token.FileSet, src []byte, imports, fu
 					continue
 				}
 				*exports = append(*exports, nm.Name)
-				recordLine("export:"+nm.Name, fset.Position(nm.Pos()).Line)
+				recordLine("export:"+nm.Name, fset.Position(nm.Pos()))
 			}
 		}
 	}
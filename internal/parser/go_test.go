@@ -287,6 +287,12 @@ func (w *Widget) Do() {}
 			t.Errorf("SymbolLines[%q] = %d, want %d", key, got, wantLine)
 		}
 	}
+	// Columns are 1-based: a func at its keyword, a type or const at its name.
+	for key, wantCol := range map[string]int{"export:Answer": 7, "class:Widget": 6, "function:Top": 1} {
+		if got := fs.SymbolColumns[key]; got != wantCol {
+			t.Errorf("SymbolColumns[%q] = %d, want %d", key, got, wantCol)
+		}
+	}
 	// Functions and classes both carry a body hash; exports/imports don't.
 	if fs.SymbolHashes["function:Top"] == "" {
 		t.Error("function:Top has no body hash")
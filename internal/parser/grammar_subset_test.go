@@ -84,7 +84,7 @@ func shipTags(t *testing.T, name, pattern string) string {
 			"it is the only thing standing between a new parser and shipping inert", name, err)
 	}
 	// A file may declare the tags more than once (.goreleaser.yaml has one build
-	// block per binary). Every occurrence must match, or one binary ships with a
+	// block per parsing binary). Every occurrence must match, or one binary ships with a
 	// grammar the others lack.
 	ms := regexp.MustCompile(pattern).FindAllSubmatch(raw, -1)
 	if len(ms) == 0 {
@@ -108,7 +108,7 @@ func shipTags(t *testing.T, name, pattern string) string {
 // grammarsUsedByThisPackage scans the package's non-test sources for
 // `grammars.XxxLanguage` and returns the lowercased language names, which is how
 // the vendored runtime names its subset build tags (RustLanguage →
-// grammar_subset_rust). It matches the identifier without requiring a call,
+// grammar_subset_rust) — except where grammarTagNames says otherwise. It matches the identifier without requiring a call,
 // because the JS parser passes these as function VALUES to a cache helper rather
 // than calling them directly — a call-shaped regex would have missed all three
 // of javascript/typescript/tsx.
@@ -130,7 +130,11 @@ func grammarsUsedByThisPackage(t *testing.T) []string {
 			t.Fatalf("read %s: %v", name, err)
 		}
 		for _, m := range re.FindAllSubmatch(src, -1) {
-			seen[strings.ToLower(string(m[1]))] = true
+			lang := strings.ToLower(string(m[1]))
+			if tag, ok := grammarTagNames[lang]; ok {
+				lang = tag
+			}
+			seen[lang] = true
 		}
 	}
 	out := make([]string, 0, len(seen))
@@ -141,12 +145,17 @@ func grammarsUsedByThisPackage(t *testing.T) []string {
 	return out
 }
 
+// grammarTagNames maps a lowercased grammars.XxxLanguage name to its build
+// tag's language name where the two differ: CSharpLanguage is gated by
+// grammar_subset_c_sharp, after the upstream tree-sitter-c-sharp.
+var grammarTagNames = map[string]string{"csharp": "c_sharp"}
+
 // Belt and braces: under the tags install.sh actually uses, the grammars this
 // package depends on must LOAD rather than return nil. Run with the ship tags:
 //
 //	go test -tags "grammar_subset grammar_subset_python grammar_subset_javascript \
 //	  grammar_subset_typescript grammar_subset_tsx grammar_subset_rust \
-//	  grammar_subset_ruby" ./internal/parser
+//	  grammar_subset_ruby grammar_subset_java grammar_subset_c_sharp grammar_subset_php grammar_subset_kotlin grammar_subset_swift grammar_subset_scala grammar_subset_dart grammar_subset_elixir grammar_subset_lua grammar_subset_proto grammar_subset_solidity grammar_subset_yaml grammar_subset_json" ./internal/parser
 //
 // Under the default full-grammar build this is trivially true; its value is that
 // running the suite with the ship tags catches a subset mismatch the string
@@ -158,6 +167,45 @@ func TestASTGrammarsLoad(t *testing.T) {
 	if rubyLanguage() == nil {
 		t.Error("ruby grammar is nil under these build tags — .rb files index to nothing")
 	}
+	if javaLanguage() == nil {
+		t.Error("java grammar is nil under these build tags — .java files index to nothing")
+	}
+	if csharpLanguage() == nil {
+		t.Error("c_sharp grammar is nil under these build tags — .cs files index to nothing")
+	}
+	if phpLanguage() == nil {
+		t.Error("php grammar is nil under these build tags — .php files index to nothing")
+	}
+	if kotlinLanguage() == nil {
+		t.Error("kotlin grammar is nil under these build tags — .kt/.kts files index to nothing")
+	}
+	if swiftLanguage() == nil {
+		t.Error("swift grammar is nil under these build tags — .swift files index to nothing")
+	}
+	if scalaLanguage() == nil {
+		t.Error("scala grammar is nil under these build tags — .scala files index to nothing")
+	}
+	if dartLanguage() == nil {
+		t.Error("dart grammar is nil under these build tags — .dart files index to nothing")
+	}
+	if elixirLanguage() == nil {
+		t.Error("elixir grammar is nil under these build tags — .ex/.exs files index to nothing")
+	}
+	if luaLanguage() == nil {
+		t.Error("lua grammar is nil under these build tags — .lua files index to nothing")
+	}
+	if protoLanguage() == nil {
+		t.Error("proto grammar is nil under these build tags — .proto files index to nothing")
+	}
+	if solidityLanguage() == nil {
+		t.Error("solidity grammar is nil under these build tags — .sol files index to nothing")
+	}
+	if yamlLanguage() == nil {
+		t.Error("yaml grammar is nil under these build tags — openapi.yaml specs index to nothing")
+	}
+	if jsonLanguage() == nil {
+		t.Error("json grammar is nil under these build tags — swagger.json specs index to nothing")
+	}
 	if pythonLanguage() == nil {
 		t.Error("python grammar is nil under these build tags — .py files index to nothing")
 	}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// HTMLParser implements structural parsing for HTML pages (.html, .htm): the
+// inline `<script>` blocks are JS, so they are handed to the JS parser over a
+// copy of the page in which everything else is blanked to spaces, as
+// VueParser does for a component's scripts, and each external
+// `<script src="…">` path goes to Imports. Functions, classes, and their
+// lines and hashes are the inline scripts'; the markup itself declares
+// nothing.
+//
+// Only scripts a browser runs as JS are parsed: no `type`, a JavaScript MIME
+// type, or `module`; `text/babel` and `text/jsx` are read as JSX. A data or
+// template block (`application/json`, `importmap`, `text/x-template`) is
+// skipped, as is the body of a script with a `src`, which browsers ignore.
+// Apps Script templates' scriptlets (`<? … ?>`, `<?= … ?>`, `<?!= … ?>`) are
+// server-side code, not page JS; they are blanked before the scripts are
+// parsed, so one inside a script body does not break its syntax.
+//
+// Known limitations: event-handler attributes (`onclick="…"`) and
+// `javascript:` URLs are not parsed; an Apps Script `include('file')`
+// scriptlet is not an import.
+type HTMLParser struct{}
+
+// NewHTMLParser creates a new HTML inline-script parser.
+func NewHTMLParser() *HTMLParser { return &HTMLParser{} }
+
+// SupportsExtension returns true for .html and .htm files.
+func (p *HTMLParser) SupportsExtension(ext string) bool {
+	return ext == ".html" || ext == ".htm"
+}
+
+// reHTMLScriptlet matches an Apps Script template scriptlet.
+var reHTMLScriptlet = regexp.MustCompile(`(?s)<\?.*?\?>`)
+
+// Parse extracts the structure of a page's inline scripts and its external
+// script paths. Best-effort: a script the JS parser cannot read degrades as a
+// .js file would.
+func (p *HTMLParser) Parse(source string) (FileStructure, error) {
+	// Normalize CRLF→LF so hashes and line numbers are line-ending-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	if locs := reHTMLScriptlet.FindAllStringIndex(source, -1); locs != nil {
+		b := []byte(source)
+		for _, l := range locs {
+			for i := l[0]; i < l[1]; i++ {
+				if b[i] != '\n' {
+					b[i] = ' '
+				}
+			}
+		}
+		source = string(b)
+	}
+	return parseScripts(source, htmlScriptExt)
+}
+
+// htmlScriptExt returns the extension the JS parser reads a page script as,
+// by its `type` attribute, or "" for one that is not inline JS.
+func htmlScriptExt(attrs map[string]string) string {
+	if _, ok := attrs["src"]; ok {
+		return ""
+	}
+	switch strings.ToLower(strings.TrimSpace(attrs["type"])) {
+	case "", "module", "text/javascript", "application/javascript", "application/x-javascript",
+		"text/ecmascript", "application/ecmascript":
+		return ".js"
+	case "text/babel", "text/jsx":
+		return ".jsx"
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const htmlSample = `<!DOCTYPE html>
+<html>
+<head>
+  <base target="_top">
+  <script src="https://code.jquery.com/jquery-3.7.1.min.js"></script>
+  <script type="module" src="./app.js"></script>
+  <?!= include('Stylesheet'); ?>
+</head>
+<body>
+  <p>Don't call function prose() from here.</p>
+  <!-- <script>function commented() {}</script> -->
+  <script type="application/json" id="config">{"function notCode() {}": 1}</script>
+  <script type="text/x-template" id="row"><li>function alsoNotCode() {}</li></script>
+  <script>
+    const rows = <?!= JSON.stringify(data) ?>;
+    function loadRows() {
+      google.script.run.withSuccessHandler(render).getRows();
+    }
+    class RowView {
+      render() { return rows.length; }
+    }
+  </script>
+  <script src="legacy.js">function ignoredBody() {}</script>
+</body>
+</html>
+`
+
+func TestHTMLParser_Extension(t *testing.T) {
+	p := NewHTMLParser()
+	for _, ext := range []string{".html", ".htm"} {
+		if !p.SupportsExtension(ext) {
+			t.Errorf("want %s supported", ext)
+		}
+	}
+	if p.SupportsExtension(".js") || p.SupportsExtension(".vue") || p.SupportsExtension(".xhtml") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+// Inline scripts are parsed, external ones are imports, and data, template,
+// commented-out, and src-shadowed script bodies are not code.
+func TestHTMLParser_Symbols(t *testing.T) {
+	got, err := NewHTMLParser().Parse(htmlSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"./app.js", "https://code.jquery.com/jquery-3.7.1.min.js", "legacy.js"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if want := []string{"RowView.render", "loadRows"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+	if want := []string{"RowView"}; !reflect.DeepEqual(got.Classes, want) {
+		t.Errorf("Classes = %q, want %q", got.Classes, want)
+	}
+	if len(got.Exports) != 0 {
+		t.Errorf("Exports = %q, want none", got.Exports)
+	}
+}
+
+func TestHTMLParser_ScriptTypes(t *testing.T) {
+	for _, c := range []struct {
+		attrs map[string]string
+		want  string
+	}{
+		{map[string]string{}, ".js"},
+		{map[string]string{"type": "module"}, ".js"},
+		{map[string]string{"type": " Text/JavaScript "}, ".js"},
+		{map[string]string{"type": "text/babel"}, ".jsx"},
+		{map[string]string{"type": "importmap"}, ""},
+		{map[string]string{"type": "application/ld+json"}, ""},
+		{map[string]string{"src": "a.js"}, ""},
+	} {
+		if got := htmlScriptExt(c.attrs); got != c.want {
+			t.Errorf("htmlScriptExt(%v) = %q, want %q", c.attrs, got, c.want)
+		}
+	}
+}
+
+func TestHTMLParser_HashesAndLines(t *testing.T) {
+	got, _ := NewHTMLParser().Parse(htmlSample)
+	for key, want := range map[string]int{"function:loadRows": 16, "class:RowView": 19, "function:RowView.render": 20} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(htmlSample, "getRows()", "getRows(true)", 1)
+	after, _ := NewHTMLParser().Parse(edited)
+	if after.SymbolHashes["function:loadRows"] == got.SymbolHashes["function:loadRows"] {
+		t.Error("editing loadRows did not change its hash")
+	}
+	if after.SymbolHashes["class:RowView"] != got.SymbolHashes["class:RowView"] {
+		t.Error("editing loadRows changed RowView's hash")
+	}
+}
+
+func TestHTMLParser_CRLFParity(t *testing.T) {
+	lf, _ := NewHTMLParser().Parse(htmlSample)
+	crlf, _ := NewHTMLParser().Parse(strings.ReplaceAll(htmlSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestHTMLParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "<script>", "<html><script>function", "<? unclosed <script>let x", "<script>\x00\xff"} {
+		got, err := NewHTMLParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		assertParserInvariants(t, got)
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzHTMLParser asserts the HTML parser never panics on arbitrary input and
+// keeps the shared invariants.
+// Run: go test -run=x -fuzz=FuzzHTMLParser ./internal/parser
+func FuzzHTMLParser(f *testing.F) {
+	seeds := []string{
+		htmlSample,
+		"<script>function a() {}</script>",
+		"<script type=\"text/babel\">const A = () => <p>hi</p></script>",
+		"<script src=\"x.js\"/>",
+		"<script><?= x ?></script>",
+		"", "<script", "<?", "<!--", "</script>",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewHTMLParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -0,0 +1,303 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// JavaParser implements structural parsing for .java files using the vendored
+// pure-Go tree-sitter Java grammar, like the Rust and Ruby parsers: generics,
+// annotations, text blocks, and lambdas are all places a masking scan would
+// misread a `{` or a `"`, and one misread brace drops every member after it.
+//
+// Symbol routing:
+//   - `class` / `interface` / `enum` / `record` / `@interface` → Classes,
+//     nested-qualified ("Outer.Inner"), the enclosing type standing in for
+//     Rust's `mod` and Ruby's `module`.
+//   - Methods, constructors, and annotation elements → Functions, qualified by
+//     their type ("Reader.fetch"). A constructor is named after its type
+//     ("Reader.Reader"), as it is written. Overloads share one name, and their
+//     hashes combine, so editing any overload flips it.
+//   - Fields and enum constants → Exports when visible (see below); located,
+//     not hashed, like the Go parser's var/const.
+//   - `import` → Imports, as written minus the keyword and semicolon:
+//     "java.util.List", "java.util.*", "static org.junit.Assert.assertEquals".
+//   - The `package` declaration → Exports ("com.acme.web"), as the C# parser
+//     lists a namespace: it is what an import names, and nothing else ties a
+//     file to it, since Java does not require the directory to match.
+//
+// Visibility follows the extract-everything rule the Rust parser set: every
+// type and method is listed, and Exports additionally lists the visible ones —
+// `public`, or a member of an interface or annotation type not marked
+// `private` (those are public without saying so). Enum constants are as
+// visible as their enum. Package-private and `protected` members are left out
+// of Exports but still listed, since a same-package call to them is ordinary.
+//
+// Method bodies are not descended into: local and anonymous classes and
+// lambdas have no name a caller elsewhere could write.
+type JavaParser struct{}
+
+// NewJavaParser creates a new Java parser.
+func NewJavaParser() *JavaParser { return &JavaParser{} }
+
+// SupportsExtension returns true for .java files.
+func (p *JavaParser) SupportsExtension(ext string) bool {
+	return ext == ".java"
+}
+
+var (
+	javaLangOnce sync.Once
+	javaLang     *ts.Language
+)
+
+func javaLanguage() *ts.Language {
+	javaLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("Java", r)
+			}
+		}()
+		javaLang = grammars.JavaLanguage()
+	})
+	return javaLang
+}
+
+// Parse extracts structure from Java source via tree-sitter. Best-effort on
+// parse errors: tree-sitter recovers to a partial tree for most mid-edit
+// buffers, and we walk whatever it produced.
+func (p *JavaParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := javaSymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}, nil
+}
+
+// javaTypeDecls are the declarations that define a named type, and whether
+// their members are public unless marked otherwise.
+var javaTypeDecls = map[string]struct{ implicitlyPublic bool }{
+	"class_declaration":           {false},
+	"enum_declaration":            {false},
+	"record_declaration":          {false},
+	"interface_declaration":       {true},
+	"annotation_type_declaration": {true},
+}
+
+func javaSymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("Java parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			hashes, lines = nil, nil
+		}
+	}()
+
+	lang := javaLanguage()
+	if lang == nil {
+		diag.noGrammar("Java")
+		return imports, functions, classes, exports, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("Java source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil
+	}
+	// As for Ruby: an ERROR root means nothing below it is a declaration, so
+	// say the symbols are missing rather than let them read as absent.
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("Java file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+
+	// recordHash combines on collision, which overloads reach in every
+	// ordinary Java file.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+	recordLine := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+	addExport := func(full string, line int) {
+		exports = append(exports, full)
+		recordLine("export:"+full, line)
+	}
+
+	// walk visits the declarations in n. prefix is the enclosing type's
+	// qualified name; implicit says its members are public by default;
+	// enumPublic is the visibility enum constants inherit.
+	var walk func(n *ts.Node, prefix string, implicit, enumPublic bool, depth int)
+	walk = func(n *ts.Node, prefix string, implicit, enumPublic bool, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			c := n.NamedChild(i)
+			kind := c.Type(lang)
+			span := src[c.StartByte():c.EndByte()]
+			line := int(c.StartPoint().Row) + 1
+			visible := javaVisible(c, lang, implicit)
+
+			if decl, ok := javaTypeDecls[kind]; ok {
+				name := rustFieldText(c, "name", lang, src)
+				if name == "" {
+					continue
+				}
+				full := qualify(prefix, name)
+				classes = append(classes, full)
+				recordHash("class:"+full, span)
+				recordLine("class:"+full, line)
+				if visible {
+					exports = append(exports, full)
+				}
+				if body := c.ChildByFieldName("body", lang); body != nil {
+					walk(body, full, decl.implicitlyPublic, visible, depth+1)
+				}
+				continue
+			}
+
+			switch kind {
+			case "import_declaration":
+				if path := javaImportPath(c, lang, src); path != "" {
+					imports = append(imports, path)
+				}
+
+			case "package_declaration":
+				if name := javaPackageName(c, lang, src); name != "" {
+					addExport(name, line)
+				}
+
+			case "method_declaration", "constructor_declaration", "compact_constructor_declaration", "annotation_type_element_declaration":
+				name := rustFieldText(c, "name", lang, src)
+				if name == "" || prefix == "" {
+					continue
+				}
+				full := qualify(prefix, name)
+				functions = append(functions, full)
+				recordHash("function:"+full, span)
+				recordLine("function:"+full, line)
+				if visible {
+					exports = append(exports, full)
+				}
+
+			case "field_declaration", "constant_declaration":
+				if !visible || prefix == "" {
+					continue
+				}
+				for j := 0; j < c.NamedChildCount(); j++ {
+					if d := c.NamedChild(j); d.Type(lang) == "variable_declarator" {
+						if name := rustFieldText(d, "name", lang, src); name != "" {
+							addExport(qualify(prefix, name), line)
+						}
+					}
+				}
+
+			case "enum_constant":
+				if name := rustFieldText(c, "name", lang, src); name != "" && enumPublic {
+					addExport(qualify(prefix, name), line)
+				}
+
+			case "enum_body_declarations", "ERROR":
+				// Members after an enum's constants sit one level down; a
+				// mid-edit buffer's ERROR node can still hold whole members.
+				walk(c, prefix, implicit, enumPublic, depth+1)
+			}
+		}
+	}
+	walk(tree.RootNode(), "", false, false, 0)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, hashes, lines
+}
+
+// javaVisible reports whether a declaration is visible outside its package:
+// marked `public`, or unmarked in a type whose members default to public.
+func javaVisible(n *ts.Node, lang *ts.Language, implicit bool) bool {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		m := n.NamedChild(i)
+		if m.Type(lang) != "modifiers" {
+			continue
+		}
+		for j := 0; j < m.ChildCount(); j++ {
+			switch m.Child(j).Type(lang) {
+			case "public":
+				return true
+			case "private", "protected":
+				return false
+			}
+		}
+	}
+	return implicit
+}
+
+// javaPackageName returns the name a package declaration declares, without
+// its annotations: "com.acme.web".
+func javaPackageName(n *ts.Node, lang *ts.Language, src []byte) string {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		c := n.NamedChild(i)
+		switch c.Type(lang) {
+		case "identifier", "scoped_identifier":
+			return strings.Join(strings.Fields(c.Text(src)), "")
+		}
+	}
+	return ""
+}
+
+// javaImportPath returns an import as written, minus `import` and the
+// semicolon, with whitespace removed so formatting cannot change it.
+func javaImportPath(n *ts.Node, lang *ts.Language, src []byte) string {
+	var b strings.Builder
+	for i := 0; i < n.ChildCount(); i++ {
+		c := n.Child(i)
+		switch c.Type(lang) {
+		case "import", ";":
+		case "static":
+			b.WriteString("static ")
+		default:
+			b.WriteString(strings.Join(strings.Fields(c.Text(src)), ""))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const javaSample = `package com.acme.web;
+
+import java.util.List;
+import java.util.*;
+import static org.junit.Assert.assertEquals;
+
+@Service
+public class Reader<T> extends Base implements Runnable {
+    private int x = 1;
+    public static final String NAME = "r", ALIAS = "a";
+    public Reader() {}
+    public List<T> fetch(int n) { return null; }
+    public List<T> fetch() { return fetch(0); }
+    void helper() {
+        Runnable r = new Runnable() { public void run() {} };
+    }
+    public static class Inner { public void go() {} }
+    interface Cb { void call(); private void hidden() {} }
+}
+
+public enum Color { RED, GREEN; int code() { return 1; } }
+
+public interface Api { String get(); default void x() {} int LIMIT = 3; }
+
+record Point(int x, int y) { public int sum() { return x + y; } }
+
+@interface Ann { String value(); }
+`
+
+func TestJavaParser_Extension(t *testing.T) {
+	p := NewJavaParser()
+	if !p.SupportsExtension(".java") {
+		t.Error("want .java supported")
+	}
+	if p.SupportsExtension(".js") || p.SupportsExtension(".class") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestJavaParser_Symbols(t *testing.T) {
+	got, err := NewJavaParser().Parse(javaSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	wantImports := []string{"java.util.*", "java.util.List", "static org.junit.Assert.assertEquals"}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports:\n got %q\nwant %q", got.Imports, wantImports)
+	}
+	wantClasses := []string{"Ann", "Api", "Color", "Point", "Reader", "Reader.Cb", "Reader.Inner"}
+	if !reflect.DeepEqual(got.Classes, wantClasses) {
+		t.Errorf("Classes:\n got %q\nwant %q", got.Classes, wantClasses)
+	}
+	// Overloads collapse to one name; the anonymous class's run() is not a
+	// member of anything a caller can name.
+	wantFunctions := []string{
+		"Ann.value", "Api.get", "Api.x", "Color.code", "Point.sum",
+		"Reader.Cb.call", "Reader.Cb.hidden", "Reader.Inner.go",
+		"Reader.Reader", "Reader.fetch", "Reader.helper",
+	}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+	// The package is what `import com.acme.web.*` names, so it is listed with
+	// the exports, annotated (package-info.java) or not.
+	if !slices.Contains(got.Exports, "com.acme.web") || got.SymbolLines["export:com.acme.web"] != 1 {
+		t.Errorf("Exports %q, line %d; want com.acme.web at line 1", got.Exports, got.SymbolLines["export:com.acme.web"])
+	}
+	info, _ := NewJavaParser().Parse("/** Web. */\n@Deprecated\npackage com . acme\n  .web;\n")
+	if !reflect.DeepEqual(info.Exports, []string{"com.acme.web"}) {
+		t.Errorf("package-info Exports = %q, want [com.acme.web]", info.Exports)
+	}
+}
+
+// Exports are the public surface: `public`, plus interface and annotation
+// members unless `private`, plus the constants of a public enum.
+func TestJavaParser_Visibility(t *testing.T) {
+	got, _ := NewJavaParser().Parse(javaSample)
+	wantExports := []string{
+		"Ann.value", "Api", "Api.LIMIT", "Api.get", "Api.x",
+		"Color", "Color.GREEN", "Color.RED", "Point.sum",
+		"Reader", "Reader.ALIAS", "Reader.Cb.call", "Reader.Inner", "Reader.Inner.go",
+		"Reader.NAME", "Reader.Reader", "Reader.fetch", "com.acme.web",
+	}
+	if !reflect.DeepEqual(got.Exports, wantExports) {
+		t.Errorf("Exports:\n got %q\nwant %q", got.Exports, wantExports)
+	}
+}
+
+func TestJavaParser_HashesAndLines(t *testing.T) {
+	got, _ := NewJavaParser().Parse(javaSample)
+	for key, want := range map[string]int{
+		"function:Reader.fetch": 12, // the first overload anchors the line
+		"class:Reader":          7,  // annotations are part of the declaration
+		"export:Reader.ALIAS":   10,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	// Editing either overload flips the shared hash.
+	edited := strings.Replace(javaSample, "return fetch(0);", "return fetch(1);", 1)
+	after, _ := NewJavaParser().Parse(edited)
+	if after.SymbolHashes["function:Reader.fetch"] == got.SymbolHashes["function:Reader.fetch"] {
+		t.Error("editing the second overload did not change the symbol hash")
+	}
+	if after.SymbolHashes["function:Point.sum"] != got.SymbolHashes["function:Point.sum"] {
+		t.Error("unrelated symbol's hash changed")
+	}
+}
+
+func TestJavaParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "class A {\n" +
+		"  // public void commented() {}\n" +
+		"  /* class Fake { } */\n" +
+		"  String s = \"public void quoted() {}\";\n" +
+		"  String t = \"\"\"\n    class Block { }\n    \"\"\";\n" +
+		"  void real() {}\n}\n"
+	got, _ := NewJavaParser().Parse(src)
+	if !reflect.DeepEqual(got.Functions, []string{"A.real"}) || !reflect.DeepEqual(got.Classes, []string{"A"}) {
+		t.Errorf("Functions %q, Classes %q; want only A and A.real", got.Functions, got.Classes)
+	}
+}
+
+func TestJavaParser_CRLFParity(t *testing.T) {
+	lf, _ := NewJavaParser().Parse(javaSample)
+	crlf, _ := NewJavaParser().Parse(strings.ReplaceAll(javaSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestJavaParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "class Broken {", "public \x00\xff", "}}}}"} {
+		got, err := NewJavaParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzJavaParser asserts the Java parser never panics on arbitrary input and
+// keeps the invariants the other tree-sitter parsers keep.
+// Run: go test -run=x -fuzz=FuzzJavaParser ./internal/parser
+func FuzzJavaParser(f *testing.F) {
+	seeds := []string{
+		javaSample,
+		"class A { void b() {} }",
+		"public enum E { X, Y; }",
+		"interface I { default void d() {} }",
+		"record R(int a) { R { } }",
+		"import static a.b.*;",
+		"class G<T extends Comparable<? super T>> { <U> U m() { return null; } }",
+		"", "class", "}}}", "public public", "class A { void f(\x00) {",
+		"String s = \"\"\"\nunterminated",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewJavaParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
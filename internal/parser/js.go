@@ -1,9 +1,8 @@
 package parser
 
 import (
-	"fmt"
-	"os"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -12,7 +11,7 @@ import (
 	"github.com/odvcencio/gotreesitter/grammars"
 )
 
-// JSParser parses .js, .mjs, .cjs, .ts, .jsx, .tsx, .gs files. Functions, classes,
+// JSParser parses .js, .mjs, .cjs, .ts, .mts, .cts, .jsx, .tsx, .gs files. Functions, classes,
 // imports, and exports all use a real tree-sitter AST via a pure-Go
 // (CGO-free) runtime when the matching grammar is embedded in the build:
 // functions/classes carry per-symbol start lines and function body hashes
@@ -21,8 +20,9 @@ import (
 // (import_statement, export_statement, export_specifier, variable_declarator,
 // …) rather than pattern-matching source text, so alias-vs-local-name
 // confusion and TS `type`-only forms are no longer regex edge cases. CommonJS
-// require(...) calls have no dedicated grammar node and stay regex-matched
-// regardless of AST availability (see extractCJSRequires). When the grammar
+// require(...) and dynamic import(...) calls have no dedicated grammar node
+// and stay regex-matched regardless of AST availability (see
+// extractCJSRequires and extractDynamicImports). When the grammar
 // is absent (a build without the grammar_subset_* tags), or the reduced
 // grammar's error recovery leaves a partial tree, this degrades to (or
 // supplements with) the former line-oriented regex extraction — so symbol
@@ -46,6 +46,10 @@ var (
 	importESMRegex = regexp.MustCompile(`import\s+(?:[\w\s{},*]*\s+from\s+)?['"]([^'"]+)['"]`)
 	// Matches: require("path")
 	importCJSRegex = regexp.MustCompile(`require\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	// Matches: import("path"), import('path'), import(`path`) — a dynamic
+	// import with a literal specifier, optionally followed by an options
+	// argument. Not obj.import(...), and not a template with a substitution.
+	importDynamicRegex = regexp.MustCompile(`(?:^|[^\w$.])import\s*\(\s*(?:'([^'\n]+)'|"([^"\n]+)"|` + "`([^`$\\n]+)`" + `)\s*[,)]`)
 
 	// Function declarations
 	// Matches: function name(...) or async function name(...)
@@ -74,13 +78,14 @@ var (
 	// type/interface/enum land in Exports the same way `export class` does — the
 	// AST records them in Classes, this regex records the exported name (so a TS
 	// `export interface Shape {}` is enumerable as both a class and an export).
-	exportDeclRegex = regexp.MustCompile(`export\s+(?:function|class|async\s+function|type|interface|enum)\s+(\w+)`)
+	// A .d.ts file's `export declare` form is the same export.
+	exportDeclRegex = regexp.MustCompile(`export\s+(?:declare\s+)?(?:function|(?:abstract\s+)?class|async\s+function|type|interface|(?:const\s+)?enum)\s+(\w+)`)
 	// Matches: export const/let/var NAME[: Type][ = value][, NAME2 ...];
 	// Captures the whole declarator list up to the statement terminator so
 	// multi-name declarations can be split on TOP-LEVEL commas only (see
 	// splitTopLevelDeclNames) — a naive split on every comma would shatter an
 	// initializer like `f(1, 2)` into a phantom declarator name.
-	exportMultiDeclRegex = regexp.MustCompile(`export\s+(?:const|let|var)\s+([^;\n]+)`)
+	exportMultiDeclRegex = regexp.MustCompile(`export\s+(?:declare\s+)?(?:const|let|var)\s+([^;\n]+)`)
 	// Matches the declarator name at the start of one comma-separated segment
 	// of a declarator list, stopping at the first non-identifier character
 	// (the `:` of a type annotation or the `=` of an initializer).
@@ -94,18 +99,38 @@ var (
 	// Matches the binding list of a destructured array export:
 	// export const [ a, b ] = y.
 	exportArrDestructureRegex = regexp.MustCompile(`export\s+(?:const|let|var)\s*\[([^\]]*)\]\s*=`)
+	// Matches: export as namespace MyLib — a UMD .d.ts file's global name.
+	exportAsNamespaceRegex = regexp.MustCompile(`export\s+as\s+namespace\s+(\w+)`)
 	// Matches: export * as ns from './m' — the namespace re-export binds `ns`.
 	exportStarAsRegex = regexp.MustCompile(`export\s+\*\s+as\s+(\w+)`)
 	// Matches: export * from './m' — the bare form, with no `as` clause. Requires
 	// "from" to follow "*" with only whitespace between, so it never matches the
 	// namespace form above (which has "as ns" in between).
 	exportStarBareRegex = regexp.MustCompile(`export\s+\*\s+from\s+['"]([^'"]+)['"]`)
-	// Matches: export default function Foo / export default [abstract] class Foo /
-	// export default ident. Three capture groups — first non-empty wins; keywords
-	// (function/class/async) in group 3 are discarded so anonymous defaults don't
-	// pollute Exports. The `abstract` modifier is consumed before `class` so the
-	// class NAME (not "abstract") is captured for `export default abstract class`.
-	exportDefaultRegex = regexp.MustCompile(`export\s+default\s+(?:(?:async\s+)?function\s+(\w+)|(?:abstract\s+)?class\s+(\w+)|(\w+))`)
+	// Matches any re-export's module specifier: export * [as ns] from './m',
+	// export [type] { a, b as c } from './m'.
+	reExportRegex = regexp.MustCompile(`export\s+(?:type\s+)?(?:\*(?:\s+as\s+\w+)?|\{[^}]*\})\s*from\s+['"]([^'"]+)['"]`)
+	// Matches: export default [async] function[*] Foo / export default
+	// [abstract] class Foo / interface Foo / export default ident; — or any
+	// other default, which has none of the three names and is exported as
+	// `default`. The `abstract` modifier is consumed before `class` so the
+	// class NAME (not "abstract") is captured for `export default abstract
+	// class`; an identifier must end the statement, so `export default
+	// connect(App)` is anonymous rather than "connect".
+	exportDefaultRegex = regexp.MustCompile(`(?m)export\s+default\s+(?:(?:async\s+)?function\s*\*?\s*(\w+)|(?:(?:abstract\s+)?class|interface)\s+(\w+)|([A-Za-z_$][\w$]*)(?:\s*;|[ \t]*$))?`)
+	// Matches a CommonJS property export: exports.foo = / module.exports.foo =
+	// / exports['foo'] =. The trailing [^=] keeps a comparison out.
+	cjsExportPropRegex = regexp.MustCompile(`(?:^|[^\w$.])(?:module\.)?exports(?:\.([A-Za-z_$][\w$]*)|\[\s*['"]([^'"\n]+)['"]\s*\])\s*=[^=]`)
+	// Matches: module.exports = { a, b: x, c() {} }, with braces nested at
+	// most one deep; a deeper literal is left to the AST.
+	cjsExportObjectRegex = regexp.MustCompile(`(?:^|[^\w$.])module\.exports\s*=\s*(?:exports\s*=\s*)?\{((?:[^{}]|\{[^{}]*\})*)\}`)
+	// Matches one level of braces inside such a literal: a method body or a
+	// nested object, blanked before the literal is split into keys.
+	cjsNestedBraceRegex = regexp.MustCompile(`\{[^{}]*\}`)
+	// Matches: module.exports = [async] function[*] Foo / class Foo / ident;
+	// — the default export, named as exportDefaultRegex names it. A bare
+	// identifier must end the statement, so `= require('x')` is not one.
+	cjsExportDefaultRegex = regexp.MustCompile(`(?m)(?:^|[^\w$.])module\.exports\s*=\s*(?:exports\s*=\s*)?(?:(?:async\s+)?function\s*\*?\s*([A-Za-z_$][\w$]*)|class\s+([A-Za-z_$][\w$]*)|([A-Za-z_$][\w$]*)\s*;?[ \t]*$)`)
 )
 
 // NewJSParser creates a new JavaScript/TypeScript parser.
@@ -113,13 +138,14 @@ func NewJSParser() *JSParser {
 	return &JSParser{}
 }
 
-// SupportsExtension returns true for .js, .mjs, .cjs, .ts, .jsx, .tsx, .gs
-// files. .mjs/.cjs are plain JS syntax (ESM/CJS module-system markers, not a
-// grammar difference) — they fall through ParseExt's default case to the same
-// JS grammar as .js.
+// SupportsExtension returns true for .js, .mjs, .cjs, .ts, .mts, .cts, .jsx,
+// .tsx, .gs files. .mjs/.cjs are plain JS syntax (ESM/CJS module-system
+// markers, not a grammar difference) — they fall through ParseExt's default
+// case to the same JS grammar as .js; .mts/.cts are likewise TypeScript (see
+// jsGrammarExt).
 func (p *JSParser) SupportsExtension(ext string) bool {
 	switch ext {
-	case ".js", ".mjs", ".cjs", ".ts", ".jsx", ".tsx", ".gs":
+	case ".js", ".mjs", ".cjs", ".ts", ".mts", ".cts", ".jsx", ".tsx", ".gs":
 		return true
 	default:
 		return false
@@ -135,9 +161,19 @@ func (p *JSParser) Parse(source string) (FileStructure, error) {
 }
 
 // ParseExt is the extension-aware entry point (see ExtAwareParser). ext selects
-// the tree-sitter grammar: .ts → typescript, .tsx → tsx, everything else → js.
+// the tree-sitter grammar: .ts/.mts/.cts → typescript, .tsx → tsx, everything
+// else → js.
 func (p *JSParser) ParseExt(source, ext string) (FileStructure, error) {
-	return p.parse(source, ext)
+	return p.parse(source, jsGrammarExt(ext))
+}
+
+// jsGrammarExt maps TS 4.7's module-system extensions to .ts: an .mts (ESM)
+// or .cts (CommonJS) file is TypeScript without JSX, parsed as .ts is.
+func jsGrammarExt(ext string) string {
+	if ext == ".mts" || ext == ".cts" {
+		return ".ts"
+	}
+	return ext
 }
 
 func (p *JSParser) parse(source, ext string) (FileStructure, error) {
@@ -147,20 +183,38 @@ func (p *JSParser) parse(source, ext string) (FileStructure, error) {
 
 	// Run the regex fallbacks on comment-stripped source so commented-out
 	// statements are ignored; the AST paths below parse the raw `source`
-	// directly (tree-sitter handles comments itself).
-	noComments := removeComments(source)
+	// directly (tree-sitter handles comments itself). JSX text is masked
+	// first so prose in markup is not read as code — except in .ts, which has
+	// no JSX but whose `<T>expr` assertions would read as elements.
+	view := source
+	if ext != ".ts" {
+		view = maskJSXText(source)
+	}
+	noComments := removeComments(view)
 
 	// Functions, classes, imports, exports: AST when the grammar is
 	// available, regex otherwise.
 	var (
 		functions, classes, imports, exports, wildcardReexports []string
+		reexports                                               []string
+		enums                                                   []Enum
+		namespaces                                              []Namespace
+		variables                                               []Variable
+		importDetails                                           []ImportDetail
+		typeImports                                             []string
+		details                                                 []ClassDetail
+		funcDetails                                             []FunctionDetail
+		decorated                                               []Decorated
 		hashes                                                  map[string]string
-		lines                                                   map[string]int
+		lines, cols                                             map[string]int
+		docs                                                    map[string]string
 		fallbackRan                                             bool
 	)
+	var diag diagnostics
 	if lang := jsLanguageFor(ext); lang != nil {
-		var hasError bool
-		functions, classes, hashes, lines, hasError = jsSymbolsFromAST(source, lang)
+		syms, hasError := jsSymbolsFromAST(source, lang, &diag)
+		functions, classes, enums, details, decorated, hashes, lines = syms.functions, syms.classes, syms.enums, syms.classDetails, syms.decorated, syms.hashes, syms.lines
+		docs, funcDetails, cols, namespaces, variables = syms.docs, syms.functionDetails, syms.cols, syms.namespaces, syms.variables
 		fallbackRan = hasError
 		if hasError {
 			// The reduced grammar failed to cleanly parse at least part of this
@@ -177,7 +231,7 @@ func (p *JSParser) parse(source, ext string) (FileStructure, error) {
 		}
 
 		var ieHasError bool
-		imports, exports, wildcardReexports, ieHasError = jsImportsExportsFromAST(source, lang)
+		imports, exports, wildcardReexports, reexports, importDetails, typeImports, ieHasError = jsImportsExportsFromAST(source, lang, &diag)
 		if ieHasError {
 			// Same posture as the functions/classes fallback above: supplement,
 			// don't replace, so a partially-recovered tree never loses a real
@@ -185,12 +239,15 @@ func (p *JSParser) parse(source, ext string) (FileStructure, error) {
 			imports = append(imports, extractImports(noComments)...)
 			exports = append(exports, extractExports(noComments)...)
 			wildcardReexports = append(wildcardReexports, extractWildcardReexports(noComments)...)
-		}
-		// require(...) calls have no dedicated grammar node (they're an
-		// ordinary call_expression that can appear anywhere, including inside
-		// function bodies the declaration-level AST walk doesn't descend
-		// into) — always union in the regex-matched requires.
-		imports = append(imports, extractCJSRequires(noComments)...)
+			reexports = append(reexports, extractReExports(noComments)...)
+		}
+		// require(...) and import(...) calls have no dedicated grammar node
+		// (they're ordinary call_expressions that can appear anywhere,
+		// including inside function bodies the declaration-level AST walk
+		// doesn't descend into) — always union in the regex-matched ones.
+		calls := append(extractCJSRequires(noComments), extractDynamicImports(noComments)...)
+		imports = append(imports, calls...)
+		typeImports = withoutModules(typeImports, calls)
 	} else {
 		// No grammar embedded in this build — degrade to the former
 		// line-oriented regex extraction entirely.
@@ -199,6 +256,7 @@ func (p *JSParser) parse(source, ext string) (FileStructure, error) {
 		imports = extractImports(noComments)
 		exports = extractExports(noComments)
 		wildcardReexports = extractWildcardReexports(noComments)
+		reexports = extractReExports(noComments)
 		fallbackRan = true
 	}
 
@@ -208,7 +266,7 @@ func (p *JSParser) parse(source, ext string) (FileStructure, error) {
 	// AST-recorded line always wins — and only for names actually in the symbol
 	// set, so a stray fallback match never plants an orphan line entry.
 	if fallbackRan {
-		fLines, cLines := fallbackSymbolLines(source)
+		fLines, cLines := fallbackSymbolLines(view)
 		if lines == nil {
 			lines = make(map[string]int, len(fLines)+len(cLines))
 		}
@@ -221,7 +279,7 @@ func (p *JSParser) parse(source, ext string) (FileStructure, error) {
 		if hashes == nil {
 			hashes = make(map[string]string)
 		}
-		bodyHashes := typedArrowHashes(source)
+		bodyHashes := typedArrowHashes(source, view)
 		for _, n := range functions {
 			if h, ok := bodyHashes[n]; ok {
 				if key := "function:" + n; hashes[key] == "" {
@@ -236,6 +294,7 @@ func (p *JSParser) parse(source, ext string) (FileStructure, error) {
 	sort.Strings(classes)
 	sort.Strings(exports)
 	sort.Strings(wildcardReexports)
+	sort.Strings(reexports)
 
 	return FileStructure{
 		Imports:           deduplicate(imports),
@@ -243,11 +302,283 @@ func (p *JSParser) parse(source, ext string) (FileStructure, error) {
 		Classes:           deduplicate(classes),
 		Exports:           deduplicate(exports),
 		WildcardReexports: deduplicate(wildcardReexports),
+		ReExports:         deduplicate(reexports),
+		Enums:             sortEnums(enums),
+		Namespaces:        sortNamespaces(namespaces),
+		Variables:         sortVariables(variables),
+		ImportDetails:     sortImportDetails(importDetails),
+		TypeImports:       typeImports,
+		ClassDetails:      sortClassDetails(details),
+		FunctionDetails:   sortFunctionDetails(funcDetails),
+		Decorators:        sortDecorated(decorated),
 		SymbolHashes:      hashes,
 		SymbolLines:       lines,
+		SymbolColumns:     cols,
+		Docs:              docs,
+		Diagnostics:       diag,
 	}, nil
 }
 
+// jsClassDetail describes the class node cls, recorded as name: what it
+// extends and the names of its members. Members are read from the class body
+// alone, so a nested class's members stay its own.
+func jsClassDetail(name string, cls *ts.Node, lang *ts.Language, src []byte) ClassDetail {
+	d := ClassDetail{Name: name}
+	if heritage := childOfType(cls, lang, "class_heritage"); heritage != nil {
+		// TS wraps the superclass in an extends_clause beside any
+		// implements_clause; JS's heritage is the extends clause itself.
+		ext := childOfType(heritage, lang, "extends_clause")
+		if ext == nil && childOfType(heritage, lang, "implements_clause") == nil {
+			ext = heritage
+		}
+		if ext != nil {
+			text := strings.TrimPrefix(ext.Text(src), "extends")
+			d.Extends = strings.Join(strings.Fields(text), " ")
+		}
+	}
+	body := childOfType(cls, lang, "class_body")
+	if body == nil {
+		return d
+	}
+	for i := 0; i < body.NamedChildCount(); i++ {
+		m := body.NamedChild(i)
+		var member string
+		switch m.Type(lang) {
+		case "method_definition", "method_signature", "abstract_method_signature":
+			member = fieldText(m, "name", lang, src)
+		case "field_definition", "public_field_definition":
+			// JS names a field by "property", TS by "name".
+			if member = fieldText(m, "name", lang, src); member == "" {
+				member = fieldText(m, "property", lang, src)
+			}
+		}
+		if member == "" {
+			continue
+		}
+		switch {
+		case hasToken(m, lang, "static"):
+			d.Static = append(d.Static, member)
+		case strings.HasSuffix(m.Type(lang), "field_definition"):
+			// Instance fields are state, not something the class offers.
+		default:
+			d.Methods = append(d.Methods, member)
+		}
+	}
+	sort.Strings(d.Methods)
+	sort.Strings(d.Static)
+	d.Methods = deduplicate(d.Methods) // a getter and its setter share a name
+	d.Static = deduplicate(d.Static)
+	return d
+}
+
+// jsFunctionDetail describes the function node fn — a declaration, method,
+// arrow function, or function expression — recorded as name.
+func jsFunctionDetail(name string, fn *ts.Node, lang *ts.Language, src []byte) FunctionDetail {
+	d := FunctionDetail{Name: name, Arrow: fn.Type(lang) == "arrow_function"}
+	// `async` and `*` are anonymous tokens ahead of the name and parameters,
+	// among any decorators and modifiers.
+	head := fn.EndByte()
+	for _, field := range []string{"name", "parameters", "parameter"} {
+		if c := fn.ChildByFieldName(field, lang); c != nil && c.StartByte() < head {
+			head = c.StartByte()
+		}
+	}
+	for i := 0; i < fn.ChildCount() && fn.Child(i).StartByte() < head; i++ {
+		switch fn.Child(i).Type(lang) {
+		case "async":
+			d.Async = true
+		case "*":
+			d.Generator = true
+		}
+	}
+	if p := fn.ChildByFieldName("parameter", lang); p != nil {
+		// `x => …`: one parameter, unparenthesized.
+		d.Params = []string{p.Text(src)}
+	} else if params := fn.ChildByFieldName("parameters", lang); params != nil {
+		for i := 0; i < params.NamedChildCount(); i++ {
+			if param := jsParamName(params.NamedChild(i), lang, src); param != "" {
+				d.Params = append(d.Params, param)
+			}
+		}
+	}
+	if ret := fn.ChildByFieldName("return_type", lang); ret != nil {
+		text := strings.TrimPrefix(strings.TrimSpace(ret.Text(src)), ":")
+		d.Returns = strings.Join(strings.Fields(text), " ")
+	}
+	return d
+}
+
+// jsParamName names parameter p as FunctionDetail.Params does, or returns ""
+// for what is not a parameter (a TS `this`, a comment).
+func jsParamName(p *ts.Node, lang *ts.Language, src []byte) string {
+	if p == nil {
+		return ""
+	}
+	switch p.Type(lang) {
+	case "identifier", "rest_pattern", "object_pattern", "array_pattern":
+		return strings.Join(strings.Fields(p.Text(src)), " ")
+	case "assignment_pattern":
+		if name := jsParamName(p.ChildByFieldName("left", lang), lang, src); name != "" {
+			return name + "?"
+		}
+	case "required_parameter", "optional_parameter":
+		pattern := p.ChildByFieldName("pattern", lang)
+		if pattern == nil || pattern.Type(lang) == "this" {
+			return ""
+		}
+		name := jsParamName(pattern, lang, src)
+		if name != "" && (p.Type(lang) == "optional_parameter" || p.ChildByFieldName("value", lang) != nil) {
+			name += "?"
+		}
+		return name
+	}
+	return ""
+}
+
+// decoratorName is what decorator d applies, less the `@` and any arguments:
+// `Injectable` for `@Injectable()`, `Auth.Guard` for `@Auth.Guard({...})`.
+func decoratorName(d *ts.Node, lang *ts.Language, src []byte) string {
+	expr := d.NamedChild(0)
+	if expr == nil {
+		return ""
+	}
+	if expr.Type(lang) == "call_expression" {
+		if expr = expr.ChildByFieldName("function", lang); expr == nil {
+			return ""
+		}
+	}
+	return strings.Join(strings.Fields(expr.Text(src)), "")
+}
+
+// sortDecorated sorts decorated symbols by kind, then name, merging two
+// entries for one symbol (an overload's signatures) in source order.
+func sortDecorated(decorated []Decorated) []Decorated {
+	sort.SliceStable(decorated, func(i, j int) bool {
+		if decorated[i].Kind != decorated[j].Kind {
+			return decorated[i].Kind < decorated[j].Kind
+		}
+		return decorated[i].Name < decorated[j].Name
+	})
+	var out []Decorated
+	for _, d := range decorated {
+		if last := len(out) - 1; last >= 0 && out[last].Kind == d.Kind && out[last].Name == d.Name {
+			out[last].Decorators = append(out[last].Decorators, d.Decorators...)
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// hasToken reports whether one of n's direct children is the anonymous token
+// typ (a modifier keyword such as `static`).
+func hasToken(n *ts.Node, lang *ts.Language, typ string) bool {
+	for i := 0; i < n.ChildCount(); i++ {
+		if c := n.Child(i); !c.IsNamed() && c.Type(lang) == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// sortClassDetails sorts details by name and keeps the first of each.
+func sortClassDetails(details []ClassDetail) []ClassDetail {
+	sort.SliceStable(details, func(i, j int) bool { return details[i].Name < details[j].Name })
+	out := details[:0]
+	for i, d := range details {
+		if i == 0 || d.Name != details[i-1].Name {
+			out = append(out, d)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// sortFunctionDetails sorts details by name and keeps the first of each: an
+// overload's first signature, a getter over its setter.
+func sortFunctionDetails(details []FunctionDetail) []FunctionDetail {
+	sort.SliceStable(details, func(i, j int) bool { return details[i].Name < details[j].Name })
+	out := details[:0]
+	for i, d := range details {
+		if i == 0 || d.Name != details[i-1].Name {
+			out = append(out, d)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// sortNamespaces sorts namespaces by name and keeps the first of each: TS
+// merges same-named namespace declarations into one.
+func sortNamespaces(namespaces []Namespace) []Namespace {
+	sort.SliceStable(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+	out := namespaces[:0]
+	for i, n := range namespaces {
+		if i == 0 || n.Name != namespaces[i-1].Name {
+			out = append(out, n)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// sortVariables sorts variables by name and keeps the first of each: a
+// redeclared `var` is one binding.
+func sortVariables(variables []Variable) []Variable {
+	sort.SliceStable(variables, func(i, j int) bool { return variables[i].Name < variables[j].Name })
+	out := variables[:0]
+	for i, v := range variables {
+		if i == 0 || v.Name != variables[i-1].Name {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// jsTopLevel reports whether the declaration decl is at the top of the file,
+// exported or `declare`d or not.
+func jsTopLevel(decl *ts.Node, lang *ts.Language) bool {
+	p := decl.Parent()
+	for p != nil && (p.Type(lang) == "export_statement" || p.Type(lang) == "ambient_declaration") {
+		p = p.Parent()
+	}
+	return p != nil && p.Type(lang) == "program"
+}
+
+// jsIsRequire reports whether value is a `require(...)` call.
+func jsIsRequire(value *ts.Node, lang *ts.Language, src []byte) bool {
+	if value == nil || value.Type(lang) != "call_expression" {
+		return false
+	}
+	callee := value.ChildByFieldName("function", lang)
+	return callee != nil && callee.Type(lang) == "identifier" && callee.Text(src) == "require"
+}
+
+// sortEnums sorts enums by name and keeps the first of each: TS merges
+// same-named enum declarations into one.
+func sortEnums(enums []Enum) []Enum {
+	sort.SliceStable(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+	out := enums[:0]
+	for i, e := range enums {
+		if i == 0 || e.Name != enums[i-1].Name {
+			out = append(out, e)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
 // Grammar caches: each grammar is loaded once and the *Language is safe for
 // concurrent reads (a fresh ts.Parser is created per Parse since it is not
 // concurrency-safe). The accessors return nil when the corresponding
@@ -262,14 +593,25 @@ var (
 func jsLanguageFor(ext string) *ts.Language {
 	switch ext {
 	case ".ts":
-		return cachedLang(&tsLangOnce, &tsLang, "typescript", grammars.TypescriptLanguage)
+		return cachedLang(&tsLangOnce, &tsLang, jsGrammarName(ext), grammars.TypescriptLanguage)
 	case ".tsx":
-		return cachedLang(&tsxLangOnce, &tsxLang, "tsx", grammars.TsxLanguage)
+		return cachedLang(&tsxLangOnce, &tsxLang, jsGrammarName(ext), grammars.TsxLanguage)
 	default: // .js, .mjs, .cjs, .jsx, .gs, "" — the JS grammar also parses JSX.
-		return cachedLang(&jsLangOnce, &jsLang, "javascript", grammars.JavascriptLanguage)
+		return cachedLang(&jsLangOnce, &jsLang, jsGrammarName(ext), grammars.JavascriptLanguage)
 	}
 }
 
+// jsGrammarName names the grammar jsLanguageFor picks for ext.
+func jsGrammarName(ext string) string {
+	switch ext {
+	case ".ts":
+		return "typescript"
+	case ".tsx":
+		return "tsx"
+	}
+	return "javascript"
+}
+
 // cachedLang lazily loads a grammar under once, recovering from a decode panic
 // so a bad blob degrades to nil (no AST symbols) rather than crashing the first
 // Parse call — mirrors the Python parser's grammar-load guard.
@@ -277,7 +619,7 @@ func cachedLang(once *sync.Once, dst **ts.Language, name string, load func() *ts
 	once.Do(func() {
 		defer func() {
 			if r := recover(); r != nil {
-				fmt.Fprintf(os.Stderr, "runecho: %s grammar failed to load (%v); %s symbols disabled\n", name, r, name)
+				grammarFailed(name, r)
 			}
 		}()
 		*dst = load()
@@ -291,13 +633,14 @@ func cachedLang(once *sync.Once, dst **ts.Language, name string, load func() *ts
 // in different scopes never collide. Functions/methods carry a body hash keyed
 // "function:<qualified name>" for modified-symbol diffing; classes, interfaces,
 // enums, and type aliases are located (start line) but not hashed (their changes
-// surface through their members).
-func jsSymbolsFromAST(source string, lang *ts.Language) (functions, classes []string, hashes map[string]string, lines map[string]int, hasError bool) {
+// surface through their members). Enums, namespaces, and classes are also
+// described on their own (see jsSymbols), under the same qualified names.
+func jsSymbolsFromAST(source string, lang *ts.Language, diag *diagnostics) (syms jsSymbols, hasError bool) {
 	// The pure-Go tree-sitter runtime can panic on adversarial or malformed
 	// input; a panic here would otherwise propagate through parseFile→Generate
 	// and crash the indexer/MCP server. Recover and degrade to no AST symbols
 	// (the same fail-safe path as a nil grammar) so one bad file can't take down
-	// the process. Named returns are reset so a panic mid-walk can't leak a
+	// the process. The named return is reset so a panic mid-walk can't leak a
 	// partial, inconsistent symbol set.
 	// hasError=true tells the caller to supplement with the regex fallback. Every
 	// give-up path below sets it, because "we produced no AST symbols" is exactly
@@ -308,20 +651,20 @@ func jsSymbolsFromAST(source string, lang *ts.Language) (functions, classes []st
 	// the nest guard rejects, and it only ever unions symbols in.
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Fprintf(os.Stderr, "runecho: JS/TS parse panicked (%v); AST symbols for this file disabled\n", r)
-			functions, classes, hashes, lines, hasError = nil, nil, nil, nil, true
+			diag.addf("JS/TS parse panicked (%v); AST symbols for this file disabled", r)
+			syms, hasError = jsSymbols{}, true
 		}
 	}()
 	src := []byte(source)
 	// Reject pathologically-nested input before the super-linear tree-sitter
 	// parse can hang the process; degrade to no AST symbols (see maxParseNestDepth).
 	if exceedsNestDepth(src) {
-		fmt.Fprintf(os.Stderr, "runecho: JS/TS source exceeds max nesting depth (%d); AST symbols for this file disabled\n", maxParseNestDepth)
-		return nil, nil, nil, nil, true
+		diag.addf("JS/TS source exceeds max nesting depth (%d); AST symbols for this file disabled", maxParseNestDepth)
+		return jsSymbols{}, true
 	}
 	tree, err := ts.NewParser(lang).Parse(src)
 	if err != nil || tree == nil || tree.RootNode() == nil {
-		return nil, nil, nil, nil, true
+		return jsSymbols{}, true
 	}
 	// The reduced grammar can't parse some declarator shapes — notably a typed
 	// arrow parameter or return type (`const f = (x: T): R => ...`) — and error
@@ -332,9 +675,19 @@ func jsSymbolsFromAST(source string, lang *ts.Language) (functions, classes []st
 	// regex fallback over the whole file rather than trusting a partial walk.
 	hasError = tree.RootNode().HasError()
 
-	hashes = make(map[string]string)
-	lines = make(map[string]int)
-
+	var (
+		functions, classes []string
+		enums              []Enum
+		namespaces         []Namespace
+		variables          []Variable
+		details            []ClassDetail
+		funcDetails        []FunctionDetail
+		decorated          []Decorated
+		hashes             = make(map[string]string)
+		lines              = make(map[string]int)
+		cols               = make(map[string]int)
+		docs               = make(map[string]string)
+	)
 	recordHash := func(key string, span []byte) {
 		h := hashBytesHex(span)
 		if existing, ok := hashes[key]; ok {
@@ -342,20 +695,58 @@ func jsSymbolsFromAST(source string, lang *ts.Language) (functions, classes []st
 		}
 		hashes[key] = h
 	}
-	recordLine := func(key string, line int) {
+	recordLine := func(key string, at ts.Point) {
 		if _, ok := lines[key]; !ok {
-			lines[key] = line
+			lines[key] = int(at.Row) + 1
+			cols[key] = int(at.Column) + 1
+		}
+	}
+	// recordDoc keeps the first JSDoc summary seen for a symbol, as
+	// recordLine keeps the first line.
+	recordDoc := func(key string, node *ts.Node) {
+		if _, ok := docs[key]; !ok {
+			if doc := jsDocSummary(node, lang, src); doc != "" {
+				docs[key] = doc
+			}
 		}
 	}
 	recordFunc := func(full string, span *ts.Node) {
 		functions = append(functions, full)
 		recordHash("function:"+full, src[span.StartByte():span.EndByte()])
-		recordLine("function:"+full, int(span.StartPoint().Row)+1)
+		recordLine("function:"+full, span.StartPoint())
+		recordDoc("function:"+full, span)
+		funcDetails = append(funcDetails, jsFunctionDetail(full, span, lang, src))
 	}
 	recordClass := func(full string, node *ts.Node) {
 		classes = append(classes, full)
 		recordHash("class:"+full, src[node.StartByte():node.EndByte()])
-		recordLine("class:"+full, int(node.StartPoint().Row)+1)
+		recordLine("class:"+full, node.StartPoint())
+		recordDoc("class:"+full, node)
+	}
+	// recordDecorators records the decorators among the named children of
+	// holders, in order, as applied to the kind:full symbol; a holder that is
+	// itself a decorator counts as one.
+	recordDecorators := func(kind, full string, holders ...*ts.Node) {
+		var names []string
+		add := func(d *ts.Node) {
+			if name := decoratorName(d, lang, src); name != "" {
+				names = append(names, name)
+			}
+		}
+		for _, h := range holders {
+			if h.Type(lang) == "decorator" {
+				add(h)
+				continue
+			}
+			for i := 0; i < h.NamedChildCount(); i++ {
+				if d := h.NamedChild(i); d.Type(lang) == "decorator" {
+					add(d)
+				}
+			}
+		}
+		if len(names) > 0 {
+			decorated = append(decorated, Decorated{Kind: kind, Name: full, Decorators: names})
+		}
 	}
 	var walk func(n *ts.Node, prefix string, depth int)
 	walk = func(n *ts.Node, prefix string, depth int) {
@@ -369,10 +760,12 @@ func jsSymbolsFromAST(source string, lang *ts.Language) (functions, classes []st
 		for i := 0; i < n.NamedChildCount(); i++ {
 			c := n.NamedChild(i)
 			switch c.Type(lang) {
-			case "function_declaration", "generator_function_declaration",
+			case "function_declaration", "generator_function_declaration", "function_signature",
 				"method_definition", "method_signature", "abstract_method_signature":
-				// A named function/method (incl. interface method_signature and
-				// `abstract foo(): void` abstract_method_signature). We do NOT recurse
+				// A named function/method (incl. interface method_signature,
+				// `abstract foo(): void` abstract_method_signature, and the
+				// bodiless function_signature of a `declare function` or an
+				// overload, whose signatures share one hash). We do NOT recurse
 				// its body: like the Go parser (and unlike Python), JS/TS symbols are
 				// top-level decls plus class methods — capturing nested closures/
 				// callbacks would just add orientation noise. Bare function_expressions
@@ -384,6 +777,17 @@ func jsSymbolsFromAST(source string, lang *ts.Language) (functions, classes []st
 					continue
 				}
 				recordFunc(qualify(prefix, name), c)
+				// A TS method's decorators precede it in the class body; JS
+				// nests them in the method.
+				first := i
+				for first > 0 && n.NamedChild(first-1).Type(lang) == "decorator" {
+					first--
+				}
+				leading := make([]*ts.Node, 0, i-first+1)
+				for j := first; j < i; j++ {
+					leading = append(leading, n.NamedChild(j))
+				}
+				recordDecorators("function", qualify(prefix, name), append(leading, c)...)
 
 			case "class_declaration", "abstract_class_declaration",
 				"interface_declaration", "enum_declaration", "type_alias_declaration",
@@ -401,6 +805,26 @@ func jsSymbolsFromAST(source string, lang *ts.Language) (functions, classes []st
 				}
 				full := qualify(prefix, name)
 				recordClass(full, c)
+				if c.Type(lang) == "enum_declaration" {
+					// `const enum` is the same node with a leading anonymous
+					// `const` token.
+					enums = append(enums, Enum{Name: full, Const: c.ChildCount() > 0 && c.Child(0).Type(lang) == "const"})
+				}
+				if t := c.Type(lang); t == "internal_module" || t == "module" {
+					// `declare module "pkg"` is named by a string: the module
+					// specifier it types.
+					ambient := c.ChildByFieldName("name", lang).Type(lang) == "string"
+					namespaces = append(namespaces, Namespace{Name: full, Ambient: ambient})
+				}
+				if t := c.Type(lang); t == "class_declaration" || t == "abstract_class_declaration" {
+					details = append(details, jsClassDetail(full, c, lang, src))
+					// `@Dec export class` hangs the decorator on the export.
+					if n.Type(lang) == "export_statement" {
+						recordDecorators("class", full, n, c)
+					} else {
+						recordDecorators("class", full, c)
+					}
+				}
 				walk(c, full, depth+1) // descend into the body so methods become Class.method
 
 			case "variable_declarator":
@@ -425,10 +849,39 @@ func jsSymbolsFromAST(source string, lang *ts.Language) (functions, classes []st
 					if cls := childOfType(c, lang, "class", "class_expression"); cls != nil {
 						full := qualify(prefix, name)
 						recordClass(full, cls)
+						details = append(details, jsClassDetail(full, cls, lang, src))
 						walk(cls, full, depth+1)
 						continue
 					}
 				}
+				// Any other top-level binding is a variable, unless it only
+				// holds a require()d module: that is an import.
+				if prefix == "" && jsTopLevel(n, lang) && !jsIsRequire(c.ChildByFieldName("value", lang), lang, src) {
+					var names []string
+					collectPatternNames(nameNode, lang, src, &names)
+					isConst := n.ChildCount() > 0 && n.Child(0).Type(lang) == "const"
+					for _, name := range names {
+						variables = append(variables, Variable{Name: name, Const: isConst})
+					}
+				}
+				walk(c, prefix, depth+1)
+
+			case "export_statement":
+				// `export default function () {}` / `class {}` / `() => …`: an
+				// anonymous default is recorded as `default`, the name importers
+				// use for it. A named one is a declaration, found below.
+				if val := c.ChildByFieldName("value", lang); val != nil && prefix == "" && fieldText(val, "name", lang, src) == "" {
+					switch val.Type(lang) {
+					case "arrow_function", "function_expression", "generator_function":
+						recordFunc("default", val)
+					case "class", "class_expression":
+						recordClass("default", val)
+						details = append(details, jsClassDetail("default", val, lang, src))
+						recordDecorators("class", "default", c, val)
+						walk(val, "default", depth+1)
+						continue
+					}
+				}
 				walk(c, prefix, depth+1)
 
 			default:
@@ -445,9 +898,63 @@ func jsSymbolsFromAST(source string, lang *ts.Language) (functions, classes []st
 		hashes = nil
 	}
 	if len(lines) == 0 {
-		lines = nil
+		lines, cols = nil, nil
 	}
-	return functions, classes, hashes, lines, hasError
+	if len(docs) == 0 {
+		docs = nil
+	}
+	return jsSymbols{functions, classes, enums, namespaces, variables, details, funcDetails, decorated, hashes, lines, cols, docs}, hasError
+}
+
+// jsSymbols is what jsSymbolsFromAST finds in one file.
+type jsSymbols struct {
+	functions, classes []string
+	enums              []Enum
+	namespaces         []Namespace
+	variables          []Variable
+	classDetails       []ClassDetail
+	functionDetails    []FunctionDetail
+	decorated          []Decorated
+	hashes             map[string]string
+	lines, cols        map[string]int
+	docs               map[string]string
+}
+
+// jsDocWrappers are the nodes a declaration's JSDoc comment sits in front of
+// rather than the declaration itself: `export`, `declare`, and the
+// `const`/`let`/`var` statement around a bound arrow function or class.
+var jsDocWrappers = map[string]bool{
+	"export_statement":     true,
+	"ambient_declaration":  true,
+	"lexical_declaration":  true,
+	"variable_declaration": true,
+	"variable_declarator":  true,
+}
+
+// jsDocSummary returns the first sentence of the JSDoc comment (`/** … */`)
+// directly above the declaration at n — on the line before it or the same
+// line — or "" if there is none. The comment precedes the outermost wrapper
+// (see jsDocWrappers) and any decorators a TS method carries ahead of it.
+func jsDocSummary(n *ts.Node, lang *ts.Language, src []byte) string {
+	for p := n.Parent(); p != nil && jsDocWrappers[p.Type(lang)]; p = p.Parent() {
+		n = p
+	}
+	prev := n.PrevSibling()
+	for prev != nil && prev.Type(lang) == "decorator" {
+		n, prev = prev, prev.PrevSibling()
+	}
+	if prev == nil || prev.Type(lang) != "comment" || prev.EndPoint().Row+1 < n.StartPoint().Row {
+		return ""
+	}
+	text := prev.Text(src)
+	if !strings.HasPrefix(text, "/**") || !strings.HasSuffix(text, "*/") || len(text) < 5 {
+		return ""
+	}
+	lines := strings.Split(text[3:len(text)-2], "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(strings.TrimSpace(line), "*")
+	}
+	return docSummary(strings.Join(lines, "\n"))
 }
 
 // fieldText returns the text of n's named child in the given field, or ""
@@ -494,7 +1001,10 @@ func nodeText(n *ts.Node, lang *ts.Language, src []byte) string {
 
 // jsImportsExportsFromAST walks the JS/TS AST and returns this file's import
 // specifiers (module paths — FileStructure.Imports is a list of paths, not
-// bound names), exported names, and bare wildcard re-export specifiers. It
+// bound names), exported names, bare wildcard re-export specifiers, the
+// specifier of every re-export (`export … from '...'`, wildcard or not), the
+// bindings taken from each module, and the sorted specifiers imported only
+// by type-only import statements (see jsTypeOnlyImport). It
 // mirrors jsSymbolsFromAST's structure (same panic/nest-depth guards, same
 // hasError contract) but walks import_statement/export_statement nodes
 // directly instead of extracting functions/classes, resolving alias vs.
@@ -503,7 +1013,7 @@ func nodeText(n *ts.Node, lang *ts.Language, src []byte) string {
 // regex-matched via extractCJSRequires regardless of AST availability (see
 // p.parse), since a declaration-level walk doesn't descend into arbitrary
 // call expressions/function bodies where require() commonly appears.
-func jsImportsExportsFromAST(source string, lang *ts.Language) (imports, exports, wildcardReexports []string, hasError bool) {
+func jsImportsExportsFromAST(source string, lang *ts.Language, diag *diagnostics) (imports, exports, wildcardReexports, reexports []string, details []ImportDetail, typeImports []string, hasError bool) {
 	// Same fail-safe posture as jsSymbolsFromAST: a panic degrades to no AST
 	// imports/exports rather than crashing the indexer/MCP server.
 	// Same hasError contract as jsSymbolsFromAST: every give-up path sets it so the
@@ -511,18 +1021,18 @@ func jsImportsExportsFromAST(source string, lang *ts.Language) (imports, exports
 	// export in a file that panicked, over-nested, or failed to parse.
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Fprintf(os.Stderr, "runecho: JS/TS import/export parse panicked (%v); AST imports/exports for this file disabled\n", r)
-			imports, exports, wildcardReexports, hasError = nil, nil, nil, true
+			diag.addf("JS/TS import/export parse panicked (%v); AST imports/exports for this file disabled", r)
+			imports, exports, wildcardReexports, reexports, details, typeImports, hasError = nil, nil, nil, nil, nil, nil, true
 		}
 	}()
 	src := []byte(source)
 	if exceedsNestDepth(src) {
-		fmt.Fprintf(os.Stderr, "runecho: JS/TS source exceeds max nesting depth (%d); AST imports/exports for this file disabled\n", maxParseNestDepth)
-		return nil, nil, nil, true
+		diag.addf("JS/TS source exceeds max nesting depth (%d); AST imports/exports for this file disabled", maxParseNestDepth)
+		return nil, nil, nil, nil, nil, nil, true
 	}
 	tree, err := ts.NewParser(lang).Parse(src)
 	if err != nil || tree == nil || tree.RootNode() == nil {
-		return nil, nil, nil, true
+		return nil, nil, nil, nil, nil, nil, true
 	}
 	// Same rationale as jsSymbolsFromAST: error-recovery on a partially
 	// unparseable file can drop sibling statements from the tree, so the
@@ -530,6 +1040,7 @@ func jsImportsExportsFromAST(source string, lang *ts.Language) (imports, exports
 	// partial walk.
 	hasError = tree.RootNode().HasError()
 
+	var runtime []string
 	var walk func(n *ts.Node, depth int)
 	walk = func(n *ts.Node, depth int) {
 		if depth > maxParseNestDepth {
@@ -540,13 +1051,35 @@ func jsImportsExportsFromAST(source string, lang *ts.Language) (imports, exports
 			switch c.Type(lang) {
 			case "import_statement":
 				collectImportSource(c, lang, src, &imports)
+				if jsTypeOnlyImport(c, lang) {
+					collectImportSource(c, lang, src, &typeImports)
+				} else {
+					collectImportSource(c, lang, src, &runtime)
+				}
+				if d, ok := jsImportDetail(c, lang, src); ok {
+					details = append(details, d)
+				}
 			case "export_statement":
 				collectExportStatement(c, lang, src, &exports, &wildcardReexports)
+				if source := fieldText(c, "source", lang, src); source != "" {
+					reexports = append(reexports, source)
+				}
 				// export_statement is also a container — e.g. `export
 				// namespace NS { export const X = 1; }` nests another
 				// export_statement inside its declaration's body — so keep
 				// descending into it like any other wrapper node.
 				walk(c, depth+1)
+			case "assignment_expression":
+				collectCJSExports(c, lang, src, &exports)
+				walk(c, depth+1)
+			case "variable_declarator":
+				// `const x = require('x')` / `const { a } = require('x')`.
+				if value := c.ChildByFieldName("value", lang); jsIsRequire(value, lang, src) {
+					if d, ok := jsRequireDetail(c.ChildByFieldName("name", lang), value, lang, src); ok {
+						details = append(details, d)
+					}
+				}
+				walk(c, depth+1)
 			default:
 				// Recurse through every other wrapper (program, statement_block,
 				// class_body, internal_module, ERROR-recovery nodes, …) so
@@ -557,7 +1090,48 @@ func jsImportsExportsFromAST(source string, lang *ts.Language) (imports, exports
 	}
 	walk(tree.RootNode(), 0)
 
-	return imports, exports, wildcardReexports, hasError
+	return imports, exports, wildcardReexports, reexports, details, withoutModules(typeImports, runtime), hasError
+}
+
+// jsTypeOnlyImport reports whether the import_statement n imports types
+// alone, which TypeScript erases: `import type …`, or named imports that are
+// each marked `type` (`import { type A, type B }`). `import type X =
+// require('x')` is not one: its require() call is also matched as a runtime
+// import, by regex in JSParser, so counting it here would split the backends.
+func jsTypeOnlyImport(n *ts.Node, lang *ts.Language) bool {
+	if childOfType(n, lang, "import_require_clause") != nil {
+		return false
+	}
+	if hasToken(n, lang, "type") {
+		return true
+	}
+	clause := childOfType(n, lang, "import_clause")
+	if clause == nil || clause.NamedChildCount() != 1 {
+		return false // a side-effect import, or a default or namespace binding
+	}
+	named := clause.NamedChild(0)
+	if named.Type(lang) != "named_imports" || named.NamedChildCount() == 0 {
+		return false
+	}
+	for i := 0; i < named.NamedChildCount(); i++ {
+		if spec := named.NamedChild(i); spec.Type(lang) == "import_specifier" && !hasToken(spec, lang, "type") {
+			return false
+		}
+	}
+	return true
+}
+
+// withoutModules returns modules, sorted and deduplicated, less any that
+// are also in runtime.
+func withoutModules(modules, runtime []string) []string {
+	var out []string
+	for _, m := range modules {
+		if !slices.Contains(runtime, m) {
+			out = append(out, m)
+		}
+	}
+	sort.Strings(out)
+	return deduplicate(out)
 }
 
 // collectImportSource extracts an import_statement's module specifier into
@@ -578,6 +1152,99 @@ func collectImportSource(n *ts.Node, lang *ts.Language, src []byte, imports *[]s
 	}
 }
 
+// jsImportDetail describes the bindings of the import_statement n, or reports
+// false if it names no module.
+func jsImportDetail(n *ts.Node, lang *ts.Language, src []byte) (ImportDetail, bool) {
+	if req := childOfType(n, lang, "import_require_clause"); req != nil {
+		// `import fs = require('fs')` binds the whole module.
+		d := ImportDetail{Module: fieldText(req, "source", lang, src), Namespace: nodeText(childOfType(req, lang, "identifier"), lang, src)}
+		return d, d.Module != ""
+	}
+	d := ImportDetail{Module: fieldText(n, "source", lang, src)}
+	if d.Module == "" {
+		return d, false
+	}
+	clause := childOfType(n, lang, "import_clause")
+	if clause == nil {
+		return d, true // `import './polyfill'`
+	}
+	for i := 0; i < clause.NamedChildCount(); i++ {
+		c := clause.NamedChild(i)
+		switch c.Type(lang) {
+		case "identifier":
+			d.Default = c.Text(src)
+		case "namespace_import":
+			d.Namespace = nodeText(childOfType(c, lang, "identifier"), lang, src)
+		case "named_imports":
+			for j := 0; j < c.NamedChildCount(); j++ {
+				if spec := c.NamedChild(j); spec.Type(lang) == "import_specifier" {
+					// The name may be a string: `import { 'a-b' as ab }`.
+					if name := jsPropertyName(spec.ChildByFieldName("name", lang), lang, src); name != "" {
+						d.Named = append(d.Named, name)
+					}
+				}
+			}
+		}
+	}
+	return d, true
+}
+
+// jsRequireDetail describes a `require()` declaration: a plain name binds
+// the whole module, and an object pattern imports each key by name.
+func jsRequireDetail(name, call *ts.Node, lang *ts.Language, src []byte) (ImportDetail, bool) {
+	args := call.ChildByFieldName("arguments", lang)
+	if name == nil || args == nil || args.NamedChildCount() != 1 {
+		return ImportDetail{}, false
+	}
+	d := ImportDetail{Module: jsLiteralSpecifier(args.NamedChild(0), lang, src)}
+	if d.Module == "" {
+		return d, false
+	}
+	switch name.Type(lang) {
+	case "identifier":
+		d.Namespace = name.Text(src)
+	case "object_pattern":
+		for i := 0; i < name.NamedChildCount(); i++ {
+			switch c := name.NamedChild(i); c.Type(lang) {
+			case "shorthand_property_identifier_pattern":
+				d.Named = append(d.Named, c.Text(src))
+			case "object_assignment_pattern":
+				d.Named = append(d.Named, fieldText(c, "left", lang, src))
+			case "pair_pattern":
+				if key := jsPropertyName(c.ChildByFieldName("key", lang), lang, src); key != "" {
+					d.Named = append(d.Named, key)
+				}
+			}
+		}
+	}
+	return d, true
+}
+
+// sortImportDetails merges the details of each module — a file may import
+// one module in several statements — and sorts them by module.
+func sortImportDetails(details []ImportDetail) []ImportDetail {
+	sort.SliceStable(details, func(i, j int) bool { return details[i].Module < details[j].Module })
+	var out []ImportDetail
+	for _, d := range details {
+		if len(out) == 0 || out[len(out)-1].Module != d.Module {
+			out = append(out, ImportDetail{Module: d.Module})
+		}
+		m := &out[len(out)-1]
+		if m.Default == "" {
+			m.Default = d.Default
+		}
+		if m.Namespace == "" {
+			m.Namespace = d.Namespace
+		}
+		m.Named = append(m.Named, d.Named...)
+	}
+	for i := range out {
+		sort.Strings(out[i].Named)
+		out[i].Named = deduplicate(out[i].Named)
+	}
+	return out
+}
+
 // collectExportStatement extracts one export_statement node's contribution
 // to *exports/*wildcardReexports. An export_statement takes one of a handful
 // of shapes distinguished by which fields/children are present:
@@ -587,7 +1254,8 @@ func collectImportSource(n *ts.Node, lang *ts.Language, src []byte, imports *[]s
 //     collectExportedDeclNames.
 //   - "value" field: `export default <expression>` where the expression
 //     isn't itself a declaration (an identifier, or an anonymous/named
-//     function or class expression) — see collectExportDefaultValueName.
+//     function or class expression) — see jsDefaultExportName. An
+//     anonymous default is exported as `default`.
 //   - a namespace_export child: `export * as ns [from '...']` binds ns.
 //   - an export_clause child: `export {a, b as c} [from '...']` and the TS
 //     `export type {...}` form — both resolved via export_specifier's
@@ -598,15 +1266,26 @@ func collectImportSource(n *ts.Node, lang *ts.Language, src []byte, imports *[]s
 //     re-export `export * from '...'` — its names aren't enumerable from
 //     this file's text alone (see extractWildcardReexports).
 //
-// TS `export = expr` and `export as namespace X` match none of these and are
-// intentionally left as a no-op (out of scope — not a named export/import).
+// A .d.ts file's `export as namespace X` exports X: the global a UMD
+// library defines when loaded without a module system. TS `export = expr`
+// matches none of these and is intentionally left as a no-op.
 func collectExportStatement(n *ts.Node, lang *ts.Language, src []byte, exports, wildcardReexports *[]string) {
+	if hasToken(n, lang, "namespace") {
+		if name := nodeText(childOfType(n, lang, "identifier"), lang, src); name != "" {
+			*exports = append(*exports, name)
+		}
+		return
+	}
 	if decl := n.ChildByFieldName("declaration", lang); decl != nil {
 		collectExportedDeclNames(decl, lang, src, exports)
 		return
 	}
 	if val := n.ChildByFieldName("value", lang); val != nil {
-		collectExportDefaultValueName(val, lang, src, exports)
+		name := jsDefaultExportName(val, lang, src)
+		if name == "" {
+			name = "default"
+		}
+		*exports = append(*exports, name)
 		return
 	}
 	if ns := childOfType(n, lang, "namespace_export"); ns != nil {
@@ -652,6 +1331,12 @@ func collectExportStatement(n *ts.Node, lang *ts.Language, src []byte, exports,
 // instead of a single name field.
 func collectExportedDeclNames(decl *ts.Node, lang *ts.Language, src []byte, exports *[]string) {
 	switch decl.Type(lang) {
+	case "ambient_declaration":
+		// `export declare <decl>` (a .d.ts staple): the declaration it wraps
+		// is the export.
+		if inner := decl.NamedChild(0); inner != nil {
+			collectExportedDeclNames(inner, lang, src, exports)
+		}
 	case "lexical_declaration", "variable_declaration":
 		for i := 0; i < decl.NamedChildCount(); i++ {
 			d := decl.NamedChild(i)
@@ -665,31 +1350,108 @@ func collectExportedDeclNames(decl *ts.Node, lang *ts.Language, src []byte, expo
 		// class_declaration, abstract_class_declaration,
 		// interface_declaration, enum_declaration, type_alias_declaration,
 		// function_signature, internal_module, module — all carry a plain
-		// "name" field. Anything else (e.g. TS import_alias/
-		// ambient_declaration, which don't) yields "" and is a safe no-op.
+		// "name" field. Anything else (e.g. TS import_alias, which doesn't)
+		// yields "" and is a safe no-op.
 		if name := fieldText(decl, "name", lang, src); name != "" {
 			*exports = append(*exports, name)
 		}
 	}
 }
 
-// collectExportDefaultValueName extracts a name from `export default
-// <expression>` when the expression isn't itself a declaration (those go
-// through collectExportedDeclNames instead — e.g. `export default class Foo
-// {}` parses as a class_declaration in the "declaration" field, not here).
-// An anonymous function/class expression, or any other expression form
-// (call, member, arrow, literal, parenthesized, …), has no exportable name
-// and is intentionally skipped — matching exportDefaultRegex's existing
-// treatment of anonymous defaults.
-func collectExportDefaultValueName(val *ts.Node, lang *ts.Language, src []byte, exports *[]string) {
+// jsDefaultExportName returns the name of `export default <expression>`
+// when the expression isn't itself a declaration (those go through
+// collectExportedDeclNames instead — e.g. `export default class Foo {}`
+// parses as a class_declaration in the "declaration" field, not here). An
+// anonymous function/class expression, or any other expression form (call,
+// member, arrow, literal, parenthesized, …), has no name of its own: "".
+func jsDefaultExportName(val *ts.Node, lang *ts.Language, src []byte) string {
 	switch val.Type(lang) {
 	case "identifier":
-		*exports = append(*exports, val.Text(src))
+		return val.Text(src)
 	case "function_expression", "generator_function", "class", "class_expression":
-		if name := fieldText(val, "name", lang, src); name != "" {
-			*exports = append(*exports, name)
+		return fieldText(val, "name", lang, src)
+	}
+	return ""
+}
+
+// collectCJSExports extracts the names a CommonJS assignment exports into
+// *exports: `exports.foo = …`, `module.exports.foo = …`, and `exports['foo'] =
+// …` export foo; `module.exports = { a, b: …, c() {} }` exports each named
+// key (a spread or computed key is skipped), also through a chained
+// `module.exports = exports = {…}`; and `module.exports = X` is a default
+// export, named as `export default X` would be.
+func collectCJSExports(n *ts.Node, lang *ts.Language, src []byte, exports *[]string) {
+	left, right := n.ChildByFieldName("left", lang), n.ChildByFieldName("right", lang)
+	if left == nil || right == nil {
+		return
+	}
+	if isModuleExports(left, lang, src) {
+		// `module.exports = exports = {…}` exports the innermost value.
+		for right.Type(lang) == "assignment_expression" && right.ChildByFieldName("right", lang) != nil {
+			right = right.ChildByFieldName("right", lang)
 		}
+		if right.Type(lang) != "object" {
+			if name := jsDefaultExportName(right, lang, src); name != "" {
+				*exports = append(*exports, name)
+			}
+			return
+		}
+		for i := 0; i < right.NamedChildCount(); i++ {
+			prop := right.NamedChild(i)
+			switch prop.Type(lang) {
+			case "shorthand_property_identifier":
+				*exports = append(*exports, prop.Text(src))
+			case "pair":
+				if name := jsPropertyName(prop.ChildByFieldName("key", lang), lang, src); name != "" {
+					*exports = append(*exports, name)
+				}
+			case "method_definition":
+				if name := jsPropertyName(prop.ChildByFieldName("name", lang), lang, src); name != "" {
+					*exports = append(*exports, name)
+				}
+			}
+		}
+		return
+	}
+	var obj, prop *ts.Node
+	switch left.Type(lang) {
+	case "member_expression":
+		obj, prop = left.ChildByFieldName("object", lang), left.ChildByFieldName("property", lang)
+	case "subscript_expression":
+		obj, prop = left.ChildByFieldName("object", lang), left.ChildByFieldName("index", lang)
+	default:
+		return
+	}
+	if obj == nil || !(obj.Type(lang) == "identifier" && obj.Text(src) == "exports" || isModuleExports(obj, lang, src)) {
+		return
+	}
+	if name := jsPropertyName(prop, lang, src); name != "" {
+		*exports = append(*exports, name)
+	}
+}
+
+// isModuleExports reports whether n is the member expression `module.exports`.
+func isModuleExports(n *ts.Node, lang *ts.Language, src []byte) bool {
+	if n.Type(lang) != "member_expression" {
+		return false
+	}
+	obj, prop := n.ChildByFieldName("object", lang), n.ChildByFieldName("property", lang)
+	return obj != nil && prop != nil && obj.Type(lang) == "identifier" && obj.Text(src) == "module" && prop.Text(src) == "exports"
+}
+
+// jsPropertyName returns a property key's name — an identifier, or a string
+// literal's contents — or "" for a computed or numeric key.
+func jsPropertyName(key *ts.Node, lang *ts.Language, src []byte) string {
+	if key == nil {
+		return ""
+	}
+	switch key.Type(lang) {
+	case "property_identifier", "identifier":
+		return key.Text(src)
+	case "string":
+		return strings.Trim(key.Text(src), `'"`)
 	}
+	return ""
 }
 
 // collectPatternNames extracts the bound identifier(s) from a
@@ -766,41 +1528,116 @@ func restPatternTarget(n *ts.Node) *ts.Node {
 	return n.NamedChild(n.NamedChildCount() - 1)
 }
 
-// Parse extracts top-level structure from JavaScript/TypeScript source.
-// removeComments strips single-line and multi-line comments.
-// Multi-line /* … */ comments are removed via regex. Single-line // comments
-// are stripped per-line with string-literal awareness so that URLs inside
-// import strings (e.g. import 'http://example.com') are preserved correctly.
-// blockCommentRegex matches a /* ... */ block comment (non-greedy, spans lines).
-var blockCommentRegex = regexp.MustCompile(`/\*[\s\S]*?\*/`)
-
+// removeComments strips // and /* … */ comments, deleting block comments
+// outright. See stripComments for what counts as a comment.
 func removeComments(source string) string {
-	source = blockCommentRegex.ReplaceAllString(source, "")
-
-	lines := strings.Split(source, "\n")
-	for i, line := range lines {
-		lines[i] = stripLineComment(line)
-	}
-	return strings.Join(lines, "\n")
+	return stripComments(source, false)
 }
 
-// maskCommentsLineFaithful blanks comment content but preserves every newline,
-// so byte offsets into the result map to the same 1-based line numbers as the
+// maskCommentsLineFaithful strips comments but preserves every newline, so
+// byte offsets into the result map to the same 1-based line numbers as the
 // original source. removeComments deletes block comments outright, which shifts
 // every line after a multi-line comment — unusable for computing a symbol's
 // start line. Block comments are replaced by just their newlines (dropping the
 // intra-line bytes is harmless: line numbers depend only on newline counts, and
-// the regex fallback matches against this same masked string), and line
-// comments are stripped per line.
+// the regex fallback matches against this same masked string).
 func maskCommentsLineFaithful(source string) string {
-	masked := blockCommentRegex.ReplaceAllStringFunc(source, func(m string) string {
-		return strings.Repeat("\n", strings.Count(m, "\n"))
-	})
-	lines := strings.Split(masked, "\n")
-	for i, line := range lines {
-		lines[i] = stripLineComment(line)
+	return stripComments(source, true)
+}
+
+// stripComments removes the comments from source in one left-to-right pass
+// that tracks string and template literal context, so `//` or `/*` inside a
+// literal — a URL, a glob like 'src/**/*.ts' — is text, not a comment, and a
+// `//` on a line inside a multi-line template is left alone. A template's
+// ${…} substitutions are code again, nested templates included. A line
+// comment's newline is kept; a block comment is replaced by its newlines when
+// keepLines is set and deleted otherwise. A quoted string ends at an
+// unescaped newline, as an unterminated one does in JS, so a stray quote
+// cannot swallow the rest of the file. Regex literals are not recognized: a
+// quote or `//` inside one is misread — still best-effort.
+func stripComments(source string, keepLines bool) string {
+	var out strings.Builder
+	out.Grow(len(source))
+	// depth is the brace depth of the code being scanned; subst holds, per
+	// open ${…} substitution, the depth of the code around its template.
+	depth := 0
+	var subst []int
+	inTemplate := false
+	for i := 0; i < len(source); {
+		c := source[i]
+		next := byte(0)
+		if i+1 < len(source) {
+			next = source[i+1]
+		}
+		if inTemplate {
+			switch {
+			case c == '\\' && next != 0:
+				out.WriteString(source[i : i+2])
+				i += 2
+				continue
+			case c == '`':
+				inTemplate = false
+			case c == '$' && next == '{':
+				subst = append(subst, depth)
+				depth = 0
+				inTemplate = false
+				out.WriteString("${")
+				i += 2
+				continue
+			}
+			out.WriteByte(c)
+			i++
+			continue
+		}
+		switch {
+		case c == '/' && next == '/':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '/' && next == '*':
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				end = len(source)
+			} else {
+				end += i + 4
+			}
+			if keepLines {
+				out.WriteString(strings.Repeat("\n", strings.Count(source[i:end], "\n")))
+			}
+			i = end
+			continue
+		case c == '\'' || c == '"':
+			j := i + 1
+			for j < len(source) && source[j] != c && source[j] != '\n' {
+				if source[j] == '\\' && j+1 < len(source) {
+					j++
+				}
+				j++
+			}
+			if j < len(source) && source[j] == c {
+				j++
+			}
+			out.WriteString(source[i:j])
+			i = j
+			continue
+		case c == '`':
+			inTemplate = true
+		case c == '{':
+			depth++
+		case c == '}':
+			if depth > 0 {
+				depth--
+			} else if len(subst) > 0 {
+				depth = subst[len(subst)-1]
+				subst = subst[:len(subst)-1]
+				inTemplate = true
+			}
+		}
+		out.WriteByte(c)
+		i++
 	}
-	return strings.Join(lines, "\n")
+	return out.String()
 }
 
 // fallbackSymbolLines computes 1-based start lines for the function and class
@@ -905,8 +1742,11 @@ func blankCommentsAndStrings(src string) string {
 // rather than over-captures, so the hash can only MISS a change, never invent
 // one from a sibling. Braces/boundaries are scanned on a comment/string-blanked
 // view so a delimiter inside a literal never miscounts.
-func typedArrowHashes(source string) map[string]string {
-	masked := blankCommentsAndStrings(source)
+//
+// Spans are found on view — source with its JSX text masked (maskJSXText), or
+// source itself — and hashed from source, so a text edit still counts.
+func typedArrowHashes(source, view string) map[string]string {
+	masked := blankCommentsAndStrings(view)
 	out := map[string]string{}
 	for _, idx := range arrowFuncRegex.FindAllStringSubmatchIndex(masked, -1) {
 		if len(idx) < 4 || idx[2] < 0 {
@@ -1009,37 +1849,6 @@ func mergeFallbackLines(lines map[string]int, prefix string, names []string, nam
 	}
 }
 
-// stripLineComment removes a // comment from a line, skipping // that appears
-// inside a string literal (single-quote, double-quote, or backtick).
-// Handles backslash escapes inside string literals. Template literal nesting
-// and exotic Unicode escapes are not tracked — this is still best-effort.
-func stripLineComment(line string) string {
-	inStr := false
-	var strChar byte
-	for i := 0; i < len(line); i++ {
-		c := line[i]
-		if inStr {
-			if c == '\\' {
-				i++ // skip the escaped character
-				continue
-			}
-			if c == strChar {
-				inStr = false
-			}
-			continue
-		}
-		if c == '\'' || c == '"' || c == '`' {
-			inStr = true
-			strChar = c
-			continue
-		}
-		if c == '/' && i+1 < len(line) && line[i+1] == '/' {
-			return line[:i]
-		}
-	}
-	return line
-}
-
 // extractImports finds all import statements.
 func extractImports(source string) []string {
 	imports := []string{}
@@ -1060,6 +1869,9 @@ func extractImports(source string) []string {
 		}
 	}
 
+	// Dynamic imports
+	imports = append(imports, extractDynamicImports(source)...)
+
 	return imports
 }
 
@@ -1080,6 +1892,22 @@ func extractCJSRequires(source string) []string {
 	return imports
 }
 
+// extractDynamicImports finds the specifier of every dynamic import('path')
+// with a literal path — a lazily loaded chunk is as much a dependency as a
+// static import. Like a require(), it can appear anywhere in the file, so it is
+// regex-matched and unioned into Imports regardless of AST availability.
+func extractDynamicImports(source string) []string {
+	var imports []string
+	for _, match := range importDynamicRegex.FindAllStringSubmatch(source, -1) {
+		for _, spec := range match[1:] {
+			if spec != "" {
+				imports = append(imports, spec)
+			}
+		}
+	}
+	return imports
+}
+
 // extractFunctions finds all top-level function declarations (regex fallback
 // used only when no tree-sitter grammar is embedded).
 func extractFunctions(source string) []string {
@@ -1178,29 +2006,61 @@ func extractExports(source string) []string {
 		}
 	}
 
-	// Namespace re-export: export * as ns from './m' binds ns.
-	matches = exportStarAsRegex.FindAllStringSubmatch(source, -1)
+	// Namespace re-export: export * as ns from './m' binds ns; a UMD
+	// library's export as namespace X exports X.
+	matches = append(exportStarAsRegex.FindAllStringSubmatch(source, -1), exportAsNamespaceRegex.FindAllStringSubmatch(source, -1)...)
 	for _, match := range matches {
 		if len(match) > 1 {
 			exports = append(exports, match[1])
 		}
 	}
 
-	// Default exports: export default [function|class] Foo
+	// Default exports: export default [function|class] Foo, or `default`
+	// for an anonymous one.
 	matches = exportDefaultRegex.FindAllStringSubmatch(source, -1)
 	for _, match := range matches {
-		name := match[1] // export default [async] function Foo
+		name := match[1] + match[2] + match[3] // at most one group matched
+		if name == "extends" || name == "implements" {
+			name = "" // export default class extends Base
+		}
 		if name == "" {
-			name = match[2] // export default class Foo
+			name = "default"
 		}
-		if name == "" && match[3] != "function" && match[3] != "class" && match[3] != "async" {
-			name = match[3] // export default identifier
+		exports = append(exports, name)
+	}
+
+	return append(exports, extractCJSExports(source)...)
+}
+
+// extractCJSExports finds the names CommonJS assignments export (see
+// collectCJSExports, the AST equivalent).
+func extractCJSExports(source string) []string {
+	var exports []string
+	for _, match := range cjsExportPropRegex.FindAllStringSubmatch(source, -1) {
+		if name := match[1] + match[2]; name != "" {
+			exports = append(exports, name)
 		}
-		if name != "" {
+	}
+	for _, match := range cjsExportObjectRegex.FindAllStringSubmatch(source, -1) {
+		for _, prop := range strings.Split(cjsNestedBraceRegex.ReplaceAllString(match[1], ""), ",") {
+			prop = strings.TrimSpace(prop)
+			if strings.HasPrefix(prop, "...") || strings.HasPrefix(prop, "[") {
+				continue
+			}
+			if i := strings.IndexAny(prop, ":("); i >= 0 {
+				prop = strings.TrimSpace(prop[:i])
+			}
+			prop = strings.Trim(prop, `'"`)
+			if prop != "" && !strings.ContainsAny(prop, " \t\n") {
+				exports = append(exports, prop)
+			}
+		}
+	}
+	for _, match := range cjsExportDefaultRegex.FindAllStringSubmatch(source, -1) {
+		if name := match[1] + match[2] + match[3]; name != "" && name != "function" && name != "class" && name != "async" {
 			exports = append(exports, name)
 		}
 	}
-
 	return exports
 }
 
@@ -1218,6 +2078,16 @@ func extractWildcardReexports(source string) []string {
 	return specifiers
 }
 
+// extractReExports finds the module specifier of every re-export, wildcard or
+// named. See reExportRegex.
+func extractReExports(source string) []string {
+	var specifiers []string
+	for _, match := range reExportRegex.FindAllStringSubmatch(source, -1) {
+		specifiers = append(specifiers, match[1])
+	}
+	return specifiers
+}
+
 // splitTopLevelDeclNames splits a `const`/`let`/`var` declarator list (the
 // text after the keyword, up to the statement terminator) into its bound
 // names. Commas inside (), [], {}, or a string/template literal are NOT
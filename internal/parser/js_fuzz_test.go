@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// jsFuzzSeeds are shaped after the real-world JS the parser meets that the
+// table tests do not: minified bundles (one enormous line, no whitespace to
+// anchor on, comment markers inside regex and string literals) and
+// template-heavy code (tagged templates, nested ${} interpolation, JSX, HTML
+// with its own comment syntax). Malformed tails are included on purpose.
+var jsFuzzSeeds = []string{
+	// minified bundles
+	`!function(e,t){"object"==typeof exports&&"undefined"!=typeof module?module.exports=t():"function"==typeof define&&define.amd?define(t):(e=e||self).Lib=t()}(this,function(){"use strict";var e=/\/\*.*?\*\//g;function t(n){return n.replace(e,"")}return{strip:t}});`,
+	`var a=require("./a"),b=require('b');exports.x=function(){return"//not a comment"+a(b)};/*! license */`,
+	`export{a as b,c};export*from"./d";import e,{f as g}from"./h";const i=(j,k)=>j/k/2;class l extends m{n(){return/[/*]/.test(this.o)}}`,
+	`(()=>{var e={123:(e,t,r)=>{r.d(t,{Z:()=>n});const n=()=>{}}};})();//# sourceMappingURL=main.js.map`,
+	// template-heavy
+	"const row = (r) => html`<tr class=${r.cls}>${r.cells.map(c => html`<td>${c}</td>`)}</tr>`;\nexport function table(rs) { return html`<table><!-- rows -->${rs.map(row)}</table>` }\n",
+	"const q = sql`SELECT * FROM t WHERE id = ${id} -- trailing // not js\n`;\nexport default q;\n",
+	"const s = `outer ${`inner ${`deepest ${x}`}`} /* not a comment */ ${'// nor this'}`;\nfunction after() {}\n",
+	"export const View = ({items}) => (\n  <ul>{/* jsx comment */}{items.map(i => <li key={i.id}>{`${i.name}`}</li>)}</ul>\n);\n",
+	"export const Note = () => <p>Don't use // here, it's {count} of <b>http://x</b></p>;\nconst id = <T,>(x: T) => x;\n",
+	"interface Props<T extends Record<string, `${number}px`>> { size: T }\nexport function f<T>(p: Props<T>): `a${string}` { return `a${p}` }\n",
+	// malformed and truncated
+	"/* unterminated block comment\nfunction hidden() {}",
+	"const t = `unterminated ${ template",
+	"function ( { [ ` ' \" /",
+	"\r\n\r\n// only comments\r\n/**/\r\n",
+	"", "`", "/*", "*/", "//", "${", "<!--", "\x00\xff",
+}
+
+var jsFuzzExts = []string{".js", ".ts", ".tsx", ".jsx", ".mjs", ".gs"}
+
+// jsParseBudget bounds a single parse of a fuzz-sized input. Go's regexp is
+// RE2, linear in its input, so the regexes cannot backtrack catastrophically;
+// what this catches is a super-linear loop in the hand-written scanners
+// (comment masking, brace matching) that the regexes feed.
+const jsParseBudget = 2 * time.Second
+
+// FuzzJSParseExt drives every grammar the JS parser selects. Beyond "never
+// panic" and the sorted/deduplicated lists, it requires determinism — the same
+// input parsed twice yields the same structure, hashes and lines included — and
+// a bounded runtime. Run: go test -run=x -fuzz=FuzzJSParseExt ./internal/parser
+func FuzzJSParseExt(f *testing.F) {
+	for i, s := range jsFuzzSeeds {
+		f.Add(s, uint8(i))
+	}
+	p := NewJSParser()
+	f.Fuzz(func(t *testing.T, src string, extIdx uint8) {
+		ext := jsFuzzExts[int(extIdx)%len(jsFuzzExts)]
+		start := time.Now()
+		first, err := p.ParseExt(src, ext)
+		if took := time.Since(start); took > jsParseBudget {
+			t.Fatalf("ParseExt(%s) took %s on %d bytes", ext, took, len(src))
+		}
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, first)
+		second, err := p.ParseExt(src, ext)
+		if err != nil {
+			t.Fatalf("second ParseExt(%s) failed where the first succeeded: %v", ext, err)
+		}
+		if !reflect.DeepEqual(first, second) {
+			t.Fatalf("ParseExt(%s) is nondeterministic:\nfirst:  %+v\nsecond: %+v", ext, first, second)
+		}
+	})
+}
+
+// FuzzRemoveComments pins the comment strippers' contracts. removeComments only
+// ever deletes, so its output is never longer than its input.
+// maskCommentsLineFaithful exists to keep line numbers, so it must preserve the
+// newline count exactly. Source without a slash has no comment to strip.
+// Run: go test -run=x -fuzz=FuzzRemoveComments ./internal/parser
+func FuzzRemoveComments(f *testing.F) {
+	for _, s := range jsFuzzSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		if out := removeComments(src); len(out) > len(src) {
+			t.Fatalf("removeComments grew the input: %d > %d bytes", len(out), len(src))
+		}
+		masked := maskCommentsLineFaithful(src)
+		if got, want := strings.Count(masked, "\n"), strings.Count(src, "\n"); got != want {
+			t.Fatalf("maskCommentsLineFaithful changed the line count: %d newlines, want %d", got, want)
+		}
+		if !strings.Contains(src, "/") {
+			if out := removeComments(src); out != src {
+				t.Fatalf("removeComments(%q) = %q, want it unchanged", src, out)
+			}
+		}
+	})
+}
+
+// FuzzMaskJSXText pins maskJSXText's contract: it only ever blanks bytes to
+// spaces, never a newline, so offsets and line numbers survive, and a source
+// with no `<` comes back unchanged. It must also stay linear on unclosed
+// markup. Run: go test -run=x -fuzz=FuzzMaskJSXText ./internal/parser
+func FuzzMaskJSXText(f *testing.F) {
+	for _, s := range jsFuzzSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		start := time.Now()
+		out := maskJSXText(src)
+		if took := time.Since(start); took > jsParseBudget {
+			t.Fatalf("maskJSXText took %s on %d bytes", took, len(src))
+		}
+		if len(out) != len(src) {
+			t.Fatalf("maskJSXText changed the length: %d, want %d", len(out), len(src))
+		}
+		for i := range out {
+			if out[i] != src[i] && (out[i] != ' ' || src[i] == '\n') {
+				t.Fatalf("maskJSXText changed byte %d from %q to %q", i, src[i], out[i])
+			}
+		}
+		if !strings.Contains(src, "<") && out != src {
+			t.Fatal("maskJSXText changed a source with no markup")
+		}
+	})
+}
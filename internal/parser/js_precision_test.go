@@ -406,14 +406,13 @@ export default () => {
 	return 42;
 };
 `,
-			// "export default" followed by an anonymous arrow — exportDefaultRegex
-			// requires an identifier after "default".  "() =>" has no name.
-			// Not captured.  Under-capture: the module's default export is
-			// not recorded.
-			wantFuncs: []string{},
+			// "export default" followed by an anonymous arrow — "() =>" has no
+			// name, so the default export and its function are both recorded
+			// under the sentinel `default`.
+			wantFuncs: []string{"default"},
 			wantClass: []string{},
-			wantExp:   []string{},
-			note:      "KNOWN: anonymous default export arrow not captured in Exports",
+			wantExp:   []string{"default"},
+			note:      "",
 		},
 		{
 			name: "export_default_object_literal",
@@ -424,11 +423,11 @@ export default {
 };
 `,
 			// "export default {" — the token after "default" is "{", not an
-			// identifier.  Not captured.
+			// identifier, so the export is the sentinel `default`.
 			wantFuncs: []string{},
 			wantClass: []string{},
-			wantExp:   []string{},
-			note:      "KNOWN: 'export default { ... }' object literal not captured in Exports",
+			wantExp:   []string{"default"},
+			note:      "",
 		},
 
 		// ------------------------------------------------------------------ //
@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -187,9 +190,9 @@ func TestJSParser_ExportDefault_NamedFunctionAndClass(t *testing.T) {
 			wantExp: []string{"MyComponent"},
 		},
 		{
-			name:    "export default anonymous function — no export name",
+			name:    "export default anonymous function — the default sentinel",
 			source:  "export default function() {}",
-			wantExp: []string{},
+			wantExp: []string{"default"},
 		},
 	}
 
@@ -450,6 +453,8 @@ func TestJSParser_SupportsExtension(t *testing.T) {
 		{".mjs", true},
 		{".cjs", true},
 		{".ts", true},
+		{".mts", true},
+		{".cts", true},
 		{".gs", true},
 		{".py", false},
 		{".go", false},
@@ -585,3 +590,688 @@ func equalStringSlices(a, b []string) bool {
 	}
 	return true
 }
+
+// TestJSParser_Enums: `enum` and `const enum` are recorded as enums as well as
+// classes, qualified like classes, and `export declare` enums (a .d.ts staple)
+// are exports.
+func TestJSParser_Enums(t *testing.T) {
+	source := `export enum Color { Red, Green }
+const enum Direction { Up = 1, Down }
+export declare const enum Flags { None = 0 }
+namespace Shapes {
+	export enum Kind { Circle }
+}
+enum Color { Blue = 2 }
+const doc = "enum Fake { A }";
+`
+	got, err := NewJSParser().ParseExt(source, ".ts")
+	if err != nil {
+		t.Fatalf("ParseExt: %v", err)
+	}
+	want := []Enum{{Name: "Color"}, {Name: "Direction", Const: true}, {Name: "Flags", Const: true}, {Name: "Shapes.Kind"}}
+	if !reflect.DeepEqual(got.Enums, want) {
+		t.Errorf("Enums = %+v, want %+v", got.Enums, want)
+	}
+	for _, e := range got.Enums {
+		if !slices.Contains(got.Classes, e.Name) {
+			t.Errorf("enum %s is not also a class (classes %q)", e.Name, got.Classes)
+		}
+	}
+	for _, name := range []string{"Color", "Flags"} {
+		if !slices.Contains(got.Exports, name) {
+			t.Errorf("Exports = %q, want %s", got.Exports, name)
+		}
+	}
+	if plain, _ := NewJSParser().ParseExt("function f() {}\n", ".ts"); plain.Enums != nil {
+		t.Errorf("a file without enums has Enums %+v, want nil", plain.Enums)
+	}
+}
+
+// TestJSParser_Namespaces: namespaces, nested and dotted ones qualified like
+// classes, and ambient `declare module "pkg"` declarations are recorded as
+// namespaces as well as classes; members are qualified by the namespace.
+func TestJSParser_Namespaces(t *testing.T) {
+	source := `namespace Geometry {
+	export function area() { return 0 }
+	export namespace Shapes { export class Circle {} }
+}
+namespace A.B { export const x = 1 }
+module Legacy {}
+declare module "express-serve" {
+	interface Request { user: string }
+}
+declare module 'untyped-lib';
+declare namespace NodeJS { interface Global {} }
+namespace Geometry { export const pi = 3 }
+`
+	want := []Namespace{
+		{Name: "A.B"}, {Name: "Geometry"}, {Name: "Geometry.Shapes"}, {Name: "Legacy"}, {Name: "NodeJS"},
+		{Name: "express-serve", Ambient: true}, {Name: "untyped-lib", Ambient: true},
+	}
+	for _, ext := range []string{".ts", ".tsx"} {
+		got, err := NewJSParser().ParseExt(source, ext)
+		if err != nil {
+			t.Fatalf("ParseExt: %v", err)
+		}
+		if !reflect.DeepEqual(got.Namespaces, want) {
+			t.Errorf("%s Namespaces = %+v, want %+v", ext, got.Namespaces, want)
+		}
+		for _, name := range []string{"Geometry.area", "Geometry.Shapes.Circle", "express-serve.Request"} {
+			if !slices.Contains(got.Functions, name) && !slices.Contains(got.Classes, name) {
+				t.Errorf("%s: %s is not a qualified member (functions %q, classes %q)", ext, name, got.Functions, got.Classes)
+			}
+		}
+		if ast, _ := NewTreeSitterParser().ParseExt(source, ext); !reflect.DeepEqual(ast.Namespaces, want) {
+			t.Errorf("%s TreeSitterParser Namespaces = %+v, want %+v", ext, ast.Namespaces, want)
+		}
+	}
+	if plain, _ := NewJSParser().ParseExt("class C {}\n", ".ts"); plain.Namespaces != nil {
+		t.Errorf("a file without namespaces has Namespaces %+v, want nil", plain.Namespaces)
+	}
+}
+
+// TestJSParser_Variables: top-level bindings that are not functions or
+// classes are variables, destructured ones by each bound name; nested
+// bindings and require()d modules are not.
+func TestJSParser_Variables(t *testing.T) {
+	source := `const fs = require('fs');
+export const API_URL = "http://example.com";
+export let retries = 3, timeout;
+var config = { debug: false };
+const { host, port: listenPort = 80, ...rest } = config;
+declare const VERSION: string;
+const handler = () => API_URL;
+const Model = class {};
+function setup() { const local = 1; return local }
+namespace NS { export const inner = 1 }
+var config = {};
+`
+	got, err := NewJSParser().ParseExt(source, ".ts")
+	if err != nil {
+		t.Fatalf("ParseExt: %v", err)
+	}
+	want := []Variable{
+		{Name: "API_URL", Const: true}, {Name: "VERSION", Const: true}, {Name: "config"}, {Name: "host", Const: true},
+		{Name: "listenPort", Const: true}, {Name: "rest", Const: true}, {Name: "retries"}, {Name: "timeout"},
+	}
+	if !reflect.DeepEqual(got.Variables, want) {
+		t.Errorf("Variables = %+v, want %+v", got.Variables, want)
+	}
+	if ast, _ := NewTreeSitterParser().ParseExt(source, ".ts"); !reflect.DeepEqual(ast.Variables, want) {
+		t.Errorf("TreeSitterParser Variables = %+v, want %+v", ast.Variables, want)
+	}
+	if !slices.Contains(got.Exports, "API_URL") || !slices.Contains(got.Functions, "handler") {
+		t.Errorf("Exports = %q, Functions = %q", got.Exports, got.Functions)
+	}
+	if plain, _ := NewJSParser().ParseExt("function f() {}\n", ".js"); plain.Variables != nil {
+		t.Errorf("a file without variables has Variables %+v, want nil", plain.Variables)
+	}
+}
+
+func TestJSParser_ImportDetails(t *testing.T) {
+	source := `import React, { useState as useLocalState, type FC } from 'react';
+import { useEffect, 'kebab-name' as kebab } from 'react';
+import * as path from 'path';
+import './polyfill';
+import fs = require('fs');
+const { join, resolve: res, sep = '/' } = require('node:path');
+const lodash = require('lodash');
+function lazy() { const { parse } = require('yaml'); return parse }
+const dynamic = require(name);
+`
+	want := []ImportDetail{
+		{Module: "./polyfill"},
+		{Module: "fs", Namespace: "fs"},
+		{Module: "lodash", Namespace: "lodash"},
+		{Module: "node:path", Named: []string{"join", "resolve", "sep"}},
+		{Module: "path", Namespace: "path"},
+		{Module: "react", Default: "React", Named: []string{"FC", "kebab-name", "useEffect", "useState"}},
+		{Module: "yaml", Named: []string{"parse"}},
+	}
+	got, err := NewJSParser().ParseExt(source, ".ts")
+	if err != nil {
+		t.Fatalf("ParseExt: %v", err)
+	}
+	if !reflect.DeepEqual(got.ImportDetails, want) {
+		t.Errorf("ImportDetails:\n got %+v\nwant %+v", got.ImportDetails, want)
+	}
+	if ast, _ := NewTreeSitterParser().ParseExt(source, ".ts"); !reflect.DeepEqual(ast.ImportDetails, want) {
+		t.Errorf("TreeSitterParser ImportDetails:\n got %+v\nwant %+v", ast.ImportDetails, want)
+	}
+	for _, d := range got.ImportDetails {
+		if !slices.Contains(got.Imports, d.Module) {
+			t.Errorf("detail for %s, which is not in Imports %q", d.Module, got.Imports)
+		}
+	}
+}
+
+// Type-only imports are erased at compile time: a module imported only
+// that way is a type import, still listed in Imports; any runtime import of
+// it, whether a value binding, require(), or import(), makes it a runtime one.
+// `import type X = require()` counts as a require().
+func TestJSParser_TypeImports(t *testing.T) {
+	source := `import type { Config } from './config';
+import type * as Schema from './schema';
+import type Default from './default';
+import { type Shape, type Size } from './geometry';
+import { type Props, render } from './render';
+import type { Store } from './store';
+import { createStore } from './store';
+import type { Lazy } from './lazy';
+import type Legacy = require('./legacy');
+import './styles.css';
+function load() { return import('./lazy') }
+`
+	want := []string{"./config", "./default", "./geometry", "./schema"}
+	got, err := NewJSParser().ParseExt(source, ".ts")
+	if err != nil {
+		t.Fatalf("ParseExt: %v", err)
+	}
+	if !reflect.DeepEqual(got.TypeImports, want) {
+		t.Errorf("TypeImports = %q, want %q", got.TypeImports, want)
+	}
+	if ast, _ := NewTreeSitterParser().ParseExt(source, ".ts"); !reflect.DeepEqual(ast.TypeImports, want) {
+		t.Errorf("TreeSitterParser TypeImports = %q, want %q", ast.TypeImports, want)
+	}
+	for _, m := range want {
+		if !slices.Contains(got.Imports, m) {
+			t.Errorf("type import %s is missing from Imports %q", m, got.Imports)
+		}
+	}
+	if plain, _ := NewJSParser().ParseExt("import { a } from './a';\n", ".ts"); plain.TypeImports != nil {
+		t.Errorf("a file without type imports has TypeImports %q, want nil", plain.TypeImports)
+	}
+}
+
+// A typings package's .d.ts declares rather than defines: its bodiless
+// functions, ambient consts and classes, and UMD global are all recorded.
+func TestJSParser_DeclarationFile(t *testing.T) {
+	source := `export as namespace MyLib;
+/** Greets someone. */
+declare function greet(name: string): string;
+declare function greet(id: number): string;
+export declare function load(path: string): Promise<void>;
+declare const VERSION: string;
+export declare class Widget {
+	constructor(el: Element);
+	render(): void;
+}
+declare namespace MyLib {
+	function helper(): void;
+}
+export function overloaded(a: string): void;
+export function overloaded(a: number): void;
+export function overloaded(a: any) {}
+`
+	got, err := NewJSParser().ParseExt(source, ".ts")
+	if err != nil {
+		t.Fatalf("ParseExt: %v", err)
+	}
+	wantFuncs := []string{"MyLib.helper", "Widget.constructor", "Widget.render", "greet", "load", "overloaded"}
+	if !reflect.DeepEqual(got.Functions, wantFuncs) {
+		t.Errorf("Functions = %q, want %q", got.Functions, wantFuncs)
+	}
+	if want := []string{"MyLib", "Widget", "load", "overloaded"}; !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+	if want := []Variable{{Name: "VERSION", Const: true}}; !reflect.DeepEqual(got.Variables, want) {
+		t.Errorf("Variables = %+v, want %+v", got.Variables, want)
+	}
+	if got.Docs["function:greet"] != "Greets someone." || got.SymbolLines["function:greet"] != 3 {
+		t.Errorf("greet doc %q, line %d", got.Docs["function:greet"], got.SymbolLines["function:greet"])
+	}
+	// Overloads are one function: the first signature's detail, and a hash
+	// over every signature.
+	if d := got.FunctionDetails[slices.IndexFunc(got.FunctionDetails, func(d FunctionDetail) bool { return d.Name == "greet" })]; !reflect.DeepEqual(d.Params, []string{"name"}) {
+		t.Errorf("greet detail = %+v, want the first signature's", d)
+	}
+	edited, _ := NewJSParser().ParseExt(strings.Replace(source, "id: number", "id: bigint", 1), ".ts")
+	if edited.SymbolHashes["function:greet"] == got.SymbolHashes["function:greet"] {
+		t.Error("editing the second overload did not change greet's hash")
+	}
+	if ast, _ := NewTreeSitterParser().ParseExt(source, ".ts"); !reflect.DeepEqual(ast, got) {
+		t.Errorf("TreeSitterParser disagrees:\n got %+v\nwant %+v", ast, got)
+	}
+	if regex := extractExports(source); !slices.Contains(regex, "MyLib") || !slices.Contains(regex, "load") || !slices.Contains(regex, "Widget") {
+		t.Errorf("regex fallback Exports = %q", regex)
+	}
+}
+
+// Node's module-system extensions: .mjs and .mts are ESM, .cjs and .cts
+// CommonJS, and the TS pair parse with the TypeScript grammar.
+func TestJSParser_ModuleExtensions(t *testing.T) {
+	esm := "import { readFile } from 'node:fs/promises';\nexport const load = async (p) => readFile(p);\nexport default function main() {}\nconst cfg = await import('./config.mjs');\n"
+	cjs := "const path = require('node:path');\nfunction resolve(p) { return path.resolve(p) }\nmodule.exports = { resolve };\n"
+	typed := "export interface Options { strict: boolean }\nexport function run(opts: Options): number { return 0 }\nconst cast = <T>(x: unknown) => x as T;\n"
+	cases := []struct {
+		ext, source      string
+		imports, exports []string
+		functions        []string
+	}{
+		{".mjs", esm, []string{"./config.mjs", "node:fs/promises"}, []string{"load", "main"}, []string{"load", "main"}},
+		{".cjs", cjs, []string{"node:path"}, []string{"resolve"}, []string{"resolve"}},
+		{".mts", esm + typed, []string{"./config.mjs", "node:fs/promises"}, []string{"Options", "load", "main", "run"}, []string{"cast", "load", "main", "run"}},
+		{".cts", cjs + typed, []string{"node:path"}, []string{"Options", "resolve", "run"}, []string{"cast", "resolve", "run"}},
+	}
+	for _, tc := range cases {
+		got, err := NewJSParser().ParseExt(tc.source, tc.ext)
+		if err != nil {
+			t.Fatalf("ParseExt(%s): %v", tc.ext, err)
+		}
+		if !reflect.DeepEqual(got.Imports, tc.imports) || !reflect.DeepEqual(got.Exports, tc.exports) || !reflect.DeepEqual(got.Functions, tc.functions) {
+			t.Errorf("%s: Imports = %q, Exports = %q, Functions = %q; want %q, %q, %q", tc.ext, got.Imports, got.Exports, got.Functions, tc.imports, tc.exports, tc.functions)
+		}
+		if ast, _ := NewTreeSitterParser().ParseExt(tc.source, tc.ext); !reflect.DeepEqual(ast, got) {
+			t.Errorf("%s: TreeSitterParser disagrees:\n got %+v\nwant %+v", tc.ext, ast, got)
+		}
+	}
+	// .mts is TypeScript, not TSX: a `<T>` generic arrow is not an element.
+	mts, _ := NewJSParser().ParseExt(typed, ".mts")
+	ts, _ := NewJSParser().ParseExt(typed, ".ts")
+	if !reflect.DeepEqual(mts, ts) {
+		t.Errorf(".mts parsed differently from .ts:\n got %+v\nwant %+v", mts, ts)
+	}
+}
+
+// TestJSParser_ClassDetails: each class records its superclass as written and
+// its instance methods and static members by leaf name, sorted; a nested
+// class's members stay its own, and interfaces get no detail.
+func TestJSParser_ClassDetails(t *testing.T) {
+	source := `export abstract class Service<T> extends Base<T> implements Runnable {
+	private static readonly registry: Map<string, Service<any>> = new Map();
+	name: string;
+	constructor(name: string) { super(); this.name = name; }
+	abstract run(): void;
+	get label() { return this.name; }
+	set label(v: string) { this.name = v; }
+	static create(): Service<any> { return null; }
+}
+const Mixed = class extends mixin(Service,
+	Logger) { log() {} };
+class Plain implements Runnable { run() {} }
+namespace Outer { export class Inner { static make() {} } }
+interface Runnable { run(): void }
+`
+	got, err := NewJSParser().ParseExt(source, ".ts")
+	if err != nil {
+		t.Fatalf("ParseExt: %v", err)
+	}
+	want := []ClassDetail{
+		{Name: "Mixed", Extends: "mixin(Service, Logger)", Methods: []string{"log"}},
+		{Name: "Outer.Inner", Static: []string{"make"}},
+		{Name: "Plain", Methods: []string{"run"}},
+		{Name: "Service", Extends: "Base<T>", Methods: []string{"constructor", "label", "run"}, Static: []string{"create", "registry"}},
+	}
+	if !reflect.DeepEqual(got.ClassDetails, want) {
+		t.Errorf("ClassDetails:\n got %+v\nwant %+v", got.ClassDetails, want)
+	}
+
+	js, _ := NewJSParser().ParseExt("class A extends B.C { static x = 1; y = 2; #secret() {} static { init() } }\n", ".js")
+	if want := []ClassDetail{{Name: "A", Extends: "B.C", Methods: []string{"#secret"}, Static: []string{"x"}}}; !reflect.DeepEqual(js.ClassDetails, want) {
+		t.Errorf("JS ClassDetails = %+v, want %+v", js.ClassDetails, want)
+	}
+}
+
+// TestJSParser_Decorators: decorators on classes and methods are recorded
+// against the decorated symbol, in source order, by the name they apply —
+// wherever the grammar hangs them (on an export, before a TS method, inside a
+// JS one). Parameter and field decorators are not.
+func TestJSParser_Decorators(t *testing.T) {
+	source := `@Injectable()
+export class UserService {
+	constructor(@Inject(TOKEN) private repo: Repo) {}
+}
+
+@Controller('users')
+export class UserController {
+	@Input() limit: number;
+	@Get(':id')
+	@Auth.Guard({ role: 'admin' })
+	find(id: string) {}
+	list() {}
+}
+
+@Component({
+	selector: 'app-root',
+})
+class AppComponent {}
+`
+	got, err := NewJSParser().ParseExt(source, ".ts")
+	if err != nil {
+		t.Fatalf("ParseExt: %v", err)
+	}
+	want := []Decorated{
+		{Kind: "class", Name: "AppComponent", Decorators: []string{"Component"}},
+		{Kind: "class", Name: "UserController", Decorators: []string{"Controller"}},
+		{Kind: "class", Name: "UserService", Decorators: []string{"Injectable"}},
+		{Kind: "function", Name: "UserController.find", Decorators: []string{"Get", "Auth.Guard"}},
+	}
+	if !reflect.DeepEqual(got.Decorators, want) {
+		t.Errorf("Decorators:\n got %+v\nwant %+v", got.Decorators, want)
+	}
+
+	js, _ := NewJSParser().ParseExt("@sealed\nexport class C { @log m() {} }\n", ".js")
+	if want := []Decorated{{Kind: "class", Name: "C", Decorators: []string{"sealed"}}, {Kind: "function", Name: "C.m", Decorators: []string{"log"}}}; !reflect.DeepEqual(js.Decorators, want) {
+		t.Errorf("JS Decorators = %+v, want %+v", js.Decorators, want)
+	}
+}
+
+func TestJSParser_ReExports(t *testing.T) {
+	source := `import { a } from './a';
+export * from './utils';
+export * as ns from './ns';
+export { x, y as z } from './y';
+export { default as W } from './w';
+export type { T } from './types';
+export { a };
+// export * from './commented';
+`
+	for _, ext := range []string{".ts", ".js"} {
+		if ext == ".js" {
+			source = strings.Replace(source, "export type {", "export {", 1)
+		}
+		got, err := NewJSParser().ParseExt(source, ext)
+		if err != nil {
+			t.Fatalf("ParseExt(%s): %v", ext, err)
+		}
+		if want := []string{"./ns", "./types", "./utils", "./w", "./y"}; !reflect.DeepEqual(got.ReExports, want) {
+			t.Errorf("%s ReExports = %q, want %q", ext, got.ReExports, want)
+		}
+		if want := []string{"./utils"}; !reflect.DeepEqual(got.WildcardReexports, want) {
+			t.Errorf("%s WildcardReexports = %q, want %q", ext, got.WildcardReexports, want)
+		}
+		if want := []string{"./a"}; !reflect.DeepEqual(got.Imports, want) {
+			t.Errorf("%s Imports = %q, want %q", ext, got.Imports, want)
+		}
+	}
+	// The regex fallback, used where the grammar leaves an ERROR node, agrees.
+	if got := extractReExports(removeComments(source)); len(got) != 5 {
+		t.Errorf("extractReExports = %q, want 5 specifiers", got)
+	}
+}
+
+// A lazily loaded chunk is a dependency wherever the import() appears; a
+// computed specifier is not a module name.
+func TestJSParser_DynamicImports(t *testing.T) {
+	source := "import { a } from './a';\n" +
+		"const Page = lazy(() => import('./pages/Home'));\n" +
+		"async function load(name) {\n" +
+		"\tconst cfg = await import(\"./config.json\", { with: { type: 'json' } });\n" +
+		"\tconst chunk = await import(`./chunks/static`);\n" +
+		"\tawait import(`./chunks/${name}`);\n" +
+		"\tawait import(name);\n" +
+		"\treturn loader.import('not-a-module');\n" +
+		"}\n"
+	want := []string{"./a", "./chunks/static", "./config.json", "./pages/Home"}
+	for _, ext := range []string{".js", ".ts"} {
+		got, _ := NewJSParser().ParseExt(source, ext)
+		if !reflect.DeepEqual(got.Imports, want) {
+			t.Errorf("%s Imports = %q, want %q", ext, got.Imports, want)
+		}
+		ast, _ := NewTreeSitterParser().ParseExt(source, ext)
+		if !reflect.DeepEqual(ast.Imports, want) {
+			t.Errorf("%s TreeSitterParser Imports = %q, want %q", ext, ast.Imports, want)
+		}
+	}
+	if got := extractImports(source); !slices.Contains(got, "./pages/Home") || slices.Contains(got, "not-a-module") {
+		t.Errorf("regex fallback Imports = %q", got)
+	}
+}
+
+func TestJSParser_CommonJSExports(t *testing.T) {
+	source := "const impl = require('./impl');\n" +
+		"exports.parse = function (s) { return impl(s) };\n" +
+		"module.exports.format = (v) => String(v);\n" +
+		"exports['to-json'] = JSON.stringify;\n" +
+		"module.exports = { parse, serialize: impl, 'kebab-key': 1, check() {}, ...extras, [computed]: 2 };\n" +
+		"if (exports.parse === undefined) {}\n" +
+		"config.exports.notMine = 1;\n"
+	want := []string{"check", "format", "kebab-key", "parse", "serialize", "to-json"}
+	for _, ext := range []string{".js", ".cjs", ".ts"} {
+		got, _ := NewJSParser().ParseExt(source, ext)
+		if !reflect.DeepEqual(got.Exports, want) {
+			t.Errorf("%s Exports = %q, want %q", ext, got.Exports, want)
+		}
+		ast, _ := NewTreeSitterParser().ParseExt(source, ext)
+		if !reflect.DeepEqual(ast.Exports, want) {
+			t.Errorf("%s TreeSitterParser Exports = %q, want %q", ext, ast.Exports, want)
+		}
+	}
+	regex := extractExports(source)
+	slices.Sort(regex)
+	if regex = deduplicate(regex); !reflect.DeepEqual(regex, want) {
+		t.Errorf("regex fallback Exports = %q, want %q", regex, want)
+	}
+
+	// A whole-module export is its default export, named as one would be.
+	for src, want := range map[string][]string{
+		"class Client {}\nmodule.exports = Client;\n": {"Client"},
+		"module.exports = function createApp() {};\n": {"createApp"},
+		"module.exports = class Store {};\n":          {"Store"},
+		"module.exports = require('./lib');\n":        nil,
+		"module.exports = exports = { run };\n":       {"run"},
+	} {
+		got, _ := NewJSParser().ParseExt(src, ".js")
+		if len(got.Exports) != len(want) || len(want) > 0 && !reflect.DeepEqual(got.Exports, want) {
+			t.Errorf("Exports(%q) = %q, want %q", src, got.Exports, want)
+		}
+		regex := extractCJSExports(src)
+		if len(regex) != len(want) || len(want) > 0 && !reflect.DeepEqual(regex, want) {
+			t.Errorf("regex fallback Exports(%q) = %q, want %q", src, regex, want)
+		}
+	}
+}
+
+// An anonymous default export is recorded under the name importers use for
+// it, `default`: as an export, and as the function or class it declares.
+func TestJSParser_AnonymousDefaultExports(t *testing.T) {
+	cases := []struct {
+		source         string
+		funcs, classes []string
+	}{
+		{"export default function () { return 1 }\n", []string{"default"}, nil},
+		{"export default async function* () {}\n", []string{"default"}, nil},
+		{"export default (req, res) => res.end();\n", []string{"default"}, nil},
+		{"export default class extends Base {\n\trender() {}\n}\n", []string{"default.render"}, []string{"default"}},
+		{"export default connect(mapState)(App);\n", nil, nil},
+		{"export default { name: 'x' };\n", nil, nil},
+	}
+	for _, tc := range cases {
+		for _, ext := range []string{".js", ".ts"} {
+			got, _ := NewJSParser().ParseExt(tc.source, ext)
+			if !reflect.DeepEqual(got.Exports, []string{"default"}) {
+				t.Errorf("%s Exports(%q) = %q, want [default]", ext, tc.source, got.Exports)
+			}
+			if !equalStringSlices(got.Functions, tc.funcs) || !equalStringSlices(got.Classes, tc.classes) {
+				t.Errorf("%s %q: Functions = %q, Classes = %q, want %q, %q", ext, tc.source, got.Functions, got.Classes, tc.funcs, tc.classes)
+			}
+			for _, name := range tc.funcs {
+				if got.SymbolLines["function:"+name] == 0 || got.SymbolHashes["function:"+name] == "" {
+					t.Errorf("%s %q: function:%s has no line or hash", ext, tc.source, name)
+				}
+			}
+			ast, _ := NewTreeSitterParser().ParseExt(tc.source, ext)
+			if !reflect.DeepEqual(ast, got) {
+				t.Errorf("%s %q: TreeSitterParser disagrees:\n got %+v\nwant %+v", ext, tc.source, ast, got)
+			}
+		}
+		if regex := extractExports(tc.source); !reflect.DeepEqual(regex, []string{"default"}) {
+			t.Errorf("regex fallback Exports(%q) = %q, want [default]", tc.source, regex)
+		}
+	}
+	// A named default keeps its name, and `default` is not added beside it.
+	for _, src := range []string{"export default function* gen() {}\n", "export default interface Props {}\n", "export default App;\n"} {
+		if regex := extractExports(src); len(regex) != 1 || regex[0] == "default" {
+			t.Errorf("regex fallback Exports(%q) = %q, want the declared name", src, regex)
+		}
+	}
+}
+
+// A // or /* inside a string or template is text; a comment inside a
+// template's ${…} substitution is still a comment.
+func TestRemoveComments_LiteralAware(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{`const url = "http://x.io"; // host`, `const url = "http://x.io"; `},
+		{`const glob = 'src/**/*.ts'; /* c */ f()`, `const glob = 'src/**/*.ts';  f()`},
+		{"const doc = `\n// not a comment\n`; // one\n", "const doc = `\n// not a comment\n`; \n"},
+		{"const s = `${a /* c */ + `//${b}`}`;", "const s = `${a  + `//${b}`}`;"},
+		{"const o = { k: `${ {x: 1}.x }// text` }; // c", "const o = { k: `${ {x: 1}.x }// text` }; "},
+		{`const q = 'it\'s // here'; // c`, `const q = 'it\'s // here'; `},
+		{"const bad = 'open\nimport x from './x' // c\n", "const bad = 'open\nimport x from './x' \n"},
+		{"a /* one\ntwo */ b", "a  b"},
+	}
+	for _, c := range cases {
+		if got := removeComments(c.in); got != c.want {
+			t.Errorf("removeComments(%q)\n got %q\nwant %q", c.in, got, c.want)
+		}
+	}
+	if got, want := maskCommentsLineFaithful("a /* one\ntwo */ b // c\n"), "a \n b \n"; got != want {
+		t.Errorf("maskCommentsLineFaithful = %q, want %q", got, want)
+	}
+
+	// The regex fallback sees what the grammar does: the string 'src/*' does
+	// not open a comment that would swallow the import after it.
+	got, _ := NewJSParser().ParseExt("import cfg from 'https://cdn.example.com/cfg.js';\nconst from = 'src/*';\nimport x from './x';\nconst to = '*/';\nconst f = (a: number): number => a;\n", ".ts")
+	if want := []string{"./x", "https://cdn.example.com/cfg.js"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+}
+
+// The JSDoc comment directly above a declaration — past `export`, a `const`,
+// or a method's decorators — documents it; a plain or detached comment does
+// not.
+func TestJSParser_Docs(t *testing.T) {
+	source := `/** Greets a user. Then more. */
+export function greet(name: string) {}
+
+/**
+ * Holds the state
+ * of the app.
+ * @see Store
+ */
+@sealed
+export class Store {
+	/** Reads it. */
+	@memo
+	get(): number { return 1 }
+	/** Writes it! */
+	set() {}
+}
+
+/** Adds two numbers. */
+export const add = (a, b) => a + b;
+
+/** A license header. */
+
+function detached() {}
+// Not JSDoc.
+function plain() {}
+/** @deprecated */
+function tagged() {}
+`
+	want := map[string]string{
+		"class:Store":        "Holds the state of the app.",
+		"function:Store.get": "Reads it.",
+		"function:Store.set": "Writes it!",
+		"function:add":       "Adds two numbers.",
+		"function:greet":     "Greets a user.",
+	}
+	got, _ := NewJSParser().ParseExt(source, ".ts")
+	if !reflect.DeepEqual(got.Docs, want) {
+		t.Errorf("Docs:\n got %v\nwant %v", got.Docs, want)
+	}
+	if ast, _ := NewTreeSitterParser().ParseExt(source, ".ts"); !reflect.DeepEqual(ast.Docs, want) {
+		t.Errorf("TreeSitterParser Docs:\n got %v\nwant %v", ast.Docs, want)
+	}
+}
+
+func TestJSParser_FunctionDetails(t *testing.T) {
+	source := `export function load(path: string, opts?: Options, retries = 3, ...rest: string[]): Promise<Config> {}
+export class Repo {
+	constructor(private db: Db, { strict }: Flags) {}
+	find<T>(this: Repo, [id]: T[]): T | undefined { return undefined }
+	get size(): number { return 0 }
+	set size(n: number) {}
+}
+export const double = (x: number): number => x * 2;
+export const id = x => x;
+`
+	got, err := NewJSParser().ParseExt(source, ".ts")
+	if err != nil {
+		t.Fatalf("ParseExt: %v", err)
+	}
+	want := []FunctionDetail{
+		{Name: "Repo.constructor", Params: []string{"db", "{ strict }"}},
+		{Name: "Repo.find", Params: []string{"[id]"}, Returns: "T | undefined"},
+		{Name: "Repo.size", Returns: "number"},
+		{Name: "double", Params: []string{"x"}, Returns: "number", Arrow: true},
+		{Name: "id", Params: []string{"x"}, Arrow: true},
+		{Name: "load", Params: []string{"path", "opts?", "retries?", "...rest"}, Returns: "Promise<Config>"},
+	}
+	if !reflect.DeepEqual(got.FunctionDetails, want) {
+		t.Errorf("FunctionDetails:\n got %+v\nwant %+v", got.FunctionDetails, want)
+	}
+
+	js, _ := NewJSParser().ParseExt("function f(a, b = 2, { c }, ...rest) {}\nconst g = function* () {};\n", ".js")
+	if want := []FunctionDetail{{Name: "f", Params: []string{"a", "b?", "{ c }", "...rest"}}, {Name: "g", Generator: true}}; !reflect.DeepEqual(js.FunctionDetails, want) {
+		t.Errorf("JS FunctionDetails = %+v, want %+v", js.FunctionDetails, want)
+	}
+}
+
+// Columns are 1-based bytes and, like lines, outside every hash: re-indenting
+// a method moves it without modifying it.
+func TestJSParser_SymbolColumns(t *testing.T) {
+	source := "export function top() {}\nclass Box {\n\topen() { return 1 }\n}\n"
+	got, _ := NewJSParser().ParseExt(source, ".js")
+	want := map[string]int{"function:top": 8, "class:Box": 1, "function:Box.open": 2}
+	for key, col := range want {
+		if got.SymbolColumns[key] != col {
+			t.Errorf("%s column = %d, want %d", key, got.SymbolColumns[key], col)
+		}
+	}
+	moved, _ := NewJSParser().ParseExt(strings.Replace(source, "\topen", "    open", 1), ".js")
+	if moved.SymbolColumns["function:Box.open"] != 5 {
+		t.Errorf("re-indented column = %d, want 5", moved.SymbolColumns["function:Box.open"])
+	}
+	if moved.SymbolHashes["function:Box.open"] != got.SymbolHashes["function:Box.open"] {
+		t.Error("re-indenting a method changed its hash")
+	}
+}
+
+func TestJSParser_FunctionFlags(t *testing.T) {
+	source := `export async function load() {}
+function* ids() {}
+async function* pages() {}
+class Feed {
+	@Throttle() public async refresh() {}
+	*[Symbol.iterator]() {}
+	async *items() {}
+	async() {}
+}
+const fetchAll = async (urls: string[]) => urls;
+const next = async x => x;
+const stream = async function* () {};
+`
+	got, err := NewJSParser().ParseExt(source, ".ts")
+	if err != nil {
+		t.Fatalf("ParseExt: %v", err)
+	}
+	want := map[string][3]bool{ // async, generator, arrow
+		"load": {true, false, false}, "ids": {false, true, false}, "pages": {true, true, false},
+		"Feed.refresh": {true, false, false}, "Feed.items": {true, true, false}, "Feed.async": {},
+		"fetchAll": {true, false, true}, "next": {true, false, true}, "stream": {true, true, false},
+	}
+	for _, d := range got.FunctionDetails {
+		w, ok := want[d.Name]
+		if !ok {
+			continue
+		}
+		delete(want, d.Name)
+		if flags := [3]bool{d.Async, d.Generator, d.Arrow}; flags != w {
+			t.Errorf("%s async/generator/arrow = %v, want %v", d.Name, flags, w)
+		}
+	}
+	for name := range want {
+		t.Errorf("no FunctionDetail for %s", name)
+	}
+}
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	ts "github.com/odvcencio/gotreesitter"
+)
+
+// TreeSitterParser is the AST-only JS/TS backend: the same FileStructure as
+// JSParser, from the tree-sitter AST alone. JSParser unions its AST results
+// with line-oriented regex extraction wherever the reduced grammar leaves an
+// ERROR node, and always for CommonJS require(...) calls, so a typed arrow
+// const the grammar cannot parse is still found — at the price of reading
+// code-shaped text (a `require('x')` or `function f()` inside a template
+// literal) as code. TreeSitterParser takes the other side of that trade:
+// every symbol it reports is a node of the tree, requires and dynamic imports
+// included (a call_expression whose callee is the bare identifier `require`
+// or the `import` keyword and whose first argument is a string literal), and
+// what the grammar could not parse is missing rather than guessed at.
+//
+// It is selected with ir.GeneratorConfig.ParserBackend. On a file the grammar
+// parses cleanly the two backends agree; they differ only on error recovery
+// and on code-shaped literals. In a build without the JS/TS grammars it yields
+// no symbols.
+type TreeSitterParser struct{}
+
+// NewTreeSitterParser creates a new AST-only JS/TS parser.
+func NewTreeSitterParser() *TreeSitterParser { return &TreeSitterParser{} }
+
+// SupportsExtension returns true for the extensions JSParser supports.
+func (p *TreeSitterParser) SupportsExtension(ext string) bool {
+	return (&JSParser{}).SupportsExtension(ext)
+}
+
+// Parse assumes JavaScript, as JSParser.Parse does.
+func (p *TreeSitterParser) Parse(source string) (FileStructure, error) {
+	return p.ParseExt(source, "")
+}
+
+// ParseExt parses source with the grammar ext selects (see ExtAwareParser).
+func (p *TreeSitterParser) ParseExt(source, ext string) (FileStructure, error) {
+	// Normalize line endings so spans/hashes are independent of CRLF vs LF.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	ext = jsGrammarExt(ext)
+
+	var (
+		functions, classes, imports, exports, wildcardReexports []string
+		reexports                                               []string
+		enums                                                   []Enum
+		namespaces                                              []Namespace
+		variables                                               []Variable
+		importDetails                                           []ImportDetail
+		typeImports                                             []string
+		details                                                 []ClassDetail
+		funcDetails                                             []FunctionDetail
+		decorated                                               []Decorated
+		hashes                                                  map[string]string
+		lines, cols                                             map[string]int
+		docs                                                    map[string]string
+	)
+	var diag diagnostics
+	if lang := jsLanguageFor(ext); lang != nil {
+		syms, _ := jsSymbolsFromAST(source, lang, &diag)
+		functions, classes, enums, details, decorated, hashes, lines = syms.functions, syms.classes, syms.enums, syms.classDetails, syms.decorated, syms.hashes, syms.lines
+		docs, funcDetails, cols, namespaces, variables = syms.docs, syms.functionDetails, syms.cols, syms.namespaces, syms.variables
+		imports, exports, wildcardReexports, reexports, importDetails, typeImports, _ = jsImportsExportsFromAST(source, lang, &diag)
+		calls := jsCallImportsFromAST(source, lang, &diag)
+		imports = append(imports, calls...)
+		typeImports = withoutModules(typeImports, calls)
+	} else {
+		diag.noGrammar(jsGrammarName(ext))
+	}
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+	sort.Strings(wildcardReexports)
+	sort.Strings(reexports)
+
+	return FileStructure{
+		Imports:           deduplicate(imports),
+		Functions:         deduplicate(functions),
+		Classes:           deduplicate(classes),
+		Exports:           deduplicate(exports),
+		WildcardReexports: deduplicate(wildcardReexports),
+		ReExports:         deduplicate(reexports),
+		Enums:             sortEnums(enums),
+		Namespaces:        sortNamespaces(namespaces),
+		Variables:         sortVariables(variables),
+		ImportDetails:     sortImportDetails(importDetails),
+		TypeImports:       typeImports,
+		ClassDetails:      sortClassDetails(details),
+		FunctionDetails:   sortFunctionDetails(funcDetails),
+		Decorators:        sortDecorated(decorated),
+		SymbolHashes:      hashes,
+		SymbolLines:       lines,
+		SymbolColumns:     cols,
+		Docs:              docs,
+		Diagnostics:       diag,
+	}, nil
+}
+
+// jsCallImportsFromAST returns the module of every `require('mod')` and
+// dynamic `import('mod')` call in the file, at any depth: a require inside a
+// function body, or a lazily loaded chunk, is as much a dependency as an
+// import at the top. A template literal counts only without a substitution.
+func jsCallImportsFromAST(source string, lang *ts.Language, diag *diagnostics) (requires []string) {
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no requires rather than taking down the indexer.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("JS/TS require scan panicked (%v); requires for this file disabled", r)
+			requires = nil
+		}
+	}()
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		return nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return nil
+	}
+	var walk func(n *ts.Node, depth int)
+	walk = func(n *ts.Node, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		if n.Type(lang) == "call_expression" {
+			callee := n.ChildByFieldName("function", lang)
+			args := n.ChildByFieldName("arguments", lang)
+			if callee != nil && args != nil {
+				isRequire := callee.Type(lang) == "identifier" && callee.Text(src) == "require" && args.NamedChildCount() == 1
+				isImport := callee.Type(lang) == "import" && args.NamedChildCount() >= 1
+				if isRequire || isImport {
+					if mod := jsLiteralSpecifier(args.NamedChild(0), lang, src); mod != "" {
+						requires = append(requires, mod)
+					}
+				}
+			}
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			walk(n.NamedChild(i), depth+1)
+		}
+	}
+	walk(tree.RootNode(), 0)
+	return requires
+}
+
+// jsLiteralSpecifier returns a string literal's or substitution-free template
+// literal's contents, or "" for any other expression.
+func jsLiteralSpecifier(arg *ts.Node, lang *ts.Language, src []byte) string {
+	switch arg.Type(lang) {
+	case "string":
+		return strings.Trim(arg.Text(src), `'"`)
+	case "template_string":
+		if text := arg.Text(src); !strings.Contains(text, "${") {
+			return strings.Trim(text, "`")
+		}
+	}
+	return ""
+}
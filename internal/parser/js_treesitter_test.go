@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const treeSitterJSSample = `import React from 'react';
+import { useState } from 'react';
+const axios = require('axios');
+
+function greet(name) {
+	return "Hello " + name;
+}
+
+async function fetchData() {
+	const lodash = require("lodash");
+	return await fetch('/api/data');
+}
+
+const add = (a, b) => a + b;
+
+class User {
+	constructor(name) {
+		this.name = name;
+	}
+}
+
+export { greet };
+export * from './models';
+export const API_URL = "http://example.com";
+export default User;
+`
+
+const treeSitterTSSample = `import type { Config } from './config';
+
+export interface Options {
+	verbose: boolean;
+}
+
+export abstract class Service {
+	abstract run(): void;
+}
+
+export function load(path: string): Config {
+	return require('./' + path);
+}
+`
+
+func TestTreeSitterParser_Extension(t *testing.T) {
+	p := NewTreeSitterParser()
+	for _, ext := range []string{".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".mts", ".cts"} {
+		if p.SupportsExtension(ext) != NewJSParser().SupportsExtension(ext) {
+			t.Errorf("SupportsExtension(%q) disagrees with JSParser", ext)
+		}
+	}
+}
+
+// On sources the grammar parses cleanly the two backends are interchangeable.
+func TestTreeSitterParser_MatchesJSParserOnCleanSources(t *testing.T) {
+	for ext, src := range map[string]string{".js": treeSitterJSSample, ".ts": treeSitterTSSample} {
+		want, _ := NewJSParser().ParseExt(src, ext)
+		got, err := NewTreeSitterParser().ParseExt(src, ext)
+		if err != nil {
+			t.Fatalf("ParseExt(%s): %v", ext, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", ext, got, want)
+		}
+	}
+}
+
+// A require nested in a function body is found from the tree; a computed
+// require path is not a module name.
+func TestTreeSitterParser_Requires(t *testing.T) {
+	got, _ := NewTreeSitterParser().ParseExt(treeSitterJSSample, ".js")
+	if want := []string{"axios", "lodash", "react"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	typed, _ := NewTreeSitterParser().ParseExt(treeSitterTSSample, ".ts")
+	if want := []string{"./config"}; !reflect.DeepEqual(typed.Imports, want) {
+		t.Errorf("TS Imports = %q, want %q", typed.Imports, want)
+	}
+}
+
+// Code-shaped text in a template literal is text: the regex fallback reads
+// it as a require, the AST backend does not.
+func TestTreeSitterParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "const doc = `\nconst x = require('fake-dep');\nfunction fakeFn() {}\n`;\n" +
+		"// const y = require('commented-dep');\n" +
+		"function real() { return doc; }\n"
+	got, _ := NewTreeSitterParser().ParseExt(src, ".js")
+	if want := []string{"real"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+	if len(got.Imports) != 0 {
+		t.Errorf("Imports = %q, want none", got.Imports)
+	}
+	if regex, _ := NewJSParser().ParseExt(src, ".js"); !reflect.DeepEqual(regex.Imports, []string{"fake-dep"}) {
+		t.Errorf("JSParser Imports = %q; the contrast this test documents has changed", regex.Imports)
+	}
+}
+
+// Output is identical, and in the same order, however often and however
+// concurrently a source is parsed.
+func TestTreeSitterParser_Determinism(t *testing.T) {
+	p := NewTreeSitterParser()
+	for ext, src := range map[string]string{".js": treeSitterJSSample, ".ts": treeSitterTSSample} {
+		first, _ := p.ParseExt(src, ext)
+		assertParserInvariants(t, first)
+		results := make([]FileStructure, 32)
+		var wg sync.WaitGroup
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = p.ParseExt(src, ext)
+			}(i)
+		}
+		wg.Wait()
+		for i, r := range results {
+			if !reflect.DeepEqual(r, first) {
+				t.Fatalf("%s: parse %d differs from the first:\n got %+v\nwant %+v", ext, i, r, first)
+			}
+		}
+	}
+}
+
+func TestTreeSitterParser_CRLFParity(t *testing.T) {
+	for ext, src := range map[string]string{".js": treeSitterJSSample, ".ts": treeSitterTSSample} {
+		lf, _ := NewTreeSitterParser().ParseExt(src, ext)
+		crlf, _ := NewTreeSitterParser().ParseExt(strings.ReplaceAll(src, "\n", "\r\n"), ext)
+		if !reflect.DeepEqual(lf, crlf) {
+			t.Errorf("%s: CRLF source parsed differently from LF", ext)
+		}
+	}
+}
+
+func TestTreeSitterParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "function", "require(", "require('a', 'b')", "const f = (x: number): string => `${x}`;", strings.Repeat("(", 5000), treeSitterJSSample[:150]} {
+		for _, ext := range []string{".js", ".ts", ".tsx", ""} {
+			got, err := NewTreeSitterParser().ParseExt(src, ext)
+			if err != nil {
+				t.Errorf("ParseExt(%q, %q) errored: %v", src, ext, err)
+			}
+			assertParserInvariants(t, got)
+			assertSpanKeysNameRealSymbols(t, got)
+		}
+	}
+}
+
+// FuzzTreeSitterParser asserts the AST-only JS/TS parser never panics on
+// arbitrary input and keeps the shared invariants.
+// Run: go test -run=x -fuzz=FuzzTreeSitterParser ./internal/parser
+func FuzzTreeSitterParser(f *testing.F) {
+	seeds := []string{
+		treeSitterJSSample, treeSitterTSSample,
+		"const a = require('a');", "export * from 'x';", "class A { m() {} }",
+		"", "require(", "`${",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewTreeSitterParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		for _, ext := range []string{".js", ".ts"} {
+			fs, err := p.ParseExt(src, ext) // must never panic
+			if err != nil {
+				continue
+			}
+			assertParserInvariants(t, fs)
+			assertSpanKeysNameRealSymbols(t, fs)
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package parser
+
+import "strings"
+
+// maskJSXText returns src with the text children of every JSX element blanked
+// to spaces, preserving length and newlines so offsets and line numbers still
+// map 1:1. The regex fallback reads JSX text as code otherwise: prose such as
+// `<p>Don't call function helper()</p>` yields a function "helper", its
+// apostrophe opens a "string" that swallows the lines after it, and a URL's
+// `//` cuts the rest of its line as a comment. Tags, attributes, and `{…}`
+// expressions are kept — those are code.
+//
+// A `<` starts an element only where an expression can start (after `(`,
+// `=`, `return`, `=>`, and the like) and only when a tag name or `>` follows,
+// so `a < b` is a comparison; a `<T,>` or `<T extends U>` type parameter list
+// is not an element. An element that never closes is left as it was. Not for
+// .ts sources, whose `<T>expr` type assertions read as elements.
+func maskJSXText(src string) string {
+	m := jsxMasker{src: src}
+	m.code(0, false)
+	out := []byte(src)
+	for _, i := range m.blank {
+		out[i] = ' '
+	}
+	return string(out)
+}
+
+// jsxMasker scans JS source for elements. blank collects the offsets of the
+// text bytes to mask; an element that turns out not to close drops its own.
+// exhausted is set once a scan runs off the end of the source: every later
+// `<` would too, so no more elements are tried, keeping the scan linear.
+type jsxMasker struct {
+	src       string
+	blank     []int
+	exhausted bool
+}
+
+// code scans JS from i, masking the elements it meets, and returns the offset
+// it stopped at: just past the `}` closing the expression it was called for
+// when inBrace, else the end of the source.
+func (m *jsxMasker) code(i int, inBrace bool) int {
+	src := m.src
+	depth := 0
+	prev := byte('(') // the last significant byte: a file starts where an expression may
+	prevWord := ""
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			i += 2
+			for i < len(src) && !(src[i] == '*' && i+1 < len(src) && src[i+1] == '/') {
+				i++
+			}
+			i += 2
+			continue
+		case c == '\'' || c == '"':
+			i = skipQuoted(src, i)
+		case c == '`':
+			i = m.template(i)
+		case c == '{':
+			depth++
+			i++
+		case c == '}':
+			i++
+			if depth--; depth < 0 && inBrace {
+				return i
+			}
+		case c == '<' && !m.exhausted && jsxCanStart(prev, prevWord) && jsxTagFollows(src, i+1):
+			mark := len(m.blank)
+			if end, ok := m.element(i); ok {
+				i = end
+				prev, prevWord = ')', ""
+				continue
+			}
+			m.blank = m.blank[:mark]
+			if m.exhausted {
+				return len(src)
+			}
+			i++
+		case isJSXIdentByte(c):
+			j := i
+			for j < len(src) && isJSXIdentByte(src[j]) {
+				j++
+			}
+			prev, prevWord = c, src[i:j]
+			i = j
+			continue
+		default:
+			i++
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			prev, prevWord = c, ""
+		}
+	}
+	return i
+}
+
+// element scans the element whose `<` is at i, recording its text for
+// masking, and returns the offset just past it. ok is false for a type
+// parameter list or an element that never closes; the caller then drops what
+// was recorded.
+func (m *jsxMasker) element(i int) (end int, ok bool) {
+	i, selfClosing, ok := m.openTag(i)
+	if !ok || selfClosing {
+		return i, ok
+	}
+	src := m.src
+	for i < len(src) {
+		switch c := src[i]; {
+		case c == '<' && i+1 < len(src) && src[i+1] == '/':
+			close := strings.IndexByte(src[i:], '>')
+			if close < 0 {
+				m.exhausted = true
+				return 0, false
+			}
+			return i + close + 1, true
+		case c == '<':
+			if i, ok = m.element(i); !ok {
+				return 0, false
+			}
+		case c == '{':
+			i = m.code(i+1, true)
+		default:
+			if c != '\n' {
+				m.blank = append(m.blank, i)
+			}
+			i++
+		}
+	}
+	m.exhausted = true
+	return 0, false
+}
+
+// openTag scans the opening tag whose `<` is at i — its name and attributes,
+// whose `{…}` values are code — and returns the offset just past it.
+func (m *jsxMasker) openTag(i int) (end int, selfClosing, ok bool) {
+	src := m.src
+	i++ // the <
+	j := i
+	for j < len(src) && (isJSXIdentByte(src[j]) || src[j] == '.' || src[j] == ':' || src[j] == '-') {
+		j++
+	}
+	if j > i {
+		k := j
+		for k < len(src) && (src[k] == ' ' || src[k] == '\t') {
+			k++
+		}
+		if k < len(src) && src[k] == ',' || hasWordAt(src, k, "extends") {
+			return 0, false, false // a type parameter list
+		}
+	}
+	for i = j; i < len(src); {
+		switch c := src[i]; {
+		case c == '"' || c == '\'':
+			i = skipQuoted(src, i)
+		case c == '{':
+			i = m.code(i+1, true)
+		case c == '/' && i+1 < len(src) && src[i+1] == '>':
+			return i + 2, true, true
+		case c == '>':
+			return i + 1, false, true
+		default:
+			i++
+		}
+	}
+	m.exhausted = true
+	return 0, false, false
+}
+
+// template skips the template literal whose backtick is at i, scanning its
+// `${…}` substitutions as code, and returns the offset just past it.
+func (m *jsxMasker) template(i int) int {
+	src := m.src
+	for i++; i < len(src); {
+		switch {
+		case src[i] == '\\':
+			i += 2
+		case src[i] == '`':
+			return i + 1
+		case src[i] == '$' && i+1 < len(src) && src[i+1] == '{':
+			i = m.code(i+2, true)
+		default:
+			i++
+		}
+	}
+	return len(src)
+}
+
+// skipQuoted returns the offset just past the '…' or "…" string at i, or the
+// end of its line for one left open.
+func skipQuoted(src string, i int) int {
+	q := src[i]
+	for i++; i < len(src) && src[i] != '\n'; i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case q:
+			return i + 1
+		}
+	}
+	return i
+}
+
+// jsxCanStart reports whether an expression may start after prev, the last
+// significant byte, which ended the word prevWord if it was an identifier.
+func jsxCanStart(prev byte, prevWord string) bool {
+	switch prevWord {
+	case "":
+	case "return", "yield", "default", "await":
+		return true
+	default:
+		return false
+	}
+	switch prev {
+	case '(', '[', '{', ',', '=', ':', '?', '!', '&', '|', ';', '>', '}':
+		return true
+	}
+	return false
+}
+
+// jsxTagFollows reports whether a tag name or a fragment's `>` starts at i.
+func jsxTagFollows(src string, i int) bool {
+	if i >= len(src) {
+		return false
+	}
+	c := src[i]
+	return c == '>' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c == '_' || c == '$'
+}
+
+func isJSXIdentByte(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '$'
+}
+
+// hasWordAt reports whether word starts at i and ends at a word boundary.
+func hasWordAt(src string, i int, word string) bool {
+	if i+len(word) > len(src) || src[i:i+len(word)] != word {
+		return false
+	}
+	return i+len(word) == len(src) || !isJSXIdentByte(src[i+len(word)])
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMaskJSXText(t *testing.T) {
+	for _, c := range []struct{ name, src, want string }{
+		{"text blanked, tag and expression kept",
+			`const a = <p className="x">Don't {n} go</p>;`,
+			`const a = <p className="x">      {n}   </p>;`},
+		{"nested elements and fragments",
+			"return (<><b>one</b> two</>)",
+			"return (<><b>   </b>    </>)"},
+		{"element inside an expression",
+			"x = <ul>{xs.map(i => <li>it's {i}</li>)}</ul>",
+			"x = <ul>{xs.map(i => <li>     {i}</li>)}</ul>"},
+		{"self-closing", "f(<br/>, 'a<b')", "f(<br/>, 'a<b')"},
+		{"comparison", "if (a < b && c<d) {}", "if (a < b && c<d) {}"},
+		{"type parameter lists", "const f = <T,>(x: T) => x; const g = <U extends V>() => 1", "const f = <T,>(x: T) => x; const g = <U extends V>() => 1"},
+		{"unclosed element left alone", "const a = <div>Don't", "const a = <div>Don't"},
+		{"markup in strings and comments", "s = '<p>x</p>' // (<p>y</p>)", "s = '<p>x</p>' // (<p>y</p>)"},
+		{"newlines kept", "(<p>\nline\n</p>)", "(<p>\n    \n</p>)"},
+	} {
+		if got := maskJSXText(c.src); got != c.want {
+			t.Errorf("%s:\n got %q\nwant %q", c.name, got, c.want)
+		}
+	}
+}
+
+// jsxProseSample has JSX prose that reads as code to a regex — a `function`
+// call, an apostrophe, a URL — and a malformed tail that sends the parser to
+// its regex fallback.
+const jsxProseSample = `import React from 'react'
+
+export function App() {
+  return (
+    <div className="app">
+      <p>Don't call function helper() from here, see http://example.com/docs</p>
+    </div>
+  )
+}
+
+export const Row = ({ item }) => {
+  return <li>{item.name}</li>
+}
+
+class Broken extends {
+`
+
+func TestJSParser_JSXProseIsNotCode(t *testing.T) {
+	for _, ext := range []string{".jsx", ".tsx", ".js"} {
+		got, err := NewJSParser().ParseExt(jsxProseSample, ext)
+		if err != nil {
+			t.Fatalf("%s: %v", ext, err)
+		}
+		if want := []string{"App", "Row"}; !reflect.DeepEqual(got.Functions, want) {
+			t.Errorf("%s Functions = %q, want %q", ext, got.Functions, want)
+		}
+		if got.SymbolLines["function:Row"] != 11 {
+			t.Errorf("%s function:Row line = %d, want 11", ext, got.SymbolLines["function:Row"])
+		}
+	}
+}
+
+// A typed arrow after JSX prose with an apostrophe is still body-hashed by the
+// fallback: the apostrophe no longer opens a string that hides its braces.
+func TestJSParser_JSXProseKeepsArrowHashes(t *testing.T) {
+	src := "export const Note = () => <p>Don't panic</p>\n" +
+		"export const total = (xs: number[]): number => {\n  return xs.length\n}\n" +
+		"class Broken extends {\n"
+	got, _ := NewJSParser().ParseExt(src, ".tsx")
+	h := got.SymbolHashes["function:total"]
+	if h == "" {
+		t.Fatalf("function:total has no hash; hashes %v", got.SymbolHashes)
+	}
+	after, _ := NewJSParser().ParseExt(strings.Replace(src, "xs.length", "xs.length + 1", 1), ".tsx")
+	if after.SymbolHashes["function:total"] == h {
+		t.Error("editing the arrow body did not change its hash")
+	}
+}
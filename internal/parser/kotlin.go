@@ -0,0 +1,339 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// KotlinParser implements structural parsing for .kt and .kts files using the
+// vendored pure-Go tree-sitter Kotlin grammar, for the Java parser's reasons
+// plus Kotlin's own: string templates (`"${a.b { it }}"`) and raw strings nest
+// braces and quotes inside literals, and semicolons are optional, so neither
+// a brace count nor a line scan finds where a declaration ends.
+//
+// Symbol routing:
+//   - `class` (incl. `interface`, `enum class`, `data class`, `annotation
+//     class`), `object`, and `typealias` → Classes, nested-qualified
+//     ("Reader.Inner").
+//   - `fun` → Functions, qualified by the enclosing type ("Reader.fetch"). An
+//     extension function is qualified by its receiver type ("String.ext"),
+//     which is how a caller writes it, as the Go parser does for methods.
+//     Members of a companion object are qualified by the class it belongs to
+//     ("Reader.create"), again as a caller writes them. Constructors are not
+//     listed: a caller writes the class.
+//   - `val` / `var` → Exports when visible (see below), top-level and member
+//     alike, including primary-constructor properties; located, not hashed.
+//   - `import` → Imports, as written minus the keyword: "android.os.Bundle",
+//     "kotlinx.coroutines.*", "com.foo.Bar as Baz".
+//   - The `package` header → Exports ("com.acme.web"): it is what an import
+//     names, and Kotlin does not tie it to the directory the file is in.
+//
+// Visibility follows the Java parser's rule: every type and function is
+// listed, and Exports additionally lists the visible ones. Kotlin is public
+// unless marked, so that is everything not `private`, `protected`, or
+// `internal` (module-wide, which like C#'s `internal` is usually smaller than
+// the repo). Enum entries are as visible as their enum.
+//
+// Function bodies are not descended into, so local functions, lambdas, and
+// object expressions are skipped — and so are the DSL blocks that make up
+// most of a .kts build script, which declare nothing a caller could name.
+type KotlinParser struct{}
+
+// NewKotlinParser creates a new Kotlin parser.
+func NewKotlinParser() *KotlinParser { return &KotlinParser{} }
+
+// SupportsExtension returns true for .kt and .kts files.
+func (p *KotlinParser) SupportsExtension(ext string) bool {
+	return ext == ".kt" || ext == ".kts"
+}
+
+var (
+	kotlinLangOnce sync.Once
+	kotlinLang     *ts.Language
+)
+
+func kotlinLanguage() *ts.Language {
+	kotlinLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("Kotlin", r)
+			}
+		}()
+		kotlinLang = grammars.KotlinLanguage()
+	})
+	return kotlinLang
+}
+
+// Parse extracts structure from Kotlin source via tree-sitter. Best-effort on
+// parse errors: tree-sitter recovers to a partial tree for most mid-edit
+// buffers, and we walk whatever it produced.
+func (p *KotlinParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := kotlinSymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}, nil
+}
+
+func kotlinSymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("Kotlin parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			hashes, lines = nil, nil
+		}
+	}()
+
+	lang := kotlinLanguage()
+	if lang == nil {
+		diag.noGrammar("Kotlin")
+		return imports, functions, classes, exports, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("Kotlin source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil
+	}
+	// As for Ruby: an ERROR root means nothing below it is a declaration, so
+	// say the symbols are missing rather than let them read as absent.
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("Kotlin file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+
+	// recordHash combines on collision: overloads, and extension functions on
+	// one receiver declared under one name.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+	recordLine := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+	addExport := func(full string, line int) {
+		exports = append(exports, full)
+		recordLine("export:"+full, line)
+	}
+	addType := func(full string, span []byte, line int, visible bool) {
+		classes = append(classes, full)
+		recordHash("class:"+full, span)
+		recordLine("class:"+full, line)
+		if visible {
+			exports = append(exports, full)
+		}
+	}
+
+	// walk visits the declarations in n. prefix is the enclosing type's
+	// qualified name; enumPublic is the visibility enum entries inherit.
+	var walk func(n *ts.Node, prefix string, enumPublic bool, depth int)
+	walk = func(n *ts.Node, prefix string, enumPublic bool, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			c := n.NamedChild(i)
+			span := src[c.StartByte():c.EndByte()]
+			line := int(c.StartPoint().Row) + 1
+			visible := kotlinVisible(c, lang, src)
+
+			switch c.Type(lang) {
+			case "import_list":
+				walk(c, prefix, enumPublic, depth+1)
+
+			case "import_header":
+				if path := kotlinImportPath(c, lang, src); path != "" {
+					imports = append(imports, path)
+				}
+
+			case "package_header":
+				if name := strings.Join(strings.Fields(kotlinChildText(c, "identifier", lang, src)), ""); name != "" {
+					addExport(name, line)
+				}
+
+			case "class_declaration", "object_declaration":
+				name := kotlinChildText(c, "type_identifier", lang, src)
+				if name == "" {
+					continue
+				}
+				full := qualify(prefix, name)
+				addType(full, span, line, visible)
+				for j := 0; j < c.NamedChildCount(); j++ {
+					switch b := c.NamedChild(j); b.Type(lang) {
+					case "primary_constructor":
+						// `val`/`var` parameters declare properties.
+						for k := 0; k < b.NamedChildCount(); k++ {
+							p := b.NamedChild(k)
+							if p.Type(lang) == "class_parameter" && kotlinChildText(p, "binding_pattern_kind", lang, src) != "" && kotlinVisible(p, lang, src) {
+								if name := kotlinChildText(p, "simple_identifier", lang, src); name != "" {
+									addExport(qualify(full, name), int(p.StartPoint().Row)+1)
+								}
+							}
+						}
+					case "class_body", "enum_class_body":
+						walk(b, full, visible, depth+1)
+					}
+				}
+
+			case "companion_object":
+				// Members are reached through the class, not the companion.
+				for j := 0; j < c.NamedChildCount(); j++ {
+					if b := c.NamedChild(j); b.Type(lang) == "class_body" {
+						walk(b, prefix, enumPublic, depth+1)
+					}
+				}
+
+			case "type_alias":
+				if name := kotlinChildText(c, "type_identifier", lang, src); name != "" {
+					addType(qualify(prefix, name), span, line, visible)
+				}
+
+			case "function_declaration":
+				name := kotlinChildText(c, "simple_identifier", lang, src)
+				if name == "" {
+					continue
+				}
+				owner := prefix
+				if recv := kotlinReceiver(c, lang, src); recv != "" {
+					owner = recv
+				}
+				full := qualify(owner, name)
+				functions = append(functions, full)
+				recordHash("function:"+full, span)
+				recordLine("function:"+full, line)
+				if visible {
+					exports = append(exports, full)
+				}
+
+			case "property_declaration":
+				if !visible {
+					continue
+				}
+				for j := 0; j < c.NamedChildCount(); j++ {
+					v := c.NamedChild(j)
+					switch v.Type(lang) {
+					case "variable_declaration":
+						if name := kotlinChildText(v, "simple_identifier", lang, src); name != "" {
+							addExport(qualify(prefix, name), line)
+						}
+					case "multi_variable_declaration":
+						for k := 0; k < v.NamedChildCount(); k++ {
+							if name := kotlinChildText(v.NamedChild(k), "simple_identifier", lang, src); name != "" {
+								addExport(qualify(prefix, name), line)
+							}
+						}
+					}
+				}
+
+			case "enum_entry":
+				if name := kotlinChildText(c, "simple_identifier", lang, src); name != "" && enumPublic {
+					addExport(qualify(prefix, name), line)
+				}
+
+			case "ERROR":
+				// A mid-edit buffer's ERROR node can still hold whole members.
+				walk(c, prefix, enumPublic, depth+1)
+			}
+		}
+	}
+	walk(tree.RootNode(), "", false, 0)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, hashes, lines
+}
+
+// kotlinVisible reports whether a declaration is visible outside its module:
+// not marked `private`, `protected`, or `internal`.
+func kotlinVisible(n *ts.Node, lang *ts.Language, src []byte) bool {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		m := n.NamedChild(i)
+		if m.Type(lang) != "modifiers" {
+			continue
+		}
+		for j := 0; j < m.NamedChildCount(); j++ {
+			if v := m.NamedChild(j); v.Type(lang) == "visibility_modifier" {
+				return v.Text(src) == "public"
+			}
+		}
+	}
+	return true
+}
+
+// kotlinChildText returns the text of n's first named child of the given
+// kind; the Kotlin grammar names few fields.
+func kotlinChildText(n *ts.Node, kind string, lang *ts.Language, src []byte) string {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Type(lang) == kind {
+			return c.Text(src)
+		}
+	}
+	return ""
+}
+
+// kotlinReceiver returns an extension function's receiver type without type
+// arguments or nullability ("List" for `List<T>?.f`), or "" for a plain
+// function.
+func kotlinReceiver(fn *ts.Node, lang *ts.Language, src []byte) string {
+	recv := kotlinChildText(fn, "receiver_type", lang, src)
+	if i := strings.IndexAny(recv, "<?"); i >= 0 {
+		recv = recv[:i]
+	}
+	return strings.Join(strings.Fields(recv), "")
+}
+
+// kotlinImportPath returns an import as written minus the keyword, with
+// whitespace collapsed so formatting cannot change it.
+func kotlinImportPath(n *ts.Node, lang *ts.Language, src []byte) string {
+	path := strings.Join(strings.Fields(kotlinChildText(n, "identifier", lang, src)), "")
+	if path == "" {
+		return ""
+	}
+	if kotlinChildText(n, "wildcard_import", lang, src) != "" {
+		path += ".*"
+	}
+	if alias := kotlinChildText(n, "import_alias", lang, src); alias != "" {
+		path += " " + strings.Join(strings.Fields(alias), " ")
+	}
+	return path
+}
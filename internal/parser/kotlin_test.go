@@ -0,0 +1,207 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const kotlinSample = `@file:JvmName("Utils")
+package com.acme.app
+
+import android.os.Bundle
+import kotlinx.coroutines.*
+import com.foo.Bar as Baz
+
+val TOP = 1
+private var hidden = 2
+internal val inner = 3
+const val MAX = 10
+val (a, b) = pair
+
+fun top(x: Int): Int { fun local() {} ; return x }
+private fun priv() = 1
+fun String.ext(): Int = length
+fun <T> List<T>?.orNone(): List<T> = this ?: emptyList()
+suspend fun <T> gen(t: T) {}
+
+@Deprecated("x")
+class Reader<T>(val id: Int, private val y: Int, plain: Int) : Base(), Api {
+    val name: String = "r"
+    private var count = 0
+    constructor(s: String) : this(0, 0, 0)
+    fun fetch(): String = "fun fake() {}"
+    fun fetch(n: Int): String = "${n}"
+    protected fun helper() {}
+    companion object Factory {
+        fun create() = 1
+        const val K = 1
+    }
+    inner class Inner { fun go() {} }
+    init { }
+}
+
+interface Api { fun fetch(): String; val p: Int }
+object Singleton { fun one() {} }
+data class Point(val x: Int, val y: Int)
+enum class Color { RED, GREEN; fun rgb() = 0 }
+private enum class Secret { A }
+sealed interface Shape
+typealias Name = String
+annotation class Ann
+`
+
+func TestKotlinParser_Extension(t *testing.T) {
+	p := NewKotlinParser()
+	for _, ext := range []string{".kt", ".kts"} {
+		if !p.SupportsExtension(ext) {
+			t.Errorf("want %s supported", ext)
+		}
+	}
+	if p.SupportsExtension(".java") || p.SupportsExtension(".ktm") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestKotlinParser_Symbols(t *testing.T) {
+	got, err := NewKotlinParser().Parse(kotlinSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	wantImports := []string{"android.os.Bundle", "com.foo.Bar as Baz", "kotlinx.coroutines.*"}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports:\n got %q\nwant %q", got.Imports, wantImports)
+	}
+	wantClasses := []string{"Ann", "Api", "Color", "Name", "Point", "Reader", "Reader.Inner", "Secret", "Shape", "Singleton"}
+	if !reflect.DeepEqual(got.Classes, wantClasses) {
+		t.Errorf("Classes:\n got %q\nwant %q", got.Classes, wantClasses)
+	}
+	// Extension functions are qualified by their receiver, companion members
+	// by their class; the local function and constructors are not listed.
+	wantFunctions := []string{
+		"Api.fetch", "Color.rgb", "List.orNone", "Reader.Inner.go", "Reader.create",
+		"Reader.fetch", "Reader.helper", "Singleton.one", "String.ext", "gen", "priv", "top",
+	}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+	// The package, past the file annotation, is listed with the exports: it
+	// is what an import names.
+	if line := got.SymbolLines["export:com.acme.app"]; line != 2 {
+		t.Errorf("export:com.acme.app line = %d, want 2", line)
+	}
+}
+
+// Exports leave out private, protected, and internal declarations and the
+// private enum's entries; val/var constructor parameters are properties.
+func TestKotlinParser_Visibility(t *testing.T) {
+	got, _ := NewKotlinParser().Parse(kotlinSample)
+	wantExports := []string{
+		"Ann", "Api", "Api.fetch", "Api.p", "Color", "Color.GREEN", "Color.RED", "Color.rgb",
+		"List.orNone", "MAX", "Name", "Point", "Point.x", "Point.y", "Reader", "Reader.Inner",
+		"Reader.Inner.go", "Reader.K", "Reader.create", "Reader.fetch", "Reader.id", "Reader.name",
+		"Shape", "Singleton", "Singleton.one", "String.ext", "TOP", "a", "b", "com.acme.app", "gen", "top",
+	}
+	if !reflect.DeepEqual(got.Exports, wantExports) {
+		t.Errorf("Exports:\n got %q\nwant %q", got.Exports, wantExports)
+	}
+}
+
+func TestKotlinParser_HashesAndLines(t *testing.T) {
+	got, _ := NewKotlinParser().Parse(kotlinSample)
+	for key, want := range map[string]int{
+		"function:Reader.fetch":  25, // the first overload anchors the line
+		"class:Reader":           20, // annotations are part of the declaration
+		"function:Reader.create": 29,
+		"export:Reader.id":       21,
+		"export:TOP":             8,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(kotlinSample, `"${n}"`, `"${n + 1}"`, 1)
+	after, _ := NewKotlinParser().Parse(edited)
+	if after.SymbolHashes["function:Reader.fetch"] == got.SymbolHashes["function:Reader.fetch"] {
+		t.Error("editing the second overload did not change the symbol hash")
+	}
+	if after.SymbolHashes["function:top"] != got.SymbolHashes["function:top"] {
+		t.Error("unrelated symbol's hash changed")
+	}
+}
+
+func TestKotlinParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "class A {\n" +
+		"  // fun commented() {}\n" +
+		"  /* class Fake { /* nested */ } */\n" +
+		"  val s = \"${listOf(1).map { \"fun interp() {}\" }}\"\n" +
+		"  val r = \"\"\"\n    class Raw { }\n  \"\"\"\n" +
+		"  fun real() {}\n}\n"
+	got, _ := NewKotlinParser().Parse(src)
+	if !reflect.DeepEqual(got.Functions, []string{"A.real"}) || !reflect.DeepEqual(got.Classes, []string{"A"}) {
+		t.Errorf("Functions %q, Classes %q; want only A and A.real", got.Functions, got.Classes)
+	}
+}
+
+// A build script is mostly DSL calls, which declare nothing; what it does
+// declare is found.
+func TestKotlinParser_Script(t *testing.T) {
+	src := "import java.io.File\n" +
+		"plugins { id(\"com.android.application\") }\n" +
+		"val version = \"1\"\n" +
+		"fun helper() {}\n" +
+		"android { compileSdk = 34 }\n"
+	got, _ := NewKotlinParser().Parse(src)
+	if !reflect.DeepEqual(got.Functions, []string{"helper"}) || !reflect.DeepEqual(got.Exports, []string{"helper", "version"}) {
+		t.Errorf("Functions %q, Exports %q", got.Functions, got.Exports)
+	}
+}
+
+func TestKotlinParser_CRLFParity(t *testing.T) {
+	lf, _ := NewKotlinParser().Parse(kotlinSample)
+	crlf, _ := NewKotlinParser().Parse(strings.ReplaceAll(kotlinSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestKotlinParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "class Broken {", "fun \x00\xff", "}}}}"} {
+		got, err := NewKotlinParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzKotlinParser asserts the Kotlin parser never panics on arbitrary input
+// and keeps the invariants the other tree-sitter parsers keep.
+// Run: go test -run=x -fuzz=FuzzKotlinParser ./internal/parser
+func FuzzKotlinParser(f *testing.F) {
+	seeds := []string{
+		kotlinSample,
+		"fun f() {}",
+		"class A { companion object { fun b() {} } }",
+		"fun <T> T.x() = this",
+		"enum class E { X, Y }",
+		"import a.b.*",
+		"val s = \"\"\"\nunterminated",
+		"", "class", "}}}", "private private", "val s = \"${",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewKotlinParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -0,0 +1,408 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// LuaParser implements structural parsing for .lua files using the vendored
+// pure-Go tree-sitter Lua grammar. Long brackets (`[==[ … ]==]`) open strings
+// and comments whose terminator is chosen by the writer, and `end` closes
+// functions, loops, and ifs alike, so neither a masker nor a keyword count
+// finds where a function ends.
+//
+// Symbol routing:
+//   - `function` declarations → Functions, as named: "helper", "M.fetch".
+//     A method `function M:send()` is "M.send": Lua distinguishes the colon
+//     only by the implicit `self`, and the index has one namespace per name,
+//     as the Ruby parser collapses singleton methods. A function value
+//     assigned to a name or a table field (`M.f = function() … end`,
+//     `local t = { f = function() … end }`) is listed the same way.
+//   - `require("mod")` / `require "mod"` → Imports, the literal module name,
+//     wherever the call appears: a require inside a function is still a
+//     dependency. A computed name records nothing.
+//   - Classes stays empty: Lua has tables, not types.
+//
+// Exports are what another chunk can reach. A Lua module is the value its
+// chunk returns, so `return M` exports every function and field assigned
+// into M ("M.fetch", "M.VERSION"), and `return { fetch = fetch }` exports
+// each field of the returned table ("fetch"). Globals are reachable from
+// every chunk of the embedding state, so global functions and variables
+// and the members of global tables are exported too. `local` names are not.
+//
+// Definitions are collected from the chunk and the `if` and `do` blocks
+// outside any function; function bodies are not descended into, since a local function
+// there has no name a caller elsewhere could write.
+type LuaParser struct{}
+
+// NewLuaParser creates a new Lua parser.
+func NewLuaParser() *LuaParser { return &LuaParser{} }
+
+// SupportsExtension returns true for .lua files.
+func (p *LuaParser) SupportsExtension(ext string) bool {
+	return ext == ".lua"
+}
+
+var (
+	luaLangOnce sync.Once
+	luaLang     *ts.Language
+)
+
+func luaLanguage() *ts.Language {
+	luaLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("Lua", r)
+			}
+		}()
+		luaLang = grammars.LuaLanguage()
+	})
+	return luaLang
+}
+
+// Parse extracts structure from Lua source via tree-sitter. Best-effort on
+// parse errors: tree-sitter recovers to a partial tree for most mid-edit
+// buffers, and we walk whatever it produced.
+func (p *LuaParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := luaSymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}, nil
+}
+
+// luaCandidate is a name that is exported if its root table turns out to be
+// the returned module or a global.
+type luaCandidate struct {
+	name string
+	line int
+}
+
+func luaSymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("Lua parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			hashes, lines = nil, nil
+		}
+	}()
+
+	lang := luaLanguage()
+	if lang == nil {
+		diag.noGrammar("Lua")
+		return imports, functions, classes, exports, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("Lua source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil
+	}
+	// As for Ruby: an ERROR root means nothing below it is a declaration, so
+	// say the symbols are missing rather than let them read as absent.
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("Lua file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+
+	// recordHash combines on collision: a function redefined in one chunk,
+	// often under an `if` choosing between implementations.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+	recordLine := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+
+	// Whether a name is exported depends on whether its root is local and on
+	// what the chunk returns, neither known until the walk ends.
+	locals := make(map[string]bool)
+	var candidates []luaCandidate
+	returned := ""
+
+	addFunction := func(full string, span []byte, line int) {
+		functions = append(functions, full)
+		recordHash("function:"+full, span)
+		recordLine("function:"+full, line)
+	}
+	// define records one name bound to value: a function is listed, a table
+	// constructor's fields are bound under it, and every name is a candidate.
+	define := func(full string, value *ts.Node, span []byte, line int) {
+		candidates = append(candidates, luaCandidate{full, line})
+		if value == nil {
+			return
+		}
+		switch value.Type(lang) {
+		case "function_definition":
+			addFunction(full, span, line)
+		case "table_constructor":
+			for _, f := range luaFields(value, lang, src) {
+				candidates = append(candidates, luaCandidate{qualify(full, f.name), f.line})
+				if f.fn {
+					addFunction(qualify(full, f.name), src[f.node.StartByte():f.node.EndByte()], f.line)
+				}
+			}
+		}
+	}
+	// assign binds each target of an assignment to the value in its position.
+	assign := func(a *ts.Node, local bool, span []byte) {
+		var targets, values []*ts.Node
+		for i := 0; i < a.NamedChildCount(); i++ {
+			switch l := a.NamedChild(i); l.Type(lang) {
+			case "variable_list":
+				for j := 0; j < l.NamedChildCount(); j++ {
+					targets = append(targets, l.NamedChild(j))
+				}
+			case "expression_list":
+				for j := 0; j < l.NamedChildCount(); j++ {
+					values = append(values, l.NamedChild(j))
+				}
+			}
+		}
+		for i, t := range targets {
+			name := luaName(t, lang, src)
+			if name == "" {
+				continue
+			}
+			if local {
+				locals[name] = true
+			}
+			var value *ts.Node
+			if i < len(values) {
+				value = values[i]
+			}
+			define(name, value, span, int(t.StartPoint().Row)+1)
+		}
+	}
+
+	var walk func(n *ts.Node, depth int)
+	walk = func(n *ts.Node, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			c := n.NamedChild(i)
+			span := src[c.StartByte():c.EndByte()]
+			line := int(c.StartPoint().Row) + 1
+
+			switch c.Type(lang) {
+			case "function_declaration":
+				nameNode := c.ChildByFieldName("name", lang)
+				if nameNode == nil {
+					continue
+				}
+				name := luaName(nameNode, lang, src)
+				if name == "" {
+					continue
+				}
+				if c.ChildCount() > 0 && c.Child(0).Type(lang) == "local" {
+					locals[name] = true
+				}
+				candidates = append(candidates, luaCandidate{name, line})
+				addFunction(name, span, line)
+
+			case "variable_declaration":
+				for j := 0; j < c.NamedChildCount(); j++ {
+					switch d := c.NamedChild(j); d.Type(lang) {
+					case "assignment_statement":
+						assign(d, true, span)
+					case "variable_list":
+						// `local a, b` with no values.
+						for k := 0; k < d.NamedChildCount(); k++ {
+							if name := luaName(d.NamedChild(k), lang, src); name != "" {
+								locals[name] = true
+							}
+						}
+					case "identifier":
+						locals[d.Text(src)] = true
+					}
+				}
+
+			case "assignment_statement":
+				assign(c, false, span)
+
+			case "return_statement":
+				if n.Type(lang) != "chunk" {
+					continue
+				}
+				// The chunk's value is its module: a table named in the file,
+				// or a table built in the return itself.
+				var value *ts.Node
+				for j := 0; j < c.NamedChildCount(); j++ {
+					if l := c.NamedChild(j); l.Type(lang) == "expression_list" {
+						value = l.NamedChild(0)
+					}
+				}
+				if value == nil {
+					continue
+				}
+				switch value.Type(lang) {
+				case "identifier":
+					returned = value.Text(src)
+				case "table_constructor":
+					for _, f := range luaFields(value, lang, src) {
+						exports = append(exports, f.name)
+						recordLine("export:"+f.name, f.line)
+						if f.fn {
+							addFunction(f.name, src[f.node.StartByte():f.node.EndByte()], f.line)
+						}
+					}
+				}
+
+			case "if_statement", "elseif_statement", "else_statement", "do_statement", "block":
+				// Conditionally defined functions are still definitions.
+				walk(c, depth+1)
+
+			case "ERROR":
+				// A mid-edit buffer's ERROR node can still hold whole
+				// definitions.
+				walk(c, depth+1)
+			}
+		}
+	}
+	walk(tree.RootNode(), 0)
+
+	for _, c := range candidates {
+		root := c.name
+		if i := strings.IndexByte(root, '.'); i >= 0 {
+			root = root[:i]
+		}
+		// The returned table's own local name is not visible to a caller;
+		// its members are.
+		if !locals[root] || (root == returned && c.name != root) {
+			exports = append(exports, c.name)
+			recordLine("export:"+c.name, c.line)
+		}
+	}
+
+	imports = append(imports, luaRequires(tree.RootNode(), lang, src, 0)...)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, hashes, lines
+}
+
+// luaName returns the name an assignment target or function declaration
+// binds: "x", "M.fetch", "a.b.c", and "M.send" for a method `M:send`. Bracket
+// indexing (`t[k]`) names nothing a reader could write as a symbol and
+// returns "".
+func luaName(n *ts.Node, lang *ts.Language, src []byte) string {
+	switch n.Type(lang) {
+	case "identifier":
+		return n.Text(src)
+	case "dot_index_expression":
+		table, field := n.ChildByFieldName("table", lang), n.ChildByFieldName("field", lang)
+		if table == nil || field == nil {
+			return ""
+		}
+		if owner := luaName(table, lang, src); owner != "" {
+			return qualify(owner, field.Text(src))
+		}
+	case "method_index_expression":
+		table, method := n.ChildByFieldName("table", lang), n.ChildByFieldName("method", lang)
+		if table == nil || method == nil {
+			return ""
+		}
+		if owner := luaName(table, lang, src); owner != "" {
+			return qualify(owner, method.Text(src))
+		}
+	}
+	return ""
+}
+
+// luaField is one named field of a table constructor.
+type luaField struct {
+	name string
+	line int
+	fn   bool // the value is a function definition
+	node *ts.Node
+}
+
+// luaFields returns a table constructor's named fields. Positional entries
+// and `[expr] = v` keys name nothing.
+func luaFields(table *ts.Node, lang *ts.Language, src []byte) []luaField {
+	var fields []luaField
+	for i := 0; i < table.NamedChildCount(); i++ {
+		f := table.NamedChild(i)
+		if f.Type(lang) != "field" {
+			continue
+		}
+		name := f.ChildByFieldName("name", lang)
+		if name == nil || name.Type(lang) != "identifier" {
+			continue
+		}
+		value := f.ChildByFieldName("value", lang)
+		fields = append(fields, luaField{
+			name: name.Text(src),
+			line: int(f.StartPoint().Row) + 1,
+			fn:   value != nil && value.Type(lang) == "function_definition",
+			node: f,
+		})
+	}
+	return fields
+}
+
+// luaRequires returns the literal module names of every `require` call under
+// n, in function bodies too.
+func luaRequires(n *ts.Node, lang *ts.Language, src []byte, depth int) []string {
+	if depth > maxParseNestDepth {
+		return nil
+	}
+	var mods []string
+	if n.Type(lang) == "function_call" {
+		if name := n.ChildByFieldName("name", lang); name != nil && name.Type(lang) == "identifier" && name.Text(src) == "require" {
+			if args := n.ChildByFieldName("arguments", lang); args != nil && args.NamedChildCount() == 1 {
+				if s := args.NamedChild(0); s.Type(lang) == "string" {
+					if content := s.ChildByFieldName("content", lang); content != nil && content.Text(src) != "" {
+						mods = append(mods, content.Text(src))
+					}
+				}
+			}
+		}
+	}
+	for i := 0; i < n.NamedChildCount(); i++ {
+		mods = append(mods, luaRequires(n.NamedChild(i), lang, src, depth+1)...)
+	}
+	return mods
+}
@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const luaSample = `local json = require("cjson")
+local util = require "app.util"
+local M = {}
+local x, y = 1, 2
+Config = { debug = false }
+
+function M.fetch(id) return id end
+function M:method(a) return self end
+local function helper() return "function fake() end" end
+function global_fn() end
+local anon = function() end
+M.assigned = function(a) end
+M.value = 3
+function M.lazy() local lfs = require("lfs") end
+-- function commented() end
+--[[ function block() end ]]
+local s = [[
+function long() end
+]]
+if cond then function inside() end end
+local t = { inner = function() end, plain = 1 }
+pcall(require, "dyn")
+return M
+`
+
+func TestLuaParser_Extension(t *testing.T) {
+	p := NewLuaParser()
+	if !p.SupportsExtension(".lua") {
+		t.Error("want .lua supported")
+	}
+	if p.SupportsExtension(".luac") || p.SupportsExtension(".rb") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestLuaParser_Symbols(t *testing.T) {
+	got, err := NewLuaParser().Parse(luaSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// A require inside a function counts; pcall's computed one does not.
+	wantImports := []string{"app.util", "cjson", "lfs"}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports:\n got %q\nwant %q", got.Imports, wantImports)
+	}
+	if len(got.Classes) != 0 {
+		t.Errorf("Classes = %q, want none", got.Classes)
+	}
+	// A method is named with a dot; function values assigned to names and
+	// fields are listed; the commented and long-string ones are not.
+	wantFunctions := []string{
+		"M.assigned", "M.fetch", "M.lazy", "M.method", "anon", "global_fn", "helper", "inside", "t.inner",
+	}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+}
+
+// Exports are the returned table's members and globals; locals, including
+// the returned table's own name, are not.
+func TestLuaParser_Visibility(t *testing.T) {
+	got, _ := NewLuaParser().Parse(luaSample)
+	wantExports := []string{
+		"Config", "Config.debug", "M.assigned", "M.fetch", "M.lazy", "M.method", "M.value", "global_fn", "inside",
+	}
+	if !reflect.DeepEqual(got.Exports, wantExports) {
+		t.Errorf("Exports:\n got %q\nwant %q", got.Exports, wantExports)
+	}
+}
+
+// A chunk returning a table literal exports its fields.
+func TestLuaParser_ReturnedTable(t *testing.T) {
+	src := "local function fetch() end\nlocal VERSION = \"1\"\n" +
+		"return {\n  fetch = fetch,\n  go = function() end,\n  VERSION = VERSION,\n}\n"
+	got, _ := NewLuaParser().Parse(src)
+	if want := []string{"VERSION", "fetch", "go"}; !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+	if want := []string{"fetch", "go"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+	if got.SymbolLines["export:go"] != 5 {
+		t.Errorf("export:go start line = %d, want 5", got.SymbolLines["export:go"])
+	}
+}
+
+func TestLuaParser_HashesAndLines(t *testing.T) {
+	got, _ := NewLuaParser().Parse(luaSample)
+	for key, want := range map[string]int{
+		"function:M.fetch":  7,
+		"function:M.method": 8,
+		"function:t.inner":  21,
+		"export:M.value":    13,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(luaSample, "return id end", "return id + 1 end", 1)
+	after, _ := NewLuaParser().Parse(edited)
+	if after.SymbolHashes["function:M.fetch"] == got.SymbolHashes["function:M.fetch"] {
+		t.Error("editing the function body did not change the symbol hash")
+	}
+	if after.SymbolHashes["function:M.method"] != got.SymbolHashes["function:M.method"] {
+		t.Error("unrelated symbol's hash changed")
+	}
+}
+
+func TestLuaParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "-- function commented() end\n" +
+		"--[==[ function block() end ]==]\n" +
+		"local s = [=[\nfunction long() end\n]=]\n" +
+		"local q = 'require(\"fake\")'\n" +
+		"function real() end\n"
+	got, _ := NewLuaParser().Parse(src)
+	if !reflect.DeepEqual(got.Functions, []string{"real"}) || len(got.Imports) != 0 {
+		t.Errorf("Functions %q, Imports %q; want only real", got.Functions, got.Imports)
+	}
+}
+
+func TestLuaParser_CRLFParity(t *testing.T) {
+	lf, _ := NewLuaParser().Parse(luaSample)
+	crlf, _ := NewLuaParser().Parse(strings.ReplaceAll(luaSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestLuaParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "function broken(", "local \x00\xff", "end end end"} {
+		got, err := NewLuaParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzLuaParser asserts the Lua parser never panics on arbitrary input and
+// keeps the invariants the other tree-sitter parsers keep.
+// Run: go test -run=x -fuzz=FuzzLuaParser ./internal/parser
+func FuzzLuaParser(f *testing.F) {
+	seeds := []string{
+		luaSample,
+		"function f() end",
+		"local M = {} function M:g() end return M",
+		"return { a = function() end, 1, [k] = 2 }",
+		"a.b.c = function() end",
+		"local x <close> = require 'y'",
+		"local s = [==[\nunterminated",
+		"", "function", "end", "local local", "--[[",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewLuaParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -0,0 +1,412 @@
+package parser
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Manifest is what a package manifest declares: the package it describes, what
+// it depends on, and the scripts it defines. Dependencies are sorted by name,
+// then scope; Scripts by name.
+type Manifest struct {
+	// Ecosystem is the manifest's format: "npm" (package.json), "go" (go.mod),
+	// or "pip" (requirements.txt).
+	Ecosystem    string       `json:"ecosystem"`
+	Name         string       `json:"name,omitempty"` // package name or module path; empty for pip
+	Dependencies []Dependency `json:"dependencies"`
+	Scripts      []Script     `json:"scripts,omitempty"`
+}
+
+// Dependency is one declared dependency. Version is the constraint as written
+// ("^4.17.21", "v0.47.0", ">=2.31,<3"), empty when none is given. Scope says
+// how it is declared: the package.json section ("devDependencies"), the go.mod
+// directive ("require", "indirect", "replace", "tool", "go", "toolchain"), or
+// "requirements".
+type Dependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Scope   string `json:"scope"`
+}
+
+// Script is one package.json script: its name and command line.
+type Script struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+// ManifestParser reads dependency manifests: package.json, go.mod, and pip
+// requirements files. What a package depends on drifts as surely as its
+// source does, and a version bump is invisible in a source diff, so each
+// manifest is indexed two ways:
+//
+//   - FileStructure.Manifest carries the declared dependencies, versions, and
+//     scripts in full (the IR's manifests; see ir.IR.Manifests).
+//   - Each dependency is also an import (→ Imports, "lodash"), hashed over its
+//     scopes and versions, so bumping it shows in a snapshot diff as a modified
+//     import and adding or dropping it as an added or removed one. A
+//     package.json script → Exports, hashed over its command. A requirements
+//     file's `-r`/`-c` includes → Imports as written.
+//
+// Manifests are claimed by name (see FilenameParser): package.json, go.mod,
+// and requirements.txt with its requirements-dev.txt and dev-requirements.txt
+// variants. The format is picked by name; Parse, without one, tells them apart
+// by content. A pip project name is normalized (PEP 503: lowercase, runs of
+// -_. as one -) since pip treats the spellings as one project.
+//
+// Known limitations: lock files are not read, so a manifest's ranges are
+// recorded, not the versions installed; pyproject.toml, Pipfile, and
+// workspace members are not manifests here; a package.json that is not valid
+// JSON yields nothing.
+type ManifestParser struct{}
+
+// NewManifestParser creates a new dependency manifest parser.
+func NewManifestParser() *ManifestParser { return &ManifestParser{} }
+
+// SupportsExtension returns false: .json, .mod, and .txt files are mostly not
+// manifests, so the parser claims files by name only (see SupportsFilename).
+func (p *ManifestParser) SupportsExtension(ext string) bool {
+	return false
+}
+
+// SupportsFilename returns true for package.json, go.mod, and pip
+// requirements files.
+func (p *ManifestParser) SupportsFilename(name string) bool {
+	return manifestKind(name) != ""
+}
+
+// manifestKind returns the ecosystem of a manifest file name, or "".
+func manifestKind(name string) string {
+	switch lower := strings.ToLower(name); {
+	case name == "package.json":
+		return "npm"
+	case name == "go.mod":
+		return "go"
+	case filepath.Ext(lower) == ".txt":
+		stem := strings.TrimSuffix(lower, ".txt")
+		if strings.HasPrefix(stem, "requirements") || strings.HasSuffix(stem, "requirements") {
+			return "pip"
+		}
+	}
+	return ""
+}
+
+// Parse reads a manifest whose name is unknown, telling the formats apart by
+// content: a JSON object is a package.json, a file with a `module` directive
+// a go.mod, anything else a requirements file.
+func (p *ManifestParser) Parse(source string) (FileStructure, error) {
+	return p.ParseNamed(source, "")
+}
+
+// goModuleLine finds a go.mod's module directive.
+var goModuleLine = regexp.MustCompile(`(?m)^\s*module\s+\S`)
+
+// ParseNamed reads the manifest named name (see SupportsFilename).
+func (p *ManifestParser) ParseNamed(source, name string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	kind := manifestKind(name)
+	if kind == "" {
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(source), "{"):
+			kind = "npm"
+		case goModuleLine.MatchString(source):
+			kind = "go"
+		default:
+			kind = "pip"
+		}
+	}
+	b := newManifestBuilder(kind)
+	switch kind {
+	case "npm":
+		if !b.readPackageJSON(source) {
+			b = newManifestBuilder(kind)
+		}
+	case "go":
+		b.readGoMod(source)
+	case "pip":
+		b.readRequirements(source)
+	}
+	m := b.m
+
+	sort.Slice(m.Dependencies, func(i, j int) bool {
+		a, c := m.Dependencies[i], m.Dependencies[j]
+		if a.Name != c.Name {
+			return a.Name < c.Name
+		}
+		return a.Scope < c.Scope
+	})
+	sort.Slice(m.Scripts, func(i, j int) bool { return m.Scripts[i].Name < m.Scripts[j].Name })
+
+	// A dependency's hash covers every scope and version it is declared with,
+	// in sorted order so it does not depend on the order of the file.
+	for _, d := range m.Dependencies {
+		b.imports = append(b.imports, d.Name)
+		h := hashBytesHex([]byte(d.Scope + "\x00" + d.Version))
+		if existing, ok := b.hashes["import:"+d.Name]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		b.hashes["import:"+d.Name] = h
+	}
+	for _, s := range m.Scripts {
+		b.exports = append(b.exports, s.Name)
+		b.hashes["export:"+s.Name] = hashBytesHex([]byte(s.Command))
+	}
+
+	sort.Strings(b.imports)
+	sort.Strings(b.exports)
+	fs := FileStructure{
+		Imports:   deduplicate(b.imports),
+		Functions: []string{},
+		Classes:   []string{},
+		Exports:   deduplicate(b.exports),
+		Manifest:  m,
+	}
+	if len(b.hashes) > 0 {
+		fs.SymbolHashes = b.hashes
+	}
+	if len(b.lines) > 0 {
+		fs.SymbolLines = b.lines
+	}
+	return fs, nil
+}
+
+// manifestBuilder collects one manifest's declarations and symbols.
+type manifestBuilder struct {
+	m                *Manifest
+	imports, exports []string
+	hashes           map[string]string
+	lines            map[string]int
+}
+
+func newManifestBuilder(ecosystem string) *manifestBuilder {
+	return &manifestBuilder{
+		m:      &Manifest{Ecosystem: ecosystem, Dependencies: []Dependency{}},
+		hashes: map[string]string{},
+		lines:  map[string]int{},
+	}
+}
+
+// dep records a dependency declared on line.
+func (b *manifestBuilder) dep(name, version, scope string, line int) {
+	if name == "" {
+		return
+	}
+	b.m.Dependencies = append(b.m.Dependencies, Dependency{Name: name, Version: version, Scope: scope})
+	if _, ok := b.lines["import:"+name]; !ok {
+		b.lines["import:"+name] = line
+	}
+}
+
+// npmDependencySections are the package.json objects that declare
+// dependencies.
+var npmDependencySections = map[string]bool{
+	"dependencies": true, "devDependencies": true, "peerDependencies": true, "optionalDependencies": true,
+}
+
+// readPackageJSON reads a package.json's name, dependency sections, and
+// scripts, noting the line of each entry. ok is false for malformed JSON.
+func (b *manifestBuilder) readPackageJSON(data string) bool {
+	starts := lineStartsOf([]byte(data))
+	dec := json.NewDecoder(strings.NewReader(data))
+	// lineOfNext returns the line the decoder's next token starts on.
+	lineOfNext := func() int {
+		off := int(dec.InputOffset())
+		for off < len(data) && strings.IndexByte(" \t\n\r,:", data[off]) >= 0 {
+			off++
+		}
+		return lineForOffset(starts, off)
+	}
+	skip := func() error { return dec.Decode(new(json.RawMessage)) }
+
+	// entries reads an object of string values, calling fn for each; a
+	// non-string value (an npm overrides object, say) is skipped.
+	entries := func(fn func(key, value string, line int)) bool {
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+			return false
+		}
+		for dec.More() {
+			line := lineOfNext()
+			tok, err := dec.Token()
+			key, ok := tok.(string)
+			if err != nil || !ok {
+				return false
+			}
+			var raw json.RawMessage
+			if dec.Decode(&raw) != nil {
+				return false
+			}
+			var value string
+			if json.Unmarshal(raw, &value) == nil {
+				fn(key, value, line)
+			}
+		}
+		_, err := dec.Token()
+		return err == nil
+	}
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return false
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		key, isKey := tok.(string)
+		if err != nil || !isKey {
+			return false
+		}
+		switch {
+		case key == "name":
+			var raw json.RawMessage
+			if dec.Decode(&raw) != nil {
+				return false
+			}
+			_ = json.Unmarshal(raw, &b.m.Name)
+		case npmDependencySections[key]:
+			if !entries(func(name, version string, line int) { b.dep(name, version, key, line) }) {
+				return false
+			}
+		case key == "scripts":
+			read := entries(func(name, command string, line int) {
+				b.m.Scripts = append(b.m.Scripts, Script{Name: name, Command: command})
+				if _, seen := b.lines["export:"+name]; !seen {
+					b.lines["export:"+name] = line
+				}
+			})
+			if !read {
+				return false
+			}
+		default:
+			if skip() != nil {
+				return false
+			}
+		}
+	}
+	tok, err := dec.Token()
+	return err == nil && tok == json.Delim('}')
+}
+
+// readGoMod reads a go.mod's module path and its go, toolchain, require,
+// replace, and tool directives, single-line or block form.
+func (b *manifestBuilder) readGoMod(data string) {
+	block := "" // the directive of the open ( … ) block, if any
+	for i, raw := range strings.Split(data, "\n") {
+		line := i + 1
+		text, comment, _ := strings.Cut(raw, "//")
+		fields := strings.Fields(text)
+		if block != "" {
+			if len(fields) == 1 && fields[0] == ")" {
+				block = ""
+				continue
+			}
+			b.goDirective(block, fields, comment, line)
+			continue
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		b.goDirective(fields[0], fields[1:], comment, line)
+	}
+}
+
+// goDirective records one go.mod directive's arguments.
+func (b *manifestBuilder) goDirective(verb string, args []string, comment string, line int) {
+	unquote := func(s string) string { return strings.Trim(s, "\"`") }
+	switch verb {
+	case "module":
+		if len(args) > 0 {
+			b.m.Name = unquote(args[0])
+		}
+	case "go", "toolchain":
+		if len(args) > 0 {
+			b.dep("go", args[0], verb, line)
+		}
+	case "require":
+		if len(args) < 2 {
+			return
+		}
+		scope := "require"
+		if strings.TrimSpace(comment) == "indirect" || strings.HasPrefix(strings.TrimSpace(comment), "indirect;") {
+			scope = "indirect"
+		}
+		b.dep(unquote(args[0]), args[1], scope, line)
+	case "replace":
+		// old [version] => new [version]; the version is the replacement.
+		for j, a := range args {
+			if a == "=>" && j > 0 && j+1 < len(args) {
+				b.dep(unquote(args[0]), "=> "+strings.Join(args[j+1:], " "), "replace", line)
+				return
+			}
+		}
+	case "tool":
+		if len(args) > 0 {
+			b.dep(unquote(args[0]), "", "tool", line)
+		}
+	}
+}
+
+// pipRequirement splits a requirement into its project name, extras, and
+// the rest: a version specifier or an `@ url` direct reference.
+var pipRequirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(.*)$`)
+
+// pipNameRun matches the separators PEP 503 folds into one "-".
+var pipNameRun = regexp.MustCompile(`[-_.]+`)
+
+// readRequirements reads a pip requirements file: one requirement per line,
+// with `\` continuations, `#` comments, environment markers after `;`, and
+// `-r`/`-c` includes. Other options (`--index-url`, `-e`) and bare paths or
+// URLs name no project and are skipped.
+func (b *manifestBuilder) readRequirements(data string) {
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		start := i + 1
+		text := lines[i]
+		for strings.HasSuffix(text, "\\") && i+1 < len(lines) {
+			i++
+			text = strings.TrimSuffix(text, "\\") + lines[i]
+		}
+		// A comment starts a line or follows whitespace (pip's rule, so a URL
+		// fragment "#egg=x" is not one).
+		if j := strings.Index(text, "#"); j == 0 || (j > 0 && strings.ContainsAny(text[j-1:j], " \t")) {
+			text = text[:j]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "-") {
+			opt, arg, _ := strings.Cut(text, " ")
+			if k := strings.IndexByte(opt, '='); k > 0 {
+				opt, arg = opt[:k], opt[k+1:]
+			}
+			switch opt {
+			case "-r", "--requirement", "-c", "--constraint":
+				if arg = strings.TrimSpace(arg); arg != "" {
+					b.imports = append(b.imports, arg)
+				}
+			}
+			continue
+		}
+		spec, _, _ := strings.Cut(text, ";")
+		m := pipRequirement.FindStringSubmatch(strings.TrimSpace(spec))
+		if m == nil {
+			continue
+		}
+		rest := strings.TrimSpace(m[3])
+		if rest != "" && !strings.ContainsAny(rest[:1], "<>=!~@(") {
+			continue // a path or URL, not a project name
+		}
+		name := pipNameRun.ReplaceAllString(strings.ToLower(m[1]), "-")
+		version := strings.Join(strings.Fields(strings.Trim(rest, "()")), "")
+		if strings.HasPrefix(rest, "@") {
+			version = "@ " + strings.TrimSpace(rest[1:])
+		}
+		b.dep(name, version, "requirements", start)
+	}
+}
@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const packageJSONSample = `{
+  "name": "@acme/web",
+  "version": "1.4.0",
+  "description": "\"dependencies\": {\"fake\": \"1\"}",
+  "scripts": {
+    "build": "tsc -p .",
+    "test": "vitest run"
+  },
+  "dependencies": {
+    "lodash": "^4.17.21",
+    "react": "^18.2.0"
+  },
+  "devDependencies": {
+    "typescript": "~5.4.0",
+    "react": "^18.2.0"
+  },
+  "overrides": {"semver": "7.5.2"},
+  "workspaces": ["packages/*"]
+}
+`
+
+const goModSample = `module github.com/acme/tool
+
+go 1.25.0
+
+toolchain go1.25.1
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	golang.org/x/text v0.21.0
+	github.com/mattn/go-runewidth v0.0.15 // indirect
+	// github.com/commented/out v1.0.0
+)
+
+replace github.com/spf13/cobra => ../cobra
+
+tool golang.org/x/tools/cmd/stringer
+`
+
+const requirementsSample = `# Runtime dependencies
+-r base.txt
+--index-url https://pypi.example.com/simple
+Django>=4.2,<5.0
+requests[socks] == 2.31.0 ; python_version >= "3.8"
+Flask_SQLAlchemy
+numpy \
+    >=1.26
+pkg @ https://example.com/pkg-1.0.tar.gz
+./vendor/local-pkg
+-e git+https://github.com/acme/lib.git#egg=lib
+`
+
+func TestManifestParser_Filenames(t *testing.T) {
+	p := NewManifestParser()
+	if p.SupportsExtension(".json") || p.SupportsExtension(".mod") || p.SupportsExtension(".txt") {
+		t.Error("must not claim extensions")
+	}
+	for _, name := range []string{"package.json", "go.mod", "requirements.txt", "requirements-dev.txt", "dev-requirements.txt", "Requirements.txt"} {
+		if !p.SupportsFilename(name) {
+			t.Errorf("want %s supported", name)
+		}
+	}
+	for _, name := range []string{"package-lock.json", "go.sum", "notes.txt", "tsconfig.json", "Package.json"} {
+		if p.SupportsFilename(name) {
+			t.Errorf("must not claim %s", name)
+		}
+	}
+}
+
+func TestManifestParser_PackageJSON(t *testing.T) {
+	got, err := NewManifestParser().ParseNamed(packageJSONSample, "package.json")
+	if err != nil {
+		t.Fatalf("ParseNamed: %v", err)
+	}
+	want := &Manifest{
+		Ecosystem: "npm",
+		Name:      "@acme/web",
+		Dependencies: []Dependency{
+			{"lodash", "^4.17.21", "dependencies"},
+			{"react", "^18.2.0", "dependencies"},
+			{"react", "^18.2.0", "devDependencies"},
+			{"typescript", "~5.4.0", "devDependencies"},
+		},
+		Scripts: []Script{{"build", "tsc -p ."}, {"test", "vitest run"}},
+	}
+	if !reflect.DeepEqual(got.Manifest, want) {
+		t.Errorf("Manifest:\n got %+v\nwant %+v", got.Manifest, want)
+	}
+	if want := []string{"lodash", "react", "typescript"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if want := []string{"build", "test"}; !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+}
+
+func TestManifestParser_GoMod(t *testing.T) {
+	got, _ := NewManifestParser().ParseNamed(goModSample, "go.mod")
+	want := &Manifest{
+		Ecosystem: "go",
+		Name:      "github.com/acme/tool",
+		Dependencies: []Dependency{
+			{"github.com/mattn/go-runewidth", "v0.0.15", "indirect"},
+			{"github.com/spf13/cobra", "=> ../cobra", "replace"},
+			{"github.com/spf13/cobra", "v1.8.0", "require"},
+			{"go", "1.25.0", "go"},
+			{"go", "go1.25.1", "toolchain"},
+			{"golang.org/x/text", "v0.21.0", "require"},
+			{"golang.org/x/tools/cmd/stringer", "", "tool"},
+		},
+	}
+	if !reflect.DeepEqual(got.Manifest, want) {
+		t.Errorf("Manifest:\n got %+v\nwant %+v", got.Manifest, want)
+	}
+}
+
+func TestManifestParser_Requirements(t *testing.T) {
+	got, _ := NewManifestParser().ParseNamed(requirementsSample, "requirements.txt")
+	want := &Manifest{
+		Ecosystem: "pip",
+		Dependencies: []Dependency{
+			{"django", ">=4.2,<5.0", "requirements"},
+			{"flask-sqlalchemy", "", "requirements"},
+			{"numpy", ">=1.26", "requirements"},
+			{"pkg", "@ https://example.com/pkg-1.0.tar.gz", "requirements"},
+			{"requests", "==2.31.0", "requirements"},
+		},
+	}
+	if !reflect.DeepEqual(got.Manifest, want) {
+		t.Errorf("Manifest:\n got %+v\nwant %+v", got.Manifest, want)
+	}
+	// The -r include is an import too; --index-url and -e are not.
+	if want := []string{"base.txt", "django", "flask-sqlalchemy", "numpy", "pkg", "requests"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+}
+
+// Without a name the format is told by content.
+func TestManifestParser_SniffsFormat(t *testing.T) {
+	for src, want := range map[string]string{packageJSONSample: "npm", goModSample: "go", requirementsSample: "pip"} {
+		got, _ := NewManifestParser().Parse(src)
+		if got.Manifest == nil || got.Manifest.Ecosystem != want {
+			t.Errorf("Parse sniffed %+v, want %s", got.Manifest, want)
+		}
+	}
+}
+
+// A version bump changes the dependency's hash and nothing else's, so a
+// snapshot diff reports it as one modified import.
+func TestManifestParser_HashesAndLines(t *testing.T) {
+	got, _ := NewManifestParser().ParseNamed(packageJSONSample, "package.json")
+	for key, want := range map[string]int{"import:lodash": 10, "import:react": 11, "import:typescript": 14, "export:build": 6} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	bumped, _ := NewManifestParser().ParseNamed(strings.Replace(packageJSONSample, "^4.17.21", "^4.18.0", 1), "package.json")
+	for key, h := range got.SymbolHashes {
+		if changed := bumped.SymbolHashes[key] != h; changed != (key == "import:lodash") {
+			t.Errorf("%s: hash changed = %v after bumping lodash", key, changed)
+		}
+	}
+	// Reordering declarations is not drift.
+	reordered := strings.Replace(packageJSONSample, `"lodash": "^4.17.21",
+    "react": "^18.2.0"`, `"react": "^18.2.0",
+    "lodash": "^4.17.21"`, 1)
+	after, _ := NewManifestParser().ParseNamed(reordered, "package.json")
+	if !reflect.DeepEqual(after.SymbolHashes, got.SymbolHashes) || !reflect.DeepEqual(after.Manifest, got.Manifest) {
+		t.Error("reordering dependencies changed the manifest")
+	}
+}
+
+func TestManifestParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	for name, src := range map[string]string{"package.json": packageJSONSample, "go.mod": goModSample, "requirements.txt": requirementsSample} {
+		got, _ := NewManifestParser().ParseNamed(src, name)
+		for _, imp := range got.Imports {
+			if strings.Contains(imp, "fake") || strings.Contains(imp, "commented") || strings.Contains(imp, "semver") || strings.Contains(imp, "local-pkg") || imp == "lib" {
+				t.Errorf("%s: import %q came from a string, comment, or non-dependency", name, imp)
+			}
+		}
+	}
+}
+
+func TestManifestParser_CRLFParity(t *testing.T) {
+	for name, src := range map[string]string{"package.json": packageJSONSample, "go.mod": goModSample, "requirements.txt": requirementsSample} {
+		lf, _ := NewManifestParser().ParseNamed(src, name)
+		crlf, _ := NewManifestParser().ParseNamed(strings.ReplaceAll(src, "\n", "\r\n"), name)
+		if !reflect.DeepEqual(lf, crlf) {
+			t.Errorf("%s: CRLF source parsed differently from LF", name)
+		}
+	}
+}
+
+func TestManifestParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "{", "[]", `{"dependencies": 1}`, `{"dependencies": {"a": 1, "b": "2"}}`, packageJSONSample[:120], "require (", "module", "replace a =>", "-r", "\\", "==1.0"} {
+		for _, name := range []string{"package.json", "go.mod", "requirements.txt", ""} {
+			got, err := NewManifestParser().ParseNamed(src, name)
+			if err != nil {
+				t.Errorf("ParseNamed(%q, %q) errored: %v", src, name, err)
+			}
+			assertParserInvariants(t, got)
+			assertSpanKeysNameRealSymbols(t, got)
+		}
+	}
+	// Malformed JSON yields an empty manifest, not a partial one.
+	if got, _ := NewManifestParser().ParseNamed(packageJSONSample[:200], "package.json"); len(got.Manifest.Dependencies) != 0 || len(got.Exports) != 0 {
+		t.Errorf("truncated package.json yielded %+v", got.Manifest)
+	}
+}
+
+// FuzzManifestParser asserts the manifest parser never panics on arbitrary
+// input and keeps the shared invariants.
+// Run: go test -run=x -fuzz=FuzzManifestParser ./internal/parser
+func FuzzManifestParser(f *testing.F) {
+	seeds := []string{
+		packageJSONSample, goModSample, requirementsSample,
+		`{"dependencies": {"a": "1"}}`, "module m\nrequire x v1\n", "a==1\n",
+		"", "{", "require (", "-r",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewManifestParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		for _, name := range []string{"package.json", "go.mod", "requirements.txt", ""} {
+			fs, err := p.ParseNamed(src, name) // must never panic
+			if err != nil {
+				continue
+			}
+			assertParserInvariants(t, fs)
+			assertSpanKeysNameRealSymbols(t, fs)
+		}
+	})
+}
@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MarkdownParser implements structural parsing for Markdown documentation
+// (.md, .markdown), so docs drift shows in a diff the way code drift does:
+//
+//   - Headings, ATX (`## Install`) and setext (underlined), go to Classes by
+//     their text, each hashed over its section — the heading through the line
+//     before the next heading of the same or a higher level — so an edited
+//     paragraph shows its section as modified.
+//   - The languages of fenced code blocks go to Exports ("go", "bash"), each
+//     hashed over every block in that language, so an edited example shows
+//     as modified too.
+//   - Relative link and image targets — inline (`[text](../setup.md#linux)`)
+//     and reference definitions (`[id]: img/arch.png`) — go to Imports without
+//     their `#fragment` or `?query`. A URL with a scheme (`https:`,
+//     `mailto:`), a protocol-relative `//host` one, and an in-page `#anchor`
+//     are not imports.
+//
+// Markdown has no functions, so Functions is always empty. The engine is a
+// line scan, like the Dockerfile parser's: lines inside a fenced code block,
+// an HTML comment, or leading YAML front matter are never headings or links,
+// and code spans are blanked before links are read.
+//
+// Known limitations: heading names are the text as written, inline markup
+// included, and two sections of one name (two "Usage" headings) share one
+// entry with a combined hash; indented code blocks are not tracked, so a
+// `[x](y)` in one counts as a link; HTML `<a href>` and `<img src>` tags are
+// not read.
+type MarkdownParser struct{}
+
+// NewMarkdownParser creates a new Markdown parser.
+func NewMarkdownParser() *MarkdownParser { return &MarkdownParser{} }
+
+// SupportsExtension returns true for .md and .markdown files.
+func (p *MarkdownParser) SupportsExtension(ext string) bool {
+	return ext == ".md" || ext == ".markdown"
+}
+
+var (
+	reMDFence   = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*(.*)$")
+	reMDATX     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	reMDSetext  = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	reMDBlock   = regexp.MustCompile(`^ {0,3}(?:[-*+][ \t]|\d{1,9}[.)][ \t]|>|<|\|)|^(?: {4}|\t)`)
+	reMDRefDef  = regexp.MustCompile(`^ {0,3}\[[^\]^][^\]]*\]:[ \t]*(<[^>]*>|\S+)`)
+	reMDInline  = regexp.MustCompile(`\]\([ \t]*(<[^>]*>|(?:[^()\s]|\([^()\s]*\))+)`)
+	reMDCode    = regexp.MustCompile("(`+)[^`]+?(`+)")
+	reMDHeadID  = regexp.MustCompile(`[ \t]*\{#[^}]*\}$`)
+	reMDScheme  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+	reMDFenceID = regexp.MustCompile(`^\{?\.?([^\s{},]+)`)
+)
+
+// mdHeading is one heading: its level, text, and the 0-based lines its
+// section starts at (a setext heading's first text line) and runs to.
+type mdHeading struct {
+	level       int
+	text        string
+	first, last int
+}
+
+// Parse extracts a Markdown document's headings, code-fence languages, and
+// relative links. Best-effort and never errors: an unclosed fence runs to the
+// end of the file.
+func (p *MarkdownParser) Parse(source string) (FileStructure, error) {
+	// Normalize CRLF→LF so hashes and line numbers are line-ending-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	lines := strings.Split(source, "\n")
+
+	imports, classes, exports := []string{}, []string{}, []string{}
+	hashes := make(map[string]string)
+	starts := make(map[string]int)
+	record := func(key string, first, last int) {
+		h := hashBytesHex([]byte(strings.Join(lines[first:last+1], "\n")))
+		// Combine on collision: two sections, or two examples, of one name.
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+		if _, ok := starts[key]; !ok {
+			starts[key] = first + 1
+		}
+	}
+
+	var headings []mdHeading
+	para := -1 // the first line of the open paragraph, or -1
+	i := 0
+	if strings.TrimRight(lines[0], " \t") == "---" {
+		for j := 1; j < len(lines); j++ {
+			if l := strings.TrimRight(lines[j], " \t"); l == "---" || l == "..." {
+				i = j + 1
+				break
+			}
+		}
+	}
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if m := reMDFence.FindStringSubmatch(line); m != nil && !(m[1][0] == '`' && strings.Contains(m[2], "`")) {
+			para = -1
+			first := i
+			for i++; i < len(lines); i++ {
+				l := strings.TrimSpace(lines[i])
+				if len(l) >= len(m[1]) && strings.Trim(l, m[1][:1]) == "" {
+					break
+				}
+			}
+			if i >= len(lines) {
+				i = len(lines) - 1
+			}
+			if id := reMDFenceID.FindStringSubmatch(m[2]); id != nil {
+				lang := strings.ToLower(id[1])
+				exports = append(exports, lang)
+				record("export:"+lang, first, i)
+			}
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "<!--") {
+			para = -1
+			for ; i < len(lines) && !strings.Contains(lines[i], "-->"); i++ {
+			}
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			para = -1
+			continue
+		}
+		if m := reMDATX.FindStringSubmatch(line); m != nil {
+			para = -1
+			if text := mdHeadingText(m[2]); text != "" {
+				headings = append(headings, mdHeading{level: len(m[1]), text: text, first: i})
+			}
+			continue
+		}
+		if m := reMDSetext.FindStringSubmatch(line); m != nil {
+			if para < 0 {
+				continue // a thematic break
+			}
+			level := 1
+			if m[1][0] == '-' {
+				level = 2
+			}
+			if text := mdHeadingText(strings.Join(lines[para:i], " ")); text != "" {
+				headings = append(headings, mdHeading{level: level, text: text, first: para})
+			}
+			para = -1
+			continue
+		}
+		imports = append(imports, mdLinks(line)...)
+		switch {
+		case reMDBlock.MatchString(line):
+			para = -1
+		case para < 0:
+			para = i
+		}
+	}
+
+	for h, head := range headings {
+		head.last = len(lines) - 1
+		for _, next := range headings[h+1:] {
+			if next.level <= head.level {
+				head.last = next.first - 1
+				break
+			}
+		}
+		classes = append(classes, head.text)
+		record("class:"+head.text, head.first, head.last)
+	}
+
+	sort.Strings(imports)
+	sort.Strings(classes)
+	sort.Strings(exports)
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(starts) == 0 {
+		starts = nil
+	}
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    []string{},
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  starts,
+	}, nil
+}
+
+// mdHeadingText returns a heading's text with surrounding space and any
+// trailing `{#custom-id}` attribute removed.
+func mdHeadingText(raw string) string {
+	return strings.TrimSpace(reMDHeadID.ReplaceAllString(strings.TrimSpace(raw), ""))
+}
+
+// mdLinks returns the relative link and image targets on one line, without
+// their fragment or query.
+func mdLinks(line string) []string {
+	// Blank code spans so a `[x](y)` in one is not a link.
+	line = reMDCode.ReplaceAllStringFunc(line, func(s string) string {
+		return strings.Repeat(" ", len(s))
+	})
+	var targets []string
+	if m := reMDRefDef.FindStringSubmatch(line); m != nil {
+		targets = append(targets, m[1])
+	}
+	for _, m := range reMDInline.FindAllStringSubmatch(line, -1) {
+		targets = append(targets, m[1])
+	}
+	var out []string
+	for _, t := range targets {
+		t = strings.TrimSuffix(strings.TrimPrefix(t, "<"), ">")
+		if reMDScheme.MatchString(t) || strings.HasPrefix(t, "//") {
+			continue
+		}
+		if cut := strings.IndexAny(t, "#?"); cut >= 0 {
+			t = t[:cut]
+		}
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const markdownSample = `---
+title: "# Not a heading"
+---
+
+# RunEcho
+
+See [setup](docs/setup.md#linux), [the API](./api.md?plain=1), and
+![architecture](img/arch.png "Architecture"). Not imports: [site](https://example.com),
+[mail](mailto:a@example.com), [top](#runecho), [cdn](//cdn.example.com/x.js),
+and ` + "`[code](span.md)`" + `.
+
+Install
+-------
+
+` + "```bash" + `
+go install ./cmd/runecho-ir
+# not a heading
+[not](a-link.md)
+` + "```" + `
+
+## Usage {#usage}
+
+` + "~~~go title=\"main.go\"" + `
+fmt.Println("hi")
+` + "~~~" + `
+
+<!--
+## Commented Out
+-->
+
+* * *
+Also a setext heading
+---
+
+### Usage
+
+` + "```BASH" + `
+runecho-ir map
+` + "```" + `
+
+[ref]: <docs/ref guide.md>
+[^1]: footnote text.md
+`
+
+func TestMarkdownParser_Extension(t *testing.T) {
+	p := NewMarkdownParser()
+	for _, ext := range []string{".md", ".markdown"} {
+		if !p.SupportsExtension(ext) {
+			t.Errorf("want %s supported", ext)
+		}
+	}
+	if p.SupportsExtension(".mdx") || p.SupportsExtension(".txt") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestMarkdownParser_Symbols(t *testing.T) {
+	got, err := NewMarkdownParser().Parse(markdownSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"./api.md", "docs/ref guide.md", "docs/setup.md", "img/arch.png"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if len(got.Functions) != 0 {
+		t.Errorf("Functions = %q, want none", got.Functions)
+	}
+	if want := []string{"Also a setext heading", "Install", "RunEcho", "Usage"}; !reflect.DeepEqual(got.Classes, want) {
+		t.Errorf("Classes = %q, want %q", got.Classes, want)
+	}
+	if want := []string{"bash", "go"}; !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+}
+
+func TestMarkdownParser_HashesAndLines(t *testing.T) {
+	got, _ := NewMarkdownParser().Parse(markdownSample)
+	for key, want := range map[string]int{"class:RunEcho": 5, "class:Install": 12, "class:Usage": 21, "export:bash": 15, "export:go": 23} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(markdownSample, "go install ./cmd/runecho-ir", "go install ./cmd/...", 1)
+	after, _ := NewMarkdownParser().Parse(edited)
+	for _, key := range []string{"class:Install", "class:RunEcho", "export:bash"} {
+		if after.SymbolHashes[key] == got.SymbolHashes[key] {
+			t.Errorf("editing the install example did not change %s", key)
+		}
+	}
+	for _, key := range []string{"class:Usage", "export:go"} {
+		if after.SymbolHashes[key] != got.SymbolHashes[key] {
+			t.Errorf("editing the install example changed %s", key)
+		}
+	}
+}
+
+func TestMarkdownParser_CRLFParity(t *testing.T) {
+	lf, _ := NewMarkdownParser().Parse(markdownSample)
+	crlf, _ := NewMarkdownParser().Parse(strings.ReplaceAll(markdownSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestMarkdownParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "#", "---", "```", "<!--", "===", "---\n# open front matter", "[a](", "](x)"} {
+		got, err := NewMarkdownParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		assertParserInvariants(t, got)
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzMarkdownParser asserts the Markdown parser never panics on arbitrary
+// input and keeps the shared invariants.
+// Run: go test -run=x -fuzz=FuzzMarkdownParser ./internal/parser
+func FuzzMarkdownParser(f *testing.F) {
+	seeds := []string{
+		markdownSample,
+		"# A\n## B\n# C\n",
+		"Title\n===\ntext [x](y.md)\n",
+		"```go\nx\n```\n",
+		"", "#", "```", "~~~", "<!--", "---", "[a]: b",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewMarkdownParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -144,3 +144,32 @@ func TestPythonParse_WalkDepth_NoCrash(t *testing.T) {
 		t.Fatalf("Python Parse hung on deeply-indented input for >60s (elapsed %v)", time.Since(start))
 	}
 }
+
+// TestNestGuard_Diagnostics: a parser that gives up on over-nested input says
+// so in FileStructure.Diagnostics, for the generator to report, rather than
+// writing to stderr; a clean parse has none.
+func TestNestGuard_Diagnostics(t *testing.T) {
+	nested := strings.Repeat("(", maxParseNestDepth+1)
+	for name, p := range map[string]Parser{
+		"java":   NewJavaParser(),
+		"python": NewPythonParser(),
+		"rust":   NewRustParser(),
+		"js":     NewTreeSitterParser(),
+	} {
+		fs, err := p.Parse(nested)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(fs.Diagnostics) == 0 {
+			t.Errorf("%s: no Diagnostics for over-nested input", name)
+		}
+		for _, d := range fs.Diagnostics {
+			if !strings.Contains(d, "max nesting depth") {
+				t.Errorf("%s: diagnostic %q, want the nesting bailout", name, d)
+			}
+		}
+		if fs, _ := p.Parse("x = 1\n"); fs.Diagnostics != nil {
+			t.Errorf("%s: clean parse has Diagnostics %q", name, fs.Diagnostics)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// NotebookParser implements structural parsing for Jupyter notebooks
+// (.ipynb). A notebook is JSON; its code cells, concatenated in order, are a
+// program in the kernel's language, so they are handed to that language's
+// built-in parser — Python for an IPython kernel, the JS/TS parser for a
+// Deno or tslab one, and so on — and the notebook's structure is that
+// program's. Markdown cells and outputs are not code and are skipped.
+//
+// The language is the notebook's `metadata.language_info.name`, falling back
+// to `metadata.kernelspec.language`, then Python, the nbformat default. Start
+// lines are mapped back to the notebook file, so `locate` points at the JSON
+// line holding a definition's first source line; body hashes are over the
+// code alone, so re-running a cell (new outputs, a new execution count) does
+// not show its functions as modified.
+//
+// For a Python notebook, IPython syntax that is not Python is blanked before
+// parsing: a line magic or shell escape (`%matplotlib inline`, `!pip
+// install x`) becomes an empty line, and a cell run under a cell magic
+// (`%%bash`) is skipped whole.
+//
+// Known limitations: nbformat 3 notebooks (cells under `worksheets`) and
+// notebooks in a language without a built-in parser (R, Julia) yield no
+// symbols; malformed JSON yields none either, rather than an error, so the
+// file is still indexed by its hash.
+type NotebookParser struct{}
+
+// NewNotebookParser creates a new Jupyter notebook parser.
+func NewNotebookParser() *NotebookParser { return &NotebookParser{} }
+
+// SupportsExtension returns true for .ipynb files.
+func (p *NotebookParser) SupportsExtension(ext string) bool {
+	return ext == ".ipynb"
+}
+
+// notebookLanguages maps kernel language names to built-in parser names.
+var notebookLanguages = map[string]string{
+	"python": "python", "python3": "python", "ipython": "python",
+	"javascript": "javascript", "typescript": "typescript",
+	"go": "go", "rust": "rust", "ruby": "ruby", "java": "java",
+	"c#": "csharp", "csharp": "csharp", "php": "php", "kotlin": "kotlin",
+	"swift": "swift", "scala": "scala", "dart": "dart", "elixir": "elixir",
+	"lua": "lua", "sql": "sql", "bash": "shell", "sh": "shell", "shell": "shell",
+}
+
+// nbSourceLine is one element of a cell's `source` array: its text and the
+// 1-based line of the notebook file it is written on.
+type nbSourceLine struct {
+	text string
+	line int
+}
+
+// nbCell is one cell of a notebook.
+type nbCell struct {
+	kind   string
+	source []nbSourceLine
+}
+
+// Parse extracts the structure of a notebook's code cells. Best-effort and
+// never errors: see the type's known limitations.
+func (p *NotebookParser) Parse(source string) (FileStructure, error) {
+	// Normalize CRLF→LF so line numbers are line-ending-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	empty := FileStructure{Imports: []string{}, Functions: []string{}, Classes: []string{}, Exports: []string{}}
+
+	lang, cells, ok := readNotebook(source)
+	if !ok {
+		return empty, nil
+	}
+	lp, ext, ok := Builtin(notebookLanguages[strings.ToLower(lang)])
+	if !ok {
+		return empty, nil
+	}
+	code, lineMap := notebookCode(cells, ext == ".py")
+
+	var (
+		fs  FileStructure
+		err error
+	)
+	if ep, ok := lp.(ExtAwareParser); ok {
+		fs, err = ep.ParseExt(code, ext)
+	} else {
+		fs, err = lp.Parse(code)
+	}
+	if err != nil {
+		return empty, nil
+	}
+	for key, line := range fs.SymbolLines {
+		switch {
+		case line >= 1 && line <= len(lineMap):
+			fs.SymbolLines[key] = lineMap[line-1]
+		case len(lineMap) > 0:
+			fs.SymbolLines[key] = lineMap[len(lineMap)-1]
+		}
+	}
+	return fs, nil
+}
+
+// notebookCode concatenates the code cells into one program and returns it
+// with the notebook-file line each of its lines came from. With ipython set,
+// line magics and shell escapes are blanked and cell-magic cells skipped.
+func notebookCode(cells []nbCell, ipython bool) (string, []int) {
+	var code strings.Builder
+	var lineMap []int
+	for _, c := range cells {
+		if c.kind != "code" {
+			continue
+		}
+		var lines []string
+		var at []int
+		startLine := true
+		for _, s := range c.source {
+			for _, seg := range strings.SplitAfter(s.text, "\n") {
+				if seg == "" {
+					continue
+				}
+				if startLine {
+					lines = append(lines, "")
+					at = append(at, s.line)
+				}
+				lines[len(lines)-1] += strings.TrimSuffix(seg, "\n")
+				startLine = strings.HasSuffix(seg, "\n")
+			}
+		}
+		if ipython && len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[0]), "%%") {
+			continue
+		}
+		for i, l := range lines {
+			if t := strings.TrimSpace(l); ipython && (strings.HasPrefix(t, "%") || strings.HasPrefix(t, "!")) {
+				l = ""
+			}
+			code.WriteString(l)
+			code.WriteByte('\n')
+			lineMap = append(lineMap, at[i])
+		}
+	}
+	return code.String(), lineMap
+}
+
+// readNotebook reads a notebook's kernel language and cells, noting the file
+// line of every source string. ok is false for malformed JSON.
+func readNotebook(data string) (lang string, cells []nbCell, ok bool) {
+	starts := lineStartsOf([]byte(data))
+	dec := json.NewDecoder(strings.NewReader(data))
+	// lineOfNext returns the line the decoder's next token starts on.
+	lineOfNext := func() int {
+		off := int(dec.InputOffset())
+		for off < len(data) && strings.IndexByte(" \t\n\r,:", data[off]) >= 0 {
+			off++
+		}
+		return lineForOffset(starts, off)
+	}
+	skip := func() error { return dec.Decode(new(json.RawMessage)) }
+	delim := func(want json.Delim) bool {
+		tok, err := dec.Token()
+		return err == nil && tok == want
+	}
+	key := func() (string, bool) {
+		tok, err := dec.Token()
+		k, isKey := tok.(string)
+		return k, err == nil && isKey
+	}
+
+	if !delim('{') {
+		return "", nil, false
+	}
+	var meta struct {
+		Kernelspec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	}
+	for dec.More() {
+		k, ok := key()
+		if !ok {
+			return "", nil, false
+		}
+		switch k {
+		case "metadata":
+			if dec.Decode(&meta) != nil {
+				return "", nil, false
+			}
+		case "cells":
+			if !delim('[') {
+				return "", nil, false
+			}
+			for dec.More() {
+				if !delim('{') {
+					return "", nil, false
+				}
+				var c nbCell
+				for dec.More() {
+					k, ok := key()
+					if !ok {
+						return "", nil, false
+					}
+					switch k {
+					case "cell_type":
+						if dec.Decode(&c.kind) != nil {
+							return "", nil, false
+						}
+					case "source":
+						src, ok := readNotebookSource(dec, lineOfNext)
+						if !ok {
+							return "", nil, false
+						}
+						c.source = src
+					default:
+						if skip() != nil {
+							return "", nil, false
+						}
+					}
+				}
+				if !delim('}') {
+					return "", nil, false
+				}
+				cells = append(cells, c)
+			}
+			if !delim(']') {
+				return "", nil, false
+			}
+		default:
+			if skip() != nil {
+				return "", nil, false
+			}
+		}
+	}
+	if !delim('}') {
+		return "", nil, false
+	}
+	lang = meta.LanguageInfo.Name
+	if lang == "" {
+		lang = meta.Kernelspec.Language
+	}
+	if lang == "" {
+		lang = "python"
+	}
+	return lang, cells, true
+}
+
+// readNotebookSource reads a cell's `source`: one string, or an array of
+// strings, each usually one line of the cell.
+func readNotebookSource(dec *json.Decoder, lineOfNext func() int) ([]nbSourceLine, bool) {
+	line := lineOfNext()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, false
+	}
+	if s, ok := tok.(string); ok {
+		return []nbSourceLine{{s, line}}, true
+	}
+	if tok != json.Delim('[') {
+		return nil, false
+	}
+	var out []nbSourceLine
+	for dec.More() {
+		line := lineOfNext()
+		tok, err := dec.Token()
+		s, ok := tok.(string)
+		if err != nil || !ok {
+			return nil, false
+		}
+		out = append(out, nbSourceLine{s, line})
+	}
+	tok, err = dec.Token()
+	return out, err == nil && tok == json.Delim(']')
+}
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// notebookSample is laid out as Jupyter writes it: one-space indent, each
+// source line its own array element.
+const notebookSample = `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "metadata": {},
+   "source": [
+    "# Analysis\n",
+    "def not_code(): pass"
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 1,
+   "metadata": {},
+   "outputs": [{"output_type": "stream", "text": ["def not_an_output(): pass\n"]}],
+   "source": [
+    "%matplotlib inline\n",
+    "!pip install pandas\n",
+    "import pandas as pd\n",
+    "from sklearn.linear_model import LinearRegression"
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 2,
+   "metadata": {},
+   "outputs": [],
+   "source": [
+    "def load(path):\n",
+    "    return pd.read_csv(path)\n",
+    "\n",
+    "class Model:\n",
+    "    def fit(self, df):\n",
+    "        return LinearRegression().fit(df, df)"
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 3,
+   "metadata": {},
+   "outputs": [],
+   "source": [
+    "%%bash\n",
+    "echo def not_python\n"
+   ]
+  }
+ ],
+ "metadata": {
+  "kernelspec": {"display_name": "Python 3", "language": "python", "name": "python3"},
+  "language_info": {"name": "python", "version": "3.12.0"}
+ },
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+
+func TestNotebookParser_Extension(t *testing.T) {
+	p := NewNotebookParser()
+	if !p.SupportsExtension(".ipynb") {
+		t.Error("want .ipynb supported")
+	}
+	if p.SupportsExtension(".py") || p.SupportsExtension(".json") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+// Code cells are parsed as one Python program; markdown cells, outputs,
+// magics, and cell-magic cells are not code.
+func TestNotebookParser_Symbols(t *testing.T) {
+	got, err := NewNotebookParser().Parse(notebookSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"pandas", "sklearn.linear_model"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if want := []string{"Model.fit", "load"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+	if want := []string{"Model"}; !reflect.DeepEqual(got.Classes, want) {
+		t.Errorf("Classes = %q, want %q", got.Classes, want)
+	}
+}
+
+// Start lines are the notebook file's; hashes ignore execution state.
+func TestNotebookParser_HashesAndLines(t *testing.T) {
+	got, _ := NewNotebookParser().Parse(notebookSample)
+	for key, want := range map[string]int{"function:load": 29, "class:Model": 32, "function:Model.fit": 33} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	rerun := strings.Replace(notebookSample, `"execution_count": 2,`, `"execution_count": 7,`, 1)
+	after, _ := NewNotebookParser().Parse(rerun)
+	if !reflect.DeepEqual(after.SymbolHashes, got.SymbolHashes) {
+		t.Error("re-running a cell changed its symbols' hashes")
+	}
+	edited := strings.Replace(notebookSample, "pd.read_csv(path)", "pd.read_parquet(path)", 1)
+	after, _ = NewNotebookParser().Parse(edited)
+	if after.SymbolHashes["function:load"] == got.SymbolHashes["function:load"] {
+		t.Error("editing load did not change its hash")
+	}
+}
+
+// A non-Python kernel's cells go to that language's parser.
+func TestNotebookParser_KernelLanguage(t *testing.T) {
+	src := `{"cells": [{"cell_type": "code", "source": "export function plot(data: number[]) {}\nclass Chart {}\n"}],
+ "metadata": {"kernelspec": {"language": "typescript"}}}`
+	got, _ := NewNotebookParser().Parse(src)
+	if want := []string{"plot"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+	if want := []string{"Chart"}; !reflect.DeepEqual(got.Classes, want) {
+		t.Errorf("Classes = %q, want %q", got.Classes, want)
+	}
+
+	r := `{"cells": [{"cell_type": "code", "source": ["f <- function(x) x\n"]}], "metadata": {"language_info": {"name": "R"}}}`
+	if got, _ := NewNotebookParser().Parse(r); len(got.Functions) != 0 {
+		t.Errorf("R notebook Functions = %q, want none", got.Functions)
+	}
+}
+
+func TestNotebookParser_CRLFParity(t *testing.T) {
+	lf, _ := NewNotebookParser().Parse(notebookSample)
+	crlf, _ := NewNotebookParser().Parse(strings.ReplaceAll(notebookSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestNotebookParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "{", "[]", `{"cells": 1}`, `{"cells": [{"source": [1]}]}`, `{"cells": [{"cell_type": "code", "source": ["def f(:"]}]}`, notebookSample[:200]} {
+		got, err := NewNotebookParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		assertParserInvariants(t, got)
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzNotebookParser asserts the notebook parser never panics on arbitrary
+// input and keeps the shared invariants.
+// Run: go test -run=x -fuzz=FuzzNotebookParser ./internal/parser
+func FuzzNotebookParser(f *testing.F) {
+	seeds := []string{
+		notebookSample,
+		`{"cells": [{"cell_type": "code", "source": "def f(): pass"}]}`,
+		`{"cells": [], "metadata": {"language_info": {"name": "go"}}}`,
+		"", "{", `{"cells": [`, `"x"`,
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewNotebookParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -0,0 +1,381 @@
+package parser
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// OpenAPIParser implements structural parsing for OpenAPI 3 and Swagger 2 API
+// specs, YAML or JSON, using the vendored pure-Go tree-sitter YAML and JSON
+// grammars. A spec is the contract an API's clients are generated from, so
+// its routes, operations, and types are indexed like code:
+//
+//   - Each path template ("/pets/{petId}") → Exports, hashed over its path
+//     item, so an edited parameter or response shows the route as modified.
+//   - Each operation's `operationId` → Functions and Exports, hashed over the
+//     operation: it is the name a generated client calls.
+//   - Each schema under `components.schemas` (Swagger 2: `definitions`) →
+//     Classes and Exports, hashed over the schema.
+//   - The file part of every external `$ref` ("./schemas/pet.yaml" of
+//     "./schemas/pet.yaml#/Pet") → Imports; a local "#/…" ref is not one.
+//
+// Specs are named rather than typed by extension, so the parser claims files
+// by name (see FilenameParser): openapi and swagger, and names ending in
+// .openapi or .swagger (petstore.openapi.yaml), with a .yaml, .yml, or .json
+// extension. A matched file whose top level has neither an `openapi` nor a
+// `swagger` key is not a spec and yields no symbols.
+//
+// Known limitations: an operation without an `operationId` is only visible
+// through its path's hash; YAML anchors and aliases are not followed, so a
+// path item that is an alias of another is not expanded; OpenAPI 3.1
+// `webhooks` are not read.
+type OpenAPIParser struct{}
+
+// NewOpenAPIParser creates a new OpenAPI/Swagger spec parser.
+func NewOpenAPIParser() *OpenAPIParser { return &OpenAPIParser{} }
+
+// SupportsExtension returns false: .yaml and .json files are mostly not API
+// specs, so the parser claims files by name only (see SupportsFilename).
+func (p *OpenAPIParser) SupportsExtension(ext string) bool {
+	return false
+}
+
+// SupportsFilename returns true for openapi and swagger specs, in any case:
+// openapi.yaml, swagger.json, petstore.openapi.yml.
+func (p *OpenAPIParser) SupportsFilename(name string) bool {
+	lower := strings.ToLower(name)
+	ext := filepath.Ext(lower)
+	if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+		return false
+	}
+	stem := strings.TrimSuffix(lower, ext)
+	for _, base := range []string{"openapi", "swagger"} {
+		if stem == base || strings.HasSuffix(stem, "."+base) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	yamlLangOnce sync.Once
+	yamlLang     *ts.Language
+	jsonLangOnce sync.Once
+	jsonLang     *ts.Language
+)
+
+func yamlLanguage() *ts.Language {
+	yamlLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("YAML", r)
+			}
+		}()
+		yamlLang = grammars.YamlLanguage()
+	})
+	return yamlLang
+}
+
+func jsonLanguage() *ts.Language {
+	jsonLangOnce.Do(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("JSON", r)
+			}
+		}()
+		jsonLang = grammars.JsonLanguage()
+	})
+	return jsonLang
+}
+
+// openAPIMethods are the operation keys of a path item.
+var openAPIMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// Parse extracts the paths, operations, and schemas of an API spec. The
+// grammar is picked by content: a document opening with `{` is JSON, anything
+// else YAML. Best-effort on parse errors, as for the code grammars.
+func (p *OpenAPIParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := openAPISymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}, nil
+}
+
+func openAPISymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("OpenAPI parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			hashes, lines = nil, nil
+		}
+	}()
+
+	isJSON := strings.HasPrefix(strings.TrimLeft(source, " \t\n\ufeff"), "{")
+	lang := yamlLanguage()
+	if isJSON {
+		lang = jsonLanguage()
+	}
+	if lang == nil {
+		if isJSON {
+			diag.noGrammar("JSON")
+		} else {
+			diag.noGrammar("YAML")
+		}
+		return imports, functions, classes, exports, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("OpenAPI source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil
+	}
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("OpenAPI spec did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+	d := specDoc{lang: lang, src: src}
+
+	top := d.pairs(d.root(tree.RootNode()))
+	isSpec := false
+	for _, p := range top {
+		if p.key == "openapi" || p.key == "swagger" {
+			isSpec = true
+		}
+	}
+	if !isSpec {
+		return imports, functions, classes, exports, nil, nil
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+	// record combines hashes on collision — an operationId used twice, which
+	// the spec forbids but a mid-edit file has — and keeps the first line.
+	record := func(key string, n *ts.Node) {
+		h := hashBytesHex(src[n.StartByte():n.EndByte()])
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+		if _, ok := lines[key]; !ok {
+			lines[key] = int(n.StartPoint().Row) + 1
+		}
+	}
+
+	for _, p := range top {
+		switch p.key {
+		case "paths":
+			for _, path := range d.pairs(p.value) {
+				if path.key == "" {
+					continue
+				}
+				exports = append(exports, path.key)
+				record("export:"+path.key, path.pair)
+				for _, op := range d.pairs(path.value) {
+					if !openAPIMethods[strings.ToLower(op.key)] {
+						continue
+					}
+					for _, f := range d.pairs(op.value) {
+						if id := d.scalar(f.value); f.key == "operationId" && id != "" {
+							functions = append(functions, id)
+							exports = append(exports, id)
+							record("function:"+id, op.pair)
+						}
+					}
+				}
+			}
+		case "components", "definitions":
+			schemas := p.value
+			if p.key == "components" {
+				schemas = nil
+				for _, c := range d.pairs(p.value) {
+					if c.key == "schemas" {
+						schemas = c.value
+					}
+				}
+			}
+			for _, s := range d.pairs(schemas) {
+				if s.key == "" {
+					continue
+				}
+				classes = append(classes, s.key)
+				exports = append(exports, s.key)
+				record("class:"+s.key, s.pair)
+			}
+		}
+	}
+	imports = append(imports, d.externalRefs(tree.RootNode(), 0)...)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, hashes, lines
+}
+
+// specDoc reads mappings and scalars out of a YAML or JSON syntax tree.
+type specDoc struct {
+	lang *ts.Language
+	src  []byte
+}
+
+// specPair is one key/value pair of a mapping.
+type specPair struct {
+	key         string
+	pair, value *ts.Node
+}
+
+// root returns the top-level value of a parsed document: YAML's
+// stream → document → node, JSON's document → object.
+func (d specDoc) root(n *ts.Node) *ts.Node {
+	for n != nil {
+		switch n.Type(d.lang) {
+		case "stream", "document":
+			var next *ts.Node
+			for i := 0; i < n.NamedChildCount(); i++ {
+				if c := n.NamedChild(i); c.Type(d.lang) != "comment" {
+					next = c
+					break
+				}
+			}
+			n = next
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// mapping returns the mapping node n holds, unwrapping YAML's block and flow
+// nodes and skipping anchors and tags, or nil if n is not a mapping.
+func (d specDoc) mapping(n *ts.Node) *ts.Node {
+	for depth := 0; n != nil && depth < 4; depth++ {
+		switch n.Type(d.lang) {
+		case "block_mapping", "flow_mapping", "object":
+			return n
+		case "block_node", "flow_node":
+			n = d.content(n)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// content returns the node a YAML block or flow node wraps, past any anchor
+// or tag.
+func (d specDoc) content(n *ts.Node) *ts.Node {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		switch c := n.NamedChild(i); c.Type(d.lang) {
+		case "anchor", "tag", "comment":
+		default:
+			return c
+		}
+	}
+	return nil
+}
+
+// pairs returns the key/value pairs of the mapping n holds, in order; none
+// if it holds no mapping.
+func (d specDoc) pairs(n *ts.Node) []specPair {
+	m := d.mapping(n)
+	if m == nil {
+		return nil
+	}
+	var out []specPair
+	for i := 0; i < m.NamedChildCount(); i++ {
+		c := m.NamedChild(i)
+		switch c.Type(d.lang) {
+		case "block_mapping_pair", "flow_pair", "pair":
+			out = append(out, specPair{
+				key:   d.scalar(c.ChildByFieldName("key", d.lang)),
+				pair:  c,
+				value: c.ChildByFieldName("value", d.lang),
+			})
+		}
+	}
+	return out
+}
+
+// scalar returns the string value of a scalar node, unquoted, or "" for a
+// missing or non-scalar node.
+func (d specDoc) scalar(n *ts.Node) string {
+	for depth := 0; n != nil && depth < 4; depth++ {
+		switch n.Type(d.lang) {
+		case "flow_node", "block_node":
+			n = d.content(n)
+		case "plain_scalar":
+			return strings.TrimSpace(n.Text(d.src))
+		case "single_quote_scalar":
+			t := n.Text(d.src)
+			return strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(t, "'"), "'"), "''", "'")
+		case "double_quote_scalar", "string":
+			var s string
+			if json.Unmarshal([]byte(n.Text(d.src)), &s) != nil {
+				s = strings.TrimSuffix(strings.TrimPrefix(n.Text(d.src), `"`), `"`)
+			}
+			return s
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// externalRefs returns the file part of every `$ref` under n that points
+// outside the document.
+func (d specDoc) externalRefs(n *ts.Node, depth int) []string {
+	if depth > maxParseNestDepth {
+		return nil
+	}
+	var out []string
+	switch n.Type(d.lang) {
+	case "block_mapping_pair", "flow_pair", "pair":
+		if d.scalar(n.ChildByFieldName("key", d.lang)) == "$ref" {
+			ref := d.scalar(n.ChildByFieldName("value", d.lang))
+			if file, _, _ := strings.Cut(ref, "#"); file != "" {
+				out = append(out, file)
+			}
+			return out
+		}
+	}
+	for i := 0; i < n.NamedChildCount(); i++ {
+		out = append(out, d.externalRefs(n.NamedChild(i), depth+1)...)
+	}
+	return out
+}
@@ -0,0 +1,213 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const openAPISample = `# Petstore API
+openapi: 3.0.3
+info:
+  title: "paths: not a path"
+  description: |
+    operationId: fakeOp
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pets"
+    post:
+      operationId: 'createPet'
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: ./schemas/pet.yaml#/NewPet
+  "/pets/{petId}":
+    parameters:
+      - name: petId
+        in: path
+    get:
+      operationId: showPetById
+      responses:
+        default:
+          $ref: common.yaml#/components/responses/Error
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        id: {type: integer}
+    Pets:
+      type: array
+      items:
+        $ref: "#/components/schemas/Pet"
+`
+
+const swaggerSample = `{
+  "swagger": "2.0",
+  "info": {"title": "operationId"},
+  "paths": {
+    "/users": {
+      "get": {"operationId": "listUsers", "responses": {"200": {"schema": {"$ref": "definitions.json#/User"}}}},
+      "x-operationId": {"operationId": "notAnOperation"}
+    }
+  },
+  "definitions": {
+    "User": {"type": "object"},
+    "Error\u0021": {"type": "object"}
+  }
+}
+`
+
+func TestOpenAPIParser_Extension(t *testing.T) {
+	p := NewOpenAPIParser()
+	if p.SupportsExtension(".yaml") || p.SupportsExtension(".json") {
+		t.Error("must not claim .yaml or .json by extension")
+	}
+	for _, name := range []string{"openapi.yaml", "openapi.yml", "swagger.json", "OpenAPI.YAML", "petstore.openapi.json", "v2.swagger.yaml"} {
+		if !p.SupportsFilename(name) {
+			t.Errorf("want %s supported", name)
+		}
+	}
+	for _, name := range []string{"config.yaml", "openapi.go", "openapi_test.yaml", "swagger", "package.json"} {
+		if p.SupportsFilename(name) {
+			t.Errorf("must not claim %s", name)
+		}
+	}
+}
+
+func TestOpenAPIParser_Symbols(t *testing.T) {
+	got, err := NewOpenAPIParser().Parse(openAPISample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"./schemas/pet.yaml", "common.yaml"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if want := []string{"createPet", "listPets", "showPetById"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+	if want := []string{"Pet", "Pets"}; !reflect.DeepEqual(got.Classes, want) {
+		t.Errorf("Classes = %q, want %q", got.Classes, want)
+	}
+	want := []string{"/pets", "/pets/{petId}", "Pet", "Pets", "createPet", "listPets", "showPetById"}
+	if !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+}
+
+// A Swagger 2 JSON spec: schemas are definitions, and only HTTP-method keys
+// hold operations.
+func TestOpenAPIParser_SwaggerJSON(t *testing.T) {
+	got, err := NewOpenAPIParser().Parse(swaggerSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"definitions.json"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if want := []string{"listUsers"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+	if want := []string{"Error!", "User"}; !reflect.DeepEqual(got.Classes, want) {
+		t.Errorf("Classes = %q, want %q", got.Classes, want)
+	}
+	if got.SymbolLines["export:/users"] != 5 || got.SymbolLines["class:User"] != 11 {
+		t.Errorf("SymbolLines = %v", got.SymbolLines)
+	}
+}
+
+// A YAML or JSON file that is not a spec yields nothing, whatever its keys.
+func TestOpenAPIParser_NotASpec(t *testing.T) {
+	for _, src := range []string{
+		"paths:\n  /x:\n    get:\n      operationId: f\n",
+		`{"paths": {"/x": {"get": {"operationId": "f"}}}}`,
+	} {
+		got, _ := NewOpenAPIParser().Parse(src)
+		if len(got.Functions) != 0 || len(got.Exports) != 0 || got.SymbolHashes != nil {
+			t.Errorf("Parse(%q) = %+v, want no symbols", src, got)
+		}
+	}
+}
+
+func TestOpenAPIParser_HashesAndLines(t *testing.T) {
+	got, _ := NewOpenAPIParser().Parse(openAPISample)
+	for key, want := range map[string]int{"export:/pets": 8, "function:listPets": 9, "function:createPet": 17, "export:/pets/{petId}": 24, "class:Pet": 35} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(openAPISample, "in: path", "in: query", 1)
+	after, _ := NewOpenAPIParser().Parse(edited)
+	if after.SymbolHashes["export:/pets/{petId}"] == got.SymbolHashes["export:/pets/{petId}"] {
+		t.Error("editing a path parameter did not change the path's hash")
+	}
+	if after.SymbolHashes["function:showPetById"] != got.SymbolHashes["function:showPetById"] {
+		t.Error("editing a path-level parameter changed the operation's hash")
+	}
+	if after.SymbolHashes["export:/pets"] != got.SymbolHashes["export:/pets"] {
+		t.Error("editing one path changed another's hash")
+	}
+}
+
+func TestOpenAPIParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	got, _ := NewOpenAPIParser().Parse(openAPISample)
+	for _, name := range append(got.Functions, got.Exports...) {
+		if strings.Contains(name, "fake") || strings.Contains(name, "not a path") || strings.Contains(name, "Petstore") {
+			t.Errorf("symbol %q came from a string or comment", name)
+		}
+	}
+}
+
+func TestOpenAPIParser_CRLFParity(t *testing.T) {
+	for _, src := range []string{openAPISample, swaggerSample} {
+		lf, _ := NewOpenAPIParser().Parse(src)
+		crlf, _ := NewOpenAPIParser().Parse(strings.ReplaceAll(src, "\n", "\r\n"))
+		if !reflect.DeepEqual(lf, crlf) {
+			t.Error("CRLF source parsed differently from LF")
+		}
+	}
+}
+
+func TestOpenAPIParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "{", "openapi:", "openapi: 3\npaths: [", `{"swagger": "2.0", "paths": 1}`, "openapi: 3\npaths:\n  /x: *alias\n", strings.Repeat("[", 5000), openAPISample[:300]} {
+		got, err := NewOpenAPIParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		assertParserInvariants(t, got)
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzOpenAPIParser asserts the OpenAPI parser never panics on arbitrary
+// input and keeps the shared invariants.
+// Run: go test -run=x -fuzz=FuzzOpenAPIParser ./internal/parser
+func FuzzOpenAPIParser(f *testing.F) {
+	seeds := []string{
+		openAPISample,
+		swaggerSample,
+		"openapi: 3.1.0\npaths:\n  /a:\n    get: {operationId: a}\n",
+		`{"openapi": "3.0.0", "components": {"schemas": {"A": {}}}}`,
+		"", "{", "openapi:", "- a",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewOpenAPIParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
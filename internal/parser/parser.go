@@ -1,5 +1,11 @@
 package parser
 
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
 // FileStructure represents the parsed structure of a source file.
 type FileStructure struct {
 	Imports   []string // Import paths (sorted)
@@ -8,15 +14,24 @@ type FileStructure struct {
 	Exports   []string // Exported symbol names (sorted)
 
 	// WildcardReexports lists the raw module specifiers pulled in via a bare
-	// `export * from './mod'` re-export (JS/TS only; sorted). A single-file
-	// parser cannot enumerate the target module's own bindings, so these names
-	// are never added to Exports — recording the specifier here at least makes
-	// the gap visible to downstream consumers (the IR generator resolves it
-	// in-repo where possible) instead of silently dropping it. Nil for parsers
-	// without this construct (everything but JS/TS) or files with no bare
-	// wildcard re-export.
+	// `export * from './mod'` re-export (JS/TS), a Dart `export 'uri';`
+	// without a `show` list, or a Protobuf `import public` (sorted). A single-file parser cannot enumerate the
+	// target module's own bindings, so these names are never added to Exports —
+	// recording the specifier here at least makes the gap visible to
+	// downstream consumers (the IR generator resolves it in-repo where
+	// possible) instead of silently dropping it. Nil for parsers without this
+	// construct (everything but JS/TS, Dart, and Protobuf) or files with no bare wildcard
+	// re-export.
 	WildcardReexports []string
 
+	// ReExports lists the module specifier of every JS/TS re-export — `export
+	// * from`, `export * as ns from`, and `export { a } from` (type-only
+	// included) — sorted, so a barrel file's edges to the modules it
+	// re-exports are recorded even though it imports nothing. The names a
+	// named re-export binds are in Exports; a wildcard's specifier is in
+	// WildcardReexports too. Nil for other parsers and for files without one.
+	ReExports []string
+
 	// SymbolHashes maps "kind:name" (e.g. "function:Reader.fetch") to a hash of
 	// that symbol's source body, for parsers that extract per-symbol spans (the
 	// AST-backed Python parser). It enables modified-symbol diffing: a symbol
@@ -30,6 +45,154 @@ type FileStructure struct {
 	// map` (symbol → file:line). Nil for parsers without span info; consumers
 	// render an unknown line as "?".
 	SymbolLines map[string]int
+
+	// SymbolColumns maps "kind:name" to the 1-based byte column, within its
+	// start line, where the symbol's span begins, for the AST parsers (Go,
+	// JS/TS, Python). Like SymbolLines it locates, so it never feeds a hash.
+	// Nil for other parsers; a missing key means the column is unknown.
+	SymbolColumns map[string]int
+
+	// Enums lists the TypeScript enums the file declares, `const enum`s
+	// included, sorted by name. Each is also in Classes under the same
+	// (qualified) name. Nil for other parsers and for files without one.
+	Enums []Enum
+
+	// Namespaces lists the TypeScript namespaces (`namespace A.B {}`, the
+	// older `module A {}`) and ambient module declarations (`declare module
+	// "pkg" {}`) the file declares, sorted by name. Each is also in Classes
+	// under the same (qualified) name. Nil for other parsers and for files
+	// without one.
+	Namespaces []Namespace
+
+	// Variables lists the JS/TS file's top-level `const`, `let`, and `var`
+	// bindings that are not functions or classes — constants and config
+	// objects — sorted by name. Exported ones are also in Exports. Nil for
+	// other parsers and for files without one.
+	Variables []Variable
+
+	// ImportDetails gives the bindings of each JS/TS module the file imports
+	// — `import` statements, TS `import x = require()`, and `require()`
+	// declarations — one per module, sorted by module. Nil for other parsers
+	// and for files without an import.
+	ImportDetails []ImportDetail
+
+	// TypeImports lists the modules a TS file imports only for their types —
+	// `import type { A }`, `import { type A }` — sorted. They are erased at
+	// compile time, so a bundler never loads them, but they stay in Imports:
+	// a change to the module can still break this file's type check. A
+	// module also imported at runtime, by a value import, `require()`, or
+	// `import()`, is not listed. Nil for other parsers and for files without
+	// one.
+	TypeImports []string
+
+	// ClassDetails describes each JS/TS class the file declares — what it
+	// extends and which methods and static members it offers — sorted by
+	// name, one per class in Classes (interfaces, enums, and namespaces have
+	// none). Nil for other parsers and for files without a class.
+	ClassDetails []ClassDetail
+
+	// FunctionDetails gives the call shape of each JS/TS function and method
+	// the AST found — its parameters and declared return type — sorted by
+	// name, at most one per name in Functions. Nil for other parsers and for
+	// files without a function.
+	FunctionDetails []FunctionDetail
+
+	// Decorators lists the JS/TS classes and methods that carry decorators,
+	// sorted by kind, then name. Nil for other parsers and for files without
+	// a decorated class or method.
+	Decorators []Decorated
+
+	// Docs maps "kind:name", as in SymbolHashes, to the first sentence of the
+	// symbol's documentation: the JSDoc comment above a JS/TS function or
+	// class, or a Python function's or class's docstring. Nil for other
+	// parsers and for files with no documented symbol.
+	Docs map[string]string
+
+	// Manifest is the dependency manifest the file declares, for the manifest
+	// parser (package.json, go.mod, requirements.txt); nil for source files.
+	Manifest *Manifest
+
+	// Diagnostics are the reasons, one line each, that the parse gave up on
+	// some of the file: its grammar failed to load, the parse panicked or
+	// nested too deep, or the tree was an error at its root. Symbols are then
+	// missing, not absent. The parsers never write them out themselves; the
+	// IR generator reports each as a warning for the file. Nil for a clean
+	// parse.
+	Diagnostics []string
+}
+
+// ClassDetail is the shape of one class. Extends is the superclass as written
+// (`Base<T>`, `mixin(A, B)`), whitespace collapsed; "" for a root class.
+// Methods are its instance methods (the constructor, getters, setters, and
+// abstract methods included) and Static its static methods and fields, both by
+// leaf name, sorted and deduplicated. Instance fields are not listed.
+type ClassDetail struct {
+	Name    string   `json:"name"`
+	Extends string   `json:"extends,omitempty"`
+	Methods []string `json:"methods,omitempty"`
+	Static  []string `json:"static,omitempty"`
+}
+
+// FunctionDetail is the call shape of one function or method. Params are its
+// parameters' names in order: a destructured one as written (`{ a, b }`), a
+// rest one as `...name`, and an optional one — a TS `?` or a default value —
+// with a trailing `?`; a TS `this` parameter is not one. Returns is the
+// declared TS return type as written, whitespace collapsed; "" if there is
+// none. Async, Generator, and Arrow say whether it is declared `async`, is a
+// `function*` or `*method`, and is an arrow function.
+type FunctionDetail struct {
+	Name      string   `json:"name"`
+	Params    []string `json:"params,omitempty"`
+	Returns   string   `json:"returns,omitempty"`
+	Async     bool     `json:"async,omitempty"`
+	Generator bool     `json:"generator,omitempty"`
+	Arrow     bool     `json:"arrow,omitempty"`
+}
+
+// Decorated is a class or method and its decorators — what marks its role in
+// a framework (`@Injectable`, `@Component`, `@Get`). Kind and Name are the
+// symbol's, as in SymbolHashes ("class"/"function", qualified like Classes and
+// Functions). Decorators are in source order, each less its `@` and arguments.
+type Decorated struct {
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Decorators []string `json:"decorators"`
+}
+
+// Enum is a TypeScript enum declaration. Const marks a `const enum`, which the
+// compiler inlines at each use and erases: it is API for type-checked callers
+// only, with no object at runtime.
+type Enum struct {
+	Name  string `json:"name"`
+	Const bool   `json:"const,omitempty"`
+}
+
+// Namespace is a TypeScript namespace or module declaration. Ambient marks a
+// `declare module "pkg"` declaration, which types a module by its import
+// specifier — Name is then the specifier, not an identifier.
+type Namespace struct {
+	Name    string `json:"name"`
+	Ambient bool   `json:"ambient,omitempty"`
+}
+
+// Variable is a top-level binding. Const marks a `const`, which cannot be
+// reassigned.
+type Variable struct {
+	Name  string `json:"name"`
+	Const bool   `json:"const,omitempty"`
+}
+
+// ImportDetail is what a file takes from one module. Default and Namespace
+// are the local names bound to the module's default export (`import React`)
+// and to the module as a whole (`import * as path`, `const fs =
+// require('fs')`); Named are the exports imported by name, as the module
+// names them (`{ a as b }` imports a), sorted and deduplicated. A module
+// imported for its side effects alone has none of the three.
+type ImportDetail struct {
+	Module    string   `json:"module"`
+	Default   string   `json:"default,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
+	Named     []string `json:"named,omitempty"`
 }
 
 // Parser extracts shallow structural information from source files.
@@ -58,6 +221,33 @@ type Parser interface {
 // determinism guarantee.
 const maxParseNestDepth = 1000
 
+// diagnostics collects one parse's FileStructure.Diagnostics.
+type diagnostics []string
+
+// addf adds a diagnostic.
+func (d *diagnostics) addf(format string, args ...any) {
+	*d = append(*d, fmt.Sprintf(format, args...))
+}
+
+// grammarFailures maps the name of each grammar that panicked while loading
+// to the panic value. A grammar loads once per process, but every parse it
+// left without symbols says why (see diagnostics.noGrammar).
+var grammarFailures sync.Map
+
+// grammarFailed records that the grammar name failed to load with r.
+func grammarFailed(name string, r any) {
+	grammarFailures.Store(name, r)
+}
+
+// noGrammar adds a diagnostic for a parse the grammar name was unavailable
+// to, if it failed to load. A build that leaves the grammar out
+// (grammar_subset) is not a failure and adds none.
+func (d *diagnostics) noGrammar(name string) {
+	if r, ok := grammarFailures.Load(name); ok {
+		d.addf("%s grammar failed to load (%v); symbols for this file disabled", name, r)
+	}
+}
+
 // exceedsNestDepth reports whether src nests (), [], or {} deeper than
 // maxParseNestDepth. It is a single linear byte scan tracking the running count
 // of all three opener classes together — a sound upper bound on AST nesting,
@@ -93,3 +283,44 @@ func exceedsNestDepth(src []byte) bool {
 type ExtAwareParser interface {
 	ParseExt(source, ext string) (FileStructure, error)
 }
+
+// FilenameParser is an optional extension implemented by parsers that claim
+// files by base name as well as by extension — currently the Dockerfile
+// parser, whose files are usually named `Dockerfile` with no extension at all.
+// The generator offers every file's base name to SupportsFilename alongside
+// its extension to SupportsExtension; a parser matching either handles the
+// file. Optional for the same reason as ExtAwareParser.
+type FilenameParser interface {
+	SupportsFilename(name string) bool
+}
+
+// NameAwareParser is an optional extension implemented by parsers whose
+// output depends on the file's name as well as its content — currently the
+// Vue and Svelte parsers, since a component is named after its file. The
+// generator calls ParseNamed with the file's base name when a parser
+// implements this, ahead of ParseExt and Parse.
+type NameAwareParser interface {
+	ParseNamed(source, name string) (FileStructure, error)
+}
+
+// docSummary returns the first sentence of a doc comment's text, already
+// stripped of its comment syntax: the first paragraph, up to a blank line or
+// a block tag (`@param`), whitespace collapsed and cut after the first word
+// ending in '.', '!', or '?'. An abbreviation like "e.g." cuts it short, as
+// it does Javadoc's summary; that is the price of not guessing.
+func docSummary(text string) string {
+	var words []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" && len(words) > 0 || strings.HasPrefix(line, "@") {
+			break
+		}
+		words = append(words, strings.Fields(line)...)
+	}
+	for i, w := range words {
+		if strings.HasSuffix(w, ".") || strings.HasSuffix(w, "!") || strings.HasSuffix(w, "?") {
+			return strings.Join(words[:i+1], " ")
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,399 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// PHPParser implements structural parsing for .php files using the vendored
+// pure-Go tree-sitter PHP grammar. A masking scan would have to know where
+// PHP starts and stops (`<?php … ?>` around HTML), and heredocs and
+// interpolated strings (`"{$a['}']}"`) hide braces and quotes of their own.
+//
+// Symbol routing:
+//   - `class` / `interface` / `trait` / `enum` → Classes. PHP has no nested
+//     types, so names are bare ("Reader").
+//   - Functions → Functions; methods qualified by their type ("Reader.fetch").
+//     A constructor is "Reader.__construct", as it is written.
+//   - `use` → Imports, one per imported name with groups expanded:
+//     `use Foo\{Bar, Baz as B};` is "Foo\Bar" and "Foo\Baz as B"; `use
+//     function` and `use const` keep their keyword ("function Foo\helper").
+//     A trait `use` inside a class is not an import.
+//   - `require` / `include` (and their `_once` forms) → Imports: the path
+//     when it is a plain string literal ("vendor/autoload.php"), otherwise
+//     the expression as written (`__DIR__ . '/x.php'`).
+//   - Namespaces → Exports, as the C# parser lists them: the namespace is what
+//     a `use` names. Like C#, declarations are not qualified by it.
+//
+// Visibility: a function, type, and global constant is visible wherever its
+// file is loaded, so all are exported. Members are public unless marked
+// `private` or `protected`; public methods, constants, properties (including
+// promoted constructor parameters), and enum cases are exported, properties
+// without their `$` ("Reader.count").
+//
+// Function bodies are not descended into. Declarations inside an `if` at file
+// level are, since `if (!function_exists('f')) { function f() {} }` is how a
+// PHP helper file declares its functions.
+type PHPParser struct{}
+
+// NewPHPParser creates a new PHP parser.
+func NewPHPParser() *PHPParser { return &PHPParser{} }
+
+// SupportsExtension returns true for .php files.
+func (p *PHPParser) SupportsExtension(ext string) bool {
+	return ext == ".php"
+}
+
+var (
+	phpLangOnce sync.Once
+	phpLang     *ts.Language
+)
+
+func phpLanguage() *ts.Language {
+	phpLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("PHP", r)
+			}
+		}()
+		phpLang = grammars.PhpLanguage()
+	})
+	return phpLang
+}
+
+// Parse extracts structure from PHP source via tree-sitter. Best-effort on
+// parse errors: tree-sitter recovers to a partial tree for most mid-edit
+// buffers, and we walk whatever it produced.
+func (p *PHPParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := phpSymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}, nil
+}
+
+// phpTypeDecls are the declarations that define a named type.
+var phpTypeDecls = map[string]bool{
+	"class_declaration":     true,
+	"interface_declaration": true,
+	"trait_declaration":     true,
+	"enum_declaration":      true,
+}
+
+// phpIncludes are the expressions that load another file.
+var phpIncludes = map[string]bool{
+	"require_expression":      true,
+	"require_once_expression": true,
+	"include_expression":      true,
+	"include_once_expression": true,
+}
+
+func phpSymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("PHP parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			hashes, lines = nil, nil
+		}
+	}()
+
+	lang := phpLanguage()
+	if lang == nil {
+		diag.noGrammar("PHP")
+		return imports, functions, classes, exports, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("PHP source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil
+	}
+	// As for Ruby: an ERROR root means nothing below it is a declaration, so
+	// say the symbols are missing rather than let them read as absent.
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("PHP file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+
+	// recordHash combines on collision: a function declared in both branches
+	// of an `if`.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+	recordLine := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+	addExport := func(full string, line int) {
+		exports = append(exports, full)
+		recordLine("export:"+full, line)
+	}
+	addFunction := func(full string, span []byte, line int, visible bool) {
+		functions = append(functions, full)
+		recordHash("function:"+full, span)
+		recordLine("function:"+full, line)
+		if visible {
+			exports = append(exports, full)
+		}
+	}
+
+	// members visits the declaration list of the type named prefix.
+	members := func(body *ts.Node, prefix string) {
+		for i := 0; i < body.NamedChildCount(); i++ {
+			c := body.NamedChild(i)
+			line := int(c.StartPoint().Row) + 1
+			visible := phpVisible(c, lang, src)
+			switch c.Type(lang) {
+			case "method_declaration":
+				name := rustFieldText(c, "name", lang, src)
+				if name == "" {
+					continue
+				}
+				addFunction(qualify(prefix, name), src[c.StartByte():c.EndByte()], line, visible)
+				if name == "__construct" {
+					// Promoted parameters declare properties.
+					if params := c.ChildByFieldName("parameters", lang); params != nil {
+						for j := 0; j < params.NamedChildCount(); j++ {
+							pp := params.NamedChild(j)
+							if pp.Type(lang) == "property_promotion_parameter" && phpVisible(pp, lang, src) {
+								if name := phpVariableName(pp, lang, src); name != "" {
+									addExport(qualify(prefix, name), int(pp.StartPoint().Row)+1)
+								}
+							}
+						}
+					}
+				}
+			case "const_declaration", "property_declaration":
+				if !visible {
+					continue
+				}
+				for j := 0; j < c.NamedChildCount(); j++ {
+					el := c.NamedChild(j)
+					var name string
+					switch el.Type(lang) {
+					case "const_element":
+						name = phpChildText(el, "name", lang, src)
+					case "property_element":
+						name = phpVariableName(el, lang, src)
+					}
+					if name != "" {
+						addExport(qualify(prefix, name), line)
+					}
+				}
+			case "enum_case":
+				if name := rustFieldText(c, "name", lang, src); name != "" {
+					addExport(qualify(prefix, name), line)
+				}
+			}
+		}
+	}
+
+	// walk visits the file-level statements in n: the program, a braced
+	// namespace's body, or the branches of an `if`.
+	var walk func(n *ts.Node, depth int)
+	walk = func(n *ts.Node, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			c := n.NamedChild(i)
+			kind := c.Type(lang)
+			span := src[c.StartByte():c.EndByte()]
+			line := int(c.StartPoint().Row) + 1
+
+			if phpTypeDecls[kind] {
+				name := rustFieldText(c, "name", lang, src)
+				if name == "" {
+					continue
+				}
+				classes = append(classes, name)
+				recordHash("class:"+name, span)
+				recordLine("class:"+name, line)
+				exports = append(exports, name)
+				if body := c.ChildByFieldName("body", lang); body != nil {
+					members(body, name)
+				}
+				continue
+			}
+
+			switch kind {
+			case "namespace_use_declaration":
+				imports = append(imports, phpUses(c, lang, src)...)
+
+			case "expression_statement":
+				if e := c.NamedChild(0); e != nil && phpIncludes[e.Type(lang)] {
+					if path := phpIncludePath(e, lang, src); path != "" {
+						imports = append(imports, path)
+					}
+				}
+
+			case "namespace_definition":
+				if name := phpChildText(c, "namespace_name", lang, src); name != "" {
+					addExport(name, line)
+				}
+				if body := c.ChildByFieldName("body", lang); body != nil {
+					walk(body, depth+1)
+				}
+
+			case "function_definition":
+				if name := rustFieldText(c, "name", lang, src); name != "" {
+					addFunction(name, span, line, true)
+				}
+
+			case "const_declaration":
+				for j := 0; j < c.NamedChildCount(); j++ {
+					if el := c.NamedChild(j); el.Type(lang) == "const_element" {
+						if name := phpChildText(el, "name", lang, src); name != "" {
+							addExport(name, line)
+						}
+					}
+				}
+
+			case "compound_statement", "if_statement", "else_clause", "else_if_clause", "colon_block", "ERROR":
+				// A conditional declaration, or a mid-edit buffer's ERROR node,
+				// can still hold whole declarations.
+				walk(c, depth+1)
+			}
+		}
+	}
+	walk(tree.RootNode(), 0)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, hashes, lines
+}
+
+// phpVisible reports whether a member is public: unmarked, or marked
+// `public`.
+func phpVisible(n *ts.Node, lang *ts.Language, src []byte) bool {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		if m := n.NamedChild(i); m.Type(lang) == "visibility_modifier" {
+			return strings.HasPrefix(m.Text(src), "public")
+		}
+	}
+	return true
+}
+
+// phpChildText returns the text of n's first named child of the given kind.
+func phpChildText(n *ts.Node, kind string, lang *ts.Language, src []byte) string {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Type(lang) == kind {
+			return c.Text(src)
+		}
+	}
+	return ""
+}
+
+// phpVariableName returns the name of n's variable, without its `$`.
+func phpVariableName(n *ts.Node, lang *ts.Language, src []byte) string {
+	return strings.TrimPrefix(phpChildText(n, "variable_name", lang, src), "$")
+}
+
+// phpUses returns the names a `use` declaration imports, with any group
+// expanded against its prefix and any `function`/`const` keyword kept.
+func phpUses(n *ts.Node, lang *ts.Language, src []byte) []string {
+	kind := ""
+	prefix := ""
+	type clause struct {
+		n       *ts.Node
+		grouped bool
+	}
+	var clauses []clause
+	for i := 0; i < n.ChildCount(); i++ {
+		c := n.Child(i)
+		switch c.Type(lang) {
+		case "function", "const":
+			kind = c.Type(lang) + " "
+		case "namespace_name":
+			prefix = c.Text(src) + `\`
+		case "namespace_use_clause":
+			clauses = append(clauses, clause{c, false})
+		case "namespace_use_group":
+			for j := 0; j < c.NamedChildCount(); j++ {
+				if g := c.NamedChild(j); g.Type(lang) == "namespace_use_clause" {
+					clauses = append(clauses, clause{g, true})
+				}
+			}
+		}
+	}
+	var out []string
+	for _, c := range clauses {
+		text := strings.Join(strings.Fields(c.n.Text(src)), " ")
+		ckind := kind
+		for _, kw := range []string{"function ", "const "} {
+			if strings.HasPrefix(text, kw) {
+				ckind, text = kw, strings.TrimPrefix(text, kw)
+				// The grammar hangs an ungrouped list's keyword on its first
+				// clause; it applies to the whole list.
+				if !c.grouped {
+					kind = kw
+				}
+			}
+		}
+		if text = strings.TrimPrefix(text, `\`); text != "" {
+			out = append(out, ckind+prefix+text)
+		}
+	}
+	return out
+}
+
+// phpIncludePath returns what a require/include expression loads: the string
+// when it is a plain literal, else the expression with whitespace collapsed.
+func phpIncludePath(e *ts.Node, lang *ts.Language, src []byte) string {
+	arg := e.NamedChild(0)
+	for arg != nil && arg.Type(lang) == "parenthesized_expression" {
+		arg = arg.NamedChild(0)
+	}
+	if arg == nil {
+		return ""
+	}
+	if t := arg.Type(lang); (t == "string" || t == "encapsed_string") && arg.NamedChildCount() <= 1 {
+		if arg.NamedChildCount() == 0 {
+			return ""
+		}
+		if c := arg.NamedChild(0); c.Type(lang) == "string_content" {
+			return c.Text(src)
+		}
+	}
+	return strings.Join(strings.Fields(arg.Text(src)), " ")
+}
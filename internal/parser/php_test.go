@@ -0,0 +1,199 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const phpSample = `<?php
+declare(strict_types=1);
+
+namespace App\Http;
+
+use Foo\Bar;
+use Foo\{Baz, Qux as Q};
+use function Foo\helper;
+use const Foo\LIMIT, Foo\OTHER;
+
+require_once __DIR__ . '/vendor/autoload.php';
+include 'x.php';
+require_once('b.php');
+
+const MAX = 3;
+
+function top(int $a): int { function inner() {} $f = function() {}; return $a; }
+
+if (!function_exists('helper')) {
+    function helper() {}
+}
+
+#[Attr]
+final class Reader extends Base implements Api
+{
+    use Loggable, Timestamps;
+    public const VERSION = '1';
+    private const SECRET = 's';
+    private int $x = 1;
+    public static $count, $other;
+    public function __construct(public readonly int $id, private int $y) {}
+    public function fetch(): ?string { return "function fake() {}"; }
+    protected function helper() {}
+    private static function hidden() {}
+    function implicit() {}
+}
+
+interface Api { public function fetch(): ?string; }
+trait Loggable { public function log($m) {} }
+enum Suit: string { case Hearts = 'H'; case Spades = 'S'; public function color() {} }
+abstract class A { abstract public function run(); }
+$anon = new class { public function x() {} };
+?>
+<html><?= $x ?></html>
+`
+
+func TestPHPParser_Extension(t *testing.T) {
+	p := NewPHPParser()
+	if !p.SupportsExtension(".php") {
+		t.Error("want .php supported")
+	}
+	if p.SupportsExtension(".phtml") || p.SupportsExtension(".inc") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestPHPParser_Symbols(t *testing.T) {
+	got, err := NewPHPParser().Parse(phpSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// Groups expand; a list's function/const keyword applies to every name;
+	// the trait use inside Reader is not an import.
+	wantImports := []string{
+		`Foo\Bar`, `Foo\Baz`, `Foo\Qux as Q`, `__DIR__ . '/vendor/autoload.php'`,
+		"b.php", `const Foo\LIMIT`, `const Foo\OTHER`, `function Foo\helper`, "x.php",
+	}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports:\n got %q\nwant %q", got.Imports, wantImports)
+	}
+	wantClasses := []string{"A", "Api", "Loggable", "Reader", "Suit"}
+	if !reflect.DeepEqual(got.Classes, wantClasses) {
+		t.Errorf("Classes:\n got %q\nwant %q", got.Classes, wantClasses)
+	}
+	// The nested function, closure, and anonymous class are not listed; the
+	// function declared under an if is.
+	wantFunctions := []string{
+		"A.run", "Api.fetch", "Loggable.log", "Reader.__construct", "Reader.fetch",
+		"Reader.helper", "Reader.hidden", "Reader.implicit", "Suit.color", "helper", "top",
+	}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+}
+
+// Exports are everything file-level plus public members; unmarked members are
+// public, and promoted constructor parameters are properties.
+func TestPHPParser_Visibility(t *testing.T) {
+	got, _ := NewPHPParser().Parse(phpSample)
+	wantExports := []string{
+		"A", "A.run", "Api", "Api.fetch", `App\Http`, "Loggable", "Loggable.log", "MAX",
+		"Reader", "Reader.VERSION", "Reader.__construct", "Reader.count", "Reader.fetch",
+		"Reader.id", "Reader.implicit", "Reader.other", "Suit", "Suit.Hearts", "Suit.Spades",
+		"Suit.color", "helper", "top",
+	}
+	if !reflect.DeepEqual(got.Exports, wantExports) {
+		t.Errorf("Exports:\n got %q\nwant %q", got.Exports, wantExports)
+	}
+
+	braced, _ := NewPHPParser().Parse("<?php\nnamespace Lib {\n  class C {}\n}\nnamespace {\n  function g() {}\n}\n")
+	if !reflect.DeepEqual(braced.Exports, []string{"C", "Lib", "g"}) || braced.SymbolLines["class:C"] != 3 {
+		t.Errorf("braced namespaces: exports %q, lines %v", braced.Exports, braced.SymbolLines)
+	}
+}
+
+func TestPHPParser_HashesAndLines(t *testing.T) {
+	got, _ := NewPHPParser().Parse(phpSample)
+	for key, want := range map[string]int{
+		"function:Reader.fetch": 32,
+		"class:Reader":          23, // attributes are part of the declaration
+		"export:Reader.id":      31,
+		"export:App\\Http":      4,
+		"function:helper":       20,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(phpSample, "return $a;", "return $a + 1;", 1)
+	after, _ := NewPHPParser().Parse(edited)
+	if after.SymbolHashes["function:top"] == got.SymbolHashes["function:top"] {
+		t.Error("editing top's body did not change its hash")
+	}
+	if after.SymbolHashes["function:Reader.fetch"] != got.SymbolHashes["function:Reader.fetch"] {
+		t.Error("unrelated symbol's hash changed")
+	}
+}
+
+func TestPHPParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "<?php\n" +
+		"// function commented() {}\n" +
+		"# class Hashed {}\n" +
+		"/* class Fake { } */\n" +
+		"$s = \"{$a['}']} function interp() {}\";\n" +
+		"$h = <<<EOT\nclass Heredoc { }\nEOT;\n" +
+		"?>\n<p>function html() {}</p>\n<?php\n" +
+		"function real() {}\n"
+	got, _ := NewPHPParser().Parse(src)
+	if !reflect.DeepEqual(got.Functions, []string{"real"}) || len(got.Classes) != 0 {
+		t.Errorf("Functions %q, Classes %q; want only real", got.Functions, got.Classes)
+	}
+}
+
+func TestPHPParser_CRLFParity(t *testing.T) {
+	lf, _ := NewPHPParser().Parse(phpSample)
+	crlf, _ := NewPHPParser().Parse(strings.ReplaceAll(phpSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestPHPParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "<?php class Broken {", "<?php \x00\xff", "no php here", "<?php }}}}"} {
+		got, err := NewPHPParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzPHPParser asserts the PHP parser never panics on arbitrary input and
+// keeps the invariants the other tree-sitter parsers keep.
+// Run: go test -run=x -fuzz=FuzzPHPParser ./internal/parser
+func FuzzPHPParser(f *testing.F) {
+	seeds := []string{
+		phpSample,
+		"<?php function f() {}",
+		"<?php namespace A { class B { function c() {} } }",
+		"<?php use A\\{B, function c, const D};",
+		"<?php enum E { case X; }",
+		"<?php require 'a.php';",
+		"<?php $x = <<<'EOT'\nunterminated",
+		"", "<?php", "<?php }}}", "<?php class A { public public }", "<?= $x",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewPHPParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -0,0 +1,245 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// ProtoParser implements structural parsing for .proto files using the
+// vendored pure-Go tree-sitter Protobuf grammar. A service's rpcs are the
+// contract its clients compile against, so they are indexed like functions.
+//
+// Symbol routing:
+//   - `message` / `enum` / `service` → Classes, nested-qualified
+//     ("Order.Line.Kind"). Names are not prefixed with the package, as the
+//     Java parser leaves out its package: a .proto refers to a type in its own
+//     package by the bare name.
+//   - `rpc` → Functions, qualified by its service ("OrderService.GetOrder").
+//   - `import` → Imports, the path as written ("google/protobuf/timestamp.proto").
+//     An `import public` also re-exports that file's definitions to whoever
+//     imports this one, which this file cannot enumerate, so its path is
+//     recorded in WildcardReexports too.
+//   - `package` → Exports, as the PHP parser exports a namespace: it is the
+//     name other packages reach these definitions through.
+//
+// Protobuf has no visibility, so every message, enum, service, and rpc is
+// also in Exports. Fields, enum values, options, and `extend` blocks are not
+// symbols.
+type ProtoParser struct{}
+
+// NewProtoParser creates a new Protobuf parser.
+func NewProtoParser() *ProtoParser { return &ProtoParser{} }
+
+// SupportsExtension returns true for .proto files.
+func (p *ProtoParser) SupportsExtension(ext string) bool {
+	return ext == ".proto"
+}
+
+var (
+	protoLangOnce sync.Once
+	protoLang     *ts.Language
+)
+
+func protoLanguage() *ts.Language {
+	protoLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("Protobuf", r)
+			}
+		}()
+		protoLang = grammars.ProtoLanguage()
+	})
+	return protoLang
+}
+
+// Parse extracts structure from Protobuf source via tree-sitter. Best-effort
+// on parse errors: tree-sitter recovers to a partial tree for most mid-edit
+// buffers, and we walk whatever it produced.
+func (p *ProtoParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, reexports, hashes, lines := protoSymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	fs := FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}
+	if len(reexports) > 0 {
+		sort.Strings(reexports)
+		fs.WildcardReexports = deduplicate(reexports)
+	}
+	return fs, nil
+}
+
+// protoTypeDecls maps each type declaration to the node kind holding its name.
+var protoTypeDecls = map[string]string{
+	"message": "message_name",
+	"enum":    "enum_name",
+	"service": "service_name",
+}
+
+func protoSymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports, reexports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("Protobuf parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			reexports, hashes, lines = nil, nil, nil
+		}
+	}()
+
+	lang := protoLanguage()
+	if lang == nil {
+		diag.noGrammar("Protobuf")
+		return imports, functions, classes, exports, nil, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("Protobuf source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil, nil
+	}
+	// As for Ruby: an ERROR root means nothing below it is a declaration, so
+	// say the symbols are missing rather than let them read as absent.
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("Protobuf file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+
+	// recordHash combines on collision: a buffer declaring one name twice
+	// mid-edit.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+	recordLine := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+
+	// walk visits the declarations in n. prefix is the enclosing message's or
+	// service's qualified name.
+	var walk func(n *ts.Node, prefix string, depth int)
+	walk = func(n *ts.Node, prefix string, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			c := n.NamedChild(i)
+			kind := c.Type(lang)
+			span := src[c.StartByte():c.EndByte()]
+			line := int(c.StartPoint().Row) + 1
+
+			if nameKind, ok := protoTypeDecls[kind]; ok {
+				name := protoChildText(c, nameKind, lang, src)
+				if name == "" {
+					continue
+				}
+				full := qualify(prefix, name)
+				classes = append(classes, full)
+				exports = append(exports, full)
+				recordHash("class:"+full, span)
+				recordLine("class:"+full, line)
+				if kind == "service" {
+					walk(c, full, depth+1)
+					continue
+				}
+				for j := 0; j < c.NamedChildCount(); j++ {
+					if b := c.NamedChild(j); b.Type(lang) == "message_body" {
+						walk(b, full, depth+1)
+					}
+				}
+				continue
+			}
+
+			switch kind {
+			case "rpc":
+				if name := protoChildText(c, "rpc_name", lang, src); name != "" {
+					full := qualify(prefix, name)
+					functions = append(functions, full)
+					exports = append(exports, full)
+					recordHash("function:"+full, span)
+					recordLine("function:"+full, line)
+				}
+
+			case "import":
+				path := c.ChildByFieldName("path", lang)
+				if path == nil {
+					continue
+				}
+				imp := strings.Trim(path.Text(src), `"'`)
+				if imp == "" {
+					continue
+				}
+				imports = append(imports, imp)
+				for j := 0; j < c.ChildCount(); j++ {
+					if c.Child(j).Type(lang) == "public" {
+						reexports = append(reexports, imp)
+					}
+				}
+
+			case "package":
+				if name := strings.Join(strings.Fields(protoChildText(c, "full_ident", lang, src)), ""); name != "" {
+					exports = append(exports, name)
+					recordLine("export:"+name, line)
+				}
+
+			case "ERROR":
+				// A mid-edit buffer's ERROR node can still hold whole
+				// declarations.
+				walk(c, prefix, depth+1)
+			}
+		}
+	}
+	walk(tree.RootNode(), "", 0)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, reexports, hashes, lines
+}
+
+// protoChildText returns the text of n's first named child of the given kind;
+// the Protobuf grammar names few fields.
+func protoChildText(n *ts.Node, kind string, lang *ts.Language, src []byte) string {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Type(lang) == kind {
+			return c.Text(src)
+		}
+	}
+	return ""
+}
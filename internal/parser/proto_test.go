@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const protoSample = `syntax = "proto3";
+
+package acme.orders.v1;
+
+import "google/protobuf/timestamp.proto";
+import public "common/money.proto";
+option go_package = "example.com/orders";
+
+// message Commented {}
+message Order {
+  string id = 1;
+  Money total = 2;
+  message Line {
+    string sku = 1;
+    enum Kind { KIND_UNSPECIFIED = 0; }
+  }
+  repeated Line lines = 3;
+  oneof source { string web = 4; string app = 5; }
+  map<string, string> labels = 6;
+  reserved 7;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_OPEN = 1 [deprecated = true];
+}
+
+service OrderService {
+  option (google.api.default_host) = "orders";
+  rpc GetOrder(GetOrderRequest) returns (Order);
+  rpc Watch(stream WatchRequest) returns (stream Order) {
+    option (google.api.http) = { get: "/v1/orders" };
+  }
+}
+
+extend google.protobuf.FieldOptions { string note = 50000; }
+`
+
+func TestProtoParser_Extension(t *testing.T) {
+	p := NewProtoParser()
+	if !p.SupportsExtension(".proto") {
+		t.Error("want .proto supported")
+	}
+	if p.SupportsExtension(".pb") || p.SupportsExtension(".go") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestProtoParser_Symbols(t *testing.T) {
+	got, err := NewProtoParser().Parse(protoSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	wantImports := []string{"common/money.proto", "google/protobuf/timestamp.proto"}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports:\n got %q\nwant %q", got.Imports, wantImports)
+	}
+	// Nested types are qualified by their enclosing message; the commented
+	// message, fields, enum values, and the extend block are not symbols.
+	wantClasses := []string{"Order", "Order.Line", "Order.Line.Kind", "OrderService", "Status"}
+	if !reflect.DeepEqual(got.Classes, wantClasses) {
+		t.Errorf("Classes:\n got %q\nwant %q", got.Classes, wantClasses)
+	}
+	wantFunctions := []string{"OrderService.GetOrder", "OrderService.Watch"}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+}
+
+// Protobuf has no visibility: every declaration is exported, and so is the
+// package. Only an `import public` re-exports its file.
+func TestProtoParser_Visibility(t *testing.T) {
+	got, _ := NewProtoParser().Parse(protoSample)
+	wantExports := []string{
+		"Order", "Order.Line", "Order.Line.Kind", "OrderService", "OrderService.GetOrder", "OrderService.Watch",
+		"Status", "acme.orders.v1",
+	}
+	if !reflect.DeepEqual(got.Exports, wantExports) {
+		t.Errorf("Exports:\n got %q\nwant %q", got.Exports, wantExports)
+	}
+	if want := []string{"common/money.proto"}; !reflect.DeepEqual(got.WildcardReexports, want) {
+		t.Errorf("WildcardReexports = %q, want %q", got.WildcardReexports, want)
+	}
+}
+
+func TestProtoParser_HashesAndLines(t *testing.T) {
+	got, _ := NewProtoParser().Parse(protoSample)
+	for key, want := range map[string]int{
+		"export:acme.orders.v1":          3,
+		"class:Order":                    10,
+		"class:Order.Line.Kind":          15,
+		"class:OrderService":             28,
+		"function:OrderService.GetOrder": 30,
+		"function:OrderService.Watch":    31,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(protoSample, "returns (Order);", "returns (OrderReply);", 1)
+	after, _ := NewProtoParser().Parse(edited)
+	if after.SymbolHashes["function:OrderService.GetOrder"] == got.SymbolHashes["function:OrderService.GetOrder"] {
+		t.Error("editing the rpc signature did not change the symbol hash")
+	}
+	if after.SymbolHashes["function:OrderService.Watch"] != got.SymbolHashes["function:OrderService.Watch"] {
+		t.Error("unrelated symbol's hash changed")
+	}
+}
+
+func TestProtoParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "// message Line {}\n" +
+		"/* service Block { rpc Fake(A) returns (B); } */\n" +
+		"option note = \"message Quoted {}\";\n" +
+		"message Real {}\n"
+	got, _ := NewProtoParser().Parse(src)
+	if !reflect.DeepEqual(got.Classes, []string{"Real"}) || len(got.Functions) != 0 {
+		t.Errorf("Classes %q, Functions %q; want only Real", got.Classes, got.Functions)
+	}
+}
+
+func TestProtoParser_CRLFParity(t *testing.T) {
+	lf, _ := NewProtoParser().Parse(protoSample)
+	crlf, _ := NewProtoParser().Parse(strings.ReplaceAll(protoSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestProtoParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "message Broken {", "service \x00\xff", "} } }"} {
+		got, err := NewProtoParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzProtoParser asserts the Protobuf parser never panics on arbitrary input
+// and keeps the invariants the other tree-sitter parsers keep.
+// Run: go test -run=x -fuzz=FuzzProtoParser ./internal/parser
+func FuzzProtoParser(f *testing.F) {
+	seeds := []string{
+		protoSample,
+		"message A { message B { message C {} } }",
+		"service S { rpc M(stream A) returns (stream B) {} }",
+		"syntax = \"proto2\"; message G { optional group R = 1 {} }",
+		"import weak \"x.proto\";",
+		"enum E { option allow_alias = true; A = 0; B = 0; }",
+		"", "message", "service", "rpc rpc rpc", "/*",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewProtoParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -3,8 +3,6 @@ package parser
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"fmt"
-	"os"
 	"regexp"
 	"sort"
 	"strings"
@@ -79,7 +77,7 @@ func pythonLanguage() *ts.Language {
 		// the nil-language path (no AST symbols) instead, which is fail-safe.
 		defer func() {
 			if r := recover(); r != nil {
-				fmt.Fprintf(os.Stderr, "runecho: Python grammar failed to load (%v); Python symbols disabled\n", r)
+				grammarFailed("Python", r)
 			}
 		}()
 		pyLang = grammars.PythonLanguage()
@@ -97,7 +95,8 @@ func (p *PythonParser) Parse(source string) (FileStructure, error) {
 	source = strings.ReplaceAll(source, "\r\n", "\n")
 
 	imports, exports, hasAll := pyImportsAndExports(source)
-	functions, classes, hashes, lines := pySymbolsFromAST(source)
+	var diag diagnostics
+	functions, classes, hashes, lines, cols, docs := pySymbolsFromAST(source, &diag)
 
 	// When __all__ is absent, a module's public surface is conventionally its
 	// non-underscore top-level names (the rule `from m import *`, PEP 8, and
@@ -116,12 +115,15 @@ func (p *PythonParser) Parse(source string) (FileStructure, error) {
 	// Dedupe after sorting (parity with the Go/JS parsers): a top-level name can
 	// legitimately repeat across conditional def/class blocks.
 	return FileStructure{
-		Imports:      deduplicate(imports),
-		Functions:    deduplicate(functions),
-		Classes:      deduplicate(classes),
-		Exports:      deduplicate(exports),
-		SymbolHashes: hashes,
-		SymbolLines:  lines,
+		Imports:       deduplicate(imports),
+		Functions:     deduplicate(functions),
+		Classes:       deduplicate(classes),
+		Exports:       deduplicate(exports),
+		SymbolHashes:  hashes,
+		SymbolLines:   lines,
+		SymbolColumns: cols,
+		Docs:          docs,
+		Diagnostics:   diag,
 	}, nil
 }
 
@@ -202,8 +204,9 @@ func isPyPublicTopLevel(qualified string) bool {
 // diffing — issue #53 added class-level hashing so a class-body change that its
 // members don't otherwise surface (e.g. an edited class-level field) is still
 // detected as a modification. lines carries each symbol's 1-based start line, keyed
-// "kind:<qualified name>", for the repo map.
-func pySymbolsFromAST(source string) (functions, classes []string, hashes map[string]string, lines map[string]int) {
+// "kind:<qualified name>", for the repo map, cols its 1-based start column, and
+// docs the first sentence of its docstring, under the same key.
+func pySymbolsFromAST(source string, diag *diagnostics) (functions, classes []string, hashes map[string]string, lines, cols map[string]int, docs map[string]string) {
 	// The pure-Go tree-sitter runtime can panic on adversarial or malformed
 	// input; a panic here would otherwise propagate through parseFile→Generate
 	// and crash the indexer/MCP server. Recover and degrade to no AST symbols
@@ -212,31 +215,34 @@ func pySymbolsFromAST(source string) (functions, classes []string, hashes map[st
 	// partial, inconsistent symbol set.
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Fprintf(os.Stderr, "runecho: Python parse panicked (%v); AST symbols for this file disabled\n", r)
-			functions, classes, hashes, lines = nil, nil, nil, nil
+			diag.addf("Python parse panicked (%v); AST symbols for this file disabled", r)
+			functions, classes, hashes, lines, cols, docs = nil, nil, nil, nil, nil, nil
 		}
 	}()
 	lang := pythonLanguage()
 	if lang == nil {
+		diag.noGrammar("Python")
 		// Grammar unavailable (e.g. a grammar_subset build that omitted Python).
 		// Degrade to no AST symbols rather than panicking; imports/exports still
 		// come from the regex pass.
-		return nil, nil, nil, nil
+		return nil, nil, nil, nil, nil, nil
 	}
 	src := []byte(source)
 	// Reject pathologically-nested input before the super-linear tree-sitter
 	// parse can hang the process; degrade to no AST symbols (see maxParseNestDepth).
 	if exceedsNestDepth(src) {
-		fmt.Fprintf(os.Stderr, "runecho: Python source exceeds max nesting depth (%d); AST symbols for this file disabled\n", maxParseNestDepth)
-		return nil, nil, nil, nil
+		diag.addf("Python source exceeds max nesting depth (%d); AST symbols for this file disabled", maxParseNestDepth)
+		return nil, nil, nil, nil, nil, nil
 	}
 	tree, err := ts.NewParser(lang).Parse(src)
 	if err != nil || tree == nil || tree.RootNode() == nil {
-		return nil, nil, nil, nil
+		return nil, nil, nil, nil, nil, nil
 	}
 
 	hashes = make(map[string]string)
 	lines = make(map[string]int)
+	cols = make(map[string]int)
+	docs = make(map[string]string)
 
 	// recordHash stores a function's body hash. If the qualified name already has
 	// one (e.g. an @property getter/setter/deleter, or conditional def branches —
@@ -252,9 +258,10 @@ func pySymbolsFromAST(source string) (functions, classes []string, hashes map[st
 	}
 	// recordLine anchors a symbol at its FIRST definition; later same-name
 	// variants don't move the anchor.
-	recordLine := func(key string, line int) {
+	recordLine := func(key string, at ts.Point) {
 		if _, ok := lines[key]; !ok {
-			lines[key] = line
+			lines[key] = int(at.Row) + 1
+			cols[key] = int(at.Column) + 1
 		}
 	}
 
@@ -269,19 +276,26 @@ func pySymbolsFromAST(source string) (functions, classes []string, hashes map[st
 			return
 		}
 		full := qualify(prefix, name)
+		var key string
 		switch defNode.Type(lang) {
 		case "function_definition":
 			functions = append(functions, full)
-			recordHash("function:"+full, src[spanNode.StartByte():spanNode.EndByte()])
-			recordLine("function:"+full, int(spanNode.StartPoint().Row)+1)
+			key = "function:" + full
 		case "class_definition":
 			classes = append(classes, full)
-			recordHash("class:"+full, src[spanNode.StartByte():spanNode.EndByte()])
-			recordLine("class:"+full, int(spanNode.StartPoint().Row)+1)
+			key = "class:" + full
 		default:
 			return
 		}
+		recordHash(key, src[spanNode.StartByte():spanNode.EndByte()])
+		recordLine(key, spanNode.StartPoint())
 		if body := defNode.ChildByFieldName("body", lang); body != nil {
+			// Like the line, the doc is the first definition's.
+			if _, ok := docs[key]; !ok {
+				if doc := pyDocstring(body, lang, src); doc != "" {
+					docs[key] = doc
+				}
+			}
 			walk(body, full, depth+1)
 		}
 	}
@@ -319,9 +333,43 @@ func pySymbolsFromAST(source string) (functions, classes []string, hashes map[st
 		hashes = nil
 	}
 	if len(lines) == 0 {
-		lines = nil
+		lines, cols = nil, nil
+	}
+	if len(docs) == 0 {
+		docs = nil
+	}
+	return functions, classes, hashes, lines, cols, docs
+}
+
+// pyDocstring returns the first sentence of the docstring opening body — a
+// string literal as its first statement — or "" if it has none. An f-string
+// is an expression, and a bytes literal is not text, so neither is a
+// docstring.
+func pyDocstring(body *ts.Node, lang *ts.Language, src []byte) string {
+	if body.NamedChildCount() == 0 {
+		return ""
 	}
-	return functions, classes, hashes, lines
+	// The grammar hangs a bare string straight off the block; allow the
+	// expression_statement wrapper the full grammar has too.
+	str := body.NamedChild(0)
+	if str.Type(lang) == "expression_statement" && str.NamedChildCount() == 1 {
+		str = str.NamedChild(0)
+	}
+	if str.Type(lang) != "string" {
+		return ""
+	}
+	text := str.Text(src)
+	open := strings.IndexAny(text, `'"`)
+	if open < 0 || strings.ContainsAny(strings.ToLower(text[:open]), "fb") {
+		return ""
+	}
+	text = text[open:]
+	for _, q := range []string{`"""`, "'''", `"`, `'`} {
+		if len(text) >= 2*len(q) && strings.HasPrefix(text, q) && strings.HasSuffix(text, q) {
+			return docSummary(text[len(q) : len(text)-len(q)])
+		}
+	}
+	return ""
 }
 
 func qualify(prefix, name string) string {
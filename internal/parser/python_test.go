@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"reflect"
 	"slices"
 	"strings"
 	"testing"
@@ -478,3 +479,49 @@ func TestPythonParser_AllComparisonDoesNotSuppressFallback(t *testing.T) {
 		t.Errorf("fallback exports suppressed by an __all__ comparison: %v", fs.Exports)
 	}
 }
+
+// A docstring's first sentence is the symbol's doc; a string that is not the
+// body's first statement, or is an f-string, is not a docstring.
+func TestPythonParser_Docs(t *testing.T) {
+	source := `def load(path):
+    """Load a config file.
+
+    Details follow.
+    """
+
+class Store:
+    r'''Holds
+    the state.'''
+
+    @property
+    def size(self):
+        'Number of entries'
+        return 0
+
+    def greet(self):
+        f"hello {self}"
+
+    def late(self):
+        x = 1
+        "not a docstring"
+`
+	got, _ := NewPythonParser().Parse(source)
+	want := map[string]string{
+		"function:load":       "Load a config file.",
+		"class:Store":         "Holds the state.",
+		"function:Store.size": "Number of entries",
+	}
+	if !reflect.DeepEqual(got.Docs, want) {
+		t.Errorf("Docs = %v, want %v", got.Docs, want)
+	}
+}
+
+func TestPythonParser_SymbolColumns(t *testing.T) {
+	src := "def top():\n    pass\n\nclass Tool:\n    def run(self):\n        pass\n"
+	got, _ := NewPythonParser().Parse(src)
+	for key, want := range map[string]int{"function:top": 1, "class:Tool": 1, "function:Tool.run": 5} {
+		if got.SymbolColumns[key] != want {
+			t.Errorf("%s column = %d, want %d", key, got.SymbolColumns[key], want)
+		}
+	}
+}
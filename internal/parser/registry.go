@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"maps"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	registeredMu sync.RWMutex
+	registered   = map[string]Parser{}
+)
+
+// Register makes p the parser for files with extension ext (".foo") in every
+// Generator created afterwards, so a program that links in a parser for a
+// proprietary language indexes it from every entry point — CLI, MCP server,
+// guard hook — without a .runecho.json. It comes ahead of the built-ins and
+// of GeneratorConfig.Parsers, behind a GeneratorConfig.Extensions mapping.
+// p need not claim ext in SupportsExtension, and must be safe for concurrent
+// use. Call it from an init func; it panics on a nil parser, an extension
+// filepath.Ext would never return, or one already registered.
+func Register(ext string, p Parser) {
+	if p == nil {
+		panic("parser: Register parser is nil")
+	}
+	if len(ext) < 2 || filepath.Ext(ext) != ext || filepath.Base(ext) != ext {
+		panic("parser: Register invalid extension " + ext)
+	}
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	if _, dup := registered[ext]; dup {
+		panic("parser: Register called twice for extension " + ext)
+	}
+	registered[ext] = p
+}
+
+// Registered returns a snapshot of the parsers added with Register, by
+// extension.
+func Registered() map[string]Parser {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+	return maps.Clone(registered)
+}
+
+// Registry dispatches a file to its parser. The parsers are consulted in the
+// order they were given, and the first to accept the file's extension
+// (SupportsExtension) or, as a FilenameParser, its base name handles it, so
+// putting one parser ahead of another is how it takes over an extension.
+//
+// A parser's answer for an extension is taken to be fixed: the first lookup
+// of each extension scans the parsers and the rest reuse the result, which
+// keeps dispatch cheap on a tree of thousands of files. A Registry is safe
+// for concurrent use.
+type Registry struct {
+	parsers []Parser
+	// named holds the positions of the FilenameParsers among parsers.
+	named []int
+	mu    sync.Mutex
+	byExt map[string]int // extension → first parser accepting it, or len(parsers)
+}
+
+// NewRegistry returns a Registry consulting parsers in order.
+func NewRegistry(parsers ...Parser) *Registry {
+	r := &Registry{parsers: append([]Parser(nil), parsers...), byExt: make(map[string]int)}
+	for i, p := range r.parsers {
+		if _, ok := p.(FilenameParser); ok {
+			r.named = append(r.named, i)
+		}
+	}
+	return r
+}
+
+// Lookup returns the parser for the file at path, by its extension and base
+// name, and that parser's position in the registry's order; nil and -1 when
+// no parser handles the file.
+func (r *Registry) Lookup(path string) (Parser, int) {
+	first := r.forExt(filepath.Ext(path))
+	name := filepath.Base(path)
+	for _, i := range r.named {
+		if i >= first {
+			break
+		}
+		if r.parsers[i].(FilenameParser).SupportsFilename(name) {
+			return r.parsers[i], i
+		}
+	}
+	if first == len(r.parsers) {
+		return nil, -1
+	}
+	return r.parsers[first], first
+}
+
+// forExt is the position of the first parser that accepts ext.
+func (r *Registry) forExt(ext string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i, ok := r.byExt[ext]; ok {
+		return i
+	}
+	i := 0
+	for ; i < len(r.parsers); i++ {
+		if r.parsers[i].SupportsExtension(ext) {
+			break
+		}
+	}
+	r.byExt[ext] = i
+	return i
+}
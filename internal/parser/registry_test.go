@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+// countingParser claims one extension and counts how often it is asked.
+type countingParser struct {
+	ext   string
+	asked int
+}
+
+func (p *countingParser) Parse(string) (FileStructure, error) { return FileStructure{}, nil }
+func (p *countingParser) SupportsExtension(ext string) bool {
+	p.asked++
+	return ext == p.ext
+}
+
+// TestRegistry_Lookup: the first parser to claim a file by extension or by
+// name handles it, a later one never; a name claim yields to an earlier
+// extension claim; extensions are scanned once.
+func TestRegistry_Lookup(t *testing.T) {
+	plugin := &countingParser{ext: ".json"}
+	r := NewRegistry(plugin, NewGoParser(), NewDockerfileParser(), NewManifestParser())
+	for _, tc := range []struct {
+		path string
+		want int
+	}{
+		{"cmd/main.go", 1},
+		{"build/Dockerfile", 2},
+		{"api.dockerfile", 2},
+		{"web/package.json", 0}, // the plugin claims .json ahead of the manifest parser's name claim
+		{"go.mod", 3},
+		{"notes.txt", -1},
+	} {
+		p, i := r.Lookup(tc.path)
+		if i != tc.want || (i < 0) != (p == nil) {
+			t.Errorf("Lookup(%q) = %T at %d, want position %d", tc.path, p, i, tc.want)
+		}
+	}
+	asked := plugin.asked
+	for range 3 {
+		r.Lookup("other/main.go")
+		r.Lookup("requirements.txt")
+	}
+	if plugin.asked != asked {
+		t.Errorf("the plugin was asked %d more times about extensions already seen", plugin.asked-asked)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	p := &countingParser{ext: ".zzreg"}
+	Register(".zzreg", p)
+	for name, reg := range map[string]func(){
+		"nil":       func() { Register(".x", nil) },
+		"no dot":    func() { Register("x", p) },
+		"bare dot":  func() { Register(".", p) },
+		"two dots":  func() { Register(".d.ts", p) },
+		"slash":     func() { Register("./x", p) },
+		"duplicate": func() { Register(".zzreg", p) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: Register did not panic", name)
+				}
+			}()
+			reg()
+		}()
+	}
+	if got := Registered(); !reflect.DeepEqual(got, map[string]Parser{".zzreg": p}) {
+		t.Errorf("Registered = %v", got)
+	}
+}
@@ -1,8 +1,6 @@
 package parser
 
 import (
-	"fmt"
-	"os"
 	"sort"
 	"strings"
 	"sync"
@@ -77,7 +75,7 @@ func rubyLanguage() *ts.Language {
 		// panic to escape forever.
 		defer func() {
 			if r := recover(); r != nil {
-				fmt.Fprintf(os.Stderr, "runecho: Ruby grammar failed to load (%v); Ruby symbols disabled\n", r)
+				grammarFailed("Ruby", r)
 			}
 		}()
 		rubyLang = grammars.RubyLanguage()
@@ -92,7 +90,8 @@ func (p *RubyParser) Parse(source string) (FileStructure, error) {
 	// Normalize line endings so hashes and start lines are style-independent.
 	source = strings.ReplaceAll(source, "\r\n", "\n")
 
-	imports, functions, classes, exports, hashes, lines := rubySymbolsFromAST(source)
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := rubySymbolsFromAST(source, &diag)
 
 	sort.Strings(imports)
 	sort.Strings(functions)
@@ -106,6 +105,7 @@ func (p *RubyParser) Parse(source string) (FileStructure, error) {
 		Exports:      deduplicate(exports),
 		SymbolHashes: hashes,
 		SymbolLines:  lines,
+		Diagnostics:  diag,
 	}, nil
 }
 
@@ -120,7 +120,7 @@ var rubyAttrFns = map[string]struct{ reader, writer bool }{
 	"attr_writer":   {false, true},
 }
 
-func rubySymbolsFromAST(source string) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+func rubySymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
 	// Non-nil so a symbol-less file yields [] rather than null.
 	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
 
@@ -128,7 +128,7 @@ func rubySymbolsFromAST(source string) (imports, functions, classes, exports []s
 	// no symbols rather than taking down the indexer or MCP server.
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Fprintf(os.Stderr, "runecho: Ruby parse panicked (%v); symbols for this file disabled\n", r)
+			diag.addf("Ruby parse panicked (%v); symbols for this file disabled", r)
 			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
 			hashes, lines = nil, nil
 		}
@@ -136,11 +136,12 @@ func rubySymbolsFromAST(source string) (imports, functions, classes, exports []s
 
 	lang := rubyLanguage()
 	if lang == nil {
+		diag.noGrammar("Ruby")
 		return imports, functions, classes, exports, nil, nil
 	}
 	src := []byte(source)
 	if exceedsNestDepth(src) {
-		fmt.Fprintf(os.Stderr, "runecho: Ruby source exceeds max nesting depth (%d); symbols for this file disabled\n", maxParseNestDepth)
+		diag.addf("Ruby source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
 		return imports, functions, classes, exports, nil, nil
 	}
 	tree, err := ts.NewParser(lang).Parse(src)
@@ -152,16 +153,11 @@ func rubySymbolsFromAST(source string) (imports, functions, classes, exports []s
 	// nodes, so the walk below silently yields nothing. For an existence
 	// checker, "this file has no symbols" and "I could not read this file" are
 	// very different claims, and conflating them is the direction that hurts:
-	// a consumer would conclude the symbols don't exist. Warn so the gap is
-	// visible, then continue best-effort — a partial tree is still worth walking.
-	//
-	// The warning carries no file path because Parser.Parse does not receive one
-	// — the same limitation the Python and Rust parsers' panic and nest-depth
-	// warnings have. Threading a path through the interface for a warning is not
-	// worth the churn; the generator already prefixes its own per-file warnings
-	// with the path, so this line reads as an unattributed detail next to them.
+	// a consumer would conclude the symbols don't exist. Say so, as a
+	// diagnostic the generator reports under the file's path, then continue
+	// best-effort — a partial tree is still worth walking.
 	if tree.RootNode().Type(lang) == "ERROR" {
-		fmt.Fprintf(os.Stderr, "runecho: Ruby file did not parse (grammar returned ERROR at root); its symbols are missing, not absent\n")
+		diag.addf("Ruby file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
 	}
 
 	hashes = make(map[string]string)
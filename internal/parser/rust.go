@@ -1,8 +1,6 @@
 package parser
 
 import (
-	"fmt"
-	"os"
 	"sort"
 	"strings"
 	"sync"
@@ -85,7 +83,7 @@ func rustLanguage() *ts.Language {
 		// nil-language path (no symbols), which is fail-safe.
 		defer func() {
 			if r := recover(); r != nil {
-				fmt.Fprintf(os.Stderr, "runecho: Rust grammar failed to load (%v); Rust symbols disabled\n", r)
+				grammarFailed("Rust", r)
 			}
 		}()
 		rustLang = grammars.RustLanguage()
@@ -102,7 +100,8 @@ func (p *RustParser) Parse(source string) (FileStructure, error) {
 	// one, and per-symbol body hashes must not depend on line-ending style.
 	source = strings.ReplaceAll(source, "\r\n", "\n")
 
-	imports, functions, classes, exports, hashes, lines := rustSymbolsFromAST(source)
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := rustSymbolsFromAST(source, &diag)
 
 	sort.Strings(imports)
 	sort.Strings(functions)
@@ -116,13 +115,14 @@ func (p *RustParser) Parse(source string) (FileStructure, error) {
 		Exports:      deduplicate(exports),
 		SymbolHashes: hashes,
 		SymbolLines:  lines,
+		Diagnostics:  diag,
 	}, nil
 }
 
 // rustSymbolsFromAST does the actual walk. Split out so the panic recovery has a
 // single place to reset every named return — a panic mid-walk must not leak a
 // partial, inconsistent symbol set into the IR.
-func rustSymbolsFromAST(source string) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+func rustSymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
 	// Initialize non-nil so a file with no symbols yields [] rather than null,
 	// matching the contract the other parsers honor.
 	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
@@ -133,7 +133,7 @@ func rustSymbolsFromAST(source string) (imports, functions, classes, exports []s
 	// file can't take down the process.
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Fprintf(os.Stderr, "runecho: Rust parse panicked (%v); symbols for this file disabled\n", r)
+			diag.addf("Rust parse panicked (%v); symbols for this file disabled", r)
 			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
 			hashes, lines = nil, nil
 		}
@@ -141,6 +141,7 @@ func rustSymbolsFromAST(source string) (imports, functions, classes, exports []s
 
 	lang := rustLanguage()
 	if lang == nil {
+		diag.noGrammar("Rust")
 		// Grammar unavailable (e.g. a grammar_subset build that omitted Rust).
 		return imports, functions, classes, exports, nil, nil
 	}
@@ -148,7 +149,7 @@ func rustSymbolsFromAST(source string) (imports, functions, classes, exports []s
 	// Reject pathologically-nested input before the super-linear tree-sitter
 	// parse can hang the process (see maxParseNestDepth).
 	if exceedsNestDepth(src) {
-		fmt.Fprintf(os.Stderr, "runecho: Rust source exceeds max nesting depth (%d); symbols for this file disabled\n", maxParseNestDepth)
+		diag.addf("Rust source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
 		return imports, functions, classes, exports, nil, nil
 	}
 	tree, err := ts.NewParser(lang).Parse(src)
@@ -80,6 +80,9 @@ func assertSpanKeysNameRealSymbols(t *testing.T, fs FileStructure) {
 	for _, n := range fs.Exports {
 		listed["export:"+n] = true
 	}
+	for _, n := range fs.Imports {
+		listed["import:"+n] = true // manifests hash their dependencies
+	}
 	for _, m := range []map[string]string{fs.SymbolHashes} {
 		for k := range m {
 			if !listed[k] {
@@ -92,6 +95,16 @@ func assertSpanKeysNameRealSymbols(t *testing.T, fs FileStructure) {
 			t.Fatalf("SymbolLines key %q names no listed symbol", k)
 		}
 	}
+	for k, col := range fs.SymbolColumns {
+		if _, ok := fs.SymbolLines[k]; !ok || col < 1 {
+			t.Fatalf("SymbolColumns key %q has no line or column %d is not 1-based", k, col)
+		}
+	}
+	for k, doc := range fs.Docs {
+		if !listed[k] || doc == "" {
+			t.Fatalf("Docs key %q names no listed symbol or has an empty doc", k)
+		}
+	}
 }
 
 // Same input must always produce the same output — RunEcho's whole contract is a
@@ -106,6 +119,31 @@ func TestParsersAreDeterministic(t *testing.T) {
 	}{
 		{"rust", NewRustParser(), rustSample},
 		{"ruby", NewRubyParser(), rubySample},
+		{"java", NewJavaParser(), javaSample},
+		{"csharp", NewCSharpParser(), csharpSample},
+		{"php", NewPHPParser(), phpSample},
+		{"kotlin", NewKotlinParser(), kotlinSample},
+		{"swift", NewSwiftParser(), swiftSample},
+		{"scala", NewScalaParser(), scalaSample},
+		{"dart", NewDartParser(), dartSample},
+		{"elixir", NewElixirParser(), elixirSample},
+		{"lua", NewLuaParser(), luaSample},
+		{"sql", NewSQLParser(), sqlSample},
+		{"proto", NewProtoParser(), protoSample},
+		{"dockerfile", NewDockerfileParser(), dockerfileSample},
+		{"vue", NewVueParser(), vueSample},
+		{"svelte", NewSvelteParser(), svelteSample},
+		{"html", NewHTMLParser(), htmlSample},
+		{"css", NewCSSParser(), scssSample},
+		{"markdown", NewMarkdownParser(), markdownSample},
+		{"notebook", NewNotebookParser(), notebookSample},
+		{"solidity", NewSolidityParser(), soliditySample},
+		{"openapi", NewOpenAPIParser(), openAPISample},
+		{"swagger", NewOpenAPIParser(), swaggerSample},
+		{"package.json", NewManifestParser(), packageJSONSample},
+		{"go.mod", NewManifestParser(), goModSample},
+		{"requirements.txt", NewManifestParser(), requirementsSample},
+		{"tree-sitter js", NewTreeSitterParser(), treeSitterJSSample},
 	}
 	for _, c := range cases {
 		first, err := c.p.Parse(c.src)
@@ -131,4 +169,48 @@ func TestSpanKeysNameRealSymbols_Samples(t *testing.T) {
 	assertSpanKeysNameRealSymbols(t, rs)
 	rb, _ := NewRubyParser().Parse(rubySample)
 	assertSpanKeysNameRealSymbols(t, rb)
+	jv, _ := NewJavaParser().Parse(javaSample)
+	assertSpanKeysNameRealSymbols(t, jv)
+	cs, _ := NewCSharpParser().Parse(csharpSample)
+	assertSpanKeysNameRealSymbols(t, cs)
+	ph, _ := NewPHPParser().Parse(phpSample)
+	assertSpanKeysNameRealSymbols(t, ph)
+	kox, _ := NewKotlinParser().Parse(kotlinSample)
+	assertSpanKeysNameRealSymbols(t, kox)
+	swx, _ := NewSwiftParser().Parse(swiftSample)
+	assertSpanKeysNameRealSymbols(t, swx)
+	scx, _ := NewScalaParser().Parse(scalaSample)
+	assertSpanKeysNameRealSymbols(t, scx)
+	dax, _ := NewDartParser().Parse(dartSample)
+	assertSpanKeysNameRealSymbols(t, dax)
+	elx, _ := NewElixirParser().Parse(elixirSample)
+	assertSpanKeysNameRealSymbols(t, elx)
+	lux, _ := NewLuaParser().Parse(luaSample)
+	assertSpanKeysNameRealSymbols(t, lux)
+	sqx, _ := NewSQLParser().Parse(sqlSample)
+	assertSpanKeysNameRealSymbols(t, sqx)
+	prx, _ := NewProtoParser().Parse(protoSample)
+	assertSpanKeysNameRealSymbols(t, prx)
+	dfx, _ := NewDockerfileParser().Parse(dockerfileSample)
+	assertSpanKeysNameRealSymbols(t, dfx)
+	vux, _ := NewVueParser().ParseNamed(vueSample, "UserCard.vue")
+	assertSpanKeysNameRealSymbols(t, vux)
+	svx, _ := NewSvelteParser().ParseNamed(svelteSample, "Button.svelte")
+	assertSpanKeysNameRealSymbols(t, svx)
+	htx, _ := NewHTMLParser().Parse(htmlSample)
+	assertSpanKeysNameRealSymbols(t, htx)
+	csx, _ := NewCSSParser().Parse(scssSample)
+	assertSpanKeysNameRealSymbols(t, csx)
+	mdx, _ := NewMarkdownParser().Parse(markdownSample)
+	assertSpanKeysNameRealSymbols(t, mdx)
+	nbx, _ := NewNotebookParser().Parse(notebookSample)
+	assertSpanKeysNameRealSymbols(t, nbx)
+	solx, _ := NewSolidityParser().Parse(soliditySample)
+	assertSpanKeysNameRealSymbols(t, solx)
+	oax, _ := NewOpenAPIParser().Parse(openAPISample)
+	assertSpanKeysNameRealSymbols(t, oax)
+	for _, src := range []string{packageJSONSample, goModSample, requirementsSample} {
+		mfx, _ := NewManifestParser().Parse(src)
+		assertSpanKeysNameRealSymbols(t, mfx)
+	}
 }
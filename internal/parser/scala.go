@@ -0,0 +1,443 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// ScalaParser implements structural parsing for .scala files using the
+// vendored pure-Go tree-sitter Scala grammar. Scala 3's indentation syntax
+// has no braces to count at all, and interpolated strings (`s"${a.map { _ }}"`)
+// and XML literals hide the braces Scala 2 code does have.
+//
+// Symbol routing:
+//   - `class` / `object` / `trait` / `enum` / `type` → Classes,
+//     nested-qualified ("Reader.Inner"). A class and its companion object
+//     share one name, and their hashes combine.
+//   - `def` → Functions, qualified by the enclosing template
+//     ("Reader.fetch"), abstract declarations included. Members of a
+//     companion object are "Reader.apply", which is how a caller writes them.
+//     Auxiliary constructors (`def this`) are not listed: a caller writes the
+//     class.
+//   - Scala 3 `extension (s: T)` methods are qualified by T ("String.ext"), as
+//     the Kotlin parser qualifies extension functions.
+//   - `val` / `var` / named `given` → Exports when visible, including a
+//     class's `val`/`var` parameters and every case class parameter; located,
+//     not hashed.
+//   - `import` → Imports, one per selector: "scala.collection.mutable",
+//     "java.util.List", "java.util.Map => JMap", "akka.actor._".
+//   - `package` clauses → Exports, as the package they put the file's
+//     definitions in: chained clauses (`package com.acme` then `package app`)
+//     are one package, "com.acme.app", and a packaging block nests in the
+//     package around it. It is what an import names, and Scala does not tie
+//     it to the directory the file is in. Definitions are not qualified by
+//     package: after an import, a caller writes "Reader".
+//
+// Visibility: Scala is public unless marked, so Exports lists everything not
+// `private` or `protected` (qualified forms like `private[app]` included).
+// Enum cases are as visible as their enum. As in the Java parser, every type
+// and def is still listed whatever its visibility.
+//
+// Def bodies are not descended into: local defs and lambdas have no name a
+// caller elsewhere could write.
+type ScalaParser struct{}
+
+// NewScalaParser creates a new Scala parser.
+func NewScalaParser() *ScalaParser { return &ScalaParser{} }
+
+// SupportsExtension returns true for .scala files.
+func (p *ScalaParser) SupportsExtension(ext string) bool {
+	return ext == ".scala"
+}
+
+var (
+	scalaLangOnce sync.Once
+	scalaLang     *ts.Language
+)
+
+func scalaLanguage() *ts.Language {
+	scalaLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("Scala", r)
+			}
+		}()
+		scalaLang = grammars.ScalaLanguage()
+	})
+	return scalaLang
+}
+
+// Parse extracts structure from Scala source via tree-sitter. Best-effort on
+// parse errors: tree-sitter recovers to a partial tree for most mid-edit
+// buffers, and we walk whatever it produced.
+func (p *ScalaParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := scalaSymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}, nil
+}
+
+// scalaTypeDefs are the definitions that declare a named type or object.
+var scalaTypeDefs = map[string]bool{
+	"class_definition":  true,
+	"object_definition": true,
+	"trait_definition":  true,
+	"enum_definition":   true,
+	"type_definition":   true,
+}
+
+func scalaSymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("Scala parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			hashes, lines = nil, nil
+		}
+	}()
+
+	lang := scalaLanguage()
+	if lang == nil {
+		diag.noGrammar("Scala")
+		return imports, functions, classes, exports, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("Scala source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil
+	}
+	// As for Ruby: an ERROR root means nothing below it is a declaration, so
+	// say the symbols are missing rather than let them read as absent.
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("Scala file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+
+	// recordHash combines on collision: overloads, and a class with its
+	// companion object.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+	recordLine := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+	addExport := func(full string, line int) {
+		exports = append(exports, full)
+		recordLine("export:"+full, line)
+	}
+
+	// pkg is the package the definitions being walked are in.
+	var pkg string
+
+	// walk visits the definitions in n. prefix is the enclosing template's
+	// qualified name; enumPublic is the visibility enum cases inherit.
+	var walk func(n *ts.Node, prefix string, enumPublic bool, depth int)
+	// define handles one definition; an extension's body may be bare defs.
+	var define func(c *ts.Node, prefix string, enumPublic bool, depth int)
+	define = func(c *ts.Node, prefix string, enumPublic bool, depth int) {
+		kind := c.Type(lang)
+		span := src[c.StartByte():c.EndByte()]
+		line := int(c.StartPoint().Row) + 1
+		visible := scalaVisible(c, lang)
+
+		if scalaTypeDefs[kind] {
+			name := rustFieldText(c, "name", lang, src)
+			if name == "" {
+				return
+			}
+			full := qualify(prefix, name)
+			classes = append(classes, full)
+			recordHash("class:"+full, span)
+			recordLine("class:"+full, line)
+			if visible {
+				exports = append(exports, full)
+			}
+			if params := c.ChildByFieldName("class_parameters", lang); params != nil {
+				isCase := scalaHasKeyword(c, lang, "case")
+				for j := 0; j < params.NamedChildCount(); j++ {
+					p := params.NamedChild(j)
+					if p.Type(lang) != "class_parameter" || !scalaVisible(p, lang) {
+						continue
+					}
+					if !isCase && !scalaHasKeyword(p, lang, "val", "var") {
+						continue
+					}
+					if name := rustFieldText(p, "name", lang, src); name != "" {
+						addExport(qualify(full, name), int(p.StartPoint().Row)+1)
+					}
+				}
+			}
+			if body := c.ChildByFieldName("body", lang); body != nil {
+				walk(body, full, visible, depth+1)
+			}
+			return
+		}
+
+		switch kind {
+		case "import_declaration":
+			imports = append(imports, scalaImports(c, lang, src)...)
+
+		case "package_clause":
+			name := strings.Join(strings.Fields(kotlinChildText(c, "package_identifier", lang, src)), "")
+			if name == "" {
+				return
+			}
+			full := qualify(pkg, name)
+			// A packaging block (`package a { … }`) holds definitions; a
+			// clause without one holds the rest of the file.
+			body := c.ChildByFieldName("body", lang)
+			if body == nil {
+				pkg = full
+				if !scalaChainedPackage(c, lang) {
+					addExport(full, line)
+				}
+				return
+			}
+			addExport(full, line)
+			outer := pkg
+			pkg = full
+			walk(body, prefix, enumPublic, depth+1)
+			pkg = outer
+
+		case "function_definition", "function_declaration":
+			name := rustFieldText(c, "name", lang, src)
+			if name == "" || name == "this" {
+				return
+			}
+			full := qualify(prefix, name)
+			functions = append(functions, full)
+			recordHash("function:"+full, span)
+			recordLine("function:"+full, line)
+			if visible {
+				exports = append(exports, full)
+			}
+
+		case "val_definition", "var_definition", "val_declaration", "var_declaration", "given_definition":
+			if !visible {
+				return
+			}
+			for _, name := range scalaBoundNames(c, lang, src) {
+				addExport(qualify(prefix, name), line)
+			}
+
+		case "extension_definition":
+			owner := prefix
+			if params := c.ChildByFieldName("parameters", lang); params != nil && params.NamedChildCount() > 0 {
+				if t := params.NamedChild(0).ChildByFieldName("type", lang); t != nil {
+					owner = scalaTypeName(t.Text(src))
+				}
+			}
+			// An indented extension has one body field per def; a braced one
+			// has a single template body.
+			for j := 0; j < c.ChildCount(); j++ {
+				if c.FieldNameForChild(j, lang) != "body" {
+					continue
+				}
+				if body := c.Child(j); body.Type(lang) == "template_body" {
+					walk(body, owner, false, depth+1)
+				} else {
+					define(body, owner, false, depth+1)
+				}
+			}
+
+		case "enum_case_definitions":
+			if !enumPublic {
+				return
+			}
+			for j := 0; j < c.NamedChildCount(); j++ {
+				ec := c.NamedChild(j)
+				if name := rustFieldText(ec, "name", lang, src); name != "" {
+					addExport(qualify(prefix, name), int(ec.StartPoint().Row)+1)
+				}
+			}
+
+		case "ERROR":
+			// A mid-edit buffer's ERROR node can still hold whole definitions.
+			walk(c, prefix, enumPublic, depth+1)
+		}
+	}
+	walk = func(n *ts.Node, prefix string, enumPublic bool, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			define(n.NamedChild(i), prefix, enumPublic, depth)
+		}
+	}
+	walk(tree.RootNode(), "", false, 0)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, hashes, lines
+}
+
+// scalaChainedPackage reports whether the package clause n, which has no
+// block, is followed by another such clause that continues its name, so that
+// n names only a prefix of the file's package.
+func scalaChainedPackage(n *ts.Node, lang *ts.Language) bool {
+	for s := n.NextSibling(); s != nil; s = s.NextSibling() {
+		switch {
+		case !s.IsNamed(), s.Type(lang) == "comment", s.Type(lang) == "block_comment":
+			continue
+		case s.Type(lang) == "package_clause":
+			return s.ChildByFieldName("body", lang) == nil
+		}
+		return false
+	}
+	return false
+}
+
+// scalaVisible reports whether a definition is public: not marked `private`
+// or `protected`, qualified or not.
+func scalaVisible(n *ts.Node, lang *ts.Language) bool {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		m := n.NamedChild(i)
+		if m.Type(lang) != "modifiers" {
+			continue
+		}
+		for j := 0; j < m.NamedChildCount(); j++ {
+			if m.NamedChild(j).Type(lang) == "access_modifier" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// scalaHasKeyword reports whether one of n's anonymous children is one of
+// the keywords.
+func scalaHasKeyword(n *ts.Node, lang *ts.Language, keywords ...string) bool {
+	for i := 0; i < n.ChildCount(); i++ {
+		t := n.Child(i).Type(lang)
+		for _, kw := range keywords {
+			if t == kw {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scalaBoundNames returns the names a val, var, or given binds: one for
+// `val x`, several for `val (a, b)` or `val a, b`.
+func scalaBoundNames(n *ts.Node, lang *ts.Language, src []byte) []string {
+	var names []string
+	for i := 0; i < n.ChildCount(); i++ {
+		switch n.FieldNameForChild(i, lang) {
+		case "pattern", "name":
+			names = append(names, scalaPatternNames(n.Child(i), lang, src)...)
+		}
+	}
+	return names
+}
+
+func scalaPatternNames(p *ts.Node, lang *ts.Language, src []byte) []string {
+	switch p.Type(lang) {
+	case "identifier":
+		return []string{p.Text(src)}
+	case "tuple_pattern", "identifiers":
+		var names []string
+		for i := 0; i < p.NamedChildCount(); i++ {
+			names = append(names, scalaPatternNames(p.NamedChild(i), lang, src)...)
+		}
+		return names
+	}
+	return nil
+}
+
+// scalaTypeName returns a type's name without type arguments or whitespace.
+func scalaTypeName(text string) string {
+	if i := strings.IndexByte(text, '['); i >= 0 {
+		text = text[:i]
+	}
+	return strings.Join(strings.Fields(text), "")
+}
+
+// scalaImports returns one import per selector of an import clause: the path
+// joined with each selector, renames and wildcards written as in the source.
+func scalaImports(n *ts.Node, lang *ts.Language, src []byte) []string {
+	var out []string
+	var path strings.Builder
+	flush := func(selector string) {
+		p := strings.TrimSuffix(path.String(), ".")
+		if p == "" {
+			return
+		}
+		if selector != "" {
+			p += "." + selector
+		}
+		out = append(out, p)
+	}
+	pending := false
+	for i := 0; i < n.ChildCount(); i++ {
+		c := n.Child(i)
+		if n.FieldNameForChild(i, lang) == "path" {
+			if c.Type(lang) == "identifier" && pending && path.Len() > 0 && !strings.HasSuffix(path.String(), ".") {
+				// A new comma-separated clause: `import a.b, c.d`.
+				flush("")
+				path.Reset()
+			}
+			path.WriteString(c.Text(src))
+			pending = true
+			continue
+		}
+		switch c.Type(lang) {
+		case "namespace_selectors":
+			for j := 0; j < c.NamedChildCount(); j++ {
+				flush(strings.Join(strings.Fields(c.NamedChild(j).Text(src)), " "))
+			}
+			path.Reset()
+			pending = false
+		case "namespace_wildcard", "as_renamed_identifier", "arrow_renamed_identifier":
+			flush(strings.Join(strings.Fields(c.Text(src)), " "))
+			path.Reset()
+			pending = false
+		}
+	}
+	if pending {
+		flush("")
+	}
+	return out
+}
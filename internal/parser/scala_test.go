@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const scalaSample = `package com.acme
+package app
+
+import scala.collection.mutable
+import java.util.{List, Map => JMap}
+import akka.actor._
+import scala.concurrent.duration.given
+import a.b, c.d
+
+val top = 1
+val (pa, pb) = (1, 2)
+def topFn(x: Int): Int = { def local() = 1; x }
+
+@deprecated("x")
+final class Reader[T](val id: Int, private val y: Int, plain: Int) extends Base with Api {
+  val name: String = "r"
+  private var count = 0
+  protected def helper(): Unit = ()
+  private[app] def scoped(): Unit = ()
+  def fetch(): String = "def fake() {}"
+  def fetch(n: Int): String = s"${n}"
+  def this(s: String) = this(0, 0, 0)
+  class Inner { def go(): Unit = () }
+  type Alias = Int
+}
+
+object Reader { def apply(): Reader[Int] = new Reader(1, 2, 3); val K = 1 }
+trait Api { def fetch(): String; def dflt(): Int = 1 }
+case class Point(x: Int, y: Int)
+sealed abstract class Shape
+private object Hidden { def secret() = 1 }
+enum Color { case Red, Green; def rgb: Int = 0 }
+given ord: Ordering[Int] = ???
+extension (s: String) def ext: Int = s.length
+extension [T](xs: List[T])
+  def second: T = xs(1)
+  def third: T = xs(2)
+type Name = String
+
+object Indented:
+  def run(): Unit =
+    println("x")
+  val flag = true
+`
+
+func TestScalaParser_Extension(t *testing.T) {
+	p := NewScalaParser()
+	if !p.SupportsExtension(".scala") {
+		t.Error("want .scala supported")
+	}
+	if p.SupportsExtension(".sc") || p.SupportsExtension(".java") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestScalaParser_Symbols(t *testing.T) {
+	got, err := NewScalaParser().Parse(scalaSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	wantImports := []string{
+		"a.b", "akka.actor._", "c.d", "java.util.List", "java.util.Map => JMap",
+		"scala.collection.mutable", "scala.concurrent.duration.given",
+	}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports:\n got %q\nwant %q", got.Imports, wantImports)
+	}
+	// Reader is listed once for the class and its companion.
+	wantClasses := []string{"Api", "Color", "Hidden", "Indented", "Name", "Point", "Reader", "Reader.Alias", "Reader.Inner", "Shape"}
+	if !reflect.DeepEqual(got.Classes, wantClasses) {
+		t.Errorf("Classes:\n got %q\nwant %q", got.Classes, wantClasses)
+	}
+	// Extension methods are qualified by the extended type; the local def and
+	// the auxiliary constructor are not listed.
+	wantFunctions := []string{
+		"Api.dflt", "Api.fetch", "Color.rgb", "Hidden.secret", "Indented.run", "List.second",
+		"List.third", "Reader.Inner.go", "Reader.apply", "Reader.fetch", "Reader.helper",
+		"Reader.scoped", "String.ext", "topFn",
+	}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+}
+
+// The package a file's definitions are in is listed with the exports:
+// chained clauses make one package, and a packaging block nests in it.
+func TestScalaParser_Packages(t *testing.T) {
+	got, _ := NewScalaParser().Parse(scalaSample)
+	if line := got.SymbolLines["export:com.acme.app"]; line != 2 {
+		t.Errorf("export:com.acme.app line = %d, want 2", line)
+	}
+	src := "package com.acme\n// app code\npackage app\n\npackage util {\n  class A\n}\npackage other { class B }\n"
+	got, _ = NewScalaParser().Parse(src)
+	want := []string{"A", "B", "com.acme.app", "com.acme.app.other", "com.acme.app.util"}
+	if !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports:\n got %q\nwant %q", got.Exports, want)
+	}
+}
+
+// Exports leave out private and protected definitions, qualified or not, and
+// a plain constructor parameter; a case class's parameters are all fields.
+func TestScalaParser_Visibility(t *testing.T) {
+	got, _ := NewScalaParser().Parse(scalaSample)
+	wantExports := []string{
+		"Api", "Api.dflt", "Api.fetch", "Color", "Color.Green", "Color.Red", "Color.rgb",
+		"Hidden.secret", "Indented", "Indented.flag", "Indented.run", "List.second", "List.third",
+		"Name", "Point", "Point.x", "Point.y", "Reader", "Reader.Alias", "Reader.Inner",
+		"Reader.Inner.go", "Reader.K", "Reader.apply", "Reader.fetch", "Reader.id", "Reader.name",
+		"Shape", "String.ext", "com.acme.app", "ord", "pa", "pb", "top", "topFn",
+	}
+	if !reflect.DeepEqual(got.Exports, wantExports) {
+		t.Errorf("Exports:\n got %q\nwant %q", got.Exports, wantExports)
+	}
+}
+
+func TestScalaParser_HashesAndLines(t *testing.T) {
+	got, _ := NewScalaParser().Parse(scalaSample)
+	for key, want := range map[string]int{
+		"function:Reader.fetch": 20, // the first overload anchors the line
+		"class:Reader":          14, // the class, not its companion; annotations included
+		"function:List.third":   37,
+		"export:Reader.id":      15,
+		"function:Indented.run": 41,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(scalaSample, `s"${n}"`, `s"${n + 1}"`, 1)
+	after, _ := NewScalaParser().Parse(edited)
+	if after.SymbolHashes["function:Reader.fetch"] == got.SymbolHashes["function:Reader.fetch"] {
+		t.Error("editing the second overload did not change the symbol hash")
+	}
+	if after.SymbolHashes["function:topFn"] != got.SymbolHashes["function:topFn"] {
+		t.Error("unrelated symbol's hash changed")
+	}
+	companion := strings.Replace(scalaSample, "val K = 1", "val K = 2", 1)
+	if c, _ := NewScalaParser().Parse(companion); c.SymbolHashes["class:Reader"] == got.SymbolHashes["class:Reader"] {
+		t.Error("editing the companion object did not change class:Reader")
+	}
+}
+
+func TestScalaParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "object A {\n" +
+		"  // def commented() = 1\n" +
+		"  /* class Fake { /* nested */ } */\n" +
+		"  val s = s\"${xs.map { _ => \"def interp() = 1\" }}\"\n" +
+		"  val r = \"\"\"\n    class Raw { }\n  \"\"\"\n" +
+		"  def real() = 1\n}\n"
+	got, _ := NewScalaParser().Parse(src)
+	if !reflect.DeepEqual(got.Functions, []string{"A.real"}) || !reflect.DeepEqual(got.Classes, []string{"A"}) {
+		t.Errorf("Functions %q, Classes %q; want only A and A.real", got.Functions, got.Classes)
+	}
+}
+
+func TestScalaParser_CRLFParity(t *testing.T) {
+	lf, _ := NewScalaParser().Parse(scalaSample)
+	crlf, _ := NewScalaParser().Parse(strings.ReplaceAll(scalaSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestScalaParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "class Broken {", "def \x00\xff", "}}}}"} {
+		got, err := NewScalaParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzScalaParser asserts the Scala parser never panics on arbitrary input
+// and keeps the invariants the other tree-sitter parsers keep.
+// Run: go test -run=x -fuzz=FuzzScalaParser ./internal/parser
+func FuzzScalaParser(f *testing.F) {
+	seeds := []string{
+		scalaSample,
+		"def f = 1",
+		"object A { def b() = 1 }",
+		"extension (x: Int)\n  def y = x",
+		"enum E { case X, Y }",
+		"import a.{b => _, *}",
+		"val s = \"\"\"\nunterminated",
+		"", "class", "}}}", "private private", "val s = s\"${",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewScalaParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -0,0 +1,248 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// SolidityParser implements structural parsing for .sol files using the
+// vendored pure-Go tree-sitter Solidity grammar. What a contract exposes to
+// the chain is what an audit reviews, so that is what is indexed.
+//
+// Symbol routing:
+//   - `contract` / `abstract contract` / `interface` / `library` → Classes
+//     and Exports: a file-level declaration is visible to any file that
+//     imports it.
+//   - A `public` or `external` function → Functions and Exports, qualified by
+//     its contract ("Vault.deposit"), as are a contract's `receive` and
+//     `fallback` entry points. An interface function is external by
+//     definition, and one with no visibility keyword (pre-0.5 source) is
+//     public. `internal` and `private` functions, modifiers, constructors,
+//     and file-level free functions (always internal) are not symbols.
+//   - `import` → Imports, the path as written ("@openzeppelin/contracts/
+//     access/Ownable.sol"), whatever the form (`import "x"`, `import {A}
+//     from "x"`, `import * as L from "x"`).
+//   - `pragma` → Imports too, as "pragma solidity ^0.8.20": a compiler
+//     version constraint is a dependency, and bumping it shows as an import
+//     change, as a Dockerfile's base-image tag does.
+//
+// Overloads share a name and so one entry, with their hashes combined.
+// Structs, enums, events, errors, and public state variables are not symbols.
+type SolidityParser struct{}
+
+// NewSolidityParser creates a new Solidity parser.
+func NewSolidityParser() *SolidityParser { return &SolidityParser{} }
+
+// SupportsExtension returns true for .sol files.
+func (p *SolidityParser) SupportsExtension(ext string) bool {
+	return ext == ".sol"
+}
+
+var (
+	solidityLangOnce sync.Once
+	solidityLang     *ts.Language
+)
+
+func solidityLanguage() *ts.Language {
+	solidityLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("Solidity", r)
+			}
+		}()
+		solidityLang = grammars.SolidityLanguage()
+	})
+	return solidityLang
+}
+
+// Parse extracts structure from Solidity source via tree-sitter. Best-effort
+// on parse errors: tree-sitter recovers to a partial tree for most mid-edit
+// buffers, and we walk whatever it produced.
+func (p *SolidityParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := soliditySymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}, nil
+}
+
+// solidityContractDecls are the node kinds of the contract-like declarations.
+var solidityContractDecls = map[string]bool{
+	"contract_declaration":  true,
+	"interface_declaration": true,
+	"library_declaration":   true,
+}
+
+func soliditySymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("Solidity parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			hashes, lines = nil, nil
+		}
+	}()
+
+	lang := solidityLanguage()
+	if lang == nil {
+		diag.noGrammar("Solidity")
+		return imports, functions, classes, exports, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("Solidity source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil
+	}
+	// As for Ruby: an ERROR root means nothing below it is a declaration, so
+	// say the symbols are missing rather than let them read as absent.
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("Solidity file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+
+	// recordHash combines on collision: overloads of one function name.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+	recordLine := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+	record := func(kind, name string, n *ts.Node) {
+		recordHash(kind+":"+name, src[n.StartByte():n.EndByte()])
+		recordLine(kind+":"+name, int(n.StartPoint().Row)+1)
+	}
+
+	// member records a contract body member, for contract (its name) of the
+	// given declaration kind.
+	member := func(c *ts.Node, contract, declKind string) {
+		var name string
+		switch c.Type(lang) {
+		case "function_definition":
+			name = solidityChildText(c, "identifier", lang, src)
+			vis := solidityChildText(c, "visibility", lang, src)
+			if declKind != "interface_declaration" && vis != "" && vis != "public" && vis != "external" {
+				return
+			}
+		case "fallback_receive_definition":
+			// Named by its keyword: receive or fallback.
+			if c.ChildCount() > 0 {
+				name = c.Child(0).Text(src)
+			}
+		}
+		if name == "" {
+			return
+		}
+		full := qualify(contract, name)
+		functions = append(functions, full)
+		exports = append(exports, full)
+		record("function", full, c)
+	}
+
+	// walk visits the file-level declarations in n.
+	var walk func(n *ts.Node, depth int)
+	walk = func(n *ts.Node, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			c := n.NamedChild(i)
+			kind := c.Type(lang)
+			switch {
+			case solidityContractDecls[kind]:
+				name := solidityChildText(c, "identifier", lang, src)
+				if name == "" {
+					continue
+				}
+				classes = append(classes, name)
+				exports = append(exports, name)
+				record("class", name, c)
+				for j := 0; j < c.NamedChildCount(); j++ {
+					if body := c.NamedChild(j); body.Type(lang) == "contract_body" {
+						for k := 0; k < body.NamedChildCount(); k++ {
+							member(body.NamedChild(k), name, kind)
+						}
+					}
+				}
+
+			case kind == "import_directive":
+				for j := 0; j < c.NamedChildCount(); j++ {
+					if s := c.NamedChild(j); s.Type(lang) == "string" {
+						if imp := strings.Trim(s.Text(src), `"'`); imp != "" {
+							imports = append(imports, imp)
+						}
+					}
+				}
+
+			case kind == "pragma_directive":
+				for j := 0; j < c.NamedChildCount(); j++ {
+					if tok := strings.Join(strings.Fields(c.NamedChild(j).Text(src)), " "); tok != "" {
+						imports = append(imports, "pragma "+tok)
+						break
+					}
+				}
+
+			case kind == "ERROR":
+				// A mid-edit buffer's ERROR node can still hold whole
+				// declarations.
+				walk(c, depth+1)
+			}
+		}
+	}
+	walk(tree.RootNode(), 0)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, hashes, lines
+}
+
+// solidityChildText returns the text of n's first named child of the given
+// kind; the Solidity grammar names few fields.
+func solidityChildText(n *ts.Node, kind string, lang *ts.Language, src []byte) string {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		if c := n.NamedChild(i); c.Type(lang) == kind {
+			return c.Text(src)
+		}
+	}
+	return ""
+}
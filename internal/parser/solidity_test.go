@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const soliditySample = `// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.20;
+pragma abicoder v2;
+
+import "./IVault.sol";
+import {Ownable} from "@openzeppelin/contracts/access/Ownable.sol";
+import * as MathLib from "./MathLib.sol";
+
+/* contract Commented { function gone() public {} } */
+interface IVault {
+    function deposit(uint256 amount) external returns (bool);
+}
+
+library SafeMath {
+    function add(uint a, uint b) internal pure returns (uint) { return a + b; }
+}
+
+abstract contract Base is Ownable {
+    function pause() public virtual;
+}
+
+contract Vault is Base, IVault {
+    string public constant NAME = "function fake() public {}";
+    uint256 public totalSupply;
+
+    modifier onlyOpen() { _; }
+    constructor() Ownable(msg.sender) {}
+    receive() external payable {}
+
+    function deposit(uint256 amount) external override returns (bool) {
+        totalSupply += amount;
+        return true;
+    }
+    function deposit(uint256 amount, address to) public returns (bool) { return true; }
+    function pause() public override onlyOwner {}
+    function _settle() internal {}
+    function secret() private {}
+}
+
+function freeHelper(uint x) pure returns (uint) { return x; }
+`
+
+func TestSolidityParser_Extension(t *testing.T) {
+	p := NewSolidityParser()
+	if !p.SupportsExtension(".sol") {
+		t.Error("want .sol supported")
+	}
+	if p.SupportsExtension(".vy") || p.SupportsExtension(".js") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestSolidityParser_Symbols(t *testing.T) {
+	got, err := NewSolidityParser().Parse(soliditySample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	wantImports := []string{"./IVault.sol", "./MathLib.sol", "@openzeppelin/contracts/access/Ownable.sol", "pragma abicoder v2", "pragma solidity ^0.8.20"}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports = %q, want %q", got.Imports, wantImports)
+	}
+	if want := []string{"Base", "IVault", "SafeMath", "Vault"}; !reflect.DeepEqual(got.Classes, want) {
+		t.Errorf("Classes = %q, want %q", got.Classes, want)
+	}
+	if want := []string{"Base.pause", "IVault.deposit", "Vault.deposit", "Vault.pause", "Vault.receive"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+}
+
+// Internal and private functions, free functions, modifiers, and
+// constructors are not part of a contract's external surface.
+func TestSolidityParser_Visibility(t *testing.T) {
+	got, _ := NewSolidityParser().Parse(soliditySample)
+	want := []string{"Base", "Base.pause", "IVault", "IVault.deposit", "SafeMath", "Vault", "Vault.deposit", "Vault.pause", "Vault.receive"}
+	if !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+	legacy, _ := NewSolidityParser().Parse("pragma solidity ^0.4.24;\ncontract Old { function transfer() { } }\n")
+	if want := []string{"Old.transfer"}; !reflect.DeepEqual(legacy.Functions, want) {
+		t.Errorf("a function without visibility is public: Functions = %q, want %q", legacy.Functions, want)
+	}
+}
+
+func TestSolidityParser_HashesAndLines(t *testing.T) {
+	got, _ := NewSolidityParser().Parse(soliditySample)
+	for key, want := range map[string]int{"class:Vault": 22, "function:Vault.deposit": 30, "function:Vault.receive": 28, "function:IVault.deposit": 11} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	// The second deposit overload's body is part of the combined hash.
+	edited := strings.Replace(soliditySample, "address to) public returns (bool) { return true; }", "address to) public returns (bool) { return false; }", 1)
+	after, _ := NewSolidityParser().Parse(edited)
+	if after.SymbolHashes["function:Vault.deposit"] == got.SymbolHashes["function:Vault.deposit"] {
+		t.Error("editing an overload did not change the combined hash")
+	}
+	if after.SymbolHashes["function:Vault.pause"] != got.SymbolHashes["function:Vault.pause"] {
+		t.Error("editing deposit changed pause's hash")
+	}
+}
+
+func TestSolidityParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	got, _ := NewSolidityParser().Parse(soliditySample)
+	for _, name := range append(got.Functions, got.Classes...) {
+		if strings.Contains(name, "fake") || strings.Contains(name, "Commented") || strings.Contains(name, "gone") {
+			t.Errorf("symbol %q came from a string or comment", name)
+		}
+	}
+}
+
+func TestSolidityParser_CRLFParity(t *testing.T) {
+	lf, _ := NewSolidityParser().Parse(soliditySample)
+	crlf, _ := NewSolidityParser().Parse(strings.ReplaceAll(soliditySample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestSolidityParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "contract", "contract X {", "pragma", "import", strings.Repeat("{", 5000)} {
+		got, err := NewSolidityParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		assertParserInvariants(t, got)
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzSolidityParser asserts the Solidity parser never panics on arbitrary
+// input and keeps the shared invariants.
+// Run: go test -run=x -fuzz=FuzzSolidityParser ./internal/parser
+func FuzzSolidityParser(f *testing.F) {
+	seeds := []string{
+		soliditySample,
+		"contract A { function f() external {} }",
+		"interface I { function g() external; }",
+		"library L { function h() public {} }",
+		"", "contract", "{", "pragma solidity",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewSolidityParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
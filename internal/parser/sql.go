@@ -0,0 +1,373 @@
+package parser
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SQLParser implements structural parsing for .sql files: the names a schema
+// migration creates. `CREATE TABLE` / `CREATE VIEW` → Classes and
+// `CREATE FUNCTION` / `CREATE PROCEDURE` → Functions, each hashed over its
+// whole statement so an edited column list or function body shows as
+// modified. Names keep their schema and drop identifier quoting:
+// `"public"."Users"` is "public.Users". Indexes, triggers, types, `ALTER`, and
+// `DROP` are not symbols; SQL has no import model, and every schema object is
+// visible to every session, so Imports and Exports are always empty.
+//
+// The engine is a statement splitter over a length-preserving mask, in the
+// shell parser's style rather than a grammar: a migration directory mixes
+// dialects — Postgres `$$` bodies, MySQL `DELIMITER //` blocks, T-SQL `GO`
+// batches and `BEGIN … END` bodies — and a tree-sitter SQL grammar that meets
+// one it does not know recovers by swallowing every statement after it into
+// an error node. The splitter blanks comments, string literals, and
+// dollar-quoted bodies, so a `;` or `CREATE TABLE` inside one never ends a
+// statement or starts a symbol, then ends a statement at a `;` outside any
+// parentheses or `BEGIN`/`CASE … END` block, at the delimiter a `DELIMITER`
+// line sets, or at a `GO` line.
+//
+// Known limitations: a backslash always escapes inside '…' (MySQL's default;
+// Postgres reads it literally outside an `E'…'` string, so `'C:\'` runs on);
+// a T-SQL procedure written without `BEGIN … END` or semicolons ends at its
+// first `;`, so the rest of its body falls outside the hash.
+type SQLParser struct{}
+
+// NewSQLParser creates a new SQL parser.
+func NewSQLParser() *SQLParser { return &SQLParser{} }
+
+// SupportsExtension returns true for .sql files.
+func (p *SQLParser) SupportsExtension(ext string) bool {
+	return ext == ".sql"
+}
+
+// sqlIdent matches one identifier part: bare, "double-quoted",
+// `backquoted`, or [bracketed]; a T-SQL temp table's leading # included.
+const sqlIdent = `(?:"(?:[^"]|"")+"|` + "`[^`]+`" + `|\[[^\]]+\]|#{0,2}[A-Za-z_][A-Za-z0-9_$#@]*)`
+
+// reSQLCreate matches the head of a CREATE statement for a named object,
+// anchored at the statement start. The modifiers cover the dialects' spellings
+// between CREATE and the object kind (`OR REPLACE`, `TEMP`, `MATERIALIZED`,
+// MySQL's `DEFINER = user` and `ALGORITHM = MERGE`, …); a statement whose
+// words there are anything else (`CREATE INDEX … ON`, `CREATE TRIGGER …
+// EXECUTE FUNCTION f`) does not match.
+var reSQLCreate = regexp.MustCompile(`(?is)^CREATE\s+` +
+	`(?:(?:OR|REPLACE|ALTER|TEMP|TEMPORARY|GLOBAL|LOCAL|UNLOGGED|MATERIALIZED|RECURSIVE|EXTERNAL|VIRTUAL|FOREIGN|TRANSIENT|SECURE|AGGREGATE|SQL|SECURITY|INVOKER|DEFINER|ALGORITHM|UNDEFINED|MERGE|TEMPTABLE)\b(?:\s*=\s*[^\s;]+)?\s+)*` +
+	`(TABLE|VIEW|FUNCTION|PROCEDURE|PROC)\s+(?:IF\s+NOT\s+EXISTS\s+)?` +
+	`(` + sqlIdent + `(?:\s*\.\s*` + sqlIdent + `)*)`)
+
+var reSQLIdentPart = regexp.MustCompile(sqlIdent)
+
+// Parse extracts the tables, views, functions, and procedures a SQL file
+// creates. Best-effort and never errors: malformed input yields whatever
+// statements the splitter delimited.
+func (p *SQLParser) Parse(source string) (FileStructure, error) {
+	// Normalize CRLF→LF so hashes and line numbers are line-ending-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	src := []byte(source)
+	masked, stmts := splitSQL(src)
+	starts := lineStartsOf(src)
+
+	functions, classes := []string{}, []string{}
+	hashes := make(map[string]string)
+	lines := make(map[string]int)
+
+	// recordHash combines on collision: a migration that creates, drops, and
+	// recreates one view.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+
+	for _, st := range stmts {
+		m := reSQLCreate.FindSubmatchIndex(masked[st[0]:st[1]])
+		if m == nil {
+			continue
+		}
+		name := sqlName(src[st[0]+m[4] : st[0]+m[5]])
+		if name == "" {
+			continue
+		}
+		key := "function:" + name
+		switch strings.ToUpper(string(masked[st[0]+m[2] : st[0]+m[3]])) {
+		case "TABLE", "VIEW":
+			classes = append(classes, name)
+			key = "class:" + name
+		default:
+			functions = append(functions, name)
+		}
+		recordHash(key, src[st[0]:st[1]])
+		if _, ok := lines[key]; !ok {
+			lines[key] = lineForOffset(starts, st[0])
+		}
+	}
+
+	sort.Strings(functions)
+	sort.Strings(classes)
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return FileStructure{
+		Imports:      []string{},
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      []string{},
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+	}, nil
+}
+
+// sqlName returns a possibly schema-qualified name with whitespace and
+// identifier quoting removed: `"public" . "Users"` is "public.Users".
+func sqlName(raw []byte) string {
+	var parts []string
+	for _, part := range reSQLIdentPart.FindAll(raw, -1) {
+		s := string(part)
+		switch {
+		case strings.HasPrefix(s, `"`):
+			s = strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+		case strings.HasPrefix(s, "`"), strings.HasPrefix(s, "["):
+			s = s[1 : len(s)-1]
+		}
+		if s = strings.TrimSpace(s); s == "" {
+			return ""
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, ".")
+}
+
+// splitSQL returns a masked copy of src — comments, string literals, and
+// dollar-quoted bodies blanked to spaces, newlines kept — and the [start, end)
+// offsets of each statement, trimmed of surrounding whitespace and of the
+// delimiter that ends it. Offsets index src and the mask alike.
+func splitSQL(src []byte) ([]byte, [][2]int) {
+	masked := append([]byte(nil), src...)
+	blank := func(from, to int) {
+		for k := from; k < to && k < len(masked); k++ {
+			if masked[k] != '\n' {
+				masked[k] = ' '
+			}
+		}
+	}
+
+	var stmts [][2]int
+	start := 0
+	emit := func(end int) {
+		s, e := start, end
+		for s < e && isSQLSpace(masked[s]) {
+			s++
+		}
+		for e > s && isSQLSpace(masked[e-1]) {
+			e--
+		}
+		if s < e {
+			stmts = append(stmts, [2]int{s, e})
+		}
+	}
+
+	delim := []byte(";")
+	parens, blocks := 0, 0
+	n := len(src)
+	for i := 0; i < n; {
+		if i == 0 || src[i-1] == '\n' {
+			// Client-side lines: `DELIMITER x` and a T-SQL `GO` batch separator.
+			eol := bytes.IndexByte(src[i:], '\n')
+			if eol < 0 {
+				eol = n
+			} else {
+				eol += i
+			}
+			fields := strings.Fields(string(src[i:eol]))
+			if len(fields) == 2 && strings.EqualFold(fields[0], "DELIMITER") {
+				emit(i)
+				delim = []byte(fields[1])
+				blank(i, eol)
+				i, start, parens, blocks = eol, eol, 0, 0
+				continue
+			}
+			if len(fields) == 1 && strings.EqualFold(fields[0], "GO") {
+				emit(i)
+				blank(i, eol)
+				i, start, parens, blocks = eol, eol, 0, 0
+				continue
+			}
+		}
+
+		if !bytes.Equal(delim, []byte(";")) && bytes.HasPrefix(src[i:], delim) {
+			// A custom delimiter ends the statement wherever it appears; the
+			// body between is the client's to pass through whole.
+			emit(i)
+			blank(i, i+len(delim))
+			i += len(delim)
+			start, parens, blocks = i, 0, 0
+			continue
+		}
+
+		c := src[i]
+		switch {
+		case c == '-' && i+1 < n && src[i+1] == '-':
+			end := bytes.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = n - i
+			}
+			blank(i, i+end)
+			i += end
+
+		case c == '/' && i+1 < n && src[i+1] == '*':
+			// Block comments nest in standard SQL and Postgres.
+			depth, j := 1, i+2
+			for j < n && depth > 0 {
+				switch {
+				case src[j] == '/' && j+1 < n && src[j+1] == '*':
+					depth++
+					j += 2
+				case src[j] == '*' && j+1 < n && src[j+1] == '/':
+					depth--
+					j += 2
+				default:
+					j++
+				}
+			}
+			blank(i, j)
+			i = j
+
+		case c == '\'':
+			j := i + 1
+			for j < n {
+				if src[j] == '\\' {
+					j += 2
+					continue
+				}
+				if src[j] == '\'' {
+					if j+1 < n && src[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			blank(i+1, j)
+			i = j + 1
+
+		case c == '"' || c == '`' || c == '[':
+			// Quoted identifiers are kept — they are the names — but skipped
+			// whole so a quote or `;` inside one is not read as code.
+			closer := c
+			if c == '[' {
+				closer = ']'
+			}
+			j := i + 1
+			for j < n && src[j] != closer {
+				j++
+			}
+			i = j + 1
+
+		case c == '$' && bytes.Equal(delim, []byte(";")) && (i == 0 || !isSQLWord(src[i-1])):
+			// A dollar-quoted body: $$ … $$ or $tag$ … $tag$. `$1` is a
+			// parameter, not a tag.
+			j := i + 1
+			for j < n && isSQLWord(src[j]) {
+				j++
+			}
+			if j >= n || src[j] != '$' || (j > i+1 && src[i+1] >= '0' && src[i+1] <= '9') {
+				i++
+				continue
+			}
+			tag := src[i : j+1]
+			end := bytes.Index(src[j+1:], tag)
+			if end < 0 {
+				blank(j+1, n)
+				i = n
+				continue
+			}
+			blank(j+1, j+1+end)
+			i = j + 1 + end + len(tag)
+
+		case c == '(':
+			parens++
+			i++
+
+		case c == ')':
+			if parens > 0 {
+				parens--
+			}
+			i++
+
+		case c == ';':
+			if bytes.Equal(delim, []byte(";")) && parens == 0 && blocks == 0 {
+				emit(i)
+				start = i + 1
+			}
+			i++
+
+		case isSQLWord(c) && (i == 0 || !isSQLWord(src[i-1])):
+			j := i
+			for j < n && isSQLWord(src[j]) {
+				j++
+			}
+			switch strings.ToUpper(string(src[i:j])) {
+			case "CASE":
+				blocks++
+			case "BEGIN":
+				// `BEGIN;` and `BEGIN TRANSACTION` open a transaction, not a
+				// block: COMMIT ends them, never END.
+				if k := skipSQLSpace(src, j); k < n && src[k] != ';' && !sqlTxnWords[sqlWordAt(src, k)] {
+					blocks++
+				}
+			case "END":
+				// `END IF` / `END LOOP` close statements that opened no block.
+				switch sqlWordAt(src, skipSQLSpace(src, j)) {
+				case "IF", "LOOP", "WHILE", "REPEAT", "FOR":
+				default:
+					if blocks > 0 {
+						blocks--
+					}
+				}
+			}
+			i = j
+
+		default:
+			i++
+		}
+	}
+	emit(n)
+	return masked, stmts
+}
+
+// sqlTxnWords are the words that make a `BEGIN` start a transaction.
+var sqlTxnWords = map[string]bool{
+	"TRANSACTION": true, "TRAN": true, "WORK": true, "DEFERRED": true,
+	"IMMEDIATE": true, "EXCLUSIVE": true, "ISOLATION": true, "READ": true,
+}
+
+// sqlWordAt returns the upper-cased word starting at i, or "".
+func sqlWordAt(src []byte, i int) string {
+	j := i
+	for j < len(src) && isSQLWord(src[j]) {
+		j++
+	}
+	return strings.ToUpper(string(src[i:j]))
+}
+
+func isSQLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isSQLWord(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func skipSQLSpace(src []byte, i int) int {
+	for i < len(src) && isSQLSpace(src[i]) {
+		i++
+	}
+	return i
+}
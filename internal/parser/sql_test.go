@@ -0,0 +1,205 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sqlSample mixes the dialects a migration directory does: Postgres dollar
+// bodies, a MySQL DELIMITER block, and a T-SQL GO batch.
+const sqlSample = `-- CREATE TABLE commented (id int);
+CREATE TABLE users (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL DEFAULT 'CREATE TABLE fake; it''s'
+);
+CREATE TABLE IF NOT EXISTS public.orders (id int);
+CREATE OR REPLACE VIEW active_users AS SELECT * FROM users WHERE active;
+CREATE MATERIALIZED VIEW mv AS SELECT 1;
+CREATE FUNCTION add(a int, b int) RETURNS int AS $$
+  SELECT a + b;
+$$ LANGUAGE sql;
+CREATE OR REPLACE PROCEDURE do_it() LANGUAGE plpgsql AS $body$
+BEGIN
+  CREATE TABLE inner_tmp (x int);
+END;
+$body$;
+CREATE INDEX idx ON users (name);
+ALTER TABLE users ADD COLUMN age int;
+CREATE TEMP TABLE tmp (x int);
+BEGIN TRANSACTION;
+CREATE TABLE ` + "`mysql_t` (`id` int)" + ` ENGINE=InnoDB;
+COMMIT;
+CREATE TABLE "Quoted" ("id;" int);
+DELIMITER $$
+CREATE DEFINER=` + "`root`@`localhost`" + ` PROCEDURE my_proc(IN x INT)
+BEGIN
+  IF x > 0 THEN SELECT x; END IF;
+  CREATE TABLE nested (id int);
+END$$
+DELIMITER ;
+CREATE TABLE after_delim (id int);
+CREATE PROCEDURE dbo.tsql_proc AS BEGIN SELECT 1; SELECT CASE WHEN 1 = 1 THEN 2 END; END
+GO
+CREATE TABLE [dbo].[bracketed] (id int);
+CREATE TRIGGER trg BEFORE INSERT ON users FOR EACH ROW EXECUTE FUNCTION f();
+/* CREATE VIEW hidden /* nested */ AS SELECT 1; */
+create view lower_v as select 1;
+`
+
+func TestSQLParser_Extension(t *testing.T) {
+	p := NewSQLParser()
+	if !p.SupportsExtension(".sql") {
+		t.Error("want .sql supported")
+	}
+	if p.SupportsExtension(".psql") || p.SupportsExtension(".sh") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestSQLParser_Symbols(t *testing.T) {
+	got, err := NewSQLParser().Parse(sqlSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// Quoting is dropped and schemas kept; the commented view, the table
+	// created inside a procedure body, and the index are not listed.
+	wantClasses := []string{
+		"Quoted", "active_users", "after_delim", "dbo.bracketed", "lower_v", "mv", "mysql_t",
+		"public.orders", "tmp", "users",
+	}
+	if !reflect.DeepEqual(got.Classes, wantClasses) {
+		t.Errorf("Classes:\n got %q\nwant %q", got.Classes, wantClasses)
+	}
+	// The trigger's EXECUTE FUNCTION names no function it creates.
+	wantFunctions := []string{"add", "dbo.tsql_proc", "do_it", "my_proc"}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+	if len(got.Imports) != 0 || len(got.Exports) != 0 {
+		t.Errorf("Imports %q, Exports %q; want none", got.Imports, got.Exports)
+	}
+}
+
+// A statement ends at its own delimiter, so editing one object's statement
+// changes only that object's hash — even across a DELIMITER block or a
+// BEGIN … END body holding semicolons.
+func TestSQLParser_HashesAndLines(t *testing.T) {
+	got, _ := NewSQLParser().Parse(sqlSample)
+	for key, want := range map[string]int{
+		"class:users":            2,
+		"function:add":           9,
+		"function:my_proc":       25,
+		"class:after_delim":      31,
+		"function:dbo.tsql_proc": 32,
+		"class:dbo.bracketed":    34,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	for _, edit := range []struct{ old, new, changed string }{
+		{"SELECT a + b;", "SELECT a - b;", "function:add"},
+		{"CREATE TABLE nested (id int);", "CREATE TABLE nested (id bigint);", "function:my_proc"},
+		{"THEN 2 END", "THEN 3 END", "function:dbo.tsql_proc"},
+		{"NOT NULL DEFAULT", "NULL DEFAULT", "class:users"},
+	} {
+		after, _ := NewSQLParser().Parse(strings.Replace(sqlSample, edit.old, edit.new, 1))
+		for key, h := range got.SymbolHashes {
+			if changed := after.SymbolHashes[key] != h; changed != (key == edit.changed) {
+				t.Errorf("editing %q: %s changed = %v", edit.old, key, changed)
+			}
+		}
+	}
+}
+
+func TestSQLParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "-- CREATE TABLE a (id int);\n" +
+		"/* CREATE VIEW b /* nested */ AS SELECT 1; */\n" +
+		"INSERT INTO t VALUES ('x; CREATE TABLE c (id int)', 'it''s; CREATE VIEW d AS SELECT 1');\n" +
+		"DO $$ BEGIN EXECUTE 'x'; END $$;\n" +
+		"SELECT $tag$ ; CREATE FUNCTION e() $tag$;\n" +
+		"CREATE TABLE real (id int);\n"
+	got, _ := NewSQLParser().Parse(src)
+	if !reflect.DeepEqual(got.Classes, []string{"real"}) || len(got.Functions) != 0 {
+		t.Errorf("Classes %q, Functions %q; want only real", got.Classes, got.Functions)
+	}
+}
+
+// BEGIN opening a transaction must not swallow the statements after it.
+func TestSQLParser_TransactionBegin(t *testing.T) {
+	for _, begin := range []string{"BEGIN;", "BEGIN TRANSACTION;", "begin work;"} {
+		src := begin + "\nCREATE TABLE a (id int);\nCREATE TABLE b (id int);\nCOMMIT;\n"
+		got, _ := NewSQLParser().Parse(src)
+		if !reflect.DeepEqual(got.Classes, []string{"a", "b"}) {
+			t.Errorf("%s: Classes = %q, want [a b]", begin, got.Classes)
+		}
+		if got.SymbolHashes["class:a"] == got.SymbolHashes["class:b"] {
+			t.Errorf("%s: a and b share one statement", begin)
+		}
+	}
+}
+
+func TestSQLParser_CRLFParity(t *testing.T) {
+	lf, _ := NewSQLParser().Parse(sqlSample)
+	crlf, _ := NewSQLParser().Parse(strings.ReplaceAll(sqlSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestSQLParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "CREATE TABLE", "CREATE TABLE \x00\xff", "'unterminated", "$$", "DELIMITER"} {
+		got, err := NewSQLParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzSQLParser pins the splitter's invariants, as FuzzShellParse does for
+// the shell masker: the mask is length-preserving and never moves a newline,
+// statements are ordered, in bounds, and disjoint, and Parse never panics.
+// Run: go test -run=x -fuzz=FuzzSQLParser ./internal/parser
+func FuzzSQLParser(f *testing.F) {
+	seeds := []string{
+		sqlSample,
+		"CREATE TABLE t (id int);",
+		"CREATE FUNCTION f() AS $x$ ; $x$;",
+		"DELIMITER //\nCREATE PROCEDURE p() BEGIN SELECT 1; END//\n",
+		"CREATE PROC p AS SELECT 1\nGO\n",
+		"/* /* */", "'\\", "$a", "$1$", "[", "\"", "END END", "BEGIN", "DELIMITER ;",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewSQLParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		masked, stmts := splitSQL([]byte(src))
+		if len(masked) != len(src) {
+			t.Fatalf("mask changed length: %d != %d", len(masked), len(src))
+		}
+		for i := range masked {
+			if (masked[i] == '\n') != (src[i] == '\n') {
+				t.Fatalf("mask altered a newline at offset %d", i)
+			}
+		}
+		prev := 0
+		for _, st := range stmts {
+			if st[0] < prev || st[0] >= st[1] || st[1] > len(src) {
+				t.Fatalf("statement %v out of order or bounds (prev end %d, len %d)", st, prev, len(src))
+			}
+			prev = st[1]
+		}
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
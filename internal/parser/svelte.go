@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SvelteParser implements structural parsing for Svelte components (.svelte)
+// the way VueParser does for Vue: the `<script>` blocks — the instance script
+// and the `<script context="module">` (Svelte 5: `<script module>`) one — are
+// parsed by the JS parser over a blanked copy of the file, and the component,
+// named after its file (Button.svelte → "Button"), goes to Classes and
+// Exports, hashed over the whole file. An instance script's `export let`
+// props are the JS parser's exports, so they are Exports too.
+//
+// Known limitations: as VueParser's; a `<script>` inside `<svelte:head>` is an
+// HTML script the page runs, not component code, but is parsed all the same.
+type SvelteParser struct{}
+
+// NewSvelteParser creates a new Svelte component parser.
+func NewSvelteParser() *SvelteParser { return &SvelteParser{} }
+
+// SupportsExtension returns true for .svelte files.
+func (p *SvelteParser) SupportsExtension(ext string) bool {
+	return ext == ".svelte"
+}
+
+// Parse extracts the script blocks' structure. Without the file name it cannot
+// name the component; the generator calls ParseNamed instead.
+func (p *SvelteParser) Parse(source string) (FileStructure, error) {
+	return parseComponent(source, "")
+}
+
+// ParseNamed is the name-aware entry point (see NameAwareParser): name is the
+// file's base name, which names the component.
+func (p *SvelteParser) ParseNamed(source, name string) (FileStructure, error) {
+	return parseComponent(source, strings.TrimSuffix(name, filepath.Ext(name)))
+}
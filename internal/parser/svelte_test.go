@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const svelteSample = `<script context="module" lang="ts">
+  export const VARIANTS = ['primary', 'ghost']
+  export function preload(): void {}
+</script>
+
+<script lang="ts">
+  import { createEventDispatcher } from 'svelte'
+  import Icon from './Icon.svelte'
+  export let label: string
+  export let variant = 'primary'
+  const dispatch = createEventDispatcher()
+  function handleClick(): void { dispatch('click') }
+</script>
+
+<!-- <script>function commented() {}</script> -->
+<button class={variant} on:click={handleClick}>
+  {#if label}{label}{/if}
+</button>
+
+<style>
+  button { padding: 1rem; }
+</style>
+`
+
+func TestSvelteParser_Extension(t *testing.T) {
+	p := NewSvelteParser()
+	if !p.SupportsExtension(".svelte") {
+		t.Error("want .svelte supported")
+	}
+	if p.SupportsExtension(".vue") || p.SupportsExtension(".js") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+// The module and instance scripts are both parsed; `export let` props are
+// exports, and the component is named after its file.
+func TestSvelteParser_Symbols(t *testing.T) {
+	got, err := NewSvelteParser().ParseNamed(svelteSample, "Button.svelte")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"./Icon.svelte", "svelte"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if want := []string{"handleClick", "preload"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+	if want := []string{"Button"}; !reflect.DeepEqual(got.Classes, want) {
+		t.Errorf("Classes = %q, want %q", got.Classes, want)
+	}
+	if want := []string{"Button", "VARIANTS", "label", "preload", "variant"}; !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+}
+
+func TestSvelteParser_HashesAndLines(t *testing.T) {
+	got, _ := NewSvelteParser().ParseNamed(svelteSample, "Button.svelte")
+	for key, want := range map[string]int{"class:Button": 1, "function:preload": 3, "function:handleClick": 12} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(svelteSample, "{#if label}", "{#if label && variant}", 1)
+	after, _ := NewSvelteParser().ParseNamed(edited, "Button.svelte")
+	if after.SymbolHashes["class:Button"] == got.SymbolHashes["class:Button"] {
+		t.Error("editing the markup did not change the component's hash")
+	}
+	if after.SymbolHashes["function:handleClick"] != got.SymbolHashes["function:handleClick"] {
+		t.Error("a markup edit changed a function's hash")
+	}
+}
+
+func TestSvelteParser_CRLFParity(t *testing.T) {
+	lf, _ := NewSvelteParser().ParseNamed(svelteSample, "Button.svelte")
+	crlf, _ := NewSvelteParser().ParseNamed(strings.ReplaceAll(svelteSample, "\n", "\r\n"), "Button.svelte")
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestSvelteParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "<script>", "{#if x}<script>let", "<script module>\x00\xff"} {
+		got, err := NewSvelteParser().ParseNamed(src, "C.svelte")
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		assertParserInvariants(t, got)
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzSvelteParser asserts the Svelte parser never panics on arbitrary input
+// and keeps the shared invariants.
+// Run: go test -run=x -fuzz=FuzzSvelteParser ./internal/parser
+func FuzzSvelteParser(f *testing.F) {
+	seeds := []string{
+		svelteSample,
+		"<script module>export const a = 1</script>",
+		"<script>export let p</script>{#each xs as x}{x}{/each}",
+		"<svelte:head><script src=\"x.js\"></script></svelte:head>",
+		"", "<script", "{#if", "</script>",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewSvelteParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.ParseNamed(src, "C.svelte") // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
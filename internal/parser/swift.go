@@ -0,0 +1,350 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	ts "github.com/odvcencio/gotreesitter"
+	"github.com/odvcencio/gotreesitter/grammars"
+)
+
+// SwiftParser implements structural parsing for .swift files using the
+// vendored pure-Go tree-sitter Swift grammar. String interpolation
+// (`"\(a.map { "}" })"`), multi-line and raw strings (`#"…"#`), nested block
+// comments, and optional semicolons all defeat a brace-and-line scan.
+//
+// Symbol routing:
+//   - `class` / `struct` / `enum` / `actor` / `protocol` / `typealias` →
+//     Classes, nested-qualified ("Reader.Inner").
+//   - `func` → Functions, qualified by the enclosing type ("Reader.fetch"),
+//     protocol requirements included. An initializer is "Reader.init", as it
+//     is written. Overloads share one name and their hashes combine.
+//     Operators, subscripts, and `deinit` have no name a caller writes and are
+//     skipped.
+//   - `extension T` adds no type: its members are qualified by T
+//     ("String.ext"), as the Rust parser qualifies an `impl`'s methods.
+//   - `let` / `var` → Exports when public; located, not hashed.
+//   - `import` → Imports: the module, with a kind keyword kept when one is
+//     given ("UIKit", "struct Foundation.Date").
+//
+// Visibility: Swift's default is `internal`, visible only inside the module,
+// so Exports lists what is `public` or `open` — the API an app's other
+// modules and a package's clients see. Protocol requirements and enum cases
+// are as visible as their declaration, and members of a `public extension`
+// are public unless marked otherwise. As in the Java parser, every type and
+// function is still listed whatever its visibility.
+//
+// Function bodies are not descended into: nested functions and closures have
+// no name a caller elsewhere could write.
+type SwiftParser struct{}
+
+// NewSwiftParser creates a new Swift parser.
+func NewSwiftParser() *SwiftParser { return &SwiftParser{} }
+
+// SupportsExtension returns true for .swift files.
+func (p *SwiftParser) SupportsExtension(ext string) bool {
+	return ext == ".swift"
+}
+
+var (
+	swiftLangOnce sync.Once
+	swiftLang     *ts.Language
+)
+
+func swiftLanguage() *ts.Language {
+	swiftLangOnce.Do(func() {
+		// Recover so a grammar-decode panic degrades to the nil-language path
+		// (no symbols) instead of escaping the first Parse call.
+		defer func() {
+			if r := recover(); r != nil {
+				grammarFailed("Swift", r)
+			}
+		}()
+		swiftLang = grammars.SwiftLanguage()
+	})
+	return swiftLang
+}
+
+// Parse extracts structure from Swift source via tree-sitter. Best-effort on
+// parse errors: tree-sitter recovers to a partial tree for most mid-edit
+// buffers, and we walk whatever it produced.
+func (p *SwiftParser) Parse(source string) (FileStructure, error) {
+	// Normalize line endings so hashes and start lines are style-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	var diag diagnostics
+	imports, functions, classes, exports, hashes, lines := swiftSymbolsFromAST(source, &diag)
+
+	sort.Strings(imports)
+	sort.Strings(functions)
+	sort.Strings(classes)
+	sort.Strings(exports)
+
+	return FileStructure{
+		Imports:      deduplicate(imports),
+		Functions:    deduplicate(functions),
+		Classes:      deduplicate(classes),
+		Exports:      deduplicate(exports),
+		SymbolHashes: hashes,
+		SymbolLines:  lines,
+		Diagnostics:  diag,
+	}, nil
+}
+
+func swiftSymbolsFromAST(source string, diag *diagnostics) (imports, functions, classes, exports []string, hashes map[string]string, lines map[string]int) {
+	// Non-nil so a symbol-less file yields [] rather than null.
+	imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+
+	// The pure-Go tree-sitter runtime can panic on adversarial input; degrade to
+	// no symbols rather than taking down the indexer or MCP server.
+	defer func() {
+		if r := recover(); r != nil {
+			diag.addf("Swift parse panicked (%v); symbols for this file disabled", r)
+			imports, functions, classes, exports = []string{}, []string{}, []string{}, []string{}
+			hashes, lines = nil, nil
+		}
+	}()
+
+	lang := swiftLanguage()
+	if lang == nil {
+		diag.noGrammar("Swift")
+		return imports, functions, classes, exports, nil, nil
+	}
+	src := []byte(source)
+	if exceedsNestDepth(src) {
+		diag.addf("Swift source exceeds max nesting depth (%d); symbols for this file disabled", maxParseNestDepth)
+		return imports, functions, classes, exports, nil, nil
+	}
+	tree, err := ts.NewParser(lang).Parse(src)
+	if err != nil || tree == nil || tree.RootNode() == nil {
+		return imports, functions, classes, exports, nil, nil
+	}
+	// As for Ruby: an ERROR root means nothing below it is a declaration, so
+	// say the symbols are missing rather than let them read as absent.
+	if tree.RootNode().Type(lang) == "ERROR" {
+		diag.addf("Swift file did not parse (grammar returned ERROR at root); its symbols are missing, not absent")
+	}
+
+	hashes = make(map[string]string)
+	lines = make(map[string]int)
+
+	// recordHash combines on collision: overloads, and a type's members split
+	// across extensions in one file.
+	recordHash := func(key string, span []byte) {
+		h := hashBytesHex(span)
+		if existing, ok := hashes[key]; ok {
+			h = hashBytesHex([]byte(existing + h))
+		}
+		hashes[key] = h
+	}
+	recordLine := func(key string, line int) {
+		if _, ok := lines[key]; !ok {
+			lines[key] = line
+		}
+	}
+	addExport := func(full string, line int) {
+		exports = append(exports, full)
+		recordLine("export:"+full, line)
+	}
+	addType := func(full string, span []byte, line int, visible bool) {
+		classes = append(classes, full)
+		recordHash("class:"+full, span)
+		recordLine("class:"+full, line)
+		if visible {
+			exports = append(exports, full)
+		}
+	}
+
+	// walk visits the declarations in n. prefix is the enclosing type's
+	// qualified name; implicit says its members are public unless marked;
+	// enumPublic is the visibility enum cases inherit.
+	var walk func(n *ts.Node, prefix string, implicit, enumPublic bool, depth int)
+	walk = func(n *ts.Node, prefix string, implicit, enumPublic bool, depth int) {
+		if depth > maxParseNestDepth {
+			return
+		}
+		for i := 0; i < n.NamedChildCount(); i++ {
+			c := n.NamedChild(i)
+			span := src[c.StartByte():c.EndByte()]
+			line := int(c.StartPoint().Row) + 1
+			visible := swiftVisible(c, lang, src, implicit)
+
+			switch c.Type(lang) {
+			case "import_declaration":
+				if path := swiftImportPath(c, lang, src); path != "" {
+					imports = append(imports, path)
+				}
+
+			case "class_declaration", "protocol_declaration":
+				nameNode := c.ChildByFieldName("name", lang)
+				body := c.ChildByFieldName("body", lang)
+				if nameNode == nil {
+					continue
+				}
+				if kind := c.ChildByFieldName("declaration_kind", lang); kind != nil && kind.Type(lang) == "extension" {
+					// Members of a public extension default to public.
+					extended := swiftTypeName(nameNode.Text(src))
+					if body != nil && extended != "" {
+						walk(body, extended, swiftMarked(c, lang, src) == "public", false, depth+1)
+					}
+					continue
+				}
+				full := qualify(prefix, nameNode.Text(src))
+				addType(full, span, line, visible)
+				if body != nil {
+					// A protocol's requirements take its visibility; other
+					// members are internal unless marked.
+					walk(body, full, visible && c.Type(lang) == "protocol_declaration", visible, depth+1)
+				}
+
+			case "typealias_declaration":
+				if name := swiftName(c, lang, src); name != "" {
+					addType(qualify(prefix, name), span, line, visible)
+				}
+
+			case "function_declaration", "protocol_function_declaration", "init_declaration":
+				name := swiftName(c, lang, src)
+				if c.Type(lang) == "init_declaration" && prefix != "" {
+					name = "init"
+				}
+				if name == "" {
+					continue
+				}
+				full := qualify(prefix, name)
+				functions = append(functions, full)
+				recordHash("function:"+full, span)
+				recordLine("function:"+full, line)
+				if visible {
+					exports = append(exports, full)
+				}
+
+			case "property_declaration", "protocol_property_declaration":
+				if !visible {
+					continue
+				}
+				for j := 0; j < c.ChildCount(); j++ {
+					if c.FieldNameForChild(j, lang) != "name" {
+						continue
+					}
+					for _, name := range swiftBoundNames(c.Child(j), lang, src) {
+						addExport(qualify(prefix, name), line)
+					}
+				}
+
+			case "enum_entry":
+				if !enumPublic {
+					continue
+				}
+				for j := 0; j < c.ChildCount(); j++ {
+					if e := c.Child(j); c.FieldNameForChild(j, lang) == "name" && e.Type(lang) == "simple_identifier" {
+						addExport(qualify(prefix, e.Text(src)), int(e.StartPoint().Row)+1)
+					}
+				}
+
+			case "ERROR":
+				// A mid-edit buffer's ERROR node can still hold whole members.
+				walk(c, prefix, implicit, enumPublic, depth+1)
+			}
+		}
+	}
+	walk(tree.RootNode(), "", false, false, 0)
+
+	if len(hashes) == 0 {
+		hashes = nil
+	}
+	if len(lines) == 0 {
+		lines = nil
+	}
+	return imports, functions, classes, exports, hashes, lines
+}
+
+// swiftMarked returns a declaration's access modifier, or "" when unmarked.
+func swiftMarked(n *ts.Node, lang *ts.Language, src []byte) string {
+	for i := 0; i < n.NamedChildCount(); i++ {
+		m := n.NamedChild(i)
+		if m.Type(lang) != "modifiers" {
+			continue
+		}
+		for j := 0; j < m.NamedChildCount(); j++ {
+			if v := m.NamedChild(j); v.Type(lang) == "visibility_modifier" {
+				// `private(set)` limits only the setter; the getter's access
+				// is whatever else is written, so it is not a marking.
+				if text := v.Text(src); !strings.Contains(text, "(") {
+					return text
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// swiftVisible reports whether a declaration is visible outside its module:
+// marked `public` or `open`, or unmarked where that defaults to public.
+func swiftVisible(n *ts.Node, lang *ts.Language, src []byte, implicit bool) bool {
+	switch swiftMarked(n, lang, src) {
+	case "public", "open":
+		return true
+	case "":
+		return implicit
+	}
+	return false
+}
+
+// swiftName returns a declaration's name when it is one a caller writes: an
+// identifier, not an operator.
+func swiftName(n *ts.Node, lang *ts.Language, src []byte) string {
+	name := n.ChildByFieldName("name", lang)
+	if name == nil {
+		return ""
+	}
+	switch name.Type(lang) {
+	case "simple_identifier", "type_identifier":
+		return name.Text(src)
+	}
+	return ""
+}
+
+// swiftBoundNames returns the identifiers a binding pattern binds: one for
+// `let x`, several for `let (a, b)`.
+func swiftBoundNames(n *ts.Node, lang *ts.Language, src []byte) []string {
+	if n.Type(lang) == "simple_identifier" {
+		return []string{n.Text(src)}
+	}
+	if n.Type(lang) != "pattern" {
+		return nil
+	}
+	var names []string
+	for i := 0; i < n.NamedChildCount(); i++ {
+		names = append(names, swiftBoundNames(n.NamedChild(i), lang, src)...)
+	}
+	return names
+}
+
+// swiftTypeName returns an extended type's name without generic arguments or
+// whitespace: "Array" for `Array<Int>`, "Outer.Inner" for `Outer . Inner`.
+func swiftTypeName(text string) string {
+	if i := strings.IndexByte(text, '<'); i >= 0 {
+		text = text[:i]
+	}
+	return strings.Join(strings.Fields(text), "")
+}
+
+// swiftImportPath returns an import's module path, prefixed by its kind
+// keyword when it names a single declaration.
+func swiftImportPath(n *ts.Node, lang *ts.Language, src []byte) string {
+	kind, path := "", ""
+	for i := 0; i < n.ChildCount(); i++ {
+		c := n.Child(i)
+		switch t := c.Type(lang); t {
+		case "typealias", "struct", "class", "enum", "protocol", "let", "var", "func":
+			kind = t + " "
+		case "identifier":
+			path = strings.Join(strings.Fields(c.Text(src)), "")
+		}
+	}
+	if path == "" {
+		return ""
+	}
+	return kind + path
+}
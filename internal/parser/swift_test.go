@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const swiftSample = `import UIKit
+@testable import MyApp
+import struct Foundation.Date
+
+public let TOP = 1
+private var hidden = 2
+public let (a, b) = (1, 2)
+
+public func top(x: Int) -> Int { func local() {}; return x }
+private func priv() {}
+func internalFn() {}
+
+@MainActor
+public final class Reader<T>: Base, Api {
+    public var name: String = "r"
+    private var count = 0
+    public private(set) var id: Int
+    public init(id: Int) { self.id = id }
+    deinit {}
+    public func fetch() -> String { return "func fake() {}" }
+    public func fetch(n: Int) -> String { "\(n)" }
+    fileprivate func helper() {}
+    open func over() {}
+    public static func make() -> Reader { fatalError() }
+    public subscript(i: Int) -> T { fatalError() }
+    public class Inner { public func go() {} }
+    var computed: Int { return 1 }
+}
+
+public protocol Api {
+    func fetch() -> String
+    var p: Int { get }
+}
+public struct Point { public let x: Int; var y: Int; func sum() -> Int { x + y } }
+public enum Color {
+    case red, green
+    case blue(Int)
+    func rgb() -> Int { 0 }
+}
+public extension String { func ext() -> Int { count } }
+extension Reader: Equatable where T: Equatable {
+    public static func == (a: Reader, b: Reader) -> Bool { true }
+    func local() {}
+}
+public typealias Name = String
+actor Counter { func inc() {} }
+`
+
+func TestSwiftParser_Extension(t *testing.T) {
+	p := NewSwiftParser()
+	if !p.SupportsExtension(".swift") {
+		t.Error("want .swift supported")
+	}
+	if p.SupportsExtension(".m") || p.SupportsExtension(".swiftinterface") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+func TestSwiftParser_Symbols(t *testing.T) {
+	got, err := NewSwiftParser().Parse(swiftSample)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	wantImports := []string{"MyApp", "UIKit", "struct Foundation.Date"}
+	if !reflect.DeepEqual(got.Imports, wantImports) {
+		t.Errorf("Imports:\n got %q\nwant %q", got.Imports, wantImports)
+	}
+	// Extensions add no type.
+	wantClasses := []string{"Api", "Color", "Counter", "Name", "Point", "Reader", "Reader.Inner"}
+	if !reflect.DeepEqual(got.Classes, wantClasses) {
+		t.Errorf("Classes:\n got %q\nwant %q", got.Classes, wantClasses)
+	}
+	// Extension members are qualified by the extended type; the operator,
+	// subscript, deinit, and nested function are not listed.
+	wantFunctions := []string{
+		"Api.fetch", "Color.rgb", "Counter.inc", "Point.sum", "Reader.Inner.go", "Reader.fetch",
+		"Reader.helper", "Reader.init", "Reader.local", "Reader.make", "Reader.over",
+		"String.ext", "internalFn", "priv", "top",
+	}
+	if !reflect.DeepEqual(got.Functions, wantFunctions) {
+		t.Errorf("Functions:\n got %q\nwant %q", got.Functions, wantFunctions)
+	}
+}
+
+// Exports are what is public or open. Unmarked members are internal, except a
+// protocol's requirements, a public enum's cases, and a public extension's
+// members; private(set) limits only the setter.
+func TestSwiftParser_Visibility(t *testing.T) {
+	got, _ := NewSwiftParser().Parse(swiftSample)
+	wantExports := []string{
+		"Api", "Api.fetch", "Api.p", "Color", "Color.blue", "Color.green", "Color.red",
+		"Name", "Point", "Point.x", "Reader", "Reader.Inner", "Reader.Inner.go",
+		"Reader.fetch", "Reader.id", "Reader.init", "Reader.make", "Reader.name", "Reader.over",
+		"String.ext", "TOP", "a", "b", "top",
+	}
+	if !reflect.DeepEqual(got.Exports, wantExports) {
+		t.Errorf("Exports:\n got %q\nwant %q", got.Exports, wantExports)
+	}
+}
+
+func TestSwiftParser_HashesAndLines(t *testing.T) {
+	got, _ := NewSwiftParser().Parse(swiftSample)
+	for key, want := range map[string]int{
+		"function:Reader.fetch": 20, // the first overload anchors the line
+		"class:Reader":          13, // attributes are part of the declaration
+		"export:Color.green":    36,
+		"function:String.ext":   40,
+		"export:TOP":            5,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(swiftSample, `"\(n)"`, `"\(n + 1)"`, 1)
+	after, _ := NewSwiftParser().Parse(edited)
+	if after.SymbolHashes["function:Reader.fetch"] == got.SymbolHashes["function:Reader.fetch"] {
+		t.Error("editing the second overload did not change the symbol hash")
+	}
+	if after.SymbolHashes["function:top"] != got.SymbolHashes["function:top"] {
+		t.Error("unrelated symbol's hash changed")
+	}
+}
+
+func TestSwiftParser_NoSymbolsFromLiteralsOrComments(t *testing.T) {
+	src := "struct A {\n" +
+		"  // func commented() {}\n" +
+		"  /* class Fake { /* nested */ } */\n" +
+		"  let s = \"\\(xs.map { \"func interp() {}\" })\"\n" +
+		"  let r = #\"class Raw { \"# \n" +
+		"  let m = \"\"\"\n    func multi() {}\n    \"\"\"\n" +
+		"  func real() {}\n}\n"
+	got, _ := NewSwiftParser().Parse(src)
+	if !reflect.DeepEqual(got.Functions, []string{"A.real"}) || !reflect.DeepEqual(got.Classes, []string{"A"}) {
+		t.Errorf("Functions %q, Classes %q; want only A and A.real", got.Functions, got.Classes)
+	}
+}
+
+func TestSwiftParser_CRLFParity(t *testing.T) {
+	lf, _ := NewSwiftParser().Parse(swiftSample)
+	crlf, _ := NewSwiftParser().Parse(strings.ReplaceAll(swiftSample, "\n", "\r\n"))
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestSwiftParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "class Broken {", "func \x00\xff", "}}}}"} {
+		got, err := NewSwiftParser().Parse(src)
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		if got.Functions == nil || got.Imports == nil || got.Classes == nil || got.Exports == nil {
+			t.Errorf("Parse(%q) returned a nil slice; want empty", src)
+		}
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzSwiftParser asserts the Swift parser never panics on arbitrary input
+// and keeps the invariants the other tree-sitter parsers keep.
+// Run: go test -run=x -fuzz=FuzzSwiftParser ./internal/parser
+func FuzzSwiftParser(f *testing.F) {
+	seeds := []string{
+		swiftSample,
+		"func f() {}",
+		"public struct A { public func b() {} }",
+		"extension Array where Element: Equatable { func x() {} }",
+		"enum E { case x, y }",
+		"import func Darwin.sqrt",
+		"let s = \"\"\"\nunterminated",
+		"", "class", "}}}", "public public", "let s = \"\\(",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewSwiftParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		fs, err := p.Parse(src) // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
@@ -0,0 +1,207 @@
+package parser
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// VueParser implements structural parsing for Vue single-file components
+// (.vue). The `<script>` and `<script setup>` blocks are plain JS/TS, so they
+// are handed to the JS parser — with the grammar their `lang` attribute names
+// — over a copy of the file in which everything else is blanked to spaces.
+// Offsets and newlines survive the blanking, so the JS parser's line numbers
+// and body hashes are the component file's own. A `<script src="…">` path goes
+// to Imports.
+//
+// The component itself is named after its file, as Vue infers it
+// (UserCard.vue → "UserCard"), and recorded in Classes and Exports, hashed
+// over the whole file so a template or style edit shows as modified too. The
+// name needs the file's name, so the generator calls ParseNamed (see
+// NameAwareParser); Parse, which has only the source, records no component.
+//
+// Known limitations: a block in a language the JS parser does not read
+// (`lang="coffee"`) is skipped; an explicit `name` option is not read, so a
+// component registered under another name is still indexed under its file's.
+type VueParser struct{}
+
+// NewVueParser creates a new Vue single-file component parser.
+func NewVueParser() *VueParser { return &VueParser{} }
+
+// SupportsExtension returns true for .vue files.
+func (p *VueParser) SupportsExtension(ext string) bool {
+	return ext == ".vue"
+}
+
+// Parse extracts the script blocks' structure. Without the file name it cannot
+// name the component; the generator calls ParseNamed instead.
+func (p *VueParser) Parse(source string) (FileStructure, error) {
+	return parseComponent(source, "")
+}
+
+// ParseNamed is the name-aware entry point (see NameAwareParser): name is the
+// file's base name, which names the component.
+func (p *VueParser) ParseNamed(source, name string) (FileStructure, error) {
+	return parseComponent(source, strings.TrimSuffix(name, filepath.Ext(name)))
+}
+
+// parseComponent parses a component file's script blocks with the JS parser
+// and, unless component is empty, records the component under that name. The
+// Vue and Svelte parsers share it.
+func parseComponent(source, component string) (FileStructure, error) {
+	// Normalize CRLF→LF so hashes and line numbers are line-ending-independent.
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	fs, err := parseScripts(source, sfcScriptExt)
+	if err != nil || component == "" {
+		return fs, err
+	}
+	fs.Classes = deduplicate(sortedWith(fs.Classes, component))
+	fs.Exports = deduplicate(sortedWith(fs.Exports, component))
+	if fs.SymbolHashes == nil {
+		fs.SymbolHashes = make(map[string]string)
+	}
+	if fs.SymbolLines == nil {
+		fs.SymbolLines = make(map[string]int)
+	}
+	key := "class:" + component
+	// A script class of the component's own name keeps its span; the
+	// component is the file.
+	if _, ok := fs.SymbolHashes[key]; !ok {
+		fs.SymbolHashes[key] = hashBytesHex([]byte(source))
+		fs.SymbolLines[key] = 1
+	}
+	return fs, nil
+}
+
+// parseScripts parses the <script> bodies of an LF-normalized file with the
+// JS parser, reading each as the extension scriptExt returns for its
+// attributes, and adds their `src` paths to Imports.
+func parseScripts(source string, scriptExt func(attrs map[string]string) string) (FileStructure, error) {
+	masked, ext, srcs := maskToScripts(source, scriptExt)
+	fs, err := NewJSParser().ParseExt(masked, ext)
+	if err != nil {
+		return FileStructure{}, err
+	}
+	if len(srcs) > 0 {
+		fs.Imports = append(fs.Imports, srcs...)
+		sort.Strings(fs.Imports)
+		fs.Imports = deduplicate(fs.Imports)
+	}
+	return fs, nil
+}
+
+// sortedWith returns names plus name, sorted.
+func sortedWith(names []string, name string) []string {
+	out := append(append([]string(nil), names...), name)
+	sort.Strings(out)
+	return out
+}
+
+// sfcScript is one top-level <script> element of a component file.
+type sfcScript struct {
+	attrs      map[string]string
+	start, end int // the body's byte range
+}
+
+var (
+	reSFCScriptOpen  = regexp.MustCompile(`(?i)<script\b((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+	reSFCScriptClose = regexp.MustCompile(`(?i)</script\s*>`)
+	// reSFCAttr matches one attribute: a bare name or name=value, the value
+	// double-, single-, or un-quoted.
+	reSFCAttr = regexp.MustCompile(`([A-Za-z_:@][\w:.@-]*)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>/]+)))?`)
+)
+
+// sfcScripts returns the <script> elements of a component file, skipping
+// those inside an HTML comment. An unclosed script runs to the end of the
+// file; a self-closing one (`<script src="x.js" />`) has an empty body.
+func sfcScripts(source string) []sfcScript {
+	var out []sfcScript
+	for i := 0; i < len(source); {
+		m := reSFCScriptOpen.FindStringSubmatchIndex(source[i:])
+		if m == nil {
+			break
+		}
+		if c := strings.Index(source[i:], "<!--"); c >= 0 && c < m[0] {
+			end := strings.Index(source[i+c+4:], "-->")
+			if end < 0 {
+				break
+			}
+			i += c + 4 + end + 3
+			continue
+		}
+		attrText := source[i+m[2] : i+m[3]]
+		s := sfcScript{attrs: sfcAttrs(attrText), start: i + m[1], end: i + m[1]}
+		i += m[1]
+		if !strings.HasSuffix(strings.TrimSpace(attrText), "/") {
+			if c := reSFCScriptClose.FindStringIndex(source[i:]); c != nil {
+				s.end = i + c[0]
+				i += c[1]
+			} else {
+				s.end = len(source)
+				i = len(source)
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// sfcAttrs parses a tag's attribute text into lower-cased names and values; a
+// bare attribute (`setup`) maps to "".
+func sfcAttrs(text string) map[string]string {
+	attrs := map[string]string{}
+	for _, m := range reSFCAttr.FindAllStringSubmatch(text, -1) {
+		attrs[strings.ToLower(m[1])] = m[2] + m[3] + m[4]
+	}
+	return attrs
+}
+
+// sfcScriptExt returns the extension the JS parser reads a component script
+// as, by its `lang` attribute, or "" for a language it does not read.
+func sfcScriptExt(attrs map[string]string) string {
+	switch strings.ToLower(attrs["lang"]) {
+	case "", "js", "javascript":
+		return ".js"
+	case "ts", "typescript":
+		return ".ts"
+	case "jsx":
+		return ".jsx"
+	case "tsx":
+		return ".tsx"
+	}
+	return ""
+}
+
+// maskToScripts blanks every byte of source outside its JS/TS script bodies
+// to a space, keeping newlines, and returns that copy, the extension to parse
+// it as (the first script's language), and the scripts' `src` paths. A script
+// for which scriptExt returns "" is not kept, nor is one in a language other
+// than the first's.
+func maskToScripts(source string, scriptExt func(attrs map[string]string) string) (masked, ext string, srcs []string) {
+	keep := make([]bool, len(source))
+	for _, s := range sfcScripts(source) {
+		if src := strings.TrimSpace(s.attrs["src"]); src != "" {
+			srcs = append(srcs, src)
+		}
+		e := scriptExt(s.attrs)
+		if e == "" || (ext != "" && e != ext) {
+			continue
+		}
+		ext = e
+		for i := s.start; i < s.end; i++ {
+			keep[i] = true
+		}
+	}
+	if ext == "" {
+		ext = ".js"
+	}
+	b := []byte(source)
+	for i := range b {
+		if !keep[i] && b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+	return string(b), ext, srcs
+}
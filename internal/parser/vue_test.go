@@ -0,0 +1,166 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const vueSample = `<template>
+  <div class="card" @click="select">{{ user.name }}</div>
+  <!-- <script>function commented() {}</script> -->
+</template>
+
+<script lang="ts">
+import { defineComponent } from 'vue'
+export interface CardProps { id: number }
+export function formatName(n: string): string { return n.trim() }
+</script>
+
+<script setup lang="ts">
+import Avatar from './Avatar.vue'
+const props = defineProps<CardProps>()
+function select(): void { emit('select', props.id) }
+</script>
+
+<style scoped>
+.card { color: red; }
+</style>
+`
+
+func TestVueParser_Extension(t *testing.T) {
+	p := NewVueParser()
+	if !p.SupportsExtension(".vue") {
+		t.Error("want .vue supported")
+	}
+	if p.SupportsExtension(".js") || p.SupportsExtension(".html") {
+		t.Error("must not claim other extensions")
+	}
+}
+
+// Both script blocks are parsed as TypeScript; the commented-out script, the
+// template, and the style are not.
+func TestVueParser_Symbols(t *testing.T) {
+	got, err := NewVueParser().ParseNamed(vueSample, "UserCard.vue")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := []string{"./Avatar.vue", "vue"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if want := []string{"formatName", "select"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+	if want := []string{"CardProps", "UserCard"}; !reflect.DeepEqual(got.Classes, want) {
+		t.Errorf("Classes = %q, want %q", got.Classes, want)
+	}
+	if want := []string{"CardProps", "UserCard", "formatName"}; !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+}
+
+// Without the file name there is no component to record.
+func TestVueParser_ParseHasNoComponent(t *testing.T) {
+	got, _ := NewVueParser().Parse(vueSample)
+	if want := []string{"CardProps"}; !reflect.DeepEqual(got.Classes, want) {
+		t.Errorf("Classes = %q, want %q", got.Classes, want)
+	}
+}
+
+// Lines are the component file's, and the component's hash covers the whole
+// file while a function's covers only its body.
+func TestVueParser_HashesAndLines(t *testing.T) {
+	got, _ := NewVueParser().ParseNamed(vueSample, "UserCard.vue")
+	for key, want := range map[string]int{
+		"class:UserCard":      1,
+		"class:CardProps":     8,
+		"function:formatName": 9,
+		"function:select":     15,
+	} {
+		if got.SymbolLines[key] != want {
+			t.Errorf("%s start line = %d, want %d", key, got.SymbolLines[key], want)
+		}
+	}
+	edited := strings.Replace(vueSample, "color: red", "color: blue", 1)
+	after, _ := NewVueParser().ParseNamed(edited, "UserCard.vue")
+	if after.SymbolHashes["class:UserCard"] == got.SymbolHashes["class:UserCard"] {
+		t.Error("editing the style did not change the component's hash")
+	}
+	if after.SymbolHashes["function:select"] != got.SymbolHashes["function:select"] {
+		t.Error("a style edit changed a function's hash")
+	}
+}
+
+// A script's src is an import; a script in a language the JS parser does not
+// read is skipped, and plain script is read as JavaScript.
+func TestVueParser_ScriptAttributes(t *testing.T) {
+	src := "<template><p/></template>\n" +
+		"<script src=\"./logic.js\"></script>\n" +
+		"<script lang=\"coffee\">\nfunction coffee() {}\n</script>\n" +
+		"<script>\nexport function plain() {}\n</script>\n"
+	got, _ := NewVueParser().ParseNamed(src, "my-widget.vue")
+	if want := []string{"./logic.js"}; !reflect.DeepEqual(got.Imports, want) {
+		t.Errorf("Imports = %q, want %q", got.Imports, want)
+	}
+	if want := []string{"plain"}; !reflect.DeepEqual(got.Functions, want) {
+		t.Errorf("Functions = %q, want %q", got.Functions, want)
+	}
+	if want := []string{"my-widget", "plain"}; !reflect.DeepEqual(got.Exports, want) {
+		t.Errorf("Exports = %q, want %q", got.Exports, want)
+	}
+}
+
+func TestVueParser_CRLFParity(t *testing.T) {
+	lf, _ := NewVueParser().ParseNamed(vueSample, "UserCard.vue")
+	crlf, _ := NewVueParser().ParseNamed(strings.ReplaceAll(vueSample, "\n", "\r\n"), "UserCard.vue")
+	if !reflect.DeepEqual(lf, crlf) {
+		t.Error("CRLF source parsed differently from LF")
+	}
+}
+
+func TestVueParser_Degrades(t *testing.T) {
+	for _, src := range []string{"", "<script>", "<script>function open(", "<!-- <script>", "<script src=x />", "\x00\xff"} {
+		got, err := NewVueParser().ParseNamed(src, "C.vue")
+		if err != nil {
+			t.Errorf("Parse(%q) errored: %v", src, err)
+		}
+		assertParserInvariants(t, got)
+		assertSpanKeysNameRealSymbols(t, got)
+	}
+}
+
+// FuzzVueParser asserts the Vue parser never panics on arbitrary input, that
+// blanking keeps the file's length and newlines, and that the result keeps the
+// shared invariants.
+// Run: go test -run=x -fuzz=FuzzVueParser ./internal/parser
+func FuzzVueParser(f *testing.F) {
+	seeds := []string{
+		vueSample,
+		"<script setup>const a = 1</script>",
+		"<script lang='tsx'>export const C = () => <div/></script>",
+		"<!-- --><script src=\"a.js\"/>",
+		"<script>a</script><script>b",
+		"", "<script", "<!--", "</script>", "<script a=\">\">x</script>",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	p := NewVueParser()
+	f.Fuzz(func(t *testing.T, src string) {
+		masked, _, _ := maskToScripts(src, sfcScriptExt)
+		if len(masked) != len(src) {
+			t.Fatalf("mask changed length: %d != %d", len(masked), len(src))
+		}
+		for i := range masked {
+			if (masked[i] == '\n') != (src[i] == '\n') {
+				t.Fatalf("mask altered a newline at offset %d", i)
+			}
+		}
+		fs, err := p.ParseNamed(src, "C.vue") // must never panic
+		if err != nil {
+			return
+		}
+		assertParserInvariants(t, fs)
+		assertSpanKeysNameRealSymbols(t, fs)
+	})
+}
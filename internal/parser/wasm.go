@@ -0,0 +1,215 @@
+package parser
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// WasmParser runs a parser plugin compiled to WebAssembly. Unlike ExecParser it
+// is sandboxed — the module sees no filesystem, no environment, no network, a
+// fixed fake clock, and a deterministic random source — so a repo can ship one
+// without the user granting it anything, and the same .wasm runs unchanged on
+// every platform runecho builds for.
+//
+// ABI version 1. The module must export:
+//
+//   - memory
+//   - runecho_abi_version() -> i32: returns 1.
+//   - runecho_alloc(size i32) -> i32: a pointer to size writable bytes.
+//   - runecho_parse(src_ptr, src_len, ext_ptr, ext_len i32) -> i64: parses the
+//     source (with its extension, e.g. ".ex") and returns out_ptr<<32 | out_len
+//     locating the result in memory. The result is the ExecParser stdout JSON
+//     object, and goes through the same ingest validation.
+//
+// A trap is a parse error for that file. WASI preview1 imports are provided
+// (sandboxed as above) so modules built by ordinary toolchains load; a reactor
+// module's _initialize runs on instantiation. Each file gets a fresh instance,
+// so no state carries from one file to the next.
+type WasmParser struct {
+	name       string
+	extensions map[string]bool
+	mod        *wasmModule
+	timeout    time.Duration
+}
+
+// wasmABIVersion is the runecho_abi_version a module must report.
+const wasmABIVersion = 1
+
+// wasmMemoryLimitPages caps a plugin instance's linear memory (64 KiB pages):
+// 1024 pages = 64 MiB, ample for parsing one source file.
+const wasmMemoryLimitPages = 1024
+
+// wasmModule is a compiled plugin, shared by every WasmParser built from the
+// same bytes. Compilation is the expensive step and the runtime is never
+// closed, so the MCP server — which builds a generator per request — must reuse
+// it rather than compile (and leak) a runtime per request.
+type wasmModule struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+var (
+	wasmModulesMu sync.Mutex
+	wasmModules   = map[[sha256.Size]byte]*wasmModule{}
+)
+
+// NewWasmParser loads the module at path for the given extensions. The module
+// is compiled (once per distinct content, per process) and its ABI checked up
+// front, so a broken plugin fails at load rather than on every file.
+func NewWasmParser(name, path string, exts []string) (*WasmParser, error) {
+	set, err := extensionSet(name, exts)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("parser plugin %q: %w", name, err)
+	}
+	mod, err := compileWasm(data)
+	if err != nil {
+		return nil, fmt.Errorf("parser plugin %q: %w", name, err)
+	}
+	p := &WasmParser{name: name, extensions: set, mod: mod, timeout: DefaultExecTimeout}
+	if err := p.checkABI(); err != nil {
+		return nil, fmt.Errorf("parser plugin %q: %w", name, err)
+	}
+	return p, nil
+}
+
+func compileWasm(data []byte) (*wasmModule, error) {
+	key := sha256.Sum256(data)
+	wasmModulesMu.Lock()
+	defer wasmModulesMu.Unlock()
+	if m, ok := wasmModules[key]; ok {
+		return m, nil
+	}
+	ctx := context.Background()
+	rt := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(wasmMemoryLimitPages).
+		WithCloseOnContextDone(true))
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, rt); err != nil {
+		rt.Close(ctx)
+		return nil, fmt.Errorf("instantiate wasi: %w", err)
+	}
+	compiled, err := rt.CompileModule(ctx, data)
+	if err != nil {
+		rt.Close(ctx)
+		return nil, fmt.Errorf("compile module: %w", err)
+	}
+	m := &wasmModule{runtime: rt, compiled: compiled}
+	wasmModules[key] = m
+	return m, nil
+}
+
+// Name returns the plugin's configured name.
+func (p *WasmParser) Name() string { return p.name }
+
+// SupportsExtension reports whether ext is one of the plugin's extensions.
+func (p *WasmParser) SupportsExtension(ext string) bool { return p.extensions[ext] }
+
+// Parse runs the module with no extension hint.
+func (p *WasmParser) Parse(source string) (FileStructure, error) {
+	return p.ParseExt(source, "")
+}
+
+// ParseExt runs the module on source (see ExtAwareParser).
+func (p *WasmParser) ParseExt(source, ext string) (FileStructure, error) {
+	out, err := p.call(func(ctx context.Context, inst api.Module) ([]byte, error) {
+		srcPtr, err := writeGuest(ctx, inst, []byte(source))
+		if err != nil {
+			return nil, err
+		}
+		extPtr, err := writeGuest(ctx, inst, []byte(ext))
+		if err != nil {
+			return nil, err
+		}
+		res, err := inst.ExportedFunction("runecho_parse").Call(ctx,
+			uint64(srcPtr), uint64(len(source)), uint64(extPtr), uint64(len(ext)))
+		if err != nil {
+			return nil, err
+		}
+		ptr, n := uint32(res[0]>>32), uint32(res[0])
+		if n > maxExecOutputBytes {
+			return nil, fmt.Errorf("output exceeds %d bytes", maxExecOutputBytes)
+		}
+		data, ok := inst.Memory().Read(ptr, n)
+		if !ok {
+			return nil, fmt.Errorf("output [%d, +%d) is outside module memory", ptr, n)
+		}
+		return append([]byte(nil), data...), nil
+	})
+	if err != nil {
+		return FileStructure{}, fmt.Errorf("parser plugin %q: %w", p.name, err)
+	}
+	fs, err := decodeExecOutput(out)
+	if err != nil {
+		return FileStructure{}, fmt.Errorf("parser plugin %q: %w", p.name, err)
+	}
+	return fs, nil
+}
+
+// checkABI instantiates the module once and verifies its exports.
+func (p *WasmParser) checkABI() error {
+	_, err := p.call(func(ctx context.Context, inst api.Module) ([]byte, error) {
+		for _, name := range []string{"runecho_alloc", "runecho_parse"} {
+			if inst.ExportedFunction(name) == nil {
+				return nil, fmt.Errorf("module does not export %s", name)
+			}
+		}
+		if inst.Memory() == nil {
+			return nil, errors.New("module does not export memory")
+		}
+		v := inst.ExportedFunction("runecho_abi_version")
+		if v == nil {
+			return nil, errors.New("module does not export runecho_abi_version")
+		}
+		res, err := v.Call(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if got := uint32(res[0]); got != wasmABIVersion {
+			return nil, fmt.Errorf("module ABI version %d, runecho supports %d", got, wasmABIVersion)
+		}
+		return nil, nil
+	})
+	return err
+}
+
+// call runs fn against a fresh instance under the plugin timeout.
+func (p *WasmParser) call(fn func(ctx context.Context, inst api.Module) ([]byte, error)) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	inst, err := p.mod.runtime.InstantiateModule(ctx, p.mod.compiled,
+		wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, fmt.Errorf("instantiate: %w", err)
+	}
+	defer inst.Close(context.Background())
+	out, err := fn(ctx, inst)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("timed out after %s", p.timeout)
+	}
+	return out, err
+}
+
+// writeGuest copies data into memory obtained from the module's runecho_alloc.
+func writeGuest(ctx context.Context, inst api.Module, data []byte) (uint32, error) {
+	res, err := inst.ExportedFunction("runecho_alloc").Call(ctx, uint64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("runecho_alloc: %w", err)
+	}
+	ptr := uint32(res[0])
+	if !inst.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("runecho_alloc returned [%d, +%d), outside module memory", ptr, len(data))
+	}
+	return ptr, nil
+}
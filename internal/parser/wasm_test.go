@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Bodies for the hand-assembled test module's runecho_parse (locals vector
+// included, trailing `end` excluded).
+var (
+	// echoParse returns the source it was given, so the test drives the output
+	// JSON through the input: (src_ptr << 32) | src_len.
+	echoParse = []byte{0x00, 0x20, 0x00, 0xad, 0x42, 0x20, 0x86, 0x20, 0x01, 0xad, 0x84}
+	trapParse = []byte{0x00, 0x00}                               // unreachable
+	spinParse = []byte{0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x00} // loop { br 0 }; unreachable
+)
+
+// wasmModuleBytes assembles a minimal ABI-v1 plugin: a bump allocator over one
+// page of memory, runecho_abi_version returning abi, and the given parse body.
+// Hand-assembled so the test needs no wasm toolchain.
+func wasmModuleBytes(abi byte, parseBody []byte) []byte {
+	uleb := func(n int) []byte {
+		var out []byte
+		for {
+			b := byte(n & 0x7f)
+			n >>= 7
+			if n != 0 {
+				out = append(out, b|0x80)
+				continue
+			}
+			return append(out, b)
+		}
+	}
+	section := func(id byte, content ...byte) []byte {
+		return append(append([]byte{id}, uleb(len(content))...), content...)
+	}
+	name := func(s string) []byte { return append(uleb(len(s)), s...) }
+	body := func(code []byte) []byte {
+		code = append(append([]byte(nil), code...), 0x0b)
+		return append(uleb(len(code)), code...)
+	}
+	var exports []byte
+	exports = append(exports, 4)
+	exports = append(append(exports, name("memory")...), 0x02, 0x00)
+	exports = append(append(exports, name("runecho_abi_version")...), 0x00, 0x00)
+	exports = append(append(exports, name("runecho_alloc")...), 0x00, 0x01)
+	exports = append(append(exports, name("runecho_parse")...), 0x00, 0x02)
+	var code []byte
+	code = append(code, 3)
+	code = append(code, body([]byte{0x00, 0x41, abi})...)
+	code = append(code, body([]byte{0x00, 0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00})...)
+	code = append(code, body(parseBody)...)
+
+	out := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	out = append(out, section(1, // types: ()->i32, (i32)->i32, (i32 i32 i32 i32)->i64
+		3, 0x60, 0x00, 0x01, 0x7f, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7e)...)
+	out = append(out, section(3, 3, 0x00, 0x01, 0x02)...)                   // functions
+	out = append(out, section(5, 1, 0x00, 0x01)...)                         // memory: min 1 page
+	out = append(out, section(6, 1, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b)...) // global: mut i32 = 1024
+	out = append(out, section(7, exports...)...)
+	out = append(out, section(10, code...)...)
+	return out
+}
+
+func wasmPlugin(t *testing.T, abi byte, parseBody []byte) (*WasmParser, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plugin.wasm")
+	if err := os.WriteFile(path, wasmModuleBytes(abi, parseBody), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return NewWasmParser("test", path, []string{".ex"})
+}
+
+// TestWasmParser_ABIRoundTrip: source reaches the guest, the guest's output is
+// read back and goes through the same normalization as an exec plugin's.
+func TestWasmParser_ABIRoundTrip(t *testing.T) {
+	p, err := wasmPlugin(t, 1, echoParse)
+	if err != nil {
+		t.Fatalf("NewWasmParser: %v", err)
+	}
+	if !p.SupportsExtension(".ex") || p.SupportsExtension(".go") {
+		t.Error("SupportsExtension must match exactly the configured extensions")
+	}
+	got, err := p.ParseExt(`{"functions":["b","a","b"],"symbol_lines":{"function:a":2}}`, ".ex")
+	if err != nil {
+		t.Fatalf("ParseExt: %v", err)
+	}
+	want := FileStructure{Functions: []string{"a", "b"}, SymbolLines: map[string]int{"function:a": 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	// A fresh instance per file: the bump allocator restarts, so a second call
+	// behaves exactly like the first.
+	again, err := p.ParseExt(`{"functions":["b","a","b"],"symbol_lines":{"function:a":2}}`, ".ex")
+	if err != nil || !reflect.DeepEqual(again, want) {
+		t.Errorf("second call = %+v, %v; want %+v", again, err, want)
+	}
+	if _, err := p.Parse(`{"funtions":[]}`); err == nil {
+		t.Error("malformed guest output accepted")
+	}
+}
+
+func TestWasmParser_Failures(t *testing.T) {
+	if _, err := wasmPlugin(t, 2, echoParse); err == nil || !strings.Contains(err.Error(), "ABI version") {
+		t.Errorf("ABI v2 module: err = %v, want an ABI version error", err)
+	}
+	path := filepath.Join(t.TempDir(), "junk.wasm")
+	os.WriteFile(path, []byte("not wasm"), 0o644)
+	if _, err := NewWasmParser("junk", path, []string{".ex"}); err == nil {
+		t.Error("non-wasm bytes accepted")
+	}
+
+	trap, err := wasmPlugin(t, 1, trapParse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trap.Parse("x"); err == nil {
+		t.Error("trapping module: want a parse error")
+	}
+
+	spin, err := wasmPlugin(t, 1, spinParse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spin.timeout = 100 * time.Millisecond
+	if _, err := spin.Parse("x"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("spinning module: err = %v, want timeout", err)
+	}
+}
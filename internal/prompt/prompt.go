@@ -0,0 +1,141 @@
+// Package prompt renders user-defined prompt/context templates from an IR. It
+// is a thin text/template binding: the template author gets the IR's facts
+// through a fixed set of helper funcs (exportsOf, dependentsOf, …) and nothing
+// else — no clock, no environment, no filesystem — so the same IR and the same
+// template always render the same bytes.
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// maxTemplateBytes caps a template file read by RenderFile. A prompt template
+// is a page of text; anything larger is a mistake, not a template.
+const maxTemplateBytes = 1 << 20
+
+// View is the template's dot: the IR's top-level facts. Per-file facts are
+// reached through the helper funcs, which take a path.
+type View struct {
+	Version  int
+	RootHash string
+	Files    []string // sorted
+}
+
+// Funcs returns the helper funcs bound to irData. Every helper returns a slice
+// (sorted, or in the IR's own deterministic symbol order) or a scalar, never a
+// map, so template `range` order never depends on Go map iteration. An unknown
+// path yields an empty result rather than an error: a template shared across
+// repos should render, not fail, where a file is absent.
+func Funcs(irData *ir.IR) template.FuncMap {
+	names := func(p, kind string) []string {
+		var out []string
+		for _, s := range irData.Files[p].Symbols {
+			if s.Kind == kind {
+				out = append(out, s.Name)
+			}
+		}
+		return out
+	}
+	return template.FuncMap{
+		"files":          func() []string { return sortedFiles(irData) },
+		"hasFile":        func(p string) bool { _, ok := irData.Files[p]; return ok },
+		"hashOf":         func(p string) string { return irData.Files[p].Hash },
+		"exportsOf":      func(p string) []string { return names(p, "export") },
+		"importsOf":      func(p string) []string { return names(p, "import") },
+		"functionsOf":    func(p string) []string { return names(p, "function") },
+		"classesOf":      func(p string) []string { return names(p, "class") },
+		"symbolsOf":      func(p string) []ir.Symbol { return irData.Files[p].Symbols },
+		"refsOf":         func(p string) []string { return irData.Files[p].Refs },
+		"dependentsOf":   irData.Dependents,
+		"dependenciesOf": irData.Dependencies,
+		"filesUnder":     func(prefix string) []string { return filesUnder(irData, prefix) },
+		"join":           func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"short":          func(h string) string { return shortHash(h) },
+	}
+}
+
+// Parse parses a template with the IR helper funcs bound. missingkey=error
+// makes a typo'd field fail loudly instead of rendering "<no value>" into a
+// prompt an agent will trust.
+func Parse(name, text string, irData *ir.IR) (*template.Template, error) {
+	t, err := template.New(name).Option("missingkey=error").Funcs(Funcs(irData)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %q: %w", name, err)
+	}
+	return t, nil
+}
+
+// Render parses text and executes it against irData, writing the result to w.
+// Output is buffered and written only on success, so a template that fails
+// halfway never leaves a truncated prompt behind.
+func Render(w io.Writer, name, text string, irData *ir.IR) error {
+	t, err := Parse(name, text, irData)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, View{Version: irData.Version, RootHash: irData.RootHash, Files: sortedFiles(irData)}); err != nil {
+		return fmt.Errorf("render template %q: %w", name, err)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// RenderFile is Render with the template read from path.
+func RenderFile(w io.Writer, path string, irData *ir.IR) error {
+	text, err := ReadTemplate(path)
+	if err != nil {
+		return err
+	}
+	return Render(w, path, text, irData)
+}
+
+// ReadTemplate reads a template file, refusing one over maxTemplateBytes.
+func ReadTemplate(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("read template: %w", err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxTemplateBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("read template: %w", err)
+	}
+	if len(data) > maxTemplateBytes {
+		return "", fmt.Errorf("template %q exceeds %d bytes", path, maxTemplateBytes)
+	}
+	return string(data), nil
+}
+
+func sortedFiles(irData *ir.IR) []string {
+	return filesUnder(irData, "")
+}
+
+// filesUnder returns the sorted IR paths beginning with prefix.
+func filesUnder(irData *ir.IR, prefix string) []string {
+	out := make([]string, 0, len(irData.Files))
+	for p := range irData.Files {
+		if strings.HasPrefix(p, prefix) {
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// shortHash truncates a hash to 12 hex chars — the width the CLI prints root
+// hashes at. Shorter input is returned unchanged.
+func shortHash(h string) string {
+	if len(h) > 12 {
+		return h[:12]
+	}
+	return h
+}
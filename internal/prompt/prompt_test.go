@@ -0,0 +1,109 @@
+package prompt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+func promptTestIR() *ir.IR {
+	return &ir.IR{
+		Version:  ir.IRVersion,
+		RootHash: "0123456789abcdef0123",
+		Files: map[string]ir.FileIR{
+			"src/util.ts": {Hash: "u1", Symbols: []ir.Symbol{
+				{Name: "slugify", Kind: "function"},
+				{Name: "slugify", Kind: "export"},
+				{Name: "clamp", Kind: "export"},
+			}},
+			"src/app.ts": {Hash: "a1", Symbols: []ir.Symbol{
+				{Name: "./util", Kind: "import"},
+				{Name: "App", Kind: "class"},
+			}},
+			"src/main.ts": {Hash: "m1", Symbols: []ir.Symbol{
+				{Name: "./util", Kind: "import"},
+				{Name: "./app", Kind: "import"},
+			}},
+		},
+	}
+}
+
+func render(t *testing.T, text string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Render(&buf, "t", text, promptTestIR()); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	return buf.String()
+}
+
+func TestRender_Helpers(t *testing.T) {
+	cases := map[string]string{
+		`{{short .RootHash}}`:                            "0123456789ab",
+		`{{join "," .Files}}`:                            "src/app.ts,src/main.ts,src/util.ts",
+		`{{join "," (exportsOf "src/util.ts")}}`:         "slugify,clamp",
+		`{{join "," (dependentsOf "src/util.ts")}}`:      "src/app.ts,src/main.ts",
+		`{{join "," (dependenciesOf "src/main.ts")}}`:    "src/app.ts,src/util.ts",
+		`{{join "," (classesOf "src/app.ts")}}`:          "App",
+		`{{join "," (functionsOf "src/util.ts")}}`:       "slugify",
+		`{{hashOf "src/app.ts"}}`:                        "a1",
+		`{{hasFile "src/app.ts"}} {{hasFile "nope.ts"}}`: "true false",
+		`{{len (exportsOf "missing.ts")}}`:               "0",
+		`{{join "," (filesUnder "src/a")}}`:              "src/app.ts",
+	}
+	for text, want := range cases {
+		if got := render(t, text); got != want {
+			t.Errorf("%s = %q, want %q", text, got, want)
+		}
+	}
+}
+
+// TestRender_Deterministic: the same IR and template render byte-identical
+// output across runs, even when ranging over every file (map-backed in the IR).
+func TestRender_Deterministic(t *testing.T) {
+	text := `{{range files}}{{.}}: {{join " " (dependentsOf .)}}
+{{end}}`
+	first := render(t, text)
+	for i := 0; i < 20; i++ {
+		if got := render(t, text); got != first {
+			t.Fatalf("run %d differs:\n%s\nvs\n%s", i, got, first)
+		}
+	}
+}
+
+// TestRender_ErrorsLeaveNoOutput: a typo'd field is an error (missingkey=error
+// plus the struct dot), and nothing is written on failure.
+func TestRender_ErrorsLeaveNoOutput(t *testing.T) {
+	var buf bytes.Buffer
+	err := Render(&buf, "t", `partial {{.NoSuchField}}`, promptTestIR())
+	if err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("output written on failure: %q", buf.String())
+	}
+	if err := Render(&buf, "t", `{{unknownFunc}}`, promptTestIR()); err == nil || !strings.Contains(err.Error(), "parse template") {
+		t.Errorf("unknown func err = %v, want parse error", err)
+	}
+}
+
+func TestRenderFile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "ctx.tmpl")
+	if err := os.WriteFile(p, []byte(`{{len .Files}} files`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := RenderFile(&buf, p, promptTestIR()); err != nil {
+		t.Fatalf("RenderFile: %v", err)
+	}
+	if buf.String() != "3 files" {
+		t.Errorf("got %q", buf.String())
+	}
+	if err := RenderFile(&buf, filepath.Join(t.TempDir(), "missing"), promptTestIR()); err == nil {
+		t.Error("expected error for missing template")
+	}
+}
@@ -0,0 +1,127 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+func init() {
+	Register("text", RendererFunc(renderText))
+	Register("json", RendererFunc(renderJSON))
+}
+
+var kindAbbrev = map[string]string{
+	"function": "fn",
+	"class":    "cls",
+	"export":   "exp",
+	"import":   "imp",
+}
+
+// lineStr formats a 1-based line, "?" when unknown.
+func lineStr(line int) string {
+	if line <= 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%d", line)
+}
+
+// renderText is the human layout: a flat symbol index, or symbols grouped
+// under their file.
+func renderText(out io.Writer, m *Map) error {
+	if m.ByFile {
+		return textByFile(out, m.Symbols, m.Compact)
+	}
+	return textBySymbol(out, m.Symbols, m.Compact)
+}
+
+// textBySymbol renders the flat symbol index: name, kind, file:line, hash.
+func textBySymbol(out io.Writer, syms []Symbol, compact bool) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, s := range syms {
+		loc := s.File + ":" + lineStr(s.Line)
+		if compact {
+			fmt.Fprintf(w, "%s\t%s\n", s.Name, loc)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, kindAbbrev[s.Kind], loc, s.Hash)
+	}
+	return w.Flush()
+}
+
+// GroupByFile returns the sorted file paths of syms and each file's symbols,
+// ordered by line then name: the grouping every by-file layout shares.
+func GroupByFile(syms []Symbol) ([]string, map[string][]Symbol) {
+	byFile := make(map[string][]Symbol)
+	var files []string
+	for _, s := range syms {
+		if _, seen := byFile[s.File]; !seen {
+			files = append(files, s.File)
+		}
+		byFile[s.File] = append(byFile[s.File], s)
+	}
+	sort.Strings(files)
+	for _, group := range byFile {
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].Line != group[j].Line {
+				return group[i].Line < group[j].Line
+			}
+			return group[i].Name < group[j].Name
+		})
+	}
+	return files, byFile
+}
+
+// textByFile groups symbols under their file, sorted by line then name.
+func textByFile(out io.Writer, syms []Symbol, compact bool) error {
+	files, byFile := GroupByFile(syms)
+	for _, file := range files {
+		fmt.Fprintln(out, file)
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, s := range byFile[file] {
+			if compact {
+				fmt.Fprintf(w, "  \t%s\t%s\n", s.Name, lineStr(s.Line))
+				continue
+			}
+			fmt.Fprintf(w, "  \t%s\t%s:%s\t%s\n", kindAbbrev[s.Kind], s.Name, lineStr(s.Line), s.Hash)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderJSON marshals the canonical machine shape: mode tells the consumer
+// which key to read without introspecting (parity with diff --json).
+func renderJSON(out io.Writer, m *Map) error {
+	payload := map[string]interface{}{
+		"root":         m.Root,
+		"changed_only": m.ChangedOnly,
+		"count":        len(m.Symbols),
+	}
+	if m.ByFile {
+		payload["mode"] = "files"
+		// files is a JSON object keyed by path (always {} when empty, never null).
+		files := make(map[string][]Symbol)
+		for _, s := range m.Symbols {
+			files[s.File] = append(files[s.File], s)
+		}
+		payload["files"] = files
+	} else {
+		payload["mode"] = "symbols"
+		syms := m.Symbols
+		if syms == nil {
+			syms = []Symbol{}
+		}
+		payload["symbols"] = syms
+	}
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
@@ -0,0 +1,87 @@
+// Package render turns the repo map into an output format. A format is a
+// Renderer registered under a name, and `runecho-ir map --format=<name>` picks
+// one, so a new format (org-mode, AsciiDoc, a team's own JSON shape) is a
+// registration rather than a change to the command. The built-ins, text and
+// json, register themselves the same way.
+package render
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+// Symbol is one located symbol: a deterministic projection of the IR (name,
+// kind, file, 1-based line, short body hash).
+type Symbol struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"` // function | class | export | import
+	File string `json:"file"`
+	Line int    `json:"line"` // 1-based; 0 = unknown (parser had no span / pre-v4 index)
+	Hash string `json:"hash,omitempty"`
+}
+
+// Map is the repo map a Renderer draws. Symbols arrive filtered and sorted by
+// the command; the layout flags are the user's, and a renderer may ignore any
+// that mean nothing in its format.
+type Map struct {
+	Root        string
+	ChangedOnly bool // --since: Symbols are only those changed since a snapshot
+	ByFile      bool // group under files instead of a flat symbol index
+	Compact     bool // terser output
+	Symbols     []Symbol
+}
+
+// Renderer writes a Map in one format. Output must be a pure function of the
+// Map: `map` output is cached by RootHash, and the cache assumes it.
+type Renderer interface {
+	Render(w io.Writer, m *Map) error
+}
+
+// RendererFunc adapts a plain function to Renderer.
+type RendererFunc func(w io.Writer, m *Map) error
+
+// Render calls f(w, m).
+func (f RendererFunc) Render(w io.Writer, m *Map) error { return f(w, m) }
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Renderer{}
+)
+
+// Register makes r available as format name. It panics on a nil renderer or
+// an empty or duplicate name.
+func Register(name string, r Renderer) {
+	if r == nil {
+		panic("render: Register renderer is nil")
+	}
+	if name == "" {
+		panic("render: Register with an empty name")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic("render: Register called twice for format " + name)
+	}
+	registry[name] = r
+}
+
+// Lookup returns the renderer registered as name.
+func Lookup(name string) (Renderer, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[name]
+	return r, ok
+}
+
+// Names returns the registered format names, sorted.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+	"testing"
+)
+
+func sampleMap() *Map {
+	return &Map{Root: "/r", Symbols: []Symbol{
+		{Name: "Alpha", Kind: "function", File: "a.go", Line: 3, Hash: "abcd"},
+		{Name: "Beta", Kind: "class", File: "b.py", Line: 0},
+		{Name: "early", Kind: "function", File: "a.go", Line: 1},
+	}}
+}
+
+func TestText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := renderText(&buf, sampleMap()); err != nil {
+		t.Fatal(err)
+	}
+	want := "Alpha  fn   a.go:3  abcd\nBeta   cls  b.py:?  \nearly  fn   a.go:1  \n"
+	if buf.String() != want {
+		t.Errorf("flat text:\n%q\nwant\n%q", buf.String(), want)
+	}
+
+	m := sampleMap()
+	m.ByFile, m.Compact = true, true
+	buf.Reset()
+	if err := renderText(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	want = "a.go\n    early  1\n    Alpha  3\nb.py\n    Beta  ?\n"
+	if buf.String() != want {
+		t.Errorf("by-file compact text:\n%q\nwant\n%q", buf.String(), want)
+	}
+}
+
+func TestJSON_EmptyIsArrayNotNull(t *testing.T) {
+	var buf bytes.Buffer
+	if err := renderJSON(&buf, &Map{Root: "/r"}); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if syms, ok := got["symbols"].([]any); !ok || len(syms) != 0 || got["mode"] != "symbols" {
+		t.Errorf("empty map JSON = %s", buf.String())
+	}
+}
+
+func TestLineStr(t *testing.T) {
+	if lineStr(0) != "?" || lineStr(-1) != "?" {
+		t.Error("lineStr(<=0) should be ?")
+	}
+	if lineStr(42) != "42" {
+		t.Error("lineStr(42) should be 42")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	Register("test-names", RendererFunc(func(w io.Writer, m *Map) error {
+		for _, s := range m.Symbols {
+			if _, err := io.WriteString(w, s.Name+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	if !slices.Contains(Names(), "test-names") || !slices.Contains(Names(), "text") {
+		t.Fatalf("Names() = %v", Names())
+	}
+	r, ok := Lookup("test-names")
+	if !ok {
+		t.Fatal("Lookup of a registered format failed")
+	}
+	var buf bytes.Buffer
+	if err := r.Render(&buf, sampleMap()); err != nil || buf.String() != "Alpha\nBeta\nearly\n" {
+		t.Errorf("custom renderer = %q, %v", buf.String(), err)
+	}
+	if _, ok := Lookup("nope"); ok {
+		t.Error("Lookup of an unregistered format succeeded")
+	}
+	for name, reg := range map[string]func(){
+		"nil":       func() { Register("x", nil) },
+		"empty":     func() { Register("", RendererFunc(renderText)) },
+		"duplicate": func() { Register("text", RendererFunc(renderText)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: Register did not panic", name)
+				}
+			}()
+			reg()
+		}()
+	}
+}
@@ -162,15 +162,16 @@ func newerSchemaErr(version int) error {
 type migration func(*sql.Tx) error
 
 var migrations = []migration{
-	migrateV1, // 0 → 1: baseline snapshots/files/symbols
-	migrateV2, // 1 → 2: central-store repos registry + snapshots.repo_id
-	migrateV3, // 2 → 3: split repo.Path into lookup key (path) + source root (source_root)
-	migrateV4, // 3 → 4: add common_dir — stable cross-worktree lookup key
-	migrateV5, // 4 → 5: add supported_seen — honest-coverage denominator
-	migrateV6, // 5 → 6: refs table — bare call sites per snapshot file
-	migrateV7, // 6 → 7: refs uniqueness — (file_id, name) enforced by the schema
-	migrateV8, // 7 → 8: symbols.sig_hash — per-symbol body hash for modified-symbol diff
-	migrateV9, // 8 → 9: contracts table — the active edit-scope binding per session
+	migrateV1,  // 0 → 1: baseline snapshots/files/symbols
+	migrateV2,  // 1 → 2: central-store repos registry + snapshots.repo_id
+	migrateV3,  // 2 → 3: split repo.Path into lookup key (path) + source root (source_root)
+	migrateV4,  // 3 → 4: add common_dir — stable cross-worktree lookup key
+	migrateV5,  // 4 → 5: add supported_seen — honest-coverage denominator
+	migrateV6,  // 5 → 6: refs table — bare call sites per snapshot file
+	migrateV7,  // 6 → 7: refs uniqueness — (file_id, name) enforced by the schema
+	migrateV8,  // 7 → 8: symbols.sig_hash — per-symbol body hash for modified-symbol diff
+	migrateV9,  // 8 → 9: contracts table — the active edit-scope binding per session
+	migrateV10, // 9 → 10: symbols.stable_id — identity carried across renames/moves
 }
 
 // SchemaVersion is the latest schema version this binary understands.
@@ -1,6 +1,8 @@
 package snapshot
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -123,6 +125,48 @@ func TestDiffLive_ModifiedSymbol(t *testing.T) {
 	}
 }
 
+// TestDiffLive_DependencyDrift: a manifest's dependencies are imports hashed
+// over their versions, so a version bump diffs as a modified import beside any
+// source change.
+func TestDiffLive_DependencyDrift(t *testing.T) {
+	db, _ := openTemp(t)
+	id, _ := db.EnrollRepo("r", "/repos/r", "", 0)
+	root := t.TempDir()
+	write := func(body string) {
+		if err := os.WriteFile(filepath.Join(root, "package.json"), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gen := ir.NewGenerator(ir.GeneratorConfig{})
+
+	write(`{"dependencies": {"lodash": "^4.17.21", "react": "^18.2.0"}}`)
+	baseIR, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sid, err := db.SaveSnapshot(id, "s", "base", "/repos/r", baseIR)
+	if err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+	base, _ := db.GetByID(sid)
+
+	write(`{"dependencies": {"lodash": "^4.18.0", "react": "^18.2.0"}}`)
+	live, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := db.DiffLive(*base, live)
+	if err != nil {
+		t.Fatalf("DiffLive: %v", err)
+	}
+	if res.TotalModified != 1 || res.TotalAdded != 0 || res.TotalRemoved != 0 {
+		t.Errorf("diff = +%d -%d ~%d, want ~1 (lodash)", res.TotalAdded, res.TotalRemoved, res.TotalModified)
+	}
+	if out := FormatFull(res); !strings.Contains(out, "~ lodash") {
+		t.Errorf("FormatFull missing the bumped dependency:\n%s", out)
+	}
+}
+
 // TestDiffLive_NoFalseModified guarantees the cross-version safety rule: when one
 // side carries no body hash (e.g. a pre-v3 snapshot), a body change is NOT
 // reported "modified" — only a real hash-vs-hash difference qualifies.
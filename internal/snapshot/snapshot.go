@@ -29,7 +29,11 @@ func (db *DB) SaveSnapshot(repoID int64, sessionID, label, root string, irData *
 		return 0, fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback()
-	id, err := writeSnapshotTx(tx, repoID, sessionID, label, root, irData)
+	prior, err := loadPriorSymbols(tx, repoID)
+	if err != nil {
+		return 0, err
+	}
+	id, err := writeSnapshotTx(tx, repoID, sessionID, label, root, irData, prior)
 	if err != nil {
 		return 0, err
 	}
@@ -54,10 +58,16 @@ func (db *DB) RollAutoSnapshot(repoID int64, sessionID, root string, irData *ir.
 		return 0, fmt.Errorf("begin roll tx: %w", err)
 	}
 	defer tx.Rollback()
+	// Resolve stable IDs against the history BEFORE the delete: the auto
+	// snapshot being replaced is usually the freshest record of a rename.
+	prior, err := loadPriorSymbols(tx, repoID)
+	if err != nil {
+		return 0, err
+	}
 	if err := deleteAutoSnapshotsTx(tx, repoID); err != nil {
 		return 0, err
 	}
-	id, err := writeSnapshotTx(tx, repoID, sessionID, autoSnapshotLabel, root, irData)
+	id, err := writeSnapshotTx(tx, repoID, sessionID, autoSnapshotLabel, root, irData, prior)
 	if err != nil {
 		return 0, err
 	}
@@ -70,8 +80,10 @@ func (db *DB) RollAutoSnapshot(repoID int64, sessionID, root string, irData *ir.
 // writeSnapshotTx inserts a full IR snapshot using the provided transaction and
 // returns the new snapshot ID. The insert logic lives here so SaveSnapshot and
 // RollAutoSnapshot share it (one writes in its own tx; the other prepends an
-// atomic delete of the prior auto snapshot).
-func writeSnapshotTx(tx *sql.Tx, repoID int64, sessionID, label, root string, irData *ir.IR) (int64, error) {
+// atomic delete of the prior auto snapshot). prior is the repo's previous
+// snapshot (see loadPriorSymbols), against which each symbol's stable ID is
+// resolved.
+func writeSnapshotTx(tx *sql.Tx, repoID int64, sessionID, label, root string, irData *ir.IR, prior []priorSymbol) (int64, error) {
 	ts := time.Now().UTC().Format(time.RFC3339)
 	res, err := tx.Exec(
 		`INSERT INTO snapshots (repo_id, session_id, label, timestamp, root, root_hash) VALUES (?, ?, ?, ?, ?, ?)`,
@@ -99,7 +111,7 @@ func writeSnapshotTx(tx *sql.Tx, repoID int64, sessionID, label, root string, ir
 		return 0, fmt.Errorf("prepare file insert: %w", err)
 	}
 	defer fileStmt.Close()
-	symStmt, err := tx.Prepare(`INSERT INTO symbols (file_id, name, kind, sig_hash) VALUES (?, ?, ?, ?)`)
+	symStmt, err := tx.Prepare(`INSERT INTO symbols (file_id, name, kind, sig_hash, stable_id) VALUES (?, ?, ?, ?, ?)`)
 	if err != nil {
 		return 0, fmt.Errorf("prepare symbol insert: %w", err)
 	}
@@ -112,6 +124,8 @@ func writeSnapshotTx(tx *sql.Tx, repoID int64, sessionID, label, root string, ir
 	}
 	defer refStmt.Close()
 
+	stableIDs := assignStableIDs(prior, irData)
+
 	for _, path := range paths {
 		file := irData.Files[path]
 		fRes, err := fileStmt.Exec(snapshotID, path, file.Hash)
@@ -126,9 +140,9 @@ func writeSnapshotTx(tx *sql.Tx, repoID int64, sessionID, label, root string, ir
 		// Insert symbols (functions, classes, exports, imports). sig_hash is the
 		// per-symbol body hash (empty unless the parser produced one — only
 		// AST-extracted functions/methods carry it today). FileIR.Symbols is the
-		// canonical, pre-correlated set, so no kind:name lookup is needed.
+		// canonical, pre-correlated set; stable_id is resolved per kind:name.
 		for _, sym := range file.Symbols {
-			if _, err := symStmt.Exec(fileID, sym.Name, sym.Kind, sym.Hash); err != nil {
+			if _, err := symStmt.Exec(fileID, sym.Name, sym.Kind, sym.Hash, stableIDs[path][sym.Kind+":"+sym.Name]); err != nil {
 				return 0, fmt.Errorf("insert symbol %q: %w", sym.Name, err)
 			}
 		}
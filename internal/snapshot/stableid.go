@@ -0,0 +1,287 @@
+package snapshot
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// migrateV10 adds symbols.stable_id — an identity for a symbol that survives
+// across snapshots, so embeddings, annotations, or agent memory can be keyed to
+// "this function" rather than to a (path, name) pair that a rename or file move
+// silently invalidates. Existing rows get an empty ID (the column default);
+// readers treat that as the derived ir.SymbolID of the row's own key — exactly
+// the ID the row would have been assigned had it been written after this
+// migration with no prior history. Purely additive, like every migration here (see
+// migrateV9 for why a table rebuild is off the table).
+func migrateV10(tx *sql.Tx) error {
+	stmts := []string{
+		`ALTER TABLE symbols ADD COLUMN stable_id TEXT NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_symbols_stable_id ON symbols(stable_id)`,
+	}
+	return execAll(tx, stmts)
+}
+
+// priorSymbol is one symbol row of the repo's previous snapshot, as seen by
+// assignStableIDs when a new snapshot is written.
+type priorSymbol struct {
+	path, kind, name string
+	hash             string // per-symbol body hash; "" when the parser had none
+	fileHash         string // content hash of the file the symbol lived in
+	id               string // resolved stable ID (never "")
+}
+
+// symKey is the (path, kind, name) identity of a symbol within one snapshot.
+type symKey struct{ path, kind, name string }
+
+// loadPriorSymbols returns every symbol of repoID's most recent snapshot (any
+// label, including the rolling "auto" one — it is the freshest history there
+// is). Empty when the repo has no snapshots yet. Pre-V10 rows carry no stored
+// ID and resolve to their derived one.
+func loadPriorSymbols(tx *sql.Tx, repoID int64) ([]priorSymbol, error) {
+	rows, err := tx.Query(
+		`SELECT f.path, s.kind, s.name, s.sig_hash, f.content_hash, s.stable_id
+		 FROM symbols s
+		 JOIN files f ON f.id = s.file_id
+		 WHERE f.snapshot_id = (
+			SELECT id FROM snapshots WHERE repo_id = ? ORDER BY timestamp DESC, id DESC LIMIT 1
+		 )
+		 ORDER BY f.path, s.kind, s.name`,
+		repoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load prior symbols: %w", err)
+	}
+	defer rows.Close()
+
+	var out []priorSymbol
+	for rows.Next() {
+		var p priorSymbol
+		if err := rows.Scan(&p.path, &p.kind, &p.name, &p.hash, &p.fileHash, &p.id); err != nil {
+			return nil, fmt.Errorf("scan prior symbol: %w", err)
+		}
+		if p.id == "" {
+			p.id = ir.SymbolID(p.path, p.kind, p.name)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// renameTrackedKinds are the symbol kinds whose identity can be carried across
+// a rename or move. An import's identity IS its module path — a changed import
+// is a different import, never a renamed one — so import, import_name, and
+// export_wildcard rows always take their derived ID.
+var renameTrackedKinds = map[string]bool{"function": true, "class": true, "export": true}
+
+// assignStableIDs resolves the stable ID of every symbol in irData against the
+// previous snapshot's symbols. Each rule below only fires on a UNIQUE match on
+// both sides — an ambiguous candidate set falls through rather than guessing —
+// so the result is independent of map iteration order and byte-identical
+// across runs:
+//
+//  1. Same (path, kind, name) as a prior symbol: keep its ID (which may itself
+//     have been inherited by an earlier rename).
+//  2. File moved: the prior file is gone, a new file has identical content, and
+//     the symbol's (kind, name) matches — inherit. Covers hashless symbols
+//     (exports, regex-parsed definitions) that rule 3 cannot see.
+//  3. Body moved: same kind and the same non-empty body hash as an unmatched
+//     prior symbol anywhere in the repo — a function cut from one file and
+//     pasted verbatim into another.
+//  4. Renamed in place: within one file, exactly one prior symbol of a kind
+//     disappeared and exactly one new one of that kind appeared. Body hashes
+//     cover the name, so a rename always changes the hash and rule 3 never
+//     catches it; the one-for-one shape is the rename signal instead.
+//
+// Anything left takes its derived ir.SymbolID. Returns path → "kind:name" → ID.
+func assignStableIDs(prior []priorSymbol, irData *ir.IR) map[string]map[string]string {
+	out := make(map[string]map[string]string, len(irData.Files))
+	set := func(k symKey, id string) {
+		if out[k.path] == nil {
+			out[k.path] = make(map[string]string)
+		}
+		out[k.path][k.kind+":"+k.name] = id
+	}
+
+	byKey := make(map[symKey]int, len(prior))
+	priorFiles := make(map[string]bool)
+	for i, p := range prior {
+		byKey[symKey{p.path, p.kind, p.name}] = i
+		priorFiles[p.path] = true
+	}
+	consumed := make([]bool, len(prior))
+
+	// Unresolved new symbols, in deterministic (path, kind, name) order.
+	type newSym struct {
+		key      symKey
+		hash     string
+		fileHash string
+	}
+	var pending []newSym
+	paths := make([]string, 0, len(irData.Files))
+	for p := range irData.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		f := irData.Files[path]
+		for _, s := range f.Symbols {
+			k := symKey{path, s.Kind, s.Name}
+			if i, ok := byKey[k]; ok { // rule 1
+				set(k, prior[i].id)
+				consumed[i] = true
+				continue
+			}
+			pending = append(pending, newSym{key: k, hash: s.Hash, fileHash: f.Hash})
+		}
+	}
+
+	// inherit runs one heuristic: group unconsumed prior and pending new symbols
+	// by the rule's match key, and link only the groups that are one-to-one.
+	inherit := func(priorKey func(p priorSymbol) (string, bool), newKey func(n newSym) (string, bool)) {
+		priorBy := make(map[string][]int)
+		for i, p := range prior {
+			if consumed[i] || !renameTrackedKinds[p.kind] {
+				continue
+			}
+			if k, ok := priorKey(p); ok {
+				priorBy[k] = append(priorBy[k], i)
+			}
+		}
+		newBy := make(map[string][]int)
+		for j, n := range pending {
+			if !renameTrackedKinds[n.key.kind] {
+				continue
+			}
+			if k, ok := newKey(n); ok {
+				newBy[k] = append(newBy[k], j)
+			}
+		}
+		var rest []newSym
+		linked := make(map[int]bool)
+		for k, js := range newBy {
+			is := priorBy[k]
+			if len(js) != 1 || len(is) != 1 {
+				continue
+			}
+			set(pending[js[0]].key, prior[is[0]].id)
+			consumed[is[0]] = true
+			linked[js[0]] = true
+		}
+		for j, n := range pending {
+			if !linked[j] {
+				rest = append(rest, n)
+			}
+		}
+		pending = rest
+	}
+
+	// Rule 2: whole-file move (prior path gone, new path new, same content).
+	inherit(
+		func(p priorSymbol) (string, bool) {
+			if _, live := irData.Files[p.path]; live {
+				return "", false
+			}
+			return p.fileHash + "\x00" + p.kind + "\x00" + p.name, true
+		},
+		func(n newSym) (string, bool) {
+			if priorFiles[n.key.path] {
+				return "", false
+			}
+			return n.fileHash + "\x00" + n.key.kind + "\x00" + n.key.name, true
+		},
+	)
+	// Rule 3: verbatim body move.
+	inherit(
+		func(p priorSymbol) (string, bool) { return p.kind + "\x00" + p.hash, p.hash != "" },
+		func(n newSym) (string, bool) { return n.key.kind + "\x00" + n.hash, n.hash != "" },
+	)
+	// Rule 4: one-for-one rename within a file.
+	inherit(
+		func(p priorSymbol) (string, bool) { return p.path + "\x00" + p.kind, true },
+		func(n newSym) (string, bool) { return n.key.path + "\x00" + n.key.kind, true },
+	)
+
+	for _, n := range pending {
+		set(n.key, ir.SymbolID(n.key.path, n.key.kind, n.key.name))
+	}
+	return out
+}
+
+// StableIDs returns the stable ID of every symbol in snapshotID, keyed by path
+// then "kind:name". Rows written before schema V10 resolve to their derived
+// ir.SymbolID.
+func (db *DB) StableIDs(snapshotID int64) (map[string]map[string]string, error) {
+	rows, err := db.conn.Query(
+		`SELECT f.path, s.kind, s.name, s.stable_id
+		 FROM symbols s
+		 JOIN files f ON f.id = s.file_id
+		 WHERE f.snapshot_id = ?`,
+		snapshotID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load stable ids: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]map[string]string)
+	for rows.Next() {
+		var path, kind, name, id string
+		if err := rows.Scan(&path, &kind, &name, &id); err != nil {
+			return nil, fmt.Errorf("scan stable id: %w", err)
+		}
+		if id == "" {
+			id = ir.SymbolID(path, kind, name)
+		}
+		if out[path] == nil {
+			out[path] = make(map[string]string)
+		}
+		out[path][kind+":"+name] = id
+	}
+	return out, rows.Err()
+}
+
+// SymbolOccurrence is one sighting of a stable symbol ID in a stored snapshot.
+type SymbolOccurrence struct {
+	SnapshotID int64
+	Label      string
+	Timestamp  time.Time
+	Path       string
+	Kind       string
+	Name       string
+}
+
+// SymbolTrail returns every snapshot of repoID in which stableID appears,
+// oldest first — the symbol's history under whatever names and paths it has
+// carried. Only post-V10 rows carry a stored ID, so the trail starts at the
+// first snapshot written by a binary with schema V10.
+func (db *DB) SymbolTrail(repoID int64, stableID string) ([]SymbolOccurrence, error) {
+	rows, err := db.conn.Query(
+		`SELECT sn.id, sn.label, sn.timestamp, f.path, s.kind, s.name
+		 FROM symbols s
+		 JOIN files f ON f.id = s.file_id
+		 JOIN snapshots sn ON sn.id = f.snapshot_id
+		 WHERE sn.repo_id = ? AND s.stable_id = ?
+		 ORDER BY sn.timestamp, sn.id, f.path, s.kind, s.name`,
+		repoID, stableID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("symbol trail %q: %w", stableID, err)
+	}
+	defer rows.Close()
+
+	var out []SymbolOccurrence
+	for rows.Next() {
+		var o SymbolOccurrence
+		var ts string
+		if err := rows.Scan(&o.SnapshotID, &o.Label, &ts, &o.Path, &o.Kind, &o.Name); err != nil {
+			return nil, fmt.Errorf("scan symbol trail: %w", err)
+		}
+		o.Timestamp, _ = time.Parse(time.RFC3339, ts)
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
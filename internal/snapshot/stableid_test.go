@@ -0,0 +1,179 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// idsOf saves irData as a new snapshot for repoID and returns its stable IDs.
+func idsOf(t *testing.T, db *DB, repoID int64, irData *ir.IR) map[string]map[string]string {
+	t.Helper()
+	snapID, err := db.SaveSnapshot(repoID, "s", "manual", "/r", irData)
+	if err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+	ids, err := db.StableIDs(snapID)
+	if err != nil {
+		t.Fatalf("StableIDs: %v", err)
+	}
+	return ids
+}
+
+func oneFileIR(path, fileHash string, syms ...ir.Symbol) *ir.IR {
+	return &ir.IR{Version: ir.IRVersion, RootHash: fileHash, Files: map[string]ir.FileIR{
+		path: {Hash: fileHash, Symbols: syms},
+	}}
+}
+
+// TestStableIDs_FirstSightingIsDerived pins that a symbol with no history gets
+// exactly ir.SymbolID of its key, so a consumer can predict it.
+func TestStableIDs_FirstSightingIsDerived(t *testing.T) {
+	db, _ := openTemp(t)
+	repoID, _ := db.EnrollRepo("r", "/r", "", 0)
+	ids := idsOf(t, db, repoID, oneFileIR("a.ts", "h1", ir.Symbol{Name: "foo", Kind: "function", Hash: "b1"}))
+	if got, want := ids["a.ts"]["function:foo"], ir.SymbolID("a.ts", "function", "foo"); got != want {
+		t.Errorf("first-sighting id = %q, want derived %q", got, want)
+	}
+}
+
+// TestStableIDs_SurvivesRenameInPlace: foo → bar in the same file (body hash
+// changes with the name) keeps foo's ID, and the ID follows through a second
+// rename, so the lineage is transitive rather than one hop deep.
+func TestStableIDs_SurvivesRenameInPlace(t *testing.T) {
+	db, _ := openTemp(t)
+	repoID, _ := db.EnrollRepo("r", "/r", "", 0)
+	first := idsOf(t, db, repoID, oneFileIR("a.ts", "h1",
+		ir.Symbol{Name: "foo", Kind: "function", Hash: "b1"},
+		ir.Symbol{Name: "keep", Kind: "function", Hash: "k1"}))
+	orig := first["a.ts"]["function:foo"]
+
+	second := idsOf(t, db, repoID, oneFileIR("a.ts", "h2",
+		ir.Symbol{Name: "bar", Kind: "function", Hash: "b2"},
+		ir.Symbol{Name: "keep", Kind: "function", Hash: "k1"}))
+	if got := second["a.ts"]["function:bar"]; got != orig {
+		t.Errorf("renamed symbol id = %q, want inherited %q", got, orig)
+	}
+
+	third := idsOf(t, db, repoID, oneFileIR("a.ts", "h3",
+		ir.Symbol{Name: "baz", Kind: "function", Hash: "b3"},
+		ir.Symbol{Name: "keep", Kind: "function", Hash: "k1"}))
+	if got := third["a.ts"]["function:baz"]; got != orig {
+		t.Errorf("second rename id = %q, want original %q", got, orig)
+	}
+
+	trail, err := db.SymbolTrail(repoID, orig)
+	if err != nil {
+		t.Fatalf("SymbolTrail: %v", err)
+	}
+	var names []string
+	for _, o := range trail {
+		names = append(names, o.Name)
+	}
+	if len(names) != 3 || names[0] != "foo" || names[1] != "bar" || names[2] != "baz" {
+		t.Errorf("trail names = %v, want [foo bar baz]", names)
+	}
+}
+
+// TestStableIDs_AmbiguousRenameFallsBack: two removals and two additions of the
+// same kind in one file cannot be paired without guessing, so both new symbols
+// take fresh derived IDs rather than an order-dependent inheritance.
+func TestStableIDs_AmbiguousRenameFallsBack(t *testing.T) {
+	db, _ := openTemp(t)
+	repoID, _ := db.EnrollRepo("r", "/r", "", 0)
+	idsOf(t, db, repoID, oneFileIR("a.ts", "h1",
+		ir.Symbol{Name: "a", Kind: "function", Hash: "1"},
+		ir.Symbol{Name: "b", Kind: "function", Hash: "2"}))
+	ids := idsOf(t, db, repoID, oneFileIR("a.ts", "h2",
+		ir.Symbol{Name: "c", Kind: "function", Hash: "3"},
+		ir.Symbol{Name: "d", Kind: "function", Hash: "4"}))
+	for _, name := range []string{"c", "d"} {
+		if got, want := ids["a.ts"]["function:"+name], ir.SymbolID("a.ts", "function", name); got != want {
+			t.Errorf("%s id = %q, want derived %q (ambiguous must not inherit)", name, got, want)
+		}
+	}
+}
+
+// TestStableIDs_FileMoveAndBodyMove covers the cross-file rules: a whole file
+// renamed with identical content carries every definition (including hashless
+// exports), and a function pasted verbatim into another file carries its ID.
+func TestStableIDs_FileMoveAndBodyMove(t *testing.T) {
+	db, _ := openTemp(t)
+	repoID, _ := db.EnrollRepo("r", "/r", "", 0)
+	first := idsOf(t, db, repoID, &ir.IR{Version: ir.IRVersion, RootHash: "x", Files: map[string]ir.FileIR{
+		"old.ts": {Hash: "same", Symbols: []ir.Symbol{{Name: "API", Kind: "export"}}},
+		"src.ts": {Hash: "s1", Symbols: []ir.Symbol{{Name: "helper", Kind: "function", Hash: "body"}}},
+	}})
+	ids := idsOf(t, db, repoID, &ir.IR{Version: ir.IRVersion, RootHash: "y", Files: map[string]ir.FileIR{
+		"new.ts": {Hash: "same", Symbols: []ir.Symbol{{Name: "API", Kind: "export"}}},
+		"src.ts": {Hash: "s2"},
+		"dst.ts": {Hash: "d1", Symbols: []ir.Symbol{{Name: "helper", Kind: "function", Hash: "body"}}},
+	}})
+	if got, want := ids["new.ts"]["export:API"], first["old.ts"]["export:API"]; got != want {
+		t.Errorf("moved-file export id = %q, want %q", got, want)
+	}
+	if got, want := ids["dst.ts"]["function:helper"], first["src.ts"]["function:helper"]; got != want {
+		t.Errorf("moved-body function id = %q, want %q", got, want)
+	}
+}
+
+// TestStableIDs_ImportsNeverInherit: a swapped import is a different import,
+// not a renamed one — even in the one-for-one shape that renames a function.
+func TestStableIDs_ImportsNeverInherit(t *testing.T) {
+	db, _ := openTemp(t)
+	repoID, _ := db.EnrollRepo("r", "/r", "", 0)
+	idsOf(t, db, repoID, oneFileIR("a.ts", "h1", ir.Symbol{Name: "lodash", Kind: "import"}))
+	ids := idsOf(t, db, repoID, oneFileIR("a.ts", "h2", ir.Symbol{Name: "ramda", Kind: "import"}))
+	if got, want := ids["a.ts"]["import:ramda"], ir.SymbolID("a.ts", "import", "ramda"); got != want {
+		t.Errorf("import id = %q, want derived %q", got, want)
+	}
+}
+
+// TestStableIDs_RollAutoKeepsLineage: the rolling auto snapshot deletes its
+// predecessor in the same transaction, so the rename history must be read
+// before the delete or every edit would reset every renamed symbol's ID.
+func TestStableIDs_RollAutoKeepsLineage(t *testing.T) {
+	db, _ := openTemp(t)
+	repoID, _ := db.EnrollRepo("r", "/r", "", 0)
+	if _, err := db.RollAutoSnapshot(repoID, "s", "/r", oneFileIR("a.ts", "h1", ir.Symbol{Name: "foo", Kind: "class"})); err != nil {
+		t.Fatal(err)
+	}
+	id2, err := db.RollAutoSnapshot(repoID, "s", "/r", oneFileIR("a.ts", "h2", ir.Symbol{Name: "Foo", Kind: "class"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := db.StableIDs(id2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids["a.ts"]["class:Foo"], ir.SymbolID("a.ts", "class", "foo"); got != want {
+		t.Errorf("auto-rolled rename id = %q, want %q", got, want)
+	}
+}
+
+// TestStableIDs_PreV10RowsResolveToDerived simulates a row written before the
+// column existed (empty stable_id) and checks both readers fall back to the
+// derived ID, so history predating the migration still links up.
+func TestStableIDs_PreV10RowsResolveToDerived(t *testing.T) {
+	db, _ := openTemp(t)
+	repoID, _ := db.EnrollRepo("r", "/r", "", 0)
+	snapID, err := db.SaveSnapshot(repoID, "s", "manual", "/r", oneFileIR("a.ts", "h1", ir.Symbol{Name: "foo", Kind: "function"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.conn.Exec(`UPDATE symbols SET stable_id = ''`); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := db.StableIDs(snapID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ir.SymbolID("a.ts", "function", "foo")
+	if got := ids["a.ts"]["function:foo"]; got != want {
+		t.Errorf("pre-V10 id = %q, want derived %q", got, want)
+	}
+	next := idsOf(t, db, repoID, oneFileIR("a.ts", "h2", ir.Symbol{Name: "fooRenamed", Kind: "function"}))
+	if got := next["a.ts"]["function:fooRenamed"]; got != want {
+		t.Errorf("rename from a pre-V10 row = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package watch
+
+import (
+	"path"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// dirEvents subscribes to the OS's change notifications (inotify, kqueue,
+// ReadDirectoryChangesW) for the directories that hold indexed files: the
+// root and every ancestor of a file in the last stat walk. An event is only a
+// hint that the tree may have moved — Run answers it with a stat walk, whose
+// diff alone decides what changed — so a directory left unwatched, one
+// created empty since the last walk or one past the OS's watch limit, only
+// means its changes wait for the next poll.
+type dirEvents struct {
+	fw   *fsnotify.Watcher
+	root string
+	dirs map[string]bool // watched directories, by IR key ("." for the root)
+	// full is set once an Add has failed, most likely on the OS's watch
+	// limit; no more are tried, so the warning comes once.
+	full bool
+}
+
+// openDirEvents starts notifications for the directories of tree.
+func openDirEvents(root string, tree map[string]ir.FileStat, warn func(string, ...any)) (*dirEvents, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	e := &dirEvents{fw: fw, root: root, dirs: make(map[string]bool)}
+	e.sync(tree, warn)
+	return e, nil
+}
+
+// sync watches the directories of tree not yet watched and drops those no
+// longer in it.
+func (e *dirEvents) sync(tree map[string]ir.FileStat, warn func(string, ...any)) {
+	want := map[string]bool{".": true}
+	for key := range tree {
+		for d := path.Dir(key); !want[d]; d = path.Dir(d) {
+			want[d] = true
+		}
+	}
+	for d := range e.dirs {
+		if !want[d] {
+			// A removed directory's watch is already gone; the error says so.
+			e.fw.Remove(filepath.Join(e.root, filepath.FromSlash(d)))
+			delete(e.dirs, d)
+		}
+	}
+	if e.full {
+		return
+	}
+	for d := range want {
+		if e.dirs[d] {
+			continue
+		}
+		if err := e.fw.Add(filepath.Join(e.root, filepath.FromSlash(d))); err != nil {
+			warn("Warning: watch %s: file events cover part of the tree (%v); the poll covers the rest\n", e.root, err)
+			e.full = true
+			return
+		}
+		e.dirs[d] = true
+	}
+}
+
+// close stops the notifications.
+func (e *dirEvents) close() { e.fw.Close() }
@@ -0,0 +1,228 @@
+package watch
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/store"
+)
+
+// journalSlack widens a journal's Since when it is replayed, so a file
+// written just before the walk it names, on a filesystem with coarse mtimes
+// (FAT's are 2s), still counts as changed.
+const journalSlack = 2 * time.Second
+
+// The journal is JSON lines: one journalHeader, then one journalEntry per
+// change the Watcher saw after the snapshot the header names. Each entry is
+// synced as it is written, so after a crash the journal lists every change
+// the watcher had noticed and not yet published.
+type journalHeader struct {
+	Root string `json:"root"`
+	// RootHash is the published snapshot's; the journal only applies to a
+	// saved IR with this hash.
+	RootHash string `json:"root_hash"`
+	// Since is when the stat walk behind that snapshot began. A change made
+	// later, noticed or not, left its file an mtime no earlier than this.
+	Since time.Time `json:"since"`
+}
+
+type journalEntry struct {
+	// Path is an IR key whose size or mtime moved, or which appeared or
+	// vanished.
+	Path string `json:"path,omitempty"`
+	// All records a Notify: a change at an unknown path, which only a full
+	// Update can be sure to pick up.
+	All bool `json:"all,omitempty"`
+}
+
+// JournalPath returns root's journal location, next to its marker under
+// $RUNECHO_HOME/watch.
+func JournalPath(root string) (string, error) {
+	return stateFile(root, ".journal")
+}
+
+// journal is the Watcher's open journal file; a nil *journal is disabled.
+type journal struct {
+	path string
+	f    *os.File
+}
+
+// checkpoint starts the journal over for s, built from a walk that began at
+// walked. The header is written atomically, so a crash leaves either the old
+// journal or the new one.
+func (w *Watcher) checkpoint(s *Snapshot, walked time.Time) {
+	if w.cfg.Journal == "" {
+		return
+	}
+	w.closeJournal()
+	data, err := json.Marshal(journalHeader{Root: filepath.Clean(w.cfg.Root), RootHash: s.IR.RootHash, Since: walked})
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(w.cfg.Journal), 0700)
+	}
+	if err == nil {
+		err = store.AtomicWriteFile(w.cfg.Journal, append(data, '\n'))
+	}
+	var f *os.File
+	if err == nil {
+		f, err = os.OpenFile(w.cfg.Journal, os.O_WRONLY|os.O_APPEND, 0600)
+	}
+	if err != nil {
+		w.dropJournal(err)
+		return
+	}
+	w.journal = &journal{path: w.cfg.Journal, f: f}
+}
+
+// record appends changes to the journal: the IR keys in paths, or, with
+// all, a change it cannot name.
+func (w *Watcher) record(paths []string, all bool) {
+	if w.journal == nil {
+		return
+	}
+	var buf []byte
+	add := func(e journalEntry) {
+		line, _ := json.Marshal(e)
+		buf = append(append(buf, line...), '\n')
+	}
+	if all {
+		add(journalEntry{All: true})
+	}
+	for _, p := range paths {
+		add(journalEntry{Path: p})
+	}
+	if _, err := w.journal.f.Write(buf); err != nil {
+		w.dropJournal(err)
+		return
+	}
+	if err := w.journal.f.Sync(); err != nil {
+		w.dropJournal(err)
+	}
+}
+
+// dropJournal gives up on the journal after a failed write. The file goes
+// too: a journal missing a change must not be replayed as if complete. The
+// next checkpoint starts a new one.
+func (w *Watcher) dropJournal(err error) {
+	w.cfg.Warn("Warning: watch %s: journal disabled until the next publish: %v\n", w.cfg.Root, err)
+	w.closeJournal()
+	os.Remove(w.cfg.Journal)
+}
+
+func (w *Watcher) closeJournal() {
+	if w.journal != nil {
+		w.journal.f.Close()
+		w.journal = nil
+	}
+}
+
+// readJournal parses the journal at path. It returns nil, with no error,
+// when there is none. A torn last line — the crash came mid-write — reads as
+// an All entry, since the change it was recording is unknown.
+func readJournal(path string) (*journalHeader, []journalEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	if !sc.Scan() {
+		return nil, nil, fmt.Errorf("journal %s: missing header", path)
+	}
+	var h journalHeader
+	if err := json.Unmarshal(sc.Bytes(), &h); err != nil {
+		return nil, nil, fmt.Errorf("journal %s: bad header: %w", path, err)
+	}
+	var entries []journalEntry
+	for sc.Scan() {
+		var e journalEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			e = journalEntry{All: true}
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, fmt.Errorf("journal %s: %w", path, err)
+	}
+	return &h, entries, nil
+}
+
+// replayJournal brings seed up to tree, a StatTree of Root, by reparsing
+// only the files the journal implicates: those it lists, those modified
+// since its header's walk, and those added or deleted since seed. It reports
+// false when the journal cannot vouch for seed (absent, another root or IR,
+// or a change it could not name) and the caller must Update instead.
+//
+// The Stats it returns are reconstructed from the IR and the walk: files
+// that failed to parse before the crash are not counted in ParseErrors.
+func (w *Watcher) replayJournal(seed *ir.IR, tree map[string]ir.FileStat) (*ir.IR, ir.Stats, int, bool) {
+	h, entries, err := readJournal(w.cfg.Journal)
+	if err != nil {
+		w.cfg.Warn("Warning: watch %s: ignoring journal: %v\n", w.cfg.Root, err)
+	}
+	if h == nil || h.Root != filepath.Clean(w.cfg.Root) || h.RootHash != seed.RootHash || seed.VerifyRootHash() != nil {
+		return nil, ir.Stats{}, 0, false
+	}
+	changed := make(map[string]bool)
+	for _, e := range entries {
+		if e.All {
+			return nil, ir.Stats{}, 0, false
+		}
+		changed[e.Path] = true
+	}
+	since := h.Since.Add(-journalSlack)
+	for key, st := range tree {
+		if _, ok := seed.Files[key]; !ok || !st.ModTime.Before(since) {
+			changed[key] = true
+		}
+	}
+	for key := range seed.Files {
+		if _, ok := tree[key]; !ok {
+			changed[key] = true
+		}
+	}
+	paths := make([]string, 0, len(changed))
+	for key := range changed {
+		paths = append(paths, filepath.Join(w.cfg.Root, filepath.FromSlash(key)))
+	}
+	sort.Strings(paths)
+	irData, _, err := w.cfg.Generator.UpdatePaths(seed, w.cfg.Root, paths)
+	if err != nil {
+		w.cfg.Warn("Warning: watch %s: journal replay failed: %v\n", w.cfg.Root, err)
+		return nil, ir.Stats{}, 0, false
+	}
+	stats := ir.Stats{SupportedSeen: len(tree), Indexed: len(irData.Files)}
+	for _, f := range irData.Files {
+		if f.ParseSkipped != "" {
+			stats.ParseSkipped++
+		}
+	}
+	return irData, stats, len(changed), true
+}
+
+// changedKeys lists, sorted, the keys whose stat differs between two walks.
+func changedKeys(before, after map[string]ir.FileStat) []string {
+	var keys []string
+	for k, a := range after {
+		if b, ok := before[k]; !ok || b.Size != a.Size || !b.ModTime.Equal(a.ModTime) || b.Abs != a.Abs {
+			keys = append(keys, k)
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,95 @@
+package watch
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/store"
+)
+
+// Marker records a clean shutdown: the IR saved for Root had RootHash and was
+// built from a tree with Stamp. A watcher starting on a tree that still has
+// that stamp, with that IR still on disk, can publish the IR without
+// re-hashing a single file (see Config.SeedStamp).
+//
+// The marker is only written once the last snapshot is saved, and is consumed
+// (removed) on the next start, so a daemon that crashes afterwards leaves no
+// marker behind and its successor falls back to an incremental Update.
+type Marker struct {
+	Root      string   `json:"root"`
+	IRVersion int      `json:"ir_version"`
+	RootHash  string   `json:"root_hash"`
+	Stamp     string   `json:"stamp"`
+	Stats     ir.Stats `json:"stats"`
+}
+
+// MarkerPath returns root's marker location: a file under
+// $RUNECHO_HOME/watch named for root, not a file in the repo, so it works for
+// an IR kept in a storage backend too.
+func MarkerPath(root string) (string, error) {
+	return stateFile(root, ".json")
+}
+
+// stateFile names root's file with extension ext under $RUNECHO_HOME/watch.
+func stateFile(root, ext string) (string, error) {
+	dir, err := store.RunechoDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(root)))
+	return filepath.Join(dir, "watch", fmt.Sprintf("%x%s", sum[:8], ext)), nil
+}
+
+// WriteMarker records s as root's clean shutdown at path.
+func WriteMarker(path, root string, s *Snapshot) error {
+	data, err := json.Marshal(Marker{
+		Root:      filepath.Clean(root),
+		IRVersion: s.IR.Version,
+		RootHash:  s.IR.RootHash,
+		Stamp:     s.Stamp,
+		Stats:     s.Stats,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("create marker dir: %w", err)
+	}
+	return store.AtomicWriteFile(path, data)
+}
+
+// TakeMarker reads and removes the marker at path. It returns nil, with no
+// error, when there is none.
+func TakeMarker(path string) (*Marker, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, fmt.Errorf("consume marker: %w", err)
+	}
+	var m Marker
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("decode marker: %w", err)
+	}
+	return &m, nil
+}
+
+// Trusts reports whether m vouches for seed as root's IR: same root, same
+// format, and the IR on disk is the one the marker was written for, intact.
+// The tree-stamp comparison is left to New, which takes the stamp anyway.
+func (m *Marker) Trusts(root string, seed *ir.IR) bool {
+	return m != nil && seed != nil &&
+		m.Root == filepath.Clean(root) &&
+		m.IRVersion == ir.IRVersion && seed.Version == ir.IRVersion &&
+		m.RootHash == seed.RootHash && m.Stamp != "" &&
+		seed.VerifyRootHash() == nil
+}
@@ -0,0 +1,428 @@
+// Package watch keeps one project's IR current while its tree changes under
+// it. A Watcher polls the tree's stamp (ir.Generator.TreeStamp — sizes and
+// mtimes, no file reads), and when the tree moves it waits for it to stop
+// moving before running a single incremental Update. A branch switch that
+// rewrites thousands of files therefore costs one Update, not thousands, and
+// the IR it produces describes one state of the tree rather than a blend of
+// the states it passed through on the way. With Config.Events the OS's file
+// events prompt the stat walk too, so a change need not wait for the poll.
+//
+// Readers go through Snapshot, which returns the last published IR. A publish
+// swaps one pointer, so a reader sees either the previous IR or the next one,
+// never an Update in progress.
+//
+// With Config.Journal set, every change the Watcher notices between publishes
+// is appended to a journal on disk. A Watcher that crashed mid-burst leaves
+// it behind, and its successor reparses just the files it implicates instead
+// of re-hashing the whole tree.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+)
+
+// Defaults for Config's zero values.
+const (
+	// DefaultPoll is how often the tree's stamp is taken. A stamp is a stat
+	// walk, so this trades idle cost for how soon a change is noticed.
+	DefaultPoll = time.Second
+	// DefaultDebounce is how long the stamp must hold still before an Update
+	// runs. Long enough to cover the gaps inside a checkout or a formatter
+	// pass, short enough that a single save shows up promptly.
+	DefaultDebounce = 500 * time.Millisecond
+	// DefaultRescan is the CLI's full-rescan period. The poll misses little,
+	// so an hourly pass is plenty to heal what it does.
+	DefaultRescan = time.Hour
+	// DefaultRescanGap is the least time between two rescans asked for with
+	// Rescan. A plugin that asks on every focus change gets one per minute,
+	// not one per request.
+	DefaultRescanGap = time.Minute
+)
+
+// Config configures a Watcher.
+type Config struct {
+	// Root is the project directory.
+	Root string
+	// Generator builds and updates the IR; required.
+	Generator *ir.Generator
+	// Seed, when non-nil, is a previously saved IR for Root. New updates it
+	// incrementally instead of generating from scratch.
+	Seed *ir.IR
+	// SeedStamp, when non-empty, is the tree stamp Seed was built from — a
+	// clean-shutdown marker's (see Marker). If the tree still has that stamp,
+	// New publishes Seed as is, with SeedStats, and skips even the
+	// incremental Update and the full re-hash it costs.
+	SeedStamp string
+	SeedStats ir.Stats
+	// Poll and Debounce: 0 means DefaultPoll / DefaultDebounce.
+	Poll     time.Duration
+	Debounce time.Duration
+	// Rescan, when positive, is the period of a full rescan: an Update run
+	// whether or not the poll saw a change, which re-hashes every file and so
+	// heals any edit the poll missed. Each period is jittered by up to a
+	// tenth either way, so daemons and projects started together drift apart.
+	// 0 disables the periodic rescan; Rescan still works.
+	Rescan time.Duration
+	// RescanGap is the least time between two rescans asked for with
+	// Watcher.Rescan; 0 means DefaultRescanGap.
+	RescanGap time.Duration
+	// OnPublish, when non-nil, is called with each newly published snapshot
+	// (the initial one included), from the goroutine that published it. The
+	// CLI saves .ai/ir.json here.
+	OnPublish func(*Snapshot)
+	// OnStop, when non-nil, is called with the last published snapshot when
+	// Run returns because its ctx is done — after any in-flight Update has
+	// finished and been published. The CLI writes its clean-shutdown marker
+	// here.
+	OnStop func(*Snapshot)
+	// Journal, when non-empty, is the path of the crash-recovery journal
+	// (see JournalPath). New replays one left there against Seed, and the
+	// Watcher keeps it for its successor until Run returns cleanly and
+	// removes it. Two Watchers must not share one.
+	Journal string
+	// Events subscribes to the OS's file-change notifications for the
+	// directories holding indexed files (see dirEvents). A burst of events
+	// that has gone quiet for Debounce triggers a stat walk at once rather
+	// than at the next poll, so a save is published about Debounce after it
+	// lands. The poll still runs and still catches what the events miss, so
+	// with Events a long Poll costs little. If notifications cannot be set
+	// up, Run warns and polls alone.
+	Events bool
+	// Warn routes non-fatal diagnostics (a failed Update). nil discards them.
+	Warn func(format string, args ...any)
+}
+
+// Snapshot is one published, internally consistent state of the project. It
+// and the IR it points to are shared by every reader and must not be
+// modified.
+type Snapshot struct {
+	IR    *ir.IR
+	Stats ir.Stats
+	// Seq counts publishes, starting at 1 for the initial IR.
+	Seq uint64
+	// Events is how many tree changes (stamp moves and Notify calls) this
+	// publish absorbed: 0 for the initial IR and for a rescan that healed a
+	// missed change, the number of files reparsed for a journal replay.
+	Events int
+	// Stamp is the tree stamp (ir.Generator.TreeStamp) taken before IR was
+	// built. A tree that still has it holds nothing IR is missing.
+	Stamp string
+	// Changes is what this publish changed from the previous snapshot's IR;
+	// empty for the initial IR.
+	Changes ir.ChangeSet
+}
+
+// Watcher maintains a project's IR. Create one with New, then call Run.
+type Watcher struct {
+	cfg    Config
+	snap   atomic.Pointer[Snapshot]
+	notify chan struct{}
+	// tree is the stat walk taken just before the initial build, so a change
+	// made between New and Run still counts as one.
+	tree    map[string]ir.FileStat
+	journal *journal
+	// events is the file-event subscription (Config.Events), nil when off
+	// or unavailable. Run closes it.
+	events *dirEvents
+	// rescan carries Rescan requests to Run; lastRescan is when Run last
+	// started one, in Unix nanoseconds (0 before the first).
+	rescan     chan time.Time
+	lastRescan atomic.Int64
+}
+
+// New builds the initial IR — a journal replay or an Update of cfg.Seed when
+// one is given, a full Generate otherwise — and publishes it, so Snapshot
+// never returns nil.
+func New(ctx context.Context, cfg Config) (*Watcher, error) {
+	if cfg.Generator == nil {
+		return nil, errors.New("watch: nil Generator")
+	}
+	if cfg.Poll <= 0 {
+		cfg.Poll = DefaultPoll
+	}
+	if cfg.Debounce <= 0 {
+		cfg.Debounce = DefaultDebounce
+	}
+	if cfg.RescanGap <= 0 {
+		cfg.RescanGap = DefaultRescanGap
+	}
+	if cfg.Warn == nil {
+		cfg.Warn = func(string, ...any) {}
+	}
+	w := &Watcher{cfg: cfg, notify: make(chan struct{}, 1), rescan: make(chan time.Time, 1)}
+	walked := time.Now()
+	tree, err := cfg.Generator.StatTree(ctx, cfg.Root)
+	if err != nil {
+		return nil, fmt.Errorf("watch %s: %w", cfg.Root, err)
+	}
+	w.tree = tree
+	if cfg.Events {
+		if w.events, err = openDirEvents(cfg.Root, tree, cfg.Warn); err != nil {
+			cfg.Warn("Warning: watch %s: no file events, polling only: %v\n", cfg.Root, err)
+		}
+	}
+	stamp := ir.StampOf(tree)
+	if cfg.Seed != nil && cfg.SeedStamp != "" && cfg.SeedStamp == stamp {
+		w.publish(cfg.Seed, cfg.SeedStats, 0, stamp, walked)
+		return w, nil
+	}
+	if cfg.Seed != nil && cfg.Journal != "" {
+		if irData, stats, n, ok := w.replayJournal(cfg.Seed, tree); ok {
+			cfg.Warn("%s: replayed the journal of an unclean shutdown; %d files reparsed\n", cfg.Root, n)
+			w.publish(irData, stats, n, stamp, walked)
+			return w, nil
+		}
+	}
+	var (
+		irData *ir.IR
+		stats  ir.Stats
+	)
+	if cfg.Seed != nil {
+		irData, stats, err = cfg.Generator.UpdateCtx(ctx, cfg.Seed, cfg.Root)
+	} else {
+		irData, stats, err = cfg.Generator.GenerateCtx(ctx, cfg.Root)
+	}
+	if err != nil {
+		if w.events != nil {
+			w.events.close()
+		}
+		return nil, fmt.Errorf("watch %s: %w", cfg.Root, err)
+	}
+	w.publish(irData, stats, 0, stamp, walked)
+	return w, nil
+}
+
+// Snapshot returns the last published state. It is safe to call from any
+// goroutine, including while Run is mid-Update.
+func (w *Watcher) Snapshot() *Snapshot { return w.snap.Load() }
+
+// Notify reports a change the poller may not see (an edit that keeps a file's
+// size within one mtime tick) or may see late. It counts as one event and
+// restarts the quiet period; it never blocks. Since it names no file, a crash
+// before the next publish costs the successor a full Update.
+func (w *Watcher) Notify() {
+	select {
+	case w.notify <- struct{}{}:
+	default: // one is already pending; they coalesce anyway
+	}
+}
+
+// Rescan asks Run for a full rescan, as if the periodic one were due, and
+// returns the earliest time it can start: now, unless one started less than
+// RescanGap ago. Requests are never dropped, only delayed and coalesced, and
+// the rescan still waits out a burst in progress. It never blocks.
+func (w *Watcher) Rescan() time.Time {
+	at := time.Now()
+	if last := w.lastRescan.Load(); last != 0 {
+		if gapEnd := time.Unix(0, last).Add(w.cfg.RescanGap); gapEnd.After(at) {
+			at = gapEnd
+		}
+	}
+	select {
+	case w.rescan <- at:
+	default: // one is already pending; it is no later than this one
+	}
+	return at
+}
+
+// nextRescan returns when the periodic rescan after one at from is due, or
+// the zero Time when it is disabled.
+func (w *Watcher) nextRescan(from time.Time) time.Time {
+	d := w.cfg.Rescan
+	if d <= 0 {
+		return time.Time{}
+	}
+	return from.Add(d - d/10 + rand.N(d/5+1))
+}
+
+// Run watches the tree until ctx is done and returns ctx.Err(). Changes are
+// coalesced: each stamp move or Notify restarts the quiet period, and only
+// when the tree has held still for Debounce does one Update run. If the tree
+// moves again while that Update runs, its result is discarded unpublished and
+// the quiet period starts over, so no snapshot mixes two states of the tree.
+// A failed Update is warned about and retried on the next change.
+//
+// An Update already running when ctx is done is finished, not abandoned, and
+// published if the tree held still through it: a shutdown during a branch
+// switch's Update should leave that Update's IR behind, not the one from
+// before the switch. It is still bounded by the Generator's own timeout.
+//
+// A rescan (Config.Rescan, Watcher.Rescan) runs the same Update with no event
+// pending, as soon as the tree is quiet. It publishes only if it finds
+// something the poll missed.
+//
+// With Config.Events, file events stand in for ticks: once they have been
+// quiet for Debounce, a stat walk runs, and a change it finds is taken as
+// already settled, so the Update follows straight away.
+//
+// A clean return removes the journal: nothing is pending, and the
+// clean-shutdown marker, not the journal, speaks for the saved IR.
+func (w *Watcher) Run(ctx context.Context) error {
+	tree := w.tree
+	ticker := time.NewTicker(w.cfg.Poll)
+	defer ticker.Stop()
+	var (
+		ev      = w.events
+		evC     <-chan fsnotify.Event // nil, never ready, without events
+		evErrC  <-chan error
+		settle  = time.NewTimer(time.Hour) // fires Debounce after the last event
+		lastEvt time.Time                  // when the latest event arrived
+	)
+	settle.Stop()
+	if ev != nil {
+		defer ev.close()
+		evC, evErrC = ev.fw.Events, ev.fw.Errors
+		// An edit between New's stat walk and the subscription raised no
+		// event; one walk now finds it.
+		settle.Reset(0)
+	}
+	setTree := func(t map[string]ir.FileStat) {
+		tree = t
+		if ev != nil {
+			ev.sync(t, w.cfg.Warn)
+		}
+	}
+	// rescanAt is when the next rescan is due; the zero Time means none is
+	// scheduled. The poll ticker checks it, so a rescan starts at most Poll
+	// late.
+	rescanAt := w.nextRescan(time.Now())
+	defer func() {
+		if ctx.Err() == nil {
+			return
+		}
+		if w.cfg.Journal != "" {
+			w.closeJournal()
+			os.Remove(w.cfg.Journal)
+		}
+		if w.cfg.OnStop != nil {
+			w.cfg.OnStop(w.Snapshot())
+		}
+	}()
+	var (
+		events  int       // changes since the last publish
+		lastHit time.Time // when the latest of them was seen
+	)
+	for {
+		settled := false
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-evC:
+			lastEvt = time.Now()
+			settle.Reset(w.cfg.Debounce)
+			continue
+		case err := <-evErrC:
+			// Most likely a dropped queue; the poll covers the gap.
+			w.cfg.Warn("Warning: watch %s: file events: %v\n", w.cfg.Root, err)
+			continue
+		case <-settle.C:
+			settled = true
+		case <-w.notify:
+			w.record(nil, true)
+			events++
+			lastHit = time.Now()
+			continue
+		case at := <-w.rescan:
+			if rescanAt.IsZero() || at.Before(rescanAt) {
+				rescanAt = at
+			}
+			continue
+		case <-ticker.C:
+		}
+
+		walked := time.Now()
+		cur, err := w.cfg.Generator.StatTree(ctx, w.cfg.Root)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			w.cfg.Warn("Warning: watch %s: %v\n", w.cfg.Root, err)
+			continue
+		}
+		if moved := changedKeys(tree, cur); len(moved) > 0 {
+			w.record(moved, false)
+			setTree(cur)
+			events++
+			if !settled {
+				lastHit = time.Now()
+				continue
+			}
+			// The events went quiet Debounce ago, and this walk saw the
+			// tree after that: the change is as old as the last event.
+			if lastEvt.After(lastHit) {
+				lastHit = lastEvt
+			}
+		}
+		rescanDue := !rescanAt.IsZero() && !time.Now().Before(rescanAt)
+		if events == 0 && !rescanDue || time.Since(lastHit) < w.cfg.Debounce {
+			continue
+		}
+		if rescanDue {
+			now := time.Now()
+			w.lastRescan.Store(now.UnixNano())
+			rescanAt = w.nextRescan(now)
+		}
+
+		// Detached from ctx so a shutdown lets it finish (see above).
+		updCtx := context.WithoutCancel(ctx)
+		irData, stats, err := w.cfg.Generator.UpdateCtx(updCtx, w.Snapshot().IR, w.cfg.Root)
+		if err != nil {
+			w.cfg.Warn("Warning: watch %s: update failed: %v\n", w.cfg.Root, err)
+			lastHit = time.Now()
+			continue
+		}
+		after, err := w.cfg.Generator.StatTree(updCtx, w.cfg.Root)
+		var moved []string
+		if err == nil {
+			moved = changedKeys(tree, after)
+		}
+		if err != nil || len(moved) > 0 {
+			// The tree moved under the Update; its IR may straddle two states.
+			if err == nil {
+				w.record(moved, false)
+				setTree(after)
+			}
+			events++
+			lastHit = time.Now()
+			continue
+		}
+		if events == 0 && irData.RootHash == w.Snapshot().IR.RootHash {
+			continue // a rescan that found nothing the poll missed
+		}
+		if events == 0 {
+			w.cfg.Warn("%s: rescan found changes the poll missed\n", w.cfg.Root)
+		}
+		w.publish(irData, stats, events, ir.StampOf(tree), walked)
+		events = 0
+	}
+}
+
+// publish makes irData current, hands it to OnPublish, and then — OnPublish
+// having saved it — starts the journal over from it. walked is when the stat
+// walk that irData was built after began.
+func (w *Watcher) publish(irData *ir.IR, stats ir.Stats, events int, stamp string, walked time.Time) {
+	var (
+		seq     uint64 = 1
+		changes ir.ChangeSet
+	)
+	if prev := w.snap.Load(); prev != nil {
+		seq = prev.Seq + 1
+		changes = ir.DiffFiles(prev.IR, irData)
+	}
+	s := &Snapshot{IR: irData, Stats: stats, Seq: seq, Events: events, Stamp: stamp, Changes: changes}
+	w.snap.Store(s)
+	if w.cfg.OnPublish != nil {
+		w.cfg.OnPublish(s)
+	}
+	w.checkpoint(s, walked)
+}
@@ -0,0 +1,464 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/parser"
+)
+
+func writeFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startWatcher runs a Watcher over root with short intervals and returns it
+// with a channel that receives every publish after the initial one.
+func startWatcher(t *testing.T, root string, debounce time.Duration) (*Watcher, <-chan *Snapshot) {
+	t.Helper()
+	return startWatcherConfig(t, Config{Root: root, Debounce: debounce})
+}
+
+// startWatcherConfig is startWatcher with more of cfg: its Generator and
+// Poll default to the test's, and OnPublish is taken.
+func startWatcherConfig(t *testing.T, cfg Config) (*Watcher, <-chan *Snapshot) {
+	t.Helper()
+	published := make(chan *Snapshot, 64)
+	ctx, cancel := context.WithCancel(context.Background())
+	if cfg.Generator == nil {
+		cfg.Generator = ir.NewGenerator(ir.GeneratorConfig{})
+	}
+	if cfg.Poll == 0 {
+		cfg.Poll = 5 * time.Millisecond
+	}
+	cfg.OnPublish = func(s *Snapshot) {
+		if s.Seq > 1 {
+			published <- s
+		}
+	}
+	w, err := New(ctx, cfg)
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	return w, published
+}
+
+func awaitPublish(t *testing.T, ch <-chan *Snapshot) *Snapshot {
+	t.Helper()
+	select {
+	case s := <-ch:
+		return s
+	case <-time.After(10 * time.Second):
+		t.Fatal("no publish within 10s")
+		return nil
+	}
+}
+
+func TestNew_PublishesInitialIR(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main\n\nfunc main() {}\n")
+	w, _ := startWatcher(t, root, 20*time.Millisecond)
+	s := w.Snapshot()
+	if s == nil || s.Seq != 1 || s.Events != 0 {
+		t.Fatalf("initial snapshot = %+v", s)
+	}
+	if _, ok := s.IR.Files["main.go"]; !ok {
+		t.Errorf("initial IR is missing main.go: %v", s.IR.Files)
+	}
+}
+
+// TestRun_CoalescesBurst writes a burst of files spaced well inside the quiet
+// period and expects exactly one publish that contains all of them.
+func TestRun_CoalescesBurst(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main\n")
+	w, published := startWatcher(t, root, 300*time.Millisecond)
+
+	const n = 40
+	for i := range n {
+		writeFile(t, root, fmt.Sprintf("pkg/f%02d.go", i), fmt.Sprintf("package pkg\n\nfunc F%d() {}\n", i))
+		time.Sleep(2 * time.Millisecond)
+	}
+	s := awaitPublish(t, published)
+	if got := len(s.IR.Files); got != n+1 {
+		t.Errorf("published IR has %d files, want %d", got, n+1)
+	}
+	if s.Seq != 2 || s.Events == 0 {
+		t.Errorf("snapshot Seq=%d Events=%d, want Seq=2 and Events>0", s.Seq, s.Events)
+	}
+	select {
+	case extra := <-published:
+		t.Errorf("burst published twice (Seq %d)", extra.Seq)
+	case <-time.After(500 * time.Millisecond):
+	}
+	if w.Snapshot() != s {
+		t.Error("Snapshot does not return the latest publish")
+	}
+	if err := s.IR.VerifyRootHash(); err != nil {
+		t.Errorf("published IR: %v", err)
+	}
+}
+
+// TestSnapshot_NeverPartial reads snapshots concurrently with a stream of
+// edits: every one it sees must be a complete IR of a single tree state.
+func TestSnapshot_NeverPartial(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.go", "package a\n\nfunc A0() {}\n")
+	writeFile(t, root, "b.go", "package a\n\nfunc B0() {}\n")
+	w, published := startWatcher(t, root, 20*time.Millisecond)
+
+	done := make(chan struct{})
+	var readerErr error
+	go func() {
+		defer close(done)
+		for range 2000 {
+			s := w.Snapshot()
+			if len(s.IR.Files) != 2 {
+				readerErr = fmt.Errorf("seq %d has %d files", s.Seq, len(s.IR.Files))
+				return
+			}
+			if err := s.IR.VerifyRootHash(); err != nil {
+				readerErr = fmt.Errorf("seq %d: %v", s.Seq, err)
+				return
+			}
+		}
+	}()
+	for i := 1; i <= 3; i++ {
+		writeFile(t, root, "a.go", fmt.Sprintf("package a\n\nfunc A%d() {}\n", i))
+		writeFile(t, root, "b.go", fmt.Sprintf("package a\n\nfunc B%d() {}\n", i))
+		awaitPublish(t, published)
+	}
+	<-done
+	if readerErr != nil {
+		t.Error(readerErr)
+	}
+}
+
+// writeUnstamped rewrites rel with content of the same size and restores its
+// mtime: an edit the stamp cannot see.
+func writeUnstamped(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, root, rel, content)
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestNotify_CatchesUnstampedEdit makes an edit invisible to the stamp and
+// relies on Notify.
+func TestNotify_CatchesUnstampedEdit(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.go", "package a\n\nfunc Old() {}\n")
+	w, published := startWatcher(t, root, 20*time.Millisecond)
+	writeUnstamped(t, root, "a.go", "package a\n\nfunc New() {}\n")
+	w.Notify()
+	s := awaitPublish(t, published)
+	if got := s.IR.Files["a.go"].Symbols; len(got) != 1 || got[0].Name != "New" {
+		t.Errorf("symbols after Notify = %+v, want New", got)
+	}
+}
+
+// slowParser handles .slow files: it signals started on its first call, then
+// takes delay to return one function per file.
+type slowParser struct {
+	once    *sync.Once
+	started chan struct{}
+	delay   time.Duration
+}
+
+func (p slowParser) SupportsExtension(ext string) bool { return ext == ".slow" }
+
+func (p slowParser) Parse(string) (parser.FileStructure, error) {
+	p.once.Do(func() { close(p.started) })
+	time.Sleep(p.delay)
+	return parser.FileStructure{Functions: []string{"slow"}}, nil
+}
+
+// TestRun_FinishesUpdateOnShutdown cancels Run while an Update is parsing and
+// expects that Update to be published and handed to OnStop, not dropped.
+func TestRun_FinishesUpdateOnShutdown(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main\n")
+	slow := slowParser{once: new(sync.Once), started: make(chan struct{}), delay: 300 * time.Millisecond}
+	stopped := make(chan *Snapshot, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := New(ctx, Config{
+		Root:      root,
+		Generator: ir.NewGenerator(ir.GeneratorConfig{Parsers: []parser.Parser{slow}}),
+		Poll:      5 * time.Millisecond,
+		Debounce:  20 * time.Millisecond,
+		OnStop:    func(s *Snapshot) { stopped <- s },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	writeFile(t, root, "a.slow", "x\n")
+	select {
+	case <-slow.started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the Update never started")
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+	s := <-stopped
+	if s.Seq != 2 || s != w.Snapshot() {
+		t.Fatalf("OnStop got seq %d, latest is %d; want the in-flight Update's snapshot", s.Seq, w.Snapshot().Seq)
+	}
+	if _, ok := s.IR.Files["a.slow"]; !ok {
+		t.Errorf("final snapshot is missing a.slow: %v", s.IR.Files)
+	}
+}
+
+// TestNew_TrustsMatchingSeedStamp: a seed whose stamp matches the tree is
+// published as is; one whose stamp is stale is updated.
+func TestNew_TrustsMatchingSeedStamp(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.go", "package a\n\nfunc A() {}\n")
+	gen := ir.NewGenerator(ir.GeneratorConfig{})
+	ctx := context.Background()
+	stamp, err := gen.TreeStamp(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A seed that disagrees with the tree: only trust can explain seeing it.
+	seed := &ir.IR{Version: ir.IRVersion, RootHashAlg: ir.CurrentRootHashAlg, Files: map[string]ir.FileIR{}}
+	seed.RootHash = ir.ComputeRootHash(seed.Files)
+
+	w, err := New(ctx, Config{Root: root, Generator: gen, Seed: seed, SeedStamp: stamp, SeedStats: ir.Stats{Indexed: 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := w.Snapshot(); s.IR != seed || s.Stats.Indexed != 7 || s.Stamp != stamp {
+		t.Errorf("matching stamp: snapshot %+v, want the seed as is", s)
+	}
+	w, err = New(ctx, Config{Root: root, Generator: gen, Seed: seed, SeedStamp: "stale"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := w.Snapshot(); s.IR == seed || len(s.IR.Files) != 1 {
+		t.Errorf("stale stamp: snapshot has %d files, want an updated IR", len(s.IR.Files))
+	}
+}
+
+func TestMarker_RoundTrip(t *testing.T) {
+	t.Setenv("RUNECHO_HOME", t.TempDir())
+	root := t.TempDir()
+	writeFile(t, root, "a.go", "package a\n")
+	w, err := New(context.Background(), Config{Root: root, Generator: ir.NewGenerator(ir.GeneratorConfig{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := w.Snapshot()
+	path, err := MarkerPath(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, err := TakeMarker(path); m != nil || err != nil {
+		t.Fatalf("TakeMarker with none = %v, %v", m, err)
+	}
+	if err := WriteMarker(path, root, s); err != nil {
+		t.Fatal(err)
+	}
+	m, err := TakeMarker(path)
+	if err != nil || m == nil {
+		t.Fatalf("TakeMarker = %v, %v", m, err)
+	}
+	if !m.Trusts(root, s.IR) || m.Stamp != s.Stamp {
+		t.Errorf("marker %+v does not vouch for its own snapshot", m)
+	}
+	if m.Trusts(t.TempDir(), s.IR) {
+		t.Error("marker vouches for another root")
+	}
+	other := *s.IR
+	other.RootHash = "0"
+	if m.Trusts(root, &other) {
+		t.Error("marker vouches for a different IR")
+	}
+	if again, _ := TakeMarker(path); again != nil {
+		t.Error("a marker can be taken twice")
+	}
+}
+
+// TestJournal_ReplaysAfterCrash lets a watcher journal a burst it never gets
+// to publish, then starts a successor on the same journal as if the first had
+// died: it must reparse just the journaled files and land on a full
+// Generate's IR. A journal that vouches for another IR, or holds a Notify,
+// is not replayed.
+func TestJournal_ReplaysAfterCrash(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.go", "package a\n\nfunc A() {}\n")
+	writeFile(t, root, "b.go", "package a\n\nfunc B() {}\n")
+	journalPath := filepath.Join(t.TempDir(), "w.journal")
+	gen := ir.NewGenerator(ir.GeneratorConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	first, err := New(ctx, Config{Root: root, Generator: gen, Poll: 5 * time.Millisecond, Debounce: time.Hour, Journal: journalPath})
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	saved := first.Snapshot().IR
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		first.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// Old enough that only the journal, not the mtime check, names it.
+	writeFile(t, root, "a.go", "package a\n\nfunc A2() {}\n")
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "a.go"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, root, "c.go", "package a\n\nfunc C() {}\n")
+	if err := os.Remove(filepath.Join(root, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		_, entries, _ := readJournal(journalPath)
+		if len(entries) >= 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("journal has %d entries, want 3", len(entries))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	second, err := New(context.Background(), Config{Root: root, Generator: gen, Seed: saved, Journal: journalPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := second.Snapshot()
+	if s.Events != 3 {
+		t.Errorf("replay reparsed %d files, want 3", s.Events)
+	}
+	full, _, err := gen.Generate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.IR.RootHash != full.RootHash {
+		t.Errorf("replayed RootHash %s != full generate %s", s.IR.RootHash, full.RootHash)
+	}
+
+	// A change the journal cannot name forces a full Update.
+	second.record(nil, true)
+	if w, err := New(context.Background(), Config{Root: root, Generator: gen, Seed: s.IR, Journal: journalPath}); err != nil || w.Snapshot().Events != 0 {
+		t.Errorf("journal with a Notify was replayed (err %v)", err)
+	}
+	// Each New checkpointed the journal for its own IR, so the one saved
+	// before the crash no longer matches it.
+	if w, err := New(context.Background(), Config{Root: root, Generator: gen, Seed: saved, Journal: journalPath}); err != nil || w.Snapshot().Events != 0 {
+		t.Errorf("journal replayed against another IR (err %v)", err)
+	}
+}
+
+// TestRescan_HealsUnstampedEdit relies on the periodic rescan, not Notify, to
+// pick up an edit invisible to the stamp.
+func TestRescan_HealsUnstampedEdit(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.go", "package a\n\nfunc Old() {}\n")
+	_, published := startWatcherConfig(t, Config{Root: root, Debounce: 20 * time.Millisecond, Rescan: 50 * time.Millisecond})
+	writeUnstamped(t, root, "a.go", "package a\n\nfunc New() {}\n")
+	s := awaitPublish(t, published)
+	if got := s.IR.Files["a.go"].Symbols; len(got) != 1 || got[0].Name != "New" || s.Events != 0 {
+		t.Errorf("rescan published symbols %+v with %d events, want New with 0", got, s.Events)
+	}
+	select {
+	case extra := <-published:
+		t.Errorf("a rescan with nothing to heal published seq %d", extra.Seq)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+// TestRescan_OnDemandRateLimited asks for two rescans in a row: the first
+// runs at once, the second is put off for RescanGap.
+func TestRescan_OnDemandRateLimited(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.go", "package a\n\nfunc Old() {}\n")
+	w, published := startWatcherConfig(t, Config{Root: root, Debounce: 20 * time.Millisecond, RescanGap: time.Hour})
+	writeUnstamped(t, root, "a.go", "package a\n\nfunc New() {}\n")
+	if at := w.Rescan(); time.Until(at) > 0 {
+		t.Errorf("first Rescan put off until %v", at)
+	}
+	if s := awaitPublish(t, published); s.IR.Files["a.go"].Symbols[0].Name != "New" {
+		t.Errorf("rescan published %+v", s.IR.Files["a.go"].Symbols)
+	}
+
+	writeUnstamped(t, root, "a.go", "package a\n\nfunc Two() {}\n")
+	if at := w.Rescan(); time.Until(at) < 59*time.Minute {
+		t.Errorf("second Rescan may start at %v, want about an hour out", at)
+	}
+	select {
+	case s := <-published:
+		t.Errorf("rate-limited rescan ran early (seq %d)", s.Seq)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+// TestEvents_PublishWithoutPoll: with file events on and a poll too slow to
+// matter, an edit to a watched directory and a new file both publish, and the
+// new file's directory is watched from then on.
+func TestEvents_PublishWithoutPoll(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main\n")
+	writeFile(t, root, "pkg/a.go", "package pkg\n\nfunc Old() {}\n")
+	_, published := startWatcherConfig(t, Config{Root: root, Poll: time.Hour, Debounce: 20 * time.Millisecond, Events: true})
+
+	writeFile(t, root, "pkg/a.go", "package pkg\n\nfunc New() {}\n")
+	s := awaitPublish(t, published)
+	if got := s.IR.Files["pkg/a.go"].Symbols; len(got) != 1 || got[0].Name != "New" || s.Events == 0 {
+		t.Errorf("published symbols %+v with %d events, want New", got, s.Events)
+	}
+	if want := (ir.ChangeSet{Modified: []string{"pkg/a.go"}}); !reflect.DeepEqual(s.Changes, want) {
+		t.Errorf("Changes = %+v, want %+v", s.Changes, want)
+	}
+
+	writeFile(t, root, "lib/b.go", "package lib\n")
+	if s = awaitPublish(t, published); s.IR.Files["lib/b.go"].Hash == "" {
+		t.Fatalf("new file not published: %v", s.IR.Files)
+	}
+	writeFile(t, root, "lib/b.go", "package lib\n\nfunc B() {}\n")
+	if s = awaitPublish(t, published); len(s.IR.Files["lib/b.go"].Symbols) != 1 {
+		t.Errorf("edit in the new directory published %+v", s.IR.Files["lib/b.go"])
+	}
+}
@@ -0,0 +1,170 @@
+// Package runecho is RunEcho's public Go API for embedding and extension. The
+// implementation lives under internal/ and stays free to change; this package
+// re-exports, mostly as type aliases, the parts a downstream program may build
+// on. Values therefore pass between this package and the internal one
+// unchanged.
+package runecho
+
+import (
+	"context"
+
+	"github.com/inth3shadows/runecho/internal/analyze"
+	"github.com/inth3shadows/runecho/internal/config"
+	"github.com/inth3shadows/runecho/internal/ir"
+	"github.com/inth3shadows/runecho/internal/parser"
+	"github.com/inth3shadows/runecho/internal/render"
+)
+
+// IR model. See internal/ir for field documentation.
+type (
+	IR       = ir.IR
+	FileIR   = ir.FileIR
+	Symbol   = ir.Symbol
+	Omission = ir.Omission
+)
+
+// Parser API. A Parser passed in GeneratorConfig.Parsers is consulted before
+// the built-ins; see internal/parser for the FileStructure contract.
+type (
+	Parser          = parser.Parser
+	FileStructure   = parser.FileStructure
+	Generator       = ir.Generator
+	GeneratorConfig = ir.GeneratorConfig
+	Stats           = ir.Stats
+	PhaseTimes      = ir.PhaseTimes
+	ChangeSet       = ir.ChangeSet
+	Result          = ir.Result
+	Warning         = ir.Warning
+	WarningKind     = ir.WarningKind
+)
+
+// Warning kinds; see ir.WarningKind.
+const (
+	WarnConfig        = ir.WarnConfig
+	WarnAccess        = ir.WarnAccess
+	WarnParse         = ir.WarnParse
+	WarnHashOnly      = ir.WarnHashOnly
+	WarnPathCollision = ir.WarnPathCollision
+	WarnCaseCollision = ir.WarnCaseCollision
+	WarnSymlinkCycle  = ir.WarnSymlinkCycle
+	WarnObjectStore   = ir.WarnObjectStore
+)
+
+// RegisterParser makes p the parser for files with extension ext (".foo") in
+// every Generator this process creates afterwards, ahead of the built-ins and
+// of GeneratorConfig.Parsers. Call it from an init func; it panics on a nil
+// parser, a malformed extension, or one already registered.
+func RegisterParser(ext string, p Parser) { parser.Register(ext, p) }
+
+// GeneratorConfig.ParserBackend values.
+const (
+	ParserBackendDefault    = ir.ParserBackendDefault
+	ParserBackendTreeSitter = ir.ParserBackendTreeSitter
+)
+
+// NewGenerator returns an IR generator. A zero GeneratorConfig indexes like
+// the CLI does for a repo without .runecho.json.
+func NewGenerator(cfg GeneratorConfig) *Generator { return ir.NewGenerator(cfg) }
+
+// DiffFiles returns the files added, changed, and dropped relative to from.
+// Generator.UpdateChanges returns the same alongside the updated IR.
+func DiffFiles(from, to *IR) ChangeSet { return ir.DiffFiles(from, to) }
+
+// Load reads an IR from a local path or a "scheme://…" URI served by a
+// registered Storage backend. (*IR).Save writes to either.
+func Load(loc string) (*IR, error) { return ir.Load(loc) }
+
+// Storage is an IR storage backend addressed by URI scheme. See ir.Storage for
+// the contract.
+type Storage = ir.Storage
+
+// RegisterStorage makes s the backend for scheme, so Load, Save, and a
+// .runecho.json "ir" location of the form "scheme://…" reach it. Call it from
+// an init func; it panics on a duplicate or malformed scheme, or on "file".
+func RegisterStorage(scheme string, s Storage) { ir.RegisterStorage(scheme, s) }
+
+// PathFilter is a walk hook; see ir.PathFilter.
+type (
+	PathFilter = ir.PathFilter
+	Decision   = ir.Decision
+)
+
+// PathFilter verdicts.
+const (
+	PathDefault = ir.PathDefault
+	PathSkip    = ir.PathSkip
+	PathInclude = ir.PathInclude
+)
+
+// RegisterPathFilter adds f to every IR walk in this process, after the
+// filters a caller passes directly. Call it from an init func.
+func RegisterPathFilter(f PathFilter) { ir.RegisterPathFilter(f) }
+
+// Renderer API. See internal/render.
+type (
+	Renderer     = render.Renderer
+	RendererFunc = render.RendererFunc
+	MapView      = render.Map
+	MapSymbol    = render.Symbol
+)
+
+// RegisterRenderer makes r available as `map --format=name`. Call it from an
+// init func; it panics on an empty or duplicate name.
+func RegisterRenderer(name string, r Renderer) { render.Register(name, r) }
+
+// Analyzer API. See internal/analyze.
+type (
+	// Analyzer inspects an IR and reports findings. Register one with
+	// RegisterAnalyzer, and every Analyze run — including `runecho-ir analyze`
+	// in a binary that links it — includes it.
+	Analyzer        = analyze.Analyzer
+	AnalysisContext = analyze.Context
+	AnalyzerOptions = analyze.Options
+	Finding         = analyze.Finding
+	Graph           = analyze.Graph
+	Report          = analyze.Report
+)
+
+// Finding severities.
+const (
+	SeverityError   = analyze.SeverityError
+	SeverityWarning = analyze.SeverityWarning
+	SeverityInfo    = analyze.SeverityInfo
+)
+
+// RegisterAnalyzer adds a to the analyzer registry. Call it from an init func.
+// It panics on a nil analyzer or a name already registered.
+func RegisterAnalyzer(a Analyzer) { analyze.Register(a) }
+
+// Analyzers returns the names of all registered analyzers, sorted.
+func Analyzers() []string { return analyze.Registered() }
+
+// Generate builds the IR for the repo at root the way the CLI does: default
+// ignores, plus the settings and parser plugins its config file declares.
+func Generate(root string) (*IR, error) {
+	return GenerateCtx(context.Background(), root)
+}
+
+// GenerateCtx is Generate with a context: cancelling ctx, or reaching its
+// deadline, stops the walk between files and returns ctx's error with no IR.
+// Without a deadline the walk is bounded by ir.DefaultGenerateTimeout.
+func GenerateCtx(ctx context.Context, root string) (*IR, error) {
+	cfg, err := config.Load(root)
+	if err != nil {
+		return nil, err
+	}
+	plugins, err := cfg.PluginParsers(root, nil)
+	if err != nil {
+		return nil, err
+	}
+	gen := ir.NewGenerator(cfg.Generator(plugins))
+	irData, _, err := gen.GenerateCtx(ctx, root)
+	return irData, err
+}
+
+// Analyze runs every registered analyzer not disabled by opts and returns the
+// merged, sorted report. A failing analyzer's error is returned alongside the
+// other analyzers' findings.
+func Analyze(irData *IR, opts AnalyzerOptions) (*Report, error) {
+	return analyze.Run(irData, opts, nil)
+}
@@ -0,0 +1,106 @@
+package runecho_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/inth3shadows/runecho"
+)
+
+// todoAnalyzer is the kind of analyzer a third party registers: it uses only
+// the public package.
+type todoAnalyzer struct{}
+
+func (todoAnalyzer) Name() string { return "test-todo" }
+
+func (todoAnalyzer) Analyze(ctx *runecho.AnalysisContext) ([]runecho.Finding, error) {
+	var out []runecho.Finding
+	for path, f := range ctx.IR.Files {
+		for _, s := range f.Symbols {
+			if s.Kind == "function" && s.Name == "TODO" {
+				out = append(out, runecho.Finding{Path: path, Line: s.Line, Symbol: s.Name, Message: "unfinished"})
+			}
+		}
+	}
+	return out, nil
+}
+
+func init() { runecho.RegisterAnalyzer(todoAnalyzer{}) }
+
+func TestThirdPartyAnalyzer(t *testing.T) {
+	if !slices.Contains(runecho.Analyzers(), "test-todo") {
+		t.Fatalf("Analyzers() = %v, missing the registered one", runecho.Analyzers())
+	}
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n\nfunc TODO() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	irData, err := runecho.Generate(root)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	rep, err := runecho.Analyze(irData, runecho.AnalyzerOptions{})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(rep.Findings) != 1 {
+		t.Fatalf("findings = %+v, want one", rep.Findings)
+	}
+	if f := rep.Findings[0]; f.Analyzer != "test-todo" || f.Path != "a.go" || f.Line != 3 || f.Severity != runecho.SeverityWarning {
+		t.Errorf("finding = %+v", f)
+	}
+}
+
+// procParser is a third party's parser for a language RunEcho does not know:
+// each "proc name" line declares a function.
+type procParser struct{}
+
+func (procParser) SupportsExtension(string) bool { return false }
+
+func (procParser) Parse(src string) (runecho.FileStructure, error) {
+	var fs runecho.FileStructure
+	for _, line := range strings.Split(src, "\n") {
+		if name, ok := strings.CutPrefix(line, "proc "); ok {
+			fs.Functions = append(fs.Functions, name)
+		}
+	}
+	return fs, nil
+}
+
+func init() { runecho.RegisterParser(".zzproc", procParser{}) }
+
+func TestThirdPartyParser(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.zzproc"), []byte("proc boot\nproc halt\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	irData, err := runecho.Generate(root)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	var names []string
+	for _, s := range irData.Files["main.zzproc"].Symbols {
+		names = append(names, s.Kind+":"+s.Name)
+	}
+	if want := []string{"function:boot", "function:halt"}; !slices.Equal(names, want) {
+		t.Errorf("symbols = %v, want %v", names, want)
+	}
+}
+
+func TestGenerateCtx_Cancelled(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	irData, err := runecho.GenerateCtx(ctx, root)
+	if !errors.Is(err, context.Canceled) || irData != nil {
+		t.Errorf("GenerateCtx on a cancelled ctx = %v, %v; want nil, context.Canceled", irData, err)
+	}
+}
@@ -0,0 +1,210 @@
+// Package runechotest helps downstream integrators test what they plug into
+// RunEcho — parsers, analyzers, path filters — against the property RunEcho
+// promises: the same input tree yields the same bytes. It builds synthetic
+// repos, compares JSON canonically, and keeps golden IR files, in the manner
+// of net/http/httptest.
+//
+// Golden files are rewritten instead of compared when the environment sets
+// RUNECHO_UPDATE_GOLDEN=1, so a deliberate output change is one command:
+//
+//	RUNECHO_UPDATE_GOLDEN=1 go test ./...
+package runechotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/inth3shadows/runecho"
+	"github.com/inth3shadows/runecho/internal/analyze"
+)
+
+// UpdateEnv names the variable that switches golden helpers to rewrite mode.
+const UpdateEnv = "RUNECHO_UPDATE_GOLDEN"
+
+// Repo writes files (slash-separated relative path → contents) into a fresh
+// temporary directory and returns its root. Parent directories are created.
+func Repo(t testing.TB, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			t.Fatalf("runechotest.Repo: %q is not a relative path inside the repo", name)
+		}
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("runechotest.Repo: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("runechotest.Repo: %v", err)
+		}
+	}
+	return root
+}
+
+// Generate indexes root with cfg and fails t on error.
+func Generate(t testing.TB, root string, cfg runecho.GeneratorConfig) *runecho.IR {
+	t.Helper()
+	irData, _, err := runecho.NewGenerator(cfg).Generate(root)
+	if err != nil {
+		t.Fatalf("runechotest.Generate(%s): %v", root, err)
+	}
+	return irData
+}
+
+// IRBytes returns irData exactly as ir.Save writes .ai/ir.json. This is the
+// form golden IR files hold.
+func IRBytes(t testing.TB, irData *runecho.IR) []byte {
+	t.Helper()
+	data, err := json.Marshal(irData)
+	if err != nil {
+		t.Fatalf("runechotest.IRBytes: %v", err)
+	}
+	return data
+}
+
+// AssertDeterministic indexes root twice, then once more from a copy at a
+// different path, and fails t unless all three IRs are byte-identical: a
+// parser or filter that leaks map order, the clock, or the absolute path
+// fails here. It returns the first IR.
+func AssertDeterministic(t testing.TB, root string, cfg runecho.GeneratorConfig) *runecho.IR {
+	t.Helper()
+	first := Generate(t, root, cfg)
+	want := IRBytes(t, first)
+	if got := IRBytes(t, Generate(t, root, cfg)); !bytes.Equal(got, want) {
+		t.Errorf("IR differs between two runs over the same tree:\n%s", firstDiff(got, want))
+	}
+	moved := filepath.Join(t.TempDir(), "moved")
+	if err := os.CopyFS(moved, os.DirFS(root)); err != nil {
+		t.Fatalf("runechotest.AssertDeterministic: copy tree: %v", err)
+	}
+	if got := IRBytes(t, Generate(t, moved, cfg)); !bytes.Equal(got, want) {
+		t.Errorf("IR depends on the repo's location on disk:\n%s", firstDiff(got, want))
+	}
+	return first
+}
+
+// AssertAnalyzerDeterministic runs a over irData twice, each time with a
+// freshly built Context, and fails t unless both runs report the same findings.
+// Order is ignored (the report sorts); content is not. It returns the findings
+// of the first run, sorted.
+func AssertAnalyzerDeterministic(t testing.TB, a runecho.Analyzer, irData *runecho.IR, opts runecho.AnalyzerOptions) []runecho.Finding {
+	t.Helper()
+	run := func() []runecho.Finding {
+		found, err := a.Analyze(&runecho.AnalysisContext{IR: irData, Graph: analyze.BuildGraph(irData), Options: opts})
+		if err != nil {
+			t.Fatalf("analyzer %q: %v", a.Name(), err)
+		}
+		analyze.SortFindings(found)
+		return found
+	}
+	first := run()
+	AssertJSONEqual(t, run(), first)
+	return first
+}
+
+// CanonicalJSON returns v in canonical JSON form: object keys sorted,
+// two-space indent, numbers as written, trailing newline. v may be JSON text
+// ([]byte, json.RawMessage, or string) or any value encoding/json marshals.
+func CanonicalJSON(v any) ([]byte, error) {
+	var raw []byte
+	switch x := v.(type) {
+	case []byte:
+		raw = x
+	case json.RawMessage:
+		raw = x
+	case string:
+		raw = []byte(x)
+	default:
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("canonical JSON: %w", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("canonical JSON: trailing data after the value")
+	}
+	out, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// AssertJSONEqual fails t unless got and want are equal once both are in
+// canonical form (see CanonicalJSON), reporting the first differing line.
+func AssertJSONEqual(t testing.TB, got, want any) {
+	t.Helper()
+	g, err := CanonicalJSON(got)
+	if err != nil {
+		t.Fatalf("runechotest.AssertJSONEqual: got: %v", err)
+	}
+	w, err := CanonicalJSON(want)
+	if err != nil {
+		t.Fatalf("runechotest.AssertJSONEqual: want: %v", err)
+	}
+	if !bytes.Equal(g, w) {
+		t.Errorf("JSON differs:\n%s", firstDiff(g, w))
+	}
+}
+
+// Golden compares got with the file at path. With UpdateEnv=1 it writes got
+// to path instead (creating parent directories) and passes. A missing golden
+// file fails with a hint to run in update mode.
+func Golden(t testing.TB, path string, got []byte) {
+	t.Helper()
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("runechotest.Golden: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("runechotest.Golden: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden file %s does not exist (run with %s=1 to create it)", path, UpdateEnv)
+	}
+	if err != nil {
+		t.Fatalf("runechotest.Golden: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from golden file %s (run with %s=1 to accept):\n%s", path, UpdateEnv, firstDiff(got, want))
+	}
+}
+
+// GoldenIR is Golden over irData's stored form (see IRBytes).
+func GoldenIR(t testing.TB, path string, irData *runecho.IR) {
+	t.Helper()
+	Golden(t, path, IRBytes(t, irData))
+}
+
+// firstDiff describes the first line where got and want differ.
+func firstDiff(got, want []byte) string {
+	g := strings.Split(string(got), "\n")
+	w := strings.Split(string(want), "\n")
+	for i := 0; i < len(g) || i < len(w); i++ {
+		var gl, wl string
+		if i < len(g) {
+			gl = g[i]
+		}
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i >= len(g) || i >= len(w) || gl != wl {
+			return fmt.Sprintf("line %d:\n  got:  %q\n  want: %q", i+1, gl, wl)
+		}
+	}
+	return "(no line differs; the difference is in line endings or a trailing newline)"
+}
@@ -0,0 +1,141 @@
+package runechotest_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/inth3shadows/runecho"
+	"github.com/inth3shadows/runecho/runechotest"
+)
+
+// recorder is a testing.TB that records failures instead of reporting them,
+// so the helpers' failure paths can be asserted on.
+type recorder struct {
+	testing.TB
+	failed bool
+	msgs   []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failed = true
+	r.msgs = append(r.msgs, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// record runs fn against a recorder in its own goroutine, so a Fatalf ends
+// only fn.
+func record(t *testing.T, fn func(tb testing.TB)) *recorder {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(r)
+	}()
+	<-done
+	return r
+}
+
+var fixture = map[string]string{
+	"src/util.ts": "export function helper() { return 1 }\n",
+	"src/app.ts":  "import { helper } from './util'\nexport function main() { return helper() }\n",
+	"cmd/main.go": "package main\n\nfunc main() {}\n",
+}
+
+func TestRepo(t *testing.T) {
+	root := runechotest.Repo(t, fixture)
+	got, err := os.ReadFile(filepath.Join(root, "src", "app.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != fixture["src/app.ts"] {
+		t.Errorf("src/app.ts = %q", got)
+	}
+
+	r := record(t, func(tb testing.TB) { runechotest.Repo(tb, map[string]string{"../escape.ts": ""}) })
+	if !r.failed {
+		t.Error("Repo accepted a path outside the repo")
+	}
+}
+
+func TestAssertDeterministic(t *testing.T) {
+	root := runechotest.Repo(t, fixture)
+	irData := runechotest.AssertDeterministic(t, root, runecho.GeneratorConfig{})
+	if len(irData.Files) != 3 {
+		t.Errorf("indexed %d files, want 3", len(irData.Files))
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	a, err := runechotest.CanonicalJSON(`{"b": 1.50, "a": [true, null]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"a\": [\n    true,\n    null\n  ],\n  \"b\": 1.50\n}\n"
+	if string(a) != want {
+		t.Errorf("CanonicalJSON = %q, want %q", a, want)
+	}
+	if _, err := runechotest.CanonicalJSON(`{} {}`); err == nil {
+		t.Error("CanonicalJSON accepted trailing data")
+	}
+}
+
+func TestAssertJSONEqual(t *testing.T) {
+	runechotest.AssertJSONEqual(t, []byte(`{"x":1,"y":[2]}`), map[string]any{"y": []int{2}, "x": 1})
+
+	r := record(t, func(tb testing.TB) { runechotest.AssertJSONEqual(tb, `{"x":1}`, `{"x":2}`) })
+	if !r.failed || !strings.Contains(r.msgs[0], "line 2:") {
+		t.Errorf("mismatch not reported usefully: %q", r.msgs)
+	}
+}
+
+func TestGoldenIR(t *testing.T) {
+	irData := runechotest.Generate(t, runechotest.Repo(t, fixture), runecho.GeneratorConfig{})
+	path := filepath.Join(t.TempDir(), "testdata", "fixture.ir.json")
+
+	r := record(t, func(tb testing.TB) { runechotest.GoldenIR(tb, path, irData) })
+	if !r.failed || !strings.Contains(r.msgs[0], runechotest.UpdateEnv) {
+		t.Errorf("missing golden file not reported with the update hint: %q", r.msgs)
+	}
+
+	t.Setenv(runechotest.UpdateEnv, "1")
+	runechotest.GoldenIR(t, path, irData)
+	t.Setenv(runechotest.UpdateEnv, "")
+	runechotest.GoldenIR(t, path, irData)
+
+	changed := runechotest.Generate(t, runechotest.Repo(t, map[string]string{"a.go": "package a\n\nfunc A() {}\n"}), runecho.GeneratorConfig{})
+	r = record(t, func(tb testing.TB) { runechotest.GoldenIR(tb, path, changed) })
+	if !r.failed {
+		t.Error("GoldenIR passed for a different IR")
+	}
+}
+
+// flaky reports a finding whose message changes between runs.
+type flaky struct{ n *int }
+
+func (flaky) Name() string { return "flaky" }
+
+func (f flaky) Analyze(ctx *runecho.AnalysisContext) ([]runecho.Finding, error) {
+	*f.n++
+	return []runecho.Finding{{Path: "src/app.ts", Message: fmt.Sprintf("run %d", *f.n)}}, nil
+}
+
+func TestAssertAnalyzerDeterministic(t *testing.T) {
+	irData := runechotest.Generate(t, runechotest.Repo(t, fixture), runecho.GeneratorConfig{})
+	n := 0
+	r := record(t, func(tb testing.TB) {
+		runechotest.AssertAnalyzerDeterministic(tb, flaky{&n}, irData, runecho.AnalyzerOptions{})
+	})
+	if !r.failed {
+		t.Error("a nondeterministic analyzer passed")
+	}
+}